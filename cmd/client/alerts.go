@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+// handleAlerts prints alerts as they fire and resolve until interrupted.
+// The global context times out after 30s, so the stream has its own.
+func handleAlerts(ctx context.Context, client pb.OrchestratorServiceClient, key string, args []string) {
+	fs := flag.NewFlagSet("alerts", flag.ExitOnError)
+	firing := fs.Bool("firing", true, "First print the alerts firing now")
+	fs.Parse(args)
+
+	if key == "" {
+		fmt.Fprintln(os.Stderr, "Error: --key is required for alerts")
+		os.Exit(1)
+	}
+
+	hostname, _ := os.Hostname()
+	sessionResp, err := client.CreateSession(ctx, &pb.AuthRequest{
+		DeviceName:  hostname,
+		SecurityKey: key,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating session: %v\n", err)
+		os.Exit(1)
+	}
+
+	watchCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	stream, err := client.WatchAlerts(watchCtx, &pb.WatchAlertsRequest{
+		SessionId:     sessionResp.SessionId,
+		IncludeFiring: *firing,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error watching alerts: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintln(os.Stderr, "Watching alerts (Ctrl-C to stop)...")
+	for {
+		a, err := stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) || watchCtx.Err() != nil {
+				return
+			}
+			fmt.Fprintf(os.Stderr, "Error receiving alert: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s  %-8s  %-8s  %s\n",
+			time.UnixMilli(a.TimeMs).Format("2006-01-02 15:04:05"), a.State, a.Severity, a.Message)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"text/tabwriter"
 	"time"
@@ -34,6 +35,28 @@ func (a *arrayFlags) Set(value string) error {
 	return nil
 }
 
+// priorityFlag is a job priority: a number, or low, normal, high or interactive
+type priorityFlag int32
+
+var priorityNames = map[string]priorityFlag{"low": -10, "normal": 0, "high": 10, "interactive": 20}
+
+func (p *priorityFlag) String() string {
+	return strconv.Itoa(int(*p))
+}
+
+func (p *priorityFlag) Set(value string) error {
+	if v, ok := priorityNames[strings.ToLower(value)]; ok {
+		*p = v
+		return nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("want a number or low, normal, high or interactive")
+	}
+	*p = priorityFlag(n)
+	return nil
+}
+
 func usage() {
 	fmt.Fprintf(os.Stderr, `Usage: client [global flags] [command] [command flags]
 
@@ -45,11 +68,20 @@ Commands:
   register         Register this device to the server registry
   list-devices     List all registered devices
   status           Get device status
+  metrics          Show a device's metrics history (min/avg/max per bucket)
+  alerts           Print alerts as they fire and resolve
   route-task       Route an AI task to the best device
   routed-cmd       Execute command on best available device (routed)
   submit-job       Submit a distributed job to all devices
   get-job          Get the status/result of a submitted job
+  create-schedule  Run a job on a cron schedule
+  list-schedules   List schedules and their recent runs
+  delete-schedule  Delete a schedule
+  run-all          Run a command on every matching device and collect results
+  map              Run LLM_GENERATE or EMBED over many items, sharded across devices
   plan-cost        Estimate execution cost for a plan
+  upload           Upload a file into a device's shared folder
+  download         Download a file from a device (resumes partial files)
   qaihub-list-devices  List Qualcomm AI Hub devices (no server needed)
 
 Legacy mode (without subcommand):
@@ -66,6 +98,12 @@ Examples:
   # Get device status
   client status --id <device-id>
 
+  # Was the NPU busy last night? Hourly buckets over the last day
+  client metrics --id <device-id> --since 24h --resolution 1h
+
+  # Watch alerts (CPU pegged, device offline, job failed) until Ctrl-C
+  client --key dev alerts
+
   # Route an AI task
   client --key dev route-task --task summarize --input "hello world"
 
@@ -79,14 +117,38 @@ Examples:
 
   # Submit a distributed job
   client --key dev submit-job --text "collect status" --max-workers 2
+  client --key dev submit-job --text "nightly report" --priority low
 
   # Get job status/result
   client get-job --id <job-id>
 
+  # Collect SYSINFO across the mesh every hour
+  client --key dev create-schedule --name hourly-sysinfo --cron @hourly --fan-out SYSINFO
+
+  # Run a plan at 6pm Berlin time on weekdays, skipping runs missed while down
+  client --key dev create-schedule --cron "0 18 * * mon-fri" --tz Europe/Berlin --plan plan.json --missed skip
+  client --key dev list-schedules
+
+  # Run a command on every Linux device with a GPU
+  client --key dev run-all --cmd "df -h" --platform linux --capability gpu
+
+  # Summarize every document in a folder across the mesh
+  client --key dev map --path docs --glob "*.md" --prompt "Summarize: {{item}}" --out summaries.jsonl
+
+  # Embed one item per line of a file
+  client --key dev map --kind EMBED --path corpus.txt --out vectors.jsonl
+
   # Estimate plan cost
   cat plan.json | client --key dev plan-cost
   client --key dev plan-cost --plan plan.json
 
+  # Upload a file (large files are chunked and resumable)
+  client --key dev upload --file model.gguf --path models/model.gguf
+  client --key dev upload --file data.csv --device <device-id> --overwrite
+
+  # Download a file (re-run to resume an interrupted download)
+  client download --path models/model.gguf --device <device-id> --out model.gguf
+
   # Execute a command locally (legacy mode)
   client --key dev --cmd pwd
 `)
@@ -137,6 +199,10 @@ func main() {
 		handleListDevices(ctx, client)
 	case "status":
 		handleStatus(ctx, client, flag.Args()[1:])
+	case "metrics":
+		handleMetrics(ctx, client, flag.Args()[1:])
+	case "alerts":
+		handleAlerts(ctx, client, *key, flag.Args()[1:])
 	case "route-task":
 		handleRouteTask(ctx, client, *key, flag.Args()[1:])
 	case "routed-cmd":
@@ -145,8 +211,22 @@ func main() {
 		handleSubmitJob(ctx, client, *key, flag.Args()[1:])
 	case "get-job":
 		handleGetJob(ctx, client, flag.Args()[1:])
+	case "create-schedule":
+		handleCreateSchedule(ctx, client, *key, flag.Args()[1:])
+	case "list-schedules":
+		handleListSchedules(ctx, client, *key, flag.Args()[1:])
+	case "delete-schedule":
+		handleDeleteSchedule(ctx, client, *key, flag.Args()[1:])
+	case "run-all":
+		handleRunAll(ctx, client, *key, flag.Args()[1:])
+	case "map":
+		handleMap(ctx, client, *key, flag.Args()[1:])
 	case "plan-cost":
 		handlePlanCost(ctx, client, *key, flag.Args()[1:])
+	case "upload":
+		handleUpload(ctx, client, *key, *addr, flag.Args()[1:])
+	case "download":
+		handleDownload(ctx, client, *addr, flag.Args()[1:])
 	case "":
 		// Legacy mode: execute command
 		if *cmd == "" {
@@ -310,6 +390,73 @@ func handleStatus(ctx context.Context, client pb.OrchestratorServiceClient, args
 		fmt.Printf("  CPU Load: unavailable\n")
 	}
 	fmt.Printf("  Memory: %d MB used / %d MB total\n", resp.MemUsedMb, resp.MemTotalMb)
+	if p := resp.Power; p != nil {
+		if p.HasBattery {
+			source := "on battery"
+			if p.Charging {
+				source = "charging"
+			} else if p.PluggedIn {
+				source = "plugged in"
+			}
+			fmt.Printf("  Battery: %.0f%% (%s)\n", p.BatteryPercent, source)
+		} else {
+			fmt.Printf("  Battery: none\n")
+		}
+		if p.ThermalState != "" {
+			fmt.Printf("  Thermal: %s (%.1f C)\n", p.ThermalState, p.TemperatureC)
+		}
+	}
+}
+
+func handleMetrics(ctx context.Context, client pb.OrchestratorServiceClient, args []string) {
+	// Parse metrics-specific flags
+	fs := flag.NewFlagSet("metrics", flag.ExitOnError)
+	id := fs.String("id", "", "Device ID (required)")
+	since := fs.Duration("since", 24*time.Hour, "How far back to look")
+	resolution := fs.Duration("resolution", 0, "Bucket size, e.g. 1m or 1h (default: automatic)")
+	fs.Parse(args)
+
+	if *id == "" {
+		fmt.Fprintln(os.Stderr, "Error: --id is required")
+		os.Exit(1)
+	}
+
+	now := time.Now()
+	resp, err := client.GetDeviceMetrics(ctx, &pb.MetricsQuery{
+		DeviceId:     *id,
+		FromMs:       now.Add(-*since).UnixMilli(),
+		ToMs:         now.UnixMilli(),
+		ResolutionMs: resolution.Milliseconds(),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting metrics: %v\n", err)
+		os.Exit(1)
+	}
+	if len(resp.Points) == 0 {
+		fmt.Println("No metrics recorded in that range.")
+		return
+	}
+
+	step := "raw samples"
+	if resp.ResolutionMs > 0 {
+		step = (time.Duration(resp.ResolutionMs) * time.Millisecond).String() + " buckets"
+	}
+	fmt.Printf("Metrics for %s (%s), %s:\n", resp.DeviceName, resp.DeviceId, step)
+	fmt.Printf("  %-20s %-13s %-13s %-13s %s\n", "START", "CPU avg/max", "GPU avg/max", "NPU avg/max", "MEM avg MB")
+	load := func(st *pb.MetricStats) string {
+		if st == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%.0f%%/%.0f%%", st.Avg*100, st.Max*100)
+	}
+	for _, p := range resp.Points {
+		mem := "-"
+		if p.MemUsedMb != nil {
+			mem = fmt.Sprintf("%.0f", p.MemUsedMb.Avg)
+		}
+		fmt.Printf("  %-20s %-13s %-13s %-13s %s\n", time.UnixMilli(p.StartMs).Format("2006-01-02 15:04:05"),
+			load(p.CpuLoad), load(p.GpuLoad), load(p.NpuLoad), mem)
+	}
 }
 
 func handleRouteTask(ctx context.Context, client pb.OrchestratorServiceClient, key string, args []string) {
@@ -365,6 +512,9 @@ func handleRoutedCmd(ctx context.Context, client pb.OrchestratorServiceClient, k
 	preferRemote := fs.Bool("prefer-remote", false, "Prefer remote device if available")
 	requireNPU := fs.Bool("require-npu", false, "Require device with NPU")
 	forceDevice := fs.String("force-device", "", "Force execution on specific device ID")
+	preferPluggedIn := fs.Bool("prefer-plugged-in", false, "Prefer devices on external power")
+	avoidLowBattery := fs.Bool("avoid-low-battery", false, "Never use devices on low battery or overheating")
+	minBattery := fs.Float64("min-battery", 0, "Battery percent below which --avoid-low-battery skips a device (0 = 20)")
 	var cmdArgs arrayFlags
 	fs.Var(&cmdArgs, "arg", "Command arguments (repeatable)")
 	fs.Parse(args)
@@ -391,6 +541,11 @@ func handleRoutedCmd(ctx context.Context, client pb.OrchestratorServiceClient, k
 		policy.Mode = pb.RoutingPolicy_REQUIRE_NPU
 	} else if *preferRemote {
 		policy.Mode = pb.RoutingPolicy_PREFER_REMOTE
+	} else if *avoidLowBattery {
+		policy.Mode = pb.RoutingPolicy_AVOID_LOW_BATTERY
+		policy.MinBatteryPercent = *minBattery
+	} else if *preferPluggedIn {
+		policy.Mode = pb.RoutingPolicy_PREFER_PLUGGED_IN
 	}
 
 	// Create session first
@@ -444,6 +599,8 @@ func handleSubmitJob(ctx context.Context, client pb.OrchestratorServiceClient, k
 	fs := flag.NewFlagSet("submit-job", flag.ExitOnError)
 	text := fs.String("text", "collect status", "Job description")
 	maxWorkers := fs.Int("max-workers", 0, "Max devices to use (0 = all)")
+	var priority priorityFlag
+	fs.Var(&priority, "priority", "low, normal, high, interactive or a number; higher runs first")
 	fs.Parse(args)
 
 	if key == "" {
@@ -467,6 +624,7 @@ func handleSubmitJob(ctx context.Context, client pb.OrchestratorServiceClient, k
 		SessionId:  sessionResp.SessionId,
 		Text:       *text,
 		MaxWorkers: int32(*maxWorkers),
+		Priority:   int32(priority),
 	})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error submitting job: %v\n", err)
@@ -476,6 +634,9 @@ func handleSubmitJob(ctx context.Context, client pb.OrchestratorServiceClient, k
 	fmt.Printf("Job submitted: %s\n", resp.JobId)
 	fmt.Printf("Summary: %s\n", resp.Summary)
 	fmt.Printf("Created at: %s\n", time.Unix(resp.CreatedAt, 0).Format(time.RFC3339))
+	if resp.TraceId != "" {
+		fmt.Printf("Trace ID: %s\n", resp.TraceId)
+	}
 }
 
 func handleGetJob(ctx context.Context, client pb.OrchestratorServiceClient, args []string) {
@@ -498,6 +659,12 @@ func handleGetJob(ctx context.Context, client pb.OrchestratorServiceClient, args
 
 	fmt.Printf("Job: %s\n", resp.JobId)
 	fmt.Printf("State: %s\n", resp.State)
+	if resp.ScheduleId != "" {
+		fmt.Printf("Schedule: %s\n", resp.ScheduleId)
+	}
+	if resp.TraceId != "" {
+		fmt.Printf("Trace ID: %s\n", resp.TraceId)
+	}
 	fmt.Printf("Tasks: %d\n", len(resp.Tasks))
 
 	for _, t := range resp.Tasks {
@@ -549,12 +716,13 @@ func handlePlanCost(ctx context.Context, client pb.OrchestratorServiceClient, ke
 
 	// Parse plan JSON
 	type taskInput struct {
-		TaskID          string `json:"task_id"`
-		Kind            string `json:"kind"`
-		Input           string `json:"input"`
-		TargetDeviceID  string `json:"target_device_id"`
-		PromptTokens    int32  `json:"prompt_tokens"`
-		MaxOutputTokens int32  `json:"max_output_tokens"`
+		TaskID          string              `json:"task_id"`
+		Kind            string              `json:"kind"`
+		Input           string              `json:"input"`
+		TargetDeviceID  string              `json:"target_device_id"`
+		PromptTokens    int32               `json:"prompt_tokens"`
+		MaxOutputTokens int32               `json:"max_output_tokens"`
+		Inputs          []*pb.InputArtifact `json:"inputs"`
 	}
 	type groupInput struct {
 		Index int32       `json:"index"`
@@ -592,6 +760,7 @@ func handlePlanCost(ctx context.Context, client pb.OrchestratorServiceClient, ke
 				TargetDeviceId:  t.TargetDeviceID,
 				PromptTokens:    t.PromptTokens,
 				MaxOutputTokens: t.MaxOutputTokens,
+				Inputs:          t.Inputs,
 			}
 		}
 		protoPlan.Groups[i] = protoGroup
@@ -622,6 +791,8 @@ func handlePlanCost(ctx context.Context, client pb.OrchestratorServiceClient, ke
 	fmt.Println("Plan Cost Estimation")
 	fmt.Println("====================")
 	fmt.Printf("Total predicted latency: %.0fms\n", resp.TotalPredictedMs)
+	fmt.Printf("Confidence: %s (%d sample(s))\n", resp.Confidence, resp.Samples)
+	fmt.Printf("Predicted energy: %.1fJ\n", resp.TotalEnergyJ)
 	fmt.Println()
 
 	if resp.Warning != "" {
@@ -631,7 +802,11 @@ func handlePlanCost(ctx context.Context, client pb.OrchestratorServiceClient, ke
 	fmt.Println("Per-device breakdown:")
 	for _, dc := range resp.DeviceCosts {
 		fmt.Printf("  Device: %s (%s)\n", dc.DeviceName, truncateID(dc.DeviceId))
-		fmt.Printf("    Total: %.0fms\n", dc.TotalMs)
+		fmt.Printf("    Total: %.0fms, %.1fJ", dc.TotalMs, dc.EnergyJ)
+		if dc.BatteryDrainPercent > 0 {
+			fmt.Printf(" (%.2f%% of battery)", dc.BatteryDrainPercent)
+		}
+		fmt.Println()
 		if dc.EstimatedPeakRamMb > 0 {
 			fmt.Printf("    Peak RAM: %d MB", dc.EstimatedPeakRamMb)
 			if !dc.RamSufficient {
@@ -644,6 +819,15 @@ func handlePlanCost(ctx context.Context, client pb.OrchestratorServiceClient, ke
 			if sc.UnknownCost {
 				costStr += " (unknown)"
 			}
+			if sc.Samples > 0 {
+				costStr += fmt.Sprintf(" [%s, %d sample(s)]", sc.Confidence, sc.Samples)
+			}
+			if sc.TransferBytes > 0 {
+				costStr += fmt.Sprintf(" (incl. %.0fms staging %d bytes)", sc.TransferMs, sc.TransferBytes)
+			}
+			if sc.NetworkMs > 0 {
+				costStr += fmt.Sprintf(" (incl. %.1fms network)", sc.NetworkMs)
+			}
 			fmt.Printf("    - %s: %s %s\n", sc.TaskId, sc.Kind, costStr)
 		}
 		fmt.Println()
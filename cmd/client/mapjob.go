@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+func handleMap(ctx context.Context, client pb.OrchestratorServiceClient, key string, args []string) {
+	fs := flag.NewFlagSet("map", flag.ExitOnError)
+	kind := fs.String("kind", "LLM_GENERATE", "Per-item kind: LLM_GENERATE or EMBED")
+	prompt := fs.String("prompt", "", `LLM_GENERATE prompt; "{{item}}" is replaced by each item, else the item is appended`)
+	path := fs.String("path", "", "File (one item per line) or directory (one item per file) in a shared folder")
+	device := fs.String("device", "", "Device holding --path (default: the server)")
+	glob := fs.String("glob", "", `Directory only: file name filter, e.g. "*.md"`)
+	chunk := fs.Int("chunk", 0, "Max items per task (0 = 8 for LLM_GENERATE, 256 for EMBED)")
+	out := fs.String("out", "", "Write the JSON lines result to this file instead of stdout")
+	wait := fs.Duration("wait", 30*time.Minute, "How long to wait for the job")
+	var items arrayFlags
+	fs.Var(&items, "item", "Inline item (repeatable)")
+	var priority priorityFlag
+	fs.Var(&priority, "priority", "low, normal, high, interactive or a number; higher runs first")
+	fs.Parse(args)
+
+	if *path == "" && len(items) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: --path or --item is required for map")
+		os.Exit(1)
+	}
+	if key == "" {
+		fmt.Fprintln(os.Stderr, "Error: --key is required for map")
+		os.Exit(1)
+	}
+
+	hostname, _ := os.Hostname()
+	sessionResp, err := client.CreateSession(ctx, &pb.AuthRequest{
+		DeviceName:  hostname,
+		SecurityKey: key,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating session: %v\n", err)
+		os.Exit(1)
+	}
+
+	resp, err := client.SubmitJob(ctx, &pb.JobRequest{
+		SessionId: sessionResp.SessionId,
+		Text:      fmt.Sprintf("map %s", *kind),
+		Map: &pb.MapSpec{
+			Kind:       *kind,
+			Prompt:     *prompt,
+			Items:      items,
+			DeviceId:   *device,
+			Path:       *path,
+			Glob:       *glob,
+			ChunkItems: int32(*chunk),
+		},
+		Priority: int32(priority),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error submitting job: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "Job %s: %s\n", resp.JobId, resp.Summary)
+
+	job := waitForJob(client, resp.JobId, *wait)
+
+	// Count failed items from the result lines
+	failed := 0
+	for _, line := range strings.Split(strings.TrimSpace(job.FinalResult), "\n") {
+		var item struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal([]byte(line), &item) == nil && item.Error != "" {
+			failed++
+		}
+	}
+
+	if *out != "" {
+		if err := os.WriteFile(*out, []byte(job.FinalResult), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", *out, err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %s\n", *out)
+	} else {
+		fmt.Print(job.FinalResult)
+	}
+
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "%d item(s) failed\n", failed)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+// jobPollInterval is how often run-all and map check their job
+const jobPollInterval = 500 * time.Millisecond
+
+func handleRunAll(ctx context.Context, client pb.OrchestratorServiceClient, key string, args []string) {
+	fs := flag.NewFlagSet("run-all", flag.ExitOnError)
+	cmdLine := fs.String("cmd", "", "Command line to run on every matching device (required)")
+	kind := fs.String("kind", "SHELL", "Task kind to fan out")
+	wait := fs.Duration("wait", 2*time.Minute, "How long to wait for all devices")
+	var platforms, capabilities arrayFlags
+	fs.Var(&platforms, "platform", "Only devices on this platform, e.g. linux or macos (repeatable)")
+	fs.Var(&capabilities, "capability", "Only devices with this capability: gpu, npu, local_model, screen_capture (repeatable)")
+	fs.Parse(args)
+
+	if *cmdLine == "" {
+		fmt.Fprintln(os.Stderr, "Error: --cmd is required for run-all")
+		os.Exit(1)
+	}
+	if key == "" {
+		fmt.Fprintln(os.Stderr, "Error: --key is required for run-all")
+		os.Exit(1)
+	}
+
+	hostname, _ := os.Hostname()
+	sessionResp, err := client.CreateSession(ctx, &pb.AuthRequest{
+		DeviceName:  hostname,
+		SecurityKey: key,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating session: %v\n", err)
+		os.Exit(1)
+	}
+
+	resp, err := client.SubmitJob(ctx, &pb.JobRequest{
+		SessionId: sessionResp.SessionId,
+		Text:      *cmdLine,
+		FanOut: &pb.FanOut{
+			Kind:         *kind,
+			Input:        *cmdLine,
+			Platforms:    platforms,
+			Capabilities: capabilities,
+		},
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error submitting job: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Job %s: %s\n", resp.JobId, resp.Summary)
+
+	job := waitForJob(client, resp.JobId, *wait)
+
+	failed := 0
+	for _, t := range job.Tasks {
+		if t.State != "DONE" {
+			failed++
+		}
+		printRunAllTask(t)
+	}
+
+	fmt.Printf("\n%d/%d device(s) succeeded\n", len(job.Tasks)-failed, len(job.Tasks))
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// printRunAllTask prints one device's result of a run-all job
+func printRunAllTask(t *pb.TaskStatus) {
+	name := t.AssignedDeviceName
+	if name == "" {
+		name = t.AssignedDeviceId
+	}
+
+	sr := t.Shell
+	if sr == nil {
+		fmt.Printf("\n== %s: %s\n", name, t.State)
+		if t.Error != "" {
+			fmt.Printf("error: %s\n", t.Error)
+		}
+		if t.Result != "" {
+			fmt.Println(strings.TrimRight(t.Result, "\n"))
+		}
+		return
+	}
+
+	timedOut := ""
+	if sr.TimedOut {
+		timedOut = ", timed out"
+	}
+	fmt.Printf("\n== %s: exit %d (%.0fms%s)\n", name, sr.ExitCode, sr.DurationMs, timedOut)
+	if sr.StdoutTail != "" {
+		fmt.Println(strings.TrimRight(sr.StdoutTail, "\n"))
+	}
+	if sr.StderrTail != "" {
+		fmt.Println("-- stderr:")
+		fmt.Println(strings.TrimRight(sr.StderrTail, "\n"))
+	}
+	if sr.ExitCode < 0 && t.Error != "" {
+		fmt.Printf("error: %s\n", t.Error)
+	}
+}
+
+// waitForJob polls a job until it is DONE or FAILED, exiting if that takes
+// longer than wait. The global context times out after 30s, so waiting has
+// its own deadline.
+func waitForJob(client pb.OrchestratorServiceClient, jobID string, wait time.Duration) *pb.JobStatus {
+	ctx, cancel := context.WithTimeout(context.Background(), wait)
+	defer cancel()
+
+	for {
+		job, err := client.GetJob(ctx, &pb.JobId{JobId: jobID})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting job: %v\n", err)
+			os.Exit(1)
+		}
+		if job.State == "DONE" || job.State == "FAILED" {
+			return job
+		}
+		select {
+		case <-ctx.Done():
+			fmt.Fprintf(os.Stderr, "Timed out after %s waiting for job %s (state %s)\n", wait, jobID, job.State)
+			os.Exit(1)
+		case <-time.After(jobPollInterval):
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+// newSession creates a session or exits
+func newSession(ctx context.Context, client pb.OrchestratorServiceClient, key, command string) string {
+	if key == "" {
+		fmt.Fprintf(os.Stderr, "Error: --key is required for %s\n", command)
+		os.Exit(1)
+	}
+	hostname, _ := os.Hostname()
+	sessionResp, err := client.CreateSession(ctx, &pb.AuthRequest{
+		DeviceName:  hostname,
+		SecurityKey: key,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating session: %v\n", err)
+		os.Exit(1)
+	}
+	return sessionResp.SessionId
+}
+
+func handleCreateSchedule(ctx context.Context, client pb.OrchestratorServiceClient, key string, args []string) {
+	fs := flag.NewFlagSet("create-schedule", flag.ExitOnError)
+	name := fs.String("name", "", "Schedule name")
+	cron := fs.String("cron", "", `Cron expression, e.g. "0 * * * *" or @daily (required)`)
+	tz := fs.String("tz", "", "IANA time zone, e.g. Europe/Berlin (default: the server's)")
+	text := fs.String("text", "", "Job description, planned on every run")
+	maxWorkers := fs.Int("max-workers", 0, "Max devices to use (0 = all)")
+	planFile := fs.String("plan", "", "Plan JSON file to run instead of planning from --text")
+	kind := fs.String("fan-out", "", "Run this task kind on every device instead, e.g. SYSINFO")
+	input := fs.String("input", "", "Input for --fan-out tasks")
+	missed := fs.String("missed", "run_once", "Runs missed while the server was down: run_once or skip")
+	overlap := fs.Bool("allow-overlap", false, "Run even if the previous run's job is still going")
+	var priority priorityFlag
+	fs.Var(&priority, "priority", "low, normal, high, interactive or a number; higher runs first")
+	fs.Parse(args)
+
+	if *cron == "" {
+		fmt.Fprintln(os.Stderr, "Error: --cron is required")
+		os.Exit(1)
+	}
+	job := &pb.JobRequest{Text: *text, MaxWorkers: int32(*maxWorkers), Priority: int32(priority)}
+	if *kind != "" {
+		job.FanOut = &pb.FanOut{Kind: *kind, Input: *input}
+	}
+	if *planFile != "" {
+		data, err := os.ReadFile(*planFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading plan file: %v\n", err)
+			os.Exit(1)
+		}
+		job.Plan = &pb.Plan{}
+		if err := protojson.Unmarshal(data, job.Plan); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing plan: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	sc, err := client.CreateSchedule(ctx, &pb.CreateScheduleRequest{
+		SessionId:    newSession(ctx, client, key, "create-schedule"),
+		Name:         *name,
+		Cron:         *cron,
+		Timezone:     *tz,
+		Job:          job,
+		MissedPolicy: *missed,
+		AllowOverlap: *overlap,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating schedule: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Schedule created: %s (%s)\n", sc.ScheduleId, sc.Name)
+	fmt.Printf("Next run: %s\n", time.UnixMilli(sc.NextRunMs).Format(time.RFC3339))
+}
+
+func handleListSchedules(ctx context.Context, client pb.OrchestratorServiceClient, key string, args []string) {
+	fs := flag.NewFlagSet("list-schedules", flag.ExitOnError)
+	runs := fs.Int("runs", 3, "Recent runs to show per schedule")
+	fs.Parse(args)
+
+	resp, err := client.ListSchedules(ctx, &pb.ListSchedulesRequest{
+		SessionId: newSession(ctx, client, key, "list-schedules"),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing schedules: %v\n", err)
+		os.Exit(1)
+	}
+	if len(resp.Schedules) == 0 {
+		fmt.Println("No schedules.")
+		return
+	}
+	for _, sc := range resp.Schedules {
+		tz := sc.Timezone
+		if tz == "" {
+			tz = "server time"
+		}
+		fmt.Printf("%s  %s  %q (%s)  missed=%s", sc.ScheduleId, sc.Name, sc.Cron, tz, sc.MissedPolicy)
+		if sc.AllowOverlap {
+			fmt.Print("  overlap allowed")
+		}
+		fmt.Printf("\n  next run: %s\n", time.UnixMilli(sc.NextRunMs).Format(time.RFC3339))
+		start := max(len(sc.Runs)-*runs, 0)
+		for _, r := range sc.Runs[start:] {
+			fmt.Printf("  - %s %-8s %s %s\n", time.UnixMilli(r.DueMs).Format(time.RFC3339), r.Status, r.JobId, r.Detail)
+		}
+	}
+}
+
+func handleDeleteSchedule(ctx context.Context, client pb.OrchestratorServiceClient, key string, args []string) {
+	fs := flag.NewFlagSet("delete-schedule", flag.ExitOnError)
+	id := fs.String("id", "", "Schedule ID (required)")
+	fs.Parse(args)
+
+	if *id == "" {
+		fmt.Fprintln(os.Stderr, "Error: --id is required")
+		os.Exit(1)
+	}
+	_, err := client.DeleteSchedule(ctx, &pb.DeleteScheduleRequest{
+		SessionId:  newSession(ctx, client, key, "delete-schedule"),
+		ScheduleId: *id,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error deleting schedule: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Schedule %s deleted\n", *id)
+}
@@ -0,0 +1,470 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/edgecli/edgecli/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const (
+	// inlineUploadMaxBytes is the largest file sent via PutFile instead of
+	// an upload ticket.
+	inlineUploadMaxBytes = 4 * 1024 * 1024
+	defaultBulkPort      = "8081"
+	maxChunkRetries      = 5
+)
+
+func handleUpload(ctx context.Context, client pb.OrchestratorServiceClient, key, addr string, args []string) {
+	// Parse upload specific flags
+	fs := flag.NewFlagSet("upload", flag.ExitOnError)
+	localFile := fs.String("file", "", "Local file to upload (required)")
+	remotePath := fs.String("path", "", "Destination path under the device's shared root (default: file basename)")
+	deviceID := fs.String("device", "", "Target device ID (default: the server at --addr)")
+	overwrite := fs.Bool("overwrite", false, "Replace an existing file")
+	bulkAddr := fs.String("bulk-addr", "", "Bulk HTTP address (default: host of --addr, port "+defaultBulkPort+")")
+	chunkMB := fs.Int("chunk-mb", 0, "Chunk size in MB (default: server suggestion)")
+	token := fs.String("token", "", "Resume an existing upload ticket")
+	fs.Parse(args)
+
+	if *localFile == "" {
+		fmt.Fprintln(os.Stderr, "Error: --file is required for upload")
+		os.Exit(1)
+	}
+	if *remotePath == "" {
+		*remotePath = filepath.Base(*localFile)
+	}
+
+	f, err := os.Open(*localFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening file: %v\n", err)
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading file info: %v\n", err)
+		os.Exit(1)
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		fmt.Fprintf(os.Stderr, "Error hashing file: %v\n", err)
+		os.Exit(1)
+	}
+	digest := hex.EncodeToString(h.Sum(nil))
+
+	// Small files go inline through PutFile, which the server forwards to --device.
+	if info.Size() <= inlineUploadMaxBytes && *token == "" {
+		if key == "" {
+			fmt.Fprintln(os.Stderr, "Error: --key is required for upload")
+			os.Exit(1)
+		}
+		content, err := os.ReadFile(*localFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading file: %v\n", err)
+			os.Exit(1)
+		}
+
+		hostname, _ := os.Hostname()
+		sessionResp, err := client.CreateSession(ctx, &pb.AuthRequest{
+			DeviceName:  hostname,
+			SecurityKey: key,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating session: %v\n", err)
+			os.Exit(1)
+		}
+
+		resp, err := client.PutFile(ctx, &pb.PutFileRequest{
+			SessionId: sessionResp.SessionId,
+			DeviceId:  *deviceID,
+			Path:      *remotePath,
+			Content:   content,
+			Sha256:    digest,
+			Overwrite: *overwrite,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error uploading file: %v\n", err)
+			os.Exit(1)
+		}
+		if resp.Error != "" {
+			fmt.Fprintf(os.Stderr, "Error uploading file: %s\n", resp.Error)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Uploaded %s -> %s (%d bytes)\n", *localFile, resp.Path, resp.SizeBytes)
+		fmt.Printf("SHA-256: %s\n", resp.Sha256)
+		return
+	}
+
+	// Large files: ticket on the target device, then chunked PUTs over bulk HTTP.
+	targetClient, httpAddr, closeTarget := resolveTransferTarget(ctx, client, *deviceID, addr, *bulkAddr)
+	defer closeTarget()
+
+	uploadToken := *token
+	chunkSize := int64(*chunkMB) * 1024 * 1024
+	if uploadToken == "" {
+		ticket, err := targetClient.CreateUploadTicket(ctx, &pb.UploadTicketRequest{
+			SessionId: newSession(ctx, targetClient, key, "upload"),
+			Path:      *remotePath,
+			SizeBytes: info.Size(),
+			Sha256:    digest,
+			Overwrite: *overwrite,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating upload ticket: %v\n", err)
+			os.Exit(1)
+		}
+		uploadToken = ticket.Token
+		if chunkSize <= 0 {
+			chunkSize = ticket.ChunkSizeBytes
+		}
+		fmt.Printf("Upload ticket: %s\n", uploadToken)
+	}
+	if chunkSize <= 0 {
+		chunkSize = 8 * 1024 * 1024
+	}
+
+	uploadURL := fmt.Sprintf("http://%s/bulk/upload/%s", httpAddr, uploadToken)
+	sum, err := uploadChunks(uploadURL, f, info.Size(), chunkSize)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error uploading file: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Resume with: --token %s\n", uploadToken)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Uploaded %s -> %s (%d bytes)\n", *localFile, *remotePath, info.Size())
+	fmt.Printf("SHA-256: %s\n", sum)
+}
+
+func handleDownload(ctx context.Context, client pb.OrchestratorServiceClient, addr string, args []string) {
+	// Parse download specific flags
+	fs := flag.NewFlagSet("download", flag.ExitOnError)
+	remotePath := fs.String("path", "", "File path on the device (required)")
+	deviceID := fs.String("device", "", "Source device ID (default: the server at --addr)")
+	out := fs.String("out", "", "Local output file (default: remote basename)")
+	bulkAddr := fs.String("bulk-addr", "", "Bulk HTTP address (default: host of --addr, port "+defaultBulkPort+")")
+	fs.Parse(args)
+
+	if *remotePath == "" {
+		fmt.Fprintln(os.Stderr, "Error: --path is required for download")
+		os.Exit(1)
+	}
+
+	targetClient, httpAddr, closeTarget := resolveTransferTarget(ctx, client, *deviceID, addr, *bulkAddr)
+	defer closeTarget()
+
+	ticket, err := targetClient.CreateDownloadTicket(ctx, &pb.DownloadTicketRequest{Path: *remotePath})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating download ticket: %v\n", err)
+		os.Exit(1)
+	}
+	if *out == "" {
+		*out = ticket.Filename
+	}
+
+	if ticket.Live {
+		fmt.Printf("%s is still being written; following it until it is finished\n", *remotePath)
+	}
+	downloadURL := fmt.Sprintf("http://%s/bulk/download/%s", httpAddr, ticket.Token)
+	sum, err := downloadResumable(downloadURL, *out)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error downloading file: %v\n", err)
+		fmt.Fprintln(os.Stderr, "Partial data kept; run the same command again to resume")
+		os.Exit(1)
+	}
+
+	size := ticket.SizeBytes
+	if info, err := os.Stat(*out); err == nil {
+		size = info.Size()
+	}
+	fmt.Printf("Downloaded %s -> %s (%d bytes)\n", *remotePath, *out, size)
+	fmt.Printf("SHA-256: %s\n", sum)
+}
+
+// downloadResumable fetches url into out via out.part, resuming from the
+// partial file with Range/If-Range. The ETag of the partial data is kept in
+// out.part.etag so a changed source file restarts the download.
+func downloadResumable(url, out string) (string, error) {
+	partPath := out + ".part"
+	etagPath := partPath + ".etag"
+	httpClient := &http.Client{}
+	retries := 0
+
+	for {
+		var offset int64
+		if info, err := os.Stat(partPath); err == nil {
+			offset = info.Size()
+		}
+		etag := ""
+		if b, err := os.ReadFile(etagPath); err == nil {
+			etag = string(b)
+		}
+
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			return "", err
+		}
+		if offset > 0 && etag != "" {
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+			req.Header.Set("If-Range", etag)
+		}
+
+		resp, err := httpClient.Do(req)
+		if err == nil {
+			err = receiveDownload(resp, partPath, etagPath, offset)
+			if err == nil {
+				break
+			}
+		}
+
+		retries++
+		if retries > maxChunkRetries {
+			return "", fmt.Errorf("giving up after %d retries: %w", maxChunkRetries, err)
+		}
+		fmt.Printf("\n  transfer interrupted (%v), resuming...\n", err)
+		time.Sleep(time.Duration(retries) * time.Second)
+	}
+	fmt.Println()
+
+	// Verify the assembled file against the server's digest before publishing it.
+	sum, err := transferFileSHA256(partPath)
+	if err != nil {
+		return "", err
+	}
+	want, _ := os.ReadFile(etagPath)
+	if expected := strings.Trim(string(want), `"`); expected != "" && expected != sum {
+		os.Remove(partPath)
+		os.Remove(etagPath)
+		return "", fmt.Errorf("sha256 mismatch: expected %s, got %s", expected, sum)
+	}
+	if err := os.Rename(partPath, out); err != nil {
+		return "", err
+	}
+	os.Remove(etagPath)
+	return sum, nil
+}
+
+// receiveDownload writes one response body into the partial file.
+// A 206 appends at offset; a 200 means the server ignored or rejected the
+// range (file changed), so the partial file is replaced.
+func receiveDownload(resp *http.Response, partPath, etagPath string, offset int64) error {
+	defer resp.Body.Close()
+
+	var flags int
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		var start int64
+		if _, err := fmt.Sscanf(resp.Header.Get("Content-Range"), "bytes %d-", &start); err != nil || start != offset {
+			return fmt.Errorf("unexpected Content-Range %q", resp.Header.Get("Content-Range"))
+		}
+		flags = os.O_WRONLY | os.O_APPEND
+	case http.StatusOK:
+		if offset > 0 {
+			fmt.Println("  source changed or range unsupported, restarting")
+		}
+		offset = 0
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		if err := os.WriteFile(etagPath, []byte(resp.Header.Get("ETag")), 0644); err != nil {
+			return err
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		// Partial file is already complete (or longer than the source).
+		if offset > 0 {
+			return nil
+		}
+		return fmt.Errorf("server returned %s", resp.Status)
+	default:
+		io.Copy(io.Discard, resp.Body)
+		return fmt.Errorf("server returned %s", resp.Status)
+	}
+
+	f, err := os.OpenFile(partPath, flags, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	total := offset + resp.ContentLength
+	pr := &progressReader{r: resp.Body, done: offset, total: total}
+	if _, err := io.Copy(f, pr); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// progressReader prints transfer progress as bytes are read.
+type progressReader struct {
+	r           io.Reader
+	done, total int64
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.done += int64(n)
+	if p.total > 0 {
+		fmt.Printf("\r  %d/%d bytes (%d%%)", p.done, p.total, p.done*100/p.total)
+	}
+	return n, err
+}
+
+// transferFileSHA256 hashes a local file.
+func transferFileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// resolveTransferTarget returns a client and bulk HTTP address for deviceID,
+// or for the server at addr when deviceID is empty.
+func resolveTransferTarget(ctx context.Context, client pb.OrchestratorServiceClient, deviceID, addr, bulkAddr string) (pb.OrchestratorServiceClient, string, func()) {
+	if deviceID == "" {
+		if bulkAddr == "" {
+			bulkAddr = defaultBulkAddr(addr)
+		}
+		return client, bulkAddr, func() {}
+	}
+
+	devicesResp, err := client.ListDevices(ctx, &pb.ListDevicesRequest{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing devices: %v\n", err)
+		os.Exit(1)
+	}
+	var target *pb.DeviceInfo
+	for _, d := range devicesResp.Devices {
+		if d.DeviceId == deviceID {
+			target = d
+			break
+		}
+	}
+	if target == nil {
+		fmt.Fprintf(os.Stderr, "Error: device not found: %s\n", deviceID)
+		os.Exit(1)
+	}
+
+	conn, err := grpc.DialContext(ctx, target.GrpcAddr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithBlock(),
+	)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error connecting to device: %v\n", err)
+		os.Exit(1)
+	}
+	if bulkAddr == "" {
+		bulkAddr = target.HttpAddr
+	}
+	if bulkAddr == "" {
+		bulkAddr = defaultBulkAddr(target.GrpcAddr)
+	}
+	return pb.NewOrchestratorServiceClient(conn), bulkAddr, func() { conn.Close() }
+}
+
+// uploadChunks sends f to uploadURL in chunks, asking the server for its
+// offset before each attempt so interrupted uploads resume where they stopped.
+func uploadChunks(uploadURL string, f *os.File, size, chunkSize int64) (string, error) {
+	httpClient := &http.Client{Timeout: 10 * time.Minute}
+	retries := 0
+
+	for {
+		offset, complete, sum, err := uploadOffset(httpClient, uploadURL)
+		if err != nil {
+			return "", err
+		}
+		if complete {
+			fmt.Println()
+			return sum, nil
+		}
+
+		n := chunkSize
+		if offset+n > size {
+			n = size - offset
+		}
+
+		req, err := http.NewRequest(http.MethodPut, uploadURL, io.NewSectionReader(f, offset, n))
+		if err != nil {
+			return "", err
+		}
+		req.ContentLength = n
+		req.Header.Set("Content-Type", "application/octet-stream")
+		req.Header.Set("Upload-Offset", strconv.FormatInt(offset, 10))
+
+		resp, err := httpClient.Do(req)
+		if err == nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			switch {
+			case resp.StatusCode == http.StatusOK:
+				retries = 0
+				fmt.Printf("\r  %d/%d bytes (%d%%)", offset+n, size, (offset+n)*100/max(size, 1))
+				continue
+			case resp.StatusCode == http.StatusConflict:
+				// Server has a different offset; re-query and continue from
+				// there. Repeated conflicts count as failures, so a competing
+				// writer cannot keep us looping.
+				err = fmt.Errorf("server returned %s", resp.Status)
+			case resp.StatusCode >= 500:
+				err = fmt.Errorf("server returned %s", resp.Status)
+			default:
+				return "", fmt.Errorf("server returned %s", resp.Status)
+			}
+		}
+
+		retries++
+		if retries > maxChunkRetries {
+			return "", fmt.Errorf("chunk at offset %d failed after %d retries: %w", offset, maxChunkRetries, err)
+		}
+		fmt.Printf("\n  chunk at offset %d failed (%v), retrying...\n", offset, err)
+		time.Sleep(time.Duration(retries) * time.Second)
+	}
+}
+
+// uploadOffset queries the server for how many bytes it has accepted.
+func uploadOffset(httpClient *http.Client, uploadURL string) (int64, bool, string, error) {
+	resp, err := httpClient.Head(uploadURL)
+	if err != nil {
+		return 0, false, "", err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, false, "", fmt.Errorf("upload status: %s", resp.Status)
+	}
+
+	offset, err := strconv.ParseInt(resp.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil {
+		return 0, false, "", fmt.Errorf("invalid Upload-Offset header: %w", err)
+	}
+	sum := resp.Header.Get("X-Content-SHA256")
+	return offset, sum != "", sum, nil
+}
+
+// defaultBulkAddr derives the bulk HTTP address from the gRPC address host.
+func defaultBulkAddr(grpcAddr string) string {
+	host, _, err := net.SplitHostPort(grpcAddr)
+	if err != nil || host == "" {
+		host = "localhost"
+	}
+	return net.JoinHostPort(host, defaultBulkPort)
+}
@@ -0,0 +1,202 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/edgecli/edgecli/internal/bench"
+	"github.com/edgecli/edgecli/internal/llm"
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+var (
+	benchCmd = &cobra.Command{
+		Use:   "bench",
+		Short: "Benchmark LLM prefill and decode throughput",
+		Long: `Run a standard prompt set against the chat provider (CHAT_PROVIDER,
+CHAT_MODEL, ...) and measure prefill and decode throughput per model.
+
+Results are stored in ~/.edgemesh/bench.json (or BENCH_FILE). A server on
+this machine advertises them to the mesh within a minute.
+
+With --server the benchmark runs on that server, or on --device through it.`,
+		RunE: runBench,
+	}
+
+	benchModels []string
+	benchRuns   int
+	benchServer string
+	benchDevice string
+	benchKey    string
+	benchCached bool
+	benchJSON   bool
+)
+
+func init() {
+	benchCmd.Flags().StringArrayVar(&benchModels, "model", nil, "Model to benchmark (repeatable; default CHAT_MODEL)")
+	benchCmd.Flags().IntVar(&benchRuns, "runs", 1, "Passes over the prompt set")
+	benchCmd.Flags().StringVar(&benchServer, "server", "", "Run on the server at this gRPC address instead of locally")
+	benchCmd.Flags().StringVar(&benchDevice, "device", "", "With --server, the device to benchmark")
+	benchCmd.Flags().StringVar(&benchKey, "key", "dev", "With --server, the security key")
+	benchCmd.Flags().BoolVar(&benchCached, "cached", false, "Show stored results instead of running")
+	benchCmd.Flags().BoolVar(&benchJSON, "json", false, "Print results as JSON")
+	rootCmd.AddCommand(benchCmd)
+}
+
+func runBench(cmd *cobra.Command, args []string) error {
+	var results []*pb.LLMBenchmark
+	var err error
+	if benchServer != "" {
+		results, err = benchRemote(cmd.Context())
+	} else {
+		if benchDevice != "" {
+			return fmt.Errorf("--device needs --server")
+		}
+		results, err = benchLocal(cmd.Context())
+	}
+	if err != nil {
+		return err
+	}
+
+	if benchJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			return err
+		}
+	} else {
+		printBenchResults(results)
+	}
+
+	if n := countBenchErrors(results); n > 0 {
+		return fmt.Errorf("%d of %d benchmark(s) failed", n, len(results))
+	}
+	return nil
+}
+
+// benchLocal benchmarks this machine's chat provider and stores the results
+func benchLocal(ctx context.Context) ([]*pb.LLMBenchmark, error) {
+	_ = godotenv.Load() // same .env the server reads, if present
+
+	path := os.Getenv("BENCH_FILE")
+	if path == "" {
+		var err error
+		if path, err = bench.DefaultPath(); err != nil {
+			return nil, err
+		}
+	}
+	store, err := bench.OpenStore(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := llm.ChatConfigFromEnv()
+	models := benchModels
+	if len(models) == 0 {
+		models = []string{cfg.Model}
+	}
+
+	var results []*pb.LLMBenchmark
+	for _, model := range models {
+		var r *bench.Result
+		if benchCached {
+			var ok bool
+			if r, ok = store.Get(cfg.Provider, model); !ok {
+				results = append(results, &pb.LLMBenchmark{Provider: cfg.Provider, Model: model, Error: "no stored benchmark"})
+				continue
+			}
+		} else {
+			if !benchJSON {
+				fmt.Fprintf(os.Stderr, "Benchmarking %s/%s ...\n", cfg.Provider, model)
+			}
+			if r, err = bench.RunModel(ctx, cfg, model, benchRuns); err != nil {
+				results = append(results, &pb.LLMBenchmark{Provider: cfg.Provider, Model: model, Error: err.Error()})
+				continue
+			}
+			if err := store.Put(r); err != nil {
+				return nil, fmt.Errorf("store result: %w", err)
+			}
+		}
+		results = append(results, &pb.LLMBenchmark{
+			Provider:         r.Provider,
+			Model:            r.Model,
+			PrefillToksPerS:  r.PrefillTPS,
+			DecodeToksPerS:   r.DecodeTPS,
+			PromptTokens:     int64(r.PromptTokens),
+			OutputTokens:     int64(r.OutputTokens),
+			Method:           r.Method,
+			MeasuredAtUnixMs: r.MeasuredAt.UnixMilli(),
+			DurationMs:       r.Duration.Milliseconds(),
+		})
+	}
+	return results, nil
+}
+
+// benchRemote asks a server to benchmark itself or one of its devices
+func benchRemote(ctx context.Context) ([]*pb.LLMBenchmark, error) {
+	dialCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(dialCtx, benchServer,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithBlock(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("connect to %s: %w", benchServer, err)
+	}
+	defer conn.Close()
+	client := pb.NewOrchestratorServiceClient(conn)
+
+	hostname, _ := os.Hostname()
+	session, err := client.CreateSession(ctx, &pb.AuthRequest{DeviceName: hostname, SecurityKey: benchKey})
+	if err != nil {
+		return nil, fmt.Errorf("create session: %w", err)
+	}
+
+	if !benchJSON && !benchCached {
+		fmt.Fprintf(os.Stderr, "Benchmarking on %s ...\n", benchServer)
+	}
+	resp, err := client.Benchmark(ctx, &pb.BenchmarkRequest{
+		SessionId: session.SessionId,
+		DeviceId:  benchDevice,
+		Models:    benchModels,
+		Runs:      int32(benchRuns),
+		Cached:    benchCached,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Results, nil
+}
+
+func printBenchResults(results []*pb.LLMBenchmark) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROVIDER\tMODEL\tPREFILL tok/s\tDECODE tok/s\tMETHOD\tMEASURED")
+	for _, r := range results {
+		if r.Error != "" {
+			fmt.Fprintf(w, "%s\t%s\t-\t-\t-\terror: %s\n", r.Provider, r.Model, r.Error)
+			continue
+		}
+		measured := time.UnixMilli(r.MeasuredAtUnixMs).Format("2006-01-02 15:04")
+		fmt.Fprintf(w, "%s\t%s\t%.1f\t%.1f\t%s\t%s\n", r.Provider, r.Model, r.PrefillToksPerS, r.DecodeToksPerS, r.Method, measured)
+	}
+	w.Flush()
+}
+
+func countBenchErrors(results []*pb.LLMBenchmark) int {
+	n := 0
+	for _, r := range results {
+		if r.Error != "" {
+			n++
+		}
+	}
+	return n
+}
@@ -324,6 +324,7 @@ func printChatHelp() {
 	fmt.Printf("  %s - Discover devices in the mesh\n", ui.Color(ui.Green, "get_capabilities"))
 	fmt.Printf("  %s - Run shell commands on devices\n", ui.Color(ui.Green, "execute_shell_cmd"))
 	fmt.Printf("  %s - Read files from devices\n", ui.Color(ui.Green, "get_file"))
+	fmt.Printf("  %s - Browse shared folders on devices\n", ui.Color(ui.Green, "list_files"))
 	fmt.Println()
 	fmt.Println(ui.Color(ui.Bold, "Examples:"))
 	fmt.Println(ui.RenderDim("  \"list all devices\""))
@@ -363,4 +364,14 @@ func printChatTools() {
 	fmt.Println(ui.RenderDim("     - read_mode (string): \"full\", \"head\", \"tail\", or \"range\""))
 	fmt.Println(ui.RenderDim("     - max_bytes (int): Maximum bytes to read (default: 65536)"))
 	fmt.Println()
+
+	// Tool 4: list_files
+	fmt.Println(ui.Color(ui.Bold, "4. list_files"))
+	fmt.Println("   List a directory in a device's shared folder")
+	fmt.Println(ui.RenderDim("   Parameters:"))
+	fmt.Println(ui.RenderDim("     - device_id (string): Target device"))
+	fmt.Println(ui.RenderDim("     - path (string): Directory relative to the shared folder"))
+	fmt.Println(ui.RenderDim("     - glob (string): Filter names, e.g. \"*.csv\""))
+	fmt.Println(ui.RenderDim("     - page_size (int), page_token (string): Pagination"))
+	fmt.Println()
 }
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+
+	"github.com/edgecli/edgecli/internal/alerts"
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+// openAlerts builds the alerting engine. Rules come from ALERT_RULES or
+// ~/.edgemesh/alerts.json, else the defaults. Alerts go to this device's
+// chat memory (unless ALERT_CHAT=false), the ALERT_LOG file (default
+// ~/.edgemesh/alerts.jsonl), ALERT_WEBHOOK_URL if set, and WatchAlerts.
+func (s *OrchestratorServer) openAlerts() *alerts.Engine {
+	rules := alerts.DefaultRules()
+	path := os.Getenv("ALERT_RULES")
+	if path == "" {
+		path, _ = alerts.DefaultRulesPath()
+	}
+	if path != "" {
+		loaded, err := alerts.LoadRules(path)
+		if err != nil {
+			log.Printf("[WARN] Alert rules not loaded, using the defaults: %v", err)
+		} else {
+			rules = loaded
+		}
+	}
+
+	var sinks []alerts.Sink
+	if v := strings.ToLower(os.Getenv("ALERT_CHAT")); v != "false" && v != "0" {
+		sinks = append(sinks, alerts.SinkFunc(s.alertToChat))
+	}
+	logPath := os.Getenv("ALERT_LOG")
+	if logPath == "" {
+		var err error
+		if logPath, err = alerts.DefaultLogPath(); err != nil {
+			log.Printf("[WARN] Alert log disabled: %v", err)
+		}
+	}
+	if logPath != "" {
+		if l, err := alerts.OpenLog(logPath); err != nil {
+			log.Printf("[WARN] Alert log disabled: %v", err)
+		} else {
+			sinks = append(sinks, l)
+		}
+	}
+	if url := os.Getenv("ALERT_WEBHOOK_URL"); url != "" {
+		sinks = append(sinks, alerts.NewWebhook(url))
+	}
+
+	log.Printf("[INFO] Alerting: %d rule(s), %d sink(s)", len(rules), len(sinks))
+	return alerts.NewEngine(rules, s.metricsStore, s.alertDevices, sinks...)
+}
+
+// alertDevices lists the registered devices and when each was last seen
+func (s *OrchestratorServer) alertDevices() []alerts.Device {
+	infos := s.registry.List()
+	out := make([]alerts.Device, 0, len(infos))
+	for _, info := range infos {
+		entry, ok := s.registry.Get(info.DeviceId)
+		if !ok {
+			continue
+		}
+		out = append(out, alerts.Device{ID: info.DeviceId, Name: info.DeviceName, LastSeen: entry.LastSeen})
+	}
+	return out
+}
+
+// alertToChat adds an alert to this device's chat memory as a system message
+func (s *OrchestratorServer) alertToChat(ctx context.Context, a alerts.Alert) error {
+	s.AddChatMessage(s.selfDeviceID, s.selfDeviceID, "system", "Alert ("+a.Severity+", "+a.State+"): "+a.Message)
+	return nil
+}
+
+// WatchAlerts streams alerts as they fire and resolve until the client
+// cancels
+func (s *OrchestratorServer) WatchAlerts(req *pb.WatchAlertsRequest, stream grpc.ServerStreamingServer[pb.Alert]) error {
+	if err := s.checkSession("WatchAlerts", req.SessionId); err != nil {
+		return err
+	}
+
+	ch, cancel := s.alertEngine.Subscribe()
+	defer cancel()
+	if req.IncludeFiring {
+		for _, a := range s.alertEngine.Firing() {
+			if err := stream.Send(toPbAlert(a)); err != nil {
+				return err
+			}
+		}
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case a := <-ch:
+			if err := stream.Send(toPbAlert(a)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func toPbAlert(a alerts.Alert) *pb.Alert {
+	return &pb.Alert{
+		Id:         a.ID,
+		Rule:       a.Rule,
+		Kind:       string(a.Kind),
+		Severity:   a.Severity,
+		State:      a.State,
+		DeviceId:   a.DeviceID,
+		DeviceName: a.DeviceName,
+		JobId:      a.JobID,
+		Value:      a.Value,
+		Message:    a.Message,
+		SinceMs:    a.Since.UnixMilli(),
+		TimeMs:     a.Time.UnixMilli(),
+	}
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/edgecli/edgecli/internal/bench"
+	"github.com/edgecli/edgecli/internal/llm"
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+const (
+	// defaultBenchInterval is how old the benchmark may get before an idle
+	// device runs it again
+	defaultBenchInterval = 24 * time.Hour
+	// benchCheckInterval is how often the device checks whether to re-benchmark
+	benchCheckInterval = time.Minute
+	// benchIdleAfter is how long without tasks before the device counts as idle
+	benchIdleAfter = 2 * time.Minute
+	// benchRetryAfter is how long to wait after a failed periodic benchmark
+	benchRetryAfter = time.Hour
+)
+
+// openBenchStore opens the benchmark results at BENCH_FILE, or
+// ~/.edgemesh/bench.json. Returns nil if they cannot be opened.
+func openBenchStore() *bench.Store {
+	path := os.Getenv("BENCH_FILE")
+	if path == "" {
+		var err error
+		if path, err = bench.DefaultPath(); err != nil {
+			log.Printf("[WARN] LLM benchmarks will not be stored: %v", err)
+			return nil
+		}
+	}
+	store, err := bench.OpenStore(path)
+	if err != nil {
+		log.Printf("[WARN] LLM benchmarks will not be stored: %v", err)
+		return nil
+	}
+	return store
+}
+
+// selfBenchmark returns the stored benchmark of the configured chat model
+func (s *OrchestratorServer) selfBenchmark() (*bench.Result, bool) {
+	if s.benchStore == nil {
+		return nil, false
+	}
+	cfg := llm.ChatConfigFromEnv()
+	return s.benchStore.Get(cfg.Provider, cfg.Model)
+}
+
+// applyBenchmark sets info's LLM throughput from the stored benchmark
+func (s *OrchestratorServer) applyBenchmark(info *pb.DeviceInfo) {
+	if r, ok := s.selfBenchmark(); ok {
+		info.LlmPrefillToksPerS = r.PrefillTPS
+		info.LlmDecodeToksPerS = r.DecodeTPS
+	}
+}
+
+// publishBenchmark advertises the stored benchmark through the registry and
+// discovery. The coordinator picks it up at the next re-registration.
+func (s *OrchestratorServer) publishBenchmark() {
+	r, ok := s.selfBenchmark()
+	if !ok {
+		return
+	}
+	if entry, ok := s.registry.Get(s.selfDeviceID); ok &&
+		(entry.Info.LlmPrefillToksPerS != r.PrefillTPS || entry.Info.LlmDecodeToksPerS != r.DecodeTPS) {
+		info := proto.Clone(entry.Info).(*pb.DeviceInfo)
+		info.LlmPrefillToksPerS = r.PrefillTPS
+		info.LlmDecodeToksPerS = r.DecodeTPS
+		s.registry.Upsert(info)
+		log.Printf("[INFO] LLM benchmark: advertising %s/%s prefill=%.1f decode=%.1f tok/s",
+			r.Provider, r.Model, r.PrefillTPS, r.DecodeTPS)
+	}
+	if s.discoverySvc != nil {
+		s.discoverySvc.SetLLMThroughput(r.PrefillTPS, r.DecodeTPS)
+	}
+}
+
+// runBenchmarks benchmarks each model with this device's chat provider and
+// stores the results. Only one benchmark runs at a time.
+func (s *OrchestratorServer) runBenchmarks(ctx context.Context, models []string, runs int) ([]*pb.LLMBenchmark, error) {
+	if !s.benchMu.TryLock() {
+		return nil, errBenchmarkRunning
+	}
+	defer s.benchMu.Unlock()
+
+	cfg := llm.ChatConfigFromEnv()
+	results := make([]*pb.LLMBenchmark, 0, len(models))
+	for _, model := range models {
+		log.Printf("[INFO] LLM benchmark: running %s/%s (%d run(s))", cfg.Provider, model, max(runs, 1))
+		r, err := bench.RunModel(ctx, cfg, model, runs)
+		if err != nil {
+			log.Printf("[WARN] LLM benchmark: %s/%s failed: %v", cfg.Provider, model, err)
+			results = append(results, &pb.LLMBenchmark{
+				DeviceId: s.selfDeviceID,
+				Provider: cfg.Provider,
+				Model:    model,
+				Error:    err.Error(),
+			})
+			continue
+		}
+		log.Printf("[INFO] LLM benchmark: %s/%s prefill=%.1f decode=%.1f tok/s (%s, %s)",
+			r.Provider, r.Model, r.PrefillTPS, r.DecodeTPS, r.Method, r.Duration.Round(time.Millisecond))
+		if s.benchStore != nil {
+			if err := s.benchStore.Put(r); err != nil {
+				log.Printf("[WARN] LLM benchmark: could not store result: %v", err)
+			}
+		}
+		results = append(results, s.toPbBenchmark(r))
+	}
+
+	s.publishBenchmark()
+	return results, nil
+}
+
+var errBenchmarkRunning = errors.New("a benchmark is already running on this device")
+
+func (s *OrchestratorServer) toPbBenchmark(r *bench.Result) *pb.LLMBenchmark {
+	return &pb.LLMBenchmark{
+		DeviceId:         s.selfDeviceID,
+		Provider:         r.Provider,
+		Model:            r.Model,
+		PrefillToksPerS:  r.PrefillTPS,
+		DecodeToksPerS:   r.DecodeTPS,
+		PromptTokens:     int64(r.PromptTokens),
+		OutputTokens:     int64(r.OutputTokens),
+		Method:           r.Method,
+		MeasuredAtUnixMs: r.MeasuredAt.UnixMilli(),
+		DurationMs:       r.Duration.Milliseconds(),
+	}
+}
+
+// Benchmark measures LLM throughput on this or a remote device, or returns
+// the stored results if req.Cached is set
+func (s *OrchestratorServer) Benchmark(ctx context.Context, req *pb.BenchmarkRequest) (*pb.BenchmarkResponse, error) {
+	if err := s.checkSession("Benchmark", req.SessionId); err != nil {
+		return nil, err
+	}
+
+	// If device_id specified and not self, forward to remote device
+	if req.DeviceId != "" && req.DeviceId != s.selfDeviceID {
+		client, sessionID, closeConn, err := s.dialDevice(ctx, req.DeviceId, "coordinator-benchmark")
+		if err != nil {
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
+		defer closeConn()
+
+		return client.Benchmark(ctx, &pb.BenchmarkRequest{
+			SessionId: sessionID,
+			Models:    req.Models,
+			Runs:      req.Runs,
+			Cached:    req.Cached,
+		})
+	}
+
+	cfg := llm.ChatConfigFromEnv()
+	models := req.Models
+	if len(models) == 0 {
+		models = []string{cfg.Model}
+	}
+
+	if req.Cached {
+		results := make([]*pb.LLMBenchmark, 0, len(models))
+		for _, model := range models {
+			var r *bench.Result
+			ok := false
+			if s.benchStore != nil {
+				r, ok = s.benchStore.Get(cfg.Provider, model)
+			}
+			if !ok {
+				results = append(results, &pb.LLMBenchmark{
+					DeviceId: s.selfDeviceID,
+					Provider: cfg.Provider,
+					Model:    model,
+					Error:    "no stored benchmark",
+				})
+				continue
+			}
+			results = append(results, s.toPbBenchmark(r))
+		}
+		return &pb.BenchmarkResponse{Results: results}, nil
+	}
+
+	results, err := s.runBenchmarks(ctx, models, int(req.Runs))
+	if errors.Is(err, errBenchmarkRunning) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &pb.BenchmarkResponse{Results: results}, nil
+}
+
+// benchIntervalFromEnv reads BENCH_INTERVAL_SECONDS; 0 turns periodic
+// re-benchmarking off
+func benchIntervalFromEnv() time.Duration {
+	if v := os.Getenv("BENCH_INTERVAL_SECONDS"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil && parsed >= 0 {
+			return time.Duration(parsed) * time.Second
+		}
+		log.Printf("[WARN] BENCH_INTERVAL_SECONDS=%q is not a number of seconds, using %v", v, defaultBenchInterval)
+	}
+	return defaultBenchInterval
+}
+
+// idle reports whether no task has run for benchIdleAfter
+func (s *OrchestratorServer) idle() bool {
+	if s.tasksRunning.Load() > 0 {
+		return false
+	}
+	return time.Since(time.UnixMilli(s.lastTaskEnd.Load())) >= benchIdleAfter
+}
+
+// benchmarkWhenIdle keeps the advertised benchmark current: it publishes
+// results stored by "edgecli bench", and re-benchmarks the configured model
+// when its result is missing or older than interval and the device is idle.
+func (s *OrchestratorServer) benchmarkWhenIdle(ctx context.Context, interval time.Duration) {
+	s.lastTaskEnd.Store(time.Now().UnixMilli())
+
+	cfg := llm.ChatConfigFromEnv()
+	switch {
+	case interval <= 0:
+		log.Printf("[INFO] LLM benchmark: periodic re-benchmark disabled")
+	case cfg.Provider == "echo" || cfg.Provider == "mock":
+		log.Printf("[INFO] LLM benchmark: provider %s runs no model, periodic re-benchmark disabled", cfg.Provider)
+		interval = 0
+	default:
+		log.Printf("[INFO] LLM benchmark: re-benchmarking %s/%s when idle, every %v", cfg.Provider, cfg.Model, interval)
+	}
+
+	var lastFailure time.Time
+	ticker := time.NewTicker(benchCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		s.publishBenchmark()
+		if interval <= 0 || !s.idle() || time.Since(lastFailure) < benchRetryAfter {
+			continue
+		}
+		if r, ok := s.selfBenchmark(); ok && time.Since(r.MeasuredAt) < interval {
+			continue
+		}
+
+		results, err := s.runBenchmarks(ctx, []string{cfg.Model}, 1)
+		if errors.Is(err, errBenchmarkRunning) {
+			continue
+		}
+		if err != nil || len(results) == 0 || results[0].Error != "" {
+			lastFailure = time.Now()
+		}
+	}
+}
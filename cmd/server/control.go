@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/edgecli/edgecli/internal/approval"
+	"github.com/edgecli/edgecli/internal/rbac"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// remoteControlTool is the approval tool name for granting stream control.
+// A saved "prefix:control" rule in ~/.edgemesh/approvals.json pre-approves
+// every request, e.g. on an unattended lab machine.
+const remoteControlTool = "remote_control"
+
+// controlPromptMu serialises approval prompts, which share the terminal
+var controlPromptMu sync.Mutex
+
+// loadRBACPolicy reads ~/.edgemesh/rbac.json, falling back to the default
+// policy (view only) when it is missing or invalid
+func loadRBACPolicy() *rbac.Policy {
+	path, err := rbac.DefaultPath()
+	if err != nil {
+		log.Printf("[WARN] RBAC: %v, using default policy", err)
+		return rbac.Default()
+	}
+	policy, err := rbac.Load(path)
+	if err != nil {
+		log.Printf("[WARN] RBAC: %v, using default policy", err)
+		return rbac.Default()
+	}
+	return policy
+}
+
+// checkStreamPermission returns the session's principal if it holds perm.
+// The principal comes from the session's security key, never from its
+// self-chosen device name.
+func (s *OrchestratorServer) checkStreamPermission(sessionID string, perm rbac.Permission) (string, error) {
+	session, err := s.lookupSession("StartWebRTC", sessionID)
+	if err != nil {
+		return "", err
+	}
+	principal := sessionPrincipal(session)
+	if !s.rbacPolicy.Allowed(session.Principal, perm) {
+		log.Printf("[WARN] StartWebRTC: %s lacks %s", principal, perm)
+		return "", status.Errorf(codes.PermissionDenied, "%s lacks the %s permission", principal, perm)
+	}
+	return principal, nil
+}
+
+// checkControlPermission returns the session's principal if it holds the
+// stream.control permission
+func (s *OrchestratorServer) checkControlPermission(sessionID string) (string, error) {
+	return s.checkStreamPermission(sessionID, rbac.PermStreamControl)
+}
+
+// sessionPrincipal names a session's principal for logs and prompts, as
+// anonymous "<device name>" when its key maps to none
+func sessionPrincipal(session *Session) string {
+	if session.Principal == "" {
+		return fmt.Sprintf("anonymous %q", session.DeviceName)
+	}
+	return session.Principal
+}
+
+// createStreamSession opens a session for a web UI stream. The web UI is
+// unauthenticated, so the session is anonymous unless the viewer passes a
+// security key that the RBAC policy maps to a principal.
+func (s *OrchestratorServer) createStreamSession(key string) string {
+	sessionID := s.CreateInternalSession("web-stream")
+	if principal := s.rbacPolicy.Principal(key); principal != "" {
+		s.mu.Lock()
+		s.sessions[sessionID].Principal = principal
+		s.mu.Unlock()
+	}
+	return sessionID
+}
+
+// approveControl asks the person at this device, through the approval
+// prompt, whether principal may control the monitor. A server without a
+// terminal reads EOF and so denies unless a saved rule allows it.
+func approveControl(ctx context.Context, principal string, monitorIndex int) bool {
+	action := &approval.Action{
+		Tool:        remoteControlTool,
+		Command:     fmt.Sprintf("control monitor-%d from %s", monitorIndex, principal),
+		CommandArgs: []string{"control", fmt.Sprintf("monitor-%d", monitorIndex), "from", principal},
+		Rationale:   fmt.Sprintf("%s asks to move the mouse and type on this device through a screen stream", principal),
+		RiskLevel:   approval.RiskHigh,
+	}
+	rules, err := approval.LoadRules()
+	if err != nil {
+		log.Printf("[WARN] approveControl: loading approval rules: %v", err)
+	}
+
+	decided := make(chan bool, 1)
+	go func() {
+		controlPromptMu.Lock()
+		defer controlPromptMu.Unlock()
+		if ctx.Err() != nil {
+			decided <- false
+			return
+		}
+		result := approval.PromptApproval(action, rules)
+		if result.Decision == approval.DecisionAlwaysAllow && rules != nil {
+			if err := rules.AddRule(action.Tool, result.Scope); err != nil {
+				log.Printf("[WARN] approveControl: saving rule: %v", err)
+			}
+		}
+		decided <- result.Decision == approval.DecisionYes || result.Decision == approval.DecisionAlwaysAllow
+	}()
+
+	select {
+	case ok := <-decided:
+		return ok
+	case <-ctx.Done():
+		// The stream ended first. The prompt stays on screen until answered;
+		// its answer is discarded.
+		return false
+	}
+}
+
+// streamStartStatus maps a StartWebRTC error to an HTTP status, so a
+// missing permission reads as 403 rather than a server fault
+func streamStartStatus(err error) int {
+	switch status.Code(err) {
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
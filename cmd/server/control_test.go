@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/edgecli/edgecli/internal/rbac"
+	pb "github.com/edgecli/edgecli/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestControlPrincipalComesFromKey(t *testing.T) {
+	policy := rbac.Default()
+	policy.Principals["ops-laptop"] = []string{"controller"}
+	policy.Keys = map[string]string{"ops-secret": "ops-laptop"}
+	s := &OrchestratorServer{sessions: make(map[string]*Session), rbacPolicy: policy}
+	ctx := context.Background()
+
+	// Naming itself after a controller grants nothing
+	impostor, err := s.CreateSession(ctx, &pb.AuthRequest{DeviceName: "ops-laptop", SecurityKey: "dev"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.checkControlPermission(impostor.SessionId); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("self-named session: err = %v, want PermissionDenied", err)
+	}
+	if _, err := s.checkControlPermission(s.createStreamSession("")); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("anonymous web stream: err = %v, want PermissionDenied", err)
+	}
+	if _, err := s.checkControlPermission(s.createStreamSession("guess")); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("web stream with unknown key: err = %v, want PermissionDenied", err)
+	}
+
+	// The key decides, whatever the device name
+	owner, err := s.CreateSession(ctx, &pb.AuthRequest{DeviceName: "anything", SecurityKey: "ops-secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if principal, err := s.checkControlPermission(owner.SessionId); err != nil || principal != "ops-laptop" {
+		t.Fatalf("keyed session: principal=%q err=%v", principal, err)
+	}
+	if principal, err := s.checkControlPermission(s.createStreamSession("ops-secret")); err != nil || principal != "ops-laptop" {
+		t.Fatalf("keyed web stream: principal=%q err=%v", principal, err)
+	}
+}
+
+func TestStreamViewPermission(t *testing.T) {
+	s := &OrchestratorServer{sessions: make(map[string]*Session), rbacPolicy: rbac.Default()}
+	if _, err := s.checkStreamPermission(s.createStreamSession(""), rbac.PermStreamView); err != nil {
+		t.Fatalf("default policy should let anyone view: %v", err)
+	}
+
+	// Viewing only for key holders
+	policy := &rbac.Policy{
+		Roles:      map[string][]rbac.Permission{"viewer": {rbac.PermStreamView}},
+		Principals: map[string][]string{"wall-display": {"viewer"}},
+		Keys:       map[string]string{"wall-secret": "wall-display"},
+	}
+	s = &OrchestratorServer{sessions: make(map[string]*Session), rbacPolicy: policy}
+	if _, err := s.checkStreamPermission(s.createStreamSession(""), rbac.PermStreamView); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("anonymous viewer: err = %v, want PermissionDenied", err)
+	}
+	if _, err := s.checkStreamPermission(s.createStreamSession("wall-secret"), rbac.PermStreamView); err != nil {
+		t.Fatalf("keyed viewer: %v", err)
+	}
+	if _, err := s.checkStreamPermission("no-such-session", rbac.PermStreamView); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("unknown session: err = %v, want Unauthenticated", err)
+	}
+}
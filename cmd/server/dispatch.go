@@ -0,0 +1,51 @@
+package main
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/edgecli/edgecli/internal/jobs"
+	"github.com/edgecli/edgecli/internal/registry"
+)
+
+// localModelTaskSlots is how many tasks a device with a local model runs at
+// once unless DEVICE_TASK_SLOTS is set: its model serves one request at a
+// time, so queueing there is what makes priorities and preemption count.
+// Other devices are unlimited, so tasks in a group that land on the same
+// device still run in parallel.
+const localModelTaskSlots = 1
+
+// openDispatcher builds the per-device dispatch queues from
+// DEVICE_TASK_SLOTS (0 = unlimited) and TASK_PREEMPTION. Without
+// DEVICE_TASK_SLOTS, slots follow each device's HasLocalModel in reg.
+func openDispatcher(reg *registry.Registry) *jobs.Dispatcher {
+	slots, perDevice := 0, true
+	if v := os.Getenv("DEVICE_TASK_SLOTS"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil && parsed >= 0 {
+			slots, perDevice = parsed, false
+		} else {
+			log.Printf("[WARN] DEVICE_TASK_SLOTS=%q is not a number of tasks, using the per-device default", v)
+		}
+	}
+	v := strings.ToLower(os.Getenv("TASK_PREEMPTION"))
+	preempt := v == "true" || v == "1"
+
+	d := jobs.NewDispatcher(slots, preempt)
+	if perDevice {
+		d.SetDeviceSlots(func(deviceID string) int {
+			if entry, ok := reg.Get(deviceID); ok && entry.Info.HasLocalModel {
+				return localModelTaskSlots
+			}
+			return 0
+		})
+		log.Printf("[INFO] Dispatch: %d task slot(s) per device with a local model, unlimited otherwise, preemption=%v", localModelTaskSlots, preempt)
+		return d
+	}
+	log.Printf("[INFO] Dispatch: %d task slot(s) per device (0 = unlimited), preemption=%v", slots, preempt)
+	if preempt && slots == 0 {
+		log.Printf("[WARN] TASK_PREEMPTION has no effect with unlimited DEVICE_TASK_SLOTS: tasks never queue")
+	}
+	return d
+}
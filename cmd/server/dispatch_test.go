@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/edgecli/edgecli/internal/jobs"
+	"github.com/edgecli/edgecli/internal/registry"
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+// testRegistry holds dev-a, without a local model, and dev-llm, with one
+func testRegistry() *registry.Registry {
+	reg := registry.NewRegistry()
+	reg.Upsert(&pb.DeviceInfo{DeviceId: "dev-a", DeviceName: "a"})
+	reg.Upsert(&pb.DeviceInfo{DeviceId: "dev-llm", DeviceName: "llm", HasLocalModel: true})
+	return reg
+}
+
+// waits reports whether a second task on deviceID waits for the first
+func waits(t *testing.T, d *jobs.Dispatcher, deviceID string) bool {
+	t.Helper()
+	first, err := d.Acquire(context.Background(), deviceID, "task-1", jobs.PriorityNormal, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Release()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	second, err := d.Acquire(ctx, deviceID, "task-2", jobs.PriorityNormal, true)
+	if err != nil {
+		return true
+	}
+	second.Release()
+	return false
+}
+
+func TestDefaultDispatcherRunsGroupTasksInParallel(t *testing.T) {
+	t.Setenv("DEVICE_TASK_SLOTS", "")
+	d := openDispatcher(testRegistry())
+
+	// Two tasks of one group placed on the same device both get a slot
+	// without either finishing
+	if waits(t, d, "dev-a") {
+		t.Fatal("second task on a device without a local model waited for the first")
+	}
+}
+
+func TestDefaultDispatcherQueuesOnLocalModel(t *testing.T) {
+	t.Setenv("DEVICE_TASK_SLOTS", "")
+	d := openDispatcher(testRegistry())
+
+	if !waits(t, d, "dev-llm") {
+		t.Fatal("a device with a local model should run one task at a time")
+	}
+}
+
+func TestDispatcherSlotsFromEnv(t *testing.T) {
+	t.Setenv("DEVICE_TASK_SLOTS", "1")
+	d := openDispatcher(testRegistry())
+
+	if !waits(t, d, "dev-a") {
+		t.Fatal("with one slot the second task should wait")
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/edgecli/edgecli/internal/trace"
+	"github.com/edgecli/edgecli/internal/transfer"
+	pb "github.com/edgecli/edgecli/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// dialDevice connects to a registered device and opens an internal session on it.
+// The caller must invoke the returned close function.
+func (s *OrchestratorServer) dialDevice(ctx context.Context, deviceID, sessionName string) (pb.OrchestratorServiceClient, string, func(), error) {
+	entry, ok := s.registry.Get(deviceID)
+	if !ok {
+		return nil, "", nil, fmt.Errorf("device not found: %s", deviceID)
+	}
+
+	dialCtx, cancel := context.WithTimeout(ctx, remoteDialTimeout)
+	defer cancel()
+
+	dialCtx, span := trace.Start(dialCtx, "grpc.dial", trace.String("device.id", deviceID), trace.String("net.peer.address", entry.Info.GrpcAddr))
+	conn, err := grpc.DialContext(dialCtx, entry.Info.GrpcAddr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithBlock(),
+		grpc.WithChainUnaryInterceptor(trace.UnaryClientInterceptor),
+		grpc.WithChainStreamInterceptor(trace.StreamClientInterceptor),
+	)
+	span.RecordError(err)
+	span.End()
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to connect to device %s: %w", deviceID, err)
+	}
+
+	client := pb.NewOrchestratorServiceClient(conn)
+	sessionResp, err := client.CreateSession(ctx, &pb.AuthRequest{
+		DeviceName:  sessionName,
+		SecurityKey: "internal-routing",
+	})
+	if err != nil {
+		conn.Close()
+		return nil, "", nil, fmt.Errorf("failed to create session on remote: %w", err)
+	}
+
+	return client, sessionResp.SessionId, func() { conn.Close() }, nil
+}
+
+// toPbFileEntry converts a transfer.FileEntry to its protobuf form.
+func toPbFileEntry(e transfer.FileEntry) *pb.FileEntry {
+	return &pb.FileEntry{
+		Name:          e.Name,
+		Path:          e.Path,
+		Type:          e.Type,
+		SizeBytes:     e.SizeBytes,
+		ModTimeUnixMs: e.ModTime.UnixMilli(),
+		Mode:          e.Mode,
+	}
+}
+
+// ListDir lists a directory under the shared root of the local or a remote device
+func (s *OrchestratorServer) ListDir(ctx context.Context, req *pb.ListDirRequest) (*pb.ListDirResponse, error) {
+	if err := s.checkSession("ListDir", req.SessionId); err != nil {
+		return nil, err
+	}
+
+	// If device_id specified and not self, forward to remote device
+	if req.DeviceId != "" && req.DeviceId != s.selfDeviceID {
+		client, sessionID, closeConn, err := s.dialDevice(ctx, req.DeviceId, "coordinator-listdir")
+		if err != nil {
+			return &pb.ListDirResponse{Error: err.Error()}, nil
+		}
+		defer closeConn()
+
+		return client.ListDir(ctx, &pb.ListDirRequest{
+			SessionId:     sessionID,
+			Path:          req.Path,
+			Glob:          req.Glob,
+			PageSize:      req.PageSize,
+			PageToken:     req.PageToken,
+			IncludeHidden: req.IncludeHidden,
+		})
+	}
+
+	page, err := transfer.ListDir(s.sharedRoot, req.Path, transfer.ListOptions{
+		Glob:          req.Glob,
+		PageSize:      int(req.PageSize),
+		PageToken:     req.PageToken,
+		IncludeHidden: req.IncludeHidden,
+	})
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &pb.ListDirResponse{Path: req.Path, Error: fmt.Sprintf("directory not found: %s", req.Path)}, nil
+		}
+		return &pb.ListDirResponse{Path: req.Path, Error: err.Error()}, nil
+	}
+
+	entries := make([]*pb.FileEntry, 0, len(page.Entries))
+	for _, e := range page.Entries {
+		entries = append(entries, toPbFileEntry(e))
+	}
+
+	log.Printf("[INFO] ListDir: path=%q glob=%q returned=%d total=%d", page.Path, req.Glob, len(entries), page.TotalMatches)
+
+	return &pb.ListDirResponse{
+		Path:          page.Path,
+		Entries:       entries,
+		NextPageToken: page.NextPageToken,
+		TotalMatches:  int32(page.TotalMatches),
+	}, nil
+}
+
+// StatFile returns metadata for a path under the shared root of the local or a remote device
+func (s *OrchestratorServer) StatFile(ctx context.Context, req *pb.StatFileRequest) (*pb.StatFileResponse, error) {
+	if err := s.checkSession("StatFile", req.SessionId); err != nil {
+		return nil, err
+	}
+
+	// If device_id specified and not self, forward to remote device
+	if req.DeviceId != "" && req.DeviceId != s.selfDeviceID {
+		client, sessionID, closeConn, err := s.dialDevice(ctx, req.DeviceId, "coordinator-statfile")
+		if err != nil {
+			return &pb.StatFileResponse{Error: err.Error()}, nil
+		}
+		defer closeConn()
+
+		return client.StatFile(ctx, &pb.StatFileRequest{
+			SessionId: sessionID,
+			Path:      req.Path,
+		})
+	}
+
+	entry, err := transfer.Stat(s.sharedRoot, req.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &pb.StatFileResponse{Exists: false}, nil
+		}
+		return &pb.StatFileResponse{Error: err.Error()}, nil
+	}
+
+	return &pb.StatFileResponse{
+		Exists: true,
+		Entry:  toPbFileEntry(*entry),
+	}, nil
+}
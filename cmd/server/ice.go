@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/edgecli/edgecli/internal/webrtcstream"
+	pb "github.com/edgecli/edgecli/proto"
+	"github.com/pion/webrtc/v3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// turnCredentialTTL bounds how long credentials handed out with a
+	// stream can open relay allocations
+	turnCredentialTTL = 12 * time.Hour
+	// maxICEWait caps how long GetIceCandidates holds a poll open
+	maxICEWait = 30 * time.Second
+	// iceHTTPWait is how long /api/stream/ice/poll waits for a candidate
+	iceHTTPWait = 10 * time.Second
+)
+
+// StreamICEServer is an ICE server in the browser's RTCIceServer shape
+type StreamICEServer struct {
+	URLs       []string `json:"urls"`
+	Username   string   `json:"username,omitempty"`
+	Credential string   `json:"credential,omitempty"`
+}
+
+// StreamICECandidate is a candidate in the browser's RTCIceCandidateInit shape
+type StreamICECandidate struct {
+	Candidate     string `json:"candidate"`
+	SDPMid        string `json:"sdpMid,omitempty"`
+	SDPMLineIndex int32  `json:"sdpMLineIndex"`
+}
+
+// StreamICERequest is the JSON request for /api/stream/ice
+type StreamICERequest struct {
+	SelectedDeviceAddr string             `json:"selected_device_addr"`
+	StreamID           string             `json:"stream_id"`
+	Candidate          StreamICECandidate `json:"candidate"`
+}
+
+// StreamICEPollRequest is the JSON request for /api/stream/ice/poll
+type StreamICEPollRequest struct {
+	SelectedDeviceAddr string `json:"selected_device_addr"`
+	StreamID           string `json:"stream_id"`
+	Since              int32  `json:"since"` // device candidates already received
+}
+
+// StreamICEPollResponse is the JSON response for /api/stream/ice/poll
+type StreamICEPollResponse struct {
+	Candidates []StreamICECandidate `json:"candidates"`
+	Complete   bool                 `json:"complete"`
+}
+
+// loadICEServers reads the STUN/TURN servers streams use from
+// STREAM_ICE_SERVERS, with STREAM_ICE_USERNAME and STREAM_ICE_CREDENTIAL
+// for TURN
+func loadICEServers() []webrtc.ICEServer {
+	servers, err := webrtcstream.ParseICEServers(os.Getenv("STREAM_ICE_SERVERS"),
+		os.Getenv("STREAM_ICE_USERNAME"), os.Getenv("STREAM_ICE_CREDENTIAL"))
+	if err != nil {
+		log.Printf("[WARN] STREAM_ICE_SERVERS: %v, using host candidates only", err)
+		return nil
+	}
+	for _, s := range servers {
+		log.Printf("[INFO] ICE server: %s", s.URLs[0])
+	}
+	return servers
+}
+
+// startTURNRelay runs the built-in TURN relay when TURN_ADDR is set, so
+// a coordinator can connect viewers and devices on different subnets
+func (s *OrchestratorServer) startTURNRelay() {
+	addr := os.Getenv("TURN_ADDR")
+	if addr == "" {
+		return
+	}
+	relay, err := webrtcstream.StartTURNRelay(webrtcstream.TURNConfig{
+		ListenAddr: addr,
+		PublicIP:   os.Getenv("TURN_PUBLIC_IP"),
+		Realm:      os.Getenv("TURN_REALM"),
+		Secret:     os.Getenv("TURN_SECRET"),
+	})
+	if err != nil {
+		log.Printf("[ERROR] TURN relay: %v", err)
+		return
+	}
+	s.turnRelay = relay
+}
+
+// relayICEServers returns this server's TURN relay with fresh credentials,
+// or nothing when it runs none
+func (s *OrchestratorServer) relayICEServers() []*pb.IceServer {
+	if s.turnRelay == nil {
+		return nil
+	}
+	server, err := s.turnRelay.ICEServer(turnCredentialTTL)
+	if err != nil {
+		log.Printf("[WARN] TURN credentials: %v", err)
+		return nil
+	}
+	return iceServersToPB([]webrtc.ICEServer{server})
+}
+
+func iceServersToPB(servers []webrtc.ICEServer) []*pb.IceServer {
+	out := make([]*pb.IceServer, 0, len(servers))
+	for _, s := range servers {
+		cred, _ := s.Credential.(string)
+		out = append(out, &pb.IceServer{Urls: s.URLs, Username: s.Username, Credential: cred})
+	}
+	return out
+}
+
+func iceServersFromPB(servers []*pb.IceServer) []webrtc.ICEServer {
+	out := make([]webrtc.ICEServer, 0, len(servers))
+	for _, s := range servers {
+		out = append(out, webrtc.ICEServer{URLs: s.Urls, Username: s.Username, Credential: s.Credential})
+	}
+	return out
+}
+
+func streamICEServers(servers []*pb.IceServer) []StreamICEServer {
+	out := make([]StreamICEServer, 0, len(servers))
+	for _, s := range servers {
+		out = append(out, StreamICEServer{URLs: s.Urls, Username: s.Username, Credential: s.Credential})
+	}
+	return out
+}
+
+// AddIceCandidate adds a viewer candidate to a trickle-ICE stream
+func (s *OrchestratorServer) AddIceCandidate(ctx context.Context, req *pb.IceCandidateRequest) (*pb.Empty, error) {
+	c := req.GetCandidate()
+	if req.StreamId == "" || c == nil {
+		return nil, status.Error(codes.InvalidArgument, "stream_id and candidate are required")
+	}
+	init := webrtc.ICECandidateInit{Candidate: c.Candidate}
+	if c.SdpMid != "" {
+		init.SDPMid = &c.SdpMid
+	}
+	idx := uint16(c.SdpMlineIndex)
+	init.SDPMLineIndex = &idx
+	if err := s.webrtcManager.AddICECandidate(req.StreamId, init); err != nil {
+		log.Printf("[WARN] AddIceCandidate: stream %s: %v", req.StreamId, err)
+		return nil, status.Errorf(codes.NotFound, "add ICE candidate: %v", err)
+	}
+	return &pb.Empty{}, nil
+}
+
+// GetIceCandidates returns a trickle-ICE stream's device candidates,
+// waiting for a new one up to wait_ms
+func (s *OrchestratorServer) GetIceCandidates(ctx context.Context, req *pb.IceCandidatesRequest) (*pb.IceCandidatesResponse, error) {
+	wait := time.Duration(req.WaitMs) * time.Millisecond
+	if wait > maxICEWait {
+		wait = maxICEWait
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+	cands, complete, err := s.webrtcManager.ICECandidates(waitCtx, req.StreamId, int(req.Since))
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "get ICE candidates: %v", err)
+	}
+	resp := &pb.IceCandidatesResponse{Complete: complete}
+	for _, c := range cands {
+		pc := &pb.IceCandidate{Candidate: c.Candidate}
+		if c.SDPMid != nil {
+			pc.SdpMid = *c.SDPMid
+		}
+		if c.SDPMLineIndex != nil {
+			pc.SdpMlineIndex = int32(*c.SDPMLineIndex)
+		}
+		resp.Candidates = append(resp.Candidates, pc)
+	}
+	return resp, nil
+}
+
+// dialStreamDevice connects to the device serving a stream
+func dialStreamDevice(ctx context.Context, addr string) (*grpc.ClientConn, error) {
+	dialCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	return grpc.DialContext(dialCtx, addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithBlock(),
+	)
+}
+
+// handleStreamICE forwards a viewer candidate to the streaming device
+func (h *WebHandler) handleStreamICE(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req StreamICERequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid JSON: %v", err))
+		return
+	}
+	if req.SelectedDeviceAddr == "" || req.StreamID == "" {
+		h.writeError(w, http.StatusBadRequest, "selected_device_addr and stream_id are required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), webRequestTimeout)
+	defer cancel()
+
+	pbReq := &pb.IceCandidateRequest{
+		StreamId: req.StreamID,
+		Candidate: &pb.IceCandidate{
+			Candidate:     req.Candidate.Candidate,
+			SdpMid:        req.Candidate.SDPMid,
+			SdpMlineIndex: req.Candidate.SDPMLineIndex,
+		},
+	}
+	var err error
+	if req.SelectedDeviceAddr == h.orchestrator.selfAddr {
+		_, err = h.orchestrator.AddIceCandidate(ctx, pbReq)
+	} else {
+		var conn *grpc.ClientConn
+		if conn, err = dialStreamDevice(ctx, req.SelectedDeviceAddr); err == nil {
+			defer conn.Close()
+			_, err = pb.NewOrchestratorServiceClient(conn).AddIceCandidate(ctx, pbReq)
+		}
+	}
+	if err != nil {
+		log.Printf("[ERROR] handleStreamICE: %v", err)
+		h.writeError(w, http.StatusInternalServerError, fmt.Sprintf("AddIceCandidate error: %v", err))
+		return
+	}
+	h.writeJSON(w, http.StatusOK, map[string]bool{"ok": true})
+}
+
+// handleStreamICEPoll long-polls the streaming device for its candidates
+func (h *WebHandler) handleStreamICEPoll(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req StreamICEPollRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, fmt.Sprintf("Invalid JSON: %v", err))
+		return
+	}
+	if req.SelectedDeviceAddr == "" || req.StreamID == "" {
+		h.writeError(w, http.StatusBadRequest, "selected_device_addr and stream_id are required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), webRequestTimeout)
+	defer cancel()
+
+	pbReq := &pb.IceCandidatesRequest{
+		StreamId: req.StreamID,
+		Since:    req.Since,
+		WaitMs:   int32(iceHTTPWait / time.Millisecond),
+	}
+	var (
+		resp *pb.IceCandidatesResponse
+		err  error
+	)
+	if req.SelectedDeviceAddr == h.orchestrator.selfAddr {
+		resp, err = h.orchestrator.GetIceCandidates(ctx, pbReq)
+	} else {
+		var conn *grpc.ClientConn
+		if conn, err = dialStreamDevice(ctx, req.SelectedDeviceAddr); err == nil {
+			defer conn.Close()
+			resp, err = pb.NewOrchestratorServiceClient(conn).GetIceCandidates(ctx, pbReq)
+		}
+	}
+	if err != nil {
+		log.Printf("[ERROR] handleStreamICEPoll: %v", err)
+		h.writeError(w, http.StatusInternalServerError, fmt.Sprintf("GetIceCandidates error: %v", err))
+		return
+	}
+
+	out := StreamICEPollResponse{Candidates: []StreamICECandidate{}, Complete: resp.Complete}
+	for _, c := range resp.Candidates {
+		out.Candidates = append(out.Candidates, StreamICECandidate{
+			Candidate:     c.Candidate,
+			SDPMid:        c.SdpMid,
+			SDPMLineIndex: c.SdpMlineIndex,
+		})
+	}
+	h.writeJSON(w, http.StatusOK, out)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/edgecli/edgecli/internal/links"
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+const (
+	// defaultLinkProbeInterval is how often each peer's link is measured
+	defaultLinkProbeInterval = time.Minute
+	// linkProbeDelay lets registration settle before the first probe
+	linkProbeDelay = 5 * time.Second
+	// linkPings is how many Ping calls one RTT measurement takes the fastest of
+	linkPings = 3
+	// linkProbeTimeout bounds one peer's probe
+	linkProbeTimeout = 15 * time.Second
+)
+
+// linkProbeIntervalFromEnv reads LINK_PROBE_INTERVAL_SECONDS; 0 turns link
+// probing off
+func linkProbeIntervalFromEnv() time.Duration {
+	if v := os.Getenv("LINK_PROBE_INTERVAL_SECONDS"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil && parsed >= 0 {
+			return time.Duration(parsed) * time.Second
+		}
+		log.Printf("[WARN] LINK_PROBE_INTERVAL_SECONDS=%q is not a number of seconds, using %v", v, defaultLinkProbeInterval)
+	}
+	return defaultLinkProbeInterval
+}
+
+// linkProbeBytesFromEnv reads LINK_PROBE_BYTES, the size of the throughput
+// probe; 0 measures round-trip time only
+func linkProbeBytesFromEnv() int {
+	if v := os.Getenv("LINK_PROBE_BYTES"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil && parsed >= 0 {
+			return min(parsed, links.MaxProbeBytes)
+		}
+		log.Printf("[WARN] LINK_PROBE_BYTES=%q is not a number of bytes, using %d", v, links.DefaultProbeBytes)
+	}
+	return links.DefaultProbeBytes
+}
+
+// Ping answers a peer measuring its link to this device. It needs no
+// session, like HealthCheck.
+func (s *OrchestratorServer) Ping(ctx context.Context, req *pb.PingRequest) (*pb.PingResponse, error) {
+	return &pb.PingResponse{
+		DeviceId:     s.selfDeviceID,
+		ServerTimeMs: time.Now().UnixMilli(),
+	}, nil
+}
+
+// probeLinks measures the link to every peer in the registry each interval.
+// Peers are probed one at a time so throughput probes do not compete.
+func (s *OrchestratorServer) probeLinks(ctx context.Context, interval time.Duration, probeBytes int) {
+	if interval <= 0 {
+		log.Printf("[INFO] Link probing disabled")
+		return
+	}
+	log.Printf("[INFO] Measuring links to peers every %v (%d byte throughput probe)", interval, probeBytes)
+
+	timer := time.NewTimer(linkProbeDelay)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		for _, device := range s.registry.List() {
+			if device.DeviceId == s.selfDeviceID || device.GrpcAddr == "" {
+				continue
+			}
+			s.probePeer(ctx, device, probeBytes)
+		}
+		timer.Reset(interval)
+	}
+}
+
+// probePeer measures round-trip time and throughput to one peer and
+// records them in the link matrix
+func (s *OrchestratorServer) probePeer(ctx context.Context, device *pb.DeviceInfo, probeBytes int) {
+	probeCtx, cancel := context.WithTimeout(ctx, linkProbeTimeout)
+	defer cancel()
+
+	conn, err := grpc.DialContext(probeCtx, device.GrpcAddr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithBlock(),
+	)
+	if err != nil {
+		s.links.RecordError(s.selfDeviceID, device.DeviceId, err)
+		return
+	}
+	defer conn.Close()
+
+	rtt, err := links.Ping(probeCtx, pb.NewOrchestratorServiceClient(conn), s.selfDeviceID, linkPings)
+	if err != nil {
+		log.Printf("[WARN] Link to %s: %v", device.DeviceName, err)
+		s.links.RecordError(s.selfDeviceID, device.DeviceId, err)
+		return
+	}
+
+	var bps float64
+	if probeBytes > 0 && device.HttpAddr != "" {
+		if bps, err = links.Throughput(probeCtx, device.HttpAddr, probeBytes, rtt); err != nil {
+			// Keep the RTT; the throughput measured before still stands
+			log.Printf("[WARN] Link to %s: %v", device.DeviceName, err)
+			bps = 0
+		}
+	}
+	s.links.Record(s.selfDeviceID, device.DeviceId, rtt, bps)
+}
+
+// storeReportedLinks takes the links a peer measured from itself. Links it
+// reports between other devices are ignored; those devices report their own.
+func (s *OrchestratorServer) storeReportedLinks(deviceID string, reported []*pb.LinkStats) {
+	for _, l := range reported {
+		if l.FromDeviceId == deviceID && l.ToDeviceId != "" {
+			s.links.Set(links.FromProto(l))
+		}
+	}
+}
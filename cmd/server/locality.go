@@ -0,0 +1,417 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/edgecli/edgecli/internal/cost"
+	"github.com/edgecli/edgecli/internal/filesync"
+	"github.com/edgecli/edgecli/internal/jobs"
+	"github.com/edgecli/edgecli/internal/transfer"
+	pb "github.com/edgecli/edgecli/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// locateTimeout bounds how long SubmitJob waits for devices to report artifacts
+const locateTimeout = 5 * time.Second
+
+// openCalibration opens the learned task latencies at CALIBRATION_FILE, or
+// ~/.edgemesh/calibration.json. If they cannot be opened, learning starts
+// over in memory.
+func openCalibration() *cost.Calibration {
+	path := os.Getenv("CALIBRATION_FILE")
+	if path == "" {
+		var err error
+		if path, err = cost.DefaultCalibrationPath(); err != nil {
+			log.Printf("[WARN] Cost calibration will not be saved: %v", err)
+			return cost.NewCalibration()
+		}
+	}
+	c, err := cost.OpenCalibration(path)
+	if err != nil {
+		log.Printf("[WARN] Cost calibration will not be saved: %v", err)
+		return cost.NewCalibration()
+	}
+	return c
+}
+
+// resolveLocality finds where every input artifact in the plan lives.
+// Path inputs are stat'ed on their device for size; hash inputs are looked
+// up on every device.
+func (s *OrchestratorServer) resolveLocality(ctx context.Context, plan *pb.Plan, devices []*pb.DeviceInfo) cost.Locality {
+	locality := make(cost.Locality)
+	if plan == nil {
+		return locality
+	}
+
+	hashes := make(map[string]bool)
+	for _, group := range plan.Groups {
+		for _, task := range group.Tasks {
+			for _, in := range task.Inputs {
+				key := cost.ArtifactKey(in)
+				if in.DeviceId != "" && in.Path != "" && len(locality[key]) == 0 {
+					size := in.SizeBytes
+					if size == 0 {
+						size = s.statArtifactSize(ctx, in.DeviceId, in.Path)
+					}
+					locality[key] = append(locality[key], cost.ArtifactLocation{
+						DeviceID:  in.DeviceId,
+						Path:      in.Path,
+						SizeBytes: size,
+					})
+				}
+				if in.Sha256 != "" {
+					hashes[in.Sha256] = true
+				}
+			}
+		}
+	}
+	if len(hashes) == 0 {
+		return locality
+	}
+
+	want := make([]string, 0, len(hashes))
+	for h := range hashes {
+		want = append(want, h)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, locateTimeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, d := range devices {
+		wg.Add(1)
+		go func(d *pb.DeviceInfo) {
+			defer wg.Done()
+			found, err := s.locateOnDevice(ctx, d.DeviceId, want)
+			if err != nil {
+				log.Printf("[WARN] resolveLocality: locate on %s failed: %v", d.DeviceName, err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for _, f := range found {
+				key := "sha256:" + f.Sha256
+				if _, ok := locality.On(&pb.InputArtifact{Sha256: f.Sha256}, d.DeviceId); ok {
+					continue
+				}
+				locality[key] = append(locality[key], cost.ArtifactLocation{
+					DeviceID:  d.DeviceId,
+					Path:      f.Path,
+					SizeBytes: f.SizeBytes,
+				})
+			}
+		}(d)
+	}
+	wg.Wait()
+	return locality
+}
+
+// statArtifactSize returns the size of a file on a device, or 0 if unknown.
+func (s *OrchestratorServer) statArtifactSize(ctx context.Context, deviceID, path string) int64 {
+	if deviceID == s.selfDeviceID {
+		entry, err := transfer.Stat(s.sharedRoot, path)
+		if err != nil {
+			return 0
+		}
+		return entry.SizeBytes
+	}
+
+	client, sessionID, closeConn, err := s.dialDevice(ctx, deviceID, "coordinator-locality")
+	if err != nil {
+		log.Printf("[WARN] statArtifactSize: %v", err)
+		return 0
+	}
+	defer closeConn()
+
+	resp, err := client.StatFile(ctx, &pb.StatFileRequest{SessionId: sessionID, Path: path})
+	if err != nil || resp.Entry == nil {
+		return 0
+	}
+	return resp.Entry.SizeBytes
+}
+
+// locateOnDevice asks one device which of the hashes it holds.
+func (s *OrchestratorServer) locateOnDevice(ctx context.Context, deviceID string, hashes []string) ([]*pb.ArtifactLocation, error) {
+	if deviceID == s.selfDeviceID {
+		return s.locateLocal(hashes)
+	}
+	client, sessionID, closeConn, err := s.dialDevice(ctx, deviceID, "coordinator-locality")
+	if err != nil {
+		return nil, err
+	}
+	defer closeConn()
+
+	resp, err := client.LocateArtifacts(ctx, &pb.LocateArtifactsRequest{SessionId: sessionID, Sha256: hashes})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Found, nil
+}
+
+// locateLocal finds files with the given hashes under the shared root,
+// including copies staged for earlier tasks.
+func (s *OrchestratorServer) locateLocal(hashes []string) ([]*pb.ArtifactLocation, error) {
+	var found []*pb.ArtifactLocation
+	remaining := make(map[string]bool, len(hashes))
+	for _, h := range hashes {
+		if loc := s.stagedCopy(h); loc != nil {
+			found = append(found, loc)
+		} else {
+			remaining[h] = true
+		}
+	}
+	if len(remaining) == 0 {
+		return found, nil
+	}
+
+	m, err := filesync.BuildManifest(s.sharedRoot, s.ticketManager.Checksum)
+	if err != nil {
+		return found, err
+	}
+	for _, p := range m.Paths() {
+		f := m[p]
+		if remaining[f.SHA256] {
+			delete(remaining, f.SHA256)
+			found = append(found, &pb.ArtifactLocation{Sha256: f.SHA256, Path: f.Path, SizeBytes: f.SizeBytes})
+		}
+	}
+	return found, nil
+}
+
+// isContentHash reports whether h is a hex SHA-256 as this repo writes them:
+// 64 lowercase hex characters
+func isContentHash(h string) bool {
+	if len(h) != sha256.Size*2 {
+		return false
+	}
+	for _, c := range h {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// stagedCopy returns a verified staged copy of the content hash, if any.
+func (s *OrchestratorServer) stagedCopy(hash string) *pb.ArtifactLocation {
+	// The hash names a directory and is globbed, so it must be plain hex
+	if !isContentHash(hash) {
+		return nil
+	}
+	staged, _ := filepath.Glob(filepath.Join(s.sharedRoot, jobs.StagingDir, hash, "*"))
+	for _, p := range staged {
+		info, err := os.Stat(p)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		if sum, err := s.ticketManager.Checksum(p); err == nil && sum == hash {
+			rel, _ := filepath.Rel(s.sharedRoot, p)
+			return &pb.ArtifactLocation{Sha256: hash, Path: filepath.ToSlash(rel), SizeBytes: info.Size()}
+		}
+	}
+	return nil
+}
+
+// LocateArtifacts reports which of the requested content hashes this device holds
+func (s *OrchestratorServer) LocateArtifacts(ctx context.Context, req *pb.LocateArtifactsRequest) (*pb.LocateArtifactsResponse, error) {
+	if err := s.checkSession("LocateArtifacts", req.SessionId); err != nil {
+		return nil, err
+	}
+
+	found, err := s.locateLocal(req.Sha256)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "locate: %v", err)
+	}
+	log.Printf("[INFO] LocateArtifacts: requested=%d found=%d", len(req.Sha256), len(found))
+	return &pb.LocateArtifactsResponse{DeviceId: s.selfDeviceID, Found: found}, nil
+}
+
+// StageFile pulls a file from a registered device's bulk HTTP server into
+// the shared root, verifying its SHA-256. The source is named by device ID
+// and download ticket, never by URL, so callers cannot point it elsewhere.
+func (s *OrchestratorServer) StageFile(ctx context.Context, req *pb.StageFileRequest) (*pb.StageFileResponse, error) {
+	if err := s.checkSession("StageFile", req.SessionId); err != nil {
+		return nil, err
+	}
+
+	dest, err := transfer.ResolveUnderRoot(s.sharedRoot, req.Path)
+	if err != nil {
+		return &pb.StageFileResponse{Path: req.Path, Error: err.Error()}, nil
+	}
+
+	if req.Sha256 != "" {
+		if sum, err := s.ticketManager.Checksum(dest); err == nil && sum == req.Sha256 {
+			info, _ := os.Stat(dest)
+			return &pb.StageFileResponse{Path: req.Path, SizeBytes: info.Size(), Sha256: sum, AlreadyPresent: true}, nil
+		}
+	}
+
+	src, ok := s.registry.Get(req.SourceDeviceId)
+	if !ok || src.Info.HttpAddr == "" {
+		return &pb.StageFileResponse{Path: req.Path, Error: fmt.Sprintf("unknown source device %q", req.SourceDeviceId)}, nil
+	}
+	if !isTicketToken(req.Token) {
+		return &pb.StageFileResponse{Path: req.Path, Error: "invalid download token"}, nil
+	}
+	url := fmt.Sprintf("http://%s/bulk/download/%s", src.Info.HttpAddr, req.Token)
+
+	size, sum, err := s.fetchToFile(ctx, url, dest, req.Sha256, req.SizeBytes)
+	if err != nil {
+		log.Printf("[ERROR] StageFile: %s: %v", req.Path, err)
+		return &pb.StageFileResponse{Path: req.Path, Error: err.Error()}, nil
+	}
+
+	log.Printf("[INFO] StageFile: path=%s size=%d sha256=%s", req.Path, size, sum)
+	return &pb.StageFileResponse{Path: req.Path, SizeBytes: size, Sha256: sum}, nil
+}
+
+// isTicketToken reports whether t looks like a transfer ticket token
+// (URL-safe base64), so it can go into a URL path unescaped
+func isTicketToken(t string) bool {
+	if t == "" {
+		return false
+	}
+	for _, c := range t {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && c != '-' && c != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// fetchToFile downloads url into dest through a temp file, checking the
+// content against want (or the server's X-Content-SHA256 when want is empty).
+// The download counts against the upload quota and may not exceed
+// sizeBytes, or the declared Content-Length when sizeBytes is 0.
+func (s *OrchestratorServer) fetchToFile(ctx context.Context, url, dest, want string, sizeBytes int64) (int64, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, "", fmt.Errorf("bulk download: %s", resp.Status)
+	}
+	if want == "" {
+		want = resp.Header.Get("X-Content-SHA256")
+	}
+	if sizeBytes <= 0 {
+		sizeBytes = resp.ContentLength
+	}
+	if sizeBytes < 0 {
+		return 0, "", fmt.Errorf("bulk download: size unknown")
+	}
+	var replacing int64
+	if info, err := os.Stat(dest); err == nil {
+		replacing = info.Size()
+	}
+	if err := s.ticketManager.CheckQuota(sizeBytes, replacing); err != nil {
+		return 0, "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return 0, "", err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dest), "."+filepath.Base(dest)+".*.part")
+	if err != nil {
+		return 0, "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	// Read one byte past the size so a longer body is caught
+	n, err := io.Copy(io.MultiWriter(tmp, h), io.LimitReader(resp.Body, sizeBytes+1))
+	s.promMetrics.bulkBytes.Add(float64(n), "fetched", "stage")
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && n != sizeBytes {
+		err = fmt.Errorf("bulk download: got %d bytes, want %d", n, sizeBytes)
+	}
+	if err != nil {
+		return 0, "", err
+	}
+
+	sum := hex.EncodeToString(h.Sum(nil))
+	if want != "" && sum != want {
+		return 0, "", fmt.Errorf("%w: got %s, want %s", transfer.ErrChecksumMismatch, sum, want)
+	}
+	// The temp file already counts as used; check again in case other
+	// writes landed during the download
+	if err := s.ticketManager.CheckQuota(0, replacing); err != nil {
+		return 0, "", err
+	}
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		return 0, "", err
+	}
+	return n, sum, nil
+}
+
+// stageTaskInputs copies the inputs a task's device lacks from their source
+// devices before the task runs. The device pulls each file directly from
+// the source's bulk HTTP server.
+func (s *OrchestratorServer) stageTaskInputs(ctx context.Context, t *jobs.Task) error {
+	for _, in := range t.Inputs {
+		if !in.NeedsStaging {
+			continue
+		}
+
+		src, ok := s.registry.Get(in.Source.DeviceID)
+		if !ok || src.Info.HttpAddr == "" {
+			return fmt.Errorf("source device %s has no bulk HTTP address", in.Source.DeviceID)
+		}
+
+		srcClient, _, closeSrc, err := s.dialDevice(ctx, in.Source.DeviceID, "coordinator-stage")
+		if err != nil {
+			return err
+		}
+		ticket, err := srcClient.CreateDownloadTicket(ctx, &pb.DownloadTicketRequest{Path: in.Source.Path})
+		closeSrc()
+		if err != nil {
+			return fmt.Errorf("download ticket for %s: %w", in.Source.Path, err)
+		}
+
+		dstClient, sessionID, closeDst, err := s.dialDevice(ctx, t.DeviceID, "coordinator-stage")
+		if err != nil {
+			return err
+		}
+		resp, err := dstClient.StageFile(ctx, &pb.StageFileRequest{
+			SessionId:      sessionID,
+			SourceDeviceId: in.Source.DeviceID,
+			Token:          ticket.Token,
+			Path:           in.LocalPath,
+			Sha256:         in.Artifact.Sha256,
+			SizeBytes:      ticket.SizeBytes,
+		})
+		closeDst()
+		if err != nil {
+			return err
+		}
+		if resp.Error != "" {
+			return fmt.Errorf("stage %s: %s", in.LocalPath, resp.Error)
+		}
+
+		if !resp.AlreadyPresent {
+			s.jobManager.AddStagedBytes(t.JobID, t.ID, resp.SizeBytes)
+		}
+		log.Printf("[INFO] stageTaskInputs: task=%s staged %s from %s to %s (%d bytes, cached=%v)",
+			t.ID, in.Source.Path, in.Source.DeviceID, t.DeviceName, resp.SizeBytes, resp.AlreadyPresent)
+	}
+	return nil
+}
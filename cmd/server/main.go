@@ -6,7 +6,9 @@ import (
 	"context"
 	"embed"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -18,6 +20,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -26,22 +29,32 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/kbinani/screenshot"
 
+	"github.com/edgecli/edgecli/internal/alerts"
 	"github.com/edgecli/edgecli/internal/allowlist"
+	"github.com/edgecli/edgecli/internal/audit"
+	"github.com/edgecli/edgecli/internal/bench"
 	"github.com/edgecli/edgecli/internal/brain"
 	"github.com/edgecli/edgecli/internal/chatmem"
 	"github.com/edgecli/edgecli/internal/cost"
 	"github.com/edgecli/edgecli/internal/deviceid"
 	"github.com/edgecli/edgecli/internal/discovery"
 	"github.com/edgecli/edgecli/internal/exec"
+	"github.com/edgecli/edgecli/internal/filesync"
 	"github.com/edgecli/edgecli/internal/jobs"
+	"github.com/edgecli/edgecli/internal/links"
 	"github.com/edgecli/edgecli/internal/llm"
 	"github.com/edgecli/edgecli/internal/metrics"
 	"github.com/edgecli/edgecli/internal/qaihub"
+	"github.com/edgecli/edgecli/internal/rbac"
 	"github.com/edgecli/edgecli/internal/registry"
+	"github.com/edgecli/edgecli/internal/scheduler"
 	"github.com/edgecli/edgecli/internal/sysinfo"
+	"github.com/edgecli/edgecli/internal/tasks"
+	"github.com/edgecli/edgecli/internal/trace"
 	"github.com/edgecli/edgecli/internal/transfer"
 	"github.com/edgecli/edgecli/internal/webrtcstream"
 	pb "github.com/edgecli/edgecli/proto"
@@ -56,6 +69,7 @@ const (
 	defaultBulkHTTPAddr = ":8081"
 	defaultSharedDir    = "./shared"
 	defaultBulkTTL      = 60
+	defaultMaxUpload    = 16 << 30 // 16 GiB per uploaded file
 	defaultDevKey       = "dev"
 	remoteDialTimeout   = 15 * time.Second
 	webRequestTimeout   = 30 * time.Second
@@ -64,9 +78,10 @@ const (
 // Session represents an authenticated client session
 type Session struct {
 	ID          string
-	DeviceName  string
+	DeviceName  string // chosen by the client; not verified
 	HostName    string
 	ConnectedAt time.Time
+	Principal   string // RBAC principal its security key maps to; "" = anonymous
 }
 
 // OrchestratorServer implements the OrchestratorService gRPC interface
@@ -78,17 +93,37 @@ type OrchestratorServer struct {
 	registry      *registry.Registry
 	jobManager    *jobs.Manager
 	webrtcManager *webrtcstream.Manager
+	turnRelay     *webrtcstream.TURNRelay // nil unless TURN_ADDR
+	streamIdle    time.Duration           // streams without a viewer this long are stopped
+	auditLog      *audit.Log              // nil if the audit trail could not be opened
+	rbacPolicy    *rbac.Policy
 	brain         *brain.Brain     // Windows AI CLI planner (platform-specific)
 	llmProvider   llm.Provider     // Cross-platform LLM planner (openai_compat, etc.)
 	chatProvider  llm.ChatProvider // Local chat provider for LLM task execution
+	embedProvider llm.EmbedProvider // Local embed provider for EMBED and MAP tasks
 	chatMemories  map[string]*chatmem.ChatMemory
 	muChat        sync.RWMutex
 	selfDeviceID  string
 	selfAddr      string
 	ticketManager *transfer.Manager
+	mirrors       recordingMirrors // remote recordings copied here, by stream ID
 	sharedRoot    string
 	bulkHTTPAddr  string
 	metricsStore  *metrics.MetricsStore
+	syncService   *filesync.Service // nil unless SYNC_ENABLED
+	syncPeers     []string
+	syncInterval  time.Duration
+	scheduler     *scheduler.Scheduler
+	dispatcher    *jobs.Dispatcher
+	discoverySvc  *discovery.Service // nil unless P2P discovery is on
+	benchStore    *bench.Store       // nil if benchmarks cannot be stored
+	calibration   *cost.Calibration  // task latencies learned from completed jobs
+	links         *links.Matrix      // measured network links between devices
+	promMetrics   *serverMetrics     // served at /metrics
+	alertEngine   *alerts.Engine     // evaluates alert rules, feeds WatchAlerts
+	benchMu       sync.Mutex         // held while a benchmark runs
+	tasksRunning  atomic.Int32       // RunTask calls in flight, for idle detection
+	lastTaskEnd   atomic.Int64       // unix ms the last RunTask finished
 }
 
 // WebHandler handles HTTP requests using in-process calls to OrchestratorServer
@@ -105,17 +140,21 @@ type WebHandler struct {
 
 // DeviceResponse is the JSON response for /api/devices
 type DeviceResponse struct {
-	DeviceID          string   `json:"device_id"`
-	DeviceName        string   `json:"device_name"`
-	Platform          string   `json:"platform"`
-	Arch              string   `json:"arch"`
-	Capabilities      []string `json:"capabilities"`
-	GRPCAddr          string   `json:"grpc_addr"`
-	CanScreenCapture  bool     `json:"can_screen_capture"`
-	HttpAddr          string   `json:"http_addr"`
-	HasLocalModel     bool     `json:"has_local_model"`
-	LocalModelName    string   `json:"local_model_name,omitempty"`
-	LocalChatEndpoint string   `json:"local_chat_endpoint,omitempty"`
+	DeviceID          string         `json:"device_id"`
+	DeviceName        string         `json:"device_name"`
+	Platform          string         `json:"platform"`
+	Arch              string         `json:"arch"`
+	Capabilities      []string       `json:"capabilities"`
+	GRPCAddr          string         `json:"grpc_addr"`
+	CanScreenCapture  bool           `json:"can_screen_capture"`
+	HttpAddr          string         `json:"http_addr"`
+	HasLocalModel     bool           `json:"has_local_model"`
+	LocalModelName    string         `json:"local_model_name,omitempty"`
+	LocalChatEndpoint string         `json:"local_chat_endpoint,omitempty"`
+	TaskKinds         []string       `json:"task_kinds,omitempty"`
+	LLMPrefillTPS     float64        `json:"llm_prefill_toks_per_s,omitempty"`
+	LLMDecodeTPS      float64        `json:"llm_decode_toks_per_s,omitempty"`
+	Power             *pb.PowerState `json:"power,omitempty"`
 }
 
 // RoutedCmdRequest is the JSON request for /api/routed-cmd
@@ -124,6 +163,7 @@ type RoutedCmdRequest struct {
 	Args          []string `json:"args"`
 	Policy        string   `json:"policy"`
 	ForceDeviceID string   `json:"force_device_id"`
+	MinBattery    float64  `json:"min_battery_percent,omitempty"` // AVOID_LOW_BATTERY threshold
 }
 
 // RoutedCmdResponse is the JSON response for /api/routed-cmd
@@ -196,6 +236,11 @@ type StepCostResponse struct {
 	PredictedMemoryMB float64 `json:"predicted_memory_mb"`
 	UnknownCost       bool    `json:"unknown_cost"`
 	Notes             string  `json:"notes,omitempty"`
+	TransferMs        float64 `json:"transfer_ms,omitempty"`
+	TransferBytes     int64   `json:"transfer_bytes,omitempty"`
+	Confidence        string  `json:"confidence"`
+	Samples           int32   `json:"samples"`
+	EnergyJ           float64 `json:"energy_j"`
 }
 
 // DeviceCostResponse is the cost breakdown for a single device
@@ -206,6 +251,10 @@ type DeviceCostResponse struct {
 	StepCosts          []StepCostResponse `json:"step_costs"`
 	EstimatedPeakRAMMB uint64             `json:"estimated_peak_ram_mb"`
 	RAMSufficient      bool               `json:"ram_sufficient"`
+	Confidence         string             `json:"confidence"`
+	Samples            int32              `json:"samples"`
+	EnergyJ            float64            `json:"energy_j"`
+	BatteryDrain       float64            `json:"battery_drain_percent,omitempty"`
 }
 
 // PlanCostResponse is the JSON response for /api/plan-cost
@@ -216,6 +265,9 @@ type PlanCostResponse struct {
 	RecommendedDeviceName string               `json:"recommended_device_name"`
 	HasUnknownCosts       bool                 `json:"has_unknown_costs"`
 	Warning               string               `json:"warning,omitempty"`
+	Confidence            string               `json:"confidence"`
+	Samples               int32                `json:"samples"`
+	TotalEnergyJ          float64              `json:"total_energy_j"`
 }
 
 // DownloadRequest is the JSON request for /api/request-download
@@ -230,12 +282,14 @@ type DownloadResponse struct {
 	Filename      string `json:"filename"`
 	SizeBytes     int64  `json:"size_bytes"`
 	ExpiresUnixMs int64  `json:"expires_unix_ms"`
+	Live          bool   `json:"live,omitempty"` // still being written; the download follows it until it is finished
 }
 
 // SubmitJobRequest is the JSON request for /api/submit-job
 type SubmitJobRequest struct {
 	Text       string `json:"text"`
 	MaxWorkers int32  `json:"max_workers"`
+	Priority   int32  `json:"priority"`
 }
 
 // JobInfoResponse is the JSON response for /api/submit-job
@@ -243,6 +297,7 @@ type JobInfoResponse struct {
 	JobID     string `json:"job_id"`
 	CreatedAt int64  `json:"created_at"`
 	Summary   string `json:"summary"`
+	TraceID   string `json:"trace_id,omitempty"`
 }
 
 // TaskStatusResponse represents a task in the job status
@@ -253,6 +308,7 @@ type TaskStatusResponse struct {
 	State              string `json:"state"`
 	Result             string `json:"result"`
 	Error              string `json:"error"`
+	Shell              *pb.ShellResult `json:"shell,omitempty"`
 }
 
 // JobStatusResponse is the JSON response for /api/job
@@ -263,15 +319,28 @@ type JobStatusResponse struct {
 	FinalResult  string               `json:"final_result"`
 	CurrentGroup int32                `json:"current_group"`
 	TotalGroups  int32                `json:"total_groups"`
+	TraceID      string               `json:"trace_id,omitempty"`
+	ScheduleID   string               `json:"schedule_id,omitempty"`
 }
 
 // StreamStartRequest is the JSON request for /api/stream/start
 type StreamStartRequest struct {
 	Policy        string `json:"policy"`
 	ForceDeviceID string `json:"force_device_id"`
-	FPS           int32  `json:"fps"`
-	Quality       int32  `json:"quality"`
-	MonitorIndex  int32  `json:"monitor_index"`
+	FPS            int32    `json:"fps"`
+	Quality        int32    `json:"quality"`
+	MonitorIndex   int32    `json:"monitor_index"`
+	Mode           string   `json:"mode,omitempty"`             // forces "video", "tiles" or "jpeg"
+	AcceptModes    []string `json:"accept_modes,omitempty"`     // modes the viewer can display, when mode is empty
+	MaxBitrateKbps int32    `json:"max_bitrate_kbps,omitempty"` // video only
+	Control        bool     `json:"control,omitempty"`          // request mouse and keyboard control
+	Audio          bool     `json:"audio,omitempty"`            // add an Opus audio track
+	AudioSource    string   `json:"audio_source,omitempty"`     // "system", "mic", "tone" or "file"
+	Trickle        bool     `json:"trickle,omitempty"`          // exchange ICE candidates via /api/stream/ice
+	Layer          string   `json:"layer,omitempty"`            // "high", "medium" or "low"
+	Viewer         string   `json:"viewer,omitempty"`           // shown in /api/streams; the client address if empty
+	Key            string   `json:"key,omitempty"`              // security key naming the viewer's RBAC principal, for control
+	Record         string   `json:"record,omitempty"`           // "device" or "coordinator" records the stream there
 }
 
 // StreamStartResponse is the JSON response for /api/stream/start
@@ -281,6 +350,18 @@ type StreamStartResponse struct {
 	SelectedDeviceAddr string `json:"selected_device_addr"`
 	StreamID           string `json:"stream_id"`
 	OfferSDP           string `json:"offer_sdp"`
+	Mode               string `json:"mode"`
+	Control            bool   `json:"control"` // an input channel is offered, pending local approval
+	Audio              bool   `json:"audio"`   // an audio track is offered
+	Trickle            bool   `json:"trickle"` // offer_sdp has no candidates; poll /api/stream/ice/poll
+	// ICEServers are the STUN/TURN servers the device uses; the viewer
+	// should pass them to RTCPeerConnection
+	ICEServers []StreamICEServer `json:"ice_servers"`
+	Layer      string            `json:"layer"` // quality layer; empty from older devices
+	// Recording is the recording's path under the shared root of
+	// RecordingDeviceID; download it with /api/request-download
+	Recording         string `json:"recording,omitempty"`
+	RecordingDeviceID string `json:"recording_device_id,omitempty"`
 }
 
 // StreamAnswerRequest is the JSON request for /api/stream/answer
@@ -387,21 +468,97 @@ func NewOrchestratorServer(addr string) *OrchestratorServer {
 		bulkHTTPAddr = defaultBulkHTTPAddr
 	}
 
-	return &OrchestratorServer{
+	// Upload limits: per-file cap and optional total quota for the shared root
+	maxUpload := int64(defaultMaxUpload)
+	if v := os.Getenv("BULK_MAX_UPLOAD_BYTES"); v != "" {
+		if parsed, parseErr := strconv.ParseInt(v, 10, 64); parseErr == nil && parsed >= 0 {
+			maxUpload = parsed
+		}
+	}
+	var sharedQuota int64
+	if v := os.Getenv("SHARED_QUOTA_BYTES"); v != "" {
+		if parsed, parseErr := strconv.ParseInt(v, 10, 64); parseErr == nil && parsed >= 0 {
+			sharedQuota = parsed
+		}
+	}
+	// Screen streaming: frame source ("synthetic" for headless hosts) and video encoder
+	webrtcManager := webrtcstream.NewManager()
+	if v := os.Getenv("STREAM_SOURCE"); v != "" {
+		if err := webrtcManager.SetFrameSource(v); err != nil {
+			log.Printf("[WARN] STREAM_SOURCE: %v, using screen capture", err)
+		}
+	}
+	if v := os.Getenv("STREAM_ENCODER"); v != "" {
+		if err := webrtcManager.SetVideoEncoder(v); err != nil {
+			log.Printf("[WARN] STREAM_ENCODER: %v, using %s", err, webrtcstream.DefaultVideoEncoder)
+		}
+	}
+	if v := os.Getenv("STREAM_INJECTOR"); v != "" {
+		if err := webrtcManager.SetInputInjector(v); err != nil {
+			log.Printf("[WARN] STREAM_INJECTOR: %v, remote input is discarded", err)
+		}
+	}
+	audioSource := webrtcstream.AudioSystem
+	if v := os.Getenv("STREAM_AUDIO"); v != "" {
+		audioSource = v
+	}
+	if err := webrtcManager.SetAudioSource(audioSource, webrtcstream.AudioConfig{
+		File:   os.Getenv("STREAM_AUDIO_FILE"),
+		Device: os.Getenv("STREAM_AUDIO_DEVICE"),
+	}); err != nil {
+		log.Printf("[WARN] STREAM_AUDIO: %v, using %s", err, webrtcstream.AudioSystem)
+	}
+	webrtcManager.SetICEServers(loadICEServers())
+	webrtcManager.SetRecordingDir(filepath.Join(sharedRootAbs, recordingsDir))
+	if v := os.Getenv("STREAM_MAX_VIEWERS"); v != "" {
+		if parsed, parseErr := strconv.Atoi(v); parseErr == nil && parsed >= 0 {
+			webrtcManager.SetMaxViewers(parsed)
+		}
+	}
+	streamIdle := webrtcstream.DefaultIdleTimeout
+	if v := os.Getenv("STREAM_IDLE_TIMEOUT_SECONDS"); v != "" {
+		if parsed, parseErr := strconv.Atoi(v); parseErr == nil && parsed > 0 {
+			streamIdle = time.Duration(parsed) * time.Second
+		}
+	}
+
+	ticketManager := transfer.NewManager(time.Duration(bulkTTL) * time.Second)
+	ticketManager.SetUploadLimits(sharedRootAbs, transfer.UploadLimits{
+		MaxFileBytes: maxUpload,
+		QuotaBytes:   sharedQuota,
+	})
+
+	s := &OrchestratorServer{
 		sessions:      make(map[string]*Session),
 		runner:        exec.NewRunner(),
 		registry:      registry.NewRegistry(),
 		jobManager:    jobs.NewManager(),
-		webrtcManager: webrtcstream.NewManager(),
+		webrtcManager: webrtcManager,
+		streamIdle:    streamIdle,
+		auditLog:      openAuditLog(),
+		rbacPolicy:    loadRBACPolicy(),
 		brain:         brain.New(),
 		chatMemories:  make(map[string]*chatmem.ChatMemory),
 		selfDeviceID:  selfID,
 		selfAddr:      selfAddr,
-		ticketManager: transfer.NewManager(time.Duration(bulkTTL) * time.Second),
+		ticketManager: ticketManager,
 		sharedRoot:    sharedRootAbs,
 		bulkHTTPAddr:  bulkHTTPAddr,
 		metricsStore:  metrics.NewMetricsStore(),
-	}
+		benchStore:    openBenchStore(),
+		calibration:   openCalibration(),
+		links:         links.NewMatrix(),
+		scheduler:     openScheduler(),
+	}
+	s.dispatcher = openDispatcher(s.registry)
+	s.jobManager.SetCalibration(s.calibration)
+	s.jobManager.SetLinks(s.links, selfID)
+	s.metricsStore.SetArchive(openMetricsArchive())
+	s.promMetrics = newServerMetrics(s)
+	s.alertEngine = s.openAlerts()
+	s.jobManager.SetEventHook(s.alertEngine.JobEvent)
+	webrtcManager.SetEventHook(s.streamEvent)
+	return s
 }
 
 // registerSelf registers this server as a device in its own registry
@@ -435,6 +592,7 @@ func (s *OrchestratorServer) CreateSession(ctx context.Context, req *pb.AuthRequ
 		DeviceName:  req.DeviceName,
 		HostName:    hostName,
 		ConnectedAt: time.Now(),
+		Principal:   s.rbacPolicy.Principal(req.SecurityKey),
 	}
 
 	// Store session
@@ -451,6 +609,25 @@ func (s *OrchestratorServer) CreateSession(ctx context.Context, req *pb.AuthRequ
 	}, nil
 }
 
+// lookupSession returns sessionID's session, or Unauthenticated if it is
+// not a live session
+func (s *OrchestratorServer) lookupSession(rpc, sessionID string) (*Session, error) {
+	s.mu.RLock()
+	session, exists := s.sessions[sessionID]
+	s.mu.RUnlock()
+	if !exists {
+		log.Printf("[ERROR] %s: session not found: %s", rpc, sessionID)
+		return nil, status.Error(codes.Unauthenticated, "session not found")
+	}
+	return session, nil
+}
+
+// checkSession returns Unauthenticated unless sessionID is a live session
+func (s *OrchestratorServer) checkSession(rpc, sessionID string) error {
+	_, err := s.lookupSession(rpc, sessionID)
+	return err
+}
+
 // Heartbeat verifies a session is still valid
 func (s *OrchestratorServer) Heartbeat(ctx context.Context, req *pb.SessionInfo) (*pb.Empty, error) {
 	s.mu.RLock()
@@ -569,6 +746,8 @@ func (s *OrchestratorServer) GetDeviceStatus(ctx context.Context, req *pb.Device
 			GpuMemTotalMb: hostStatus.GPUMemTotalMB,
 			NpuLoad:       hostStatus.NPULoad,
 			TimestampMs:   hostStatus.Timestamp,
+			Power:         powerState(hostStatus),
+			Links:         links.ToProto(s.links.From(s.selfDeviceID)),
 		}, nil
 	}
 
@@ -625,6 +804,7 @@ func (s *OrchestratorServer) GetActivity(ctx context.Context, req *pb.GetActivit
 		Activity: &pb.ActivityData{
 			RunningTasks:     pbRunningTasks,
 			DeviceActivities: deviceActivities,
+			Links:            links.ToProto(s.links.All()),
 		},
 	}
 
@@ -659,8 +839,13 @@ func (s *OrchestratorServer) GetActivity(ctx context.Context, req *pb.GetActivit
 	return resp, nil
 }
 
-// GetDeviceMetrics returns metrics history for a specific device
-func (s *OrchestratorServer) GetDeviceMetrics(ctx context.Context, req *pb.DeviceId) (*pb.MetricsHistoryResponse, error) {
+// GetDeviceMetrics returns metrics history for a specific device: the live
+// samples, or aggregates from the long-term history when the query has bounds
+func (s *OrchestratorServer) GetDeviceMetrics(ctx context.Context, req *pb.MetricsQuery) (*pb.MetricsHistoryResponse, error) {
+	if req.FromMs != 0 || req.ToMs != 0 || req.ResolutionMs != 0 {
+		return s.queryMetricsHistory(req)
+	}
+
 	samples := s.metricsStore.GetHistory(req.DeviceId, 0)
 	deviceName, _ := s.metricsStore.GetDeviceInfo(req.DeviceId)
 
@@ -707,6 +892,13 @@ func (s *OrchestratorServer) GetJobDetail(ctx context.Context, req *pb.JobId) (*
 			GroupIndex:         int32(task.GroupIndex),
 			StartedAtMs:        task.StartedAt,
 			EndedAtMs:          task.EndedAt,
+			InputPaths:         task.InputPaths(),
+			StagedBytes:        task.StagedBytes,
+			Placement:          task.Placement,
+			Shell:              task.Shell,
+			Priority:           int32(task.Priority),
+			QueuePosition:      int32(s.dispatcher.Position(task.DeviceID, task.ID)),
+			Preemptions:        int32(task.Preemptions),
 		}
 	}
 
@@ -720,6 +912,8 @@ func (s *OrchestratorServer) GetJobDetail(ctx context.Context, req *pb.JobId) (*
 		CreatedAtMs:  job.CreatedAt.UnixMilli(),
 		StartedAtMs:  job.StartedAt.UnixMilli(),
 		EndedAtMs:    job.EndedAt.UnixMilli(),
+		ScheduleId:   job.ScheduleID,
+		Priority:     int32(job.Priority),
 	}, nil
 }
 
@@ -775,7 +969,7 @@ func (s *OrchestratorServer) getSelfDeviceInfo() *pb.DeviceInfo {
 	// Detect local Ollama/LLM availability
 	hasLocalModel, localModelName, localChatEndpoint := detectLocalModel()
 
-	return &pb.DeviceInfo{
+	info := &pb.DeviceInfo{
 		DeviceId:          s.selfDeviceID,
 		DeviceName:        hostname,
 		Platform:          runtime.GOOS,
@@ -790,6 +984,10 @@ func (s *OrchestratorServer) getSelfDeviceInfo() *pb.DeviceInfo {
 		LocalModelName:    localModelName,
 		LocalChatEndpoint: localChatEndpoint,
 	}
+	s.applyBenchmark(info)
+	info.Power = powerState(sysinfo.GetPowerStatus())
+	info.TaskKinds = tasks.Default.Advertise(info)
+	return info
 }
 
 // deriveBulkHTTPAddr combines the host from selfAddr with the port from bulkHTTPAddr.
@@ -917,7 +1115,9 @@ func (s *OrchestratorServer) autoRegisterWithCoordinator(coordinatorAddr string)
 
 		client := pb.NewOrchestratorServiceClient(conn)
 
-		// Register ourselves
+		// Register ourselves, with the latest benchmark and power state
+		s.applyBenchmark(selfInfo)
+		selfInfo.Power = powerState(sysinfo.GetPowerStatus())
 		ack, err := client.RegisterDevice(ctx, selfInfo)
 		cancel()
 		conn.Close()
@@ -1140,6 +1340,8 @@ func (s *OrchestratorServer) forwardCommand(ctx context.Context, targetAddr stri
 	conn, err := grpc.DialContext(dialCtx, targetAddr,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 		grpc.WithBlock(),
+		grpc.WithChainUnaryInterceptor(trace.UnaryClientInterceptor),
+		grpc.WithChainStreamInterceptor(trace.StreamClientInterceptor),
 	)
 	if err != nil {
 		log.Printf("[ERROR] forwardCommand: failed to dial %s: %v", targetAddr, err)
@@ -1187,69 +1389,44 @@ func (s *OrchestratorServer) forwardCommand(ctx context.Context, targetAddr stri
 
 // RunTask executes a task locally on this device (worker RPC)
 func (s *OrchestratorServer) RunTask(ctx context.Context, req *pb.TaskRequest) (*pb.TaskResult, error) {
+	if err := s.checkSession("RunTask", req.SessionId); err != nil {
+		return nil, err
+	}
+
 	start := time.Now()
 	log.Printf("[INFO] RunTask: task_id=%s job_id=%s kind=%s", req.TaskId, req.JobId, req.Kind)
+	span := trace.FromContext(ctx)
+	span.SetAttributes(trace.String("task.id", req.TaskId), trace.String("job.id", req.JobId), trace.String("task.kind", req.Kind))
 
-	switch req.Kind {
-	case "SYSINFO":
-		info := s.collectSysInfo()
-		return &pb.TaskResult{
-			TaskId: req.TaskId,
-			Ok:     true,
-			Output: info,
-			TimeMs: float64(time.Since(start).Milliseconds()),
-		}, nil
-
-	case "ECHO":
-		return &pb.TaskResult{
-			TaskId: req.TaskId,
-			Ok:     true,
-			Output: "echo: " + req.Input,
-			TimeMs: float64(time.Since(start).Milliseconds()),
-		}, nil
-
-	case "LLM_GENERATE":
-		output, err := s.runLLMGenerate(ctx, req.Input)
-		if err != nil {
-			return &pb.TaskResult{
-				TaskId: req.TaskId,
-				Ok:     false,
-				Error:  fmt.Sprintf("LLM_GENERATE failed: %v", err),
-				TimeMs: float64(time.Since(start).Milliseconds()),
-			}, nil
-		}
-		return &pb.TaskResult{
-			TaskId: req.TaskId,
-			Ok:     true,
-			Output: output,
-			TimeMs: float64(time.Since(start).Milliseconds()),
-		}, nil
+	s.tasksRunning.Add(1)
+	defer func() {
+		s.tasksRunning.Add(-1)
+		s.lastTaskEnd.Store(time.Now().UnixMilli())
+	}()
 
-	case "IMAGE_GENERATE":
-		imagePath, err := s.runImageGenerate(ctx, req.Input)
-		if err != nil {
-			return &pb.TaskResult{
-				TaskId: req.TaskId,
-				Ok:     false,
-				Error:  fmt.Sprintf("IMAGE_GENERATE failed: %v", err),
-				TimeMs: float64(time.Since(start).Milliseconds()),
-			}, nil
-		}
+	h, ok := tasks.Get(req.Kind)
+	if !ok {
 		return &pb.TaskResult{
 			TaskId: req.TaskId,
-			Ok:     true,
-			Output: imagePath, // Return path to generated image
+			Ok:     false,
+			Error:  "unknown task kind: " + req.Kind,
 			TimeMs: float64(time.Since(start).Milliseconds()),
 		}, nil
+	}
 
-	default:
+	result, err := h.Run(ctx, taskEnv{s}, req)
+	if err != nil {
+		span.RecordError(err)
 		return &pb.TaskResult{
 			TaskId: req.TaskId,
 			Ok:     false,
-			Error:  "unknown task kind: " + req.Kind,
+			Error:  fmt.Sprintf("%s failed: %v", req.Kind, err),
 			TimeMs: float64(time.Since(start).Milliseconds()),
 		}, nil
 	}
+	result.TaskId = req.TaskId
+	result.TimeMs = float64(time.Since(start).Milliseconds())
+	return result, nil
 }
 
 // runLLMGenerate executes LLM inference using this device's CHAT_PROVIDER.
@@ -1259,12 +1436,30 @@ func (s *OrchestratorServer) runLLMGenerate(ctx context.Context, prompt string)
 		return "", fmt.Errorf("chat provider not configured (set CHAT_PROVIDER in .env)")
 	}
 
+	ctx, span := trace.Start(ctx, "llm.chat", trace.String("llm.provider", s.chatProvider.Name()),
+		trace.Int("llm.prompt_chars", int64(len(prompt))))
+	defer span.End()
+
 	// Convert prompt to chat message format
 	messages := []llm.ChatMessage{{Role: "user", Content: prompt}}
 
-	// Use chat provider directly for simple text generation
-	result, err := s.chatProvider.Chat(ctx, messages)
+	// Use chat provider directly for simple text generation, with its
+	// prefill and decode timings if it reports them
+	var result string
+	var err error
+	if timed, ok := s.chatProvider.(llm.TimedChatProvider); ok {
+		var timing *llm.ChatTiming
+		result, timing, err = timed.ChatTimed(ctx, messages)
+		s.promMetrics.observeLLM(timing)
+		if timing != nil {
+			span.SetAttributes(trace.Int("llm.prompt_tokens", int64(timing.PromptTokens)),
+				trace.Int("llm.output_tokens", int64(timing.OutputTokens)))
+		}
+	} else {
+		result, err = s.chatProvider.Chat(ctx, messages)
+	}
 	if err != nil {
+		span.RecordError(err)
 		return "", fmt.Errorf("chat provider error: %w", err)
 	}
 
@@ -1277,6 +1472,19 @@ func (s *OrchestratorServer) runLLMGenerate(ctx context.Context, prompt string)
 	return result, nil
 }
 
+// runEmbed embeds texts with the local embed provider
+func (s *OrchestratorServer) runEmbed(ctx context.Context, texts []string) ([][]float32, error) {
+	if s.embedProvider == nil {
+		return nil, fmt.Errorf("embed provider not configured (set EMBED_PROVIDER in .env)")
+	}
+	vectors, err := s.embedProvider.Embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("embed provider error: %w", err)
+	}
+	log.Printf("[INFO] EMBED completed: provider=%s texts=%d", s.embedProvider.Name(), len(texts))
+	return vectors, nil
+}
+
 // runLLMGenerateOllama is a fallback that uses Ollama's native /api/chat endpoint.
 func (s *OrchestratorServer) runLLMGenerateOllama(ctx context.Context, endpoint, model, prompt string) (string, error) {
 	url := strings.TrimRight(endpoint, "/") + "/api/chat"
@@ -1357,15 +1565,23 @@ func (s *OrchestratorServer) SubmitJob(ctx context.Context, req *pb.JobRequest)
 		return nil, status.Error(codes.FailedPrecondition, "no devices available")
 	}
 
+	if req.FanOut != nil {
+		return s.submitFanOut(ctx, req.FanOut, devices, req.Priority)
+	}
+	if req.Map != nil {
+		return s.submitMap(ctx, req.Map, devices, req.Priority)
+	}
+
 	// Try to generate plan using LLM provider or brain if available and no plan provided
 	plan := req.Plan
 	reduce := req.Reduce
 	if plan == nil || len(plan.Groups) == 0 {
+		planCtx, span := trace.Start(ctx, "job.plan")
 		// Prefer cross-platform LLM provider over Windows AI brain
 		if s.llmProvider != nil {
 			// Use LLM provider for planning
 			var err error
-			plan, reduce, err = s.generatePlanWithLLM(req.Text, devices, int(req.MaxWorkers))
+			plan, reduce, err = s.generatePlanWithLLM(planCtx, req.Text, devices, int(req.MaxWorkers))
 			if err == nil && plan != nil {
 				log.Printf("[INFO] SubmitJob: LLM plan generated, groups=%d", len(plan.Groups))
 			} else if err != nil {
@@ -1386,11 +1602,22 @@ func (s *OrchestratorServer) SubmitJob(ctx context.Context, req *pb.JobRequest)
 				log.Printf("[WARN] SubmitJob: brain plan generation failed, using default: %v", err)
 			}
 		}
+		span.SetAttributes(trace.Bool("plan.generated", plan != nil))
+		span.End()
 	}
 
+	// Find where task inputs live so tasks can be placed near their data
+	schedCtx, span := trace.Start(ctx, "job.schedule", trace.Int("devices", int64(len(devices))))
+	locality := s.resolveLocality(schedCtx, plan, devices)
+
 	// Create job with tasks (plan and reduce will use smart defaults if nil)
-	job, err := s.jobManager.CreateJob(req.Text, devices, int(req.MaxWorkers), plan, reduce)
+	job, err := s.jobManager.CreateJob(req.Text, devices, int(req.MaxWorkers), plan, reduce, locality)
+	span.RecordError(err)
+	span.End()
 	if err != nil {
+		if errors.Is(err, jobs.ErrInputNotFound) || errors.Is(err, jobs.ErrUnsupportedKind) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
 		return nil, status.Errorf(codes.Internal, "failed to create job: %v", err)
 	}
 
@@ -1398,17 +1625,66 @@ func (s *OrchestratorServer) SubmitJob(ctx context.Context, req *pb.JobRequest)
 		job.ID, len(job.Tasks), job.TotalGroups, req.Text)
 
 	// Execute groups sequentially (tasks within groups run in parallel)
-	go s.executeJobGroups(job)
+	traceID := s.startJob(ctx, job, req.Priority)
 
 	return &pb.JobInfo{
 		JobId:     job.ID,
 		CreatedAt: job.CreatedAt.Unix(),
 		Summary:   fmt.Sprintf("distributed to %d devices in %d group(s)", len(job.Tasks), job.TotalGroups),
+		TraceId:   traceID,
+	}, nil
+}
+
+// submitFanOut creates and starts a job running one task on every matching device
+func (s *OrchestratorServer) submitFanOut(ctx context.Context, fo *pb.FanOut, devices []*pb.DeviceInfo, priority int32) (*pb.JobInfo, error) {
+	kind := fo.Kind
+	if kind == "" {
+		kind = "SHELL"
+	}
+	filter := jobs.FanOutFilter{Platforms: fo.Platforms, Capabilities: fo.Capabilities}
+	job, err := s.jobManager.FanOut(kind, fo.Input, devices, filter)
+	if err != nil {
+		switch {
+		case errors.Is(err, jobs.ErrUnknownCapability):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, jobs.ErrNoMatchingDevices), errors.Is(err, jobs.ErrUnsupportedKind):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to create job: %v", err)
+	}
+
+	log.Printf("[INFO] SubmitJob: fan-out job_id=%s kind=%s devices=%d platforms=%v capabilities=%v input=%q",
+		job.ID, kind, len(job.Tasks), fo.Platforms, fo.Capabilities, fo.Input)
+
+	traceID := s.startJob(ctx, job, priority)
+
+	return &pb.JobInfo{
+		JobId:     job.ID,
+		CreatedAt: job.CreatedAt.Unix(),
+		Summary:   fmt.Sprintf("fanned out %s to %d device(s)", kind, len(job.Tasks)),
+		TraceId:   traceID,
 	}, nil
 }
 
+// startJob executes job in the background at priority. The job outlives
+// the request that submitted it but continues its trace; the trace ID is
+// returned.
+func (s *OrchestratorServer) startJob(reqCtx context.Context, job *jobs.Job, priority int32) string {
+	s.jobManager.SetPriority(job.ID, int(priority))
+	ctx, span := trace.Start(trace.Detach(reqCtx), "job.execute",
+		trace.String("job.id", job.ID), trace.Int("job.groups", int64(job.TotalGroups)))
+	traceID := span.SpanContext().TraceID.String()
+	s.jobManager.SetTraceID(job.ID, traceID)
+	go func() {
+		defer span.End()
+		s.executeJobGroups(ctx, job)
+	}()
+	return traceID
+}
+
 // executeJobGroups executes task groups sequentially (tasks within groups run in parallel)
-func (s *OrchestratorServer) executeJobGroups(job *jobs.Job) {
+func (s *OrchestratorServer) executeJobGroups(ctx context.Context, job *jobs.Job) {
+	span := trace.FromContext(ctx)
 	s.jobManager.SetJobRunning(job.ID)
 
 	// Start metrics polling for active devices
@@ -1430,7 +1706,12 @@ func (s *OrchestratorServer) executeJobGroups(job *jobs.Job) {
 		groupTasks := s.jobManager.GetTasksForGroup(job.ID, groupIdx)
 
 		// Execute all tasks in this group in parallel
-		groupResults, failedCount := s.executeTaskGroup(job, groupTasks)
+		groupCtx, groupSpan := trace.Start(ctx, "job.group", trace.Int("group", int64(groupIdx)), trace.Int("tasks", int64(len(groupTasks))))
+		groupResults, failedCount := s.executeTaskGroup(groupCtx, job, groupTasks)
+		if failedCount > 0 {
+			groupSpan.SetError(fmt.Sprintf("%d task(s) failed", failedCount))
+		}
+		groupSpan.End()
 		allResults = append(allResults, groupResults...)
 		totalFailed += failedCount
 
@@ -1442,11 +1723,16 @@ func (s *OrchestratorServer) executeJobGroups(job *jobs.Job) {
 	}
 
 	// Apply reduce to combine results
-	finalResult := s.applyReduce(job.ReduceSpec, allResults)
-	if totalFailed > 0 {
+	finalResult := s.applyReduce(job, allResults)
+	// MAP results stay JSON lines; failed items carry their own error
+	if totalFailed > 0 && job.ReduceSpec.Kind != jobs.ReduceMap {
 		finalResult = fmt.Sprintf("Warning: %d task(s) failed\n\n%s", totalFailed, finalResult)
 	}
 	s.jobManager.SetJobDone(job.ID, finalResult)
+	span.SetAttributes(trace.Int("tasks.failed", int64(totalFailed)))
+	if totalFailed > 0 {
+		span.SetError(fmt.Sprintf("%d task(s) failed", totalFailed))
+	}
 
 	log.Printf("[INFO] executeJobGroups: job=%s completed, %d succeeded, %d failed",
 		job.ID, len(allResults), totalFailed)
@@ -1512,11 +1798,6 @@ func (s *OrchestratorServer) fetchAndStoreDeviceMetrics(ctx context.Context, dev
 
 		client := pb.NewOrchestratorServiceClient(conn)
 		status, err = client.GetDeviceStatus(pollCtx, &pb.DeviceId{DeviceId: deviceID})
-
-		// Update registry with fresh status if successful
-		if err == nil && status != nil {
-			s.registry.UpdateStatus(deviceID, status)
-		}
 	}
 
 	if err != nil {
@@ -1524,6 +1805,14 @@ func (s *OrchestratorServer) fetchAndStoreDeviceMetrics(ctx context.Context, dev
 		return
 	}
 
+	// Update registry with fresh status, including the power state routing uses
+	if status != nil {
+		s.registry.UpdateStatus(deviceID, status)
+		if deviceID != s.selfDeviceID {
+			s.storeReportedLinks(deviceID, status.Links)
+		}
+	}
+
 	// Convert to metrics sample and store
 	sample := metrics.MetricsSample{
 		Timestamp:     time.Now().UnixMilli(),
@@ -1561,8 +1850,9 @@ func (s *OrchestratorServer) startContinuousMetricsPolling(ctx context.Context)
 	}
 }
 
-// executeTaskGroup runs all tasks in a group in parallel
-func (s *OrchestratorServer) executeTaskGroup(job *jobs.Job, tasks []*jobs.Task) ([]string, int) {
+// executeTaskGroup runs all tasks in a group in parallel, each once its
+// device's dispatch queue gives it a slot
+func (s *OrchestratorServer) executeTaskGroup(groupCtx context.Context, job *jobs.Job, tasks []*jobs.Task) ([]string, int) {
 	var wg sync.WaitGroup
 	var results []string
 	var resultsMu sync.Mutex
@@ -1573,43 +1863,8 @@ func (s *OrchestratorServer) executeTaskGroup(job *jobs.Job, tasks []*jobs.Task)
 		go func(t *jobs.Task) {
 			defer wg.Done()
 
-			log.Printf("[INFO] executeTaskGroup: executing task=%s on device=%s addr=%s",
-				t.ID, t.DeviceName, t.DeviceAddr)
-
-			// Mark task as running
-			s.jobManager.SetTaskRunning(job.ID, t.ID)
-
-			// Create context with timeout
-			ctx, cancel := context.WithTimeout(context.Background(), 250*time.Second)
-			defer cancel()
-
-			// Dial the device
-			conn, err := grpc.DialContext(ctx, t.DeviceAddr,
-				grpc.WithTransportCredentials(insecure.NewCredentials()),
-				grpc.WithBlock(),
-			)
-			if err != nil {
-				log.Printf("[ERROR] executeTaskGroup: failed to dial %s: %v", t.DeviceAddr, err)
-				s.jobManager.UpdateTask(job.ID, t.ID, jobs.TaskFailed, "", err.Error())
-				resultsMu.Lock()
-				failedCount++
-				resultsMu.Unlock()
-				return
-			}
-			defer conn.Close()
-
-			client := pb.NewOrchestratorServiceClient(conn)
-
-			// Call RunTask on the device
-			result, err := client.RunTask(ctx, &pb.TaskRequest{
-				TaskId: t.ID,
-				JobId:  job.ID,
-				Kind:   t.Kind,
-				Input:  t.Input,
-			})
-
+			output, err := s.dispatchTask(groupCtx, job, t)
 			if err != nil {
-				log.Printf("[ERROR] executeTaskGroup: RunTask failed on %s: %v", t.DeviceAddr, err)
 				s.jobManager.UpdateTask(job.ID, t.ID, jobs.TaskFailed, "", err.Error())
 				resultsMu.Lock()
 				failedCount++
@@ -1617,24 +1872,10 @@ func (s *OrchestratorServer) executeTaskGroup(job *jobs.Job, tasks []*jobs.Task)
 				return
 			}
 
-			if !result.Ok {
-				log.Printf("[ERROR] executeTaskGroup: task failed on %s: %s", t.DeviceAddr, result.Error)
-				s.jobManager.UpdateTask(job.ID, t.ID, jobs.TaskFailed, "", result.Error)
-				resultsMu.Lock()
-				failedCount++
-				resultsMu.Unlock()
-				return
-			}
-
-			// Task succeeded
-			s.jobManager.UpdateTask(job.ID, t.ID, jobs.TaskDone, result.Output, "")
 			resultsMu.Lock()
 			results = append(results, fmt.Sprintf("=== %s (%s) ===\n%s",
-				t.DeviceName, t.DeviceID[:8], result.Output))
+				t.DeviceName, t.DeviceID[:8], output))
 			resultsMu.Unlock()
-
-			log.Printf("[INFO] executeTaskGroup: task=%s completed on %s in %.2fms",
-				t.ID, t.DeviceName, result.TimeMs)
 		}(task)
 	}
 
@@ -1642,8 +1883,128 @@ func (s *OrchestratorServer) executeTaskGroup(job *jobs.Job, tasks []*jobs.Task)
 	return results, failedCount
 }
 
+// dispatchTask waits for a slot on the task's device and runs it there,
+// requeueing it each time a more urgent task preempts it
+func (s *OrchestratorServer) dispatchTask(groupCtx context.Context, job *jobs.Job, t *jobs.Task) (string, error) {
+	for {
+		queueCtx, queueSpan := trace.Start(groupCtx, "task.queue", trace.String("task.id", t.ID),
+			trace.String("device.id", t.DeviceID), trace.Int("priority", int64(t.Priority)))
+		lease, err := s.dispatcher.Acquire(queueCtx, t.DeviceID, t.ID, t.Priority, t.Preemptible())
+		queueSpan.RecordError(err)
+		queueSpan.End()
+		if err != nil {
+			return "", err
+		}
+
+		ctx, cancel := lease.Context(groupCtx)
+		output, err := s.runTask(ctx, job, t)
+		preempted := err != nil && errors.Is(context.Cause(ctx), jobs.ErrPreempted)
+		cancel()
+		lease.Release()
+		if !preempted {
+			return output, err
+		}
+		log.Printf("[INFO] executeTaskGroup: task=%s preempted on %s, requeued", t.ID, t.DeviceName)
+		s.jobManager.RequeueTask(job.ID, t.ID)
+	}
+}
+
+// runTask runs one task on its device and returns its output. A task that
+// succeeds is marked done; the caller marks failed tasks.
+func (s *OrchestratorServer) runTask(ctx context.Context, job *jobs.Job, t *jobs.Task) (string, error) {
+	log.Printf("[INFO] executeTaskGroup: executing task=%s on device=%s addr=%s priority=%d",
+		t.ID, t.DeviceName, t.DeviceAddr, t.Priority)
+
+	// Mark task as running
+	s.jobManager.SetTaskRunning(job.ID, t.ID)
+
+	ctx, span := trace.Start(ctx, "task.run", trace.String("task.id", t.ID),
+		trace.String("task.kind", t.Kind), trace.String("device.id", t.DeviceID), trace.String("device.name", t.DeviceName))
+	defer span.End()
+
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(ctx, 250*time.Second)
+	defer cancel()
+
+	// Dial the device
+	dialCtx, dialSpan := trace.Start(ctx, "grpc.dial", trace.String("net.peer.address", t.DeviceAddr))
+	conn, err := grpc.DialContext(dialCtx, t.DeviceAddr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithBlock(),
+		grpc.WithChainUnaryInterceptor(trace.UnaryClientInterceptor),
+		grpc.WithChainStreamInterceptor(trace.StreamClientInterceptor),
+	)
+	dialSpan.RecordError(err)
+	dialSpan.End()
+	if err != nil {
+		span.RecordError(err)
+		log.Printf("[ERROR] executeTaskGroup: failed to dial %s: %v", t.DeviceAddr, err)
+		return "", err
+	}
+	defer conn.Close()
+
+	client := pb.NewOrchestratorServiceClient(conn)
+
+	// RunTask needs a session on the device
+	sessionResp, err := client.CreateSession(ctx, &pb.AuthRequest{
+		DeviceName:  "coordinator-task",
+		SecurityKey: "internal-routing",
+	})
+	if err != nil {
+		span.RecordError(err)
+		log.Printf("[ERROR] executeTaskGroup: failed to create session on %s: %v", t.DeviceAddr, err)
+		return "", err
+	}
+
+	// Copy inputs the device lacks before running the task
+	stageCtx, stageSpan := trace.Start(ctx, "task.stage", trace.Int("inputs", int64(len(t.Inputs))))
+	err = s.stageTaskInputs(stageCtx, t)
+	stageSpan.RecordError(err)
+	stageSpan.End()
+	if err != nil {
+		span.RecordError(err)
+		log.Printf("[ERROR] executeTaskGroup: staging inputs for task=%s failed: %v", t.ID, err)
+		return "", fmt.Errorf("staging inputs: %w", err)
+	}
+
+	// Call RunTask on the device
+	result, err := client.RunTask(ctx, &pb.TaskRequest{
+		SessionId:  sessionResp.SessionId,
+		TaskId:     t.ID,
+		JobId:      job.ID,
+		Kind:       t.Kind,
+		Input:      t.Input,
+		InputPaths: t.InputPaths(),
+	})
+
+	if err == nil && result.Shell != nil {
+		s.jobManager.SetTaskShell(job.ID, t.ID, result.Shell)
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		log.Printf("[ERROR] executeTaskGroup: RunTask failed on %s: %v", t.DeviceAddr, err)
+		return "", err
+	}
+
+	if !result.Ok {
+		span.SetError(result.Error)
+		log.Printf("[ERROR] executeTaskGroup: task failed on %s: %s", t.DeviceAddr, result.Error)
+		return "", errors.New(result.Error)
+	}
+
+	// Task succeeded
+	s.jobManager.SetTaskRunTime(job.ID, t.ID, result.TimeMs)
+	s.jobManager.UpdateTask(job.ID, t.ID, jobs.TaskDone, result.Output, "")
+
+	log.Printf("[INFO] executeTaskGroup: task=%s completed on %s in %.2fms",
+		t.ID, t.DeviceName, result.TimeMs)
+	return result.Output, nil
+}
+
 // applyReduce combines results based on the reduce specification
-func (s *OrchestratorServer) applyReduce(spec *jobs.ReduceSpec, results []string) string {
+func (s *OrchestratorServer) applyReduce(job *jobs.Job, results []string) string {
+	spec := job.ReduceSpec
 	if spec == nil {
 		spec = &jobs.ReduceSpec{Kind: "CONCAT"}
 	}
@@ -1651,6 +2012,9 @@ func (s *OrchestratorServer) applyReduce(spec *jobs.ReduceSpec, results []string
 	switch spec.Kind {
 	case "CONCAT":
 		return strings.Join(results, "\n\n")
+	case jobs.ReduceMap:
+		// Shard outputs are read from the tasks, which keep their raw results
+		return s.jobManager.MapResult(job.ID)
 	default:
 		// Default to CONCAT for unknown reduce kinds
 		return strings.Join(results, "\n\n")
@@ -1674,6 +2038,7 @@ func (s *OrchestratorServer) GetJob(ctx context.Context, req *pb.JobId) (*pb.Job
 			State:              string(t.State),
 			Result:             t.Result,
 			Error:              t.Error,
+			Shell:              t.Shell,
 		}
 	}
 
@@ -1684,6 +2049,8 @@ func (s *OrchestratorServer) GetJob(ctx context.Context, req *pb.JobId) (*pb.Job
 		FinalResult:  job.FinalResult,
 		CurrentGroup: int32(job.CurrentGroup),
 		TotalGroups:  int32(job.TotalGroups),
+		TraceId:      job.TraceID,
+		ScheduleId:   job.ScheduleID,
 	}, nil
 }
 
@@ -1800,36 +2167,77 @@ func (s *OrchestratorServer) PreviewPlanCost(ctx context.Context, req *pb.PlanCo
 		return nil, status.Error(codes.InvalidArgument, "plan is required and must have at least one group")
 	}
 
-	// Estimate costs
+	// Estimate costs, including staging inputs onto each device
 	estimator := cost.NewEstimator()
+	estimator.SetLocality(s.resolveLocality(ctx, req.Plan, devices))
+	estimator.SetCalibration(s.calibration)
+	estimator.SetLinks(s.links, s.selfDeviceID)
 	resp := estimator.EstimatePlanCost(req.Plan, devices)
 
-	log.Printf("[INFO] PreviewPlanCost: devices=%d total_ms=%.2f recommended=%s has_unknown=%v",
-		len(devices), resp.TotalPredictedMs, resp.RecommendedDeviceId, resp.HasUnknownCosts)
+	log.Printf("[INFO] PreviewPlanCost: devices=%d total_ms=%.2f recommended=%s has_unknown=%v confidence=%s samples=%d",
+		len(devices), resp.TotalPredictedMs, resp.RecommendedDeviceId, resp.HasUnknownCosts, resp.Confidence, resp.Samples)
 
 	return resp, nil
 }
 
 // StartWebRTC creates a new WebRTC peer connection and returns an offer SDP
 func (s *OrchestratorServer) StartWebRTC(ctx context.Context, req *pb.WebRTCConfig) (*pb.WebRTCOffer, error) {
-	log.Printf("[INFO] StartWebRTC: session=%s fps=%d quality=%d monitor=%d",
-		req.SessionId, req.TargetFps, req.JpegQuality, req.MonitorIndex)
-
-	streamID, offerSDP, err := s.webrtcManager.Start(
-		req.SessionId,
-		int(req.TargetFps),
-		int(req.JpegQuality),
-		int(req.MonitorIndex),
-	)
+	log.Printf("[INFO] StartWebRTC: session=%s viewer=%q mode=%q layer=%q accept=%v fps=%d quality=%d monitor=%d max_bitrate=%dkbps control=%v audio=%v(%s) trickle=%v record=%v",
+		req.SessionId, req.Viewer, req.Mode, req.Layer, req.AcceptModes, req.TargetFps, req.JpegQuality, req.MonitorIndex, req.MaxBitrateKbps, req.InputControl,
+		req.Audio, req.AudioSource, req.Trickle, req.Record)
+
+	opts := webrtcstream.Options{
+		TargetFPS:      int(req.TargetFps),
+		JPEGQuality:    int(req.JpegQuality),
+		MonitorIndex:   int(req.MonitorIndex),
+		Mode:           req.Mode,
+		AcceptModes:    req.AcceptModes,
+		MaxBitrateKbps: int(req.MaxBitrateKbps),
+		Audio:          req.Audio,
+		AudioSource:    req.AudioSource,
+		Trickle:        req.Trickle,
+		ICEServers:     iceServersFromPB(req.IceServers),
+		Layer:          req.Layer,
+		Viewer:         req.Viewer,
+		Record:         req.Record,
+	}
+	if _, err := s.checkStreamPermission(req.SessionId, rbac.PermStreamView); err != nil {
+		return nil, err
+	}
+	if req.InputControl {
+		principal, err := s.checkControlPermission(req.SessionId)
+		if err != nil {
+			return nil, err
+		}
+		opts.InputControl = true
+		opts.Principal = principal
+		opts.AuthorizeControl = approveControl
+	}
+
+	offer, err := s.webrtcManager.Start(req.SessionId, opts)
+	if errors.Is(err, webrtcstream.ErrTooManyViewers) {
+		log.Printf("[WARN] StartWebRTC: %d viewers already watching", s.webrtcManager.MaxViewers())
+		return nil, status.Errorf(codes.ResourceExhausted, "device serves at most %d viewers", s.webrtcManager.MaxViewers())
+	}
+	if errors.Is(err, webrtcstream.ErrRecordingDisabled) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
 	if err != nil {
 		log.Printf("[ERROR] StartWebRTC failed: %v", err)
 		return nil, status.Errorf(codes.Internal, "failed to start WebRTC: %v", err)
 	}
 
-	log.Printf("[INFO] StartWebRTC: created stream %s", streamID)
+	log.Printf("[INFO] StartWebRTC: created %s stream %s", offer.Mode, offer.StreamID)
 	return &pb.WebRTCOffer{
-		StreamId: streamID,
-		Sdp:      offerSDP,
+		StreamId:     offer.StreamID,
+		Sdp:          offer.SDP,
+		Mode:         offer.Mode,
+		InputControl: offer.InputControl,
+		Audio:        offer.Audio,
+		Trickle:      offer.Trickle,
+		IceServers:   iceServersToPB(offer.ICEServers),
+		Layer:        offer.Layer,
+		Recording:    s.relRecording(offer.Recording),
 	}, nil
 }
 
@@ -1859,7 +2267,8 @@ func (s *OrchestratorServer) StopWebRTC(ctx context.Context, req *pb.WebRTCStop)
 	return &pb.Empty{}, nil
 }
 
-// CreateDownloadTicket validates a file path and issues a one-time download token
+// CreateDownloadTicket validates a file path and issues a download token that
+// stays valid for the transfer session (resumes and range requests)
 func (s *OrchestratorServer) CreateDownloadTicket(ctx context.Context, req *pb.DownloadTicketRequest) (*pb.DownloadTicketResponse, error) {
 	path := req.Path
 	if path == "" {
@@ -1896,8 +2305,19 @@ func (s *OrchestratorServer) CreateDownloadTicket(ctx context.Context, req *pb.D
 		return nil, status.Errorf(codes.Internal, "failed to create ticket: %v", err)
 	}
 
-	log.Printf("[INFO] CreateDownloadTicket: path=%s size=%d token=%s...%s expires=%v",
-		fullPath, info.Size(), ticket.Token[:4], ticket.Token[len(ticket.Token)-4:],
+	// Warm the checksum cache so the first GET does not wait on hashing.
+	// A file still being written is hashed once it is finished.
+	live := s.ticketManager.Live(fullPath)
+	if !live {
+		go func() {
+			if _, err := s.ticketManager.Checksum(fullPath); err != nil {
+				log.Printf("[WARN] CreateDownloadTicket: checksum %s: %v", fullPath, err)
+			}
+		}()
+	}
+
+	log.Printf("[INFO] CreateDownloadTicket: path=%s size=%d live=%v token=%s...%s expires=%v",
+		fullPath, info.Size(), live, ticket.Token[:4], ticket.Token[len(ticket.Token)-4:],
 		ticket.ExpiresAt.Format(time.RFC3339))
 
 	return &pb.DownloadTicketResponse{
@@ -1905,6 +2325,7 @@ func (s *OrchestratorServer) CreateDownloadTicket(ctx context.Context, req *pb.D
 		Filename:      ticket.Filename,
 		SizeBytes:     ticket.SizeBytes,
 		ExpiresUnixMs: ticket.ExpiresAt.UnixMilli(),
+		Live:          live,
 	}, nil
 }
 
@@ -2106,6 +2527,8 @@ func (s *OrchestratorServer) forwardReadFile(ctx context.Context, req *pb.ReadFi
 	conn, err := grpc.DialContext(dialCtx, targetDevice.GrpcAddr,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 		grpc.WithBlock(),
+		grpc.WithChainUnaryInterceptor(trace.UnaryClientInterceptor),
+		grpc.WithChainStreamInterceptor(trace.StreamClientInterceptor),
 	)
 	if err != nil {
 		return &pb.ReadFileResponse{Error: fmt.Sprintf("failed to connect to device %s: %v", req.DeviceId, err)}, nil
@@ -2124,11 +2547,11 @@ func (s *OrchestratorServer) forwardReadFile(ctx context.Context, req *pb.ReadFi
 	}
 
 	// Forward request with remote session
-	remoteReq := *req
+	remoteReq := proto.Clone(req).(*pb.ReadFileRequest)
 	remoteReq.SessionId = sessionResp.SessionId
 	remoteReq.DeviceId = "" // Clear device_id so remote reads locally
 
-	return client.ReadFile(ctx, &remoteReq)
+	return client.ReadFile(ctx, remoteReq)
 }
 
 // isTextLike checks if content appears to be text (no null bytes, mostly printable)
@@ -2151,9 +2574,11 @@ func isTextLike(data []byte) bool {
 	return true
 }
 
-// handleBulkDownload serves file bytes for a valid, unexpired token
+// handleBulkDownload serves file bytes for a valid, unexpired token.
+// Tickets are redeemed rather than consumed so interrupted downloads can
+// resume with Range requests against the same URL.
 func (s *OrchestratorServer) handleBulkDownload(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
@@ -2170,8 +2595,8 @@ func (s *OrchestratorServer) handleBulkDownload(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	// Consume ticket (atomic: check + delete)
-	ticket := s.ticketManager.Consume(token)
+	// Redeem ticket (valid for the transfer session, expiry slides on use)
+	ticket := s.ticketManager.Redeem(token)
 	if ticket == nil {
 		http.Error(w, "invalid or expired token", http.StatusForbidden)
 		return
@@ -2186,28 +2611,50 @@ func (s *OrchestratorServer) handleBulkDownload(w http.ResponseWriter, r *http.R
 	}
 	defer f.Close()
 
-	// Set headers for download
-	w.Header().Set("Content-Type", "application/octet-stream")
-	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, ticket.Filename))
-	w.Header().Set("Content-Length", strconv.FormatInt(ticket.SizeBytes, 10))
+	if s.ticketManager.Live(ticket.FilePath) {
+		s.serveLiveFile(w, r, ticket, f)
+		return
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		log.Printf("[ERROR] handleBulkDownload: stat %s: %v", ticket.FilePath, err)
+		http.Error(w, "file not accessible", http.StatusInternalServerError)
+		return
+	}
 
-	// Stream file bytes
-	written, err := io.Copy(w, f)
+	// Content hash doubles as a strong ETag so If-Range detects a changed file.
+	sum, err := s.ticketManager.Checksum(ticket.FilePath)
 	if err != nil {
-		log.Printf("[ERROR] handleBulkDownload: stream error after %d bytes: %v", written, err)
+		log.Printf("[ERROR] handleBulkDownload: checksum %s: %v", ticket.FilePath, err)
+		http.Error(w, "file not accessible", http.StatusInternalServerError)
 		return
 	}
+	digest, _ := hex.DecodeString(sum)
+
+	// Set headers for download; ServeContent handles Range, If-Range and Content-Length
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, ticket.Filename))
+	w.Header().Set("ETag", `"`+sum+`"`)
+	w.Header().Set("X-Content-SHA256", sum)
+	w.Header().Set("Digest", "sha-256="+base64.StdEncoding.EncodeToString(digest))
+
+	http.ServeContent(w, r, ticket.Filename, info.ModTime(), f)
 
-	log.Printf("[INFO] handleBulkDownload: served %s (%d bytes)", ticket.Filename, written)
+	if r.Method == http.MethodGet {
+		log.Printf("[INFO] handleBulkDownload: served %s (%d bytes, range=%q)", ticket.Filename, info.Size(), r.Header.Get("Range"))
+	}
 }
 
-// startBulkHTTP starts the HTTP server for bulk file downloads
+// startBulkHTTP starts the HTTP server for bulk file downloads and uploads
 func (s *OrchestratorServer) startBulkHTTP() {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/bulk/download/", s.handleBulkDownload)
+	mux.HandleFunc("/bulk/upload/", s.handleBulkUpload)
+	mux.HandleFunc(links.ProbePath, links.ProbeHandler)
 
 	log.Printf("[INFO] Bulk HTTP server listening on %s", s.bulkHTTPAddr)
-	if err := http.ListenAndServe(s.bulkHTTPAddr, mux); err != nil {
+	if err := http.ListenAndServe(s.bulkHTTPAddr, trace.Handler(s.promMetrics.countBulk(mux), traceNoRoot)); err != nil {
 		log.Fatalf("[FATAL] Bulk HTTP server failed: %v", err)
 	}
 }
@@ -2626,6 +3073,10 @@ func (h *WebHandler) handleDevices(w http.ResponseWriter, r *http.Request) {
 			HasLocalModel:     d.HasLocalModel,
 			LocalModelName:    d.LocalModelName,
 			LocalChatEndpoint: d.LocalChatEndpoint,
+			TaskKinds:         d.TaskKinds,
+			LLMPrefillTPS:     d.LlmPrefillToksPerS,
+			LLMDecodeTPS:      d.LlmDecodeToksPerS,
+			Power:             d.Power,
 		})
 	}
 
@@ -2667,6 +3118,11 @@ func (h *WebHandler) handleRoutedCmd(w http.ResponseWriter, r *http.Request) {
 	case "FORCE_DEVICE_ID":
 		policy.Mode = pb.RoutingPolicy_FORCE_DEVICE_ID
 		policy.DeviceId = req.ForceDeviceID
+	case "PREFER_PLUGGED_IN":
+		policy.Mode = pb.RoutingPolicy_PREFER_PLUGGED_IN
+	case "AVOID_LOW_BATTERY":
+		policy.Mode = pb.RoutingPolicy_AVOID_LOW_BATTERY
+		policy.MinBatteryPercent = req.MinBattery
 	}
 
 	cmdResp, err := h.orchestrator.ExecuteRoutedCommand(ctx, &pb.RoutedCommandRequest{
@@ -2957,6 +3413,7 @@ func (h *WebHandler) handleSubmitJob(w http.ResponseWriter, r *http.Request) {
 		SessionId:  sessionID,
 		Text:       req.Text,
 		MaxWorkers: req.MaxWorkers,
+		Priority:   req.Priority,
 	})
 	if err != nil {
 		log.Printf("[ERROR] SubmitJob failed: %v", err)
@@ -2968,6 +3425,7 @@ func (h *WebHandler) handleSubmitJob(w http.ResponseWriter, r *http.Request) {
 		JobID:     jobResp.JobId,
 		CreatedAt: jobResp.CreatedAt,
 		Summary:   jobResp.Summary,
+		TraceID:   jobResp.TraceId,
 	})
 }
 
@@ -3003,6 +3461,7 @@ func (h *WebHandler) handleGetJob(w http.ResponseWriter, r *http.Request) {
 			State:              t.State,
 			Result:             t.Result,
 			Error:              t.Error,
+			Shell:              t.Shell,
 		}
 	}
 
@@ -3013,6 +3472,8 @@ func (h *WebHandler) handleGetJob(w http.ResponseWriter, r *http.Request) {
 		FinalResult:  jobResp.FinalResult,
 		CurrentGroup: jobResp.CurrentGroup,
 		TotalGroups:  jobResp.TotalGroups,
+		TraceID:      jobResp.TraceId,
+		ScheduleID:   jobResp.ScheduleId,
 	})
 }
 
@@ -3047,6 +3508,7 @@ func (h *WebHandler) handleActivity(w http.ResponseWriter, r *http.Request) {
 		"activity": map[string]interface{}{
 			"running_tasks":     resp.Activity.RunningTasks,
 			"device_activities": resp.Activity.DeviceActivities,
+			"links":             resp.Activity.Links,
 		},
 	}
 
@@ -3064,8 +3526,12 @@ func (h *WebHandler) handleDeviceMetrics(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	deviceID := r.URL.Query().Get("device_id")
-	if deviceID == "" {
+	query, err := parseMetricsQuery(r.URL.Query())
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if query.DeviceId == "" {
 		h.writeError(w, http.StatusBadRequest, "device_id parameter is required")
 		return
 	}
@@ -3073,7 +3539,7 @@ func (h *WebHandler) handleDeviceMetrics(w http.ResponseWriter, r *http.Request)
 	ctx, cancel := context.WithTimeout(r.Context(), webRequestTimeout)
 	defer cancel()
 
-	resp, err := h.orchestrator.GetDeviceMetrics(ctx, &pb.DeviceId{DeviceId: deviceID})
+	resp, err := h.orchestrator.GetDeviceMetrics(ctx, query)
 	if err != nil {
 		log.Printf("[ERROR] GetDeviceMetrics failed: %v", err)
 		h.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Metrics error: %v", err))
@@ -3228,6 +3694,11 @@ func (h *WebHandler) handlePlanCost(w http.ResponseWriter, r *http.Request) {
 				PredictedMemoryMB: sc.PredictedMemoryMb,
 				UnknownCost:       sc.UnknownCost,
 				Notes:             sc.Notes,
+				TransferMs:        sc.TransferMs,
+				TransferBytes:     sc.TransferBytes,
+				Confidence:        sc.Confidence,
+				Samples:           sc.Samples,
+				EnergyJ:           sc.EnergyJ,
 			}
 		}
 		deviceCosts[i] = DeviceCostResponse{
@@ -3237,6 +3708,10 @@ func (h *WebHandler) handlePlanCost(w http.ResponseWriter, r *http.Request) {
 			StepCosts:          stepCosts,
 			EstimatedPeakRAMMB: dc.EstimatedPeakRamMb,
 			RAMSufficient:      dc.RamSufficient,
+			Confidence:         dc.Confidence,
+			Samples:            dc.Samples,
+			EnergyJ:            dc.EnergyJ,
+			BatteryDrain:       dc.BatteryDrainPercent,
 		}
 	}
 
@@ -3247,6 +3722,9 @@ func (h *WebHandler) handlePlanCost(w http.ResponseWriter, r *http.Request) {
 		RecommendedDeviceName: costResp.RecommendedDeviceName,
 		HasUnknownCosts:       costResp.HasUnknownCosts,
 		Warning:               costResp.Warning,
+		Confidence:            costResp.Confidence,
+		Samples:               costResp.Samples,
+		TotalEnergyJ:          costResp.TotalEnergyJ,
 	})
 }
 
@@ -3258,12 +3736,13 @@ func (h *WebHandler) convertJSONToPlan(planJSON interface{}) (*pb.Plan, error) {
 	}
 
 	type taskInput struct {
-		TaskID          string `json:"task_id"`
-		Kind            string `json:"kind"`
-		Input           string `json:"input"`
-		TargetDeviceID  string `json:"target_device_id"`
-		PromptTokens    int32  `json:"prompt_tokens"`
-		MaxOutputTokens int32  `json:"max_output_tokens"`
+		TaskID          string              `json:"task_id"`
+		Kind            string              `json:"kind"`
+		Input           string              `json:"input"`
+		TargetDeviceID  string              `json:"target_device_id"`
+		PromptTokens    int32               `json:"prompt_tokens"`
+		MaxOutputTokens int32               `json:"max_output_tokens"`
+		Inputs          []*pb.InputArtifact `json:"inputs"`
 	}
 	type groupInput struct {
 		Index int32       `json:"index"`
@@ -3298,6 +3777,7 @@ func (h *WebHandler) convertJSONToPlan(planJSON interface{}) (*pb.Plan, error) {
 				TargetDeviceId:  t.TargetDeviceID,
 				PromptTokens:    t.PromptTokens,
 				MaxOutputTokens: t.MaxOutputTokens,
+				Inputs:          t.Inputs,
 			}
 		}
 		protoPlan.Groups[i] = protoGroup
@@ -3322,7 +3802,15 @@ func (h *WebHandler) handleStreamStart(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), webRequestTimeout)
 	defer cancel()
 
-	sessionID := h.orchestrator.CreateInternalSession("web-stream")
+	sessionID := h.orchestrator.createStreamSession(req.Key)
+	viewer := req.Viewer
+	if viewer == "" {
+		viewer = clientHost(r)
+	}
+	if req.Record != "" && req.Record != recordOnDevice && req.Record != recordOnCoordinator {
+		h.writeError(w, http.StatusBadRequest, fmt.Sprintf("record must be %q or %q", recordOnDevice, recordOnCoordinator))
+		return
+	}
 
 	devicesResp, err := h.orchestrator.ListDevices(ctx, &pb.ListDevicesRequest{})
 	if err != nil {
@@ -3386,14 +3874,25 @@ func (h *WebHandler) handleStreamStart(w http.ResponseWriter, r *http.Request) {
 	// For local device, call directly
 	if selectedDevice.DeviceId == h.orchestrator.selfDeviceID {
 		webrtcResp, err := h.orchestrator.StartWebRTC(ctx, &pb.WebRTCConfig{
-			SessionId:    sessionID,
-			TargetFps:    req.FPS,
-			JpegQuality:  req.Quality,
-			MonitorIndex: req.MonitorIndex,
+			SessionId:      sessionID,
+			TargetFps:      req.FPS,
+			JpegQuality:    req.Quality,
+			MonitorIndex:   req.MonitorIndex,
+			Mode:           req.Mode,
+			AcceptModes:    req.AcceptModes,
+			MaxBitrateKbps: req.MaxBitrateKbps,
+			InputControl:   req.Control,
+			Audio:          req.Audio,
+			AudioSource:    req.AudioSource,
+			Trickle:        req.Trickle,
+			IceServers:     h.orchestrator.relayICEServers(),
+			Layer:          req.Layer,
+			Viewer:         viewer,
+			Record:         req.Record != "",
 		})
 		if err != nil {
 			log.Printf("[ERROR] handleStreamStart: StartWebRTC failed: %v", err)
-			h.writeError(w, http.StatusInternalServerError, fmt.Sprintf("StartWebRTC error: %v", err))
+			h.writeError(w, streamStartStatus(err), fmt.Sprintf("StartWebRTC error: %v", err))
 			return
 		}
 
@@ -3403,6 +3902,14 @@ func (h *WebHandler) handleStreamStart(w http.ResponseWriter, r *http.Request) {
 			SelectedDeviceAddr: selectedDevice.GrpcAddr,
 			StreamID:           webrtcResp.StreamId,
 			OfferSDP:           webrtcResp.Sdp,
+			Mode:               webrtcResp.Mode,
+			Control:            webrtcResp.InputControl,
+			Audio:              webrtcResp.Audio,
+			Trickle:            webrtcResp.Trickle,
+			ICEServers:         streamICEServers(webrtcResp.IceServers),
+			Layer:              webrtcResp.Layer,
+			Recording:          webrtcResp.Recording,
+			RecordingDeviceID:  recordingDevice(webrtcResp.Recording, selectedDevice.DeviceId),
 		})
 		return
 	}
@@ -3414,6 +3921,8 @@ func (h *WebHandler) handleStreamStart(w http.ResponseWriter, r *http.Request) {
 	conn, err := grpc.DialContext(dialCtx, selectedDevice.GrpcAddr,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 		grpc.WithBlock(),
+		grpc.WithChainUnaryInterceptor(trace.UnaryClientInterceptor),
+		grpc.WithChainStreamInterceptor(trace.StreamClientInterceptor),
 	)
 	if err != nil {
 		log.Printf("[ERROR] handleStreamStart: failed to dial device %s: %v", selectedDevice.GrpcAddr, err)
@@ -3424,26 +3933,73 @@ func (h *WebHandler) handleStreamStart(w http.ResponseWriter, r *http.Request) {
 
 	deviceClient := pb.NewOrchestratorServiceClient(conn)
 
+	// The device checks permissions against its own session, so open one
+	// there with the viewer's key; the device's own policy maps it to a
+	// principal
+	remoteKey := req.Key
+	if remoteKey == "" {
+		remoteKey = "internal-routing"
+	}
+	remoteSession, err := deviceClient.CreateSession(ctx, &pb.AuthRequest{
+		DeviceName:  "web-stream",
+		SecurityKey: remoteKey,
+	})
+	if err != nil {
+		log.Printf("[ERROR] handleStreamStart: CreateSession on %s failed: %v", selectedDevice.GrpcAddr, err)
+		h.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to create session on device: %v", err))
+		return
+	}
+
 	webrtcResp, err := deviceClient.StartWebRTC(ctx, &pb.WebRTCConfig{
-		SessionId:    sessionID,
-		TargetFps:    req.FPS,
-		JpegQuality:  req.Quality,
-		MonitorIndex: req.MonitorIndex,
+		SessionId:      remoteSession.SessionId,
+		TargetFps:      req.FPS,
+		JpegQuality:    req.Quality,
+		MonitorIndex:   req.MonitorIndex,
+		Mode:           req.Mode,
+		AcceptModes:    req.AcceptModes,
+		MaxBitrateKbps: req.MaxBitrateKbps,
+		InputControl:   req.Control,
+		Audio:          req.Audio,
+		AudioSource:    req.AudioSource,
+		Trickle:        req.Trickle,
+		IceServers:     h.orchestrator.relayICEServers(),
+		Layer:          req.Layer,
+		Viewer:         viewer,
+		Record:         req.Record != "",
 	})
 	if err != nil {
 		log.Printf("[ERROR] handleStreamStart: StartWebRTC failed: %v", err)
-		h.writeError(w, http.StatusInternalServerError, fmt.Sprintf("StartWebRTC error: %v", err))
+		h.writeError(w, streamStartStatus(err), fmt.Sprintf("StartWebRTC error: %v", err))
 		return
 	}
 
 	log.Printf("[INFO] handleStreamStart: stream %s started on %s", webrtcResp.StreamId, selectedDevice.DeviceName)
 
+	// The device records; the coordinator keeps a copy as it is written
+	recording, recordingDeviceID := webrtcResp.Recording, recordingDevice(webrtcResp.Recording, selectedDevice.DeviceId)
+	if recording != "" && req.Record == recordOnCoordinator {
+		local, err := h.orchestrator.mirrorRecording(ctx, selectedDevice, webrtcResp.StreamId, recording)
+		if err != nil {
+			log.Printf("[WARN] handleStreamStart: mirroring recording from %s: %v", selectedDevice.DeviceName, err)
+		} else {
+			recording, recordingDeviceID = local, h.orchestrator.selfDeviceID
+		}
+	}
+
 	h.writeJSON(w, http.StatusOK, StreamStartResponse{
 		SelectedDeviceID:   selectedDevice.DeviceId,
 		SelectedDeviceName: selectedDevice.DeviceName,
 		SelectedDeviceAddr: selectedDevice.GrpcAddr,
 		StreamID:           webrtcResp.StreamId,
 		OfferSDP:           webrtcResp.Sdp,
+		Mode:               webrtcResp.Mode,
+		Control:            webrtcResp.InputControl,
+		Audio:              webrtcResp.Audio,
+		Trickle:            webrtcResp.Trickle,
+		ICEServers:         streamICEServers(webrtcResp.IceServers),
+		Layer:              webrtcResp.Layer,
+		Recording:          recording,
+		RecordingDeviceID:  recordingDeviceID,
 	})
 }
 
@@ -3489,6 +4045,8 @@ func (h *WebHandler) handleStreamAnswer(w http.ResponseWriter, r *http.Request)
 	conn, err := grpc.DialContext(dialCtx, req.SelectedDeviceAddr,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 		grpc.WithBlock(),
+		grpc.WithChainUnaryInterceptor(trace.UnaryClientInterceptor),
+		grpc.WithChainStreamInterceptor(trace.StreamClientInterceptor),
 	)
 	if err != nil {
 		log.Printf("[ERROR] handleStreamAnswer: failed to dial %s: %v", req.SelectedDeviceAddr, err)
@@ -3555,6 +4113,8 @@ func (h *WebHandler) handleStreamStop(w http.ResponseWriter, r *http.Request) {
 	conn, err := grpc.DialContext(dialCtx, req.SelectedDeviceAddr,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 		grpc.WithBlock(),
+		grpc.WithChainUnaryInterceptor(trace.UnaryClientInterceptor),
+		grpc.WithChainStreamInterceptor(trace.StreamClientInterceptor),
 	)
 	if err != nil {
 		log.Printf("[ERROR] handleStreamStop: failed to dial %s: %v", req.SelectedDeviceAddr, err)
@@ -3573,6 +4133,7 @@ func (h *WebHandler) handleStreamStop(w http.ResponseWriter, r *http.Request) {
 		h.writeError(w, http.StatusInternalServerError, fmt.Sprintf("StopWebRTC error: %v", err))
 		return
 	}
+	h.orchestrator.mirrors.stop(req.StreamID)
 
 	log.Printf("[INFO] handleStreamStop: stream %s stopped", req.StreamID)
 
@@ -3645,6 +4206,7 @@ func (h *WebHandler) handleRequestDownload(w http.ResponseWriter, r *http.Reques
 			Filename:      ticketResp.Filename,
 			SizeBytes:     ticketResp.SizeBytes,
 			ExpiresUnixMs: ticketResp.ExpiresUnixMs,
+			Live:          ticketResp.Live,
 		})
 		return
 	}
@@ -3655,6 +4217,8 @@ func (h *WebHandler) handleRequestDownload(w http.ResponseWriter, r *http.Reques
 	conn, err := grpc.DialContext(dialCtx, targetDevice.GrpcAddr,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 		grpc.WithBlock(),
+		grpc.WithChainUnaryInterceptor(trace.UnaryClientInterceptor),
+		grpc.WithChainStreamInterceptor(trace.StreamClientInterceptor),
 	)
 	if err != nil {
 		log.Printf("[ERROR] handleRequestDownload: failed to dial device %s: %v", targetDevice.GrpcAddr, err)
@@ -3684,6 +4248,7 @@ func (h *WebHandler) handleRequestDownload(w http.ResponseWriter, r *http.Reques
 		Filename:      ticketResp.Filename,
 		SizeBytes:     ticketResp.SizeBytes,
 		ExpiresUnixMs: ticketResp.ExpiresUnixMs,
+		Live:          ticketResp.Live,
 	})
 }
 
@@ -4101,7 +4666,16 @@ func (h *WebHandler) handleAgent(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
+	// The turn is for the target device; it goes ahead of queued tasks
+	// there and, with preemption on, stops a running one
+	lease, err := h.orchestrator.dispatcher.Acquire(ctx, deviceID,
+		"agent-"+uuid.New().String(), jobs.PriorityInteractive, false)
+	if err != nil {
+		h.writeError(w, http.StatusServiceUnavailable, fmt.Sprintf("Device busy: %v", err))
+		return
+	}
 	resp, err := h.agent.Run(ctx, req.Message, history)
+	lease.Release()
 	if err != nil {
 		log.Printf("[ERROR] handleAgent: %v", err)
 		h.writeError(w, http.StatusInternalServerError, fmt.Sprintf("Agent error: %v", err))
@@ -4250,6 +4824,8 @@ func (h *WebHandler) handleLLMTask(w http.ResponseWriter, r *http.Request) {
 		conn, err := grpc.DialContext(ctx, result.Device.GrpcAddr,
 			grpc.WithTransportCredentials(insecure.NewCredentials()),
 			grpc.WithBlock(),
+			grpc.WithChainUnaryInterceptor(trace.UnaryClientInterceptor),
+			grpc.WithChainStreamInterceptor(trace.StreamClientInterceptor),
 		)
 		if err != nil {
 			h.writeError(w, http.StatusBadGateway, fmt.Sprintf("Failed to connect to device: %v", err))
@@ -4371,10 +4947,15 @@ func main() {
 		log.Printf("[INFO] LLM provider: disabled")
 	}
 
-	// Create gRPC server
-	grpcServer := grpc.NewServer()
+	// Create gRPC server, tracing calls and counting and timing every call for /metrics
 	orchestrator := NewOrchestratorServer(addr)
 	orchestrator.llmProvider = llmProvider // Inject LLM provider
+	flushTraces := setupTracing(orchestrator.selfDeviceID, orchestrator.getSelfDeviceInfo().DeviceName)
+	defer flushTraces(context.Background())
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(trace.UnaryServerInterceptor(traceRPC), orchestrator.promMetrics.unaryInterceptor),
+		grpc.ChainStreamInterceptor(trace.StreamServerInterceptor(traceRPC), orchestrator.promMetrics.streamInterceptor),
+	)
 
 	// Auto-register self so list-devices always shows this server
 	orchestrator.registerSelf()
@@ -4386,6 +4967,9 @@ func main() {
 	log.Printf("[INFO] Server gRPC address: %s", orchestrator.selfAddr)
 	log.Printf("[INFO] Bulk HTTP address: %s", orchestrator.deriveBulkHTTPAddr())
 	log.Printf("[INFO] Shared directory: %s", orchestrator.sharedRoot)
+	if v := os.Getenv("ALLOWED_COMMANDS"); v != "" {
+		allowlist.Allow(strings.Split(v, ",")...)
+	}
 	log.Printf("[INFO] Allowed commands: %v", allowlist.ListAllowed())
 	log.Printf("[INFO] Windows AI Brain available: %v", orchestrator.brain.IsAvailable())
 
@@ -4397,6 +4981,27 @@ func main() {
 	defer metricsCancel()
 	go orchestrator.startContinuousMetricsPolling(metricsCtx)
 
+	// Measure round-trip time and throughput to peers for placement
+	go orchestrator.probeLinks(metricsCtx, linkProbeIntervalFromEnv(), linkProbeBytesFromEnv())
+	go orchestrator.metricsStore.Archive().SaveLoop(metricsCtx, metrics.SaveInterval)
+	go orchestrator.calibration.SaveLoop(metricsCtx, cost.CalibrationSaveInterval)
+	go orchestrator.alertEngine.Run(metricsCtx, alerts.EvalInterval)
+	go orchestrator.runScheduler(metricsCtx)
+
+	// Start shared folder sync (opt-in via SYNC_ENABLED)
+	syncCtx, syncCancel := context.WithCancel(context.Background())
+	defer syncCancel()
+	orchestrator.startSync(syncCtx)
+
+	// Start the TURN relay for streams across subnets (opt-in via TURN_ADDR)
+	orchestrator.startTURNRelay()
+
+	// Stop streams whose viewer went away without stopping them
+	streamCtx, streamCancel := context.WithCancel(context.Background())
+	defer streamCancel()
+	go orchestrator.webrtcManager.ReapIdle(streamCtx, orchestrator.streamIdle)
+	defer orchestrator.mirrors.stopAll()
+
 	// Get dev key from environment
 	devKey := os.Getenv("DEV_KEY")
 	if devKey == "" {
@@ -4425,6 +5030,21 @@ func main() {
 		log.Printf("[INFO] Chat provider: disabled")
 	}
 
+	// Initialize Embed provider (optional, falls back to the chat settings)
+	embedProvider, err := llm.NewEmbedFromEnv()
+	if err != nil {
+		log.Printf("[WARN] Embed provider init failed: %v", err)
+	}
+	if embedProvider != nil {
+		log.Printf("[INFO] Embed provider: %s", embedProvider.Name())
+		orchestrator.embedProvider = embedProvider
+	}
+
+	// Keep the advertised LLM benchmark current, re-running it when idle
+	benchCtx, benchCancel := context.WithCancel(context.Background())
+	defer benchCancel()
+	go orchestrator.benchmarkWhenIdle(benchCtx, benchIntervalFromEnv())
+
 	// Initialize Agent (LLM tool-calling)
 	agentGRPCAddr := "localhost:50051"
 	if idx := strings.LastIndex(addr, ":"); idx >= 0 {
@@ -4469,11 +5089,17 @@ func main() {
 	httpMux.HandleFunc("/api/job-detail", webHandler.handleJobDetail)
 	httpMux.HandleFunc("/api/activity", webHandler.handleActivity)
 	httpMux.HandleFunc("/api/device-metrics", webHandler.handleDeviceMetrics)
+	httpMux.HandleFunc("/api/metrics/export", webHandler.handleMetricsExport)
 	httpMux.HandleFunc("/api/plan", webHandler.handlePreviewPlan)
 	httpMux.HandleFunc("/api/plan-cost", webHandler.handlePlanCost)
+	httpMux.HandleFunc("/api/task-kinds", webHandler.handleTaskKinds)
 	httpMux.HandleFunc("/api/stream/start", webHandler.handleStreamStart)
 	httpMux.HandleFunc("/api/stream/answer", webHandler.handleStreamAnswer)
 	httpMux.HandleFunc("/api/stream/stop", webHandler.handleStreamStop)
+	httpMux.HandleFunc("/api/stream/ice", webHandler.handleStreamICE)
+	httpMux.HandleFunc("/api/stream/ice/poll", webHandler.handleStreamICEPoll)
+	httpMux.HandleFunc("/api/streams", webHandler.handleStreams)
+	httpMux.HandleFunc("/api/audit", webHandler.handleAudit)
 	httpMux.HandleFunc("/api/request-download", webHandler.handleRequestDownload)
 
 	// QAI Hub endpoints
@@ -4495,6 +5121,9 @@ func main() {
 	// LLM task routing endpoint
 	httpMux.HandleFunc("/api/llm-task", webHandler.handleLLMTask)
 
+	// Prometheus metrics
+	httpMux.Handle("/metrics", orchestrator.promMetrics.registry)
+
 	// Start HTTP Web UI server in goroutine
 	webAddr := os.Getenv("WEB_ADDR")
 	if webAddr == "" {
@@ -4527,9 +5156,13 @@ func main() {
 			HasLocalModel:     selfInfo.HasLocalModel,
 			LocalModelName:    selfInfo.LocalModelName,
 			LocalChatEndpoint: selfInfo.LocalChatEndpoint,
+			TaskKinds:         selfInfo.TaskKinds,
+			LLMPrefillTPS:     selfInfo.LlmPrefillToksPerS,
+			LLMDecodeTPS:      selfInfo.LlmDecodeToksPerS,
 		}
 
 		discoverySvc := discovery.NewService(discoveryPort, selfDevice, &discoveryCallback{registry: orchestrator.registry})
+		orchestrator.discoverySvc = discoverySvc
 
 		// Add seed peers for cross-subnet discovery
 		if seedPeers := os.Getenv("SEED_PEERS"); seedPeers != "" {
@@ -4569,7 +5202,7 @@ func main() {
 		webPort = webAddr[idx:]
 	}
 	log.Printf("[INFO] Open http://localhost%s in your browser", webPort)
-	if err := http.ListenAndServe(webAddr, httpMux); err != nil {
+	if err := http.ListenAndServe(webAddr, trace.Handler(httpMux, traceWeb)); err != nil {
 		log.Fatalf("[FATAL] HTTP server failed: %v", err)
 	}
 }
@@ -4594,6 +5227,9 @@ func (dc *discoveryCallback) OnDeviceDiscovered(device *discovery.DeviceAnnounce
 		device.HasLocalModel,
 		device.LocalModelName,
 		device.LocalChatEndpoint,
+		device.TaskKinds,
+		device.LLMPrefillTPS,
+		device.LLMDecodeTPS,
 	)
 }
 
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/edgecli/edgecli/internal/jobs"
+	pb "github.com/edgecli/edgecli/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// mapMaxItems caps the items of one map job
+	mapMaxItems = 10000
+	// mapMaxFileBytes is the largest line-per-item file a map job reads
+	mapMaxFileBytes = 10 * 1024 * 1024
+	// mapMaxItemBytes is how much of each file in a directory becomes its item
+	mapMaxItemBytes = 1024 * 1024
+)
+
+// errMapInput marks a map request whose items cannot be loaded
+var errMapInput = errors.New("map input")
+
+// submitMap loads the items of a map request and starts a sharded job
+func (s *OrchestratorServer) submitMap(ctx context.Context, spec *pb.MapSpec, devices []*pb.DeviceInfo, priority int32) (*pb.JobInfo, error) {
+	kind := spec.Kind
+	if kind == "" {
+		kind = "LLM_GENERATE"
+	}
+
+	items, names, err := s.loadMapItems(ctx, spec)
+	if err != nil {
+		if errors.Is(err, errMapInput) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Errorf(codes.Unavailable, "load map items: %v", err)
+	}
+
+	job, err := s.jobManager.Map(jobs.MapInput{
+		Kind:       kind,
+		Prompt:     spec.Prompt,
+		Items:      items,
+		Names:      names,
+		ChunkItems: int(spec.ChunkItems),
+	}, devices)
+	if err != nil {
+		if errors.Is(err, jobs.ErrUnsupportedKind) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	shards := make(map[string]int)
+	for _, t := range job.Tasks {
+		shards[t.DeviceID]++
+	}
+	log.Printf("[INFO] SubmitJob: map job_id=%s kind=%s items=%d tasks=%d groups=%d shards=%v",
+		job.ID, kind, len(items), len(job.Tasks), job.TotalGroups, shards)
+
+	traceID := s.startJob(ctx, job, priority)
+
+	return &pb.JobInfo{
+		JobId:     job.ID,
+		CreatedAt: job.CreatedAt.Unix(),
+		Summary:   fmt.Sprintf("mapped %d item(s) over %d device(s) in %d task(s)", len(items), len(shards), len(job.Tasks)),
+		TraceId:   traceID,
+	}, nil
+}
+
+// loadMapItems returns a map request's inline items, or reads them from
+// its path: a file gives one item per non-empty line, a directory one item
+// per matching file, named by its path
+func (s *OrchestratorServer) loadMapItems(ctx context.Context, spec *pb.MapSpec) ([]string, []string, error) {
+	if spec.Path == "" {
+		if len(spec.Items) == 0 {
+			return nil, nil, fmt.Errorf("%w: items or path is required", errMapInput)
+		}
+		if len(spec.Items) > mapMaxItems {
+			return nil, nil, fmt.Errorf("%w: %d items exceeds the limit of %d", errMapInput, len(spec.Items), mapMaxItems)
+		}
+		return spec.Items, nil, nil
+	}
+	if len(spec.Items) > 0 {
+		return nil, nil, fmt.Errorf("%w: set items or path, not both", errMapInput)
+	}
+
+	deviceID := spec.DeviceId
+	if deviceID == "" {
+		deviceID = s.selfDeviceID
+	}
+	client, sessionID, closeConn, err := s.dialDevice(ctx, deviceID, "coordinator-map")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeConn()
+
+	st, err := client.StatFile(ctx, &pb.StatFileRequest{SessionId: sessionID, Path: spec.Path})
+	if err != nil {
+		return nil, nil, err
+	}
+	if st.Error != "" {
+		return nil, nil, fmt.Errorf("%w: %s", errMapInput, st.Error)
+	}
+	if !st.Exists || st.Entry == nil {
+		return nil, nil, fmt.Errorf("%w: %s not found on %s", errMapInput, spec.Path, deviceID)
+	}
+
+	if st.Entry.Type != "dir" {
+		content, err := readMapFile(ctx, client, sessionID, spec.Path, mapMaxFileBytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		var items []string
+		for _, line := range strings.Split(content, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				items = append(items, line)
+			}
+		}
+		if len(items) == 0 {
+			return nil, nil, fmt.Errorf("%w: %s has no items", errMapInput, spec.Path)
+		}
+		if len(items) > mapMaxItems {
+			return nil, nil, fmt.Errorf("%w: %s has %d items, the limit is %d", errMapInput, spec.Path, len(items), mapMaxItems)
+		}
+		return items, nil, nil
+	}
+
+	var items, names []string
+	pageToken := ""
+	for {
+		page, err := client.ListDir(ctx, &pb.ListDirRequest{
+			SessionId: sessionID,
+			Path:      spec.Path,
+			Glob:      spec.Glob,
+			PageSize:  1000,
+			PageToken: pageToken,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		if page.Error != "" {
+			return nil, nil, fmt.Errorf("%w: %s", errMapInput, page.Error)
+		}
+		for _, e := range page.Entries {
+			if e.Type != "file" {
+				continue
+			}
+			if len(items) == mapMaxItems {
+				return nil, nil, fmt.Errorf("%w: %s has more than %d files", errMapInput, spec.Path, mapMaxItems)
+			}
+			content, err := readMapFile(ctx, client, sessionID, e.Path, mapMaxItemBytes)
+			if err != nil {
+				return nil, nil, err
+			}
+			items = append(items, content)
+			names = append(names, e.Path)
+		}
+		if page.NextPageToken == "" {
+			break
+		}
+		pageToken = page.NextPageToken
+	}
+	if len(items) == 0 {
+		return nil, nil, fmt.Errorf("%w: no files in %s match %q", errMapInput, spec.Path, spec.Glob)
+	}
+	return items, names, nil
+}
+
+// readMapFile reads up to maxBytes of path; a line-per-item file may not be
+// cut short, a directory item may
+func readMapFile(ctx context.Context, client pb.OrchestratorServiceClient, sessionID, path string, maxBytes int) (string, error) {
+	resp, err := client.ReadFile(ctx, &pb.ReadFileRequest{
+		SessionId: sessionID,
+		Path:      path,
+		Mode:      pb.ReadMode_READ_MODE_FULL,
+		MaxBytes:  int32(maxBytes),
+	})
+	if err != nil {
+		return "", err
+	}
+	if resp.Error != "" {
+		return "", fmt.Errorf("%w: read %s: %s", errMapInput, path, resp.Error)
+	}
+	if resp.Truncated {
+		if maxBytes == mapMaxFileBytes {
+			return "", fmt.Errorf("%w: %s is larger than %d bytes; split it or use a directory", errMapInput, path, maxBytes)
+		}
+		log.Printf("[WARN] loadMapItems: %s truncated to %d bytes", path, maxBytes)
+	}
+	return string(resp.Content), nil
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/edgecli/edgecli/internal/metrics"
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+// defaultMetricsRange is the history a query without from_ms covers
+const defaultMetricsRange = 24 * time.Hour
+
+// openMetricsArchive opens the long-term metrics history at
+// METRICS_HISTORY_FILE, or ~/.edgemesh/metrics-history.json.gz. If it
+// cannot be opened, history is kept in memory only.
+func openMetricsArchive() *metrics.Archive {
+	path := os.Getenv("METRICS_HISTORY_FILE")
+	if path == "" {
+		var err error
+		if path, err = metrics.DefaultArchivePath(); err != nil {
+			log.Printf("[WARN] Metrics history will not be saved: %v", err)
+			return metrics.NewArchive()
+		}
+	}
+	a, err := metrics.OpenArchive(path)
+	if err != nil {
+		log.Printf("[WARN] Metrics history will not be saved: %v", err)
+		return metrics.NewArchive()
+	}
+	return a
+}
+
+// metricsRange resolves a query's bounds and resolution
+func metricsRange(req *pb.MetricsQuery) (from, to time.Time, resolution time.Duration, err error) {
+	to = time.Now()
+	if req.ToMs > 0 {
+		to = time.UnixMilli(req.ToMs)
+	}
+	from = to.Add(-defaultMetricsRange)
+	if req.FromMs > 0 {
+		from = time.UnixMilli(req.FromMs)
+	}
+	if req.FromMs < 0 || req.ToMs < 0 || req.ResolutionMs < 0 {
+		return from, to, 0, fmt.Errorf("from_ms, to_ms and resolution_ms must not be negative")
+	}
+	if from.After(to) {
+		return from, to, 0, fmt.Errorf("from_ms is after to_ms")
+	}
+	return from, to, time.Duration(req.ResolutionMs) * time.Millisecond, nil
+}
+
+// queryMetricsHistory answers GetDeviceMetrics from the long-term history
+func (s *OrchestratorServer) queryMetricsHistory(req *pb.MetricsQuery) (*pb.MetricsHistoryResponse, error) {
+	from, to, resolution, err := metricsRange(req)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	resp := &pb.MetricsHistoryResponse{DeviceId: req.DeviceId}
+	r, ok := s.metricsStore.Archive().Query(req.DeviceId, from, to, resolution)
+	if !ok {
+		return resp, nil
+	}
+	resp.DeviceName = r.DeviceName
+	resp.ResolutionMs = r.Resolution.Milliseconds()
+	resp.Points = make([]*pb.MetricsPoint, len(r.Points))
+	for i, p := range r.Points {
+		resp.Points[i] = toPbMetricsPoint(p)
+	}
+	return resp, nil
+}
+
+// toPbMetricsPoint converts an archived point to its protobuf form
+func toPbMetricsPoint(p metrics.Point) *pb.MetricsPoint {
+	stats := func(f int) *pb.MetricStats {
+		a := p.Aggs[f]
+		if a.N == 0 {
+			return nil
+		}
+		return &pb.MetricStats{Min: a.Min, Max: a.Max, Avg: a.Avg(), Count: int64(a.N)}
+	}
+	return &pb.MetricsPoint{
+		StartMs:       p.StartMs,
+		CpuLoad:       stats(metrics.FieldCPULoad),
+		MemUsedMb:     stats(metrics.FieldMemUsedMB),
+		MemTotalMb:    stats(metrics.FieldMemTotalMB),
+		GpuLoad:       stats(metrics.FieldGPULoad),
+		GpuMemUsedMb:  stats(metrics.FieldGPUMemUsedMB),
+		GpuMemTotalMb: stats(metrics.FieldGPUMemTotalMB),
+		NpuLoad:       stats(metrics.FieldNPULoad),
+	}
+}
+
+// parseMetricsQuery reads device_id, from, to and resolution from web
+// query parameters. Times are RFC 3339 or Unix milliseconds; resolution
+// is a duration such as 1m or 1h.
+func parseMetricsQuery(q url.Values) (*pb.MetricsQuery, error) {
+	req := &pb.MetricsQuery{DeviceId: q.Get("device_id")}
+	for _, p := range []struct {
+		name string
+		ms   *int64
+	}{{"from", &req.FromMs}, {"to", &req.ToMs}} {
+		v := q.Get(p.name)
+		if v == "" {
+			continue
+		}
+		if ms, err := strconv.ParseInt(v, 10, 64); err == nil {
+			*p.ms = ms
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: want RFC 3339 or Unix milliseconds, got %q", p.name, v)
+		}
+		*p.ms = t.UnixMilli()
+	}
+	if v := q.Get("resolution"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("resolution: want a duration such as 1m or 1h, got %q", v)
+		}
+		req.ResolutionMs = d.Milliseconds()
+	}
+	return req, nil
+}
+
+// handleMetricsExport serves long-term metrics history as CSV or JSON:
+// GET /api/metrics/export?device_id=&from=&to=&resolution=&format=csv|json.
+// Without device_id every archived device is exported.
+func (h *WebHandler) handleMetricsExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	req, err := parseMetricsQuery(r.URL.Query())
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "json" {
+		h.writeError(w, http.StatusBadRequest, "format must be csv or json")
+		return
+	}
+
+	deviceIDs := []string{req.DeviceId}
+	if req.DeviceId == "" {
+		deviceIDs = h.orchestrator.metricsStore.Archive().DeviceIDs()
+	}
+	var results []*pb.MetricsHistoryResponse
+	for _, id := range deviceIDs {
+		resp, err := h.orchestrator.queryMetricsHistory(&pb.MetricsQuery{
+			DeviceId: id, FromMs: req.FromMs, ToMs: req.ToMs, ResolutionMs: req.ResolutionMs,
+		})
+		if err != nil {
+			h.writeError(w, http.StatusBadRequest, status.Convert(err).Message())
+			return
+		}
+		results = append(results, resp)
+	}
+
+	if format == "json" {
+		h.writeJSON(w, http.StatusOK, map[string]interface{}{"devices": results})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="metrics.csv"`)
+	cw := csv.NewWriter(w)
+	header := []string{"device_id", "device_name", "start", "start_ms", "resolution_ms"}
+	for _, f := range metrics.FieldNames {
+		header = append(header, f+"_min", f+"_max", f+"_avg", f+"_count")
+	}
+	cw.Write(header)
+	for _, resp := range results {
+		for _, p := range resp.Points {
+			row := []string{resp.DeviceId, resp.DeviceName,
+				time.UnixMilli(p.StartMs).UTC().Format(time.RFC3339),
+				strconv.FormatInt(p.StartMs, 10), strconv.FormatInt(resp.ResolutionMs, 10)}
+			for _, st := range []*pb.MetricStats{p.CpuLoad, p.MemUsedMb, p.MemTotalMb, p.GpuLoad, p.GpuMemUsedMb, p.GpuMemTotalMb, p.NpuLoad} {
+				if st == nil {
+					row = append(row, "", "", "", "0")
+					continue
+				}
+				row = append(row, formatFloat(st.Min), formatFloat(st.Max), formatFloat(st.Avg), strconv.FormatInt(st.Count, 10))
+			}
+			cw.Write(row)
+		}
+	}
+	cw.Flush()
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"github.com/edgecli/edgecli/internal/sysinfo"
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+// powerState converts a host sample's battery and thermal state
+func powerState(h *sysinfo.HostStatus) *pb.PowerState {
+	p := &pb.PowerState{
+		HasBattery:   h.BatteryPercent >= 0,
+		Charging:     h.Charging,
+		PluggedIn:    h.PluggedIn,
+		ThermalState: h.ThermalState,
+	}
+	if p.HasBattery {
+		p.BatteryPercent = h.BatteryPercent
+	}
+	if h.TemperatureC > 0 {
+		p.TemperatureC = h.TemperatureC
+	}
+	return p
+}
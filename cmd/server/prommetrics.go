@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/edgecli/edgecli/internal/jobs"
+	"github.com/edgecli/edgecli/internal/links"
+	"github.com/edgecli/edgecli/internal/llm"
+	"github.com/edgecli/edgecli/internal/prom"
+)
+
+// serverMetrics are the Prometheus metrics this node serves at /metrics.
+// Counters are updated as things happen; gauges are read from the job
+// manager, metrics store, stream manager and benchmark store at each scrape.
+type serverMetrics struct {
+	registry *prom.Registry
+
+	rpcs       *prom.Counter
+	rpcSeconds *prom.Histogram
+	bulkBytes  *prom.Counter
+	llmTokens  *prom.Counter
+	llmSeconds *prom.Counter
+	llmRate    *prom.Gauge
+
+	jobs       *prom.Gauge
+	tasks      *prom.Gauge
+	queueDepth *prom.Gauge
+	running    *prom.Gauge
+	devices    *prom.Gauge
+	cpuLoad    *prom.Gauge
+	gpuLoad    *prom.Gauge
+	npuLoad    *prom.Gauge
+	memUsed    *prom.Gauge
+	memTotal   *prom.Gauge
+	streams    *prom.Gauge
+	benchRate  *prom.Gauge
+}
+
+// newServerMetrics registers the node's metrics, reading state from s at scrape time
+func newServerMetrics(s *OrchestratorServer) *serverMetrics {
+	r := prom.NewRegistry()
+	m := &serverMetrics{
+		registry: r,
+
+		rpcs: r.Counter("edgemesh_grpc_requests_total",
+			"gRPC calls handled, by method and status code.", "method", "code"),
+		rpcSeconds: r.Histogram("edgemesh_grpc_request_duration_seconds",
+			"Time to handle a gRPC call, by method.", prom.DefBuckets, "method"),
+		bulkBytes: r.Counter("edgemesh_bulk_bytes_total",
+			"Bulk HTTP bytes: sent and received by this node's bulk server, fetched from peers when staging.", "direction", "endpoint"),
+		llmTokens: r.Counter("edgemesh_llm_tokens_total",
+			"Tokens processed by LLM_GENERATE tasks, by phase (prefill or decode).", "phase"),
+		llmSeconds: r.Counter("edgemesh_llm_seconds_total",
+			"Time spent in each LLM phase; tokens_total / seconds_total is the throughput.", "phase"),
+		llmRate: r.Gauge("edgemesh_llm_tokens_per_second",
+			"Throughput of the last LLM_GENERATE task, by phase.", "phase"),
+
+		jobs: r.Gauge("edgemesh_jobs",
+			"Jobs held by this node's job manager, by state.", "state"),
+		tasks: r.Gauge("edgemesh_tasks",
+			"Tasks of those jobs, by state.", "state"),
+		queueDepth: r.Gauge("edgemesh_task_queue_depth",
+			"Tasks waiting to run, by assigned device.", "device"),
+		running: r.Gauge("edgemesh_worker_tasks_running",
+			"RunTask calls in flight on this node."),
+		devices: r.Gauge("edgemesh_devices",
+			"Devices in this node's registry."),
+		cpuLoad: r.Gauge("edgemesh_device_cpu_load",
+			"Latest CPU load (0..1) of each polled device.", "device", "name"),
+		gpuLoad: r.Gauge("edgemesh_device_gpu_load",
+			"Latest GPU load (0..1) of each polled device that reports one.", "device", "name"),
+		npuLoad: r.Gauge("edgemesh_device_npu_load",
+			"Latest NPU load (0..1) of each polled device that reports one.", "device", "name"),
+		memUsed: r.Gauge("edgemesh_device_memory_used_bytes",
+			"Latest memory use of each polled device.", "device", "name"),
+		memTotal: r.Gauge("edgemesh_device_memory_total_bytes",
+			"Memory of each polled device.", "device", "name"),
+		streams: r.Gauge("edgemesh_webrtc_streams",
+			"Active WebRTC streams served by this node, by peer connection state.", "state"),
+		benchRate: r.Gauge("edgemesh_llm_benchmark_tokens_per_second",
+			"Benchmarked throughput of this node's chat model, by phase.", "phase"),
+	}
+	r.OnScrape(func() { m.collect(s) })
+	return m
+}
+
+// collect sets the gauges from s's current state
+func (m *serverMetrics) collect(s *OrchestratorServer) {
+	jobCounts, taskCounts, queued := s.jobManager.Counts()
+	for _, state := range []jobs.JobState{jobs.JobQueued, jobs.JobRunning, jobs.JobDone, jobs.JobFailed} {
+		m.jobs.Set(float64(jobCounts[state]), string(state))
+	}
+	for _, state := range []jobs.TaskState{jobs.TaskQueued, jobs.TaskRunning, jobs.TaskDone, jobs.TaskFailed} {
+		m.tasks.Set(float64(taskCounts[state]), string(state))
+	}
+	m.queueDepth.Reset()
+	for device, n := range queued {
+		m.queueDepth.Set(float64(n), device)
+	}
+	m.running.Set(float64(s.tasksRunning.Load()))
+
+	devices := s.registry.List()
+	m.devices.Set(float64(len(devices)))
+	for _, g := range []*prom.Gauge{m.cpuLoad, m.gpuLoad, m.npuLoad, m.memUsed, m.memTotal} {
+		g.Reset()
+	}
+	for _, d := range devices {
+		sample := s.metricsStore.GetLatest(d.DeviceId)
+		if sample == nil {
+			continue
+		}
+		// Negative loads mean the device cannot measure them
+		if sample.CPULoad >= 0 {
+			m.cpuLoad.Set(sample.CPULoad, d.DeviceId, d.DeviceName)
+		}
+		if sample.GPULoad >= 0 {
+			m.gpuLoad.Set(sample.GPULoad, d.DeviceId, d.DeviceName)
+		}
+		if sample.NPULoad >= 0 {
+			m.npuLoad.Set(sample.NPULoad, d.DeviceId, d.DeviceName)
+		}
+		m.memUsed.Set(float64(sample.MemUsedMB)*1024*1024, d.DeviceId, d.DeviceName)
+		m.memTotal.Set(float64(sample.MemTotalMB)*1024*1024, d.DeviceId, d.DeviceName)
+	}
+
+	m.streams.Reset()
+	for _, st := range s.webrtcManager.Streams() {
+		m.streams.Add(1, st.State)
+	}
+
+	m.benchRate.Reset()
+	if r, ok := s.selfBenchmark(); ok {
+		m.benchRate.Set(r.PrefillTPS, "prefill")
+		m.benchRate.Set(r.DecodeTPS, "decode")
+	}
+}
+
+// observeLLM records an LLM call's prefill and decode timings
+func (m *serverMetrics) observeLLM(t *llm.ChatTiming) {
+	if t == nil {
+		return
+	}
+	for _, p := range []struct {
+		phase  string
+		tokens int
+		d      time.Duration
+	}{
+		{"prefill", t.PromptTokens, t.PromptDuration},
+		{"decode", t.OutputTokens, t.OutputDuration},
+	} {
+		if p.tokens <= 0 || p.d <= 0 {
+			continue
+		}
+		m.llmTokens.Add(float64(p.tokens), p.phase)
+		m.llmSeconds.Add(p.d.Seconds(), p.phase)
+		m.llmRate.Set(float64(p.tokens)/p.d.Seconds(), p.phase)
+	}
+}
+
+// unaryInterceptor counts and times unary gRPC calls
+func (m *serverMetrics) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	m.observeRPC(info.FullMethod, start, err)
+	return resp, err
+}
+
+// streamInterceptor counts and times streaming gRPC calls
+func (m *serverMetrics) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	m.observeRPC(info.FullMethod, start, err)
+	return err
+}
+
+func (m *serverMetrics) observeRPC(method string, start time.Time, err error) {
+	m.rpcs.Inc(method, status.Code(err).String())
+	m.rpcSeconds.Observe(time.Since(start).Seconds(), method)
+}
+
+// countBulk wraps the bulk HTTP server to count the bytes it sends and receives
+func (m *serverMetrics) countBulk(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		endpoint := bulkEndpoint(r.URL.Path)
+		cw := &countingWriter{ResponseWriter: w}
+		var cr *countingReader
+		if r.Body != nil {
+			cr = &countingReader{ReadCloser: r.Body}
+			r.Body = cr
+		}
+		next.ServeHTTP(cw, r)
+		m.bulkBytes.Add(float64(cw.n), "sent", endpoint)
+		if cr != nil {
+			m.bulkBytes.Add(float64(cr.n), "received", endpoint)
+		}
+	})
+}
+
+// bulkEndpoint names a bulk HTTP path for the endpoint label
+func bulkEndpoint(path string) string {
+	switch {
+	case strings.HasPrefix(path, "/bulk/download/"):
+		return "download"
+	case strings.HasPrefix(path, "/bulk/upload/"):
+		return "upload"
+	case path == links.ProbePath:
+		return "probe"
+	default:
+		return "other"
+	}
+}
+
+// countingWriter counts response body bytes
+type countingWriter struct {
+	http.ResponseWriter
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+// Flush keeps live downloads streaming through the wrapper
+func (w *countingWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// countingReader counts request body bytes
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
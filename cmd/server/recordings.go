@@ -0,0 +1,325 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgecli/edgecli/internal/audit"
+	"github.com/edgecli/edgecli/internal/transfer"
+	"github.com/edgecli/edgecli/internal/webrtcstream"
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+// recordingsDir is where recorded streams go, under the shared root
+const recordingsDir = "recordings"
+
+// Values of StreamStartRequest.Record
+const (
+	recordOnDevice      = "device"
+	recordOnCoordinator = "coordinator"
+)
+
+// defaultAuditLimit is how many events /api/audit returns without ?limit
+const defaultAuditLimit = 100
+
+const (
+	// mirrorIdleTimeout ends a mirror whose device sends nothing this long
+	mirrorIdleTimeout = time.Minute
+	// mirrorStopGrace is how long a stopped stream's mirror may keep
+	// copying while the device finishes the file
+	mirrorStopGrace = 10 * time.Second
+)
+
+// mirrorClient fetches recordings from devices; downloads follow a file
+// while it is written, so only the wait for headers is bounded
+var mirrorClient = &http.Client{
+	Transport: &http.Transport{
+		ResponseHeaderTimeout: 10 * time.Second,
+		IdleConnTimeout:       90 * time.Second,
+	},
+}
+
+// recordingMirrors cancels the recording copies of remote streams
+type recordingMirrors struct {
+	mu     sync.Mutex
+	cancel map[string]context.CancelFunc // by stream ID
+}
+
+// start returns the context a stream's mirror downloads run under
+func (m *recordingMirrors) start(streamID string) context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.cancel == nil {
+		m.cancel = make(map[string]context.CancelFunc)
+	}
+	m.cancel[streamID] = cancel
+	return ctx
+}
+
+// stop ends a stream's mirror once the device has had mirrorStopGrace to
+// finish the file
+func (m *recordingMirrors) stop(streamID string) {
+	m.mu.Lock()
+	cancel, ok := m.cancel[streamID]
+	delete(m.cancel, streamID)
+	m.mu.Unlock()
+	if ok {
+		time.AfterFunc(mirrorStopGrace, cancel)
+	}
+}
+
+// stopAll ends every mirror at once, on shutdown
+func (m *recordingMirrors) stopAll() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, cancel := range m.cancel {
+		cancel()
+		delete(m.cancel, id)
+	}
+}
+
+// openAuditLog opens the audit trail at AUDIT_LOG, or ~/.edgemesh/audit.jsonl.
+// Without one, events are only logged.
+func openAuditLog() *audit.Log {
+	path := os.Getenv("AUDIT_LOG")
+	if path == "" {
+		var err error
+		if path, err = audit.DefaultPath(); err != nil {
+			log.Printf("[WARN] Audit trail disabled: %v", err)
+			return nil
+		}
+	}
+	l, err := audit.Open(path)
+	if err != nil {
+		log.Printf("[WARN] Audit trail disabled: %v", err)
+		return nil
+	}
+	return l
+}
+
+// relRecording turns a recording path into the shared-root relative path
+// download tickets take
+func (s *OrchestratorServer) relRecording(path string) string {
+	if path == "" {
+		return ""
+	}
+	rel, err := filepath.Rel(s.sharedRoot, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// streamEvent writes stream starts and stops to the audit trail and keeps
+// recordings marked live while they are written, so downloads follow them
+func (s *OrchestratorServer) streamEvent(ev webrtcstream.StreamEvent) {
+	st := ev.Stream
+	if st.Recording != "" {
+		live := ev.Type == webrtcstream.EventStart
+		s.ticketManager.SetLive(st.Recording, live)
+		s.ticketManager.SetLive(webrtcstream.ManifestPath(st.Recording), live)
+	}
+
+	s.mu.RLock()
+	var principal string
+	if session, ok := s.sessions[st.SessionID]; ok {
+		principal = sessionPrincipal(session)
+	}
+	s.mu.RUnlock()
+
+	detail := map[string]string{
+		"viewer":  st.Viewer,
+		"mode":    st.Mode,
+		"layer":   st.Layer,
+		"monitor": strconv.Itoa(st.MonitorIndex),
+	}
+	if st.Audio {
+		detail["audio"] = "true"
+	}
+	if st.Control != "" {
+		detail["control"] = st.Control
+	}
+	if st.Recording != "" {
+		detail["recording"] = s.relRecording(st.Recording)
+	}
+	if ev.Reason != "" {
+		detail["reason"] = ev.Reason
+	}
+	if err := s.auditLog.Record(audit.Event{
+		Action:    "stream." + ev.Type,
+		Principal: principal,
+		Device:    s.selfDeviceID,
+		Target:    st.ID,
+		Detail:    detail,
+	}); err != nil {
+		log.Printf("[WARN] Audit: stream %s %s: %v", st.ID, ev.Type, err)
+	}
+}
+
+// serveLiveFile serves a file that is still being written. A plain GET
+// follows it until it is finished; a ranged GET or HEAD gets what has been
+// written so far. There is no checksum yet, so no ETag either.
+func (s *OrchestratorServer) serveLiveFile(w http.ResponseWriter, r *http.Request, ticket *transfer.Ticket, f *os.File) {
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, ticket.Filename))
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Content-Live", "true")
+
+	if r.Method == http.MethodHead || r.Header.Get("Range") != "" {
+		info, err := f.Stat()
+		if err != nil {
+			log.Printf("[ERROR] handleBulkDownload: stat %s: %v", ticket.FilePath, err)
+			http.Error(w, "file not accessible", http.StatusInternalServerError)
+			return
+		}
+		http.ServeContent(w, r, ticket.Filename, info.ModTime(), f)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	flush := func() {
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	n, err := transfer.FollowFile(r.Context(), w, f, func() bool { return s.ticketManager.Live(ticket.FilePath) }, flush)
+	if err != nil {
+		log.Printf("[WARN] handleBulkDownload: following %s stopped after %d bytes: %v", ticket.Filename, n, err)
+		return
+	}
+	log.Printf("[INFO] handleBulkDownload: served %s as it was written (%d bytes)", ticket.Filename, n)
+}
+
+// recordingDevice is deviceID if the stream is recorded, for
+// StreamStartResponse.RecordingDeviceID
+func recordingDevice(recording, deviceID string) string {
+	if recording == "" {
+		return ""
+	}
+	return deviceID
+}
+
+// mirrorRecording copies a recording and its manifest from device into this
+// device's recordings/<device id>/ folder, following them while the device
+// still writes, until the stream is stopped. It returns the local recording
+// path relative to the shared root once both downloads have started.
+func (s *OrchestratorServer) mirrorRecording(ctx context.Context, device *pb.DeviceInfo, streamID, rel string) (string, error) {
+	if device.HttpAddr == "" {
+		return "", fmt.Errorf("device %s has no HTTP address configured", device.DeviceName)
+	}
+	conn, err := dialStreamDevice(ctx, device.GrpcAddr)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	client := pb.NewOrchestratorServiceClient(conn)
+
+	localRel := filepath.ToSlash(filepath.Join(recordingsDir, filepath.Base(device.DeviceId), filepath.Base(rel)))
+	local := filepath.Join(s.sharedRoot, filepath.FromSlash(localRel))
+	if err := os.MkdirAll(filepath.Dir(local), 0o755); err != nil {
+		return "", err
+	}
+	mirrorCtx := s.mirrors.start(streamID)
+	for _, remote := range []string{rel, webrtcstream.ManifestPath(rel)} {
+		ticket, err := client.CreateDownloadTicket(ctx, &pb.DownloadTicketRequest{Path: remote})
+		if err != nil {
+			return "", fmt.Errorf("download ticket for %s: %w", remote, err)
+		}
+		dest := filepath.Join(filepath.Dir(local), filepath.Base(remote))
+		out, err := os.OpenFile(dest, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err != nil {
+			return "", err
+		}
+		url := fmt.Sprintf("http://%s/bulk/download/%s", device.HttpAddr, ticket.Token)
+		s.ticketManager.SetLive(dest, true)
+		go s.followRecording(mirrorCtx, url, dest, out)
+	}
+	log.Printf("[INFO] Recording %s on %s is mirrored to %s", rel, device.DeviceName, local)
+	return localRel, nil
+}
+
+// followRecording downloads url into out until the device finishes the
+// file, ctx is cancelled, or nothing arrives for mirrorIdleTimeout
+func (s *OrchestratorServer) followRecording(ctx context.Context, url, dest string, out *os.File) {
+	defer s.ticketManager.SetLive(dest, false)
+	defer out.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		log.Printf("[WARN] Mirroring %s: %v", dest, err)
+		return
+	}
+	resp, err := mirrorClient.Do(req)
+	if err != nil {
+		log.Printf("[WARN] Mirroring %s: %v", dest, err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("[WARN] Mirroring %s: %s", dest, resp.Status)
+		return
+	}
+	idle := time.AfterFunc(mirrorIdleTimeout, cancel)
+	defer idle.Stop()
+	n, err := io.Copy(out, &idleReader{r: resp.Body, timer: idle, timeout: mirrorIdleTimeout})
+	if err != nil {
+		log.Printf("[WARN] Mirroring %s stopped after %d bytes: %v", dest, n, err)
+		return
+	}
+	log.Printf("[INFO] Mirrored %s (%d bytes)", dest, n)
+}
+
+// handleAudit returns this device's newest audit events, oldest first
+func (h *WebHandler) handleAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	limit := defaultAuditLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 {
+			h.writeError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = parsed
+	}
+	events, err := h.orchestrator.auditLog.Recent(limit)
+	if err != nil {
+		log.Printf("[ERROR] handleAudit: %v", err)
+		h.writeError(w, http.StatusInternalServerError, "Audit trail error: "+err.Error())
+		return
+	}
+	if events == nil {
+		events = []audit.Event{}
+	}
+	h.writeJSON(w, http.StatusOK, events)
+}
+
+// idleReader restarts timer on every read that returns data
+type idleReader struct {
+	r       io.Reader
+	timer   *time.Timer
+	timeout time.Duration
+}
+
+func (r *idleReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.timer.Reset(r.timeout)
+	}
+	return n, err
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/edgecli/edgecli/internal/scheduler"
+	"github.com/edgecli/edgecli/internal/trace"
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+// openScheduler loads the schedules at SCHEDULES_FILE, or
+// ~/.edgemesh/schedules.json. If they cannot be loaded, schedules are kept
+// in memory only.
+func openScheduler() *scheduler.Scheduler {
+	path := os.Getenv("SCHEDULES_FILE")
+	if path == "" {
+		var err error
+		if path, err = scheduler.DefaultPath(); err != nil {
+			log.Printf("[WARN] Schedules will not be saved: %v", err)
+			return scheduler.New()
+		}
+	}
+	s, err := scheduler.Open(path)
+	if err != nil {
+		log.Printf("[WARN] Schedules will not be saved: %v", err)
+		return scheduler.New()
+	}
+	return s
+}
+
+// runScheduler submits scheduled jobs until ctx is done
+func (s *OrchestratorServer) runScheduler(ctx context.Context) {
+	sessionID := s.CreateInternalSession("scheduler")
+	log.Printf("[INFO] Scheduler: %d schedule(s)", len(s.scheduler.List()))
+	s.scheduler.Run(ctx, func(ctx context.Context, sc *scheduler.Schedule) (string, error) {
+		return s.runSchedule(ctx, sc, sessionID)
+	}, s.jobManager.IsActive)
+}
+
+// runSchedule submits one run's job and links it to its schedule
+func (s *OrchestratorServer) runSchedule(ctx context.Context, sc *scheduler.Schedule, sessionID string) (string, error) {
+	ctx, span := trace.Start(ctx, "schedule.run",
+		trace.String("schedule.id", sc.ID), trace.String("schedule.name", sc.Name))
+	defer span.End()
+
+	req := proto.Clone(sc.Job).(*pb.JobRequest)
+	req.SessionId = sessionID
+	info, err := s.SubmitJob(ctx, req)
+	if err != nil {
+		span.RecordError(err)
+		return "", err
+	}
+	s.jobManager.SetScheduleID(info.JobId, sc.ID)
+	span.SetAttributes(trace.String("job.id", info.JobId))
+	return info.JobId, nil
+}
+
+// CreateSchedule stores a schedule that submits a job every time its cron
+// expression matches
+func (s *OrchestratorServer) CreateSchedule(ctx context.Context, req *pb.CreateScheduleRequest) (*pb.ScheduleInfo, error) {
+	if err := s.checkSession("CreateSchedule", req.SessionId); err != nil {
+		return nil, err
+	}
+	sc, err := s.scheduler.Create(scheduler.Schedule{
+		Name:         req.Name,
+		Cron:         req.Cron,
+		Timezone:     req.Timezone,
+		Job:          req.Job,
+		Missed:       scheduler.MissedPolicy(req.MissedPolicy),
+		AllowOverlap: req.AllowOverlap,
+	}, time.Now())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	log.Printf("[INFO] CreateSchedule: id=%s name=%q cron=%q next=%s",
+		sc.ID, sc.Name, sc.Cron, sc.NextRunAt.Format(time.RFC3339))
+	return toPbSchedule(sc), nil
+}
+
+// ListSchedules returns every schedule with its recent runs
+func (s *OrchestratorServer) ListSchedules(ctx context.Context, req *pb.ListSchedulesRequest) (*pb.ListSchedulesResponse, error) {
+	if err := s.checkSession("ListSchedules", req.SessionId); err != nil {
+		return nil, err
+	}
+	list := s.scheduler.List()
+	resp := &pb.ListSchedulesResponse{Schedules: make([]*pb.ScheduleInfo, 0, len(list))}
+	for _, sc := range list {
+		resp.Schedules = append(resp.Schedules, toPbSchedule(sc))
+	}
+	return resp, nil
+}
+
+// DeleteSchedule removes a schedule; jobs it already started keep running
+func (s *OrchestratorServer) DeleteSchedule(ctx context.Context, req *pb.DeleteScheduleRequest) (*pb.Empty, error) {
+	if err := s.checkSession("DeleteSchedule", req.SessionId); err != nil {
+		return nil, err
+	}
+	if err := s.scheduler.Delete(req.ScheduleId); err != nil {
+		if errors.Is(err, scheduler.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	log.Printf("[INFO] DeleteSchedule: id=%s", req.ScheduleId)
+	return &pb.Empty{}, nil
+}
+
+func toPbSchedule(sc *scheduler.Schedule) *pb.ScheduleInfo {
+	info := &pb.ScheduleInfo{
+		ScheduleId:   sc.ID,
+		Name:         sc.Name,
+		Cron:         sc.Cron,
+		Timezone:     sc.Timezone,
+		Job:          sc.Job,
+		MissedPolicy: string(sc.Missed),
+		AllowOverlap: sc.AllowOverlap,
+		CreatedAtMs:  sc.CreatedAt.UnixMilli(),
+		NextRunMs:    sc.NextRunAt.UnixMilli(),
+		LastJobId:    sc.LastJobID,
+	}
+	if !sc.LastRunAt.IsZero() {
+		info.LastRunMs = sc.LastRunAt.UnixMilli()
+	}
+	for _, r := range sc.Runs {
+		info.Runs = append(info.Runs, &pb.ScheduleRun{
+			DueMs:  r.Due.UnixMilli(),
+			Status: r.Status,
+			JobId:  r.JobID,
+			Detail: r.Detail,
+		})
+	}
+	return info
+}
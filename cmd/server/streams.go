@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+// streamsDeviceTimeout bounds how long /api/streams waits for one device
+const streamsDeviceTimeout = 3 * time.Second
+
+// StreamSessionResponse is one active stream in /api/streams
+type StreamSessionResponse struct {
+	StreamID     string `json:"stream_id"`
+	Viewer       string `json:"viewer"`
+	Mode         string `json:"mode"`
+	Layer        string `json:"layer"`
+	MonitorIndex int32  `json:"monitor_index"`
+	TargetFPS    int32  `json:"target_fps"`
+	BitrateKbps  int32  `json:"bitrate_kbps,omitempty"`
+	State        string `json:"state"`
+	StartedAtMs  int64  `json:"started_at_ms"`
+	IdleMs       int64  `json:"idle_ms"`
+	Audio        bool   `json:"audio"`
+	Control      string `json:"control,omitempty"`
+	Recording    string `json:"recording,omitempty"` // path under the device's shared root
+	Principal    string `json:"principal,omitempty"` // who holds control
+}
+
+// CaptureFeedResponse is a monitor capture shared by a device's streams
+type CaptureFeedResponse struct {
+	MonitorIndex int32  `json:"monitor_index"`
+	Subscribers  int32  `json:"subscribers"`
+	Captures     uint64 `json:"captures"`
+	FramesServed uint64 `json:"frames_served"`
+}
+
+// DeviceStreamsResponse is one device's streams in /api/streams
+type DeviceStreamsResponse struct {
+	DeviceID      string                  `json:"device_id"`
+	DeviceName    string                  `json:"device_name"`
+	GRPCAddr      string                  `json:"grpc_addr"`
+	MaxViewers    int32                   `json:"max_viewers"` // 0 means no limit
+	IdleTimeoutMs int64                   `json:"idle_timeout_ms"`
+	Streams       []StreamSessionResponse `json:"streams"`
+	Feeds         []CaptureFeedResponse   `json:"feeds"`
+	Error         string                  `json:"error,omitempty"`
+}
+
+// ListStreams reports who is watching this device and how the monitor
+// captures are shared
+func (s *OrchestratorServer) ListStreams(ctx context.Context, req *pb.ListStreamsRequest) (*pb.ListStreamsResponse, error) {
+	if err := s.checkSession("ListStreams", req.SessionId); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	resp := &pb.ListStreamsResponse{
+		MaxViewers:    int32(s.webrtcManager.MaxViewers()),
+		IdleTimeoutMs: s.streamIdle.Milliseconds(),
+	}
+	for _, st := range s.webrtcManager.Streams() {
+		var idle int64
+		if !st.IdleSince.IsZero() {
+			idle = now.Sub(st.IdleSince).Milliseconds()
+		}
+		resp.Streams = append(resp.Streams, &pb.StreamSession{
+			StreamId:     st.ID,
+			Viewer:       st.Viewer,
+			Mode:         st.Mode,
+			Layer:        st.Layer,
+			MonitorIndex: int32(st.MonitorIndex),
+			TargetFps:    int32(st.TargetFPS),
+			BitrateKbps:  int32(st.BitrateKbps),
+			State:        st.State,
+			StartedAtMs:  st.Started.UnixMilli(),
+			IdleMs:       idle,
+			Audio:        st.Audio,
+			Control:      st.Control,
+			Recording:    s.relRecording(st.Recording),
+			Principal:    st.Principal,
+		})
+	}
+	for _, f := range s.webrtcManager.Feeds() {
+		resp.Feeds = append(resp.Feeds, &pb.CaptureFeed{
+			MonitorIndex: int32(f.MonitorIndex),
+			Subscribers:  int32(f.Subscribers),
+			Captures:     f.Captures,
+			FramesServed: f.FramesServed,
+		})
+	}
+	return resp, nil
+}
+
+// clientHost is the address a web request came from, naming viewers that
+// did not name themselves
+func clientHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// handleStreams lists the streams on every registered device
+func (h *WebHandler) handleStreams(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), webRequestTimeout)
+	defer cancel()
+
+	devicesResp, err := h.orchestrator.ListDevices(ctx, &pb.ListDevicesRequest{})
+	if err != nil {
+		log.Printf("[ERROR] handleStreams: ListDevices failed: %v", err)
+		h.writeError(w, http.StatusInternalServerError, "ListDevices error: "+err.Error())
+		return
+	}
+
+	out := make([]DeviceStreamsResponse, len(devicesResp.Devices))
+	var wg sync.WaitGroup
+	for i, d := range devicesResp.Devices {
+		wg.Add(1)
+		go func(i int, d *pb.DeviceInfo) {
+			defer wg.Done()
+			out[i] = h.deviceStreams(ctx, d)
+		}(i, d)
+	}
+	wg.Wait()
+	h.writeJSON(w, http.StatusOK, out)
+}
+
+// deviceStreams asks one device for its streams; a device that cannot be
+// reached is listed with the error
+func (h *WebHandler) deviceStreams(ctx context.Context, d *pb.DeviceInfo) DeviceStreamsResponse {
+	ds := DeviceStreamsResponse{
+		DeviceID:   d.DeviceId,
+		DeviceName: d.DeviceName,
+		GRPCAddr:   d.GrpcAddr,
+		Streams:    []StreamSessionResponse{},
+		Feeds:      []CaptureFeedResponse{},
+	}
+	ctx, cancel := context.WithTimeout(ctx, streamsDeviceTimeout)
+	defer cancel()
+
+	var (
+		resp *pb.ListStreamsResponse
+		err  error
+	)
+	if d.DeviceId == h.orchestrator.selfDeviceID {
+		sessionID := h.orchestrator.CreateInternalSession("web-streams")
+		resp, err = h.orchestrator.ListStreams(ctx, &pb.ListStreamsRequest{SessionId: sessionID})
+	} else if conn, dialErr := dialStreamDevice(ctx, d.GrpcAddr); dialErr != nil {
+		err = dialErr
+	} else {
+		defer conn.Close()
+		client := pb.NewOrchestratorServiceClient(conn)
+		var session *pb.SessionInfo
+		session, err = client.CreateSession(ctx, &pb.AuthRequest{
+			DeviceName:  "web-streams",
+			SecurityKey: "internal-routing",
+		})
+		if err == nil {
+			resp, err = client.ListStreams(ctx, &pb.ListStreamsRequest{SessionId: session.SessionId})
+		}
+	}
+	if err != nil {
+		log.Printf("[WARN] handleStreams: %s: %v", d.DeviceName, err)
+		ds.Error = err.Error()
+		return ds
+	}
+
+	ds.MaxViewers, ds.IdleTimeoutMs = resp.MaxViewers, resp.IdleTimeoutMs
+	for _, st := range resp.Streams {
+		ds.Streams = append(ds.Streams, StreamSessionResponse{
+			StreamID:     st.StreamId,
+			Viewer:       st.Viewer,
+			Mode:         st.Mode,
+			Layer:        st.Layer,
+			MonitorIndex: st.MonitorIndex,
+			TargetFPS:    st.TargetFps,
+			BitrateKbps:  st.BitrateKbps,
+			State:        st.State,
+			StartedAtMs:  st.StartedAtMs,
+			IdleMs:       st.IdleMs,
+			Audio:        st.Audio,
+			Control:      st.Control,
+			Recording:    st.Recording,
+			Principal:    st.Principal,
+		})
+	}
+	for _, f := range resp.Feeds {
+		ds.Feeds = append(ds.Feeds, CaptureFeedResponse{
+			MonitorIndex: f.MonitorIndex,
+			Subscribers:  f.Subscribers,
+			Captures:     f.Captures,
+			FramesServed: f.FramesServed,
+		})
+	}
+	return ds
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edgecli/edgecli/internal/filesync"
+	pb "github.com/edgecli/edgecli/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// syncPeer pulls manifests and files from a registered device over gRPC and
+// the bulk HTTP plane. It dials once and reuses the connection for the
+// whole round; the sync service closes it when the round ends.
+type syncPeer struct {
+	s    *OrchestratorServer
+	info *pb.DeviceInfo
+
+	mu        sync.Mutex
+	client    pb.OrchestratorServiceClient
+	sessionID string
+	closeConn func()
+}
+
+func (p *syncPeer) ID() string   { return p.info.DeviceId }
+func (p *syncPeer) Name() string { return p.info.DeviceName }
+
+// connect returns the peer's client, dialing on first use
+func (p *syncPeer) connect(ctx context.Context) (pb.OrchestratorServiceClient, string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.client == nil {
+		client, sessionID, closeConn, err := p.s.dialDevice(ctx, p.info.DeviceId, "coordinator-sync")
+		if err != nil {
+			return nil, "", err
+		}
+		p.client, p.sessionID, p.closeConn = client, sessionID, closeConn
+	}
+	return p.client, p.sessionID, nil
+}
+
+func (p *syncPeer) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closeConn != nil {
+		p.closeConn()
+	}
+	p.client, p.sessionID, p.closeConn = nil, "", nil
+	return nil
+}
+
+func (p *syncPeer) Manifest(ctx context.Context) (filesync.Manifest, error) {
+	client, sessionID, err := p.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.GetSyncManifest(ctx, &pb.SyncManifestRequest{SessionId: sessionID})
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(filesync.Manifest, len(resp.Files))
+	for _, f := range resp.Files {
+		if _, dup := m[f.Path]; dup {
+			return nil, fmt.Errorf("manifest lists %q twice", f.Path)
+		}
+		m[f.Path] = filesync.FileMeta{
+			Path:      f.Path,
+			SizeBytes: f.SizeBytes,
+			ModTimeMs: f.ModTimeMs,
+			SHA256:    f.Sha256,
+		}
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (p *syncPeer) Fetch(ctx context.Context, rel string, w io.Writer) error {
+	client, _, err := p.connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	ticket, err := client.CreateDownloadTicket(ctx, &pb.DownloadTicketRequest{Path: rel})
+	if err != nil {
+		return fmt.Errorf("download ticket: %w", err)
+	}
+
+	url := fmt.Sprintf("http://%s/bulk/download/%s", p.info.HttpAddr, ticket.Token)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("bulk download: %s", resp.Status)
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// startSync enables shared folder sync when SYNC_ENABLED is set.
+// SYNC_PEERS lists paired device IDs or names ("*" pairs with every device),
+// and SYNC_INTERVAL_SECONDS sets the round interval.
+func (s *OrchestratorServer) startSync(ctx context.Context) {
+	if v := strings.ToLower(os.Getenv("SYNC_ENABLED")); v != "true" && v != "1" {
+		return
+	}
+
+	for _, p := range strings.Split(os.Getenv("SYNC_PEERS"), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			s.syncPeers = append(s.syncPeers, p)
+		}
+	}
+	if len(s.syncPeers) == 0 {
+		log.Printf("[WARN] Sync enabled but SYNC_PEERS is empty; nothing will be synced")
+	}
+
+	interval := filesync.DefaultInterval
+	if v := os.Getenv("SYNC_INTERVAL_SECONDS"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil && parsed > 0 {
+			interval = time.Duration(parsed) * time.Second
+		}
+	}
+
+	statePath, err := filesync.DefaultStatePath()
+	if err != nil {
+		log.Printf("[WARN] Sync state will not be persisted: %v", err)
+	}
+	hostname, _ := os.Hostname()
+
+	svc, err := filesync.NewService(filesync.Config{
+		Root:      s.sharedRoot,
+		StatePath: statePath,
+		SelfName:  hostname,
+		Interval:  interval,
+		Checksum:  s.ticketManager.Checksum,
+		Quota:     s.ticketManager.CheckQuota,
+	})
+	if err != nil {
+		log.Printf("[ERROR] Sync disabled: %v", err)
+		return
+	}
+	s.syncService = svc
+	s.syncInterval = interval
+
+	log.Printf("[INFO] Shared folder sync enabled: root=%s peers=%v interval=%v", s.sharedRoot, s.syncPeers, interval)
+	go svc.Run(ctx, s.pairedSyncPeers)
+}
+
+// pairedSyncPeers returns the registered devices named in SYNC_PEERS.
+func (s *OrchestratorServer) pairedSyncPeers() []filesync.Peer {
+	var peers []filesync.Peer
+	for _, d := range s.registry.List() {
+		if d.DeviceId == s.selfDeviceID || d.HttpAddr == "" {
+			continue
+		}
+		for _, want := range s.syncPeers {
+			if want == "*" || want == d.DeviceId || strings.EqualFold(want, d.DeviceName) {
+				peers = append(peers, &syncPeer{s: s, info: d})
+				break
+			}
+		}
+	}
+	return peers
+}
+
+// GetSyncManifest returns the content-hash manifest of the shared root.
+// Only devices that opted into sync serve their manifest.
+func (s *OrchestratorServer) GetSyncManifest(ctx context.Context, req *pb.SyncManifestRequest) (*pb.SyncManifestResponse, error) {
+	if err := s.checkSession("GetSyncManifest", req.SessionId); err != nil {
+		return nil, err
+	}
+
+	if s.syncService == nil {
+		return nil, status.Error(codes.FailedPrecondition, "sync is not enabled on this device")
+	}
+
+	m, err := s.syncService.LocalManifest()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "build manifest: %v", err)
+	}
+
+	files := make([]*pb.SyncFile, 0, len(m))
+	for _, p := range m.Paths() {
+		f := m[p]
+		files = append(files, &pb.SyncFile{
+			Path:      f.Path,
+			SizeBytes: f.SizeBytes,
+			ModTimeMs: f.ModTimeMs,
+			Sha256:    f.SHA256,
+		})
+	}
+
+	hostname, _ := os.Hostname()
+	return &pb.SyncManifestResponse{
+		DeviceId:   s.selfDeviceID,
+		DeviceName: hostname,
+		Files:      files,
+	}, nil
+}
+
+// SyncStatus reports sync progress for the local or a remote device
+func (s *OrchestratorServer) SyncStatus(ctx context.Context, req *pb.SyncStatusRequest) (*pb.SyncStatusResponse, error) {
+	if err := s.checkSession("SyncStatus", req.SessionId); err != nil {
+		return nil, err
+	}
+
+	// If device_id specified and not self, forward to remote device
+	if req.DeviceId != "" && req.DeviceId != s.selfDeviceID {
+		client, sessionID, closeConn, err := s.dialDevice(ctx, req.DeviceId, "coordinator-syncstatus")
+		if err != nil {
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
+		defer closeConn()
+
+		return client.SyncStatus(ctx, &pb.SyncStatusRequest{
+			SessionId: sessionID,
+			SyncNow:   req.SyncNow,
+		})
+	}
+
+	if s.syncService == nil {
+		return &pb.SyncStatusResponse{Enabled: false, DeviceId: s.selfDeviceID}, nil
+	}
+
+	if req.SyncNow {
+		go func() {
+			for _, p := range s.pairedSyncPeers() {
+				if _, err := s.syncService.SyncPeer(context.Background(), p); err != nil {
+					log.Printf("[WARN] SyncStatus: sync with %s failed: %v", p.Name(), err)
+				}
+			}
+		}()
+	}
+
+	files, bytes, peers := s.syncService.Status()
+	resp := &pb.SyncStatusResponse{
+		Enabled:    true,
+		DeviceId:   s.selfDeviceID,
+		LocalFiles: int32(files),
+		LocalBytes: bytes,
+		IntervalMs: s.syncInterval.Milliseconds(),
+		Paired:     s.syncPeers,
+	}
+	for _, p := range peers {
+		resp.Peers = append(resp.Peers, &pb.SyncPeerStatus{
+			PeerId:         p.PeerID,
+			PeerName:       p.PeerName,
+			LastSyncUnixMs: p.LastSyncAt.UnixMilli(),
+			LastError:      p.LastError,
+			Rounds:         int32(p.Rounds),
+			FilesPulled:    int32(p.FilesPulled),
+			FilesDeleted:   int32(p.FilesDeleted),
+			Conflicts:      int32(p.Conflicts),
+			Pending:        int32(p.Pending),
+			BytesPulled:    p.BytesPulled,
+		})
+	}
+	return resp, nil
+}
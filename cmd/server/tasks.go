@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/edgecli/edgecli/internal/allowlist"
+	"github.com/edgecli/edgecli/internal/exec"
+	"github.com/edgecli/edgecli/internal/tasks"
+	"github.com/edgecli/edgecli/internal/tools"
+)
+
+// taskEnv gives task handlers this worker's system info, chat and embed
+// providers, image generator and command runner
+type taskEnv struct {
+	s *OrchestratorServer
+}
+
+func (e taskEnv) SysInfo() string { return e.s.collectSysInfo() }
+
+func (e taskEnv) Chat(ctx context.Context, prompt string) (string, error) {
+	return e.s.runLLMGenerate(ctx, prompt)
+}
+
+func (e taskEnv) GenerateImage(ctx context.Context, prompt string) (string, error) {
+	return e.s.runImageGenerate(ctx, prompt)
+}
+
+func (e taskEnv) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return e.s.runEmbed(ctx, texts)
+}
+
+// RunCommand runs name with args if the shell safety check and the command
+// allowlist both permit it
+func (e taskEnv) RunCommand(ctx context.Context, name string, args []string) (*exec.Result, error) {
+	line := strings.TrimSpace(name + " " + strings.Join(args, " "))
+	if err := tools.ValidateShellCommand(line); err != nil {
+		log.Printf("[WARN] RunCommand: rejected %q: %v", line, err)
+		return nil, err
+	}
+	spec, err := allowlist.ValidateCommand(name, args)
+	if err != nil {
+		log.Printf("[WARN] RunCommand: rejected %q: %v", line, err)
+		return nil, err
+	}
+	log.Printf("[INFO] RunCommand: cmd=%s args=%v", spec.Executable, spec.Args)
+	return e.s.runner.Run(ctx, spec.Executable, spec.Args...), nil
+}
+
+// TaskKindResponse is one registered task kind in /api/task-kinds
+type TaskKindResponse struct {
+	tasks.Schema
+	Requirements tasks.Requirements `json:"requirements"`
+}
+
+// handleTaskKinds lists the task kinds this server can plan and run
+func (h *WebHandler) handleTaskKinds(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	handlers := tasks.Default.Handlers()
+	kinds := make([]TaskKindResponse, len(handlers))
+	for i, th := range handlers {
+		kinds[i] = TaskKindResponse{Schema: th.Schema(), Requirements: th.Requirements()}
+	}
+	h.writeJSON(w, http.StatusOK, kinds)
+}
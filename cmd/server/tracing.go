@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/edgecli/edgecli/internal/trace"
+)
+
+// quietRPCs are polled often enough, or last long enough, that tracing them
+// on their own would bury real work; they are traced only as part of a
+// caller's trace
+var quietRPCs = map[string]bool{
+	"edgemesh.OrchestratorService/Heartbeat":        true,
+	"edgemesh.OrchestratorService/ListDevices":      true,
+	"edgemesh.OrchestratorService/GetDeviceStatus":  true,
+	"edgemesh.OrchestratorService/HealthCheck":      true,
+	"edgemesh.OrchestratorService/Ping":             true,
+	"edgemesh.OrchestratorService/GetJob":           true,
+	"edgemesh.OrchestratorService/GetJobDetail":     true,
+	"edgemesh.OrchestratorService/GetActivity":      true,
+	"edgemesh.OrchestratorService/GetDeviceMetrics": true,
+	"edgemesh.OrchestratorService/GetIceCandidates": true,
+	"edgemesh.OrchestratorService/ListStreams":      true,
+	"edgemesh.OrchestratorService/SyncStatus":       true,
+	"edgemesh.OrchestratorService/WatchAlerts":      true,
+}
+
+// traceRPC starts traces for every RPC but the quiet ones
+func traceRPC(method string) bool { return !quietRPCs[method] }
+
+// traceWeb starts traces for web API requests that do something; the UI
+// polls the GET endpoints
+func traceWeb(name string) bool {
+	method, path, _ := strings.Cut(name, " ")
+	return method != http.MethodGet && method != http.MethodHead && strings.HasPrefix(path, "/api/")
+}
+
+// traceNoRoot only continues traces started elsewhere
+func traceNoRoot(string) bool { return false }
+
+// setupTracing exports spans as configured by the environment and traces
+// outbound HTTP made with the default transport. The returned function
+// flushes queued spans.
+func setupTracing(deviceID, deviceName string) func(context.Context) {
+	exporters, err := trace.ExportersFromEnv(
+		trace.String("service.name", "edgemesh"),
+		trace.String("service.instance.id", deviceID),
+		trace.String("host.name", deviceName),
+	)
+	if err != nil {
+		log.Printf("[WARN] Tracing disabled: %v", err)
+		return func(context.Context) {}
+	}
+	http.DefaultTransport = &trace.Transport{Base: http.DefaultTransport}
+	if len(exporters) == 0 {
+		log.Printf("[INFO] Tracing: trace IDs are propagated but spans are not exported (set TRACE_FILE or OTEL_EXPORTER_OTLP_ENDPOINT)")
+	} else {
+		log.Printf("[INFO] Tracing: exporting spans to %d exporter(s)", len(exporters))
+	}
+	return trace.Configure(exporters...)
+}
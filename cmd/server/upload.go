@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/edgecli/edgecli/internal/transfer"
+	pb "github.com/edgecli/edgecli/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// putFileMaxBytes caps inline PutFile payloads; larger files use upload tickets.
+const putFileMaxBytes = 10 * 1024 * 1024
+
+// uploadStatusResponse is the JSON body returned by /bulk/upload/<token>.
+type uploadStatusResponse struct {
+	ReceivedBytes int64  `json:"received_bytes"`
+	SizeBytes     int64  `json:"size_bytes"`
+	Complete      bool   `json:"complete"`
+	SHA256        string `json:"sha256,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// uploadErrorCode maps transfer errors to gRPC status codes.
+func uploadErrorCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, transfer.ErrInvalidPath):
+		return codes.InvalidArgument
+	case errors.Is(err, transfer.ErrFileTooLarge), errors.Is(err, transfer.ErrQuotaExceeded):
+		return codes.ResourceExhausted
+	case errors.Is(err, transfer.ErrFileExists):
+		return codes.AlreadyExists
+	case errors.Is(err, transfer.ErrChecksumMismatch):
+		return codes.DataLoss
+	default:
+		return codes.Internal
+	}
+}
+
+// relToShared returns fullPath relative to the shared root for responses.
+func (s *OrchestratorServer) relToShared(fullPath string) string {
+	rel, err := filepath.Rel(s.sharedRoot, fullPath)
+	if err != nil {
+		return fullPath
+	}
+	return filepath.ToSlash(rel)
+}
+
+// CreateUploadTicket validates a destination under the shared root and issues
+// a resumable upload token for the bulk HTTP plane.
+func (s *OrchestratorServer) CreateUploadTicket(ctx context.Context, req *pb.UploadTicketRequest) (*pb.UploadTicketResponse, error) {
+	if err := s.checkSession("CreateUploadTicket", req.SessionId); err != nil {
+		return nil, err
+	}
+	if req.SizeBytes < 0 {
+		return nil, status.Error(codes.InvalidArgument, "size_bytes must not be negative")
+	}
+
+	fullPath, err := transfer.ResolveUnderRoot(s.sharedRoot, req.Path)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ticket, err := s.ticketManager.CreateUpload(fullPath, req.SizeBytes, req.Sha256, req.Overwrite)
+	if err != nil {
+		log.Printf("[ERROR] CreateUploadTicket: path=%s size=%d: %v", fullPath, req.SizeBytes, err)
+		return nil, status.Error(uploadErrorCode(err), err.Error())
+	}
+
+	log.Printf("[INFO] CreateUploadTicket: path=%s size=%d token=%s...%s expires=%v",
+		fullPath, req.SizeBytes, ticket.Token[:4], ticket.Token[len(ticket.Token)-4:],
+		ticket.ExpiresAt.Format(time.RFC3339))
+
+	return &pb.UploadTicketResponse{
+		Token:          ticket.Token,
+		Path:           s.relToShared(fullPath),
+		ChunkSizeBytes: transfer.DefaultChunkSize,
+		ExpiresUnixMs:  ticket.ExpiresAt.UnixMilli(),
+		MaxUploadBytes: s.ticketManager.Limits().MaxFileBytes,
+	}, nil
+}
+
+// PutFile writes a small file into the shared root of the local or a remote device
+func (s *OrchestratorServer) PutFile(ctx context.Context, req *pb.PutFileRequest) (*pb.PutFileResponse, error) {
+	if err := s.checkSession("PutFile", req.SessionId); err != nil {
+		return nil, err
+	}
+
+	// If device_id specified and not self, forward to remote device
+	if req.DeviceId != "" && req.DeviceId != s.selfDeviceID {
+		return s.forwardPutFile(ctx, req)
+	}
+
+	return s.putLocalFile(req), nil
+}
+
+// putLocalFile writes the request content atomically under the shared root
+func (s *OrchestratorServer) putLocalFile(req *pb.PutFileRequest) *pb.PutFileResponse {
+	if len(req.Content) > putFileMaxBytes {
+		return &pb.PutFileResponse{Error: fmt.Sprintf("content exceeds %d bytes; use an upload ticket", putFileMaxBytes)}
+	}
+
+	fullPath, err := transfer.ResolveUnderRoot(s.sharedRoot, req.Path)
+	if err != nil {
+		return &pb.PutFileResponse{Error: err.Error()}
+	}
+
+	var replacing int64
+	if info, statErr := os.Stat(fullPath); statErr == nil {
+		replacing = info.Size()
+	}
+	if err := s.ticketManager.CheckQuota(int64(len(req.Content)), replacing); err != nil {
+		return &pb.PutFileResponse{Error: err.Error()}
+	}
+
+	sum, err := transfer.WriteFileAtomic(fullPath, req.Content, req.Sha256, req.Overwrite)
+	if err != nil {
+		log.Printf("[ERROR] PutFile: path=%s: %v", fullPath, err)
+		return &pb.PutFileResponse{Error: err.Error()}
+	}
+
+	log.Printf("[INFO] PutFile: wrote %s (%d bytes, sha256=%s)", fullPath, len(req.Content), sum[:12])
+
+	return &pb.PutFileResponse{
+		Path:      s.relToShared(fullPath),
+		SizeBytes: int64(len(req.Content)),
+		Sha256:    sum,
+	}
+}
+
+// forwardPutFile forwards a PutFile request to a remote device
+func (s *OrchestratorServer) forwardPutFile(ctx context.Context, req *pb.PutFileRequest) (*pb.PutFileResponse, error) {
+	client, sessionID, closeConn, err := s.dialDevice(ctx, req.DeviceId, "coordinator-putfile")
+	if err != nil {
+		return &pb.PutFileResponse{Error: err.Error()}, nil
+	}
+	defer closeConn()
+
+	return client.PutFile(ctx, &pb.PutFileRequest{
+		SessionId: sessionID,
+		Path:      req.Path,
+		Content:   req.Content,
+		Sha256:    req.Sha256,
+		Overwrite: req.Overwrite,
+	})
+}
+
+// handleBulkUpload receives chunked, resumable uploads for a valid token.
+//
+//	HEAD/GET  report progress via Upload-Offset and Upload-Length headers
+//	PUT/PATCH append the body at Upload-Offset (or Content-Range start)
+//	DELETE    abandon the upload and remove the partial file
+func (s *OrchestratorServer) handleBulkUpload(w http.ResponseWriter, r *http.Request) {
+	// Extract token from URL: /bulk/upload/<token>
+	const prefix = "/bulk/upload/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		http.NotFound(w, r)
+		return
+	}
+	token := strings.TrimPrefix(r.URL.Path, prefix)
+	if token == "" {
+		http.Error(w, "missing token", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodHead, http.MethodGet:
+		st := s.ticketManager.UploadStatus(token)
+		if st == nil {
+			http.Error(w, "invalid or expired token", http.StatusForbidden)
+			return
+		}
+		writeUploadStatus(w, r, http.StatusOK, st, "")
+
+	case http.MethodPut, http.MethodPatch:
+		offset, err := parseUploadOffset(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		st, err := s.ticketManager.WriteChunk(token, offset, r.Body)
+		if st == nil && err == nil {
+			http.Error(w, "invalid or expired token", http.StatusForbidden)
+			return
+		}
+		if err != nil {
+			code := http.StatusInternalServerError
+			switch {
+			case errors.Is(err, transfer.ErrOffsetMismatch):
+				code = http.StatusConflict
+			case errors.Is(err, transfer.ErrChunkOverflow):
+				code = http.StatusRequestEntityTooLarge
+			case errors.Is(err, transfer.ErrChecksumMismatch):
+				code = http.StatusUnprocessableEntity
+			case errors.Is(err, transfer.ErrFileExists):
+				code = http.StatusPreconditionFailed
+			}
+			log.Printf("[ERROR] handleBulkUpload: %v", err)
+			if st == nil {
+				http.Error(w, err.Error(), code)
+				return
+			}
+			writeUploadStatus(w, r, code, st, err.Error())
+			return
+		}
+
+		if st.Complete {
+			log.Printf("[INFO] handleBulkUpload: completed %s (%d bytes, sha256=%s)", st.DestPath, st.SizeBytes, st.SHA256[:12])
+		}
+		writeUploadStatus(w, r, http.StatusOK, st, "")
+
+	case http.MethodDelete:
+		if !s.ticketManager.CancelUpload(token) {
+			http.Error(w, "invalid or expired token", http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// parseUploadOffset reads the chunk offset from Upload-Offset, falling back to
+// the start of a "bytes start-end/total" Content-Range. Missing means 0.
+func parseUploadOffset(r *http.Request) (int64, error) {
+	if v := r.Header.Get("Upload-Offset"); v != "" {
+		offset, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || offset < 0 {
+			return 0, fmt.Errorf("invalid Upload-Offset: %q", v)
+		}
+		return offset, nil
+	}
+	if v := r.Header.Get("Content-Range"); v != "" {
+		spec := strings.TrimPrefix(v, "bytes ")
+		start, _, ok := strings.Cut(spec, "-")
+		if !ok {
+			return 0, fmt.Errorf("invalid Content-Range: %q", v)
+		}
+		offset, err := strconv.ParseInt(start, 10, 64)
+		if err != nil || offset < 0 {
+			return 0, fmt.Errorf("invalid Content-Range: %q", v)
+		}
+		return offset, nil
+	}
+	return 0, nil
+}
+
+// writeUploadStatus sets progress headers and, except for HEAD, a JSON body.
+func writeUploadStatus(w http.ResponseWriter, r *http.Request, code int, st *transfer.UploadStatus, errMsg string) {
+	w.Header().Set("Upload-Offset", strconv.FormatInt(st.ReceivedBytes, 10))
+	w.Header().Set("Upload-Length", strconv.FormatInt(st.SizeBytes, 10))
+	w.Header().Set("Cache-Control", "no-store")
+	if st.Complete {
+		w.Header().Set("X-Content-SHA256", st.SHA256)
+	}
+	if r.Method == http.MethodHead {
+		w.WriteHeader(code)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(uploadStatusResponse{
+		ReceivedBytes: st.ReceivedBytes,
+		SizeBytes:     st.SizeBytes,
+		Complete:      st.Complete,
+		SHA256:        st.SHA256,
+		Error:         errMsg,
+	})
+}
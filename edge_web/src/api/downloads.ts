@@ -7,7 +7,7 @@ export async function requestDownload(
 ): Promise<DownloadTicketResponse> {
   const request: DownloadRequest = {
     device_id: deviceId,
-    file_path: filePath,
+    path: filePath,
   };
   return apiPost<DownloadTicketResponse>('/api/request-download', request);
 }
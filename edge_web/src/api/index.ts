@@ -23,7 +23,14 @@ export { sendAssistantMessage } from './assistant';
 export { sendAgentMessage, getAgentHealth, getChatMemory } from './agent';
 
 // Streaming APIs
-export { startStream, sendStreamAnswer, stopStream } from './streaming';
+export {
+  startStream,
+  sendStreamAnswer,
+  sendStreamCandidate,
+  pollStreamCandidates,
+  stopStream,
+  listStreams,
+} from './streaming';
 export type { StreamOptions } from './streaming';
 
 // Download APIs
@@ -1,12 +1,20 @@
-import { apiPost } from './client';
+import { apiGet, apiPost } from './client';
 import type {
+  AudioSource,
+  DeviceStreams,
   RoutingPolicy,
+  StreamLayer,
+  StreamMode,
+  StreamRecordTarget,
   StreamStartRequest,
   StreamStartResponse,
   StreamAnswerRequest,
   StreamAnswerResponse,
   StreamStopRequest,
   StreamStopResponse,
+  StreamIceRequest,
+  StreamIcePollRequest,
+  StreamIcePollResponse,
 } from './types';
 
 export interface StreamOptions {
@@ -14,6 +22,16 @@ export interface StreamOptions {
   fps?: number;
   quality?: number;
   monitorIndex?: number;
+  mode?: StreamMode;
+  acceptModes?: StreamMode[];
+  maxBitrateKbps?: number;
+  control?: boolean;
+  audio?: boolean;
+  audioSource?: AudioSource;
+  trickle?: boolean;
+  layer?: StreamLayer;
+  viewer?: string;
+  record?: StreamRecordTarget;
 }
 
 export async function startStream(
@@ -26,6 +44,16 @@ export async function startStream(
     fps: options.fps,
     quality: options.quality,
     monitor_index: options.monitorIndex,
+    mode: options.mode,
+    accept_modes: options.acceptModes,
+    max_bitrate_kbps: options.maxBitrateKbps,
+    control: options.control,
+    audio: options.audio,
+    audio_source: options.audioSource,
+    trickle: options.trickle,
+    layer: options.layer,
+    viewer: options.viewer,
+    record: options.record,
   };
   return apiPost<StreamStartResponse>('/api/stream/start', request);
 }
@@ -43,9 +71,41 @@ export async function sendStreamAnswer(
   return apiPost<StreamAnswerResponse>('/api/stream/answer', request);
 }
 
+export async function sendStreamCandidate(
+  streamId: string,
+  candidate: RTCIceCandidateInit,
+  selectedDeviceAddr: string
+): Promise<void> {
+  const request: StreamIceRequest = {
+    stream_id: streamId,
+    candidate,
+    selected_device_addr: selectedDeviceAddr,
+  };
+  await apiPost<{ ok: boolean }>('/api/stream/ice', request);
+}
+
+/** Long-polls for the device's candidates after the first since */
+export async function pollStreamCandidates(
+  streamId: string,
+  since: number,
+  selectedDeviceAddr: string
+): Promise<StreamIcePollResponse> {
+  const request: StreamIcePollRequest = {
+    stream_id: streamId,
+    since,
+    selected_device_addr: selectedDeviceAddr,
+  };
+  return apiPost<StreamIcePollResponse>('/api/stream/ice/poll', request);
+}
+
 export async function stopStream(streamId: string): Promise<StreamStopResponse> {
   const request: StreamStopRequest = {
     stream_id: streamId,
   };
   return apiPost<StreamStopResponse>('/api/stream/stop', request);
 }
+
+/** Lists who is watching each device and how captures are shared */
+export async function listStreams(): Promise<DeviceStreams[]> {
+  return apiGet<DeviceStreams[]>('/api/streams');
+}
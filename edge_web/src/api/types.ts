@@ -20,6 +20,7 @@ export interface Device {
   has_local_model: boolean;
   local_model_name?: string;
   local_chat_endpoint?: string;
+  task_kinds?: string[]; // kinds the device runs; absent on older workers
 }
 
 // Helper to check capabilities
@@ -186,7 +187,40 @@ export interface StreamStartRequest {
   fps?: number;
   quality?: number;
   monitor_index?: number;
-}
+  /** Forces a mode; otherwise the device picks from accept_modes */
+  mode?: StreamMode;
+  accept_modes?: StreamMode[];
+  max_bitrate_kbps?: number;
+  /** Ask for mouse and keyboard control; needs the stream.control permission */
+  control?: boolean;
+  /** Add an Opus audio track */
+  audio?: boolean;
+  /** Where the audio comes from; the device's default if omitted */
+  audio_source?: AudioSource;
+  /** Return the offer at once and exchange candidates via /api/stream/ice */
+  trickle?: boolean;
+  /** Quality preset; sets the frame size and the defaults for fps, bitrate and quality */
+  layer?: StreamLayer;
+  /** Shown to others in /api/streams; the browser's address if omitted */
+  viewer?: string;
+  /** Record the stream on the streaming device or on the coordinator */
+  record?: StreamRecordTarget;
+}
+
+/** Where a stream is recorded; the coordinator keeps a copy as the device writes it */
+export type StreamRecordTarget = 'device' | 'coordinator';
+
+/** Quality presets a viewer can pick; viewers of one monitor share its capture */
+export type StreamLayer = 'high' | 'medium' | 'low';
+
+/** Audio sources a device can stream; "file" plays a file set on the device */
+export type AudioSource = 'system' | 'mic' | 'tone' | 'file';
+
+/**
+ * "video" sends a VP8 track; "tiles" sends changed regions over a
+ * DataChannel in chunks; "jpeg" sends one downscaled JPEG per message
+ */
+export type StreamMode = 'video' | 'tiles' | 'jpeg';
 
 export interface StreamStartResponse {
   stream_id: string;
@@ -194,8 +228,97 @@ export interface StreamStartResponse {
   selected_device_id: string;
   selected_device_name: string;
   selected_device_addr: string;
+  /** Empty when the device runs a build without video support (JPEG frames) */
+  mode?: StreamMode | '';
+  /** An input channel is offered; control starts once the device's user approves */
+  control?: boolean;
+  /** An audio track is offered */
+  audio?: boolean;
+  /** offer_sdp has no candidates; they follow via pollStreamCandidates */
+  trickle?: boolean;
+  /** STUN/TURN servers the device uses; pass them to RTCPeerConnection */
+  ice_servers?: RTCIceServer[];
+  /** Quality layer the stream uses; empty from older devices */
+  layer?: StreamLayer | '';
+  /** Recording path under the shared folder of recording_device_id; download it with requestDownload */
+  recording?: string;
+  recording_device_id?: string;
+}
+
+export interface StreamIceRequest {
+  selected_device_addr: string;
+  stream_id: string;
+  candidate: RTCIceCandidateInit;
+}
+
+export interface StreamIcePollRequest {
+  selected_device_addr: string;
+  stream_id: string;
+  /** Device candidates already received */
+  since: number;
+}
+
+export interface StreamIcePollResponse {
+  candidates: RTCIceCandidateInit[];
+  /** The device has gathered all its candidates */
+  complete: boolean;
 }
 
+/** An active stream on a device, from /api/streams */
+export interface StreamSession {
+  stream_id: string;
+  viewer: string;
+  mode: StreamMode;
+  layer: StreamLayer;
+  monitor_index: number;
+  target_fps: number;
+  /** Current video bitrate target; absent in DataChannel modes */
+  bitrate_kbps?: number;
+  /** Peer connection state */
+  state: string;
+  started_at_ms: number;
+  /** Time without a connected viewer; 0 while connected */
+  idle_ms: number;
+  audio: boolean;
+  control?: ControlState;
+  /** Recording path under the device's shared folder */
+  recording?: string;
+}
+
+/** A monitor capture shared by a device's streams */
+export interface CaptureFeed {
+  monitor_index: number;
+  subscribers: number;
+  captures: number;
+  frames_served: number;
+}
+
+export interface DeviceStreams {
+  device_id: string;
+  device_name: string;
+  grpc_addr: string;
+  /** 0 means no limit */
+  max_viewers: number;
+  idle_timeout_ms: number;
+  streams: StreamSession[];
+  feeds: CaptureFeed[];
+  /** Set when the device could not be asked */
+  error?: string;
+}
+
+/** Control states reported by the device on the input channel */
+export type ControlState = 'pending' | 'granted' | 'denied' | 'revoked';
+
+/**
+ * One event on the input channel. x and y are fractions (0..1) of the
+ * streamed frame; b is the DOM mouse button; k is the DOM key name.
+ */
+export type StreamInputEvent =
+  | { t: 'move'; x: number; y: number }
+  | { t: 'down' | 'up' | 'click'; x: number; y: number; b: number }
+  | { t: 'wheel'; dx: number; dy: number }
+  | { t: 'keydown' | 'keyup'; k: string };
+
 export interface StreamAnswerRequest {
   stream_id: string;
   answer_sdp: string;
@@ -217,7 +340,8 @@ export interface StreamStopResponse {
 // Download types
 export interface DownloadRequest {
   device_id: string;
-  file_path: string;
+  /** Path under the device's shared folder */
+  path: string;
 }
 
 export interface DownloadTicketResponse {
@@ -225,6 +349,8 @@ export interface DownloadTicketResponse {
   download_url: string;
   file_size: number;
   expires_at: string;
+  /** The file is still being written, e.g. a stream recording; the download follows it until it is finished */
+  live?: boolean;
 }
 
 // Assistant types
@@ -2,15 +2,31 @@ import { useState, useRef, useCallback } from 'react';
 import {
   startStream,
   sendStreamAnswer,
+  sendStreamCandidate,
+  pollStreamCandidates,
   stopStream,
+  type AudioSource,
+  type ControlState,
   type RoutingPolicy,
+  type StreamInputEvent,
+  type StreamLayer,
+  type StreamMode,
+  type StreamRecordTarget,
   type StreamStartResponse,
 } from '@/api';
+import { createTileReceiver, supportedStreamModes } from '@/lib/tileStream';
 
 type WebRTCState = 'idle' | 'connecting' | 'connected' | 'disconnected' | 'error';
 
 interface UseWebRTCOptions {
+  /** Called with a JPEG object URL per frame in jpeg mode */
   onFrame?: (frameUrl: string) => void;
+  /** Called with the remote media stream in video mode */
+  onTrack?: (stream: MediaStream) => void;
+  /** Called with the audio track's stream when audio was requested */
+  onAudio?: (stream: MediaStream) => void;
+  /** Returns the canvas tiles-mode frames are painted on */
+  getCanvas?: () => HTMLCanvasElement | null;
   onError?: (error: string) => void;
 }
 
@@ -19,6 +35,15 @@ interface StreamOptions {
   fps?: number;
   quality?: number;
   monitorIndex?: number;
+  /** Forces a mode; when unset the device picks from what this browser supports */
+  mode?: StreamMode;
+  maxBitrateKbps?: number;
+  /** Ask the device for mouse and keyboard control */
+  control?: boolean;
+  audio?: boolean;
+  audioSource?: AudioSource;
+  layer?: StreamLayer;
+  record?: StreamRecordTarget;
 }
 
 interface UseWebRTCResult {
@@ -26,20 +51,50 @@ interface UseWebRTCResult {
   error: string | null;
   streamInfo: StreamStartResponse | null;
   iceConnectionState: RTCIceConnectionState | null;
+  /** Null unless control was requested */
+  controlState: ControlState | null;
+  /** Sends an input event; dropped unless control is granted */
+  sendInput: (event: StreamInputEvent) => void;
   start: (policy: RoutingPolicy, options?: StreamOptions) => Promise<void>;
   stop: () => Promise<void>;
 }
 
+/**
+ * Adds the device's trickled candidates to pc until its gathering
+ * completes or the connection closes
+ */
+async function pollCandidates(pc: RTCPeerConnection, info: StreamStartResponse) {
+  let since = 0;
+  while (pc.signalingState !== 'closed') {
+    try {
+      const resp = await pollStreamCandidates(info.stream_id, since, info.selected_device_addr);
+      for (const candidate of resp.candidates) {
+        await pc.addIceCandidate(candidate);
+      }
+      since += resp.candidates.length;
+      if (resp.complete) return;
+    } catch (err) {
+      if (pc.signalingState !== 'closed') {
+        console.warn('Polling ICE candidates failed:', err);
+      }
+      return;
+    }
+  }
+}
+
 export function useWebRTC(options: UseWebRTCOptions = {}): UseWebRTCResult {
-  const { onFrame, onError } = options;
+  const { onFrame, onTrack, onAudio, getCanvas, onError } = options;
 
   const [state, setState] = useState<WebRTCState>('idle');
   const [error, setError] = useState<string | null>(null);
   const [streamInfo, setStreamInfo] = useState<StreamStartResponse | null>(null);
   const [iceConnectionState, setIceConnectionState] = useState<RTCIceConnectionState | null>(null);
+  const [controlState, setControlState] = useState<ControlState | null>(null);
 
   const pcRef = useRef<RTCPeerConnection | null>(null);
   const dcRef = useRef<RTCDataChannel | null>(null);
+  const inputRef = useRef<RTCDataChannel | null>(null);
+  const controlGrantedRef = useRef(false);
   const frameUrlRef = useRef<string | null>(null);
 
   const cleanup = useCallback(() => {
@@ -49,11 +104,17 @@ export function useWebRTC(options: UseWebRTCOptions = {}): UseWebRTCResult {
       frameUrlRef.current = null;
     }
 
-    // Close data channel
+    // Close data channels
     if (dcRef.current) {
       dcRef.current.close();
       dcRef.current = null;
     }
+    if (inputRef.current) {
+      inputRef.current.close();
+      inputRef.current = null;
+    }
+    controlGrantedRef.current = false;
+    setControlState(null);
 
     // Close peer connection
     if (pcRef.current) {
@@ -76,13 +137,40 @@ export function useWebRTC(options: UseWebRTCOptions = {}): UseWebRTCResult {
           fps: streamOptions.fps,
           quality: streamOptions.quality,
           monitorIndex: streamOptions.monitorIndex,
+          mode: streamOptions.mode,
+          acceptModes: streamOptions.mode ? undefined : supportedStreamModes(),
+          maxBitrateKbps: streamOptions.maxBitrateKbps,
+          control: streamOptions.control,
+          audio: streamOptions.audio,
+          audioSource: streamOptions.audioSource,
+          layer: streamOptions.layer,
+          record: streamOptions.record,
+          trickle: true,
         });
         setStreamInfo(startResponse);
+        if (startResponse.control) {
+          setControlState('pending');
+        }
 
-        // Step 2: Create RTCPeerConnection (no ICE servers for local/direct)
-        const pc = new RTCPeerConnection();
+        // Step 2: Create RTCPeerConnection with the device's STUN/TURN
+        // servers, which include the coordinator's relay if it runs one
+        const pc = new RTCPeerConnection({ iceServers: startResponse.ice_servers ?? [] });
         pcRef.current = pc;
 
+        // Trickle ICE: our candidates go to the device as they are found.
+        // Devices running an older build return a complete offer instead.
+        const trickle = !!startResponse.trickle;
+        if (trickle) {
+          pc.onicecandidate = (event) => {
+            if (!event.candidate) return;
+            sendStreamCandidate(
+              startResponse.stream_id,
+              event.candidate.toJSON(),
+              startResponse.selected_device_addr
+            ).catch((err) => console.warn('Sending ICE candidate failed:', err));
+          };
+        }
+
         // Monitor ICE connection state
         pc.oniceconnectionstatechange = () => {
           setIceConnectionState(pc.iceConnectionState);
@@ -95,9 +183,42 @@ export function useWebRTC(options: UseWebRTCOptions = {}): UseWebRTCResult {
           }
         };
 
-        // Handle incoming data channel for screen frames
+        // Video mode: screen frames arrive as a VP8 track. Audio comes as
+        // its own track in any mode.
+        pc.ontrack = (event) => {
+          if (event.track.kind === 'audio') {
+            onAudio?.(new MediaStream([event.track]));
+            return;
+          }
+          onTrack?.(event.streams[0] ?? new MediaStream([event.track]));
+          setState('connected');
+        };
+
+        // Tiles and JPEG modes: screen frames arrive on a data channel
         pc.ondatachannel = (event) => {
           const dc = event.channel;
+
+          // Input channel: the device reports control state changes on it
+          if (dc.label === 'input') {
+            inputRef.current = dc;
+            dc.onmessage = (msgEvent) => {
+              try {
+                const status = JSON.parse(msgEvent.data);
+                if (status.t === 'control') {
+                  controlGrantedRef.current = status.state === 'granted';
+                  setControlState(status.state as ControlState);
+                }
+              } catch {
+                // Ignore malformed status messages
+              }
+            };
+            dc.onclose = () => {
+              controlGrantedRef.current = false;
+              setControlState((prev) => (prev === 'granted' ? 'revoked' : prev));
+            };
+            return;
+          }
+
           dcRef.current = dc;
 
           dc.binaryType = 'arraybuffer';
@@ -106,6 +227,16 @@ export function useWebRTC(options: UseWebRTCOptions = {}): UseWebRTCResult {
             setState('connected');
           };
 
+          dc.onclose = () => {
+            setState('disconnected');
+          };
+
+          if (startResponse.mode === 'tiles') {
+            const onChunk = createTileReceiver(() => getCanvas?.() ?? null);
+            dc.onmessage = (msgEvent) => onChunk(msgEvent.data);
+            return;
+          }
+
           dc.onmessage = (msgEvent) => {
             // Binary JPEG frame
             const blob = new Blob([msgEvent.data], { type: 'image/jpeg' });
@@ -119,10 +250,6 @@ export function useWebRTC(options: UseWebRTCOptions = {}): UseWebRTCResult {
             frameUrlRef.current = url;
             onFrame?.(url);
           };
-
-          dc.onclose = () => {
-            setState('disconnected');
-          };
         };
 
         // Step 3: Set remote description (server's offer)
@@ -131,12 +258,22 @@ export function useWebRTC(options: UseWebRTCOptions = {}): UseWebRTCResult {
           sdp: startResponse.offer_sdp,
         });
 
+        if (trickle) {
+          pollCandidates(pc, startResponse);
+        }
+
         // Step 4: Create and set local answer
         const answer = await pc.createAnswer();
         await pc.setLocalDescription(answer);
 
-        // Step 5: Wait for ICE gathering to complete (with timeout)
-        await new Promise<void>((resolve, reject) => {
+        // Step 5: Without trickle ICE, wait for gathering so the answer
+        // carries every candidate (with timeout)
+        await new Promise<void>((resolve) => {
+          if (trickle) {
+            resolve();
+            return;
+          }
+
           const timeout = setTimeout(() => {
             resolve(); // Proceed even if not all candidates gathered
           }, 5000);
@@ -174,9 +311,16 @@ export function useWebRTC(options: UseWebRTCOptions = {}): UseWebRTCResult {
         cleanup();
       }
     },
-    [onFrame, onError, cleanup]
+    [onFrame, onTrack, onAudio, getCanvas, onError, cleanup]
   );
 
+  const sendInput = useCallback((event: StreamInputEvent) => {
+    const dc = inputRef.current;
+    if (controlGrantedRef.current && dc?.readyState === 'open') {
+      dc.send(JSON.stringify(event));
+    }
+  }, []);
+
   const stop = useCallback(async () => {
     try {
       if (streamInfo) {
@@ -197,6 +341,8 @@ export function useWebRTC(options: UseWebRTCOptions = {}): UseWebRTCResult {
     error,
     streamInfo,
     iceConnectionState,
+    controlState,
+    sendInput,
     start,
     stop,
   };
@@ -0,0 +1,76 @@
+// Pointer and keyboard capture for remote control (see
+// internal/webrtcstream/input.go). Positions are sent as fractions of the
+// streamed frame, so letterboxing from object-contain is subtracted first.
+
+import type { KeyboardEvent, MouseEvent, WheelEvent } from 'react';
+import type { StreamInputEvent } from '@/api';
+
+const MOVE_INTERVAL_MS = 16;
+
+type Viewport = HTMLVideoElement | HTMLCanvasElement | HTMLImageElement;
+
+/** Intrinsic frame size of the element showing the stream */
+function frameSize(el: Viewport): [number, number] {
+  if (el instanceof HTMLVideoElement) return [el.videoWidth, el.videoHeight];
+  if (el instanceof HTMLImageElement) return [el.naturalWidth, el.naturalHeight];
+  return [el.width, el.height];
+}
+
+/** Maps a client position to a fraction of the frame, or null outside it */
+function framePoint(el: Viewport, clientX: number, clientY: number): { x: number; y: number } | null {
+  const rect = el.getBoundingClientRect();
+  const [fw, fh] = frameSize(el);
+  if (!fw || !fh || !rect.width || !rect.height) return null;
+  const scale = Math.min(rect.width / fw, rect.height / fh);
+  const w = fw * scale;
+  const h = fh * scale;
+  const x = (clientX - rect.left - (rect.width - w) / 2) / w;
+  const y = (clientY - rect.top - (rect.height - h) / 2) / h;
+  if (x < 0 || x > 1 || y < 0 || y > 1) return null;
+  return { x, y };
+}
+
+/**
+ * Returns event handler props for the stream viewport that forward input
+ * through send. Mouse moves are throttled; everything else goes at once.
+ */
+export function createControlHandlers(send: (event: StreamInputEvent) => void) {
+  let lastMove = 0;
+
+  return {
+    tabIndex: 0,
+    onMouseMove: (e: MouseEvent<Viewport>) => {
+      const now = performance.now();
+      if (now - lastMove < MOVE_INTERVAL_MS) return;
+      const p = framePoint(e.currentTarget, e.clientX, e.clientY);
+      if (!p) return;
+      lastMove = now;
+      send({ t: 'move', ...p });
+    },
+    onMouseDown: (e: MouseEvent<Viewport>) => {
+      e.currentTarget.focus();
+      const p = framePoint(e.currentTarget, e.clientX, e.clientY);
+      if (!p) return;
+      e.preventDefault();
+      send({ t: 'down', ...p, b: e.button });
+    },
+    onMouseUp: (e: MouseEvent<Viewport>) => {
+      const p = framePoint(e.currentTarget, e.clientX, e.clientY);
+      if (!p) return;
+      e.preventDefault();
+      send({ t: 'up', ...p, b: e.button });
+    },
+    onContextMenu: (e: MouseEvent<Viewport>) => e.preventDefault(),
+    onWheel: (e: WheelEvent<Viewport>) => {
+      send({ t: 'wheel', dx: e.deltaX, dy: e.deltaY });
+    },
+    onKeyDown: (e: KeyboardEvent<Viewport>) => {
+      e.preventDefault();
+      send({ t: 'keydown', k: e.key });
+    },
+    onKeyUp: (e: KeyboardEvent<Viewport>) => {
+      e.preventDefault();
+      send({ t: 'keyup', k: e.key });
+    },
+  };
+}
@@ -0,0 +1,111 @@
+// Receiver for the "tiles" stream mode (see internal/webrtcstream/framing.go
+// and tiles.go). Each DataChannel message is a chunk with a 12-byte header;
+// the chunks of a frame reassemble into a list of changed rectangles, each a
+// JPEG painted at its offset on a canvas.
+
+import type { StreamMode } from '@/api';
+
+const CHUNK_MAGIC = 0xed;
+const CHUNK_HEADER_SIZE = 12;
+const RECT_HEADER_SIZE = 12;
+
+interface PartialFrame {
+  chunks: Uint8Array[];
+  received: number;
+}
+
+/** Modes this browser can display, best first */
+export function supportedStreamModes(): StreamMode[] {
+  const modes: StreamMode[] = [];
+  const caps = typeof RTCRtpReceiver !== 'undefined' ? RTCRtpReceiver.getCapabilities?.('video') : null;
+  if (caps?.codecs.some((c) => c.mimeType.toLowerCase() === 'video/vp8')) {
+    modes.push('video');
+  }
+  modes.push('tiles', 'jpeg');
+  return modes;
+}
+
+/**
+ * Returns a handler for tiles-mode messages that paints complete frames
+ * onto the canvas returned by getCanvas. Frames are painted strictly in
+ * order, since each delta applies on top of the previous frame.
+ */
+export function createTileReceiver(
+  getCanvas: () => HTMLCanvasElement | null
+): (data: ArrayBuffer) => void {
+  const pending = new Map<number, PartialFrame>();
+  let painting = Promise.resolve();
+
+  const paint = async (buf: Uint8Array) => {
+    const canvas = getCanvas();
+    const ctx = canvas?.getContext('2d');
+    if (!canvas || !ctx) {
+      return;
+    }
+    const view = new DataView(buf.buffer, buf.byteOffset, buf.byteLength);
+    const width = view.getUint16(0, true);
+    const height = view.getUint16(2, true);
+    const count = view.getUint16(4, true);
+    if (canvas.width !== width || canvas.height !== height) {
+      canvas.width = width;
+      canvas.height = height;
+    }
+
+    const rects: Promise<{ x: number; y: number; bitmap: ImageBitmap }>[] = [];
+    let offset = 6;
+    for (let i = 0; i < count; i++) {
+      const x = view.getUint16(offset, true);
+      const y = view.getUint16(offset + 2, true);
+      const length = view.getUint32(offset + 8, true);
+      const start = offset + RECT_HEADER_SIZE;
+      const blob = new Blob([buf.slice(start, start + length)], { type: 'image/jpeg' });
+      rects.push(createImageBitmap(blob).then((bitmap) => ({ x, y, bitmap })));
+      offset = start + length;
+    }
+    for (const { x, y, bitmap } of await Promise.all(rects)) {
+      ctx.drawImage(bitmap, x, y);
+      bitmap.close();
+    }
+  };
+
+  return (data: ArrayBuffer) => {
+    const msg = new Uint8Array(data);
+    if (msg.length < CHUNK_HEADER_SIZE || msg[0] !== CHUNK_MAGIC) {
+      return;
+    }
+    const view = new DataView(data);
+    const frameId = view.getUint32(4, true);
+    const index = view.getUint16(8, true);
+    const count = view.getUint16(10, true);
+
+    let frame = pending.get(frameId);
+    if (!frame) {
+      frame = { chunks: new Array(count), received: 0 };
+      pending.set(frameId, frame);
+    }
+    if (!frame.chunks[index]) {
+      frame.chunks[index] = msg.subarray(CHUNK_HEADER_SIZE);
+      frame.received++;
+    }
+    if (frame.received < count) {
+      return;
+    }
+
+    // Chunks arrive in order, so older frames will never complete
+    for (const id of pending.keys()) {
+      if (id <= frameId) {
+        pending.delete(id);
+      }
+    }
+    const size = frame.chunks.reduce((n, c) => n + c.length, 0);
+    const buf = new Uint8Array(size);
+    let offset = 0;
+    for (const c of frame.chunks) {
+      buf.set(c, offset);
+      offset += c.length;
+    }
+    painting = painting
+      .then(() => paint(buf))
+      .catch((err) => console.error('Tile frame error:', err));
+  };
+}
@@ -1,12 +1,27 @@
-import { useState, useEffect, useCallback } from 'react';
+import { useState, useEffect, useCallback, useMemo, useRef } from 'react';
 import { GlassCard, GlassContainer } from '@/components/GlassCard';
 import { Button } from '@/components/ui/button';
 import { Input } from '@/components/ui/input';
 import { Label } from '@/components/ui/label';
 import { Badge } from '@/components/ui/badge';
+import { Switch } from '@/components/ui/switch';
+import { Table, TableBody, TableCell, TableHead, TableHeader, TableRow } from '@/components/ui/table';
 import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from '@/components/ui/select';
-import { listDevices, type Device, type RoutingPolicy } from '@/api';
+import {
+  listDevices,
+  listStreams,
+  requestDownload,
+  triggerDownload,
+  type AudioSource,
+  type Device,
+  type DeviceStreams,
+  type RoutingPolicy,
+  type StreamLayer,
+  type StreamMode,
+  type StreamRecordTarget,
+} from '@/api';
 import { useWebRTC } from '@/hooks/useWebRTC';
+import { createControlHandlers } from '@/lib/remoteControl';
 import {
   Eye,
   Play,
@@ -17,6 +32,9 @@ import {
   Wifi,
   WifiOff,
   Settings,
+  MousePointer2,
+  Users,
+  Download,
 } from 'lucide-react';
 
 const streamPolicies: { value: RoutingPolicy; label: string }[] = [
@@ -35,6 +53,20 @@ const iceStateColors: Record<string, string> = {
   closed: 'text-muted-foreground',
 };
 
+// Default rates of each quality layer; the inputs override them
+const streamLayers: Record<StreamLayer, { label: string; fps: number; kbps: number; quality: number }> = {
+  high: { label: 'High', fps: 15, kbps: 2500, quality: 60 },
+  medium: { label: 'Medium (960px)', fps: 10, kbps: 1200, quality: 50 },
+  low: { label: 'Low (640px)', fps: 5, kbps: 500, quality: 40 },
+};
+
+const controlStateLabels: Record<string, string> = {
+  pending: 'Awaiting approval',
+  granted: 'In control',
+  denied: 'Control denied',
+  revoked: 'Control ended',
+};
+
 export const LiveViewPage = () => {
   // Devices state
   const [devices, setDevices] = useState<Device[]>([]);
@@ -43,20 +75,56 @@ export const LiveViewPage = () => {
   // Stream settings
   const [policy, setPolicy] = useState<RoutingPolicy>('BEST_AVAILABLE');
   const [forceDeviceId, setForceDeviceId] = useState<string>('');
-  const [fps, setFps] = useState(8);
+  const [mode, setMode] = useState<StreamMode | 'auto'>('auto');
+  const [fps, setFps] = useState(15);
+  const [maxBitrateKbps, setMaxBitrateKbps] = useState(2500);
   const [quality, setQuality] = useState(60);
   const [monitorIndex, setMonitorIndex] = useState(0);
+  const [control, setControl] = useState(false);
+  const [audio, setAudio] = useState<AudioSource | 'off' | 'default'>('off');
+  const [layer, setLayer] = useState<StreamLayer>('high');
+  const [record, setRecord] = useState<StreamRecordTarget | 'off'>('off');
+  const [recordingError, setRecordingError] = useState<string | null>(null);
   const [showSettings, setShowSettings] = useState(false);
 
-  // Frame state
+  // Frame state: a JPEG URL in jpeg mode, a media stream in video mode;
+  // tiles mode paints straight onto the canvas
   const [frameUrl, setFrameUrl] = useState<string | null>(null);
+  const [mediaStream, setMediaStream] = useState<MediaStream | null>(null);
+  const [audioStream, setAudioStream] = useState<MediaStream | null>(null);
+  const videoRef = useRef<HTMLVideoElement | null>(null);
+  const audioRef = useRef<HTMLAudioElement | null>(null);
+  const canvasRef = useRef<HTMLCanvasElement | null>(null);
+  const getCanvas = useCallback(() => canvasRef.current, []);
 
   // WebRTC hook
-  const { state, error, streamInfo, iceConnectionState, start, stop } = useWebRTC({
+  const { state, error, streamInfo, iceConnectionState, controlState, sendInput, start, stop } = useWebRTC({
     onFrame: setFrameUrl,
+    onTrack: setMediaStream,
+    onAudio: setAudioStream,
+    getCanvas,
     onError: (err) => console.error('WebRTC error:', err),
   });
 
+  // Input handlers for the viewport, attached only while in control
+  const controlHandlers = useMemo(
+    () => (controlState === 'granted' ? createControlHandlers(sendInput) : {}),
+    [controlState, sendInput]
+  );
+  const controlClass = controlState === 'granted' ? 'outline-none cursor-crosshair ring-2 ring-danger-pink' : '';
+
+  useEffect(() => {
+    if (videoRef.current) {
+      videoRef.current.srcObject = mediaStream;
+    }
+  }, [mediaStream]);
+
+  useEffect(() => {
+    if (audioRef.current) {
+      audioRef.current.srcObject = audioStream;
+    }
+  }, [audioStream]);
+
   // Filter devices that can screen capture
   const screenCapableDevices = devices.filter((d) => d.can_screen_capture);
 
@@ -82,6 +150,29 @@ export const LiveViewPage = () => {
     fetchDevices();
   }, [fetchDevices]);
 
+  // Who is watching each device
+  const [deviceStreams, setDeviceStreams] = useState<DeviceStreams[]>([]);
+  const fetchStreams = useCallback(async () => {
+    try {
+      setDeviceStreams(await listStreams());
+    } catch (err) {
+      console.error('Failed to list streams:', err);
+    }
+  }, []);
+
+  useEffect(() => {
+    fetchStreams();
+    const interval = setInterval(fetchStreams, 5000);
+    return () => clearInterval(interval);
+  }, [fetchStreams, state]);
+
+  const handleLayerChange = (value: StreamLayer) => {
+    setLayer(value);
+    setFps(streamLayers[value].fps);
+    setMaxBitrateKbps(streamLayers[value].kbps);
+    setQuality(streamLayers[value].quality);
+  };
+
   // Handle start stream
   const handleStart = () => {
     start(policy, {
@@ -89,13 +180,34 @@ export const LiveViewPage = () => {
       fps,
       quality,
       monitorIndex,
+      mode: mode === 'auto' ? undefined : mode,
+      maxBitrateKbps,
+      control,
+      audio: audio !== 'off',
+      audioSource: audio === 'off' || audio === 'default' ? undefined : audio,
+      layer,
+      record: record === 'off' ? undefined : record,
     });
   };
 
+  // Download the recording; while the stream runs the download follows it
+  const handleDownloadRecording = async () => {
+    if (!streamInfo?.recording || !streamInfo.recording_device_id) return;
+    setRecordingError(null);
+    try {
+      const ticket = await requestDownload(streamInfo.recording_device_id, streamInfo.recording);
+      triggerDownload(ticket.download_url, streamInfo.recording.split('/').pop());
+    } catch (err) {
+      setRecordingError(err instanceof Error ? err.message : 'Failed to download recording');
+    }
+  };
+
   // Handle stop stream
   const handleStop = () => {
     stop();
     setFrameUrl(null);
+    setMediaStream(null);
+    setAudioStream(null);
   };
 
   const isStreaming = state === 'connecting' || state === 'connected';
@@ -119,13 +231,31 @@ export const LiveViewPage = () => {
         </Button>
       </div>
 
-      {/* Viewport */}
+      {/* Viewport; the video element stays muted, so audio plays here */}
+      <audio ref={audioRef} autoPlay className="hidden" />
       <GlassContainer className="aspect-video flex items-center justify-center relative overflow-hidden">
-        {frameUrl ? (
+        {mediaStream ? (
+          <video
+            ref={videoRef}
+            autoPlay
+            muted
+            playsInline
+            className={`w-full h-full object-contain ${controlClass}`}
+            {...controlHandlers}
+          />
+        ) : streamInfo?.mode === 'tiles' ? (
+          <canvas
+            ref={canvasRef}
+            className={`w-full h-full object-contain ${controlClass}`}
+            {...controlHandlers}
+          />
+        ) : frameUrl ? (
           <img
             src={frameUrl}
             alt="Live stream"
-            className="w-full h-full object-contain"
+            className={`w-full h-full object-contain ${controlClass}`}
+            draggable={false}
+            {...controlHandlers}
           />
         ) : isStreaming ? (
           <div className="text-center space-y-4">
@@ -180,6 +310,17 @@ export const LiveViewPage = () => {
               </span>
               {state === 'connected' ? 'LIVE' : 'CONNECTING'}
             </Badge>
+            {controlState && (
+              <Badge
+                variant="outline"
+                className={`gap-1 ${
+                  controlState === 'granted' ? 'bg-danger-pink/20 border-danger-pink text-danger-pink' : ''
+                }`}
+              >
+                <MousePointer2 className="w-3 h-3" />
+                {controlStateLabels[controlState]}
+              </Badge>
+            )}
           </div>
         )}
 
@@ -284,6 +425,60 @@ export const LiveViewPage = () => {
           {/* Advanced Settings */}
           {showSettings && (
             <div className="grid grid-cols-1 sm:grid-cols-3 gap-4 pt-4 border-t border-outline">
+              <div className="space-y-2">
+                <Label htmlFor="layer">Quality</Label>
+                <Select
+                  value={layer}
+                  onValueChange={(v) => handleLayerChange(v as StreamLayer)}
+                  disabled={isStreaming}
+                >
+                  <SelectTrigger id="layer" className="bg-surface-2 border-outline">
+                    <SelectValue />
+                  </SelectTrigger>
+                  <SelectContent>
+                    {(Object.keys(streamLayers) as StreamLayer[]).map((l) => (
+                      <SelectItem key={l} value={l}>
+                        {streamLayers[l].label}
+                      </SelectItem>
+                    ))}
+                  </SelectContent>
+                </Select>
+              </div>
+              <div className="space-y-2">
+                <Label htmlFor="record">Record</Label>
+                <Select
+                  value={record}
+                  onValueChange={(v) => setRecord(v as StreamRecordTarget | 'off')}
+                  disabled={isStreaming}
+                >
+                  <SelectTrigger id="record" className="bg-surface-2 border-outline">
+                    <SelectValue />
+                  </SelectTrigger>
+                  <SelectContent>
+                    <SelectItem value="off">Off</SelectItem>
+                    <SelectItem value="device">On the device</SelectItem>
+                    <SelectItem value="coordinator">On the coordinator</SelectItem>
+                  </SelectContent>
+                </Select>
+              </div>
+              <div className="space-y-2">
+                <Label htmlFor="mode">Mode</Label>
+                <Select
+                  value={mode}
+                  onValueChange={(v) => setMode(v as StreamMode | 'auto')}
+                  disabled={isStreaming}
+                >
+                  <SelectTrigger id="mode" className="bg-surface-2 border-outline">
+                    <SelectValue />
+                  </SelectTrigger>
+                  <SelectContent>
+                    <SelectItem value="auto">Auto</SelectItem>
+                    <SelectItem value="video">Video (VP8)</SelectItem>
+                    <SelectItem value="tiles">Tiles (changed regions)</SelectItem>
+                    <SelectItem value="jpeg">JPEG frames (legacy)</SelectItem>
+                  </SelectContent>
+                </Select>
+              </div>
               <div className="space-y-2">
                 <Label htmlFor="fps">FPS (1-30)</Label>
                 <Input
@@ -292,13 +487,26 @@ export const LiveViewPage = () => {
                   min={1}
                   max={30}
                   value={fps}
-                  onChange={(e) => setFps(parseInt(e.target.value) || 8)}
+                  onChange={(e) => setFps(parseInt(e.target.value) || 15)}
                   className="bg-surface-2 border-outline"
                   disabled={isStreaming}
                 />
               </div>
               <div className="space-y-2">
-                <Label htmlFor="quality">Quality (10-100)</Label>
+                <Label htmlFor="bitrate">Max Bitrate (kbps)</Label>
+                <Input
+                  id="bitrate"
+                  type="number"
+                  min={150}
+                  max={20000}
+                  value={maxBitrateKbps}
+                  onChange={(e) => setMaxBitrateKbps(parseInt(e.target.value) || 2500)}
+                  className="bg-surface-2 border-outline"
+                  disabled={isStreaming || mode === 'tiles' || mode === 'jpeg'}
+                />
+              </div>
+              <div className="space-y-2">
+                <Label htmlFor="quality">JPEG Quality (10-100)</Label>
                 <Input
                   id="quality"
                   type="number"
@@ -307,7 +515,7 @@ export const LiveViewPage = () => {
                   value={quality}
                   onChange={(e) => setQuality(parseInt(e.target.value) || 60)}
                   className="bg-surface-2 border-outline"
-                  disabled={isStreaming}
+                  disabled={isStreaming || mode === 'video'}
                 />
               </div>
               <div className="space-y-2">
@@ -323,6 +531,39 @@ export const LiveViewPage = () => {
                   disabled={isStreaming}
                 />
               </div>
+              <div className="space-y-2">
+                <Label htmlFor="audio">Audio</Label>
+                <Select
+                  value={audio}
+                  onValueChange={(v) => setAudio(v as AudioSource | 'off' | 'default')}
+                  disabled={isStreaming}
+                >
+                  <SelectTrigger id="audio" className="bg-surface-2 border-outline">
+                    <SelectValue />
+                  </SelectTrigger>
+                  <SelectContent>
+                    <SelectItem value="off">Off</SelectItem>
+                    <SelectItem value="default">Device default</SelectItem>
+                    <SelectItem value="system">System audio</SelectItem>
+                    <SelectItem value="mic">Microphone</SelectItem>
+                    <SelectItem value="tone">Test tone</SelectItem>
+                  </SelectContent>
+                </Select>
+              </div>
+              <div className="space-y-2">
+                <Label htmlFor="control">Request Control</Label>
+                <div className="flex items-center gap-2 h-10">
+                  <Switch
+                    id="control"
+                    checked={control}
+                    onCheckedChange={setControl}
+                    disabled={isStreaming}
+                  />
+                  <span className="text-sm text-muted-foreground">
+                    Mouse and keyboard, after approval on the device
+                  </span>
+                </div>
+              </div>
             </div>
           )}
         </div>
@@ -347,12 +588,105 @@ export const LiveViewPage = () => {
             <div>
               <span className="text-muted-foreground">Settings:</span>
               <p className="font-mono text-xs">
-                {fps}fps / {quality}% / Mon {monitorIndex}
+                {streamInfo.mode || 'jpeg'}
+                {streamInfo.layer ? ` (${streamInfo.layer})` : ''}
+                {streamInfo.audio ? '+audio' : ''} / {fps}fps /{' '}
+                {streamInfo.mode === 'video' ? `${maxBitrateKbps}kbps` : `${quality}%`} / Mon{' '}
+                {monitorIndex}
               </p>
             </div>
           </div>
+          {streamInfo.recording && (
+            <div className="flex items-center gap-3 mt-4 pt-4 border-t border-outline text-sm">
+              <span className="text-muted-foreground">Recording:</span>
+              <span className="font-mono text-xs">{streamInfo.recording}</span>
+              <Button variant="outline" size="sm" onClick={handleDownloadRecording}>
+                <Download className="w-4 h-4 mr-2" />
+                Download
+              </Button>
+              {recordingError && <span className="text-danger-pink">{recordingError}</span>}
+            </div>
+          )}
         </GlassCard>
       )}
+
+      {/* Who's Watching */}
+      <GlassCard className="p-4">
+        <div className="flex items-center gap-2 mb-3">
+          <Users className="w-4 h-4" />
+          <h2 className="font-semibold">Who's Watching</h2>
+        </div>
+        {deviceStreams.every((d) => d.streams.length === 0 && !d.error) ? (
+          <p className="text-sm text-muted-foreground">No active streams</p>
+        ) : (
+          <div className="overflow-x-auto">
+            <Table>
+              <TableHeader>
+                <TableRow>
+                  <TableHead>Device</TableHead>
+                  <TableHead>Viewer</TableHead>
+                  <TableHead>Monitor</TableHead>
+                  <TableHead>Mode</TableHead>
+                  <TableHead>State</TableHead>
+                  <TableHead className="text-right">Since</TableHead>
+                </TableRow>
+              </TableHeader>
+              <TableBody>
+                {deviceStreams.map((d) =>
+                  d.error ? (
+                    <TableRow key={d.device_id}>
+                      <TableCell className="text-sm">{d.device_name}</TableCell>
+                      <TableCell colSpan={5} className="text-sm text-danger-pink">
+                        Unreachable: {d.error}
+                      </TableCell>
+                    </TableRow>
+                  ) : (
+                    d.streams.map((s) => {
+                      const feed = d.feeds.find((f) => f.monitor_index === s.monitor_index);
+                      return (
+                        <TableRow key={s.stream_id}>
+                          <TableCell className="text-sm">
+                            {d.device_name}
+                            {d.max_viewers > 0 && (
+                              <span className="text-muted-foreground">
+                                {' '}
+                                ({d.streams.length}/{d.max_viewers})
+                              </span>
+                            )}
+                          </TableCell>
+                          <TableCell className="font-mono text-xs">{s.viewer || '-'}</TableCell>
+                          <TableCell className="text-sm">
+                            {s.monitor_index}
+                            {feed && feed.subscribers > 1 && (
+                              <Badge variant="outline" className="ml-2">
+                                shared by {feed.subscribers}
+                              </Badge>
+                            )}
+                          </TableCell>
+                          <TableCell className="text-sm">
+                            {s.mode} / {s.layer}
+                            {s.control ? `, control ${s.control}` : ''}
+                            {s.recording ? ', recording' : ''}
+                          </TableCell>
+                          <TableCell className="text-sm">
+                            {s.state}
+                            {s.idle_ms > 0 && (
+                              <span className="text-warning-amber">, idle {Math.round(s.idle_ms / 1000)}s</span>
+                            )}
+                          </TableCell>
+                          <TableCell className="text-right font-mono text-xs">
+                            {new Date(s.started_at_ms).toLocaleTimeString()}
+                          </TableCell>
+                        </TableRow>
+                      );
+                    })
+                  )
+                )}
+              </TableBody>
+            </Table>
+          </div>
+        )}
+      </GlassCard>
     </div>
   );
 };
@@ -0,0 +1,386 @@
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/edgecli/edgecli/internal/jobs"
+	"github.com/edgecli/edgecli/internal/metrics"
+)
+
+const (
+	// EvalInterval is how often Run evaluates the metric and offline rules
+	EvalInterval = 5 * time.Second
+	// staleAfter is the age past which a device's latest sample is ignored
+	staleAfter = 30 * time.Second
+	// recentSize is how many alerts Recent keeps
+	recentSize = 100
+	// queueSize bounds the alerts waiting for the sinks; more are dropped
+	queueSize = 256
+	// notifyTimeout bounds one sink call
+	notifyTimeout = 10 * time.Second
+)
+
+// Alert states
+const (
+	StateFiring   = "firing"
+	StateResolved = "resolved"
+)
+
+// Alert is a rule firing or resolving. Job alerts fire once and never resolve.
+type Alert struct {
+	ID         string    `json:"id"` // rule and subject; the same when the alert resolves
+	Rule       string    `json:"rule"`
+	Kind       Kind      `json:"kind"`
+	Severity   string    `json:"severity"`
+	State      string    `json:"state"`
+	DeviceID   string    `json:"device_id,omitempty"`
+	DeviceName string    `json:"device_name,omitempty"`
+	JobID      string    `json:"job_id,omitempty"`
+	Value      float64   `json:"value,omitempty"` // the metric's value, seconds unseen, or failed tasks
+	Message    string    `json:"message"`
+	Since      time.Time `json:"since"` // when the condition began to hold
+	Time       time.Time `json:"time"`
+}
+
+// Device is one device the engine watches
+type Device struct {
+	ID       string
+	Name     string
+	LastSeen time.Time
+}
+
+// state tracks one rule against one device
+type state struct {
+	since  time.Time // when the condition began to hold
+	firing bool
+	alert  Alert // the firing alert
+}
+
+// Engine evaluates rules and hands alerts to its sinks and subscribers
+type Engine struct {
+	rules   []Rule
+	store   *metrics.MetricsStore
+	devices func() []Device
+	sinks   []Sink
+	queue   chan Alert
+
+	mu     sync.Mutex
+	states map[string]*state // by alert ID
+	recent []Alert
+	subs   map[chan Alert]struct{}
+}
+
+// NewEngine returns an engine checking rules against the samples in store
+// and the devices listed by devices. Call Run to evaluate and notify sinks.
+func NewEngine(rules []Rule, store *metrics.MetricsStore, devices func() []Device, sinks ...Sink) *Engine {
+	return &Engine{
+		rules:   rules,
+		store:   store,
+		devices: devices,
+		sinks:   sinks,
+		queue:   make(chan Alert, queueSize),
+		states:  make(map[string]*state),
+		subs:    make(map[chan Alert]struct{}),
+	}
+}
+
+// Rules returns the rules the engine evaluates
+func (e *Engine) Rules() []Rule {
+	return e.rules
+}
+
+// Run evaluates the rules every interval and delivers alerts to the sinks
+// until ctx is done
+func (e *Engine) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.Evaluate(time.Now())
+		case a := <-e.queue:
+			e.deliver(ctx, a)
+		}
+	}
+}
+
+func (e *Engine) deliver(ctx context.Context, a Alert) {
+	for _, s := range e.sinks {
+		nctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+		if err := s.Notify(nctx, a); err != nil {
+			log.Printf("[WARN] Alerts: %T could not deliver %s: %v", s, a.ID, err)
+		}
+		cancel()
+	}
+}
+
+// Evaluate checks the metric and offline rules as of now
+func (e *Engine) Evaluate(now time.Time) {
+	for _, a := range e.evaluate(now) {
+		e.emit(a)
+	}
+}
+
+func (e *Engine) evaluate(now time.Time) []Alert {
+	devices := e.devices()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var out []Alert
+	seen := make(map[string]bool)
+	for i := range e.rules {
+		r := &e.rules[i]
+		if r.Kind != KindMetric && r.Kind != KindDeviceOffline {
+			continue
+		}
+		for _, d := range devices {
+			if !r.applies(d.ID, d.Name) {
+				continue
+			}
+			id := r.Name + "/" + d.ID
+			seen[id] = true
+			value, holds, known := e.check(r, d, now)
+			if !known {
+				// No recent data: a pending alert starts over, a firing one stays
+				if st := e.states[id]; st != nil && !st.firing {
+					delete(e.states, id)
+				}
+				continue
+			}
+			if a, ok := e.step(r, id, d, value, holds, now); ok {
+				out = append(out, a)
+			}
+		}
+	}
+
+	// Devices that left the registry take their alerts with them
+	for id, st := range e.states {
+		if seen[id] {
+			continue
+		}
+		delete(e.states, id)
+		if st.firing {
+			a := st.alert
+			a.State = StateResolved
+			a.Time = now
+			a.Message = fmt.Sprintf("%s resolved: %s is no longer registered", a.Rule, a.DeviceID)
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// check returns the rule's value for d and whether its condition holds
+// (for a firing alert: still holds, given the clear level). known is false
+// without recent data.
+func (e *Engine) check(r *Rule, d Device, now time.Time) (value float64, holds, known bool) {
+	if r.Kind == KindDeviceOffline {
+		if d.LastSeen.IsZero() {
+			return 0, false, false
+		}
+		unseen := now.Sub(d.LastSeen)
+		return unseen.Seconds(), unseen >= time.Duration(r.For), true
+	}
+
+	sample := e.store.GetLatest(d.ID)
+	if sample == nil || now.Sub(time.UnixMilli(sample.Timestamp)) > staleAfter {
+		return 0, false, false
+	}
+	value, ok := metricValue(sample, r.Metric)
+	if !ok {
+		return 0, false, false
+	}
+	st := e.states[r.Name+"/"+d.ID]
+	if st != nil && st.firing {
+		// Hysteresis: keep firing until the value is back past the clear level
+		return value, r.past(value, r.clearAt()), true
+	}
+	return value, r.past(value, r.Threshold), true
+}
+
+// step moves one rule and device along pending -> firing -> resolved,
+// returning the alert for a transition
+func (e *Engine) step(r *Rule, id string, d Device, value float64, holds bool, now time.Time) (Alert, bool) {
+	st := e.states[id]
+	if !holds {
+		if st == nil {
+			return Alert{}, false
+		}
+		delete(e.states, id)
+		if !st.firing {
+			return Alert{}, false
+		}
+		return e.alert(r, id, d, value, StateResolved, st.since, now), true
+	}
+
+	if st == nil {
+		st = &state{since: now}
+		if r.Kind == KindDeviceOffline {
+			st.since = d.LastSeen
+		}
+		e.states[id] = st
+	}
+	if st.firing {
+		return Alert{}, false
+	}
+	// Offline rules already waited For in their condition
+	if r.Kind == KindMetric && now.Sub(st.since) < time.Duration(r.For) {
+		return Alert{}, false
+	}
+	st.firing = true
+	st.alert = e.alert(r, id, d, value, StateFiring, st.since, now)
+	return st.alert, true
+}
+
+func (e *Engine) alert(r *Rule, id string, d Device, value float64, to string, since, now time.Time) Alert {
+	name := d.Name
+	if name == "" {
+		name = d.ID
+	}
+	var msg string
+	switch {
+	case r.Kind == KindDeviceOffline && to == StateFiring:
+		msg = fmt.Sprintf("%s is offline: not seen for %s", name, now.Sub(d.LastSeen).Round(time.Second))
+	case r.Kind == KindDeviceOffline:
+		msg = fmt.Sprintf("%s is back online after %s", name, now.Sub(since).Round(time.Second))
+	case to == StateFiring:
+		msg = fmt.Sprintf("%s on %s: %s is %.2f (%s %.2f for %s)", r.Name, name, r.Metric, value, r.Op, r.Threshold, now.Sub(since).Round(time.Second))
+	default:
+		msg = fmt.Sprintf("%s on %s resolved: %s is %.2f", r.Name, name, r.Metric, value)
+	}
+	return Alert{
+		ID: id, Rule: r.Name, Kind: r.Kind, Severity: r.Severity, State: to,
+		DeviceID: d.ID, DeviceName: d.Name, Value: value,
+		Message: msg, Since: since, Time: now,
+	}
+}
+
+// metricValue reads a rule metric from a sample; false if the device does
+// not report it
+func metricValue(s *metrics.MetricsSample, metric string) (float64, bool) {
+	var v float64
+	switch metric {
+	case "cpu_load":
+		v = s.CPULoad
+	case "gpu_load":
+		v = s.GPULoad
+	case "npu_load":
+		v = s.NPULoad
+	case "mem_used":
+		if s.MemTotalMB == 0 {
+			return 0, false
+		}
+		v = float64(s.MemUsedMB) / float64(s.MemTotalMB)
+	case "gpu_mem_used":
+		if s.GPUMemTotalMB == 0 {
+			return 0, false
+		}
+		v = float64(s.GPUMemUsedMB) / float64(s.GPUMemTotalMB)
+	default:
+		return 0, false
+	}
+	// Devices report -1 for loads they cannot measure
+	return v, v >= 0
+}
+
+// JobEvent checks the job rules against a job manager event. It does not
+// block, so it can be the manager's event hook.
+func (e *Engine) JobEvent(ev jobs.Event) {
+	if ev.TaskID != "" {
+		return
+	}
+	failed := ev.State == string(jobs.JobFailed)
+	if !failed && ev.FailedTasks == 0 {
+		return
+	}
+	msg := fmt.Sprintf("job %s failed", ev.JobID)
+	switch {
+	case failed && ev.Error != "":
+		msg += ": " + ev.Error
+	case !failed:
+		msg = fmt.Sprintf("job %s finished with %d failed task(s)", ev.JobID, ev.FailedTasks)
+	}
+	for i := range e.rules {
+		r := &e.rules[i]
+		if r.Kind != KindJobFailed {
+			continue
+		}
+		e.emit(Alert{
+			ID: r.Name + "/" + ev.JobID, Rule: r.Name, Kind: r.Kind, Severity: r.Severity,
+			State: StateFiring, JobID: ev.JobID, Value: float64(ev.FailedTasks),
+			Message: msg, Since: ev.Time, Time: ev.Time,
+		})
+	}
+}
+
+// emit records a, sends it to subscribers and queues it for the sinks
+func (e *Engine) emit(a Alert) {
+	e.mu.Lock()
+	e.recent = append(e.recent, a)
+	if len(e.recent) > recentSize {
+		e.recent = e.recent[len(e.recent)-recentSize:]
+	}
+	for ch := range e.subs {
+		select {
+		case ch <- a:
+		default: // a subscriber that falls behind misses alerts
+		}
+	}
+	e.mu.Unlock()
+
+	log.Printf("[INFO] Alert %s: %s", a.State, a.Message)
+	select {
+	case e.queue <- a:
+	default:
+		log.Printf("[WARN] Alerts: queue full, %s not delivered to sinks", a.ID)
+	}
+}
+
+// Subscribe returns a channel receiving every alert from now on, and a
+// function ending the subscription
+func (e *Engine) Subscribe() (<-chan Alert, func()) {
+	ch := make(chan Alert, 64)
+	e.mu.Lock()
+	e.subs[ch] = struct{}{}
+	e.mu.Unlock()
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			e.mu.Lock()
+			delete(e.subs, ch)
+			e.mu.Unlock()
+		})
+	}
+}
+
+// Firing returns the metric and offline alerts firing now
+func (e *Engine) Firing() []Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var out []Alert
+	for _, st := range e.states {
+		if st.firing {
+			out = append(out, st.alert)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Time.Before(out[j].Time) })
+	return out
+}
+
+// Recent returns up to limit of the newest alerts, oldest first
+func (e *Engine) Recent(limit int) []Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	n := len(e.recent)
+	if limit > 0 && limit < n {
+		n = limit
+	}
+	return append([]Alert(nil), e.recent[len(e.recent)-n:]...)
+}
@@ -0,0 +1,183 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/edgecli/edgecli/internal/jobs"
+	"github.com/edgecli/edgecli/internal/metrics"
+)
+
+func newTestEngine(t *testing.T, rules []Rule, devices *[]Device) (*Engine, *metrics.MetricsStore) {
+	t.Helper()
+	if err := Validate(rules); err != nil {
+		t.Fatal(err)
+	}
+	store := metrics.NewMetricsStore()
+	t.Cleanup(store.Stop)
+	return NewEngine(rules, store, func() []Device { return *devices }), store
+}
+
+func TestMetricRuleForAndHysteresis(t *testing.T) {
+	rules := []Rule{{Name: "cpu", Kind: KindMetric, Metric: "cpu_load", Threshold: 0.9, Clear: ptr(0.8), For: Duration(time.Minute)}}
+	devices := []Device{{ID: "a", Name: "laptop"}}
+	e, store := newTestEngine(t, rules, &devices)
+
+	start := time.Now()
+	at := func(sec int, cpu float64) []Alert {
+		now := start.Add(time.Duration(sec) * time.Second)
+		store.AddSample("a", "laptop", metrics.MetricsSample{Timestamp: now.UnixMilli(), CPULoad: cpu})
+		return e.evaluate(now)
+	}
+
+	if got := at(0, 0.95); len(got) != 0 {
+		t.Fatalf("fired before for elapsed: %+v", got)
+	}
+	if got := at(30, 0.5); len(got) != 0 {
+		t.Fatalf("dip while pending fired: %+v", got)
+	}
+	// The dip reset the pending alert, so a minute from here is needed
+	at(40, 0.95)
+	if got := at(90, 0.95); len(got) != 0 {
+		t.Fatalf("fired 50s into a new breach: %+v", got)
+	}
+	got := at(100, 0.95)
+	if len(got) != 1 || got[0].State != StateFiring || got[0].DeviceID != "a" || got[0].Severity != SeverityWarning {
+		t.Fatalf("want one firing alert, got %+v", got)
+	}
+	if firing := e.Firing(); len(firing) != 1 || firing[0].ID != "cpu/a" {
+		t.Fatalf("Firing() = %+v", firing)
+	}
+	// Between clear and threshold the alert keeps firing
+	if got := at(110, 0.85); len(got) != 0 {
+		t.Fatalf("resolved above clear: %+v", got)
+	}
+	got = at(120, 0.7)
+	if len(got) != 1 || got[0].State != StateResolved || got[0].ID != "cpu/a" {
+		t.Fatalf("want one resolved alert, got %+v", got)
+	}
+	if len(e.Firing()) != 0 {
+		t.Fatal("still firing after resolve")
+	}
+}
+
+func TestOfflineRule(t *testing.T) {
+	rules := []Rule{{Name: "offline", Kind: KindDeviceOffline, Devices: []string{"phone"}}}
+	now := time.Now()
+	devices := []Device{
+		{ID: "a", Name: "laptop", LastSeen: now.Add(-time.Hour)},
+		{ID: "b", Name: "phone", LastSeen: now.Add(-10 * time.Second)},
+	}
+	e, _ := newTestEngine(t, rules, &devices)
+
+	if got := e.evaluate(now); len(got) != 0 {
+		t.Fatalf("fired early or for an unwatched device: %+v", got)
+	}
+	got := e.evaluate(now.Add(25 * time.Second))
+	if len(got) != 1 || got[0].State != StateFiring || got[0].DeviceID != "b" {
+		t.Fatalf("want phone offline, got %+v", got)
+	}
+	devices[1].LastSeen = now.Add(30 * time.Second)
+	got = e.evaluate(now.Add(31 * time.Second))
+	if len(got) != 1 || got[0].State != StateResolved {
+		t.Fatalf("want phone back online, got %+v", got)
+	}
+
+	// A device leaving the registry resolves its alert
+	e.evaluate(now.Add(time.Minute))
+	devices = devices[:1]
+	got = e.evaluate(now.Add(2 * time.Minute))
+	if len(got) != 1 || got[0].State != StateResolved || got[0].DeviceID != "b" {
+		t.Fatalf("want removed device resolved, got %+v", got)
+	}
+}
+
+func TestJobEventAndSinks(t *testing.T) {
+	var devices []Device
+	e, _ := newTestEngine(t, []Rule{{Name: "job", Kind: KindJobFailed, Severity: SeverityCritical}}, &devices)
+
+	posted := make(chan Alert, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var a Alert
+		json.NewDecoder(r.Body).Decode(&a)
+		posted <- a
+	}))
+	defer srv.Close()
+	logPath := filepath.Join(t.TempDir(), "alerts.jsonl")
+	logSink, err := OpenLog(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.sinks = []Sink{logSink, NewWebhook(srv.URL)}
+
+	ch, cancel := e.Subscribe()
+	defer cancel()
+
+	e.JobEvent(jobs.Event{JobID: "j1", State: string(jobs.JobDone)})
+	e.JobEvent(jobs.Event{JobID: "j2", TaskID: "t1", State: string(jobs.TaskFailed)})
+	e.JobEvent(jobs.Event{JobID: "j3", State: string(jobs.JobDone), FailedTasks: 2, Time: time.Now()})
+
+	a := <-ch
+	if a.JobID != "j3" || a.Severity != SeverityCritical || a.State != StateFiring {
+		t.Fatalf("subscriber got %+v", a)
+	}
+	select {
+	case extra := <-ch:
+		t.Fatalf("unexpected alert %+v", extra)
+	default:
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	go e.Run(ctx, time.Hour)
+	select {
+	case got := <-posted:
+		if got.ID != "job/j3" {
+			t.Fatalf("webhook got %+v", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook not called")
+	}
+	data, err := os.ReadFile(logPath)
+	if err != nil || len(data) == 0 {
+		t.Fatalf("alert log: %q, %v", data, err)
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	dir := t.TempDir()
+	rules, err := LoadRules(filepath.Join(dir, "missing.json"))
+	if err != nil || len(rules) != len(DefaultRules()) {
+		t.Fatalf("missing file: %d rules, %v", len(rules), err)
+	}
+
+	path := filepath.Join(dir, "alerts.json")
+	os.WriteFile(path, []byte(`[{"name":"gpu","kind":"metric","metric":"gpu_load","threshold":0.5,"for":"2m"},{"name":"gone","kind":"device_offline"}]`), 0o600)
+	rules, err = LoadRules(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rules[0].Op != ">" || time.Duration(rules[0].For) != 2*time.Minute || rules[0].Severity != SeverityWarning {
+		t.Fatalf("gpu rule = %+v", rules[0])
+	}
+	if time.Duration(rules[1].For) != DefaultOfflineAfter {
+		t.Fatalf("offline rule = %+v", rules[1])
+	}
+
+	for _, bad := range []string{
+		`[{"name":"x","kind":"metric","metric":"disk"}]`,
+		`[{"name":"x","kind":"metric","metric":"cpu_load","threshold":0.5,"clear":0.6}]`,
+		`[{"name":"x","kind":"job_failed"},{"name":"x","kind":"job_failed"}]`,
+	} {
+		os.WriteFile(path, []byte(bad), 0o600)
+		if _, err := LoadRules(path); err == nil {
+			t.Errorf("%s: want error", bad)
+		}
+	}
+}
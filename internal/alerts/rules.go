@@ -0,0 +1,234 @@
+// Package alerts evaluates declarative rules against device metrics,
+// device liveness and job outcomes, and notifies sinks as alerts fire and
+// resolve
+package alerts
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Kind selects what a rule watches
+type Kind string
+
+const (
+	// KindMetric compares one of a device's metrics with a threshold
+	KindMetric Kind = "metric"
+	// KindDeviceOffline fires when a device has not been seen for the rule's For
+	KindDeviceOffline Kind = "device_offline"
+	// KindJobFailed fires when a job fails or finishes with failed tasks
+	KindJobFailed Kind = "job_failed"
+)
+
+// Severities, lowest first
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Metrics a KindMetric rule can watch. Loads are fractions 0-1; the memory
+// metrics are the fraction of the total in use.
+var Metrics = []string{"cpu_load", "gpu_load", "npu_load", "mem_used", "gpu_mem_used"}
+
+// Duration is a time.Duration written as a Go duration string, e.g. "5m"
+type Duration time.Duration
+
+// MarshalJSON writes d as a duration string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON reads a duration string or a number of seconds
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		var secs float64
+		if json.Unmarshal(data, &secs) != nil {
+			return fmt.Errorf("duration must be a string like \"5m\" or seconds, got %s", data)
+		}
+		*d = Duration(secs * float64(time.Second))
+		return nil
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Rule is one alerting rule
+type Rule struct {
+	Name     string   `json:"name"`
+	Kind     Kind     `json:"kind"`
+	Severity string   `json:"severity,omitempty"` // default warning
+	Devices  []string `json:"devices,omitempty"`  // device IDs or names the rule applies to; empty = all
+
+	// KindMetric: fire once Metric Op Threshold has held for For, and
+	// resolve once the value is back past Clear. Clear defaults to
+	// Threshold; a Clear below a ">" threshold gives hysteresis.
+	Metric    string   `json:"metric,omitempty"`
+	Op        string   `json:"op,omitempty"` // ">" (default) or "<"
+	Threshold float64  `json:"threshold,omitempty"`
+	Clear     *float64 `json:"clear,omitempty"`
+
+	// KindMetric: how long the condition must hold. KindDeviceOffline: how
+	// long a device must go unseen (default 30s).
+	For Duration `json:"for,omitempty"`
+}
+
+// DefaultOfflineAfter is how long a device must go unseen before a
+// device_offline rule without For fires
+const DefaultOfflineAfter = 30 * time.Second
+
+func ptr(v float64) *float64 { return &v }
+
+// DefaultRules warns when a device's CPU stays above 90% for five minutes,
+// when a device goes offline and when a job fails
+func DefaultRules() []Rule {
+	return []Rule{
+		{
+			Name:      "cpu_high",
+			Kind:      KindMetric,
+			Severity:  SeverityWarning,
+			Metric:    "cpu_load",
+			Op:        ">",
+			Threshold: 0.9,
+			Clear:     ptr(0.8),
+			For:       Duration(5 * time.Minute),
+		},
+		{
+			Name:     "device_offline",
+			Kind:     KindDeviceOffline,
+			Severity: SeverityCritical,
+			For:      Duration(DefaultOfflineAfter),
+		},
+		{
+			Name:     "job_failed",
+			Kind:     KindJobFailed,
+			Severity: SeverityWarning,
+		},
+	}
+}
+
+// DefaultRulesPath returns ~/.edgemesh/alerts.json
+func DefaultRulesPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".edgemesh", "alerts.json"), nil
+}
+
+// LoadRules reads a JSON array of rules; a missing file yields the default rules
+func LoadRules(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return DefaultRules(), nil
+		}
+		return nil, err
+	}
+	var rules []Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if err := Validate(rules); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// Validate checks rules are complete and their names unique, filling in
+// defaults
+func Validate(rules []Rule) error {
+	seen := make(map[string]bool)
+	for i := range rules {
+		r := &rules[i]
+		if r.Name == "" {
+			return fmt.Errorf("rule %d has no name", i)
+		}
+		if seen[r.Name] {
+			return fmt.Errorf("rule %q is defined twice", r.Name)
+		}
+		seen[r.Name] = true
+
+		switch r.Severity {
+		case "":
+			r.Severity = SeverityWarning
+		case SeverityInfo, SeverityWarning, SeverityCritical:
+		default:
+			return fmt.Errorf("rule %q: unknown severity %q", r.Name, r.Severity)
+		}
+		if r.For < 0 {
+			return fmt.Errorf("rule %q: negative for", r.Name)
+		}
+
+		switch r.Kind {
+		case KindMetric:
+			if !knownMetric(r.Metric) {
+				return fmt.Errorf("rule %q: unknown metric %q (want one of %v)", r.Name, r.Metric, Metrics)
+			}
+			switch r.Op {
+			case "":
+				r.Op = ">"
+			case ">", "<":
+			default:
+				return fmt.Errorf("rule %q: op must be \">\" or \"<\"", r.Name)
+			}
+			if r.Clear != nil && r.past(*r.Clear, r.Threshold) {
+				return fmt.Errorf("rule %q: clear %v is on the firing side of threshold %v", r.Name, *r.Clear, r.Threshold)
+			}
+		case KindDeviceOffline:
+			if r.For == 0 {
+				r.For = Duration(DefaultOfflineAfter)
+			}
+		case KindJobFailed:
+		default:
+			return fmt.Errorf("rule %q: unknown kind %q", r.Name, r.Kind)
+		}
+	}
+	return nil
+}
+
+func knownMetric(name string) bool {
+	for _, m := range Metrics {
+		if m == name {
+			return true
+		}
+	}
+	return false
+}
+
+// past reports whether a is beyond b in the rule's firing direction
+func (r *Rule) past(a, b float64) bool {
+	if r.Op == "<" {
+		return a < b
+	}
+	return a > b
+}
+
+// clearAt is the value a firing metric rule must get back to
+func (r *Rule) clearAt() float64 {
+	if r.Clear != nil {
+		return *r.Clear
+	}
+	return r.Threshold
+}
+
+// applies reports whether the rule covers a device
+func (r *Rule) applies(deviceID, deviceName string) bool {
+	if len(r.Devices) == 0 {
+		return true
+	}
+	for _, d := range r.Devices {
+		if d == deviceID || (deviceName != "" && d == deviceName) {
+			return true
+		}
+	}
+	return false
+}
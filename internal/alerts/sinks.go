@@ -0,0 +1,105 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Sink is told about every alert as it fires or resolves
+type Sink interface {
+	Notify(ctx context.Context, a Alert) error
+}
+
+// SinkFunc adapts a function to a Sink
+type SinkFunc func(ctx context.Context, a Alert) error
+
+// Notify calls f
+func (f SinkFunc) Notify(ctx context.Context, a Alert) error {
+	return f(ctx, a)
+}
+
+// LogSink appends alerts to a file, one JSON object per line
+type LogSink struct {
+	mu   sync.Mutex
+	path string
+}
+
+// DefaultLogPath returns ~/.edgemesh/alerts.jsonl
+func DefaultLogPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".edgemesh", "alerts.jsonl"), nil
+}
+
+// OpenLog returns a sink appending to path, creating its directory
+func OpenLog(path string) (*LogSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("create alert log dir: %w", err)
+	}
+	return &LogSink{path: path}, nil
+}
+
+// Path returns the file the sink appends to
+func (l *LogSink) Path() string {
+	return l.path
+}
+
+// Notify appends a
+func (l *LogSink) Notify(ctx context.Context, a Alert) error {
+	line, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Webhook posts each alert as JSON to a URL
+type Webhook struct {
+	URL    string
+	client *http.Client
+}
+
+// NewWebhook returns a sink posting to url
+func NewWebhook(url string) *Webhook {
+	return &Webhook{URL: url, client: &http.Client{}}
+}
+
+// Notify posts a
+func (w *Webhook) Notify(ctx context.Context, a Alert) error {
+	body, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 )
 
@@ -16,9 +17,42 @@ type CommandSpec struct {
 
 // AllowedCommands is the set of commands permitted for remote execution
 var AllowedCommands = map[string]bool{
-	"pwd": true,
-	"ls":  true,
-	"cat": true,
+	"pwd":      true,
+	"ls":       true,
+	"cat":      true,
+	"df":       true,
+	"uptime":   true,
+	"uname":    true,
+	"hostname": true,
+	"whoami":   true,
+}
+
+// statusFlags lists the flags each status command accepts. These commands
+// run as given, but only with read-only flags: no positional arguments, so
+// e.g. "hostname <name>" cannot rename the device.
+var statusFlags = map[string][]string{
+	"df":       {"-h", "-H", "-k", "-i", "-T", "-l", "-a"},
+	"uptime":   {"-p", "-s"},
+	"uname":    {"-a", "-s", "-n", "-r", "-v", "-m", "-p", "-i", "-o"},
+	"hostname": {"-s", "-f", "-d", "-i", "-I", "-A"},
+	"whoami":   {},
+}
+
+// passThrough commands were added with Allow and run with their arguments
+// unchanged
+var passThrough = map[string]bool{}
+
+// Allow adds commands that run as given, e.g. from ALLOWED_COMMANDS. It
+// must be called before commands are served.
+func Allow(commands ...string) {
+	for _, c := range commands {
+		c = strings.TrimSpace(c)
+		if c == "" || strings.ContainsAny(c, `/\`) {
+			continue
+		}
+		AllowedCommands[c] = true
+		passThrough[c] = true
+	}
 }
 
 // ValidateCommand checks if a command is allowed and returns the OS-specific
@@ -29,6 +63,18 @@ func ValidateCommand(command string, args []string) (*CommandSpec, error) {
 		return nil, fmt.Errorf("command %q is not in the allowlist", command)
 	}
 
+	if passThrough[command] {
+		return &CommandSpec{Executable: command, Args: args}, nil
+	}
+	if flags, ok := statusFlags[command]; ok {
+		for _, a := range args {
+			if !slices.Contains(flags, a) {
+				return nil, fmt.Errorf("%s does not accept argument %q", command, a)
+			}
+		}
+		return &CommandSpec{Executable: command, Args: args}, nil
+	}
+
 	switch command {
 	case "pwd":
 		return mapPwd()
@@ -0,0 +1,44 @@
+package allowlist
+
+import "testing"
+
+func TestStatusCommandsTakeOnlyReadOnlyFlags(t *testing.T) {
+	for _, tc := range []struct {
+		command string
+		args    []string
+		ok      bool
+	}{
+		{"hostname", nil, true},
+		{"hostname", []string{"-s"}, true},
+		{"hostname", []string{"foo"}, false},
+		{"hostname", []string{"-F", "/tmp/name"}, false},
+		{"uname", []string{"-a"}, true},
+		{"uname", []string{"-a", "extra"}, false},
+		{"df", []string{"-h"}, true},
+		{"df", []string{"/"}, false},
+		{"whoami", nil, true},
+		{"whoami", []string{"--help"}, false},
+		{"uptime", []string{"-p"}, true},
+	} {
+		_, err := ValidateCommand(tc.command, tc.args)
+		if (err == nil) != tc.ok {
+			t.Errorf("%s %v: err = %v, want ok=%v", tc.command, tc.args, err, tc.ok)
+		}
+	}
+}
+
+func TestAllowedCommandsTakeAnyArguments(t *testing.T) {
+	Allow("nvidia-smi", "bad/path")
+	defer func() {
+		delete(AllowedCommands, "nvidia-smi")
+		delete(passThrough, "nvidia-smi")
+	}()
+
+	spec, err := ValidateCommand("nvidia-smi", []string{"--query-gpu=name"})
+	if err != nil || spec.Executable != "nvidia-smi" || len(spec.Args) != 1 {
+		t.Fatalf("spec=%+v err=%v", spec, err)
+	}
+	if _, err := ValidateCommand("bad/path", nil); err == nil {
+		t.Error("commands with a path separator must not be allowed")
+	}
+}
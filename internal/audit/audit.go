@@ -0,0 +1,113 @@
+// Package audit keeps an append-only trail of security-relevant actions,
+// one JSON object per line
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Event is one audited action
+type Event struct {
+	Time      time.Time         `json:"time"`
+	Action    string            `json:"action"`              // e.g. "stream.start"
+	Principal string            `json:"principal,omitempty"` // who acted, as their session names them
+	Device    string            `json:"device,omitempty"`    // the device the action ran on
+	Target    string            `json:"target,omitempty"`    // what was acted on, e.g. a stream ID
+	Detail    map[string]string `json:"detail,omitempty"`
+}
+
+// Log appends events to a file. A nil *Log discards them.
+type Log struct {
+	mu   sync.Mutex
+	path string
+}
+
+// DefaultPath returns ~/.edgemesh/audit.jsonl
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".edgemesh", "audit.jsonl"), nil
+}
+
+// Open returns a log appending to path, creating its directory
+func Open(path string) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("create audit dir: %w", err)
+	}
+	return &Log{path: path}, nil
+}
+
+// Path returns the file the log appends to
+func (l *Log) Path() string {
+	if l == nil {
+		return ""
+	}
+	return l.path
+}
+
+// Record appends ev, stamping it with the current time if it has none
+func (l *Log) Record(ev Event) error {
+	if l == nil {
+		return nil
+	}
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	line, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Recent returns up to limit of the newest events, oldest first. Lines that
+// do not parse are skipped.
+func (l *Log) Recent(limit int) ([]Event, error) {
+	if l == nil {
+		return nil, nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, err := os.Open(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var ev Event
+		if json.Unmarshal(scanner.Bytes(), &ev) != nil {
+			continue
+		}
+		events = append(events, ev)
+		if limit > 0 && len(events) > limit {
+			events = events[1:]
+		}
+	}
+	return events, scanner.Err()
+}
@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecordAndRecent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "audit.jsonl")
+	l, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if events, err := l.Recent(10); err != nil || len(events) != 0 {
+		t.Fatalf("empty log: %v, %v", events, err)
+	}
+
+	for _, target := range []string{"a", "b", "c"} {
+		if err := l.Record(Event{Action: "stream.start", Target: target}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A torn line is skipped rather than failing the read
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	f.WriteString("{\"action\":\n")
+	f.Close()
+	if err := l.Record(Event{Action: "stream.stop", Target: "d", Detail: map[string]string{"reason": "idle"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := l.Recent(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].Target != "c" || events[1].Target != "d" || events[1].Detail["reason"] != "idle" {
+		t.Fatalf("recent events %+v", events)
+	}
+	if events[1].Time.IsZero() {
+		t.Fatal("event not timestamped")
+	}
+}
+
+func TestNilLogDiscards(t *testing.T) {
+	var l *Log
+	if err := l.Record(Event{Action: "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if events, err := l.Recent(1); err != nil || events != nil {
+		t.Fatalf("nil log returned %v, %v", events, err)
+	}
+}
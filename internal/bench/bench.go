@@ -0,0 +1,216 @@
+// Package bench measures a chat provider's prefill and decode throughput
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/edgecli/edgecli/internal/llm"
+)
+
+// ErrNoModel is returned for providers that do not run a model, such as echo
+var ErrNoModel = errors.New("provider does not run a model")
+
+// Ways a Result was measured
+const (
+	// MethodProvider uses the token counts and timings the provider reports
+	MethodProvider = "provider"
+	// MethodWallClock fits throughput to the wall-clock time of each prompt,
+	// with tokens estimated at 4 characters each
+	MethodWallClock = "wall-clock"
+)
+
+// Result is the measured throughput of one model
+type Result struct {
+	Provider     string        `json:"provider"`
+	Model        string        `json:"model"`
+	PrefillTPS   float64       `json:"prefill_toks_per_s"`
+	DecodeTPS    float64       `json:"decode_toks_per_s"`
+	PromptTokens int           `json:"prompt_tokens"`
+	OutputTokens int           `json:"output_tokens"`
+	Method       string        `json:"method"`
+	MeasuredAt   time.Time     `json:"measured_at"`
+	Duration     time.Duration `json:"duration_ns"`
+}
+
+// Prompt is one prompt of the standard set
+type Prompt struct {
+	Name string
+	Text string
+}
+
+// passage is the reading for the prompts that need context
+const passage = `Edge computing moves work from distant data centers to the devices where data is produced. ` +
+	`A laptop, a phone and a single-board computer on the same network can share tasks: the laptop renders, ` +
+	`the phone transcribes, and the small board watches a sensor. Scheduling across them means knowing what ` +
+	`each device is good at, how busy it is, and how long it takes to move data between them. Language models ` +
+	`make this harder because their speed depends on two different phases. Reading the prompt, called prefill, ` +
+	`is parallel and fast on accelerators. Writing the answer, called decode, produces one token at a time and ` +
+	`is limited by memory bandwidth. A device that prefills quickly may still decode slowly, so a scheduler ` +
+	`that knows both numbers can send long documents to one device and long answers to another. `
+
+// StandardPrompts are run, in order, by every benchmark. They mix short and
+// long prompts with short and long answers so the two phases can be told
+// apart even from wall-clock times.
+var StandardPrompts = []Prompt{
+	{"short-answer", "What is the capital of France? Answer in one sentence."},
+	{"summarize", passage + "\n\nSummarize the text above in three sentences."},
+	{"long-context", longContext() + "\n\nHow many numbered sections are above? Answer with the number only."},
+	{"generate", "Write a 200-word story about a lighthouse keeper who finds a message in a bottle."},
+}
+
+// longContext repeats the passage as numbered sections, about 1500 tokens
+func longContext() string {
+	var b strings.Builder
+	for i := 1; i <= 8; i++ {
+		fmt.Fprintf(&b, "Section %d. %s\n", i, passage)
+	}
+	return b.String()
+}
+
+// warmup loads the model before anything is timed
+const warmup = "Reply with OK."
+
+// Options tunes a benchmark run
+type Options struct {
+	Model string // recorded in the result; the provider decides what runs
+	Runs  int    // passes over StandardPrompts; 0 means 1
+}
+
+// sample is one timed prompt
+type sample struct {
+	promptTokens, outputTokens     int
+	promptDuration, outputDuration time.Duration
+	wall                           time.Duration
+}
+
+// Run benchmarks chat with StandardPrompts. Providers implementing
+// llm.TimedChatProvider are measured from their own timings; others from
+// wall-clock times.
+func Run(ctx context.Context, chat llm.ChatProvider, opts Options) (*Result, error) {
+	switch chat.Name() {
+	case "echo", "mock":
+		return nil, fmt.Errorf("%w: %s", ErrNoModel, chat.Name())
+	}
+	runs := opts.Runs
+	if runs <= 0 {
+		runs = 1
+	}
+
+	start := time.Now()
+	if _, err := chat.Chat(ctx, []llm.ChatMessage{{Role: "user", Content: warmup}}); err != nil {
+		return nil, fmt.Errorf("warmup: %w", err)
+	}
+
+	timed, hasTiming := chat.(llm.TimedChatProvider)
+	var samples []sample
+	for r := 0; r < runs; r++ {
+		for _, p := range StandardPrompts {
+			messages := []llm.ChatMessage{{Role: "user", Content: p.Text}}
+			t0 := time.Now()
+			var s sample
+			if hasTiming {
+				_, timing, err := timed.ChatTimed(ctx, messages)
+				if err != nil {
+					return nil, fmt.Errorf("prompt %s: %w", p.Name, err)
+				}
+				s = sample{
+					promptTokens:   timing.PromptTokens,
+					outputTokens:   timing.OutputTokens,
+					promptDuration: timing.PromptDuration,
+					outputDuration: timing.OutputDuration,
+				}
+			} else {
+				reply, err := chat.Chat(ctx, messages)
+				if err != nil {
+					return nil, fmt.Errorf("prompt %s: %w", p.Name, err)
+				}
+				s = sample{promptTokens: len(p.Text) / 4, outputTokens: len(reply) / 4}
+			}
+			s.wall = time.Since(t0)
+			samples = append(samples, s)
+		}
+	}
+
+	result := &Result{
+		Provider:   chat.Name(),
+		Model:      opts.Model,
+		MeasuredAt: time.Now(),
+		Duration:   time.Since(start),
+	}
+	for _, s := range samples {
+		result.PromptTokens += s.promptTokens
+		result.OutputTokens += s.outputTokens
+	}
+
+	if hasTiming {
+		result.Method = MethodProvider
+		var promptDur, outputDur time.Duration
+		for _, s := range samples {
+			promptDur += s.promptDuration
+			outputDur += s.outputDuration
+		}
+		if promptDur > 0 {
+			result.PrefillTPS = float64(result.PromptTokens) / promptDur.Seconds()
+		}
+		if outputDur > 0 {
+			result.DecodeTPS = float64(result.OutputTokens) / outputDur.Seconds()
+		}
+	} else {
+		result.Method = MethodWallClock
+		result.PrefillTPS, result.DecodeTPS = fitThroughput(samples)
+	}
+
+	if result.PrefillTPS <= 0 || result.DecodeTPS <= 0 {
+		return nil, fmt.Errorf("could not separate prefill from decode (prefill=%.1f decode=%.1f tok/s)", result.PrefillTPS, result.DecodeTPS)
+	}
+	return result, nil
+}
+
+// fitThroughput fits wall = prompt_tokens/prefill + output_tokens/decode
+// by least squares, keeping both per-token costs non-negative
+func fitThroughput(samples []sample) (prefill, decode float64) {
+	var spp, spo, soo, spt, sot float64
+	for _, s := range samples {
+		p, o, t := float64(s.promptTokens), float64(s.outputTokens), s.wall.Seconds()
+		spp += p * p
+		spo += p * o
+		soo += o * o
+		spt += p * t
+		sot += o * t
+	}
+
+	// a and b are seconds per prompt and output token
+	var a, b float64
+	if det := spp*soo - spo*spo; det > 0 {
+		a = (spt*soo - spo*sot) / det
+		b = (spp*sot - spo*spt) / det
+	}
+	switch {
+	case a <= 0 && soo > 0:
+		a, b = 0, sot/soo
+	case b <= 0 && spp > 0:
+		a, b = spt/spp, 0
+	}
+
+	if a > 0 {
+		prefill = 1 / a
+	}
+	if b > 0 {
+		decode = 1 / b
+	}
+	return prefill, decode
+}
+
+// RunModel benchmarks model with the provider cfg names
+func RunModel(ctx context.Context, cfg llm.ChatConfig, model string, runs int) (*Result, error) {
+	cfg.Model = model
+	chat, err := llm.NewChat(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return Run(ctx, chat, Options{Model: model, Runs: runs})
+}
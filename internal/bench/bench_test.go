@@ -0,0 +1,125 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/edgecli/edgecli/internal/llm"
+)
+
+// timedChat reports exact timings for 100 tok/s prefill and 10 tok/s decode
+type timedChat struct{ calls int }
+
+func (c *timedChat) Name() string { return "ollama" }
+func (c *timedChat) Health(context.Context) (*llm.HealthResult, error) {
+	return &llm.HealthResult{}, nil
+}
+func (c *timedChat) Chat(context.Context, []llm.ChatMessage) (string, error) {
+	c.calls++
+	return "OK", nil
+}
+func (c *timedChat) ChatTimed(_ context.Context, msgs []llm.ChatMessage) (string, *llm.ChatTiming, error) {
+	c.calls++
+	return "reply", &llm.ChatTiming{
+		PromptTokens:   200,
+		PromptDuration: 2 * time.Second,
+		OutputTokens:   50,
+		OutputDuration: 5 * time.Second,
+	}, nil
+}
+
+func TestRunUsesProviderTiming(t *testing.T) {
+	chat := &timedChat{}
+	r, err := Run(context.Background(), chat, Options{Model: "m", Runs: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Method != MethodProvider || r.PrefillTPS != 100 || r.DecodeTPS != 10 {
+		t.Fatalf("got %+v", r)
+	}
+	if want := 1 + 2*len(StandardPrompts); chat.calls != want {
+		t.Fatalf("calls = %d, want %d", chat.calls, want)
+	}
+	if r.PromptTokens != 200*2*len(StandardPrompts) || r.Provider != "ollama" || r.Model != "m" {
+		t.Fatalf("got %+v", r)
+	}
+}
+
+func TestRunRefusesEcho(t *testing.T) {
+	if _, err := Run(context.Background(), llm.NewEchoChat(), Options{}); !errors.Is(err, ErrNoModel) {
+		t.Fatalf("err = %v, want ErrNoModel", err)
+	}
+}
+
+func TestFitThroughput(t *testing.T) {
+	// 1000 tok/s prefill and 20 tok/s decode
+	wall := func(p, o int) time.Duration {
+		return time.Duration((float64(p)/1000 + float64(o)/20) * float64(time.Second))
+	}
+	samples := []sample{
+		{promptTokens: 10, outputTokens: 10, wall: wall(10, 10)},
+		{promptTokens: 2000, outputTokens: 5, wall: wall(2000, 5)},
+		{promptTokens: 300, outputTokens: 60, wall: wall(300, 60)},
+		{promptTokens: 20, outputTokens: 250, wall: wall(20, 250)},
+	}
+	prefill, decode := fitThroughput(samples)
+	if math.Abs(prefill-1000) > 1 || math.Abs(decode-20) > 0.01 {
+		t.Fatalf("prefill=%.2f decode=%.2f", prefill, decode)
+	}
+
+	// Prompt size not mattering at all cannot give a negative prefill cost
+	flat := []sample{
+		{promptTokens: 10, outputTokens: 100, wall: 5 * time.Second},
+		{promptTokens: 1000, outputTokens: 100, wall: 4 * time.Second},
+	}
+	if prefill, decode := fitThroughput(flat); prefill != 0 || decode <= 0 {
+		t.Fatalf("prefill=%.2f decode=%.2f", prefill, decode)
+	}
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bench.json")
+	s, err := OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.Get("ollama", "m"); ok {
+		t.Fatal("empty store returned a result")
+	}
+
+	if err := s.Put(&Result{Provider: "ollama", Model: "m", PrefillTPS: 1, DecodeTPS: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Put(&Result{Provider: "ollama", Model: "m", PrefillTPS: 3, DecodeTPS: 4}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A second store, like edgecli next to the server, sees the same file
+	other, err := OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Put(&Result{Provider: "openai", Model: "x", PrefillTPS: 5, DecodeTPS: 6}); err != nil {
+		t.Fatal(err)
+	}
+	// Make sure the mtime differs on filesystems with coarse timestamps
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatal(err)
+	}
+
+	if r, ok := s.Get("ollama", "m"); !ok || r.PrefillTPS != 3 {
+		t.Fatalf("got %+v, %v", r, ok)
+	}
+	if r, ok := s.Get("openai", "x"); !ok || r.DecodeTPS != 6 {
+		t.Fatalf("store did not reload: %+v, %v", r, ok)
+	}
+	if all := s.All(); len(all) != 2 || all[0].Provider != "ollama" {
+		t.Fatalf("All = %+v", all)
+	}
+}
@@ -0,0 +1,134 @@
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultPath returns ~/.edgemesh/bench.json
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".edgemesh", "bench.json"), nil
+}
+
+// Store keeps the latest Result per provider and model in a JSON file.
+// It re-reads the file when another process, such as "edgecli bench",
+// has written it.
+type Store struct {
+	mu      sync.Mutex
+	path    string
+	modTime time.Time
+	results map[string]*Result
+}
+
+// OpenStore loads the results at path; a missing file is an empty store
+func OpenStore(path string) (*Store, error) {
+	s := &Store{path: path, results: make(map[string]*Result)}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.reloadLocked(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func storeKey(provider, model string) string {
+	return provider + "/" + model
+}
+
+// reloadLocked reads the file if it changed since the last read
+func (s *Store) reloadLocked() error {
+	info, err := os.Stat(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.ModTime().Equal(s.modTime) {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return err
+	}
+	var list []*Result
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("parse %s: %w", s.path, err)
+	}
+	s.results = make(map[string]*Result, len(list))
+	for _, r := range list {
+		s.results[storeKey(r.Provider, r.Model)] = r
+	}
+	s.modTime = info.ModTime()
+	return nil
+}
+
+// Put records r, replacing the previous result for its provider and model
+func (s *Store) Put(r *Result) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.reloadLocked(); err != nil {
+		return err
+	}
+	s.results[storeKey(r.Provider, r.Model)] = r
+
+	data, err := json.MarshalIndent(s.allLocked(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("create bench dir: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return err
+	}
+	if info, err := os.Stat(s.path); err == nil {
+		s.modTime = info.ModTime()
+	}
+	return nil
+}
+
+// Get returns the latest result for provider and model
+func (s *Store) Get(provider, model string) (*Result, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_ = s.reloadLocked() // keep what we have if the file is unreadable
+	r, ok := s.results[storeKey(provider, model)]
+	return r, ok
+}
+
+// All returns every stored result, ordered by provider and model
+func (s *Store) All() []*Result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_ = s.reloadLocked()
+	return s.allLocked()
+}
+
+func (s *Store) allLocked() []*Result {
+	list := make([]*Result, 0, len(s.results))
+	for _, r := range s.results {
+		list = append(list, r)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return storeKey(list[i].Provider, list[i].Model) < storeKey(list[j].Provider, list[j].Model)
+	})
+	return list
+}
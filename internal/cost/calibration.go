@@ -0,0 +1,346 @@
+package cost
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/edgecli/edgecli/internal/tasks"
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+const (
+	// CalibrationAlpha weights the newest sample in the moving averages
+	CalibrationAlpha = 0.3
+	// CalibrationSaveInterval is how often learned statistics are written to disk
+	CalibrationSaveInterval = time.Minute
+)
+
+// uncalibratedKinds are not learned: their run time depends on the input
+// far more than on the device
+var uncalibratedKinds = map[string]bool{"SHELL": true}
+
+// Confidence of an estimate, by how many completed tasks it was learned from
+const (
+	ConfidenceDefault = "default" // no history, built-in cost model
+	ConfidenceLow     = "low"     // 1-2 samples
+	ConfidenceMedium  = "medium"  // 3-9 samples
+	ConfidenceHigh    = "high"    // 10 or more samples
+)
+
+// confidenceRank orders confidences from least to most certain
+var confidenceRank = map[string]int{
+	ConfidenceDefault: 0,
+	ConfidenceLow:     1,
+	ConfidenceMedium:  2,
+	ConfidenceHigh:    3,
+}
+
+// ConfidenceFor returns the confidence of an estimate learned from n samples
+func ConfidenceFor(n int) string {
+	switch {
+	case n >= 10:
+		return ConfidenceHigh
+	case n >= 3:
+		return ConfidenceMedium
+	case n >= 1:
+		return ConfidenceLow
+	default:
+		return ConfidenceDefault
+	}
+}
+
+// Sample is one completed task
+type Sample struct {
+	DeviceID   string
+	Kind       string
+	DurationMs float64 // time the device spent running the task
+	Tokens     int     // prompt plus output tokens of LLM_GENERATE; 0 otherwise
+	Items      int     // items of a MAP shard; 0 otherwise
+}
+
+// KindStats is what has been learned about one kind on one device
+type KindStats struct {
+	DeviceID     string    `json:"device_id"`
+	Kind         string    `json:"kind"`
+	Samples      int       `json:"samples"`
+	LatencyMs    float64   `json:"latency_ms"`              // moving average of task time
+	TokenSamples int       `json:"token_samples,omitempty"` // samples with token counts
+	MsPerToken   float64   `json:"ms_per_token,omitempty"`  // moving average, LLM_GENERATE only
+	ItemSamples  int       `json:"item_samples,omitempty"`  // samples with item counts
+	MsPerItem    float64   `json:"ms_per_item,omitempty"`   // moving average, MAP only
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Calibration learns per-device, per-kind task latencies from completed
+// tasks and persists them, so estimates improve on the built-in defaults.
+type Calibration struct {
+	mu    sync.RWMutex
+	path  string // "" = kept in memory only
+	stats map[string]*KindStats
+	dirty bool // changed since the last Save
+}
+
+// DefaultCalibrationPath returns ~/.edgemesh/calibration.json
+func DefaultCalibrationPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".edgemesh", "calibration.json"), nil
+}
+
+// NewCalibration returns an empty calibration kept in memory
+func NewCalibration() *Calibration {
+	return &Calibration{stats: make(map[string]*KindStats)}
+}
+
+// OpenCalibration loads the statistics at path, which Save writes back.
+// A missing file starts empty.
+func OpenCalibration(path string) (*Calibration, error) {
+	c := NewCalibration()
+	c.path = path
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var list []*KindStats
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	for _, st := range list {
+		c.stats[calibrationKey(st.DeviceID, st.Kind)] = st
+	}
+	return c, nil
+}
+
+func calibrationKey(deviceID, kind string) string {
+	return deviceID + "/" + kind
+}
+
+// Record folds a completed task into its device and kind's statistics.
+// They reach disk on the next Save.
+func (c *Calibration) Record(s Sample) {
+	if s.DeviceID == "" || s.Kind == "" || s.DurationMs <= 0 || uncalibratedKinds[s.Kind] {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := calibrationKey(s.DeviceID, s.Kind)
+	st, ok := c.stats[key]
+	if !ok {
+		st = &KindStats{DeviceID: s.DeviceID, Kind: s.Kind}
+		c.stats[key] = st
+	}
+	st.LatencyMs = ewma(st.LatencyMs, s.DurationMs, st.Samples)
+	st.Samples++
+	if s.Tokens > 0 {
+		st.MsPerToken = ewma(st.MsPerToken, s.DurationMs/float64(s.Tokens), st.TokenSamples)
+		st.TokenSamples++
+	}
+	if s.Items > 0 {
+		st.MsPerItem = ewma(st.MsPerItem, s.DurationMs/float64(s.Items), st.ItemSamples)
+		st.ItemSamples++
+	}
+	st.UpdatedAt = time.Now()
+	c.dirty = true
+}
+
+// ewma adds x to an average of n earlier samples; the first sample is taken as is
+func ewma(avg, x float64, n int) float64 {
+	if n == 0 {
+		return x
+	}
+	return CalibrationAlpha*x + (1-CalibrationAlpha)*avg
+}
+
+// Stats returns the statistics of kind on a device
+func (c *Calibration) Stats(deviceID, kind string) (KindStats, bool) {
+	if c == nil {
+		return KindStats{}, false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	st, ok := c.stats[calibrationKey(deviceID, kind)]
+	if !ok {
+		return KindStats{}, false
+	}
+	return *st, true
+}
+
+// All returns every device and kind's statistics, ordered by device and kind
+func (c *Calibration) All() []KindStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	list := make([]KindStats, 0, len(c.stats))
+	for _, st := range c.stats {
+		list = append(list, *st)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return calibrationKey(list[i].DeviceID, list[i].Kind) < calibrationKey(list[j].DeviceID, list[j].Kind)
+	})
+	return list
+}
+
+// Save writes the statistics to their file, if there is one and they changed
+func (c *Calibration) Save() error {
+	c.mu.Lock()
+	if c.path == "" || !c.dirty {
+		c.mu.Unlock()
+		return nil
+	}
+	c.dirty = false
+	c.mu.Unlock()
+
+	if err := c.write(c.All()); err != nil {
+		c.mu.Lock()
+		c.dirty = true
+		c.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (c *Calibration) write(list []KindStats) error {
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return fmt.Errorf("create calibration dir: %w", err)
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
+
+// SaveLoop saves the statistics every interval until ctx is done, then once more
+func (c *Calibration) SaveLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if err := c.Save(); err != nil {
+				log.Printf("[WARN] Calibration: save failed: %v", err)
+			}
+			return
+		case <-ticker.C:
+			if err := c.Save(); err != nil {
+				log.Printf("[WARN] Calibration: save failed: %v", err)
+			}
+		}
+	}
+}
+
+// EstimateTokens returns the tokens an LLM_GENERATE task is expected to
+// process, from its spec or, failing that, its prompt; 0 for other kinds
+func EstimateTokens(task *pb.TaskSpec) int {
+	if task.Kind != "LLM_GENERATE" {
+		return 0
+	}
+	if task.PromptTokens > 0 || task.MaxOutputTokens > 0 {
+		return int(task.PromptTokens + task.MaxOutputTokens)
+	}
+	return len(task.Input) / 4
+}
+
+// SampleTokens returns the tokens a completed LLM_GENERATE task processed,
+// estimated at 4 characters each; 0 for other kinds
+func SampleTokens(kind, input, output string) int {
+	if kind != "LLM_GENERATE" {
+		return 0
+	}
+	return max((len(input)+len(output))/4, 1)
+}
+
+// SampleKind returns the kind a task is calibrated as. MAP shards are kept
+// apart by the kind they run per item, e.g. "MAP:EMBED".
+func SampleKind(kind, input string) string {
+	if kind != "MAP" {
+		return kind
+	}
+	var shard tasks.MapShard
+	if json.Unmarshal([]byte(input), &shard) != nil || shard.Kind == "" {
+		return kind
+	}
+	return kind + ":" + shard.Kind
+}
+
+// SampleItems returns the items of a MAP shard; 0 for other kinds
+func SampleItems(kind, input string) int {
+	if kind != "MAP" {
+		return 0
+	}
+	var shard tasks.MapShard
+	if json.Unmarshal([]byte(input), &shard) != nil {
+		return 0
+	}
+	return len(shard.Items)
+}
+
+// SetCalibration makes the estimator use learned statistics in place of
+// the built-in cost models
+func (e *Estimator) SetCalibration(c *Calibration) {
+	e.calibration = c
+}
+
+// calibrate replaces step's latency with the learned one, if any. MAP
+// shards are scaled by their items and LLM_GENERATE by its tokens; a MAP
+// shard without per-item history keeps the built-in estimate.
+func (e *Estimator) calibrate(step *pb.StepCostEstimate, task *pb.TaskSpec, device *pb.DeviceInfo) {
+	step.Confidence = ConfidenceDefault
+	if uncalibratedKinds[task.Kind] {
+		return
+	}
+	st, ok := e.calibration.Stats(device.DeviceId, SampleKind(task.Kind, task.Input))
+	if !ok || st.Samples == 0 {
+		return
+	}
+
+	var note string
+	if task.Kind == "MAP" {
+		items := SampleItems(task.Kind, task.Input)
+		if items == 0 || st.ItemSamples == 0 {
+			return
+		}
+		step.PredictedMs = st.MsPerItem * float64(items)
+		step.Samples = int32(st.ItemSamples)
+		note = fmt.Sprintf("calibrated %.1fms/item from %d task(s)", st.MsPerItem, st.ItemSamples)
+	} else if tokens := EstimateTokens(task); tokens > 0 && st.TokenSamples > 0 {
+		step.PredictedMs = st.MsPerToken * float64(tokens)
+		step.Samples = int32(st.TokenSamples)
+		note = fmt.Sprintf("calibrated %.1fms/token from %d task(s)", st.MsPerToken, st.TokenSamples)
+	} else {
+		step.PredictedMs = st.LatencyMs
+		step.Samples = int32(st.Samples)
+		note = fmt.Sprintf("calibrated from %d task(s)", st.Samples)
+	}
+	step.Confidence = ConfidenceFor(int(step.Samples))
+	// The built-in model's notes describe the estimate that was replaced
+	step.Notes = note
+}
+
+// lowerConfidence returns the less certain of two confidences
+func lowerConfidence(a, b string) string {
+	if confidenceRank[b] < confidenceRank[a] {
+		return b
+	}
+	return a
+}
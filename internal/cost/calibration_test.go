@@ -0,0 +1,134 @@
+package cost
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/edgecli/edgecli/internal/tasks"
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+func TestCalibrationRecordEWMA(t *testing.T) {
+	c := NewCalibration()
+	for _, ms := range []float64{100, 200, 200} {
+		c.Record(Sample{DeviceID: "dev-a", Kind: "SYSINFO", DurationMs: ms})
+	}
+
+	st, ok := c.Stats("dev-a", "SYSINFO")
+	if !ok {
+		t.Fatal("no stats recorded")
+	}
+	// 100, then 0.3*200+0.7*100 = 130, then 0.3*200+0.7*130 = 151
+	if st.Samples != 3 || math.Abs(st.LatencyMs-151) > 0.01 {
+		t.Fatalf("stats = %+v, want 3 samples averaging 151ms", st)
+	}
+	if _, ok := c.Stats("dev-b", "SYSINFO"); ok {
+		t.Fatal("stats leaked to another device")
+	}
+}
+
+func TestConfidenceFor(t *testing.T) {
+	for n, want := range map[int]string{0: ConfidenceDefault, 2: ConfidenceLow, 3: ConfidenceMedium, 10: ConfidenceHigh} {
+		if got := ConfidenceFor(n); got != want {
+			t.Errorf("ConfidenceFor(%d) = %s, want %s", n, got, want)
+		}
+	}
+}
+
+func TestCalibrationPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calibration.json")
+	c, err := OpenCalibration(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Record(Sample{DeviceID: "dev-a", Kind: "LLM_GENERATE", DurationMs: 1000, Tokens: 100})
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("calibration written before Save: %v", err)
+	}
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := OpenCalibration(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st, ok := reopened.Stats("dev-a", "LLM_GENERATE")
+	if !ok || st.Samples != 1 || st.MsPerToken != 10 {
+		t.Fatalf("reopened stats = %+v, %v", st, ok)
+	}
+}
+
+func TestEstimatePlanCost_Calibrated(t *testing.T) {
+	c := NewCalibration()
+	for i := 0; i < 3; i++ {
+		c.Record(Sample{DeviceID: "dev-a", Kind: "LLM_GENERATE", DurationMs: 500, Tokens: 100})
+	}
+	device := &pb.DeviceInfo{DeviceId: "dev-a", DeviceName: "laptop", Platform: "linux"}
+	plan := &pb.Plan{Groups: []*pb.TaskGroup{{Tasks: []*pb.TaskSpec{
+		{TaskId: "gen", Kind: "LLM_GENERATE", PromptTokens: 300, MaxOutputTokens: 100},
+	}}}}
+
+	estimator := NewEstimator()
+	estimator.SetCalibration(c)
+	resp := estimator.EstimatePlanCost(plan, []*pb.DeviceInfo{device})
+
+	// 5ms/token learned, 400 tokens expected
+	step := resp.DeviceCosts[0].StepCosts[0]
+	if math.Abs(step.PredictedMs-2000) > 0.01 {
+		t.Errorf("predicted %.1fms, want 2000ms", step.PredictedMs)
+	}
+	if resp.Confidence != ConfidenceMedium || resp.Samples != 3 {
+		t.Errorf("confidence = %s (%d samples), want medium (3)", resp.Confidence, resp.Samples)
+	}
+
+	// Without history the built-in model is used
+	resp = NewEstimator().EstimatePlanCost(plan, []*pb.DeviceInfo{device})
+	if resp.Confidence != ConfidenceDefault || resp.Samples != 0 {
+		t.Errorf("uncalibrated confidence = %s (%d samples)", resp.Confidence, resp.Samples)
+	}
+}
+
+func TestCalibrationSkipsShell(t *testing.T) {
+	c := NewCalibration()
+	c.Record(Sample{DeviceID: "dev-a", Kind: "SHELL", DurationMs: 60000})
+	if _, ok := c.Stats("dev-a", "SHELL"); ok {
+		t.Fatal("SHELL task was calibrated")
+	}
+}
+
+func TestEstimatePlanCost_CalibratedMap(t *testing.T) {
+	shard := func(kind string, items int) string {
+		data, _ := json.Marshal(tasks.MapShard{Kind: kind, Items: make([]string, items)})
+		return string(data)
+	}
+	c := NewCalibration()
+	for i := 0; i < 3; i++ {
+		input := shard("LLM_GENERATE", 4)
+		c.Record(Sample{DeviceID: "dev-a", Kind: SampleKind("MAP", input), DurationMs: 2000, Items: SampleItems("MAP", input)})
+	}
+	if _, ok := c.Stats("dev-a", "MAP:LLM_GENERATE"); !ok {
+		t.Fatal("MAP samples not kept by item kind")
+	}
+	device := &pb.DeviceInfo{DeviceId: "dev-a", DeviceName: "laptop", Platform: "linux"}
+	plan := &pb.Plan{Groups: []*pb.TaskGroup{{Tasks: []*pb.TaskSpec{
+		{TaskId: "map", Kind: "MAP", Input: shard("LLM_GENERATE", 10)},
+		{TaskId: "embed", Kind: "MAP", Input: shard("EMBED", 10)},
+	}}}}
+
+	estimator := NewEstimator()
+	estimator.SetCalibration(c)
+	steps := estimator.EstimatePlanCost(plan, []*pb.DeviceInfo{device}).DeviceCosts[0].StepCosts
+
+	// 500ms/item learned, 10 items
+	if math.Abs(steps[0].PredictedMs-5000) > 0.01 || steps[0].Confidence != ConfidenceMedium {
+		t.Errorf("MAP step = %.1fms (%s), want 5000ms (medium)", steps[0].PredictedMs, steps[0].Confidence)
+	}
+	// No EMBED history: the built-in model is kept
+	if steps[1].Confidence != ConfidenceDefault {
+		t.Errorf("EMBED MAP step confidence = %s, want default", steps[1].Confidence)
+	}
+}
@@ -0,0 +1,61 @@
+package cost
+
+import (
+	"fmt"
+
+	"github.com/edgecli/edgecli/internal/sysinfo"
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+// Typical power draw while running a task, and battery capacity, by platform
+const (
+	PhoneActiveWatts  = 4.0  // android, ios
+	LaptopActiveWatts = 25.0 // macos, windows, linux
+	PhoneBatteryWh    = 15.0
+	LaptopBatteryWh   = 60.0
+)
+
+// devicePower returns device's active draw in watts and battery capacity in Wh
+func devicePower(device *pb.DeviceInfo) (watts, batteryWh float64) {
+	switch device.Platform {
+	case "android", "ios":
+		return PhoneActiveWatts, PhoneBatteryWh
+	default:
+		return LaptopActiveWatts, LaptopBatteryWh
+	}
+}
+
+// estimateEnergy sets step's energy from its predicted time on device
+func estimateEnergy(step *pb.StepCostEstimate, device *pb.DeviceInfo) {
+	watts, _ := devicePower(device)
+	step.EnergyJ = watts * step.PredictedMs / 1000
+}
+
+// onBattery reports whether device runs on its battery
+func onBattery(device *pb.DeviceInfo) bool {
+	return device.Power != nil && device.Power.HasBattery && !device.Power.PluggedIn
+}
+
+// batteryDrain returns the share of a full battery energyJ would use, or 0
+// if device is not on battery power
+func batteryDrain(device *pb.DeviceInfo, energyJ float64) float64 {
+	if !onBattery(device) {
+		return 0
+	}
+	_, batteryWh := devicePower(device)
+	return energyJ / 3600 / batteryWh * 100
+}
+
+// batteryWarning describes the risk of running the plan on a device on
+// battery, or returns "" if there is none
+func batteryWarning(device *pb.DeviceInfo, estimate *pb.DeviceCostEstimate) string {
+	if !onBattery(device) {
+		return ""
+	}
+	left := device.Power.BatteryPercent - estimate.BatteryDrainPercent
+	if left >= sysinfo.LowBatteryPercent {
+		return ""
+	}
+	return fmt.Sprintf("%s is on battery at %.0f%%; the plan would use about %.1f%% more",
+		device.DeviceName, device.Power.BatteryPercent, estimate.BatteryDrainPercent)
+}
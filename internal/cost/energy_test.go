@@ -0,0 +1,51 @@
+package cost
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+func TestEstimatePlanCost_Energy(t *testing.T) {
+	phone := &pb.DeviceInfo{
+		DeviceId:           "phone",
+		DeviceName:         "pixel",
+		Platform:           "android",
+		LlmPrefillToksPerS: 1000,
+		LlmDecodeToksPerS:  100,
+		Power:              &pb.PowerState{HasBattery: true, BatteryPercent: 10},
+	}
+	laptop := &pb.DeviceInfo{
+		DeviceId:   "laptop",
+		DeviceName: "thinkpad",
+		Platform:   "linux",
+		Power:      &pb.PowerState{HasBattery: true, BatteryPercent: 50, PluggedIn: true},
+	}
+	// 1000 prompt + 900 output tokens: 10s on the phone, about 33s on the laptop
+	plan := &pb.Plan{Groups: []*pb.TaskGroup{{Tasks: []*pb.TaskSpec{
+		{TaskId: "gen", Kind: "LLM_GENERATE", PromptTokens: 1000, MaxOutputTokens: 900},
+	}}}}
+
+	resp := NewEstimator().EstimatePlanCost(plan, []*pb.DeviceInfo{phone, laptop})
+
+	if resp.RecommendedDeviceId != "phone" {
+		t.Fatalf("recommended %s, want the faster phone", resp.RecommendedDeviceId)
+	}
+	// 4W for 10s
+	if math.Abs(resp.TotalEnergyJ-40) > 0.01 {
+		t.Errorf("total energy = %.2fJ, want 40J", resp.TotalEnergyJ)
+	}
+	// 40J of a 15Wh battery
+	phoneCost := resp.DeviceCosts[0]
+	if want := 40.0 / 3600 / PhoneBatteryWh * 100; math.Abs(phoneCost.BatteryDrainPercent-want) > 1e-9 {
+		t.Errorf("phone battery drain = %.4f%%, want %.4f%%", phoneCost.BatteryDrainPercent, want)
+	}
+	if resp.DeviceCosts[1].BatteryDrainPercent != 0 {
+		t.Errorf("plugged-in laptop drains %.4f%% of its battery", resp.DeviceCosts[1].BatteryDrainPercent)
+	}
+	if !strings.Contains(resp.Warning, "on battery at 10%") {
+		t.Errorf("warning = %q, want a low battery warning", resp.Warning)
+	}
+}
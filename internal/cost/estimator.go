@@ -2,27 +2,29 @@
 package cost
 
 import (
+	"fmt"
 	"strings"
 
+	"github.com/edgecli/edgecli/internal/links"
+	"github.com/edgecli/edgecli/internal/tasks"
 	pb "github.com/edgecli/edgecli/proto"
 )
 
-// Default throughput values (tokens/sec) for different platforms
-const (
-	DefaultLaptopPrefillTPS = 300.0 // macos, windows, linux
-	DefaultLaptopDecodeTPS  = 30.0
-	DefaultPhonePrefillTPS  = 120.0 // android, ios
-	DefaultPhoneDecodeTPS   = 12.0
-	UnknownStepPenaltyMS    = 250.0 // penalty for unknown step types
-	DefaultMemoryMB         = 2048  // conservative LLM memory estimate
-)
+// UnknownStepPenaltyMS is the estimate for kinds with no registered handler
+const UnknownStepPenaltyMS = 250.0
 
 // Estimator calculates cost estimates for execution plans.
-type Estimator struct{}
+type Estimator struct {
+	locality    Locality        // where task inputs live (nil = inputs ignored)
+	kinds       *tasks.Registry // cost models per task kind
+	calibration *Calibration    // learned latencies (nil = built-in models only)
+	links       *links.Matrix   // measured links (nil = network ignored)
+	origin      string          // device tasks are sent from, with links
+}
 
-// NewEstimator creates a new cost estimator.
+// NewEstimator creates a new cost estimator using the registered task kinds.
 func NewEstimator() *Estimator {
-	return &Estimator{}
+	return &Estimator{kinds: tasks.Default}
 }
 
 // EstimatePlanCost evaluates a plan against multiple devices and returns
@@ -66,11 +68,27 @@ func (e *Estimator) EstimatePlanCost(plan *pb.Plan, devices []*pb.DeviceInfo) *p
 		resp.TotalPredictedMs = bestCost
 		resp.RecommendedDeviceId = bestDevice.DeviceId
 		resp.RecommendedDeviceName = bestDevice.DeviceName
+		for _, dc := range deviceCosts {
+			if dc.DeviceId == bestDevice.DeviceId {
+				resp.Confidence = dc.Confidence
+				resp.Samples = dc.Samples
+				resp.TotalEnergyJ = dc.EnergyJ
+			}
+		}
 	}
 
+	var warnings []string
 	if hasUnknownCosts {
-		resp.Warning = "some steps have unknown cost (using penalty estimate)"
+		warnings = append(warnings, "some steps have unknown cost (using penalty estimate)")
+	}
+	for i, device := range devices {
+		if device == bestDevice {
+			if w := batteryWarning(device, deviceCosts[i]); w != "" {
+				warnings = append(warnings, w)
+			}
+		}
 	}
+	resp.Warning = strings.Join(warnings, "; ")
 
 	return resp
 }
@@ -96,13 +114,35 @@ func (e *Estimator) estimateForDevice(plan *pb.Plan, device *pb.DeviceInfo) *pb.
 		ramSufficient = false
 	}
 
+	// Every step draws power, whether or not it runs in parallel
+	var energyJ float64
+	for _, step := range stepCosts {
+		energyJ += step.EnergyJ
+	}
+
+	// The plan is only as certain as its least certain step
+	confidence := ConfidenceDefault
+	var samples int32
+	for i, step := range stepCosts {
+		if i == 0 {
+			confidence, samples = step.Confidence, step.Samples
+			continue
+		}
+		confidence = lowerConfidence(confidence, step.Confidence)
+		samples = min(samples, step.Samples)
+	}
+
 	return &pb.DeviceCostEstimate{
-		DeviceId:           device.DeviceId,
-		DeviceName:         device.DeviceName,
-		TotalMs:            totalMs,
-		StepCosts:          stepCosts,
-		EstimatedPeakRamMb: peakMemoryMB,
-		RamSufficient:      ramSufficient,
+		DeviceId:            device.DeviceId,
+		DeviceName:          device.DeviceName,
+		TotalMs:             totalMs,
+		StepCosts:           stepCosts,
+		EstimatedPeakRamMb:  peakMemoryMB,
+		RamSufficient:       ramSufficient,
+		Confidence:          confidence,
+		Samples:             samples,
+		EnergyJ:             energyJ,
+		BatteryDrainPercent: batteryDrain(device, energyJ),
 	}
 }
 
@@ -115,6 +155,7 @@ func (e *Estimator) estimateGroupCost(group *pb.TaskGroup, device *pb.DeviceInfo
 
 	for _, task := range group.Tasks {
 		stepCost := e.estimateStep(task, device)
+		estimateEnergy(stepCost, device)
 		stepCosts = append(stepCosts, stepCost)
 
 		// Parallel tasks: take the max latency
@@ -132,116 +173,55 @@ func (e *Estimator) estimateGroupCost(group *pb.TaskGroup, device *pb.DeviceInfo
 	return maxCost, stepCosts, maxMemory
 }
 
-// estimateStep calculates cost for a single task based on its kind.
+// estimateStep calculates cost for a single task based on its kind,
+// plus the time to stage inputs the device does not already hold.
 func (e *Estimator) estimateStep(task *pb.TaskSpec, device *pb.DeviceInfo) *pb.StepCostEstimate {
-	switch task.Kind {
-	case "LLM_GENERATE":
-		return e.estimateLLMStep(task, device)
-	case "IMAGE_GENERATE":
-		return e.estimateImageStep(task, device)
-	case "SYSINFO", "ECHO":
-		// Fast local operations - minimal cost
-		return &pb.StepCostEstimate{
-			TaskId:            task.TaskId,
-			Kind:              task.Kind,
-			PredictedMs:       10, // ~10ms for local ops
-			PredictedMemoryMb: 0,
-			UnknownCost:       false,
-		}
+	step := e.estimateKind(task, device)
+	if ms, note := e.networkTransfer(task, device); ms > 0 {
+		step.NetworkMs = ms
+		step.PredictedMs += ms
+		addNote(step, note)
+	}
+	if len(task.Inputs) == 0 {
+		return step
+	}
+
+	ms, bytes, missing := e.inputTransfer(task, device)
+	step.TransferMs = ms
+	step.TransferBytes = bytes
+	step.PredictedMs += ms
+	var note string
+	switch {
+	case missing > 0:
+		note = fmt.Sprintf("%d input(s) not found on any device", missing)
+	case bytes > 0:
+		note = fmt.Sprintf("stages %d input bytes", bytes)
 	default:
-		return e.estimateUnknownStep(task)
+		note = "inputs already on device"
 	}
+	addNote(step, note)
+	return step
 }
 
-// estimateImageStep calculates cost for IMAGE_GENERATE tasks.
-// Image generation is typically 10-30 seconds on GPU, 30-60s on CPU.
-func (e *Estimator) estimateImageStep(task *pb.TaskSpec, device *pb.DeviceInfo) *pb.StepCostEstimate {
-	var latencyMS float64
-	var memoryMB float64
-	var notes string
-
-	// Estimate based on device capabilities
-	if device.HasGpu || device.HasNpu {
-		// GPU/NPU accelerated: ~15 seconds for 512x512, 20 steps
-		latencyMS = 15000
-		memoryMB = 4096 // 4GB for Stable Diffusion
-		notes = "GPU/NPU accelerated image generation"
-	} else {
-		// CPU only: much slower
-		latencyMS = 45000 // 45 seconds
-		memoryMB = 4096
-		notes = "CPU-only image generation (slower)"
-	}
-
-	// Adjust for TinyML on Arduino (if we detect it)
-	if device.Platform == "arduino" || strings.Contains(strings.ToLower(device.DeviceName), "arduino") {
-		latencyMS = 5000 // TinyML is faster but lower quality
-		memoryMB = 256   // Much smaller models
-		notes = "TinyML image generation (lower quality)"
-	}
-
-	return &pb.StepCostEstimate{
-		TaskId:            task.TaskId,
-		Kind:              task.Kind,
-		PredictedMs:       latencyMS,
-		PredictedMemoryMb: memoryMB,
-		UnknownCost:       false,
-		Notes:             notes,
+// addNote appends note to step's notes
+func addNote(step *pb.StepCostEstimate, note string) {
+	if step.Notes != "" {
+		step.Notes += "; "
 	}
+	step.Notes += note
 }
 
-// estimateLLMStep calculates latency for LLM_GENERATE step.
-// Formula: predicted_ms = (prompt_tokens / prefill_tps + max_output_tokens / decode_tps) * 1000
-func (e *Estimator) estimateLLMStep(task *pb.TaskSpec, device *pb.DeviceInfo) *pb.StepCostEstimate {
-	prefillTPS, decodeTPS := e.getDeviceThroughput(device)
-
-	var latencyMS float64
-	var notes string
-
-	// Calculate latency based on tokens
-	if task.PromptTokens > 0 || task.MaxOutputTokens > 0 {
-		prefillTime := float64(task.PromptTokens) / prefillTPS
-		decodeTime := float64(task.MaxOutputTokens) / decodeTPS
-		latencyMS = (prefillTime + decodeTime) * 1000
-	} else {
-		// No token info provided, use a default estimate
-		latencyMS = 1000 // 1 second default
-		notes = "no token counts provided, using default estimate"
-	}
-
-	// Check if we used defaults
-	if device.LlmPrefillToksPerS == 0 || device.LlmDecodeToksPerS == 0 {
-		if notes != "" {
-			notes += "; "
-		}
-		notes += "using default throughput for platform"
-	}
-
-	return &pb.StepCostEstimate{
-		TaskId:            task.TaskId,
-		Kind:              task.Kind,
-		PredictedMs:       latencyMS,
-		PredictedMemoryMb: DefaultMemoryMB,
-		UnknownCost:       false,
-		Notes:             notes,
-	}
-}
-
-// getDeviceThroughput returns throughput values for a device, using defaults if not reported.
-func (e *Estimator) getDeviceThroughput(device *pb.DeviceInfo) (prefill, decode float64) {
-	// Use device-reported values if available
-	if device.LlmPrefillToksPerS > 0 && device.LlmDecodeToksPerS > 0 {
-		return device.LlmPrefillToksPerS, device.LlmDecodeToksPerS
-	}
-
-	// Fallback to defaults based on platform
-	switch device.Platform {
-	case "android", "ios":
-		return DefaultPhonePrefillTPS, DefaultPhoneDecodeTPS
-	default:
-		// macos, windows, linux, or unknown
-		return DefaultLaptopPrefillTPS, DefaultLaptopDecodeTPS
+// estimateKind calculates cost for a single task with its kind's cost model.
+func (e *Estimator) estimateKind(task *pb.TaskSpec, device *pb.DeviceInfo) *pb.StepCostEstimate {
+	h, ok := e.kinds.Get(task.Kind)
+	if !ok {
+		return e.estimateUnknownStep(task)
 	}
+	step := h.Estimate(task, device)
+	step.TaskId = task.TaskId
+	step.Kind = task.Kind
+	e.calibrate(step, task, device)
+	return step
 }
 
 // estimateUnknownStep returns a penalty estimate for unrecognized step types.
@@ -253,5 +233,6 @@ func (e *Estimator) estimateUnknownStep(task *pb.TaskSpec) *pb.StepCostEstimate
 		PredictedMemoryMb: 0,
 		UnknownCost:       true,
 		Notes:             "unknown step type, using penalty estimate",
+		Confidence:        ConfidenceDefault,
 	}
 }
@@ -354,3 +354,65 @@ func TestEstimatePlanCost_SysinfoAndEcho(t *testing.T) {
 		t.Error("SYSINFO and ECHO should not have unknown costs")
 	}
 }
+
+func TestEstimatePlanCost_InputTransfer(t *testing.T) {
+	holder := &pb.DeviceInfo{DeviceId: "holder", DeviceName: "nas", Platform: "linux"}
+	other := &pb.DeviceInfo{DeviceId: "other", DeviceName: "laptop", Platform: "linux"}
+
+	input := &pb.InputArtifact{Sha256: "abc", SizeBytes: 400_000_000}
+	plan := &pb.Plan{
+		Groups: []*pb.TaskGroup{
+			{Index: 0, Tasks: []*pb.TaskSpec{
+				{TaskId: "t1", Kind: "ECHO", Inputs: []*pb.InputArtifact{input}},
+			}},
+		},
+	}
+
+	estimator := NewEstimator()
+	estimator.SetLocality(Locality{
+		ArtifactKey(input): {{DeviceID: "holder", Path: "data.bin", SizeBytes: 400_000_000}},
+	})
+	resp := estimator.EstimatePlanCost(plan, []*pb.DeviceInfo{other, holder})
+
+	if resp.RecommendedDeviceId != "holder" {
+		t.Errorf("expected device holding the input to be recommended, got %s", resp.RecommendedDeviceId)
+	}
+
+	for _, dc := range resp.DeviceCosts {
+		step := dc.StepCosts[0]
+		switch dc.DeviceId {
+		case "holder":
+			if step.TransferBytes != 0 || step.TransferMs != 0 {
+				t.Errorf("holder should not stage, got %d bytes / %.0fms", step.TransferBytes, step.TransferMs)
+			}
+		case "other":
+			// 400MB at 40MB/s = 10s, plus setup
+			expected := 10000 + TransferSetupMS
+			if math.Abs(step.TransferMs-expected) > 1 {
+				t.Errorf("transfer ms: expected ~%.0f, got %.0f", expected, step.TransferMs)
+			}
+			if step.TransferBytes != 400_000_000 {
+				t.Errorf("transfer bytes: expected 400000000, got %d", step.TransferBytes)
+			}
+			if step.PredictedMs < step.TransferMs {
+				t.Errorf("predicted ms %.0f should include transfer %.0f", step.PredictedMs, step.TransferMs)
+			}
+		}
+	}
+}
+
+func TestLocalityLookupPathInput(t *testing.T) {
+	// Unresolved path inputs are assumed to live on their named device
+	input := &pb.InputArtifact{DeviceId: "dev-a", Path: "models/m.gguf"}
+	var locality Locality
+
+	if _, ok := locality.On(input, "dev-a"); !ok {
+		t.Error("path input should be on its named device")
+	}
+	if _, ok := locality.On(input, "dev-b"); ok {
+		t.Error("path input should not be on another device")
+	}
+	if locs := locality.Lookup(&pb.InputArtifact{Sha256: "missing"}); len(locs) != 0 {
+		t.Errorf("unknown hash should have no locations, got %v", locs)
+	}
+}
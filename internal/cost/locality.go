@@ -0,0 +1,91 @@
+package cost
+
+import (
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+// Transfer defaults used until links are measured
+const (
+	DefaultLinkBytesPerSec = 40e6 // ~320 Mbit/s, typical LAN/Wi-Fi 6
+	TransferSetupMS        = 50.0 // ticket + connection setup per staged file
+)
+
+// ArtifactLocation is one copy of a task input on a device.
+type ArtifactLocation struct {
+	DeviceID  string
+	Path      string // relative to the device's shared root
+	SizeBytes int64
+}
+
+// Locality maps artifact keys (see ArtifactKey) to the devices holding a copy.
+type Locality map[string][]ArtifactLocation
+
+// ArtifactKey identifies an input artifact: by content hash when known,
+// otherwise by device and path.
+func ArtifactKey(a *pb.InputArtifact) string {
+	if a.Sha256 != "" {
+		return "sha256:" + a.Sha256
+	}
+	return a.DeviceId + ":" + a.Path
+}
+
+// Lookup returns the known copies of an artifact. An artifact named by
+// device and path is assumed to exist there even if it was not resolved.
+func (l Locality) Lookup(a *pb.InputArtifact) []ArtifactLocation {
+	if locs := l[ArtifactKey(a)]; len(locs) > 0 {
+		return locs
+	}
+	if a.DeviceId != "" && a.Path != "" {
+		return []ArtifactLocation{{DeviceID: a.DeviceId, Path: a.Path, SizeBytes: a.SizeBytes}}
+	}
+	return nil
+}
+
+// On returns the copy held by deviceID, if any.
+func (l Locality) On(a *pb.InputArtifact, deviceID string) (ArtifactLocation, bool) {
+	for _, loc := range l.Lookup(a) {
+		if loc.DeviceID == deviceID {
+			return loc, true
+		}
+	}
+	return ArtifactLocation{}, false
+}
+
+// SetLocality tells the estimator where task inputs live, so steps include
+// the time to stage missing inputs onto each candidate device.
+func (e *Estimator) SetLocality(l Locality) {
+	e.locality = l
+}
+
+// TransferMs estimates the time to copy sizeBytes between two devices.
+func (e *Estimator) TransferMs(sizeBytes int64) float64 {
+	return TransferSetupMS + float64(sizeBytes)/DefaultLinkBytesPerSec*1000
+}
+
+// EstimateTask returns the cost of running one task on a device,
+// including input staging.
+func (e *Estimator) EstimateTask(task *pb.TaskSpec, device *pb.DeviceInfo) *pb.StepCostEstimate {
+	return e.estimateStep(task, device)
+}
+
+// inputTransfer sums the staging cost of the task inputs the device lacks.
+// Inputs that cannot be found anywhere are reported as missing.
+func (e *Estimator) inputTransfer(task *pb.TaskSpec, device *pb.DeviceInfo) (ms float64, bytes int64, missing int) {
+	for _, in := range task.Inputs {
+		locs := e.locality.Lookup(in)
+		if len(locs) == 0 {
+			missing++
+			continue
+		}
+		if _, ok := e.locality.On(in, device.DeviceId); ok {
+			continue
+		}
+		size := in.SizeBytes
+		if size == 0 {
+			size = locs[0].SizeBytes
+		}
+		bytes += size
+		ms += e.linkTransferMs(locs[0].DeviceID, device.DeviceId, size)
+	}
+	return ms, bytes, missing
+}
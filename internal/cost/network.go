@@ -0,0 +1,77 @@
+package cost
+
+import (
+	"fmt"
+
+	"github.com/edgecli/edgecli/internal/links"
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+// Network defaults used until a link is measured
+const (
+	DefaultLinkRTTMs   = 5.0  // typical LAN/Wi-Fi round trip
+	DefaultOutputBytes = 4096 // result size of kinds without a better guess
+	BytesPerToken      = 4    // LLM output size per token
+)
+
+// SetLinks makes the estimator use measured links between devices. Inputs
+// are staged at the measured speed, and steps on devices other than origin
+// (the device placing the task) include sending the task and its result.
+func (e *Estimator) SetLinks(m *links.Matrix, originID string) {
+	e.links = m
+	e.origin = originID
+}
+
+// link returns the measured link between two devices. A link is measured by
+// its source, so the reverse direction is used if only the other side probed.
+func (e *Estimator) link(a, b string) (links.Link, bool) {
+	if l, ok := e.links.Get(a, b); ok {
+		return l, true
+	}
+	return e.links.Get(b, a)
+}
+
+// linkTransferMs estimates copying sizeBytes from one device to another,
+// over the measured link if there is one
+func (e *Estimator) linkTransferMs(from, to string, sizeBytes int64) float64 {
+	l, ok := e.link(to, from)
+	if !ok || l.BytesPerSec <= 0 {
+		return e.TransferMs(sizeBytes)
+	}
+	// One round trip for the ticket, one for the download
+	return 2*l.RTTMs + float64(sizeBytes)/l.BytesPerSec*1000
+}
+
+// networkTransfer estimates sending task from the origin to device and its
+// result back. It is 0 on the origin itself, or if no links were set.
+func (e *Estimator) networkTransfer(task *pb.TaskSpec, device *pb.DeviceInfo) (ms float64, note string) {
+	if e.links == nil || device.DeviceId == e.origin {
+		return 0, ""
+	}
+
+	bytes := int64(len(task.Input)) + outputBytes(task)
+	rtt, bps := DefaultLinkRTTMs, DefaultLinkBytesPerSec
+	l, ok := e.link(e.origin, device.DeviceId)
+	if ok {
+		rtt = l.RTTMs
+		if l.BytesPerSec > 0 {
+			bps = l.BytesPerSec
+		}
+	}
+	ms = rtt + float64(bytes)/bps*1000
+
+	if ok {
+		note = fmt.Sprintf("network %.0fms over measured link (%.1fms rtt, %.1f MB/s)", ms, rtt, bps/1e6)
+	} else {
+		note = fmt.Sprintf("network %.0fms over default link", ms)
+	}
+	return ms, note
+}
+
+// outputBytes guesses the size of a task's result
+func outputBytes(task *pb.TaskSpec) int64 {
+	if task.Kind == "LLM_GENERATE" && task.MaxOutputTokens > 0 {
+		return int64(task.MaxOutputTokens) * BytesPerToken
+	}
+	return DefaultOutputBytes
+}
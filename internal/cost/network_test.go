@@ -0,0 +1,71 @@
+package cost
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/edgecli/edgecli/internal/links"
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+func TestSlowLinkLosesToLocalDevice(t *testing.T) {
+	local := &pb.DeviceInfo{DeviceId: "local", Platform: "linux", LlmPrefillToksPerS: 100, LlmDecodeToksPerS: 10}
+	gpu := &pb.DeviceInfo{DeviceId: "gpu", Platform: "linux", LlmPrefillToksPerS: 2000, LlmDecodeToksPerS: 100}
+
+	// 1MB prompt: 30s locally, 2.5s on the GPU box before the network
+	task := &pb.TaskSpec{TaskId: "t1", Kind: "LLM_GENERATE", Input: strings.Repeat("x", 1_000_000),
+		PromptTokens: 1000, MaxOutputTokens: 200}
+	plan := &pb.Plan{Groups: []*pb.TaskGroup{{Tasks: []*pb.TaskSpec{task}}}}
+
+	estimator := NewEstimator()
+	if resp := estimator.EstimatePlanCost(plan, []*pb.DeviceInfo{local, gpu}); resp.RecommendedDeviceId != "gpu" {
+		t.Fatalf("without links expected gpu, got %s", resp.RecommendedDeviceId)
+	}
+
+	// Over 20KB/s Wi-Fi the prompt alone takes 50s
+	m := links.NewMatrix()
+	m.Record("local", "gpu", 40, 20_000)
+	estimator.SetLinks(m, "local")
+	resp := estimator.EstimatePlanCost(plan, []*pb.DeviceInfo{local, gpu})
+	if resp.RecommendedDeviceId != "local" {
+		t.Fatalf("over a slow link expected local, got %s", resp.RecommendedDeviceId)
+	}
+
+	for _, dc := range resp.DeviceCosts {
+		step := dc.StepCosts[0]
+		switch dc.DeviceId {
+		case "local":
+			if step.NetworkMs != 0 {
+				t.Errorf("origin should not pay network time, got %.0fms", step.NetworkMs)
+			}
+		case "gpu":
+			// 40ms + (1,000,000 + 800 bytes) / 20KB/s
+			expected := 40 + 1_000_800.0/20_000*1000
+			if math.Abs(step.NetworkMs-expected) > 1 {
+				t.Errorf("network ms: expected %.0f, got %.0f", expected, step.NetworkMs)
+			}
+			if !strings.Contains(step.Notes, "measured link") {
+				t.Errorf("notes should mention the measured link: %q", step.Notes)
+			}
+		}
+	}
+}
+
+func TestStagingUsesMeasuredLink(t *testing.T) {
+	input := &pb.InputArtifact{Sha256: "abc", SizeBytes: 10_000_000}
+	task := &pb.TaskSpec{TaskId: "t1", Kind: "ECHO", Inputs: []*pb.InputArtifact{input}}
+	worker := &pb.DeviceInfo{DeviceId: "worker", Platform: "linux"}
+
+	estimator := NewEstimator()
+	estimator.SetLocality(Locality{ArtifactKey(input): {{DeviceID: "holder", SizeBytes: 10_000_000}}})
+	m := links.NewMatrix()
+	// The worker measured its link to the holder: 10ms, 1MB/s
+	m.Record("worker", "holder", 10, 1e6)
+	estimator.SetLinks(m, "worker")
+
+	step := estimator.EstimateTask(task, worker)
+	if expected := 2*10 + 10_000.0; math.Abs(step.TransferMs-expected) > 1 {
+		t.Errorf("transfer ms: expected %.0f, got %.0f", expected, step.TransferMs)
+	}
+}
@@ -248,13 +248,26 @@ func (s *Service) announceLoop() {
 	}
 }
 
+// SetLLMThroughput updates the benchmarked LLM throughput this device
+// announces from the next broadcast on
+func (s *Service) SetLLMThroughput(prefillTPS, decodeTPS float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.selfDevice.LLMPrefillTPS = prefillTPS
+	s.selfDevice.LLMDecodeTPS = decodeTPS
+}
+
 // broadcastMessage sends a discovery message to all devices on the LAN
 func (s *Service) broadcastMessage(msgType MessageType) {
+	s.mu.RLock()
+	self := *s.selfDevice
+	s.mu.RUnlock()
+
 	msg := DiscoveryMessage{
 		Type:      msgType,
 		Version:   1,
 		Timestamp: time.Now().UnixMilli(),
-		Device:    *s.selfDevice,
+		Device:    self,
 	}
 
 	data, err := json.Marshal(msg)
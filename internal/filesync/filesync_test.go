@@ -0,0 +1,337 @@
+package filesync
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// dirPeer serves another directory as a peer, like a remote device would.
+type dirPeer struct {
+	id, name string
+	root     string
+	fetches  int
+}
+
+func (p *dirPeer) ID() string   { return p.id }
+func (p *dirPeer) Name() string { return p.name }
+
+func (p *dirPeer) Manifest(ctx context.Context) (Manifest, error) {
+	return BuildManifest(p.root, fileSHA256)
+}
+
+func (p *dirPeer) Fetch(ctx context.Context, rel string, w io.Writer) error {
+	p.fetches++
+	return copyFile(filepath.Join(p.root, filepath.FromSlash(rel)), w)
+}
+
+func writeFile(t *testing.T, root, rel, content string, mtime time.Time) {
+	t.Helper()
+	p := filepath.Join(root, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(p, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, root, rel string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(rel)))
+	if err != nil {
+		t.Fatalf("read %s: %v", rel, err)
+	}
+	return string(data)
+}
+
+func meta(p, sum string, mtime int64) FileMeta {
+	return FileMeta{Path: p, SHA256: sum, ModTimeMs: mtime}
+}
+
+func TestPlanRules(t *testing.T) {
+	base := Manifest{
+		"same.txt":     meta("same.txt", "a", 1),
+		"theirs.txt":   meta("theirs.txt", "a", 1),
+		"ours.txt":     meta("ours.txt", "a", 1),
+		"deleted.txt":  meta("deleted.txt", "a", 1),
+		"modified.txt": meta("modified.txt", "a", 1),
+		"both.txt":     meta("both.txt", "a", 1),
+		"gone.txt":     meta("gone.txt", "a", 1),
+	}
+	local := Manifest{
+		"same.txt":     meta("same.txt", "a", 1),
+		"theirs.txt":   meta("theirs.txt", "a", 1),
+		"ours.txt":     meta("ours.txt", "b", 2),
+		"deleted.txt":  meta("deleted.txt", "a", 1),
+		"modified.txt": meta("modified.txt", "b", 2),
+		"both.txt":     meta("both.txt", "b", 2),
+	}
+	remote := Manifest{
+		"same.txt":   meta("same.txt", "a", 1),
+		"theirs.txt": meta("theirs.txt", "c", 3),
+		"ours.txt":   meta("ours.txt", "a", 1),
+		"both.txt":   meta("both.txt", "c", 3),
+		"gone.txt":   meta("gone.txt", "a", 1),
+		"new.txt":    meta("new.txt", "n", 3),
+	}
+
+	got := map[string]Action{}
+	for _, a := range Plan(local, remote, base, "me", "peer") {
+		got[a.Path] = a
+	}
+
+	expect := map[string]ActionKind{
+		"theirs.txt":  ActionPull,
+		"deleted.txt": ActionDelete,
+		"both.txt":    ActionConflictKeepRemote,
+		"new.txt":     ActionPull,
+	}
+	if len(got) != len(expect) {
+		t.Fatalf("got %d actions %+v, want %d", len(got), got, len(expect))
+	}
+	for p, kind := range expect {
+		if got[p].Kind != kind {
+			t.Errorf("%s: got %q, want %q", p, got[p].Kind, kind)
+		}
+	}
+	// Remote is newer, so our version moves aside under our name.
+	want := ConflictName("both.txt", "me", time.UnixMilli(2))
+	if got["both.txt"].ConflictPath != want {
+		t.Errorf("conflict path %q, want %q", got["both.txt"].ConflictPath, want)
+	}
+}
+
+func TestPlanConflictIsSymmetric(t *testing.T) {
+	a := Manifest{"f.txt": meta("f.txt", "x", 5)}
+	b := Manifest{"f.txt": meta("f.txt", "y", 9)}
+
+	fromA := Plan(a, b, nil, "devA", "devB")
+	fromB := Plan(b, a, nil, "devB", "devA")
+	if len(fromA) != 1 || len(fromB) != 1 {
+		t.Fatalf("expected one action each, got %+v / %+v", fromA, fromB)
+	}
+	if fromA[0].Kind != ActionConflictKeepRemote || fromB[0].Kind != ActionConflictKeepLocal {
+		t.Fatalf("newer version should win on both sides: %q / %q", fromA[0].Kind, fromB[0].Kind)
+	}
+	if fromA[0].ConflictPath != fromB[0].ConflictPath {
+		t.Fatalf("conflict copies must share a name: %q vs %q", fromA[0].ConflictPath, fromB[0].ConflictPath)
+	}
+}
+
+func TestConflictName(t *testing.T) {
+	ts := time.Date(2026, 10, 18, 13, 15, 0, 0, time.UTC)
+	got := ConflictName("notes/todo.txt", "my laptop", ts)
+	want := "notes/todo (conflict my-laptop 20261018-131500).txt"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestBuildManifestSkipsHidden(t *testing.T) {
+	root := t.TempDir()
+	now := time.Now()
+	writeFile(t, root, "a.txt", "a", now)
+	writeFile(t, root, ".a.txt.sync-1234.part", "partial", now)
+	writeFile(t, root, ".git/config", "x", now)
+
+	m, err := BuildManifest(root, fileSHA256)
+	if err != nil {
+		t.Fatalf("BuildManifest failed: %v", err)
+	}
+	if len(m) != 1 || m["a.txt"].SizeBytes != 1 {
+		t.Fatalf("unexpected manifest: %+v", m)
+	}
+}
+
+func TestSyncRoundsConverge(t *testing.T) {
+	rootA, rootB := t.TempDir(), t.TempDir()
+	t0 := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	writeFile(t, rootA, "shared.txt", "v1", t0)
+	writeFile(t, rootA, "docs/a-only.txt", "from A", t0)
+	writeFile(t, rootB, "b-only.txt", "from B", t0)
+
+	svcA, err := NewService(Config{Root: rootA, SelfName: "devA", StatePath: filepath.Join(t.TempDir(), "a.json")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	svcB, err := NewService(Config{Root: rootB, SelfName: "devB"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	peerB := &dirPeer{id: "b", name: "devB", root: rootB}
+	peerA := &dirPeer{id: "a", name: "devA", root: rootA}
+	ctx := context.Background()
+
+	round := func() {
+		t.Helper()
+		if _, err := svcA.SyncPeer(ctx, peerB); err != nil {
+			t.Fatalf("A<-B: %v", err)
+		}
+		if _, err := svcB.SyncPeer(ctx, peerA); err != nil {
+			t.Fatalf("B<-A: %v", err)
+		}
+	}
+
+	round()
+	if readFile(t, rootA, "b-only.txt") != "from B" || readFile(t, rootB, "docs/a-only.txt") != "from A" {
+		t.Fatal("new files should propagate both ways")
+	}
+
+	// Unchanged content is not transferred again.
+	fetches := peerB.fetches + peerA.fetches
+	round()
+	if peerB.fetches+peerA.fetches != fetches {
+		t.Fatal("in-sync round should not fetch")
+	}
+
+	// One-sided edit propagates; delete propagates.
+	writeFile(t, rootB, "shared.txt", "v2", t0.Add(time.Minute))
+	os.Remove(filepath.Join(rootA, "docs", "a-only.txt"))
+	round()
+	if readFile(t, rootA, "shared.txt") != "v2" {
+		t.Fatal("edit on B should reach A")
+	}
+	if _, err := os.Stat(filepath.Join(rootB, "docs", "a-only.txt")); !os.IsNotExist(err) {
+		t.Fatal("delete on A should reach B")
+	}
+
+	// Concurrent edits keep both versions, identically on both sides.
+	writeFile(t, rootA, "shared.txt", "A edit", t0.Add(2*time.Minute))
+	writeFile(t, rootB, "shared.txt", "B edit", t0.Add(3*time.Minute))
+	round()
+	round()
+
+	conflict := ConflictName("shared.txt", "devA", t0.Add(2*time.Minute))
+	for _, root := range []string{rootA, rootB} {
+		if got := readFile(t, root, "shared.txt"); got != "B edit" {
+			t.Errorf("%s: newer edit should win, got %q", root, got)
+		}
+		if got := readFile(t, root, conflict); got != "A edit" {
+			t.Errorf("%s: losing edit should be kept as %q, got %q", root, conflict, got)
+		}
+	}
+
+	// State persists across restarts.
+	reloaded, err := NewService(Config{Root: rootA, SelfName: "devA", StatePath: svcA.cfg.StatePath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reloaded.bases["b"]) == 0 {
+		t.Fatal("base manifest should be persisted")
+	}
+}
+
+// evilPeer advertises a fixed manifest and serves the same bytes for any path.
+type evilPeer struct {
+	dirPeer
+	manifest Manifest
+	closed   bool
+}
+
+func (p *evilPeer) Manifest(ctx context.Context) (Manifest, error) { return p.manifest, nil }
+
+func (p *evilPeer) Fetch(ctx context.Context, rel string, w io.Writer) error {
+	p.fetches++
+	_, err := io.WriteString(w, "pwned")
+	return err
+}
+
+func (p *evilPeer) Close() error {
+	p.closed = true
+	return nil
+}
+
+func TestSyncRejectsPathsOutsideRoot(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "shared")
+	if err := os.Mkdir(root, 0755); err != nil {
+		t.Fatal(err)
+	}
+	svc, err := NewService(Config{Root: root, SelfName: "devA"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := "0fcc0a8e9ec4e8e2d4a7ec6e5ea5bd36c16a40bd7a1f2bb4a8ea6ac0e6f4d7a1"
+
+	for _, rel := range []string{"../escape.txt", "docs/../../escape.txt", filepath.Join(parent, "escape.txt"), "/escape.txt", ""} {
+		peer := &evilPeer{
+			dirPeer:  dirPeer{id: "evil", name: "evil"},
+			manifest: Manifest{rel: meta(rel, sum, 1)},
+		}
+		if _, err := svc.SyncPeer(context.Background(), peer); err == nil {
+			t.Errorf("%q: round should fail", rel)
+		}
+		if peer.fetches != 0 {
+			t.Errorf("%q: nothing should be fetched", rel)
+		}
+		if !peer.closed {
+			t.Errorf("%q: peer should be closed after the round", rel)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(parent, "escape.txt")); !os.IsNotExist(err) {
+		t.Fatal("file written outside the sync root")
+	}
+
+	// The key and the entry's own path must agree
+	peer := &evilPeer{
+		dirPeer:  dirPeer{id: "evil", name: "evil"},
+		manifest: Manifest{"ok.txt": meta("../escape.txt", sum, 1)},
+	}
+	if _, err := svc.SyncPeer(context.Background(), peer); err == nil {
+		t.Error("mismatched manifest entry should fail the round")
+	}
+}
+
+func TestSyncHonorsQuota(t *testing.T) {
+	rootA, rootB := t.TempDir(), t.TempDir()
+	t0 := time.Now().Add(-time.Hour).Truncate(time.Second)
+	writeFile(t, rootB, "small.txt", "ok", t0)
+	writeFile(t, rootB, "big.txt", strings.Repeat("x", 100), t0)
+
+	quota := func(sizeBytes, replacing int64) error {
+		if sizeBytes > 10 {
+			return errors.New("quota exceeded")
+		}
+		return nil
+	}
+	svc, err := NewService(Config{Root: rootA, SelfName: "devA", Quota: quota})
+	if err != nil {
+		t.Fatal(err)
+	}
+	peer := &dirPeer{id: "b", name: "devB", root: rootB}
+	if _, err := svc.SyncPeer(context.Background(), peer); err == nil {
+		t.Fatal("round should report the file over quota")
+	}
+	if readFile(t, rootA, "small.txt") != "ok" {
+		t.Error("files within quota should still be pulled")
+	}
+	if _, err := os.Stat(filepath.Join(rootA, "big.txt")); !os.IsNotExist(err) {
+		t.Error("file over quota should not be written")
+	}
+}
+
+func TestSyncStopsAtDeclaredSize(t *testing.T) {
+	root := t.TempDir()
+	svc, err := NewService(Config{Root: root, SelfName: "devA"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Declares 2 bytes but sends "pwned"
+	peer := &evilPeer{
+		dirPeer:  dirPeer{id: "evil", name: "evil"},
+		manifest: Manifest{"a.txt": FileMeta{Path: "a.txt", SizeBytes: 2, SHA256: "00"}},
+	}
+	if _, err := svc.SyncPeer(context.Background(), peer); err == nil || !strings.Contains(err.Error(), "declared") {
+		t.Fatalf("err = %v, want a declared-size error", err)
+	}
+}
@@ -0,0 +1,162 @@
+// Package filesync keeps the shared folder in step across paired devices.
+//
+// Each device builds a content-hash manifest of its shared root. A sync round
+// with a peer compares the local manifest, the peer's manifest and the base
+// manifest recorded after the previous round (a three-way diff). Only files
+// whose hash changed are transferred, and every device pulls; nothing is
+// pushed. When both sides changed a file, the newer version keeps the path
+// and the other is kept beside it as a conflict copy, named identically on
+// both devices so the two sides converge.
+package filesync
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/edgecli/edgecli/internal/transfer"
+)
+
+// FileMeta describes one file in a manifest.
+type FileMeta struct {
+	Path      string `json:"path"` // slash-separated, relative to the root
+	SizeBytes int64  `json:"size_bytes"`
+	ModTimeMs int64  `json:"mod_time_ms"`
+	SHA256    string `json:"sha256"`
+}
+
+// Manifest maps relative paths to file metadata.
+type Manifest map[string]FileMeta
+
+// ChecksumFunc returns the hex SHA-256 of the file at an absolute path.
+type ChecksumFunc func(path string) (string, error)
+
+// ignored reports whether a relative path is excluded from sync:
+// dot-files and dot-directories (partial transfers, editor state).
+func ignored(rel string) bool {
+	for _, part := range strings.Split(rel, "/") {
+		if strings.HasPrefix(part, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildManifest hashes every regular, non-hidden file under root.
+// A missing root yields an empty manifest.
+func BuildManifest(root string, checksum ChecksumFunc) (Manifest, error) {
+	m := make(Manifest)
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if p == root {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if ignored(rel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil // removed while walking
+		}
+		sum, err := checksum(p)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return fmt.Errorf("hash %s: %w", rel, err)
+		}
+		m[rel] = FileMeta{
+			Path:      rel,
+			SizeBytes: info.Size(),
+			ModTimeMs: info.ModTime().UnixMilli(),
+			SHA256:    sum,
+		}
+		return nil
+	})
+	return m, err
+}
+
+// TotalBytes returns the summed size of all files in the manifest.
+func (m Manifest) TotalBytes() int64 {
+	var total int64
+	for _, f := range m {
+		total += f.SizeBytes
+	}
+	return total
+}
+
+// Paths returns the manifest's paths in sorted order.
+func (m Manifest) Paths() []string {
+	paths := make([]string, 0, len(m))
+	for p := range m {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Validate rejects a manifest (typically a peer's) with a path that is
+// empty, absolute or escapes the root, or that disagrees with its key.
+func (m Manifest) Validate() error {
+	for rel, meta := range m {
+		if meta.Path != rel {
+			return fmt.Errorf("manifest path %q: entry is for %q", rel, meta.Path)
+		}
+		if err := transfer.CheckRelPath(rel); err != nil {
+			return fmt.Errorf("manifest path %q: %w", rel, err)
+		}
+	}
+	return nil
+}
+
+// ConflictName returns the path used for the losing version of a conflict.
+// It depends only on the loser's device name and modification time, so both
+// devices derive the same name and the conflict copy itself syncs cleanly.
+//
+//	notes/todo.txt -> notes/todo (conflict laptop 20261018-131500).txt
+func ConflictName(rel, deviceName string, modTime time.Time) string {
+	dir, file := path.Split(rel)
+	ext := path.Ext(file)
+	base := strings.TrimSuffix(file, ext)
+	name := sanitizeName(deviceName)
+	if name == "" {
+		name = "device"
+	}
+	return dir + fmt.Sprintf("%s (conflict %s %s)%s", base, name, modTime.UTC().Format("20060102-150405"), ext)
+}
+
+// sanitizeName keeps device names safe for use in file names.
+func sanitizeName(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			b.WriteRune(r)
+		case r == ' ', r == '.':
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
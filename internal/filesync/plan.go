@@ -0,0 +1,133 @@
+package filesync
+
+import "time"
+
+// ActionKind is what a sync round does with one path.
+type ActionKind string
+
+const (
+	// ActionPull fetches the peer's version into Path.
+	ActionPull ActionKind = "pull"
+	// ActionDelete removes the local file because the peer deleted it and
+	// the local copy was unchanged since the last round.
+	ActionDelete ActionKind = "delete"
+	// ActionConflictKeepLocal keeps the local file at Path and fetches the
+	// peer's version into ConflictPath.
+	ActionConflictKeepLocal ActionKind = "conflict_keep_local"
+	// ActionConflictKeepRemote moves the local file to ConflictPath and
+	// fetches the peer's version into Path.
+	ActionConflictKeepRemote ActionKind = "conflict_keep_remote"
+)
+
+// Action is one step of a sync round.
+type Action struct {
+	Kind         ActionKind
+	Path         string
+	Remote       FileMeta // peer's version (zero for ActionDelete)
+	ConflictPath string   // set for conflict actions
+}
+
+// Plan computes the actions needed to bring local up to date with remote,
+// given base, the manifest both sides agreed on after the previous round.
+//
+// Rules, per path:
+//   - same hash on both sides: nothing to do
+//   - only the peer changed it (or created it): pull
+//   - only we changed it: nothing; the peer pulls from us
+//   - peer deleted it and ours is unchanged: delete; if ours changed, keep it
+//   - both changed: the newer version (by mtime, then hash) keeps the path,
+//     the other becomes a conflict copy named after the device it came from
+func Plan(local, remote, base Manifest, selfName, peerName string) []Action {
+	var actions []Action
+
+	for _, p := range union(local, remote, base).Paths() {
+		l, lok := local[p]
+		r, rok := remote[p]
+		b, bok := base[p]
+
+		if lok && rok && l.SHA256 == r.SHA256 {
+			continue
+		}
+
+		if !rok {
+			if bok && lok && l.SHA256 == b.SHA256 {
+				actions = append(actions, Action{Kind: ActionDelete, Path: p})
+			}
+			continue
+		}
+
+		if !lok {
+			// Deleted here since the last round and unchanged there: the
+			// peer will apply our delete. Otherwise the peer's copy is new.
+			if bok && r.SHA256 == b.SHA256 {
+				continue
+			}
+			actions = append(actions, Action{Kind: ActionPull, Path: p, Remote: r})
+			continue
+		}
+
+		localChanged := !bok || l.SHA256 != b.SHA256
+		remoteChanged := !bok || r.SHA256 != b.SHA256
+		switch {
+		case !localChanged:
+			actions = append(actions, Action{Kind: ActionPull, Path: p, Remote: r})
+		case !remoteChanged:
+			// Ours is newer; the peer pulls it.
+		case remoteWins(l, r):
+			actions = append(actions, Action{
+				Kind:         ActionConflictKeepRemote,
+				Path:         p,
+				Remote:       r,
+				ConflictPath: ConflictName(p, selfName, time.UnixMilli(l.ModTimeMs)),
+			})
+		default:
+			actions = append(actions, Action{
+				Kind:         ActionConflictKeepLocal,
+				Path:         p,
+				Remote:       r,
+				ConflictPath: ConflictName(p, peerName, time.UnixMilli(r.ModTimeMs)),
+			})
+		}
+	}
+	return actions
+}
+
+// NextBase returns the base manifest to record after a round: paths where
+// both sides now agree take the agreed version, paths missing on both sides
+// are dropped, and everything else keeps its previous base entry.
+func NextBase(local, remote, base Manifest) Manifest {
+	next := make(Manifest)
+	for p := range union(local, remote, base) {
+		l, lok := local[p]
+		r, rok := remote[p]
+		switch {
+		case lok && rok && l.SHA256 == r.SHA256:
+			next[p] = r
+		case !lok && !rok:
+			// gone on both sides
+		default:
+			if b, ok := base[p]; ok {
+				next[p] = b
+			}
+		}
+	}
+	return next
+}
+
+// remoteWins breaks a conflict deterministically so both devices agree.
+func remoteWins(local, remote FileMeta) bool {
+	if remote.ModTimeMs != local.ModTimeMs {
+		return remote.ModTimeMs > local.ModTimeMs
+	}
+	return remote.SHA256 > local.SHA256
+}
+
+func union(ms ...Manifest) Manifest {
+	u := make(Manifest)
+	for _, m := range ms {
+		for p, f := range m {
+			u[p] = f
+		}
+	}
+	return u
+}
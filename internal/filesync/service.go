@@ -0,0 +1,481 @@
+package filesync
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/edgecli/edgecli/internal/transfer"
+)
+
+// DefaultInterval is the time between sync rounds.
+const DefaultInterval = 30 * time.Second
+
+// Peer is a paired device the service can pull from. A Peer that is also
+// an io.Closer is closed at the end of each round.
+type Peer interface {
+	ID() string
+	Name() string
+	// Manifest returns the peer's current manifest.
+	Manifest(ctx context.Context) (Manifest, error)
+	// Fetch streams the peer's copy of rel into w.
+	Fetch(ctx context.Context, rel string, w io.Writer) error
+}
+
+// Config configures a sync Service.
+type Config struct {
+	Root      string        // shared root to keep in sync
+	StatePath string        // JSON file for base manifests (empty = not persisted)
+	SelfName  string        // this device's name, used in conflict copies
+	Interval  time.Duration // time between rounds (default DefaultInterval)
+	Checksum  ChecksumFunc  // file hasher (callers pass a caching one)
+	Quota     QuotaFunc     // storage quota for pulled files (nil = none)
+}
+
+// QuotaFunc rejects a write of sizeBytes that replaces a file of replacing
+// bytes when it would exceed the storage quota.
+type QuotaFunc func(sizeBytes, replacing int64) error
+
+// PeerStatus reports the outcome of sync rounds with one peer.
+type PeerStatus struct {
+	PeerID       string
+	PeerName     string
+	LastSyncAt   time.Time
+	LastError    string
+	Rounds       int
+	FilesPulled  int   // in the last round
+	FilesDeleted int   // in the last round
+	Conflicts    int   // in the last round
+	Pending      int   // actions that failed in the last round
+	BytesPulled  int64 // total since start
+}
+
+// Service runs sync rounds against a set of peers.
+type Service struct {
+	cfg Config
+
+	roundMu sync.Mutex // one round at a time; rounds touch the same files
+
+	mu         sync.Mutex
+	bases      map[string]Manifest // peer ID -> base manifest
+	status     map[string]*PeerStatus
+	localFiles int
+	localBytes int64
+}
+
+// DefaultStatePath returns ~/.edgemesh/sync_state.json.
+func DefaultStatePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".edgemesh", "sync_state.json"), nil
+}
+
+// NewService creates a Service and loads persisted base manifests.
+func NewService(cfg Config) (*Service, error) {
+	if cfg.Root == "" {
+		return nil, fmt.Errorf("sync root is required")
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultInterval
+	}
+	if cfg.Checksum == nil {
+		cfg.Checksum = fileSHA256
+	}
+
+	s := &Service{
+		cfg:    cfg,
+		bases:  make(map[string]Manifest),
+		status: make(map[string]*PeerStatus),
+	}
+
+	if cfg.StatePath != "" {
+		data, err := os.ReadFile(cfg.StatePath)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("read sync state: %w", err)
+		}
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &s.bases); err != nil {
+				return nil, fmt.Errorf("parse sync state: %w", err)
+			}
+		}
+	}
+	return s, nil
+}
+
+// LocalManifest builds the manifest of the shared root and records its
+// size for Status.
+func (s *Service) LocalManifest() (Manifest, error) {
+	m, err := BuildManifest(s.cfg.Root, s.cfg.Checksum)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.localFiles = len(m)
+	s.localBytes = m.TotalBytes()
+	s.mu.Unlock()
+	return m, nil
+}
+
+// Status returns local manifest totals and a snapshot of per-peer status.
+func (s *Service) Status() (files int, bytes int64, peers []PeerStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, st := range s.status {
+		peers = append(peers, *st)
+	}
+	return s.localFiles, s.localBytes, peers
+}
+
+// Run syncs with every peer returned by peers each interval until ctx ends.
+func (s *Service) Run(ctx context.Context, peers func() []Peer) {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		for _, p := range peers() {
+			if ctx.Err() != nil {
+				return
+			}
+			if _, err := s.SyncPeer(ctx, p); err != nil {
+				log.Printf("[WARN] filesync: round with %s failed: %v", p.Name(), err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SyncPeer runs one round against peer: diff manifests, apply actions, and
+// record the new base.
+func (s *Service) SyncPeer(ctx context.Context, peer Peer) (*PeerStatus, error) {
+	s.roundMu.Lock()
+	defer s.roundMu.Unlock()
+
+	st := s.peerStatus(peer)
+	st.Rounds++
+	st.LastSyncAt = time.Now()
+	st.FilesPulled, st.FilesDeleted, st.Conflicts, st.Pending = 0, 0, 0, 0
+
+	if c, ok := peer.(io.Closer); ok {
+		defer c.Close()
+	}
+	remote, err := peer.Manifest(ctx)
+	if err != nil {
+		return s.finishRound(st, fmt.Errorf("fetch manifest: %w", err))
+	}
+	// Peer paths are joined onto our root, so they must stay under it
+	if err := remote.Validate(); err != nil {
+		return s.finishRound(st, fmt.Errorf("peer manifest: %w", err))
+	}
+	local, err := s.LocalManifest()
+	if err != nil {
+		return s.finishRound(st, fmt.Errorf("build local manifest: %w", err))
+	}
+
+	s.mu.Lock()
+	base := s.bases[peer.ID()]
+	s.mu.Unlock()
+
+	actions := Plan(local, remote, base, s.cfg.SelfName, peer.Name())
+	var lastErr error
+	for _, a := range actions {
+		if ctx.Err() != nil {
+			st.Pending++
+			lastErr = ctx.Err()
+			continue
+		}
+		n, err := s.apply(ctx, peer, a, local)
+		if err != nil {
+			log.Printf("[WARN] filesync: %s %s from %s: %v", a.Kind, a.Path, peer.Name(), err)
+			st.Pending++
+			lastErr = err
+			continue
+		}
+		st.BytesPulled += n
+		switch a.Kind {
+		case ActionPull:
+			st.FilesPulled++
+		case ActionDelete:
+			st.FilesDeleted++
+		default:
+			st.Conflicts++
+		}
+	}
+	if len(actions) > 0 {
+		log.Printf("[INFO] filesync: round with %s: pulled=%d deleted=%d conflicts=%d failed=%d",
+			peer.Name(), st.FilesPulled, st.FilesDeleted, st.Conflicts, st.Pending)
+	}
+
+	// Rebuild after applying so the base reflects what is actually on disk.
+	if len(actions) > 0 {
+		if local, err = s.LocalManifest(); err != nil {
+			return s.finishRound(st, fmt.Errorf("rebuild local manifest: %w", err))
+		}
+	}
+	s.mu.Lock()
+	s.bases[peer.ID()] = NextBase(local, remote, base)
+	s.mu.Unlock()
+	if err := s.saveState(); err != nil {
+		log.Printf("[WARN] filesync: save state: %v", err)
+	}
+
+	if lastErr != nil {
+		return s.finishRound(st, fmt.Errorf("%d of %d actions failed, last: %w", st.Pending, len(actions), lastErr))
+	}
+	return s.finishRound(st, nil)
+}
+
+func (s *Service) peerStatus(peer Peer) *PeerStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.status[peer.ID()]
+	if !ok {
+		st = &PeerStatus{PeerID: peer.ID()}
+		s.status[peer.ID()] = st
+	}
+	st.PeerName = peer.Name()
+	return st
+}
+
+func (s *Service) finishRound(st *PeerStatus, err error) (*PeerStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st.LastError = ""
+	if err != nil {
+		st.LastError = err.Error()
+	}
+	snapshot := *st
+	return &snapshot, err
+}
+
+// apply performs one action and returns the number of bytes fetched.
+func (s *Service) apply(ctx context.Context, peer Peer, a Action, local Manifest) (int64, error) {
+	full, err := transfer.ResolveUnderRoot(s.cfg.Root, filepath.FromSlash(a.Path))
+	if err != nil {
+		return 0, err
+	}
+
+	switch a.Kind {
+	case ActionDelete:
+		if err := s.checkUnchanged(full, local, a.Path); err != nil {
+			return 0, err
+		}
+		if err := os.Remove(full); err != nil && !os.IsNotExist(err) {
+			return 0, err
+		}
+		return 0, nil
+
+	case ActionPull:
+		if err := s.checkUnchanged(full, local, a.Path); err != nil {
+			return 0, err
+		}
+		return s.fetch(ctx, peer, a.Remote, a.Path, local)
+
+	case ActionConflictKeepLocal:
+		return s.fetch(ctx, peer, a.Remote, a.ConflictPath, local)
+
+	case ActionConflictKeepRemote:
+		if err := s.checkUnchanged(full, local, a.Path); err != nil {
+			return 0, err
+		}
+		conflictFull, err := transfer.ResolveUnderRoot(s.cfg.Root, filepath.FromSlash(a.ConflictPath))
+		if err != nil {
+			return 0, err
+		}
+		if existing, ok := local[a.ConflictPath]; ok && existing.SHA256 == local[a.Path].SHA256 {
+			// Conflict copy already exists (e.g. pulled from the peer)
+			if err := os.Remove(full); err != nil {
+				return 0, err
+			}
+		} else if err := os.Rename(full, conflictFull); err != nil {
+			return 0, fmt.Errorf("move local version aside: %w", err)
+		}
+		return s.fetch(ctx, peer, a.Remote, a.Path, local)
+	}
+	return 0, fmt.Errorf("unknown action %q", a.Kind)
+}
+
+// checkUnchanged guards against local edits made after the manifest was
+// built, which would otherwise be overwritten or deleted.
+func (s *Service) checkUnchanged(full string, local Manifest, rel string) error {
+	want, existed := local[rel]
+	if _, err := os.Stat(full); os.IsNotExist(err) {
+		if existed {
+			return fmt.Errorf("local file removed during sync")
+		}
+		return nil
+	}
+	if !existed {
+		return fmt.Errorf("local file created during sync")
+	}
+	sum, err := s.cfg.Checksum(full)
+	if err != nil {
+		return err
+	}
+	if sum != want.SHA256 {
+		return fmt.Errorf("local file changed during sync")
+	}
+	return nil
+}
+
+// fetch writes the peer's version of meta into dest (relative), verifying
+// its hash and preserving its modification time. If a local file already
+// has the same content it is copied instead of transferred.
+func (s *Service) fetch(ctx context.Context, peer Peer, meta FileMeta, dest string, local Manifest) (int64, error) {
+	destFull, err := transfer.ResolveUnderRoot(s.cfg.Root, filepath.FromSlash(dest))
+	if err != nil {
+		return 0, err
+	}
+	if existing, ok := local[dest]; ok && existing.SHA256 == meta.SHA256 {
+		return 0, nil
+	}
+	var replacing int64
+	if info, err := os.Stat(destFull); err == nil {
+		replacing = info.Size()
+	}
+	if err := s.checkQuota(meta.SizeBytes, replacing); err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(destFull), 0755); err != nil {
+		return 0, err
+	}
+
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	tmp := filepath.Join(filepath.Dir(destFull),
+		fmt.Sprintf(".%s.sync-%s.part", filepath.Base(destFull), hex.EncodeToString(suffix)))
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp) // no-op after a successful rename
+
+	h := sha256.New()
+	// The peer may send more than its manifest declared; stop at that size
+	cw := &countingWriter{w: io.MultiWriter(f, h), limit: meta.SizeBytes}
+
+	var fetched int64
+	if src := s.findLocalCopy(local, meta.SHA256); src != "" {
+		err = copyFile(src, cw)
+	} else {
+		err = peer.Fetch(ctx, meta.Path, cw)
+		fetched = cw.n
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if sum := hex.EncodeToString(h.Sum(nil)); sum != meta.SHA256 {
+		return 0, fmt.Errorf("sha256 mismatch: expected %s, got %s", meta.SHA256, sum)
+	}
+	mtime := time.UnixMilli(meta.ModTimeMs)
+	if err := os.Chtimes(tmp, mtime, mtime); err != nil {
+		return 0, err
+	}
+	// The temp file is already under the root, so it counts as used; check
+	// again in case other writes landed during the transfer
+	if err := s.checkQuota(0, replacing); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp, destFull); err != nil {
+		return 0, err
+	}
+	return fetched, nil
+}
+
+func (s *Service) checkQuota(sizeBytes, replacing int64) error {
+	if s.cfg.Quota == nil {
+		return nil
+	}
+	return s.cfg.Quota(sizeBytes, replacing)
+}
+
+// findLocalCopy returns the absolute path of a local file with the given
+// hash, or "" if there is none.
+func (s *Service) findLocalCopy(local Manifest, sum string) string {
+	for _, p := range local.Paths() {
+		if local[p].SHA256 == sum {
+			full := filepath.Join(s.cfg.Root, filepath.FromSlash(p))
+			if got, err := s.cfg.Checksum(full); err == nil && got == sum {
+				return full
+			}
+		}
+	}
+	return ""
+}
+
+func (s *Service) saveState() error {
+	if s.cfg.StatePath == "" {
+		return nil
+	}
+	s.mu.Lock()
+	data, err := json.MarshalIndent(s.bases, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.cfg.StatePath), 0755); err != nil {
+		return err
+	}
+	tmp := s.cfg.StatePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.cfg.StatePath)
+}
+
+type countingWriter struct {
+	w     io.Writer
+	n     int64
+	limit int64 // most bytes accepted
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.n+int64(len(p)) > c.limit {
+		return 0, fmt.Errorf("peer sent more than the %d bytes it declared", c.limit)
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func copyFile(src string, w io.Writer) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
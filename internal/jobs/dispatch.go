@@ -0,0 +1,257 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Task priorities; higher runs first and zero is normal
+const (
+	PriorityLow         = -10
+	PriorityNormal      = 0
+	PriorityHigh        = 10
+	PriorityInteractive = 20 // may preempt less urgent tasks
+)
+
+// ErrPreempted is the cause a lease's context is canceled with when a more
+// urgent request takes its slot
+var ErrPreempted = errors.New("preempted by a higher-priority task")
+
+// Dispatcher hands out a fixed number of task slots per device, most urgent
+// request first and in arrival order within a priority. With preemption on,
+// an interactive request waiting on a full device takes the slot of its
+// least urgent preemptible lease.
+type Dispatcher struct {
+	mu      sync.Mutex
+	slots   int // per device; 0 = unlimited
+	preempt bool
+	devices map[string]*deviceQueue
+
+	deviceSlots func(deviceID string) int // overrides slots if set
+}
+
+type deviceQueue struct {
+	running []*Lease
+	waiting []*waiter // most urgent first
+}
+
+type waiter struct {
+	id          string
+	priority    int
+	preemptible bool
+	lease       *Lease // set when granted
+	ready       chan struct{}
+}
+
+// Lease is a device slot granted by Acquire. It must be released.
+type Lease struct {
+	ID       string
+	DeviceID string
+	Priority int
+
+	d           *Dispatcher
+	preemptible bool
+	preempting  bool
+	released    bool
+	preempted   chan struct{}
+}
+
+// NewDispatcher returns a dispatcher giving each device slots concurrent
+// tasks (0 = unlimited), preempting for interactive requests if preempt is set
+func NewDispatcher(slots int, preempt bool) *Dispatcher {
+	return &Dispatcher{
+		slots:   max(slots, 0),
+		preempt: preempt,
+		devices: make(map[string]*deviceQueue),
+	}
+}
+
+// SetDeviceSlots makes fn decide each device's slots (0 = unlimited) in
+// place of the dispatcher-wide number
+func (d *Dispatcher) SetDeviceSlots(fn func(deviceID string) int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.deviceSlots = fn
+}
+
+// slotsFor returns deviceID's slots. Called with d.mu held.
+func (d *Dispatcher) slotsFor(deviceID string) int {
+	if d.deviceSlots != nil {
+		return max(d.deviceSlots(deviceID), 0)
+	}
+	return d.slots
+}
+
+// Acquire waits for a slot on deviceID. id names the request for Position;
+// preemptible leases may be preempted by interactive requests.
+func (d *Dispatcher) Acquire(ctx context.Context, deviceID, id string, priority int, preemptible bool) (*Lease, error) {
+	w := &waiter{id: id, priority: priority, preemptible: preemptible, ready: make(chan struct{})}
+
+	d.mu.Lock()
+	q := d.devices[deviceID]
+	if q == nil {
+		q = &deviceQueue{}
+		d.devices[deviceID] = q
+	}
+	i := 0
+	for i < len(q.waiting) && q.waiting[i].priority >= priority {
+		i++
+	}
+	q.waiting = append(q.waiting, nil)
+	copy(q.waiting[i+1:], q.waiting[i:])
+	q.waiting[i] = w
+	d.dispatch(deviceID, q)
+	d.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return w.lease, nil
+	case <-ctx.Done():
+	}
+
+	d.mu.Lock()
+	if w.lease != nil {
+		// Granted as the context ended
+		d.mu.Unlock()
+		w.lease.Release()
+		return nil, ctx.Err()
+	}
+	for i, other := range q.waiting {
+		if other == w {
+			q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+			break
+		}
+	}
+	if len(q.running) == 0 && len(q.waiting) == 0 {
+		delete(d.devices, deviceID)
+	}
+	d.mu.Unlock()
+	return nil, ctx.Err()
+}
+
+// dispatch grants free slots to the head of the queue, then preempts for
+// interactive requests still waiting. Called with d.mu held.
+func (d *Dispatcher) dispatch(deviceID string, q *deviceQueue) {
+	slots := d.slotsFor(deviceID)
+	for len(q.waiting) > 0 && (slots == 0 || len(q.running) < slots) {
+		w := q.waiting[0]
+		q.waiting = q.waiting[1:]
+		w.lease = &Lease{
+			ID:          w.id,
+			DeviceID:    deviceID,
+			Priority:    w.priority,
+			d:           d,
+			preemptible: w.preemptible,
+			preempted:   make(chan struct{}),
+		}
+		q.running = append(q.running, w.lease)
+		close(w.ready)
+	}
+	if !d.preempt {
+		return
+	}
+
+	// Slots already being given up go to the first interactive waiters
+	pending := 0
+	for _, l := range q.running {
+		if l.preempting {
+			pending++
+		}
+	}
+	for _, w := range q.waiting {
+		if w.priority < PriorityInteractive {
+			return
+		}
+		if pending > 0 {
+			pending--
+			continue
+		}
+		victim := q.victim(w.priority)
+		if victim == nil {
+			return
+		}
+		victim.preempting = true
+		close(victim.preempted)
+	}
+}
+
+// victim picks the least urgent preemptible lease below priority, the most
+// recently granted among equals since it has done the least work
+func (q *deviceQueue) victim(priority int) *Lease {
+	var victim *Lease
+	for _, l := range q.running {
+		if !l.preemptible || l.preempting || l.Priority >= priority {
+			continue
+		}
+		if victim == nil || l.Priority <= victim.Priority {
+			victim = l
+		}
+	}
+	return victim
+}
+
+// Release gives the slot back. Releasing twice is a no-op.
+func (l *Lease) Release() {
+	d := l.d
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if l.released {
+		return
+	}
+	l.released = true
+	q := d.devices[l.DeviceID]
+	for i, other := range q.running {
+		if other == l {
+			q.running = append(q.running[:i], q.running[i+1:]...)
+			break
+		}
+	}
+	d.dispatch(l.DeviceID, q)
+	if len(q.running) == 0 && len(q.waiting) == 0 {
+		delete(d.devices, l.DeviceID)
+	}
+}
+
+// Preempted is closed when a more urgent request needs the slot; the holder
+// should stop its work and release the lease
+func (l *Lease) Preempted() <-chan struct{} {
+	return l.preempted
+}
+
+// Context returns a child of parent that is canceled with ErrPreempted
+// when the lease is preempted
+func (l *Lease) Context(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+	go func() {
+		select {
+		case <-l.preempted:
+			cancel(ErrPreempted)
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() { cancel(context.Canceled) }
+}
+
+// Position returns id's place in deviceID's queue, 1 being next, or 0 if
+// it is not waiting
+func (d *Dispatcher) Position(deviceID, id string) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if q := d.devices[deviceID]; q != nil {
+		for i, w := range q.waiting {
+			if w.id == id {
+				return i + 1
+			}
+		}
+	}
+	return 0
+}
+
+// Preemptible reports whether t may be stopped and rerun for a more urgent
+// task. SHELL commands may have side effects, so they run to the end.
+func (t *Task) Preemptible() bool {
+	return t.Kind != "SHELL"
+}
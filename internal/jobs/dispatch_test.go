@@ -0,0 +1,169 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// acquireAsync starts Acquire and returns a channel delivering its lease
+func acquireAsync(t *testing.T, d *Dispatcher, id string, priority int) <-chan *Lease {
+	t.Helper()
+	ch := make(chan *Lease, 1)
+	go func() {
+		l, err := d.Acquire(context.Background(), "npu", id, priority, true)
+		if err != nil {
+			t.Errorf("%s: %v", id, err)
+		}
+		ch <- l
+	}()
+	waitFor(t, func() bool { return d.Position("npu", id) > 0 })
+	return ch
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestDispatcherOrdersByPriority(t *testing.T) {
+	d := NewDispatcher(1, false)
+	running, err := d.Acquire(context.Background(), "npu", "map-1", PriorityNormal, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	low := acquireAsync(t, d, "batch", PriorityLow)
+	normal := acquireAsync(t, d, "map-2", PriorityNormal)
+	high := acquireAsync(t, d, "urgent", PriorityHigh)
+	if got := []int{d.Position("npu", "urgent"), d.Position("npu", "map-2"), d.Position("npu", "batch")}; got[0] != 1 || got[1] != 2 || got[2] != 3 {
+		t.Fatalf("positions = %v, want [1 2 3]", got)
+	}
+
+	// Without preemption the running task keeps its slot
+	select {
+	case <-running.Preempted():
+		t.Fatal("preempted with preemption off")
+	default:
+	}
+
+	for _, ch := range []<-chan *Lease{high, normal, low} {
+		running.Release()
+		running = <-ch
+	}
+	if running.ID != "batch" {
+		t.Fatalf("last lease %s, want batch", running.ID)
+	}
+	running.Release()
+	running.Release()
+	if len(d.devices) != 0 {
+		t.Fatalf("idle device queues left: %v", d.devices)
+	}
+}
+
+func TestDispatcherPreemptsForInteractive(t *testing.T) {
+	d := NewDispatcher(2, true)
+	ctx := context.Background()
+	batch, _ := d.Acquire(ctx, "npu", "batch", PriorityLow, true)
+	shell, _ := d.Acquire(ctx, "npu", "shell", PriorityLow, false)
+
+	// A high priority request waits but does not preempt
+	high := acquireAsync(t, d, "high", PriorityHigh)
+	agent := acquireAsync(t, d, "agent", PriorityInteractive)
+
+	taskCtx, cancel := batch.Context(ctx)
+	defer cancel()
+	<-taskCtx.Done()
+	if !errors.Is(context.Cause(taskCtx), ErrPreempted) {
+		t.Fatalf("cause = %v, want ErrPreempted", context.Cause(taskCtx))
+	}
+	select {
+	case <-shell.Preempted():
+		t.Fatal("non-preemptible lease preempted")
+	default:
+	}
+
+	// The preempted task requeues behind the interactive one
+	batch.Release()
+	if l := <-agent; l.ID != "agent" {
+		t.Fatalf("slot went to %s", l.ID)
+	}
+	requeued := acquireAsync(t, d, "batch", PriorityLow)
+	if pos := d.Position("npu", "batch"); pos != 2 {
+		t.Fatalf("requeued at %d, want 2", pos)
+	}
+	shell.Release()
+	(<-high).Release()
+	(<-requeued).Release()
+}
+
+func TestDispatcherAcquireCanceled(t *testing.T) {
+	d := NewDispatcher(1, false)
+	held, _ := d.Acquire(context.Background(), "npu", "held", PriorityNormal, true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := d.Acquire(ctx, "npu", "late", PriorityHigh, true); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v", err)
+	}
+	if pos := d.Position("npu", "late"); pos != 0 {
+		t.Fatalf("canceled request still queued at %d", pos)
+	}
+	held.Release()
+	if len(d.devices) != 0 {
+		t.Fatal("queue not cleaned up")
+	}
+}
+
+func TestSetPriority(t *testing.T) {
+	m := NewManager()
+	job := &Job{ID: "j", Tasks: []*Task{{ID: "a"}, {ID: "b", Priority: PriorityLow}}}
+	m.jobs[job.ID] = job
+	m.SetPriority("j", PriorityHigh)
+	if job.Priority != PriorityHigh || job.Tasks[0].Priority != PriorityHigh || job.Tasks[1].Priority != PriorityLow {
+		t.Fatalf("priorities: job %d tasks %d %d", job.Priority, job.Tasks[0].Priority, job.Tasks[1].Priority)
+	}
+
+	m.SetTaskRunning("j", "a")
+	m.RequeueTask("j", "a")
+	if a := job.Tasks[0]; a.State != TaskQueued || a.StartedAt != 0 || a.Preemptions != 1 {
+		t.Fatalf("requeued task %+v", a)
+	}
+}
+
+func TestDispatcherDeviceSlots(t *testing.T) {
+	d := NewDispatcher(0, false)
+	d.SetDeviceSlots(func(deviceID string) int {
+		if deviceID == "dev-one" {
+			return 1
+		}
+		return 0
+	})
+
+	for _, device := range []string{"dev-one", "dev-any"} {
+		first, err := d.Acquire(context.Background(), device, "first", PriorityNormal, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		second, err := d.Acquire(ctx, device, "second", PriorityNormal, true)
+		cancel()
+		if device == "dev-one" && err == nil {
+			t.Errorf("%s: second task got a second slot", device)
+		}
+		if device == "dev-any" && err != nil {
+			t.Errorf("%s: second task waited: %v", device, err)
+		}
+		if second != nil {
+			second.Release()
+		}
+		first.Release()
+	}
+}
@@ -0,0 +1,115 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/edgecli/edgecli/internal/tasks"
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+// ErrNoMatchingDevices is returned when no device matches a fan-out
+var ErrNoMatchingDevices = errors.New("no device matches the fan-out filter")
+
+// ErrUnknownCapability is returned for a FanOutFilter capability that is not
+// one of the Cap* values
+var ErrUnknownCapability = errors.New("unknown capability")
+
+// Capabilities a FanOutFilter can require
+const (
+	CapGPU           = "gpu"
+	CapNPU           = "npu"
+	CapLocalModel    = "local_model"
+	CapScreenCapture = "screen_capture"
+)
+
+// FanOutFilter selects the devices a fan-out job runs on
+type FanOutFilter struct {
+	Platforms    []string // GOOS values ("macos" means darwin); any if empty
+	Capabilities []string // Cap* values; all are required
+}
+
+// validate rejects capabilities the filter does not know
+func (f FanOutFilter) validate() error {
+	for _, c := range f.Capabilities {
+		switch c {
+		case CapGPU, CapNPU, CapLocalModel, CapScreenCapture:
+		default:
+			return fmt.Errorf("%w %q (want %s, %s, %s or %s)", ErrUnknownCapability, c, CapGPU, CapNPU, CapLocalModel, CapScreenCapture)
+		}
+	}
+	return nil
+}
+
+// Match reports whether device is on one of the platforms and has every
+// capability
+func (f FanOutFilter) Match(device *pb.DeviceInfo) bool {
+	if len(f.Platforms) > 0 {
+		found := false
+		for _, p := range f.Platforms {
+			if normalizePlatform(p) == normalizePlatform(device.Platform) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, c := range f.Capabilities {
+		var has bool
+		switch c {
+		case CapGPU:
+			has = device.HasGpu
+		case CapNPU:
+			has = device.HasNpu
+		case CapLocalModel:
+			has = device.HasLocalModel
+		case CapScreenCapture:
+			has = device.CanScreenCapture
+		}
+		if !has {
+			return false
+		}
+	}
+	return true
+}
+
+func normalizePlatform(p string) string {
+	p = strings.ToLower(strings.TrimSpace(p))
+	if p == "macos" {
+		return "darwin"
+	}
+	return p
+}
+
+// FanOut creates a job that runs one kind task with input on every device
+// that matches filter and runs the kind. The tasks share one group, so they
+// run in parallel, and their outputs are concatenated.
+func (m *Manager) FanOut(kind, input string, devices []*pb.DeviceInfo, filter FanOutFilter) (*Job, error) {
+	if err := filter.validate(); err != nil {
+		return nil, err
+	}
+
+	specs := make([]*pb.TaskSpec, 0, len(devices))
+	for _, d := range devices {
+		if !filter.Match(d) || !tasks.Supports(d, kind) {
+			continue
+		}
+		specs = append(specs, &pb.TaskSpec{
+			TaskId:         uuid.New().String(),
+			Kind:           kind,
+			Input:          input,
+			TargetDeviceId: d.DeviceId,
+		})
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("%w: none of %d device(s) runs %s and matches", ErrNoMatchingDevices, len(devices), kind)
+	}
+
+	plan := &pb.Plan{Groups: []*pb.TaskGroup{{Index: 0, Tasks: specs}}}
+	return m.CreateJob("", devices, 0, plan, &pb.ReduceSpec{Kind: "CONCAT"}, nil)
+}
@@ -0,0 +1,61 @@
+package jobs
+
+import (
+	"errors"
+	"testing"
+
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+// shellOnly is the task_kinds of a device that runs SHELL
+var shellOnly = []string{"SHELL"}
+
+func TestFanOutFiltersDevices(t *testing.T) {
+	devices := []*pb.DeviceInfo{
+		{DeviceId: "linux-gpu", Platform: "linux", HasGpu: true, TaskKinds: shellOnly},
+		{DeviceId: "linux-cpu", Platform: "linux", TaskKinds: shellOnly},
+		{DeviceId: "mac", Platform: "darwin", HasGpu: true, TaskKinds: shellOnly},
+		{DeviceId: "sensor", Platform: "linux", HasGpu: true, TaskKinds: []string{"SYSINFO"}},
+	}
+
+	cases := []struct {
+		name   string
+		filter FanOutFilter
+		want   []string
+	}{
+		{"everything that runs SHELL", FanOutFilter{}, []string{"linux-gpu", "linux-cpu", "mac"}},
+		{"linux only", FanOutFilter{Platforms: []string{"linux"}}, []string{"linux-gpu", "linux-cpu"}},
+		{"macos alias", FanOutFilter{Platforms: []string{"macos"}}, []string{"mac"}},
+		{"gpu only", FanOutFilter{Capabilities: []string{CapGPU}}, []string{"linux-gpu", "mac"}},
+		{"linux with gpu", FanOutFilter{Platforms: []string{"linux"}, Capabilities: []string{CapGPU}}, []string{"linux-gpu"}},
+	}
+	for _, c := range cases {
+		job, err := NewManager().FanOut("SHELL", "uptime", devices, c.filter)
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if job.TotalGroups != 1 || len(job.Tasks) != len(c.want) {
+			t.Errorf("%s: %d task(s) in %d group(s), want %d in 1", c.name, len(job.Tasks), job.TotalGroups, len(c.want))
+			continue
+		}
+		for i, task := range job.Tasks {
+			if task.DeviceID != c.want[i] || task.Kind != "SHELL" || task.Input != "uptime" {
+				t.Errorf("%s: task %d is %s on %s, want SHELL on %s", c.name, i, task.Kind, task.DeviceID, c.want[i])
+			}
+		}
+	}
+}
+
+func TestFanOutErrors(t *testing.T) {
+	devices := []*pb.DeviceInfo{{DeviceId: "linux", Platform: "linux", TaskKinds: shellOnly}}
+
+	_, err := NewManager().FanOut("SHELL", "uptime", devices, FanOutFilter{Capabilities: []string{"fpga"}})
+	if !errors.Is(err, ErrUnknownCapability) {
+		t.Errorf("unknown capability: err = %v", err)
+	}
+	_, err = NewManager().FanOut("SHELL", "uptime", devices, FanOutFilter{Platforms: []string{"windows"}})
+	if !errors.Is(err, ErrNoMatchingDevices) {
+		t.Errorf("no matches: err = %v", err)
+	}
+}
@@ -2,13 +2,16 @@
 package jobs
 
 import (
-	"log"
-	"strings"
+	"errors"
+	"fmt"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/edgecli/edgecli/internal/cost"
+	"github.com/edgecli/edgecli/internal/links"
+	"github.com/edgecli/edgecli/internal/tasks"
 	pb "github.com/edgecli/edgecli/proto"
 )
 
@@ -32,21 +35,32 @@ const (
 	JobFailed  JobState = "FAILED"
 )
 
+// ErrUnsupportedKind is returned when a task's kind is not run by its
+// target device or by any candidate device
+var ErrUnsupportedKind = errors.New("task kind not supported")
+
 // Task represents a unit of work to be executed on a device
 type Task struct {
-	ID         string
-	JobID      string
-	Kind       string // "SYSINFO" or "ECHO"
-	Input      string
-	DeviceID   string
-	DeviceName string
-	DeviceAddr string
-	State      TaskState
-	Result     string
-	Error      string
-	GroupIndex int   // which group this task belongs to
-	StartedAt  int64 // Unix milliseconds when task started running
-	EndedAt    int64 // Unix milliseconds when task completed/failed
+	ID          string
+	JobID       string
+	Kind        string // a registered task kind, e.g. "SYSINFO"
+	Input       string
+	DeviceID    string
+	DeviceName  string
+	DeviceAddr  string
+	State       TaskState
+	Result      string
+	Error       string
+	GroupIndex  int             // which group this task belongs to
+	StartedAt   int64           // Unix milliseconds when task started running
+	EndedAt     int64           // Unix milliseconds when task completed/failed
+	Inputs      []*StagedInput  // input artifacts and how they reach the device
+	Placement   string          // why the device was chosen
+	StagedBytes int64           // input bytes copied onto the device
+	Shell       *pb.ShellResult // command outcome of a SHELL task
+	RunMs       float64         // time the device reported running the task
+	Priority    int             // higher is dispatched first
+	Preemptions int             // times stopped and requeued for a more urgent task
 }
 
 // ReduceSpec specifies how to combine results
@@ -66,12 +80,62 @@ type Job struct {
 	CurrentGroup int         // which group is currently executing
 	TotalGroups  int         // total number of groups
 	ReduceSpec   *ReduceSpec // how to combine results
+	TraceID      string      // trace the job's spans belong to, if any
+	ScheduleID   string      // schedule that started the job, if any
+	Priority     int         // default for its tasks
 }
 
 // Manager manages jobs and their tasks in-memory
 type Manager struct {
-	jobs map[string]*Job
-	mu   sync.RWMutex
+	jobs        map[string]*Job
+	mu          sync.RWMutex
+	calibration *cost.Calibration // learns from completed tasks (nil = off)
+	links       *links.Matrix     // measured links between devices (nil = off)
+	origin      string            // device that sends tasks out, with links
+	eventHook   func(Event)       // told about failed tasks and finished jobs
+}
+
+// Event reports a task failing or a job finishing
+type Event struct {
+	JobID       string
+	TaskID      string // empty for job events
+	State       string // the task's TaskState or the job's JobState
+	DeviceID    string // device that ran the task
+	DeviceName  string
+	Error       string
+	FailedTasks int // tasks of the job that failed, for job events
+	Time        time.Time
+}
+
+// SetEventHook has fn called as tasks fail and jobs finish. It runs on the
+// goroutine changing the state, without the manager's lock, and must not block.
+func (m *Manager) SetEventHook(fn func(Event)) {
+	m.mu.Lock()
+	m.eventHook = fn
+	m.mu.Unlock()
+}
+
+func (m *Manager) emit(ev *Event) {
+	if ev == nil {
+		return
+	}
+	m.mu.RLock()
+	fn := m.eventHook
+	m.mu.RUnlock()
+	if fn != nil {
+		fn(*ev)
+	}
+}
+
+// jobEvent describes job's end; the caller holds m.mu
+func jobEvent(job *Job) *Event {
+	ev := &Event{JobID: job.ID, State: string(job.State), Time: job.EndedAt}
+	for _, t := range job.Tasks {
+		if t.State == TaskFailed {
+			ev.FailedTasks++
+		}
+	}
+	return ev
 }
 
 // NewManager creates a new job manager
@@ -82,8 +146,9 @@ func NewManager() *Manager {
 }
 
 // CreateJob creates a new job with tasks distributed across devices
-// If no plan provided, auto-generates a smart plan based on userText
-func (m *Manager) CreateJob(userText string, devices []*pb.DeviceInfo, maxWorkers int, plan *pb.Plan, reduce *pb.ReduceSpec) (*Job, error) {
+// If no plan provided, auto-generates a smart plan based on userText.
+// Tasks with input artifacts and no target are placed using locality.
+func (m *Manager) CreateJob(userText string, devices []*pb.DeviceInfo, maxWorkers int, plan *pb.Plan, reduce *pb.ReduceSpec, locality cost.Locality) (*Job, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -130,14 +195,29 @@ func (m *Manager) CreateJob(userText string, devices []*pb.DeviceInfo, maxWorker
 	// Convert plan to tasks
 	for _, group := range plan.Groups {
 		for _, taskSpec := range group.Tasks {
-			// Find target device
+			// Find target device among those that run the task's kind
+			candidates := tasks.Default.Filter(selectedDevices, taskSpec.Kind)
 			var device *pb.DeviceInfo
+			placement := ""
 			if taskSpec.TargetDeviceId != "" {
 				device = deviceMap[taskSpec.TargetDeviceId]
+				placement = "target device"
+				if device != nil && !tasks.Supports(device, taskSpec.Kind) {
+					return nil, fmt.Errorf("%w: %s does not run %s", ErrUnsupportedKind, device.DeviceName, taskSpec.Kind)
+				}
 			}
-			if device == nil && len(selectedDevices) > 0 {
+			if device == nil && (len(taskSpec.Inputs) > 0 || m.links != nil) {
+				// Run where the inputs are, or where staging them and
+				// sending the task over the network is cheapest
+				device, placement = placeByCost(taskSpec, candidates, m.estimator(locality))
+			}
+			if device == nil && len(candidates) > 0 {
 				// Assign to first available device if not specified
-				device = selectedDevices[0]
+				device = candidates[0]
+				placement = "first available device"
+			}
+			if device == nil && len(selectedDevices) > 0 {
+				return nil, fmt.Errorf("%w: no device runs %s", ErrUnsupportedKind, taskSpec.Kind)
 			}
 
 			deviceName := ""
@@ -149,6 +229,11 @@ func (m *Manager) CreateJob(userText string, devices []*pb.DeviceInfo, maxWorker
 				deviceID = device.DeviceId
 			}
 
+			inputs, err := planInputs(taskSpec, deviceID, locality)
+			if err != nil {
+				return nil, err
+			}
+
 			taskID := taskSpec.TaskId
 			if taskID == "" {
 				taskID = uuid.New().String()
@@ -164,6 +249,9 @@ func (m *Manager) CreateJob(userText string, devices []*pb.DeviceInfo, maxWorker
 				DeviceAddr: deviceAddr,
 				State:      TaskQueued,
 				GroupIndex: int(group.Index),
+				Inputs:     inputs,
+				Placement:  placement,
+				Priority:   int(taskSpec.Priority),
 			}
 			job.Tasks = append(job.Tasks, task)
 		}
@@ -193,132 +281,24 @@ func (m *Manager) GenerateDefaultPlan(devices []*pb.DeviceInfo) *pb.Plan {
 }
 
 // GenerateSmartPlan analyzes the user's request and creates an intelligent plan.
-// For LLM-related requests (summarize, chat, code), it creates LLM_GENERATE tasks.
-// For status/info requests, it creates SYSINFO tasks.
+// Registered kinds that implement tasks.Planner are offered the request in
+// registration order, with the devices that support them; the first to
+// return a task wins (e.g. LLM_GENERATE for summarize, chat, code).
+// Otherwise it falls back to SYSINFO tasks.
 func (m *Manager) GenerateSmartPlan(userText string, devices []*pb.DeviceInfo) *pb.Plan {
-	textLower := strings.ToLower(userText)
-
-	// Detect if this is an image generation task (check first, higher priority)
-	isImageTask := strings.Contains(textLower, "image") ||
-		strings.Contains(textLower, "picture") ||
-		strings.Contains(textLower, "photo") ||
-		strings.Contains(textLower, "draw") ||
-		strings.Contains(textLower, "painting") ||
-		strings.Contains(textLower, "artwork") ||
-		strings.Contains(textLower, "visualize") ||
-		strings.Contains(textLower, "render")
-
-	// Detect if this is an LLM task (but not image)
-	isLLMTask := !isImageTask && (strings.Contains(textLower, "summarize") ||
-		strings.Contains(textLower, "write") ||
-		strings.Contains(textLower, "code") ||
-		strings.Contains(textLower, "explain") ||
-		strings.Contains(textLower, "chat") ||
-		strings.Contains(textLower, "answer") ||
-		strings.Contains(textLower, "translate"))
-
-	if isImageTask {
-		// Create IMAGE_GENERATE task routed to best GPU/NPU device
-		var bestDevice *pb.DeviceInfo
-		for _, d := range devices {
-			if d.HasGpu || d.HasNpu {
-				bestDevice = d
-				break
-			}
-		}
-		if bestDevice == nil && len(devices) > 0 {
-			bestDevice = devices[0]
-		}
-
-		// Image gen is heavier than text
-		promptTokens := int32(len(userText) / 4)
-		if promptTokens < 10 {
-			promptTokens = 10
-		}
-
-		task := &pb.TaskSpec{
-			TaskId:          uuid.New().String(),
-			Kind:            "IMAGE_GENERATE",
-			Input:           userText,
-			TargetDeviceId:  "",
-			PromptTokens:    promptTokens,
-			MaxOutputTokens: 100, // output is just the image path
-		}
-		if bestDevice != nil {
-			task.TargetDeviceId = bestDevice.DeviceId
-		}
-
-		return &pb.Plan{
-			Groups: []*pb.TaskGroup{
-				{Index: 0, Tasks: []*pb.TaskSpec{task}},
-			},
+	for _, h := range tasks.Default.Handlers() {
+		planner, ok := h.(tasks.Planner)
+		if !ok {
+			continue
 		}
-	} else if isLLMTask {
-		// Create LLM_GENERATE task routed to best device (NPU > GPU > CPU)
-		// First, filter for devices that actually have LLM capability
-		llmDevices := FilterLLMDevices(devices)
-
-		var bestDevice *pb.DeviceInfo
-		if len(llmDevices) > 0 {
-			// Use smart LLM device selection (NPU > GPU > CPU, fastest prefill)
-			bestDevice = SelectBestLLMDevice(llmDevices)
-			log.Printf("[INFO] GenerateSmartPlan: LLM task routed to %s (has_npu=%v, has_gpu=%v, prefill_tps=%.1f)",
-				bestDevice.DeviceName, bestDevice.HasNpu, bestDevice.HasGpu, bestDevice.LlmPrefillToksPerS)
-		} else {
-			// Fallback: no LLM devices, use NPU/GPU/CPU priority (old behavior)
-			log.Printf("[WARN] GenerateSmartPlan: No LLM-capable devices found, falling back to hardware priority")
-			for _, d := range devices {
-				if d.HasNpu {
-					bestDevice = d
-					break
-				}
-			}
-			if bestDevice == nil {
-				for _, d := range devices {
-					if d.HasGpu {
-						bestDevice = d
-						break
-					}
-				}
-			}
-			if bestDevice == nil && len(devices) > 0 {
-				bestDevice = devices[0]
+		candidates := tasks.Default.Filter(devices, h.Schema().Kind)
+		if task := planner.Plan(userText, candidates); task != nil {
+			return &pb.Plan{
+				Groups: []*pb.TaskGroup{
+					{Index: 0, Tasks: []*pb.TaskSpec{task}},
+				},
 			}
 		}
-
-		// Estimate tokens (rough: 4 chars per token)
-		promptTokens := int32(len(userText) / 4)
-		if promptTokens < 10 {
-			promptTokens = 10
-		}
-
-		// Output tokens based on task type
-		maxOutput := int32(300) // default
-		if strings.Contains(textLower, "summarize") {
-			maxOutput = 200
-		} else if strings.Contains(textLower, "code") || strings.Contains(textLower, "write") {
-			maxOutput = 500
-		} else if strings.Contains(textLower, "image") {
-			maxOutput = 100 // just a description
-		}
-
-		task := &pb.TaskSpec{
-			TaskId:          uuid.New().String(),
-			Kind:            "LLM_GENERATE",
-			Input:           userText,
-			TargetDeviceId:  "",
-			PromptTokens:    promptTokens,
-			MaxOutputTokens: maxOutput,
-		}
-		if bestDevice != nil {
-			task.TargetDeviceId = bestDevice.DeviceId
-		}
-
-		return &pb.Plan{
-			Groups: []*pb.TaskGroup{
-				{Index: 0, Tasks: []*pb.TaskSpec{task}},
-			},
-		}
 	}
 
 	// Fall back to SYSINFO for status/info requests
@@ -345,16 +325,20 @@ func (m *Manager) SetJobRunning(jobID string) {
 	}
 }
 
-// UpdateTask updates the state and result of a task
+// UpdateTask updates the state and result of a task. A task that is done
+// is recorded in the calibration, if one is set.
 func (m *Manager) UpdateTask(jobID, taskID string, state TaskState, result, errMsg string) {
 	m.mu.Lock()
-	defer m.mu.Unlock()
 
 	job, ok := m.jobs[jobID]
 	if !ok {
+		m.mu.Unlock()
 		return
 	}
 
+	calibration := m.calibration
+	var sample *cost.Sample
+	var ev *Event
 	now := time.Now().UnixMilli()
 	for _, task := range job.Tasks {
 		if task.ID == taskID {
@@ -367,6 +351,125 @@ func (m *Manager) UpdateTask(jobID, taskID string, state TaskState, result, errM
 			} else if state == TaskDone || state == TaskFailed {
 				task.EndedAt = now
 			}
+			if state == TaskDone && calibration != nil {
+				sample = completedSample(task)
+			}
+			if state == TaskFailed {
+				ev = &Event{
+					JobID:      jobID,
+					TaskID:     taskID,
+					State:      string(state),
+					DeviceID:   task.DeviceID,
+					DeviceName: task.DeviceName,
+					Error:      errMsg,
+					Time:       time.UnixMilli(now),
+				}
+			}
+			break
+		}
+	}
+	m.mu.Unlock()
+	m.emit(ev)
+
+	if sample != nil {
+		calibration.Record(*sample)
+	}
+}
+
+// completedSample describes a finished task for calibration. The device's
+// own run time is preferred, since the wall time also counts dialing and
+// staging inputs.
+func completedSample(t *Task) *cost.Sample {
+	ms := t.RunMs
+	if ms <= 0 && t.StartedAt > 0 && t.EndedAt > t.StartedAt {
+		ms = float64(t.EndedAt - t.StartedAt)
+	}
+	if ms <= 0 {
+		return nil
+	}
+	return &cost.Sample{
+		DeviceID:   t.DeviceID,
+		Kind:       cost.SampleKind(t.Kind, t.Input),
+		DurationMs: ms,
+		Tokens:     cost.SampleTokens(t.Kind, t.Input, t.Result),
+		Items:      cost.SampleItems(t.Kind, t.Input),
+	}
+}
+
+// SetCalibration records completed tasks in c and uses it to place tasks
+func (m *Manager) SetCalibration(c *cost.Calibration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calibration = c
+}
+
+// SetLinks places tasks counting the network between originID and the
+// devices, as measured in l
+func (m *Manager) SetLinks(l *links.Matrix, originID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.links = l
+	m.origin = originID
+}
+
+// estimator returns a cost estimator that knows locality and what the
+// manager has learned; callers hold m.mu
+func (m *Manager) estimator(locality cost.Locality) *cost.Estimator {
+	estimator := cost.NewEstimator()
+	estimator.SetLocality(locality)
+	estimator.SetCalibration(m.calibration)
+	if m.links != nil {
+		estimator.SetLinks(m.links, m.origin)
+	}
+	return estimator
+}
+
+// SetTaskRunTime records how long the device reported running a task
+func (m *Manager) SetTaskRunTime(jobID, taskID string, ms float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[jobID]
+	if !ok {
+		return
+	}
+	for _, task := range job.Tasks {
+		if task.ID == taskID {
+			task.RunMs = ms
+			break
+		}
+	}
+}
+
+// AddStagedBytes records input bytes copied onto a task's device
+func (m *Manager) AddStagedBytes(jobID, taskID string, n int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[jobID]
+	if !ok {
+		return
+	}
+	for _, task := range job.Tasks {
+		if task.ID == taskID {
+			task.StagedBytes += n
+			break
+		}
+	}
+}
+
+// SetTaskShell records a SHELL task's command outcome
+func (m *Manager) SetTaskShell(jobID, taskID string, shell *pb.ShellResult) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[jobID]
+	if !ok {
+		return
+	}
+	for _, task := range job.Tasks {
+		if task.ID == taskID {
+			task.Shell = shell
 			break
 		}
 	}
@@ -395,25 +498,30 @@ func (m *Manager) SetTaskRunning(jobID, taskID string) {
 // SetJobDone marks a job as done with the final result
 func (m *Manager) SetJobDone(jobID, finalResult string) {
 	m.mu.Lock()
-	defer m.mu.Unlock()
-
+	var ev *Event
 	if job, ok := m.jobs[jobID]; ok {
 		job.State = JobDone
 		job.FinalResult = finalResult
 		job.EndedAt = time.Now()
+		ev = jobEvent(job)
 	}
+	m.mu.Unlock()
+	m.emit(ev)
 }
 
 // SetJobFailed marks a job as failed with an error message
 func (m *Manager) SetJobFailed(jobID, errMsg string) {
 	m.mu.Lock()
-	defer m.mu.Unlock()
-
+	var ev *Event
 	if job, ok := m.jobs[jobID]; ok {
 		job.State = JobFailed
 		job.FinalResult = "Job failed: " + errMsg
 		job.EndedAt = time.Now()
+		ev = jobEvent(job)
+		ev.Error = errMsg
 	}
+	m.mu.Unlock()
+	m.emit(ev)
 }
 
 // SetCurrentGroup updates the current group being executed
@@ -426,6 +534,69 @@ func (m *Manager) SetCurrentGroup(jobID string, groupIndex int) {
 	}
 }
 
+// SetTraceID records the trace a job runs under
+func (m *Manager) SetTraceID(jobID, traceID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if job, ok := m.jobs[jobID]; ok {
+		job.TraceID = traceID
+	}
+}
+
+// SetScheduleID links a job to the schedule that started it
+func (m *Manager) SetScheduleID(jobID, scheduleID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if job, ok := m.jobs[jobID]; ok {
+		job.ScheduleID = scheduleID
+	}
+}
+
+// SetPriority sets a job's priority, which its tasks without their own take
+func (m *Manager) SetPriority(jobID string, priority int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if job, ok := m.jobs[jobID]; ok {
+		job.Priority = priority
+		for _, task := range job.Tasks {
+			if task.Priority == 0 {
+				task.Priority = priority
+			}
+		}
+	}
+}
+
+// RequeueTask puts a preempted task back in the queued state
+func (m *Manager) RequeueTask(jobID, taskID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[jobID]
+	if !ok {
+		return
+	}
+	for _, task := range job.Tasks {
+		if task.ID == taskID {
+			task.State = TaskQueued
+			task.StartedAt = 0
+			task.Preemptions++
+			break
+		}
+	}
+}
+
+// IsActive reports whether a job is queued or running
+func (m *Manager) IsActive(jobID string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	job, ok := m.jobs[jobID]
+	return ok && (job.State == JobQueued || job.State == JobRunning)
+}
+
 // GetTasksForGroup returns all tasks in a specific group
 func (m *Manager) GetTasksForGroup(jobID string, groupIndex int) []*Task {
 	m.mu.RLock()
@@ -561,6 +732,27 @@ func (m *Manager) GetRunningTaskCountByDevice() map[string]int {
 	return counts
 }
 
+// Counts returns how many jobs and tasks are in each state, and how many
+// tasks wait in the queue of each device
+func (m *Manager) Counts() (jobStates map[JobState]int, taskStates map[TaskState]int, queued map[string]int) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	jobStates = make(map[JobState]int)
+	taskStates = make(map[TaskState]int)
+	queued = make(map[string]int)
+	for _, job := range m.jobs {
+		jobStates[job.State]++
+		for _, task := range job.Tasks {
+			taskStates[task.State]++
+			if task.State == TaskQueued && task.DeviceID != "" {
+				queued[task.DeviceID]++
+			}
+		}
+	}
+	return jobStates, taskStates, queued
+}
+
 // GetAllJobs returns all jobs
 func (m *Manager) GetAllJobs() []*Job {
 	m.mu.RLock()
@@ -0,0 +1,147 @@
+package jobs
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/edgecli/edgecli/internal/cost"
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+func TestCreateJobSkipsDevicesWithoutKind(t *testing.T) {
+	devices := []*pb.DeviceInfo{
+		{DeviceId: "sensor", DeviceName: "sensor", TaskKinds: []string{"SYSINFO"}},
+		{DeviceId: "laptop", DeviceName: "laptop", TaskKinds: []string{"SYSINFO", "ECHO"}},
+	}
+	plan := &pb.Plan{Groups: []*pb.TaskGroup{{Index: 0, Tasks: []*pb.TaskSpec{
+		{TaskId: "t1", Kind: "ECHO", Input: "hi"},
+	}}}}
+
+	job, err := NewManager().CreateJob("", devices, 0, plan, nil, cost.Locality{})
+	if err != nil {
+		t.Fatalf("CreateJob failed: %v", err)
+	}
+	if got := job.Tasks[0].DeviceID; got != "laptop" {
+		t.Fatalf("ECHO task assigned to %s, want the device that runs ECHO", got)
+	}
+}
+
+func TestCreateJobRejectsUnsupportedKind(t *testing.T) {
+	devices := []*pb.DeviceInfo{
+		{DeviceId: "sensor", DeviceName: "sensor", TaskKinds: []string{"SYSINFO"}},
+	}
+	for _, spec := range []*pb.TaskSpec{
+		{TaskId: "pinned", Kind: "ECHO", TargetDeviceId: "sensor"},
+		{TaskId: "free", Kind: "ECHO"},
+	} {
+		plan := &pb.Plan{Groups: []*pb.TaskGroup{{Index: 0, Tasks: []*pb.TaskSpec{spec}}}}
+		_, err := NewManager().CreateJob("", devices, 0, plan, nil, cost.Locality{})
+		if !errors.Is(err, ErrUnsupportedKind) {
+			t.Errorf("%s: expected ErrUnsupportedKind, got %v", spec.TaskId, err)
+		}
+	}
+}
+
+func TestSmartPlanRoutesByKind(t *testing.T) {
+	devices := []*pb.DeviceInfo{
+		{DeviceId: "cpu", DeviceName: "cpu"},
+		{DeviceId: "gpu", DeviceName: "gpu", HasGpu: true},
+		{DeviceId: "llm", DeviceName: "llm", HasLocalModel: true},
+	}
+	m := NewManager()
+
+	cases := []struct {
+		text, kind, device string
+	}{
+		{"draw a picture of a cat", "IMAGE_GENERATE", "gpu"},
+		{"summarize this article", "LLM_GENERATE", "llm"},
+	}
+	for _, c := range cases {
+		plan := m.GenerateSmartPlan(c.text, devices)
+		task := plan.Groups[0].Tasks[0]
+		if task.Kind != c.kind || task.TargetDeviceId != c.device {
+			t.Errorf("%q planned %s on %s, want %s on %s", c.text, task.Kind, task.TargetDeviceId, c.kind, c.device)
+		}
+	}
+
+	plan := m.GenerateSmartPlan("how are my devices", devices)
+	if tasks := plan.Groups[0].Tasks; len(tasks) != len(devices) || tasks[0].Kind != "SYSINFO" {
+		t.Fatalf("status request planned %+v, want SYSINFO per device", tasks)
+	}
+}
+
+func TestUpdateTaskRecordsCalibration(t *testing.T) {
+	devices := []*pb.DeviceInfo{{DeviceId: "laptop", DeviceName: "laptop", TaskKinds: []string{"ECHO"}}}
+	plan := &pb.Plan{Groups: []*pb.TaskGroup{{Index: 0, Tasks: []*pb.TaskSpec{
+		{TaskId: "t1", Kind: "ECHO", Input: "hi"},
+	}}}}
+
+	m := NewManager()
+	calibration := cost.NewCalibration()
+	m.SetCalibration(calibration)
+	job, err := m.CreateJob("", devices, 0, plan, nil, cost.Locality{})
+	if err != nil {
+		t.Fatalf("CreateJob failed: %v", err)
+	}
+
+	m.SetTaskRunTime(job.ID, "t1", 42)
+	m.UpdateTask(job.ID, "t1", TaskDone, "hi", "")
+
+	st, ok := calibration.Stats("laptop", "ECHO")
+	if !ok || st.Samples != 1 || st.LatencyMs != 42 {
+		t.Fatalf("stats = %+v, %v; want one 42ms sample", st, ok)
+	}
+}
+
+func TestCounts(t *testing.T) {
+	devices := []*pb.DeviceInfo{{DeviceId: "a", DeviceName: "a"}}
+	plan := &pb.Plan{Groups: []*pb.TaskGroup{{Index: 0, Tasks: []*pb.TaskSpec{
+		{TaskId: "t1", Kind: "ECHO", TargetDeviceId: "a"},
+		{TaskId: "t2", Kind: "ECHO", TargetDeviceId: "a"},
+	}}}}
+
+	m := NewManager()
+	job, err := m.CreateJob("", devices, 0, plan, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.SetJobRunning(job.ID)
+	m.SetTaskRunning(job.ID, job.Tasks[0].ID)
+
+	jobs, tasks, queued := m.Counts()
+	if jobs[JobRunning] != 1 || tasks[TaskRunning] != 1 || tasks[TaskQueued] != 1 {
+		t.Fatalf("jobs=%v tasks=%v", jobs, tasks)
+	}
+	if queued["a"] != 1 {
+		t.Fatalf("queued=%v, want one task queued on a", queued)
+	}
+}
+
+func TestEventHook(t *testing.T) {
+	devices := []*pb.DeviceInfo{{DeviceId: "a", DeviceName: "a"}}
+	plan := &pb.Plan{Groups: []*pb.TaskGroup{{Index: 0, Tasks: []*pb.TaskSpec{
+		{TaskId: "t1", Kind: "ECHO", TargetDeviceId: "a"},
+		{TaskId: "t2", Kind: "ECHO", TargetDeviceId: "a"},
+	}}}}
+
+	m := NewManager()
+	var events []Event
+	m.SetEventHook(func(ev Event) { events = append(events, ev) })
+	job, err := m.CreateJob("", devices, 0, plan, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.UpdateTask(job.ID, job.Tasks[0].ID, TaskDone, "ok", "")
+	m.UpdateTask(job.ID, job.Tasks[1].ID, TaskFailed, "", "boom")
+	m.SetJobDone(job.ID, "ok")
+
+	if len(events) != 2 {
+		t.Fatalf("events = %+v, want a task and a job event", events)
+	}
+	if ev := events[0]; ev.TaskID != job.Tasks[1].ID || ev.State != string(TaskFailed) || ev.DeviceID != "a" || ev.Error != "boom" {
+		t.Fatalf("task event = %+v", ev)
+	}
+	if ev := events[1]; ev.TaskID != "" || ev.State != string(JobDone) || ev.FailedTasks != 1 {
+		t.Fatalf("job event = %+v", ev)
+	}
+}
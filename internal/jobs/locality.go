@@ -0,0 +1,98 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/edgecli/edgecli/internal/cost"
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+// StagingDir holds inputs copied onto a worker, under its shared root.
+// It is a dot-directory so sync and listings skip it.
+const StagingDir = ".staging"
+
+// ErrInputNotFound is returned when a task input is not held by any device.
+var ErrInputNotFound = errors.New("input artifact not found on any device")
+
+// StagedInput is one task input and how it reaches the task's device.
+type StagedInput struct {
+	Artifact     *pb.InputArtifact
+	Source       cost.ArtifactLocation // copy to fetch from
+	LocalPath    string                // path on the task's device, relative to its shared root
+	NeedsStaging bool                  // false when the device already holds a copy
+}
+
+// StagePath returns where a staged copy of an artifact lives on a worker.
+// Content-addressed inputs share one copy per hash.
+func StagePath(a *pb.InputArtifact, source cost.ArtifactLocation) string {
+	name := path.Base(source.Path)
+	if name == "." || name == "/" {
+		name = "input"
+	}
+	if a.Sha256 != "" {
+		return path.Join(StagingDir, a.Sha256, name)
+	}
+	return path.Join(StagingDir, source.DeviceID, path.Clean("/" + source.Path)[1:])
+}
+
+// placeByCost picks the candidate device with the lowest estimated cost
+// for the task, counting the time to stage inputs it does not hold and,
+// with measured links, to send the task and its result over the network.
+// Ties keep candidate order.
+func placeByCost(spec *pb.TaskSpec, candidates []*pb.DeviceInfo, estimator *cost.Estimator) (*pb.DeviceInfo, string) {
+	var best *pb.DeviceInfo
+	var bestStep *pb.StepCostEstimate
+	for _, d := range candidates {
+		step := estimator.EstimateTask(spec, d)
+		if bestStep == nil || step.PredictedMs < bestStep.PredictedMs {
+			best, bestStep = d, step
+		}
+	}
+	if best == nil {
+		return nil, ""
+	}
+	switch {
+	case bestStep.TransferBytes > 0:
+		return best, fmt.Sprintf("lowest cost, stages %d bytes (%.0fms)", bestStep.TransferBytes, bestStep.TransferMs)
+	case len(spec.Inputs) > 0:
+		return best, "inputs local"
+	case bestStep.NetworkMs > 0:
+		return best, fmt.Sprintf("lowest cost, network %.0fms", bestStep.NetworkMs)
+	default:
+		return best, "lowest cost"
+	}
+}
+
+// planInputs decides, for each input, whether the device already holds a
+// copy or where to stage it from.
+func planInputs(spec *pb.TaskSpec, deviceID string, locality cost.Locality) ([]*StagedInput, error) {
+	inputs := make([]*StagedInput, 0, len(spec.Inputs))
+	for _, a := range spec.Inputs {
+		if loc, ok := locality.On(a, deviceID); ok {
+			inputs = append(inputs, &StagedInput{Artifact: a, Source: loc, LocalPath: loc.Path})
+			continue
+		}
+		locs := locality.Lookup(a)
+		if len(locs) == 0 {
+			return nil, fmt.Errorf("%w: %s", ErrInputNotFound, cost.ArtifactKey(a))
+		}
+		inputs = append(inputs, &StagedInput{
+			Artifact:     a,
+			Source:       locs[0],
+			LocalPath:    StagePath(a, locs[0]),
+			NeedsStaging: true,
+		})
+	}
+	return inputs, nil
+}
+
+// InputPaths returns the task's inputs as paths on its device.
+func (t *Task) InputPaths() []string {
+	paths := make([]string, len(t.Inputs))
+	for i, in := range t.Inputs {
+		paths[i] = in.LocalPath
+	}
+	return paths
+}
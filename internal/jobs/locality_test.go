@@ -0,0 +1,100 @@
+package jobs
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/edgecli/edgecli/internal/cost"
+	"github.com/edgecli/edgecli/internal/links"
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+func TestCreateJobPlacesTaskWithItsInput(t *testing.T) {
+	devices := []*pb.DeviceInfo{
+		{DeviceId: "first", DeviceName: "first", Platform: "linux"},
+		{DeviceId: "holder", DeviceName: "holder", Platform: "linux"},
+	}
+	input := &pb.InputArtifact{Sha256: "abc"}
+	locality := cost.Locality{
+		cost.ArtifactKey(input): {{DeviceID: "holder", Path: "data/set.csv", SizeBytes: 1 << 30}},
+	}
+	plan := &pb.Plan{Groups: []*pb.TaskGroup{{Index: 0, Tasks: []*pb.TaskSpec{
+		{TaskId: "t1", Kind: "ECHO", Inputs: []*pb.InputArtifact{input}},
+	}}}}
+
+	job, err := NewManager().CreateJob("", devices, 0, plan, nil, locality)
+	if err != nil {
+		t.Fatalf("CreateJob failed: %v", err)
+	}
+
+	task := job.Tasks[0]
+	if task.DeviceID != "holder" {
+		t.Fatalf("task should run where its input is, got %s", task.DeviceID)
+	}
+	if len(task.Inputs) != 1 || task.Inputs[0].NeedsStaging {
+		t.Fatalf("input should already be local: %+v", task.Inputs)
+	}
+	if got := task.InputPaths(); got[0] != "data/set.csv" {
+		t.Errorf("input path: got %q", got[0])
+	}
+}
+
+func TestCreateJobStagesInputForPinnedTask(t *testing.T) {
+	devices := []*pb.DeviceInfo{
+		{DeviceId: "gpu", DeviceName: "gpu", Platform: "linux"},
+		{DeviceId: "nas", DeviceName: "nas", Platform: "linux"},
+	}
+	input := &pb.InputArtifact{DeviceId: "nas", Path: "videos/clip.mp4"}
+	plan := &pb.Plan{Groups: []*pb.TaskGroup{{Index: 0, Tasks: []*pb.TaskSpec{
+		{TaskId: "t1", Kind: "ECHO", TargetDeviceId: "gpu", Inputs: []*pb.InputArtifact{input}},
+	}}}}
+
+	job, err := NewManager().CreateJob("", devices, 0, plan, nil, cost.Locality{})
+	if err != nil {
+		t.Fatalf("CreateJob failed: %v", err)
+	}
+
+	in := job.Tasks[0].Inputs[0]
+	if !in.NeedsStaging || in.Source.DeviceID != "nas" {
+		t.Fatalf("input should be staged from nas: %+v", in)
+	}
+	if want := ".staging/nas/videos/clip.mp4"; in.LocalPath != want {
+		t.Errorf("staged path: got %q, want %q", in.LocalPath, want)
+	}
+}
+
+func TestCreateJobMissingInput(t *testing.T) {
+	devices := []*pb.DeviceInfo{{DeviceId: "a", DeviceName: "a"}}
+	plan := &pb.Plan{Groups: []*pb.TaskGroup{{Index: 0, Tasks: []*pb.TaskSpec{
+		{TaskId: "t1", Kind: "ECHO", Inputs: []*pb.InputArtifact{{Sha256: "nowhere"}}},
+	}}}}
+
+	_, err := NewManager().CreateJob("", devices, 0, plan, nil, cost.Locality{})
+	if !errors.Is(err, ErrInputNotFound) {
+		t.Fatalf("expected ErrInputNotFound, got %v", err)
+	}
+}
+
+func TestCreateJobCountsNetwork(t *testing.T) {
+	devices := []*pb.DeviceInfo{
+		{DeviceId: "gpu", DeviceName: "gpu", Platform: "linux", LlmPrefillToksPerS: 2000, LlmDecodeToksPerS: 100},
+		{DeviceId: "self", DeviceName: "self", Platform: "linux", LlmPrefillToksPerS: 100, LlmDecodeToksPerS: 10},
+	}
+	plan := &pb.Plan{Groups: []*pb.TaskGroup{{Index: 0, Tasks: []*pb.TaskSpec{
+		{TaskId: "t1", Kind: "LLM_GENERATE", Input: strings.Repeat("x", 1_000_000), PromptTokens: 1000, MaxOutputTokens: 200},
+	}}}}
+
+	m := NewManager()
+	matrix := links.NewMatrix()
+	matrix.Record("self", "gpu", 40, 20_000) // slow Wi-Fi
+	m.SetLinks(matrix, "self")
+
+	job, err := m.CreateJob("", devices, 0, plan, nil, nil)
+	if err != nil {
+		t.Fatalf("CreateJob failed: %v", err)
+	}
+	if task := job.Tasks[0]; task.DeviceID != "self" {
+		t.Fatalf("task should stay off the slow link, got %s (%s)", task.DeviceID, task.Placement)
+	}
+}
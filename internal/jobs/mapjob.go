@@ -0,0 +1,229 @@
+package jobs
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/edgecli/edgecli/internal/tasks"
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+// ReduceMap reassembles MAP shard outputs in item order
+const ReduceMap = "MAP"
+
+// Default items per MAP task; a device's share is split into tasks of at
+// most this many items so each stays well inside the task timeout
+const (
+	DefaultMapChunkGenerate = 8
+	DefaultMapChunkEmbed    = 256
+)
+
+// MapInput describes a data-parallel job: Kind runs once per item
+type MapInput struct {
+	Kind       string   // one of tasks.MapKinds
+	Prompt     string   // LLM_GENERATE template, see tasks.RenderMapPrompt
+	Items      []string // the work, in order
+	Names      []string // optional, one per item
+	ChunkItems int      // max items per task; 0 = the kind's default
+}
+
+// MapItem is one line of a MAP job's final result
+type MapItem struct {
+	Index     int       `json:"index"`
+	Name      string    `json:"name,omitempty"`
+	Output    string    `json:"output,omitempty"`
+	Embedding []float32 `json:"embedding,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Map creates a job that shards in's items across LLM-capable devices in
+// proportion to their throughput. Each device's share is cut into chunks;
+// chunk g of every device runs in group g, so a device runs one chunk at a
+// time. The MAP reducer puts the outputs back in item order.
+func (m *Manager) Map(in MapInput, devices []*pb.DeviceInfo) (*Job, error) {
+	if !tasks.ValidMapKind(in.Kind) {
+		return nil, fmt.Errorf("map kind %q is not one of %s", in.Kind, strings.Join(tasks.MapKinds, ", "))
+	}
+	if len(in.Items) == 0 {
+		return nil, fmt.Errorf("map job has no items")
+	}
+	if len(in.Names) != 0 && len(in.Names) != len(in.Items) {
+		return nil, fmt.Errorf("map job has %d names for %d items", len(in.Names), len(in.Items))
+	}
+
+	candidates := tasks.Default.Filter(devices, "MAP")
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("%w: no device runs MAP", ErrUnsupportedKind)
+	}
+	if llmDevices := tasks.FilterLLMDevices(candidates); len(llmDevices) > 0 {
+		candidates = llmDevices
+	} else {
+		log.Printf("[WARN] Map: no LLM-capable devices, sharding across all %d devices that run MAP", len(candidates))
+	}
+
+	ranked := RankLLMDevices(candidates)
+	weights := make([]float64, len(ranked))
+	for i, d := range ranked {
+		prefill, decode := tasks.DeviceThroughput(d)
+		if in.Kind == "EMBED" {
+			weights[i] = prefill
+		} else {
+			weights[i] = decode
+		}
+	}
+	counts := Apportion(len(in.Items), weights)
+
+	chunk := in.ChunkItems
+	if chunk <= 0 {
+		chunk = DefaultMapChunkGenerate
+		if in.Kind == "EMBED" {
+			chunk = DefaultMapChunkEmbed
+		}
+	}
+
+	var groups []*pb.TaskGroup
+	offset := 0
+	for i, d := range ranked {
+		for g, start := 0, offset; start < offset+counts[i]; g, start = g+1, start+chunk {
+			end := min(start+chunk, offset+counts[i])
+			shard := tasks.MapShard{Kind: in.Kind, Prompt: in.Prompt, Offset: start, Items: in.Items[start:end]}
+			if len(in.Names) > 0 {
+				shard.Names = in.Names[start:end]
+			}
+			input, err := json.Marshal(shard)
+			if err != nil {
+				return nil, fmt.Errorf("encode shard: %w", err)
+			}
+			if g == len(groups) {
+				groups = append(groups, &pb.TaskGroup{Index: int32(g)})
+			}
+			groups[g].Tasks = append(groups[g].Tasks, &pb.TaskSpec{
+				TaskId:         uuid.New().String(),
+				Kind:           "MAP",
+				Input:          string(input),
+				TargetDeviceId: d.DeviceId,
+			})
+		}
+		offset += counts[i]
+	}
+
+	return m.CreateJob("", devices, 0, &pb.Plan{Groups: groups}, &pb.ReduceSpec{Kind: ReduceMap}, nil)
+}
+
+// RankLLMDevices orders devices best first by repeatedly taking
+// tasks.SelectBestLLMDevice of those left
+func RankLLMDevices(devices []*pb.DeviceInfo) []*pb.DeviceInfo {
+	left := append([]*pb.DeviceInfo(nil), devices...)
+	ranked := make([]*pb.DeviceInfo, 0, len(devices))
+	for len(left) > 0 {
+		best := tasks.SelectBestLLMDevice(left)
+		ranked = append(ranked, best)
+		for i, d := range left {
+			if d == best {
+				left = append(left[:i], left[i+1:]...)
+				break
+			}
+		}
+	}
+	return ranked
+}
+
+// Apportion splits n items in proportion to weights by largest remainder;
+// ties go to the earlier weight
+func Apportion(n int, weights []float64) []int {
+	counts := make([]int, len(weights))
+	var total float64
+	for _, w := range weights {
+		total += w
+	}
+	if len(weights) == 0 || total <= 0 {
+		return counts
+	}
+
+	type remainder struct {
+		index int
+		frac  float64
+	}
+	rems := make([]remainder, len(weights))
+	assigned := 0
+	for i, w := range weights {
+		exact := float64(n) * w / total
+		counts[i] = int(math.Floor(exact))
+		assigned += counts[i]
+		rems[i] = remainder{i, exact - float64(counts[i])}
+	}
+	sort.SliceStable(rems, func(a, b int) bool { return rems[a].frac > rems[b].frac })
+	for i := 0; assigned < n; i++ {
+		counts[rems[i%len(rems)].index]++
+		assigned++
+	}
+	return counts
+}
+
+// MapResult reduces a MAP job's tasks with ReduceMapTasks
+func (m *Manager) MapResult(jobID string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	job, ok := m.jobs[jobID]
+	if !ok {
+		return ""
+	}
+	return ReduceMapTasks(job.Tasks)
+}
+
+// ReduceMapTasks reassembles a MAP job's results as JSON lines, one MapItem
+// per item in order. Items of failed or unrun shards carry the task's error.
+func ReduceMapTasks(jobTasks []*Task) string {
+	var items []MapItem
+	for _, t := range jobTasks {
+		var shard tasks.MapShard
+		if err := json.Unmarshal([]byte(t.Input), &shard); err != nil {
+			log.Printf("[WARN] ReduceMapTasks: task %s has no readable shard: %v", t.ID, err)
+			continue
+		}
+
+		var result tasks.MapShardResult
+		taskErr := t.Error
+		if t.State == TaskDone {
+			if err := json.Unmarshal([]byte(t.Result), &result); err != nil {
+				taskErr = fmt.Sprintf("unreadable shard result: %v", err)
+			}
+		} else if taskErr == "" {
+			taskErr = "not run"
+		}
+
+		for i := range shard.Items {
+			item := MapItem{Index: shard.Offset + i}
+			if i < len(shard.Names) {
+				item.Name = shard.Names[i]
+			}
+			switch {
+			case t.State != TaskDone || taskErr != "":
+				item.Error = taskErr
+			case i < len(result.Errors) && result.Errors[i] != "":
+				item.Error = result.Errors[i]
+			case i < len(result.Embeddings):
+				item.Embedding = result.Embeddings[i]
+			case i < len(result.Outputs):
+				item.Output = result.Outputs[i]
+			}
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(a, b int) bool { return items[a].Index < items[b].Index })
+
+	var b strings.Builder
+	for _, item := range items {
+		line, _ := json.Marshal(item)
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
@@ -0,0 +1,98 @@
+package jobs
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/edgecli/edgecli/internal/tasks"
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+func TestApportion(t *testing.T) {
+	cases := []struct {
+		n       int
+		weights []float64
+		want    []int
+	}{
+		{10, []float64{30, 10}, []int{8, 2}}, // 7.5/2.5, tie goes to the first
+		{3, []float64{1, 1, 1}, []int{1, 1, 1}},
+		{2, []float64{1, 1, 1}, []int{1, 1, 0}},
+		{5, []float64{0, 0}, []int{0, 0}},
+	}
+	for _, c := range cases {
+		if got := Apportion(c.n, c.weights); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Apportion(%d, %v) = %v, want %v", c.n, c.weights, got, c.want)
+		}
+	}
+}
+
+// mapKinds is the task_kinds of a device that runs MAP
+var mapKinds = []string{"MAP"}
+
+func TestMapShardsByThroughput(t *testing.T) {
+	devices := []*pb.DeviceInfo{
+		{DeviceId: "slow", DeviceName: "slow", HasLocalModel: true, LlmPrefillToksPerS: 100, LlmDecodeToksPerS: 10, TaskKinds: mapKinds},
+		{DeviceId: "fast", DeviceName: "fast", HasLocalModel: true, HasGpu: true, LlmPrefillToksPerS: 300, LlmDecodeToksPerS: 30, TaskKinds: mapKinds},
+		{DeviceId: "no-llm", DeviceName: "no-llm", TaskKinds: mapKinds},
+	}
+	items := make([]string, 12)
+	for i := range items {
+		items[i] = string(rune('a' + i))
+	}
+
+	job, err := NewManager().Map(MapInput{Kind: "LLM_GENERATE", Prompt: "{{item}}", Items: items, ChunkItems: 4}, devices)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// fast gets 9 items in chunks of 4, 4, 1; slow gets 3; no-llm none
+	perDevice := make(map[string][]int)
+	for _, task := range job.Tasks {
+		var shard tasks.MapShard
+		if err := json.Unmarshal([]byte(task.Input), &shard); err != nil {
+			t.Fatal(err)
+		}
+		perDevice[task.DeviceID] = append(perDevice[task.DeviceID], len(shard.Items))
+		if task.Kind != "MAP" || shard.Items[0] != items[shard.Offset] {
+			t.Errorf("task on %s: kind %s, offset %d starts with %q", task.DeviceID, task.Kind, shard.Offset, shard.Items[0])
+		}
+	}
+	if !reflect.DeepEqual(perDevice, map[string][]int{"fast": {4, 4, 1}, "slow": {3}}) {
+		t.Fatalf("shards per device %v", perDevice)
+	}
+	if job.TotalGroups != 3 || job.ReduceSpec.Kind != ReduceMap {
+		t.Fatalf("groups=%d reduce=%s", job.TotalGroups, job.ReduceSpec.Kind)
+	}
+}
+
+func TestReduceMapTasks(t *testing.T) {
+	shard := func(offset int, items ...string) string {
+		b, _ := json.Marshal(tasks.MapShard{Kind: "LLM_GENERATE", Offset: offset, Items: items, Names: items})
+		return string(b)
+	}
+	result := func(r tasks.MapShardResult) string {
+		b, _ := json.Marshal(r)
+		return string(b)
+	}
+
+	got := ReduceMapTasks([]*Task{
+		{Input: shard(2, "c", "d"), State: TaskDone, Result: result(tasks.MapShardResult{Offset: 2, Outputs: []string{"C", ""}, Errors: []string{"", "timeout"}})},
+		{Input: shard(0, "a", "b"), State: TaskDone, Result: result(tasks.MapShardResult{Outputs: []string{"A", "B"}})},
+		{Input: shard(4, "e"), State: TaskFailed, Error: "device gone"},
+		{Input: shard(5, "f"), State: TaskQueued},
+	})
+
+	want := []string{
+		`{"index":0,"name":"a","output":"A"}`,
+		`{"index":1,"name":"b","output":"B"}`,
+		`{"index":2,"name":"c","output":"C"}`,
+		`{"index":3,"name":"d","error":"timeout"}`,
+		`{"index":4,"name":"e","error":"device gone"}`,
+		`{"index":5,"name":"f","error":"not run"}`,
+	}
+	if lines := strings.Split(strings.TrimSpace(got), "\n"); !reflect.DeepEqual(lines, want) {
+		t.Fatalf("reduced:\n%s", got)
+	}
+}
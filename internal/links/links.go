@@ -0,0 +1,189 @@
+// Package links measures the network links between devices and keeps them
+// as a matrix of round-trip times and throughputs
+package links
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+const (
+	// Alpha weights the newest probe in the moving averages
+	Alpha = 0.3
+
+	// MaxAge is how long a link is trusted after its last successful probe
+	MaxAge = 10 * time.Minute
+)
+
+// Link is the measured network path from one device to another
+type Link struct {
+	From        string
+	To          string
+	RTTMs       float64   // round-trip time, moving average
+	BytesPerSec float64   // bulk throughput, moving average; 0 = unmeasured
+	MeasuredAt  time.Time // last successful probe
+	Samples     int       // successful probes
+	Error       string    // last probe's error, if it failed
+}
+
+// Matrix holds the links between every pair of devices that measured each other
+type Matrix struct {
+	mu    sync.RWMutex
+	links map[[2]string]*Link
+}
+
+// NewMatrix returns an empty matrix
+func NewMatrix() *Matrix {
+	return &Matrix{links: make(map[[2]string]*Link)}
+}
+
+// Record folds a successful probe from one device to another into the
+// link's averages. A zero bytesPerSec keeps the throughput measured before.
+func (m *Matrix) Record(from, to string, rttMs, bytesPerSec float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	l := m.linkLocked(from, to)
+	l.RTTMs = ewma(l.RTTMs, rttMs, l.Samples)
+	if bytesPerSec > 0 {
+		if l.BytesPerSec == 0 {
+			l.BytesPerSec = bytesPerSec
+		} else {
+			l.BytesPerSec = Alpha*bytesPerSec + (1-Alpha)*l.BytesPerSec
+		}
+	}
+	l.Samples++
+	l.MeasuredAt = time.Now()
+	l.Error = ""
+}
+
+// RecordError notes a failed probe; the link's earlier averages are kept
+func (m *Matrix) RecordError(from, to string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.linkLocked(from, to).Error = err.Error()
+}
+
+// Set replaces a link with one measured elsewhere, e.g. reported by its source device
+func (m *Matrix) Set(l Link) {
+	if l.From == "" || l.To == "" {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.links[[2]string{l.From, l.To}] = &l
+}
+
+func (m *Matrix) linkLocked(from, to string) *Link {
+	key := [2]string{from, to}
+	l, ok := m.links[key]
+	if !ok {
+		l = &Link{From: from, To: to}
+		m.links[key] = l
+	}
+	return l
+}
+
+// ewma adds x to an average of n earlier samples; the first sample is taken as is
+func ewma(avg, x float64, n int) float64 {
+	if n == 0 {
+		return x
+	}
+	return Alpha*x + (1-Alpha)*avg
+}
+
+// Get returns the link from one device to another if it was measured
+// successfully within MaxAge
+func (m *Matrix) Get(from, to string) (Link, bool) {
+	if m == nil {
+		return Link{}, false
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	l, ok := m.links[[2]string{from, to}]
+	if !ok || l.Samples == 0 || time.Since(l.MeasuredAt) > MaxAge {
+		return Link{}, false
+	}
+	return *l, true
+}
+
+// From returns the links measured from a device, ordered by destination
+func (m *Matrix) From(from string) []Link {
+	var list []Link
+	for _, l := range m.All() {
+		if l.From == from {
+			list = append(list, l)
+		}
+	}
+	return list
+}
+
+// All returns every link, ordered by source and destination. Links not
+// measured successfully within MaxAge are dropped.
+func (m *Matrix) All() []Link {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list := make([]Link, 0, len(m.links))
+	for key, l := range m.links {
+		if !l.MeasuredAt.IsZero() && time.Since(l.MeasuredAt) > MaxAge {
+			delete(m.links, key)
+			continue
+		}
+		list = append(list, *l)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].From != list[j].From {
+			return list[i].From < list[j].From
+		}
+		return list[i].To < list[j].To
+	})
+	return list
+}
+
+// Proto converts the link to its wire form
+func (l Link) Proto() *pb.LinkStats {
+	s := &pb.LinkStats{
+		FromDeviceId: l.From,
+		ToDeviceId:   l.To,
+		RttMs:        l.RTTMs,
+		BytesPerSec:  l.BytesPerSec,
+		Samples:      int32(l.Samples),
+		Error:        l.Error,
+	}
+	if !l.MeasuredAt.IsZero() {
+		s.MeasuredAtUnixMs = l.MeasuredAt.UnixMilli()
+	}
+	return s
+}
+
+// FromProto converts a link from its wire form
+func FromProto(s *pb.LinkStats) Link {
+	l := Link{
+		From:        s.FromDeviceId,
+		To:          s.ToDeviceId,
+		RTTMs:       s.RttMs,
+		BytesPerSec: s.BytesPerSec,
+		Samples:     int(s.Samples),
+		Error:       s.Error,
+	}
+	if s.MeasuredAtUnixMs > 0 {
+		l.MeasuredAt = time.UnixMilli(s.MeasuredAtUnixMs)
+	}
+	return l
+}
+
+// ToProto converts links to their wire form
+func ToProto(list []Link) []*pb.LinkStats {
+	out := make([]*pb.LinkStats, 0, len(list))
+	for _, l := range list {
+		out = append(out, l.Proto())
+	}
+	return out
+}
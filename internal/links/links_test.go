@@ -0,0 +1,82 @@
+package links
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordAverages(t *testing.T) {
+	m := NewMatrix()
+	m.Record("a", "b", 10, 1e6)
+	m.Record("a", "b", 20, 0) // ping only: throughput kept
+	m.Record("a", "b", 20, 2e6)
+
+	l, ok := m.Get("a", "b")
+	if !ok {
+		t.Fatal("link a->b not found")
+	}
+	// 10 -> 0.3*20+0.7*10 = 13 -> 0.3*20+0.7*13 = 15.1
+	if math.Abs(l.RTTMs-15.1) > 1e-9 {
+		t.Fatalf("rtt = %v, want 15.1", l.RTTMs)
+	}
+	if math.Abs(l.BytesPerSec-1.3e6) > 1e-3 {
+		t.Fatalf("bytes/s = %v, want 1.3e6", l.BytesPerSec)
+	}
+	if l.Samples != 3 {
+		t.Fatalf("samples = %d", l.Samples)
+	}
+	if _, ok := m.Get("b", "a"); ok {
+		t.Fatal("links are directed; b->a was never measured")
+	}
+}
+
+func TestErrorsAndStaleLinks(t *testing.T) {
+	m := NewMatrix()
+	m.RecordError("a", "b", errors.New("unreachable"))
+	if _, ok := m.Get("a", "b"); ok {
+		t.Fatal("a link that never succeeded must not be used")
+	}
+	if all := m.All(); len(all) != 1 || all[0].Error != "unreachable" {
+		t.Fatalf("All() = %+v", all)
+	}
+
+	m.Set(Link{From: "a", To: "c", RTTMs: 5, Samples: 1, MeasuredAt: time.Now().Add(-2 * MaxAge)})
+	if _, ok := m.Get("a", "c"); ok {
+		t.Fatal("stale link returned")
+	}
+	if from := m.From("a"); len(from) != 1 || from[0].To != "b" {
+		t.Fatalf("From(a) = %+v, want only the failing a->b", from)
+	}
+}
+
+func TestProtoRoundTrip(t *testing.T) {
+	in := Link{From: "a", To: "b", RTTMs: 3.5, BytesPerSec: 5e6, Samples: 4, MeasuredAt: time.UnixMilli(1700000000000)}
+	out := FromProto(in.Proto())
+	if out != in {
+		t.Fatalf("round trip = %+v, want %+v", out, in)
+	}
+}
+
+func TestThroughputProbe(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(ProbeHandler))
+	defer srv.Close()
+
+	addr := strings.TrimPrefix(srv.URL, "http://")
+	bps, err := Throughput(context.Background(), addr, 256*1024, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bps <= 0 {
+		t.Fatalf("bytes/s = %v", bps)
+	}
+
+	if _, err := Throughput(context.Background(), addr, -1, 0); err == nil {
+		t.Fatal("expected an error for an invalid probe size")
+	}
+}
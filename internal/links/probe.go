@@ -0,0 +1,105 @@
+package links
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+const (
+	// DefaultProbeBytes is how much a throughput probe downloads
+	DefaultProbeBytes = 1 << 20
+
+	// MaxProbeBytes caps what the probe endpoint will serve
+	MaxProbeBytes = 8 << 20
+
+	// ProbePath is the bulk HTTP endpoint that serves probe payloads
+	ProbePath = "/bulk/probe"
+)
+
+// Ping measures the round-trip time to a peer as the fastest of n Ping calls
+func Ping(ctx context.Context, client pb.OrchestratorServiceClient, selfID string, n int) (float64, error) {
+	best := -1.0
+	var lastErr error
+	for i := 0; i < max(n, 1); i++ {
+		start := time.Now()
+		if _, err := client.Ping(ctx, &pb.PingRequest{DeviceId: selfID}); err != nil {
+			lastErr = err
+			continue
+		}
+		rtt := float64(time.Since(start).Microseconds()) / 1000
+		if best < 0 || rtt < best {
+			best = rtt
+		}
+	}
+	if best < 0 {
+		return 0, fmt.Errorf("ping: %w", lastErr)
+	}
+	return best, nil
+}
+
+// Throughput downloads probeBytes from a peer's bulk HTTP server and returns
+// the bytes per second, not counting one round trip of rttMs
+func Throughput(ctx context.Context, httpAddr string, probeBytes int, rttMs float64) (float64, error) {
+	url := fmt.Sprintf("http://%s%s?bytes=%d", httpAddr, ProbePath, probeBytes)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("throughput probe: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("throughput probe: %s", resp.Status)
+	}
+	n, err := io.Copy(io.Discard, resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("throughput probe: %w", err)
+	}
+	if n == 0 {
+		return 0, fmt.Errorf("throughput probe: empty response")
+	}
+
+	elapsed := time.Since(start).Seconds() - rttMs/1000
+	// On a fast link the transfer can take less than the round trip's jitter
+	elapsed = max(elapsed, 0.001)
+	return float64(n) / elapsed, nil
+}
+
+// ProbeHandler serves ?bytes=N bytes of zeros, up to MaxProbeBytes, for Throughput
+func ProbeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	size := DefaultProbeBytes
+	if v := r.URL.Query().Get("bytes"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "invalid bytes", http.StatusBadRequest)
+			return
+		}
+		size = min(parsed, MaxProbeBytes)
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.Itoa(size))
+	w.Header().Set("Cache-Control", "no-store")
+	buf := make([]byte, 32*1024)
+	for size > 0 {
+		chunk := min(size, len(buf))
+		if _, err := w.Write(buf[:chunk]); err != nil {
+			return
+		}
+		size -= chunk
+	}
+}
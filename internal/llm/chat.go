@@ -56,29 +56,42 @@ type ChatConfig struct {
 //   - CHAT_API_KEY: API key (optional, for OpenAI-compatible providers)
 //   - CHAT_TIMEOUT_SECONDS: request timeout (default: 60)
 func NewChatFromEnv() (ChatProvider, error) {
-	provider := envOrDefault("CHAT_PROVIDER", "ollama")
-	timeoutSecs := envIntOrDefault("CHAT_TIMEOUT_SECONDS", 60)
+	return NewChat(ChatConfigFromEnv())
+}
 
+// ChatConfigFromEnv reads the chat configuration NewChatFromEnv uses,
+// with each provider's defaults filled in.
+func ChatConfigFromEnv() ChatConfig {
 	cfg := ChatConfig{
-		Provider:    provider,
+		Provider:    envOrDefault("CHAT_PROVIDER", "ollama"),
 		APIKey:      os.Getenv("CHAT_API_KEY"),
-		TimeoutSecs: timeoutSecs,
+		TimeoutSecs: envIntOrDefault("CHAT_TIMEOUT_SECONDS", 60),
 	}
 
-	switch provider {
+	switch cfg.Provider {
 	case "ollama":
 		cfg.BaseURL = envOrDefault("CHAT_BASE_URL", "http://localhost:11434")
 		cfg.Model = envOrDefault("CHAT_MODEL", "llama2")
-		return NewOllamaChat(cfg), nil
-
 	case "openai":
 		cfg.BaseURL = envOrDefault("CHAT_BASE_URL", "http://localhost:1234") // LM Studio default
 		cfg.Model = os.Getenv("CHAT_MODEL")                                  // Let the provider use its default if not set
-		return NewOpenAIChat(cfg), nil
-
 	case "tinyllama":
 		cfg.BaseURL = envOrDefault("CHAT_BASE_URL", "http://localhost:3332")
 		cfg.Model = "TinyLlama-1.1B-Chat"
+	}
+	return cfg
+}
+
+// NewChat creates the ChatProvider cfg.Provider names.
+func NewChat(cfg ChatConfig) (ChatProvider, error) {
+	switch cfg.Provider {
+	case "ollama":
+		return NewOllamaChat(cfg), nil
+
+	case "openai":
+		return NewOpenAIChat(cfg), nil
+
+	case "tinyllama":
 		return NewTinyLlamaChat(cfg), nil
 
 	case "echo", "mock":
@@ -86,7 +99,7 @@ func NewChatFromEnv() (ChatProvider, error) {
 		return NewEchoChat(), nil
 
 	default:
-		return nil, fmt.Errorf("unknown chat provider: %s (valid: ollama, openai, tinyllama)", provider)
+		return nil, fmt.Errorf("unknown chat provider: %s (valid: ollama, openai, tinyllama)", cfg.Provider)
 	}
 }
 
@@ -157,12 +170,39 @@ type ollamaChatOptions struct {
 }
 
 // ollamaChatResponse is the response from Ollama's /api/chat endpoint.
+// Durations are in nanoseconds.
 type ollamaChatResponse struct {
-	Message ollamaChatMsg `json:"message"`
-	Done    bool          `json:"done"`
+	Message            ollamaChatMsg `json:"message"`
+	Done               bool          `json:"done"`
+	PromptEvalCount    int           `json:"prompt_eval_count"`
+	PromptEvalDuration int64         `json:"prompt_eval_duration"`
+	EvalCount          int           `json:"eval_count"`
+	EvalDuration       int64         `json:"eval_duration"`
 }
 
 func (o *OllamaChat) Chat(ctx context.Context, messages []ChatMessage) (string, error) {
+	resp, err := o.chat(ctx, messages)
+	if err != nil {
+		return "", err
+	}
+	return resp.Message.Content, nil
+}
+
+// ChatTimed is Chat with Ollama's own prefill and decode timings.
+func (o *OllamaChat) ChatTimed(ctx context.Context, messages []ChatMessage) (string, *ChatTiming, error) {
+	resp, err := o.chat(ctx, messages)
+	if err != nil {
+		return "", nil, err
+	}
+	return resp.Message.Content, &ChatTiming{
+		PromptTokens:   resp.PromptEvalCount,
+		PromptDuration: time.Duration(resp.PromptEvalDuration),
+		OutputTokens:   resp.EvalCount,
+		OutputDuration: time.Duration(resp.EvalDuration),
+	}, nil
+}
+
+func (o *OllamaChat) chat(ctx context.Context, messages []ChatMessage) (*ollamaChatResponse, error) {
 	// Convert messages to Ollama format
 	ollamaMsgs := make([]ollamaChatMsg, len(messages))
 	for i, m := range messages {
@@ -180,25 +220,25 @@ func (o *OllamaChat) Chat(ctx context.Context, messages []ChatMessage) (string,
 
 	bodyBytes, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", fmt.Errorf("marshal request: %w", err)
+		return nil, fmt.Errorf("marshal request: %w", err)
 	}
 
 	url := strings.TrimRight(o.cfg.BaseURL, "/") + "/api/chat"
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(bodyBytes))
 	if err != nil {
-		return "", fmt.Errorf("create request: %w", err)
+		return nil, fmt.Errorf("create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := o.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("http request to %s: %w", url, err)
+		return nil, fmt.Errorf("http request to %s: %w", url, err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("read response body: %w", err)
+		return nil, fmt.Errorf("read response body: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -206,15 +246,15 @@ func (o *OllamaChat) Chat(ctx context.Context, messages []ChatMessage) (string,
 		if len(snippet) > 200 {
 			snippet = snippet[:200] + "..."
 		}
-		return "", fmt.Errorf("Ollama returned status %d: %s", resp.StatusCode, snippet)
+		return nil, fmt.Errorf("Ollama returned status %d: %s", resp.StatusCode, snippet)
 	}
 
 	var chatResp ollamaChatResponse
 	if err := json.Unmarshal(respBody, &chatResp); err != nil {
-		return "", fmt.Errorf("unmarshal response: %w", err)
+		return nil, fmt.Errorf("unmarshal response: %w", err)
 	}
 
-	return chatResp.Message.Content, nil
+	return &chatResp, nil
 }
 
 // OpenAIChat implements ChatProvider using OpenAI-compatible API.
@@ -277,10 +317,16 @@ func (o *OpenAIChat) Health(ctx context.Context) (*HealthResult, error) {
 
 // openaiChatRequest is the request body for /v1/chat/completions.
 type openaiChatRequest struct {
-	Model       string          `json:"model,omitempty"`
-	Messages    []openaiChatMsg `json:"messages"`
-	Temperature float64         `json:"temperature,omitempty"`
-	MaxTokens   int             `json:"max_tokens,omitempty"`
+	Model         string               `json:"model,omitempty"`
+	Messages      []openaiChatMsg      `json:"messages"`
+	Temperature   float64              `json:"temperature,omitempty"`
+	MaxTokens     int                  `json:"max_tokens,omitempty"`
+	Stream        bool                 `json:"stream,omitempty"`
+	StreamOptions *openaiStreamOptions `json:"stream_options,omitempty"`
+}
+
+type openaiStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
 }
 
 type openaiChatMsg struct {
@@ -0,0 +1,240 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// EmbedProvider turns texts into embedding vectors.
+type EmbedProvider interface {
+	// Name returns the provider name (e.g., "ollama", "openai").
+	Name() string
+
+	// Embed returns one vector per text, in order.
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// NewEmbedFromEnv creates an EmbedProvider from environment variables.
+// Each variable falls back to its CHAT_ counterpart, so a worker with a chat
+// provider can embed without extra configuration.
+// Environment variables:
+//   - EMBED_PROVIDER: "ollama" (default), "openai" or "echo"
+//   - EMBED_BASE_URL: base URL (default: http://localhost:11434 for Ollama)
+//   - EMBED_MODEL: model name (default: "nomic-embed-text" for Ollama)
+//   - EMBED_API_KEY: API key (optional, for OpenAI-compatible providers)
+//   - EMBED_TIMEOUT_SECONDS: request timeout (default: 60)
+func NewEmbedFromEnv() (EmbedProvider, error) {
+	provider := envOrDefault("EMBED_PROVIDER", envOrDefault("CHAT_PROVIDER", "ollama"))
+	cfg := ChatConfig{
+		Provider:    provider,
+		BaseURL:     envOrDefault("EMBED_BASE_URL", os.Getenv("CHAT_BASE_URL")),
+		Model:       os.Getenv("EMBED_MODEL"),
+		APIKey:      envOrDefault("EMBED_API_KEY", os.Getenv("CHAT_API_KEY")),
+		TimeoutSecs: envIntOrDefault("EMBED_TIMEOUT_SECONDS", envIntOrDefault("CHAT_TIMEOUT_SECONDS", 60)),
+	}
+
+	switch provider {
+	case "ollama":
+		if cfg.BaseURL == "" {
+			cfg.BaseURL = "http://localhost:11434"
+		}
+		if cfg.Model == "" {
+			cfg.Model = "nomic-embed-text"
+		}
+		return NewOllamaEmbed(cfg), nil
+
+	case "openai":
+		if cfg.BaseURL == "" {
+			cfg.BaseURL = "http://localhost:1234" // LM Studio default
+		}
+		return NewOpenAIEmbed(cfg), nil
+
+	case "echo", "mock":
+		return NewEchoEmbed(), nil
+
+	default:
+		return nil, fmt.Errorf("unknown embed provider: %s (valid: ollama, openai, echo)", provider)
+	}
+}
+
+// OllamaEmbed implements EmbedProvider using Ollama's /api/embed endpoint.
+type OllamaEmbed struct {
+	cfg    ChatConfig
+	client *http.Client
+}
+
+// NewOllamaEmbed creates a new Ollama embed provider.
+func NewOllamaEmbed(cfg ChatConfig) *OllamaEmbed {
+	return &OllamaEmbed{
+		cfg: cfg,
+		client: &http.Client{
+			Timeout: time.Duration(cfg.TimeoutSecs) * time.Second,
+		},
+	}
+}
+
+func (o *OllamaEmbed) Name() string { return "ollama" }
+
+type ollamaEmbedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type ollamaEmbedResponse struct {
+	Embeddings [][]float32 `json:"embeddings"`
+}
+
+func (o *OllamaEmbed) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	url := strings.TrimRight(o.cfg.BaseURL, "/") + "/api/embed"
+	var resp ollamaEmbedResponse
+	if err := postJSON(ctx, o.client, url, "", ollamaEmbedRequest{Model: o.cfg.Model, Input: texts}, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("Ollama returned %d embeddings for %d inputs", len(resp.Embeddings), len(texts))
+	}
+	return resp.Embeddings, nil
+}
+
+// OpenAIEmbed implements EmbedProvider using the OpenAI-compatible
+// /v1/embeddings endpoint.
+type OpenAIEmbed struct {
+	cfg    ChatConfig
+	client *http.Client
+}
+
+// NewOpenAIEmbed creates a new OpenAI-compatible embed provider.
+func NewOpenAIEmbed(cfg ChatConfig) *OpenAIEmbed {
+	return &OpenAIEmbed{
+		cfg: cfg,
+		client: &http.Client{
+			Timeout: time.Duration(cfg.TimeoutSecs) * time.Second,
+		},
+	}
+}
+
+func (o *OpenAIEmbed) Name() string { return "openai" }
+
+type openaiEmbedRequest struct {
+	Model string   `json:"model,omitempty"`
+	Input []string `json:"input"`
+}
+
+type openaiEmbedResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+func (o *OpenAIEmbed) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	url := strings.TrimRight(o.cfg.BaseURL, "/") + "/v1/embeddings"
+	var resp openaiEmbedResponse
+	if err := postJSON(ctx, o.client, url, o.cfg.APIKey, openaiEmbedRequest{Model: o.cfg.Model, Input: texts}, &resp); err != nil {
+		return nil, err
+	}
+
+	// The API may return data out of order; index says which input it is
+	vectors := make([][]float32, len(texts))
+	for _, d := range resp.Data {
+		if d.Index < 0 || d.Index >= len(texts) {
+			return nil, fmt.Errorf("API returned embedding index %d for %d inputs", d.Index, len(texts))
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	for i, v := range vectors {
+		if v == nil {
+			return nil, fmt.Errorf("API returned no embedding for input %d", i)
+		}
+	}
+	return vectors, nil
+}
+
+// EchoEmbedDims is the length of EchoEmbed's vectors.
+const EchoEmbedDims = 8
+
+// EchoEmbed is a mock provider for testing when no embedding model is
+// available. Its vectors are derived from a hash of the text, so equal texts
+// get equal vectors.
+type EchoEmbed struct{}
+
+// NewEchoEmbed creates a new echo embed provider.
+func NewEchoEmbed() *EchoEmbed {
+	return &EchoEmbed{}
+}
+
+func (e *EchoEmbed) Name() string { return "echo" }
+
+func (e *EchoEmbed) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		h := fnv.New64a()
+		h.Write([]byte(text))
+		seed := h.Sum64()
+
+		v := make([]float32, EchoEmbedDims)
+		var norm float64
+		for d := range v {
+			seed = seed*6364136223846793005 + 1442695040888963407
+			v[d] = float32(int32(seed>>32)) / math.MaxInt32
+			norm += float64(v[d]) * float64(v[d])
+		}
+		if norm > 0 {
+			for d := range v {
+				v[d] /= float32(math.Sqrt(norm))
+			}
+		}
+		vectors[i] = v
+	}
+	return vectors, nil
+}
+
+// postJSON posts body as JSON to url and decodes a 200 response into out.
+func postJSON(ctx context.Context, client *http.Client, url, apiKey string, body, out interface{}) error {
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(bodyBytes))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("http request to %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		snippet := string(respBody)
+		if len(snippet) > 200 {
+			snippet = snippet[:200] + "..."
+		}
+		return fmt.Errorf("%s returned status %d: %s", url, resp.StatusCode, snippet)
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("unmarshal response: %w", err)
+	}
+	return nil
+}
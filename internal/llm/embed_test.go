@@ -0,0 +1,63 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestOllamaEmbed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaEmbedRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if r.URL.Path != "/api/embed" || req.Model != "nomic-embed-text" || len(req.Input) != 2 {
+			t.Errorf("unexpected request %s %+v", r.URL.Path, req)
+		}
+		w.Write([]byte(`{"embeddings":[[1,0],[0,1]]}`))
+	}))
+	defer srv.Close()
+
+	got, err := NewOllamaEmbed(ChatConfig{BaseURL: srv.URL, Model: "nomic-embed-text", TimeoutSecs: 5}).
+		Embed(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, [][]float32{{1, 0}, {0, 1}}) {
+		t.Fatalf("got %v", got)
+	}
+}
+
+func TestOpenAIEmbedOrdersByIndex(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/embeddings" || r.Header.Get("Authorization") != "Bearer k" {
+			t.Errorf("unexpected request %s auth=%q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		w.Write([]byte(`{"data":[{"index":1,"embedding":[2]},{"index":0,"embedding":[1]}]}`))
+	}))
+	defer srv.Close()
+
+	got, err := NewOpenAIEmbed(ChatConfig{BaseURL: srv.URL, APIKey: "k", TimeoutSecs: 5}).
+		Embed(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, [][]float32{{1}, {2}}) {
+		t.Fatalf("got %v", got)
+	}
+
+	if _, err := NewOpenAIEmbed(ChatConfig{BaseURL: srv.URL, APIKey: "k", TimeoutSecs: 5}).
+		Embed(context.Background(), []string{"a", "b", "c"}); err == nil {
+		t.Fatal("expected an error when an input has no embedding")
+	}
+}
+
+func TestEchoEmbedIsDeterministic(t *testing.T) {
+	e := NewEchoEmbed()
+	a, _ := e.Embed(context.Background(), []string{"same", "same", "other"})
+	if !reflect.DeepEqual(a[0], a[1]) || reflect.DeepEqual(a[0], a[2]) || len(a[0]) != EchoEmbedDims {
+		t.Fatalf("vectors %v", a)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/edgecli/edgecli/internal/tasks"
 	pb "github.com/edgecli/edgecli/proto"
 )
 
@@ -32,14 +33,6 @@ type reduceJSON struct {
 	Kind string `json:"kind"`
 }
 
-// validKinds are the allowed task kinds.
-var validKinds = map[string]bool{
-	"SYSINFO":        true,
-	"ECHO":           true,
-	"LLM_GENERATE":   true,
-	"IMAGE_GENERATE": true,
-}
-
 // ParsePlanJSON parses and validates raw LLM output into proto Plan and ReduceSpec.
 func ParsePlanJSON(raw string) (*pb.Plan, *pb.ReduceSpec, error) {
 	// Strip markdown code fences if present
@@ -70,8 +63,8 @@ func ParsePlanJSON(raw string) (*pb.Plan, *pb.ReduceSpec, error) {
 			seenIDs[t.TaskID] = true
 
 			kind := strings.ToUpper(t.Kind)
-			if !validKinds[kind] {
-				return nil, nil, fmt.Errorf("group %d task %d (%s): invalid kind %q (allowed: SYSINFO, ECHO, LLM_GENERATE, IMAGE_GENERATE)", gi, ti, t.TaskID, t.Kind)
+			if _, ok := tasks.Get(kind); !ok {
+				return nil, nil, fmt.Errorf("group %d task %d (%s): invalid kind %q (allowed: %s)", gi, ti, t.TaskID, t.Kind, strings.Join(tasks.Kinds(), ", "))
 			}
 
 			// Validate input for path traversal
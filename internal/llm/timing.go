@@ -0,0 +1,150 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ChatTiming is how long one chat completion spent reading the prompt
+// (prefill) and generating the reply (decode).
+type ChatTiming struct {
+	PromptTokens   int
+	PromptDuration time.Duration
+	OutputTokens   int // tokens generated during OutputDuration
+	OutputDuration time.Duration
+}
+
+// TimedChatProvider is a ChatProvider that can report prefill and decode
+// timings, which benchmarks use instead of wall-clock estimates.
+type TimedChatProvider interface {
+	ChatProvider
+	ChatTimed(ctx context.Context, messages []ChatMessage) (string, *ChatTiming, error)
+}
+
+// openaiStreamChunk is one server-sent event of a streamed completion.
+type openaiStreamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// ChatTimed streams the completion. The time to the first token is the
+// prefill; the rest of the stream is the decode. Token counts come from
+// the final usage chunk, or are estimated if the server sends none.
+func (o *OpenAIChat) ChatTimed(ctx context.Context, messages []ChatMessage) (string, *ChatTiming, error) {
+	openaiMsgs := make([]openaiChatMsg, len(messages))
+	promptChars := 0
+	for i, m := range messages {
+		openaiMsgs[i] = openaiChatMsg{Role: m.Role, Content: m.Content}
+		promptChars += len(m.Content)
+	}
+
+	reqBody := openaiChatRequest{
+		Model:         o.cfg.Model,
+		Messages:      openaiMsgs,
+		Temperature:   0.7,
+		MaxTokens:     1024,
+		Stream:        true,
+		StreamOptions: &openaiStreamOptions{IncludeUsage: true},
+	}
+	bodyBytes, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	url := strings.TrimRight(o.cfg.BaseURL, "/") + "/v1/chat/completions"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(bodyBytes))
+	if err != nil {
+		return "", nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if o.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.cfg.APIKey)
+	}
+
+	start := time.Now()
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return "", nil, fmt.Errorf("http request to %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		snippet := string(respBody)
+		if len(snippet) > 200 {
+			snippet = snippet[:200] + "..."
+		}
+		return "", nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, snippet)
+	}
+
+	var content strings.Builder
+	var firstToken time.Time
+	chunks := 0
+	var promptTokens, outputTokens int
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+		var chunk openaiStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return "", nil, fmt.Errorf("unmarshal stream chunk: %w", err)
+		}
+		for _, c := range chunk.Choices {
+			if c.Delta.Content == "" {
+				continue
+			}
+			if firstToken.IsZero() {
+				firstToken = time.Now()
+			}
+			chunks++
+			content.WriteString(c.Delta.Content)
+		}
+		if chunk.Usage != nil {
+			promptTokens, outputTokens = chunk.Usage.PromptTokens, chunk.Usage.CompletionTokens
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", nil, fmt.Errorf("read stream: %w", err)
+	}
+	end := time.Now()
+
+	if firstToken.IsZero() {
+		return "", nil, fmt.Errorf("API streamed no content")
+	}
+	if promptTokens == 0 {
+		promptTokens = promptChars / 4
+	}
+	if outputTokens == 0 {
+		outputTokens = chunks
+	}
+
+	// The first token came out of the prefill, so the decode made the rest
+	return content.String(), &ChatTiming{
+		PromptTokens:   promptTokens,
+		PromptDuration: firstToken.Sub(start),
+		OutputTokens:   max(outputTokens-1, 0),
+		OutputDuration: end.Sub(firstToken),
+	}, nil
+}
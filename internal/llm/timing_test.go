@@ -0,0 +1,37 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOpenAIChatTimedStreams(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openaiChatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if !req.Stream || req.StreamOptions == nil || !req.StreamOptions.IncludeUsage {
+			t.Errorf("request did not ask for a stream with usage: %+v", req)
+		}
+		w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"Hel\"}}]}\n\n" +
+			"data: {\"choices\":[{\"delta\":{\"content\":\"lo\"}}]}\n\n" +
+			"data: {\"choices\":[],\"usage\":{\"prompt_tokens\":12,\"completion_tokens\":5}}\n\n" +
+			"data: [DONE]\n\n"))
+	}))
+	defer srv.Close()
+
+	reply, timing, err := NewOpenAIChat(ChatConfig{BaseURL: srv.URL, TimeoutSecs: 5}).
+		ChatTimed(context.Background(), []ChatMessage{{Role: "user", Content: "hi"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply != "Hello" {
+		t.Fatalf("reply = %q", reply)
+	}
+	// The first of the 5 completion tokens belongs to the prefill
+	if timing.PromptTokens != 12 || timing.OutputTokens != 4 {
+		t.Fatalf("timing = %+v", timing)
+	}
+}
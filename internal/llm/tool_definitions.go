@@ -109,6 +109,43 @@ var ToolDefinitions = []ToolDefinition{
 			}`),
 		},
 	},
+	{
+		Type: "function",
+		Function: FunctionDef{
+			Name:        "list_files",
+			Description: "List files and directories in a device's shared folder with sizes, modification times and types. Paths are relative to the shared folder and cannot escape it. Use this to discover file paths before calling get_file.",
+			Parameters: json.RawMessage(`{
+				"type": "object",
+				"properties": {
+					"device_id": {
+						"type": "string",
+						"description": "Target device ID from get_capabilities. Leave empty to list the local/coordinator device."
+					},
+					"path": {
+						"type": "string",
+						"description": "Directory relative to the shared folder (empty for the top level)",
+						"default": ""
+					},
+					"glob": {
+						"type": "string",
+						"description": "Filter entry names with a glob pattern (e.g., '*.csv', 'model-*')"
+					},
+					"page_size": {
+						"type": "integer",
+						"description": "Maximum entries to return",
+						"default": 100,
+						"minimum": 1,
+						"maximum": 1000
+					},
+					"page_token": {
+						"type": "string",
+						"description": "next_page_token from a previous list_files call to fetch the next page"
+					}
+				},
+				"required": []
+			}`),
+		},
+	},
 }
 
 // GetToolDefinitions returns a copy of all tool definitions
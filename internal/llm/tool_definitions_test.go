@@ -34,7 +34,7 @@ func TestToolDefinitions_Valid(t *testing.T) {
 }
 
 func TestToolDefinitions_ExpectedTools(t *testing.T) {
-	expected := []string{"get_capabilities", "execute_shell_cmd", "get_file"}
+	expected := []string{"get_capabilities", "execute_shell_cmd", "get_file", "list_files"}
 
 	for _, name := range expected {
 		found := false
@@ -90,6 +90,7 @@ func TestListToolNames(t *testing.T) {
 		"get_capabilities":  true,
 		"execute_shell_cmd": true,
 		"get_file":          true,
+		"list_files":        true,
 	}
 
 	for _, name := range names {
@@ -190,3 +191,26 @@ func TestGetFileSchema(t *testing.T) {
 		}
 	}
 }
+
+func TestListFilesSchema(t *testing.T) {
+	tool := GetToolByName("list_files")
+	if tool == nil {
+		t.Fatal("list_files tool not found")
+	}
+
+	var params map[string]interface{}
+	if err := json.Unmarshal(tool.Function.Parameters, &params); err != nil {
+		t.Fatalf("Failed to parse list_files parameters: %v", err)
+	}
+
+	props, ok := params["properties"].(map[string]interface{})
+	if !ok {
+		t.Fatal("list_files should have properties")
+	}
+
+	for _, name := range []string{"device_id", "path", "glob", "page_size", "page_token"} {
+		if _, ok := props[name]; !ok {
+			t.Errorf("list_files should have %s property", name)
+		}
+	}
+}
@@ -99,6 +99,8 @@ func (e *ToolExecutor) Execute(ctx context.Context, toolCall ToolCall) (interfac
 		return e.executeShellCmd(ctx, toolCall.Function.Arguments)
 	case "get_file":
 		return e.executeGetFile(ctx, toolCall.Function.Arguments)
+	case "list_files":
+		return e.executeListFiles(ctx, toolCall.Function.Arguments)
 	default:
 		return nil, fmt.Errorf("unknown tool: %s", toolCall.Function.Name)
 	}
@@ -402,3 +404,90 @@ func (e *ToolExecutor) executeGetFile(ctx context.Context, argsJSON string) (int
 		ContentPreview: resp.ContentPreview,
 	}, nil
 }
+
+// ListFilesParams defines parameters for list_files
+type ListFilesParams struct {
+	DeviceID  string `json:"device_id"`
+	Path      string `json:"path"`
+	Glob      string `json:"glob"`
+	PageSize  int    `json:"page_size"`
+	PageToken string `json:"page_token"`
+}
+
+// ListFilesEntry is a single entry returned by list_files
+type ListFilesEntry struct {
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	Type      string `json:"type"`
+	SizeBytes int64  `json:"size_bytes"`
+	Modified  string `json:"modified"`
+}
+
+// ListFilesResult is the result of list_files
+type ListFilesResult struct {
+	DeviceID      string           `json:"device_id,omitempty"`
+	Path          string           `json:"path"`
+	Entries       []ListFilesEntry `json:"entries"`
+	TotalMatches  int32            `json:"total_matches"`
+	NextPageToken string           `json:"next_page_token,omitempty"`
+	Error         string           `json:"error,omitempty"`
+}
+
+func (e *ToolExecutor) executeListFiles(ctx context.Context, argsJSON string) (interface{}, error) {
+	var params ListFilesParams
+	if argsJSON != "" {
+		if err := json.Unmarshal([]byte(argsJSON), &params); err != nil {
+			return nil, fmt.Errorf("invalid parameters: %w", err)
+		}
+	}
+
+	req := &pb.ListDirRequest{
+		SessionId: e.sessionID,
+		DeviceId:  params.DeviceID,
+		Path:      params.Path,
+		Glob:      params.Glob,
+		PageSize:  int32(params.PageSize),
+		PageToken: params.PageToken,
+	}
+
+	// Call ListDir RPC with session retry
+	resp, err := e.client.ListDir(ctx, req)
+	if err != nil && e.refreshSessionOnError(ctx, err) {
+		req.SessionId = e.sessionID
+		resp, err = e.client.ListDir(ctx, req)
+	}
+
+	if err != nil {
+		return ListFilesResult{
+			Path:  params.Path,
+			Error: fmt.Sprintf("RPC failed: %v", err),
+		}, nil
+	}
+
+	if resp.Error != "" {
+		return ListFilesResult{
+			DeviceID: params.DeviceID,
+			Path:     params.Path,
+			Error:    resp.Error,
+		}, nil
+	}
+
+	entries := make([]ListFilesEntry, 0, len(resp.Entries))
+	for _, entry := range resp.Entries {
+		entries = append(entries, ListFilesEntry{
+			Name:      entry.Name,
+			Path:      entry.Path,
+			Type:      entry.Type,
+			SizeBytes: entry.SizeBytes,
+			Modified:  time.UnixMilli(entry.ModTimeUnixMs).UTC().Format(time.RFC3339),
+		})
+	}
+
+	return ListFilesResult{
+		DeviceID:      params.DeviceID,
+		Path:          resp.Path,
+		Entries:       entries,
+		TotalMatches:  resp.TotalMatches,
+		NextPageToken: resp.NextPageToken,
+	}, nil
+}
@@ -0,0 +1,432 @@
+package metrics
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// RawRetention is how long the archive keeps every sample
+	RawRetention = 15 * time.Minute
+
+	// MinuteRetention is how long it keeps one-minute rollups
+	MinuteRetention = 7 * 24 * time.Hour
+
+	// HourRetention is how long it keeps hourly rollups
+	HourRetention = 180 * 24 * time.Hour
+
+	// MaxQueryPoints bounds the points of a query with automatic resolution
+	MaxQueryPoints = 1000
+
+	// SaveInterval is how often the archive is written to disk
+	SaveInterval = 5 * time.Minute
+)
+
+// Field indexes into Point.Aggs
+const (
+	FieldCPULoad = iota
+	FieldMemUsedMB
+	FieldMemTotalMB
+	FieldGPULoad
+	FieldGPUMemUsedMB
+	FieldGPUMemTotalMB
+	FieldNPULoad
+	NumFields
+)
+
+// FieldNames names each field as MetricsSample's JSON does
+var FieldNames = [NumFields]string{
+	"cpu_load", "mem_used_mb", "mem_total_mb", "gpu_load", "gpu_mem_used_mb", "gpu_mem_total_mb", "npu_load",
+}
+
+// Agg summarises one field's values within a bucket
+type Agg struct {
+	N   int     `json:"n"`
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+	Sum float64 `json:"sum"`
+}
+
+func (a *Agg) add(v float64) {
+	a.merge(Agg{N: 1, Min: v, Max: v, Sum: v})
+}
+
+func (a *Agg) merge(b Agg) {
+	if b.N == 0 {
+		return
+	}
+	if a.N == 0 {
+		*a = b
+		return
+	}
+	a.N += b.N
+	a.Min = min(a.Min, b.Min)
+	a.Max = max(a.Max, b.Max)
+	a.Sum += b.Sum
+}
+
+// Avg returns the mean value, or 0 without values
+func (a Agg) Avg() float64 {
+	if a.N == 0 {
+		return 0
+	}
+	return a.Sum / float64(a.N)
+}
+
+// Point aggregates a device's samples from StartMs over one resolution step.
+// A raw point holds a single sample.
+type Point struct {
+	StartMs int64          `json:"t"`
+	Aggs    [NumFields]Agg `json:"a"`
+}
+
+func sampleAggs(s MetricsSample) [NumFields]Agg {
+	var aggs [NumFields]Agg
+	values := [NumFields]float64{
+		s.CPULoad, float64(s.MemUsedMB), float64(s.MemTotalMB),
+		s.GPULoad, float64(s.GPUMemUsedMB), float64(s.GPUMemTotalMB), s.NPULoad,
+	}
+	for i, v := range values {
+		// Negative loads mean the device cannot measure them
+		if v >= 0 {
+			aggs[i].add(v)
+		}
+	}
+	return aggs
+}
+
+func (p *Point) merge(aggs [NumFields]Agg) {
+	for i := range p.Aggs {
+		p.Aggs[i].merge(aggs[i])
+	}
+}
+
+// tier holds points of one resolution for as long as it keeps them
+type tier struct {
+	step   time.Duration // 0 = raw samples
+	keep   time.Duration
+	Points []Point
+}
+
+// add folds a sample taken at ms into the tier
+func (t *tier) add(ms int64, aggs [NumFields]Agg) {
+	start := ms
+	if t.step > 0 {
+		start -= start % t.step.Milliseconds()
+	}
+	if n := len(t.Points); n > 0 && t.Points[n-1].StartMs == start && t.step > 0 {
+		t.Points[n-1].merge(aggs)
+		return
+	}
+	t.Points = append(t.Points, Point{StartMs: start, Aggs: aggs})
+}
+
+// prune drops points that ended before the tier's retention
+func (t *tier) prune(now time.Time) {
+	cutoff := now.Add(-t.keep).UnixMilli() - t.step.Milliseconds()
+	i := sort.Search(len(t.Points), func(i int) bool { return t.Points[i].StartMs >= cutoff })
+	t.Points = t.Points[i:]
+}
+
+// covers reports whether the tier still holds data from ms on
+func (t *tier) covers(ms int64, now time.Time) bool {
+	return ms >= now.Add(-t.keep).UnixMilli()
+}
+
+// series is one device's tiers, finest first
+type series struct {
+	name   string
+	lastMs int64
+	tiers  [3]*tier
+}
+
+func newSeries(name string) *series {
+	return &series{name: name, tiers: [3]*tier{
+		{step: 0, keep: RawRetention},
+		{step: time.Minute, keep: MinuteRetention},
+		{step: time.Hour, keep: HourRetention},
+	}}
+}
+
+// Archive keeps long-term metrics history per device in tiers: raw samples
+// for minutes, one-minute rollups for days and hourly rollups for months.
+// Each sample is folded into every tier as it arrives.
+type Archive struct {
+	mu      sync.Mutex
+	path    string // "" = kept in memory only
+	devices map[string]*series
+	dirty   bool
+}
+
+// DefaultArchivePath returns ~/.edgemesh/metrics-history.json.gz
+func DefaultArchivePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".edgemesh", "metrics-history.json.gz"), nil
+}
+
+// NewArchive returns an empty archive kept in memory
+func NewArchive() *Archive {
+	return &Archive{devices: make(map[string]*series)}
+}
+
+// archiveFile is the on-disk form of an archive
+type archiveFile struct {
+	Devices []archiveDevice `json:"devices"`
+}
+
+type archiveDevice struct {
+	ID     string  `json:"device_id"`
+	Name   string  `json:"device_name"`
+	Raw    []Point `json:"raw"`
+	Minute []Point `json:"minute"`
+	Hour   []Point `json:"hour"`
+}
+
+// OpenArchive loads the archive at path, which Save writes back. A missing
+// file starts empty.
+func OpenArchive(path string) (*Archive, error) {
+	a := NewArchive()
+	a.path = path
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return a, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	var file archiveFile
+	if err := json.NewDecoder(zr).Decode(&file); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	now := time.Now()
+	for _, d := range file.Devices {
+		s := newSeries(d.Name)
+		for i, points := range [][]Point{d.Raw, d.Minute, d.Hour} {
+			s.tiers[i].Points = points
+			s.tiers[i].prune(now)
+			if n := len(points); n > 0 {
+				s.lastMs = max(s.lastMs, points[n-1].StartMs)
+			}
+		}
+		a.devices[d.ID] = s
+	}
+	return a, nil
+}
+
+// Add archives a device's sample. Samples older than the device's latest
+// are dropped, since rollups only grow at their end.
+func (a *Archive) Add(deviceID, deviceName string, sample MetricsSample) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	s, ok := a.devices[deviceID]
+	if !ok {
+		s = newSeries(deviceName)
+		a.devices[deviceID] = s
+	}
+	if deviceName != "" {
+		s.name = deviceName
+	}
+	if sample.Timestamp < s.lastMs {
+		return
+	}
+	s.lastMs = sample.Timestamp
+
+	aggs := sampleAggs(sample)
+	now := time.Now()
+	for _, t := range s.tiers {
+		t.add(sample.Timestamp, aggs)
+		t.prune(now)
+	}
+	a.dirty = true
+}
+
+// Range is the result of a query
+type Range struct {
+	DeviceID   string
+	DeviceName string
+	Resolution time.Duration // 0 = raw samples
+	Points     []Point
+}
+
+// Query returns a device's points from from up to to. It reads the finest
+// tier that still covers from, then merges points into buckets of
+// resolution, which is raised to at least that tier's step. A zero
+// resolution picks the tier's step, coarsened to at most MaxQueryPoints
+// points. ok is false for a device the archive has never seen.
+func (a *Archive) Query(deviceID string, from, to time.Time, resolution time.Duration) (r Range, ok bool) {
+	if a == nil {
+		return Range{}, false
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	s, ok := a.devices[deviceID]
+	if !ok {
+		return Range{}, false
+	}
+	r = Range{DeviceID: deviceID, DeviceName: s.name}
+
+	now := time.Now()
+	fromMs, toMs := from.UnixMilli(), to.UnixMilli()
+	t := s.tiers[len(s.tiers)-1]
+	for _, candidate := range s.tiers {
+		if candidate.covers(fromMs, now) {
+			t = candidate
+			break
+		}
+	}
+
+	if resolution <= 0 {
+		resolution = t.step
+		if span := to.Sub(from); span/MaxQueryPoints > max(resolution, time.Second) {
+			resolution = roundUpStep(span / MaxQueryPoints)
+		}
+	}
+	resolution = max(resolution, t.step)
+	r.Resolution = resolution
+
+	i := sort.Search(len(t.Points), func(i int) bool { return t.Points[i].StartMs+t.step.Milliseconds() > fromMs })
+	for ; i < len(t.Points) && t.Points[i].StartMs <= toMs; i++ {
+		p := t.Points[i]
+		if resolution == 0 {
+			r.Points = append(r.Points, p)
+			continue
+		}
+		start := p.StartMs - p.StartMs%resolution.Milliseconds()
+		if n := len(r.Points); n > 0 && r.Points[n-1].StartMs == start {
+			r.Points[n-1].merge(p.Aggs)
+			continue
+		}
+		r.Points = append(r.Points, Point{StartMs: start, Aggs: p.Aggs})
+	}
+	return r, true
+}
+
+// roundUpStep rounds d up to a whole second, minute or hour
+func roundUpStep(d time.Duration) time.Duration {
+	for _, unit := range []time.Duration{time.Hour, time.Minute, time.Second} {
+		if d >= unit {
+			return (d + unit - 1) / unit * unit
+		}
+	}
+	return time.Second
+}
+
+// Save writes the archive to its file, if it has one and has changed
+func (a *Archive) Save() error {
+	a.mu.Lock()
+	if a.path == "" || !a.dirty {
+		a.mu.Unlock()
+		return nil
+	}
+	now := time.Now()
+	file := archiveFile{Devices: make([]archiveDevice, 0, len(a.devices))}
+	for id, s := range a.devices {
+		for _, t := range s.tiers {
+			t.prune(now)
+		}
+		if len(s.tiers[2].Points) == 0 {
+			// Nothing heard from the device for months
+			delete(a.devices, id)
+			continue
+		}
+		// Copies, since Add keeps appending to the tiers
+		file.Devices = append(file.Devices, archiveDevice{
+			ID:     id,
+			Name:   s.name,
+			Raw:    append([]Point(nil), s.tiers[0].Points...),
+			Minute: append([]Point(nil), s.tiers[1].Points...),
+			Hour:   append([]Point(nil), s.tiers[2].Points...),
+		})
+	}
+	a.dirty = false
+	a.mu.Unlock()
+
+	sort.Slice(file.Devices, func(i, j int) bool { return file.Devices[i].ID < file.Devices[j].ID })
+	if err := a.write(file); err != nil {
+		a.mu.Lock()
+		a.dirty = true
+		a.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (a *Archive) write(file archiveFile) error {
+	if err := os.MkdirAll(filepath.Dir(a.path), 0o700); err != nil {
+		return fmt.Errorf("create metrics history dir: %w", err)
+	}
+	tmp := a.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(f)
+	err = json.NewEncoder(zw).Encode(file)
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, a.path)
+}
+
+// SaveLoop saves the archive every interval until ctx is done, then once more
+func (a *Archive) SaveLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if err := a.Save(); err != nil {
+				log.Printf("[WARN] Metrics history: save failed: %v", err)
+			}
+			return
+		case <-ticker.C:
+			if err := a.Save(); err != nil {
+				log.Printf("[WARN] Metrics history: save failed: %v", err)
+			}
+		}
+	}
+}
+
+// DeviceIDs returns the archived devices, sorted
+func (a *Archive) DeviceIDs() []string {
+	if a == nil {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	ids := make([]string, 0, len(a.devices))
+	for id := range a.devices {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestArchiveRollups(t *testing.T) {
+	a := NewArchive()
+	now := time.Now().Truncate(time.Hour).Add(-30 * time.Minute)
+	// Two samples in one minute, one in the next; the NPU is unmeasured once
+	for i, s := range []MetricsSample{
+		{Timestamp: now.UnixMilli(), CPULoad: 0.2, NPULoad: 0.5, MemUsedMB: 100},
+		{Timestamp: now.Add(20 * time.Second).UnixMilli(), CPULoad: 0.6, NPULoad: -1, MemUsedMB: 300},
+		{Timestamp: now.Add(70 * time.Second).UnixMilli(), CPULoad: 1.0, NPULoad: 0.1, MemUsedMB: 200},
+	} {
+		a.Add("dev-a", "mac", s)
+		if i == 2 {
+			// Out of order: dropped
+			a.Add("dev-a", "mac", MetricsSample{Timestamp: now.UnixMilli(), CPULoad: 9})
+		}
+	}
+
+	// Older than raw retention, so one-minute rollups
+	r, ok := a.Query("dev-a", now.Add(-time.Hour), now.Add(time.Hour), 0)
+	if !ok || r.Resolution != time.Minute || len(r.Points) != 2 {
+		t.Fatalf("minute query = %+v, %v", r, ok)
+	}
+	cpu := r.Points[0].Aggs[FieldCPULoad]
+	if cpu.N != 2 || cpu.Min != 0.2 || cpu.Max != 0.6 || cpu.Avg() != 0.4 {
+		t.Fatalf("first minute cpu = %+v", cpu)
+	}
+	if npu := r.Points[0].Aggs[FieldNPULoad]; npu.N != 1 || npu.Avg() != 0.5 {
+		t.Fatalf("unmeasured NPU counted: %+v", npu)
+	}
+
+	// An hourly resolution merges the minutes
+	r, _ = a.Query("dev-a", now.Add(-time.Hour), now.Add(time.Hour), time.Hour)
+	if len(r.Points) != 1 || r.Points[0].Aggs[FieldCPULoad].N != 3 || r.Points[0].Aggs[FieldMemUsedMB].Max != 300 {
+		t.Fatalf("hour query = %+v", r)
+	}
+
+	// Months back only the hourly tier covers
+	r, _ = a.Query("dev-a", now.Add(-30*24*time.Hour), now.Add(time.Hour), time.Minute)
+	if r.Resolution != time.Hour || len(r.Points) != 1 {
+		t.Fatalf("month query = %+v", r)
+	}
+
+	if _, ok := a.Query("dev-x", now, now, 0); ok {
+		t.Fatal("unknown device found")
+	}
+}
+
+func TestArchiveRawAndPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json.gz")
+	a, err := OpenArchive(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		a.Add("dev-a", "mac", MetricsSample{Timestamp: now.Add(time.Duration(i-5) * 2 * time.Second).UnixMilli(), CPULoad: float64(i) / 10})
+	}
+	r, _ := a.Query("dev-a", now.Add(-time.Minute), now, 0)
+	if r.Resolution != 0 || len(r.Points) != 5 {
+		t.Fatalf("raw query = %+v", r)
+	}
+	if err := a.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := OpenArchive(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := b.Query("dev-a", now.Add(-time.Minute), now, 0)
+	if !ok || got.DeviceName != "mac" || len(got.Points) != 5 || got.Points[4].Aggs[FieldCPULoad].Max != 0.4 {
+		t.Fatalf("reloaded query = %+v", got)
+	}
+}
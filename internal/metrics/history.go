@@ -43,6 +43,7 @@ type MetricsStore struct {
 	devices map[string]*DeviceMetricsHistory
 	mu      sync.RWMutex
 	stopCh  chan struct{}
+	archive *Archive // long-term history, nil = none
 }
 
 // NewMetricsStore creates a new metrics store with background cleanup
@@ -63,9 +64,24 @@ func (s *MetricsStore) Stop() {
 	close(s.stopCh)
 }
 
+// SetArchive also keeps every sample added from now on in a
+func (s *MetricsStore) SetArchive(a *Archive) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.archive = a
+}
+
+// Archive returns the long-term history, or nil
+func (s *MetricsStore) Archive() *Archive {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.archive
+}
+
 // AddSample adds a metrics sample for a device
 func (s *MetricsStore) AddSample(deviceID, deviceName string, sample MetricsSample) {
 	s.mu.Lock()
+	s.archive.Add(deviceID, deviceName, sample)
 	history, exists := s.devices[deviceID]
 	if !exists {
 		history = &DeviceMetricsHistory{
@@ -0,0 +1,79 @@
+package prom
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	r := NewRegistry()
+	rpcs := r.Counter("rpc_total", "RPCs handled.", "method", "code")
+	load := r.Gauge("cpu_load", "CPU load\nof a device.", "device")
+	latency := r.Histogram("rpc_seconds", "RPC latency.", []float64{0.1, 1}, "method")
+
+	rpcs.Inc("/Ping", "OK")
+	rpcs.Add(2, "/Ping", "OK")
+	rpcs.Inc(`/Odd"name`, "Unknown")
+	load.Set(0.25, "dev-a")
+	latency.Observe(0.05, "/Ping")
+	latency.Observe(0.1, "/Ping") // bounds are inclusive
+	latency.Observe(3, "/Ping")
+
+	var sb strings.Builder
+	if err := r.WriteText(&sb); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP rpc_total RPCs handled.
+# TYPE rpc_total counter
+rpc_total{method="/Odd\"name",code="Unknown"} 1
+rpc_total{method="/Ping",code="OK"} 3
+# HELP cpu_load CPU load\nof a device.
+# TYPE cpu_load gauge
+cpu_load{device="dev-a"} 0.25
+# HELP rpc_seconds RPC latency.
+# TYPE rpc_seconds histogram
+rpc_seconds_bucket{method="/Ping",le="0.1"} 2
+rpc_seconds_bucket{method="/Ping",le="1"} 2
+rpc_seconds_bucket{method="/Ping",le="+Inf"} 3
+rpc_seconds_sum{method="/Ping"} 3.15
+rpc_seconds_count{method="/Ping"} 3
+`
+	if got := sb.String(); got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestOnScrapeAndReset(t *testing.T) {
+	r := NewRegistry()
+	devices := r.Gauge("devices", "Devices.", "device")
+	current := []string{"a", "b"}
+	r.OnScrape(func() {
+		devices.Reset()
+		for _, d := range current {
+			devices.Set(1, d)
+		}
+	})
+
+	current = []string{"b"}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	if strings.Contains(body, `device="a"`) || !strings.Contains(body, `devices{device="b"} 1`) {
+		t.Fatalf("scrape did not reflect current devices:\n%s", body)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != ContentType {
+		t.Fatalf("content type = %q", ct)
+	}
+}
+
+func TestRegisterTwicePanics(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("x_total", "x")
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic")
+		}
+	}()
+	r.Gauge("x_total", "x")
+}
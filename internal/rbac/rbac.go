@@ -0,0 +1,114 @@
+// Package rbac maps session principals to roles and roles to permissions
+package rbac
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Permission names one guarded capability
+type Permission string
+
+const (
+	// PermStreamView allows watching a device's screen
+	PermStreamView Permission = "stream.view"
+	// PermStreamControl allows sending mouse and keyboard input to a
+	// device through a stream. It is never granted by default.
+	PermStreamControl Permission = "stream.control"
+)
+
+// Wildcard matches every principal in Principals
+const Wildcard = "*"
+
+// Policy assigns roles to principals. A principal is verified, never taken
+// from the client: a session's principal is the name its security key maps
+// to in Keys. Sessions whose key is not listed are anonymous and hold only
+// the wildcard's roles.
+type Policy struct {
+	Roles      map[string][]Permission `json:"roles"`      // role -> permissions
+	Principals map[string][]string     `json:"principals"` // principal or "*" -> roles
+	Keys       map[string]string       `json:"keys"`       // security key -> principal
+}
+
+// Default lets everyone view streams and nobody control them
+func Default() *Policy {
+	return &Policy{
+		Roles: map[string][]Permission{
+			"viewer":     {PermStreamView},
+			"controller": {PermStreamView, PermStreamControl},
+		},
+		Principals: map[string][]string{
+			Wildcard: {"viewer"},
+		},
+	}
+}
+
+// DefaultPath returns ~/.edgemesh/rbac.json
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".edgemesh", "rbac.json"), nil
+}
+
+// Load reads a policy file; a missing file yields the default policy
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Default(), nil
+		}
+		return nil, err
+	}
+	p := &Policy{}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	for key, principal := range p.Keys {
+		if key == "" || principal == "" || principal == Wildcard {
+			return nil, fmt.Errorf("parse %s: key for %q must be non-empty and name a principal", path, principal)
+		}
+	}
+	for principal, roles := range p.Principals {
+		for _, role := range roles {
+			if _, ok := p.Roles[role]; !ok {
+				return nil, fmt.Errorf("parse %s: principal %q has undefined role %q", path, principal, role)
+			}
+		}
+	}
+	return p, nil
+}
+
+// Principal returns the principal a security key authenticates, or "" for
+// an anonymous session
+func (p *Policy) Principal(key string) string {
+	if p == nil || key == "" {
+		return ""
+	}
+	return p.Keys[key]
+}
+
+// Allowed reports whether principal holds perm through any of its roles or
+// the wildcard's roles. The anonymous principal "" holds only the wildcard's.
+func (p *Policy) Allowed(principal string, perm Permission) bool {
+	if p == nil {
+		return false
+	}
+	names := []string{Wildcard}
+	if principal != "" {
+		names = append(names, principal)
+	}
+	for _, who := range names {
+		for _, role := range p.Principals[who] {
+			for _, granted := range p.Roles[role] {
+				if granted == perm {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
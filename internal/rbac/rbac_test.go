@@ -0,0 +1,68 @@
+package rbac
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultPolicyDeniesControl(t *testing.T) {
+	p := Default()
+	if !p.Allowed("web-stream", PermStreamView) {
+		t.Error("default policy should allow viewing")
+	}
+	if p.Allowed("web-stream", PermStreamControl) {
+		t.Error("default policy must not allow control")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	p, err := Load(filepath.Join(dir, "missing.json"))
+	if err != nil || p.Allowed("anyone", PermStreamControl) {
+		t.Fatalf("missing file: %v, control allowed=%v", err, p.Allowed("anyone", PermStreamControl))
+	}
+
+	path := filepath.Join(dir, "rbac.json")
+	os.WriteFile(path, []byte(`{
+		"roles": {"viewer": ["stream.view"], "controller": ["stream.view", "stream.control"]},
+		"principals": {"*": ["viewer"], "ops-laptop": ["controller"]}
+	}`), 0600)
+	if p, err = Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if !p.Allowed("ops-laptop", PermStreamControl) {
+		t.Error("ops-laptop should have control")
+	}
+	if p.Allowed("web-stream", PermStreamControl) || !p.Allowed("web-stream", PermStreamView) {
+		t.Error("other principals should only view")
+	}
+
+	os.WriteFile(path, []byte(`{
+		"roles": {"controller": ["stream.view", "stream.control"]},
+		"principals": {"ops-laptop": ["controller"]},
+		"keys": {"s3cret": "ops-laptop"}
+	}`), 0600)
+	if p, err = Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.Principal("s3cret"); got != "ops-laptop" {
+		t.Errorf("Principal(s3cret) = %q, want ops-laptop", got)
+	}
+	if got := p.Principal("guess"); got != "" {
+		t.Errorf("unknown key should be anonymous, got %q", got)
+	}
+	if p.Allowed(p.Principal("guess"), PermStreamView) {
+		t.Error("anonymous principal should hold only wildcard roles")
+	}
+
+	os.WriteFile(path, []byte(`{"roles": {}, "principals": {}, "keys": {"k": "*"}}`), 0600)
+	if _, err := Load(path); err == nil {
+		t.Error("expected error for a key mapped to the wildcard")
+	}
+
+	os.WriteFile(path, []byte(`{"roles": {}, "principals": {"x": ["admin"]}}`), 0600)
+	if _, err := Load(path); err == nil {
+		t.Error("expected error for undefined role")
+	}
+}
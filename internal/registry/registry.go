@@ -6,6 +6,9 @@ import (
 	"sync"
 	"time"
 
+	"google.golang.org/protobuf/proto"
+
+	"github.com/edgecli/edgecli/internal/sysinfo"
 	pb "github.com/edgecli/edgecli/proto"
 )
 
@@ -114,10 +117,12 @@ func (r *Registry) GetStatus(deviceID string) *pb.DeviceStatus {
 		GpuMemUsedMb:  entry.Status.GpuMemUsedMb,
 		GpuMemTotalMb: entry.Status.GpuMemTotalMb,
 		NpuLoad:       entry.Status.NpuLoad,
+		Power:         entry.Status.Power,
 	}
 }
 
-// UpdateStatus updates the status of a device
+// UpdateStatus updates the status of a device. Its power state is copied
+// into the device info, so routing and cost estimates see the latest.
 func (r *Registry) UpdateStatus(deviceID string, status *pb.DeviceStatus) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -126,6 +131,12 @@ func (r *Registry) UpdateStatus(deviceID string, status *pb.DeviceStatus) {
 	if ok {
 		entry.Status = status
 		entry.LastSeen = time.Now()
+		if status.Power != nil && !proto.Equal(status.Power, entry.Info.Power) {
+			// Info may be held by readers; replace it rather than modify it
+			info := proto.Clone(entry.Info).(*pb.DeviceInfo)
+			info.Power = status.Power
+			entry.Info = info
+		}
 	}
 }
 
@@ -213,6 +224,12 @@ func (r *Registry) SelectDevice(policy *pb.RoutingPolicy, selfDeviceID string) *
 	case pb.RoutingPolicy_REQUIRE_LOCAL_MODEL:
 		return r.selectRequireLocalModel(selfDeviceID)
 
+	case pb.RoutingPolicy_PREFER_PLUGGED_IN:
+		return r.selectPreferPluggedIn(selfDeviceID)
+
+	case pb.RoutingPolicy_AVOID_LOW_BATTERY:
+		return r.selectAvoidLowBattery(policy.MinBatteryPercent, selfDeviceID)
+
 	case pb.RoutingPolicy_BEST_AVAILABLE:
 		fallthrough
 	default:
@@ -302,15 +319,20 @@ func (r *Registry) selectPreferRemote(selfDeviceID string) *SelectionResult {
 
 // selectBestAvailable selects the best device regardless of location
 func (r *Registry) selectBestAvailable(selfDeviceID string) *SelectionResult {
-	if len(r.devices) == 0 {
-		return &SelectionResult{
-			Error: fmt.Errorf("no devices available"),
-		}
-	}
+	return r.selectBestWhere(selfDeviceID, nil)
+}
 
-	var npuDevice, gpuDevice, cpuDevice *pb.DeviceInfo
+// selectBestWhere selects the best device among those keep accepts (nil = all)
+func (r *Registry) selectBestWhere(selfDeviceID string, keep func(*pb.DeviceInfo) bool) *SelectionResult {
+	var npuDevice, gpuDevice, cpuDevice, anyDevice *pb.DeviceInfo
 
 	for _, entry := range r.devices {
+		if keep != nil && !keep(entry.Info) {
+			continue
+		}
+		if anyDevice == nil {
+			anyDevice = entry.Info
+		}
 		if entry.Info.HasNpu && npuDevice == nil {
 			npuDevice = entry.Info
 		}
@@ -343,10 +365,10 @@ func (r *Registry) selectBestAvailable(selfDeviceID string) *SelectionResult {
 	}
 
 	// Fallback to first device
-	for _, entry := range r.devices {
+	if anyDevice != nil {
 		return &SelectionResult{
-			Device:          entry.Info,
-			ExecutedLocally: entry.Info.DeviceId == selfDeviceID,
+			Device:          anyDevice,
+			ExecutedLocally: anyDevice.DeviceId == selfDeviceID,
 		}
 	}
 
@@ -387,6 +409,45 @@ func (r *Registry) selectRequireLocalModel(selfDeviceID string) *SelectionResult
 	}
 }
 
+// selectPreferPluggedIn prefers devices on external power, falls back to best available
+func (r *Registry) selectPreferPluggedIn(selfDeviceID string) *SelectionResult {
+	result := r.selectBestWhere(selfDeviceID, func(d *pb.DeviceInfo) bool {
+		return d.Power == nil || d.Power.PluggedIn
+	})
+	if result.Error == nil {
+		return result
+	}
+	return r.selectBestAvailable(selfDeviceID)
+}
+
+// selectAvoidLowBattery selects the best device that is not on battery below
+// minPercent (0 = sysinfo.LowBatteryPercent) and not critically hot
+func (r *Registry) selectAvoidLowBattery(minPercent float64, selfDeviceID string) *SelectionResult {
+	if minPercent <= 0 {
+		minPercent = sysinfo.LowBatteryPercent
+	}
+	result := r.selectBestWhere(selfDeviceID, func(d *pb.DeviceInfo) bool {
+		return !LowOnPower(d, minPercent)
+	})
+	if result.Error != nil {
+		result.Error = fmt.Errorf("no device with at least %.0f%% battery or on external power", minPercent)
+	}
+	return result
+}
+
+// LowOnPower reports whether device runs on battery below minPercent, or is
+// critically hot. Devices that report no power state are not.
+func LowOnPower(device *pb.DeviceInfo, minPercent float64) bool {
+	p := device.Power
+	if p == nil {
+		return false
+	}
+	if p.ThermalState == sysinfo.ThermalCritical {
+		return true
+	}
+	return p.HasBattery && !p.PluggedIn && p.BatteryPercent < minPercent
+}
+
 // Remove deletes a device from the registry
 // Returns true if the device was found and removed
 func (r *Registry) Remove(deviceID string) bool {
@@ -402,7 +463,7 @@ func (r *Registry) Remove(deviceID string) bool {
 
 // UpsertFromDiscovery adds/updates a device from a discovery announcement
 // Returns true if this is a new device (not previously known)
-func (r *Registry) UpsertFromDiscovery(deviceID, deviceName, grpcAddr, httpAddr, platform, arch string, hasCPU, hasGPU, hasNPU, canScreenCapture bool, hasLocalModel bool, localModelName, localChatEndpoint string) bool {
+func (r *Registry) UpsertFromDiscovery(deviceID, deviceName, grpcAddr, httpAddr, platform, arch string, hasCPU, hasGPU, hasNPU, canScreenCapture bool, hasLocalModel bool, localModelName, localChatEndpoint string, taskKinds []string, llmPrefillTPS, llmDecodeTPS float64) bool {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -410,19 +471,22 @@ func (r *Registry) UpsertFromDiscovery(deviceID, deviceName, grpcAddr, httpAddr,
 	_, exists := r.devices[deviceID]
 
 	info := &pb.DeviceInfo{
-		DeviceId:          deviceID,
-		DeviceName:        deviceName,
-		Platform:          platform,
-		Arch:              arch,
-		HasCpu:            hasCPU,
-		HasGpu:            hasGPU,
-		HasNpu:            hasNPU,
-		GrpcAddr:          grpcAddr,
-		HttpAddr:          httpAddr,
-		CanScreenCapture:  canScreenCapture,
-		HasLocalModel:     hasLocalModel,
-		LocalModelName:    localModelName,
-		LocalChatEndpoint: localChatEndpoint,
+		DeviceId:           deviceID,
+		DeviceName:         deviceName,
+		Platform:           platform,
+		Arch:               arch,
+		HasCpu:             hasCPU,
+		HasGpu:             hasGPU,
+		HasNpu:             hasNPU,
+		GrpcAddr:           grpcAddr,
+		HttpAddr:           httpAddr,
+		CanScreenCapture:   canScreenCapture,
+		HasLocalModel:      hasLocalModel,
+		LocalModelName:     localModelName,
+		LocalChatEndpoint:  localChatEndpoint,
+		TaskKinds:          taskKinds,
+		LlmPrefillToksPerS: llmPrefillTPS,
+		LlmDecodeToksPerS:  llmDecodeTPS,
 	}
 
 	r.devices[deviceID] = &DeviceEntry{
@@ -0,0 +1,68 @@
+package registry
+
+import (
+	"testing"
+
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+func newPowerRegistry() *Registry {
+	r := NewRegistry()
+	r.Upsert(&pb.DeviceInfo{
+		DeviceId: "phone", Platform: "android", HasCpu: true, HasNpu: true,
+		Power: &pb.PowerState{HasBattery: true, BatteryPercent: 10},
+	})
+	r.Upsert(&pb.DeviceInfo{
+		DeviceId: "laptop", Platform: "linux", HasCpu: true,
+		Power: &pb.PowerState{HasBattery: true, BatteryPercent: 90, PluggedIn: true},
+	})
+	return r
+}
+
+func TestSelectDevicePowerPolicies(t *testing.T) {
+	r := newPowerRegistry()
+
+	// The phone's NPU wins when power is not considered
+	if got := r.SelectDevice(nil, "laptop").Device.DeviceId; got != "phone" {
+		t.Fatalf("BEST_AVAILABLE chose %s, want phone", got)
+	}
+	for _, mode := range []pb.RoutingPolicy_Mode{pb.RoutingPolicy_PREFER_PLUGGED_IN, pb.RoutingPolicy_AVOID_LOW_BATTERY} {
+		result := r.SelectDevice(&pb.RoutingPolicy{Mode: mode}, "laptop")
+		if result.Error != nil || result.Device.DeviceId != "laptop" || !result.ExecutedLocally {
+			t.Errorf("%s chose %+v, want the laptop", mode, result)
+		}
+	}
+}
+
+func TestSelectDeviceAvoidLowBatteryFails(t *testing.T) {
+	r := newPowerRegistry()
+	r.Remove("laptop")
+
+	if result := r.SelectDevice(&pb.RoutingPolicy{Mode: pb.RoutingPolicy_AVOID_LOW_BATTERY}, ""); result.Error == nil {
+		t.Errorf("AVOID_LOW_BATTERY chose %s at 10%% battery", result.Device.DeviceId)
+	}
+	// A lower threshold lets the phone run, and PREFER_PLUGGED_IN falls back to it
+	policy := &pb.RoutingPolicy{Mode: pb.RoutingPolicy_AVOID_LOW_BATTERY, MinBatteryPercent: 5}
+	if result := r.SelectDevice(policy, ""); result.Error != nil {
+		t.Errorf("AVOID_LOW_BATTERY at 5%%: %v", result.Error)
+	}
+	if result := r.SelectDevice(&pb.RoutingPolicy{Mode: pb.RoutingPolicy_PREFER_PLUGGED_IN}, ""); result.Error != nil {
+		t.Errorf("PREFER_PLUGGED_IN: %v", result.Error)
+	}
+}
+
+func TestUpdateStatusRefreshesPower(t *testing.T) {
+	r := newPowerRegistry()
+	before, _ := r.Get("phone")
+	info := before.Info
+
+	r.UpdateStatus("phone", &pb.DeviceStatus{DeviceId: "phone", Power: &pb.PowerState{HasBattery: true, BatteryPercent: 80, PluggedIn: true}})
+
+	after, _ := r.Get("phone")
+	if after.Info.Power.BatteryPercent != 80 || !after.Info.Power.PluggedIn {
+		t.Errorf("info power = %v, want the status's", after.Info.Power)
+	}
+	if info.Power.BatteryPercent != 10 {
+		t.Error("the earlier info was modified in place")
+	}
+}
@@ -0,0 +1,56 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	// Wednesday 2026-10-14 10:17 UTC
+	from := time.Date(2026, 10, 14, 10, 17, 30, 0, time.UTC)
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"@hourly", time.Date(2026, 10, 14, 11, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 10, 14, 10, 30, 0, 0, time.UTC)},
+		{"0 18 * * *", time.Date(2026, 10, 14, 18, 0, 0, 0, time.UTC)},
+		{"0 9 * * mon-fri", time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{"30 2 1 jan *", time.Date(2027, 1, 1, 2, 30, 0, 0, time.UTC)},
+		{"5/20 10 * * *", time.Date(2026, 10, 14, 10, 25, 0, 0, time.UTC)},
+		// Both day fields restricted: either one matches
+		{"0 0 20 * fri", time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, c := range cases {
+		cron, err := ParseCron(c.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", c.expr, err)
+		}
+		if got := cron.Next(from); !got.Equal(c.want) {
+			t.Errorf("%s: next = %v, want %v", c.expr, got, c.want)
+		}
+	}
+}
+
+func TestCronNextInLocation(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	cron, _ := ParseCron("0 18 * * *")
+	got := cron.Next(time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC).In(loc))
+	if want := time.Date(2026, 10, 14, 22, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Fatalf("6pm New York = %v, want %v", got.UTC(), want)
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "5-1 * * * *", "*/0 * * * *", "@often"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("%q: want error", expr)
+		}
+	}
+}
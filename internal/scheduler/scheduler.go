@@ -0,0 +1,422 @@
+// Package scheduler runs jobs on cron schedules. Schedules are stored on
+// disk, runs missed while the server was down are skipped or caught up once,
+// and a run is skipped while the schedule's previous job is still going.
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+// MissedPolicy says what happens to runs that came due while the
+// scheduler was not running
+type MissedPolicy string
+
+const (
+	// MissedSkip drops missed runs and waits for the next one
+	MissedSkip MissedPolicy = "skip"
+	// MissedRunOnce runs once to catch up, however many runs were missed
+	MissedRunOnce MissedPolicy = "run_once"
+)
+
+// Run outcomes
+const (
+	RunStarted = "started" // a job was submitted
+	RunSkipped = "skipped" // the previous run's job was still going
+	RunMissed  = "missed"  // due while the scheduler was down, dropped
+	RunFailed  = "failed"  // the job could not be submitted
+)
+
+const (
+	// missedAfter is how late a run may start before it counts as missed
+	missedAfter = time.Minute
+	// maxRuns is how many runs each schedule remembers
+	maxRuns = 20
+	// maxSleep bounds how long the loop sleeps, so clock changes are noticed
+	maxSleep = time.Minute
+)
+
+// ErrNotFound is returned for a schedule ID that does not exist
+var ErrNotFound = errors.New("schedule not found")
+
+// Run records one time a schedule came due
+type Run struct {
+	Due    time.Time `json:"due"`
+	Status string    `json:"status"`
+	JobID  string    `json:"job_id,omitempty"`
+	Detail string    `json:"detail,omitempty"`
+}
+
+// Schedule submits Job every time Cron matches
+type Schedule struct {
+	ID           string          `json:"id"`
+	Name         string          `json:"name"`
+	Cron         string          `json:"cron"`
+	Timezone     string          `json:"timezone,omitempty"` // IANA name; empty = the server's local time
+	Job          *pb.JobRequest  `json:"-"`                  // what each run submits; its session is ignored
+	JobJSON      json.RawMessage `json:"job"`                // Job as protobuf JSON, for storage
+	Missed       MissedPolicy    `json:"missed"`
+	AllowOverlap bool            `json:"allow_overlap,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+	NextRunAt    time.Time       `json:"next_run_at"`
+	LastRunAt    time.Time       `json:"last_run_at"`
+	LastJobID    string          `json:"last_job_id,omitempty"`
+	Runs         []Run           `json:"runs,omitempty"` // newest last
+
+	cron *Cron
+	loc  *time.Location
+}
+
+// SubmitFunc starts a run's job and returns its ID
+type SubmitFunc func(ctx context.Context, s *Schedule) (jobID string, err error)
+
+// Scheduler stores schedules and submits their jobs as they come due
+type Scheduler struct {
+	mu        sync.Mutex
+	path      string // "" = kept in memory only
+	schedules map[string]*Schedule
+	wake      chan struct{}
+}
+
+// DefaultPath returns ~/.edgemesh/schedules.json
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".edgemesh", "schedules.json"), nil
+}
+
+// New returns a scheduler keeping schedules in memory
+func New() *Scheduler {
+	return &Scheduler{schedules: make(map[string]*Schedule), wake: make(chan struct{}, 1)}
+}
+
+// Open loads the schedules at path and saves to it on every change. A
+// missing file starts empty.
+func Open(path string) (*Scheduler, error) {
+	s := New()
+	s.path = path
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var list []*Schedule
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	for _, sc := range list {
+		if err := sc.prepare(); err != nil {
+			return nil, fmt.Errorf("parse %s: schedule %s: %w", path, sc.ID, err)
+		}
+		s.schedules[sc.ID] = sc
+	}
+	return s, nil
+}
+
+// prepare parses the stored fields of a loaded schedule
+func (sc *Schedule) prepare() error {
+	if err := sc.compile(); err != nil {
+		return err
+	}
+	sc.Job = &pb.JobRequest{}
+	if len(sc.JobJSON) > 0 {
+		if err := protojson.Unmarshal(sc.JobJSON, sc.Job); err != nil {
+			return fmt.Errorf("job: %w", err)
+		}
+	}
+	return nil
+}
+
+// compile parses Cron and Timezone
+func (sc *Schedule) compile() error {
+	c, err := ParseCron(sc.Cron)
+	if err != nil {
+		return err
+	}
+	loc := time.Local
+	if sc.Timezone != "" {
+		if loc, err = time.LoadLocation(sc.Timezone); err != nil {
+			return fmt.Errorf("timezone: %w", err)
+		}
+	}
+	sc.cron, sc.loc = c, loc
+	return nil
+}
+
+// next returns the first run time after t
+func (sc *Schedule) next(t time.Time) time.Time {
+	return sc.cron.Next(t.In(sc.loc))
+}
+
+// Create validates sc, assigns its ID and first run time, and stores it.
+// The job must not be empty.
+func (s *Scheduler) Create(sc Schedule, now time.Time) (*Schedule, error) {
+	if sc.Job == nil || (sc.Job.Text == "" && sc.Job.Plan == nil && sc.Job.FanOut == nil && sc.Job.Map == nil) {
+		return nil, errors.New("schedule needs a job: text, plan, fan-out or map")
+	}
+	switch sc.Missed {
+	case "":
+		sc.Missed = MissedRunOnce
+	case MissedSkip, MissedRunOnce:
+	default:
+		return nil, fmt.Errorf("unknown missed-run policy %q (want %q or %q)", sc.Missed, MissedSkip, MissedRunOnce)
+	}
+	if err := sc.compile(); err != nil {
+		return nil, err
+	}
+	sc.NextRunAt = sc.next(now)
+	if sc.NextRunAt.IsZero() {
+		return nil, fmt.Errorf("cron %q never matches", sc.Cron)
+	}
+
+	sc.Job = proto.Clone(sc.Job).(*pb.JobRequest)
+	sc.Job.SessionId = ""
+	job, err := protojson.Marshal(sc.Job)
+	if err != nil {
+		return nil, err
+	}
+	sc.ID = uuid.New().String()
+	if sc.Name == "" {
+		sc.Name = sc.ID[:8]
+	}
+	sc.JobJSON = job
+	sc.CreatedAt = now
+	sc.LastRunAt, sc.LastJobID, sc.Runs = time.Time{}, "", nil
+
+	s.mu.Lock()
+	s.schedules[sc.ID] = &sc
+	err = s.saveLocked()
+	out := sc.copy()
+	s.mu.Unlock()
+	if err != nil {
+		log.Printf("[WARN] scheduler: could not save: %v", err)
+	}
+	s.poke()
+	return out, nil
+}
+
+// copy returns a copy safe to hand out of the lock
+func (sc *Schedule) copy() *Schedule {
+	out := *sc
+	out.Runs = append([]Run(nil), sc.Runs...)
+	return &out
+}
+
+// List returns every schedule, oldest first
+func (s *Scheduler) List() []*Schedule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := make([]*Schedule, 0, len(s.schedules))
+	for _, sc := range s.schedules {
+		list = append(list, sc.copy())
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.Before(list[j].CreatedAt)
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// Get returns one schedule
+func (s *Scheduler) Get(id string) (*Schedule, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sc, ok := s.schedules[id]
+	if !ok {
+		return nil, false
+	}
+	return sc.copy(), true
+}
+
+// Delete removes a schedule. Jobs it already started keep running.
+func (s *Scheduler) Delete(id string) error {
+	s.mu.Lock()
+	if _, ok := s.schedules[id]; !ok {
+		s.mu.Unlock()
+		return ErrNotFound
+	}
+	delete(s.schedules, id)
+	err := s.saveLocked()
+	s.mu.Unlock()
+	if err != nil {
+		log.Printf("[WARN] scheduler: could not save: %v", err)
+	}
+	s.poke()
+	return nil
+}
+
+func (s *Scheduler) poke() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run submits jobs as schedules come due until ctx is done. active reports
+// whether a job is still queued or running, for overlap prevention.
+func (s *Scheduler) Run(ctx context.Context, submit SubmitFunc, active func(jobID string) bool) {
+	for {
+		s.Tick(ctx, time.Now(), submit, active)
+
+		sleep := maxSleep
+		if next, ok := s.nextDue(); ok {
+			sleep = min(max(time.Until(next), 0), maxSleep)
+		}
+		timer := time.NewTimer(sleep)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-s.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+func (s *Scheduler) nextDue() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var next time.Time
+	for _, sc := range s.schedules {
+		if next.IsZero() || sc.NextRunAt.Before(next) {
+			next = sc.NextRunAt
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// due is a schedule whose run time has come, decided under the lock
+type due struct {
+	sc     *Schedule
+	run    Run
+	submit bool
+}
+
+// Tick handles every schedule due at now: it submits its job, or records
+// the run as skipped or missed, and moves it to its next run time
+func (s *Scheduler) Tick(ctx context.Context, now time.Time, submit SubmitFunc, active func(jobID string) bool) {
+	var work []due
+	s.mu.Lock()
+	for _, sc := range s.schedules {
+		if sc.NextRunAt.IsZero() || sc.NextRunAt.After(now) {
+			continue
+		}
+		d := due{sc: sc.copy(), run: Run{Due: sc.NextRunAt}}
+		if late := now.Sub(sc.NextRunAt); late > missedAfter {
+			missed := sc.countMissed(now)
+			if sc.Missed == MissedSkip {
+				d.run.Status = RunMissed
+				d.run.Detail = fmt.Sprintf("%d run(s) missed while the scheduler was down", missed)
+			} else {
+				d.run.Detail = fmt.Sprintf("catching up on %d missed run(s)", missed)
+				d.submit = true
+			}
+		} else {
+			d.submit = true
+		}
+		if d.submit && !sc.AllowOverlap && sc.LastJobID != "" && active != nil && active(sc.LastJobID) {
+			d.submit = false
+			d.run.Status = RunSkipped
+			d.run.Detail = "previous job " + sc.LastJobID + " is still running"
+		}
+		sc.NextRunAt = sc.next(now)
+		work = append(work, d)
+	}
+	s.mu.Unlock()
+	if len(work) == 0 {
+		return
+	}
+
+	for i := range work {
+		d := &work[i]
+		if !d.submit {
+			log.Printf("[INFO] scheduler: %s (%s) %s: %s", d.sc.Name, d.sc.ID, d.run.Status, d.run.Detail)
+			continue
+		}
+		jobID, err := submit(ctx, d.sc)
+		if err != nil {
+			d.run.Status = RunFailed
+			d.run.Detail = err.Error()
+			log.Printf("[WARN] scheduler: %s (%s) could not submit its job: %v", d.sc.Name, d.sc.ID, err)
+			continue
+		}
+		d.run.Status = RunStarted
+		d.run.JobID = jobID
+		log.Printf("[INFO] scheduler: %s (%s) started job %s", d.sc.Name, d.sc.ID, jobID)
+	}
+
+	s.mu.Lock()
+	for _, d := range work {
+		sc, ok := s.schedules[d.sc.ID]
+		if !ok {
+			continue // deleted while its job was being submitted
+		}
+		sc.LastRunAt = now
+		if d.run.JobID != "" {
+			sc.LastJobID = d.run.JobID
+		}
+		sc.Runs = append(sc.Runs, d.run)
+		if len(sc.Runs) > maxRuns {
+			sc.Runs = sc.Runs[len(sc.Runs)-maxRuns:]
+		}
+	}
+	err := s.saveLocked()
+	s.mu.Unlock()
+	if err != nil {
+		log.Printf("[WARN] scheduler: could not save: %v", err)
+	}
+}
+
+// countMissed counts the run times from NextRunAt up to now
+func (sc *Schedule) countMissed(now time.Time) int {
+	n := 0
+	for t := sc.NextRunAt; !t.IsZero() && !t.After(now) && n < 10000; t = sc.next(t) {
+		n++
+	}
+	return n
+}
+
+func (s *Scheduler) saveLocked() error {
+	if s.path == "" {
+		return nil
+	}
+	list := make([]*Schedule, 0, len(s.schedules))
+	for _, sc := range s.schedules {
+		list = append(list, sc)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("create schedules dir: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,116 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+func TestTickSubmitsAndPreventsOverlap(t *testing.T) {
+	s := New()
+	now := time.Date(2026, 10, 14, 10, 0, 30, 0, time.UTC)
+	sc, err := s.Create(Schedule{Name: "sysinfo", Cron: "@hourly", Timezone: "UTC", Job: &pb.JobRequest{SessionId: "secret", Text: "collect status"}}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.Job.SessionId != "" || sc.Missed != MissedRunOnce {
+		t.Fatalf("created %+v", sc)
+	}
+
+	var submitted []string
+	running := map[string]bool{}
+	submit := func(ctx context.Context, sc *Schedule) (string, error) {
+		id := fmt.Sprintf("job-%d", len(submitted)+1)
+		submitted = append(submitted, sc.Job.Text)
+		running[id] = true
+		return id, nil
+	}
+	active := func(id string) bool { return running[id] }
+
+	s.Tick(context.Background(), now.Add(30*time.Minute), submit, active)
+	if len(submitted) != 0 {
+		t.Fatal("submitted before due")
+	}
+	s.Tick(context.Background(), now.Add(59*time.Minute+40*time.Second), submit, active)
+	got, _ := s.Get(sc.ID)
+	if len(submitted) != 1 || got.LastJobID != "job-1" || got.Runs[0].Status != RunStarted {
+		t.Fatalf("first run: submitted=%v schedule=%+v", submitted, got)
+	}
+	if want := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC); !got.NextRunAt.Equal(want) {
+		t.Fatalf("next run %v, want %v", got.NextRunAt, want)
+	}
+
+	// job-1 is still running at noon, so that run is skipped
+	s.Tick(context.Background(), time.Date(2026, 10, 14, 12, 0, 5, 0, time.UTC), submit, active)
+	got, _ = s.Get(sc.ID)
+	if len(submitted) != 1 || got.Runs[1].Status != RunSkipped {
+		t.Fatalf("overlapping run: submitted=%v runs=%+v", submitted, got.Runs)
+	}
+
+	running["job-1"] = false
+	s.Tick(context.Background(), time.Date(2026, 10, 14, 13, 0, 5, 0, time.UTC), submit, active)
+	got, _ = s.Get(sc.ID)
+	if len(submitted) != 2 || got.LastJobID != "job-2" {
+		t.Fatalf("after job-1 ended: submitted=%v schedule=%+v", submitted, got)
+	}
+}
+
+func TestMissedRunsAndPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedules.json")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	created := time.Date(2026, 10, 14, 10, 0, 30, 0, time.UTC)
+	skip, _ := s.Create(Schedule{Name: "skip", Cron: "@hourly", Timezone: "UTC", Missed: MissedSkip, Job: &pb.JobRequest{Text: "a"}}, created)
+	once, _ := s.Create(Schedule{Name: "once", Cron: "@hourly", Timezone: "UTC", Job: &pb.JobRequest{FanOut: &pb.FanOut{Kind: "SYSINFO"}}}, created)
+	if _, err := s.Create(Schedule{Cron: "@hourly", Missed: "sometimes", Job: &pb.JobRequest{Text: "a"}}, created); err == nil {
+		t.Fatal("unknown missed policy accepted")
+	}
+
+	// The server restarts three and a half hours later
+	s, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.List()) != 2 {
+		t.Fatalf("reloaded %d schedules", len(s.List()))
+	}
+	var kinds []string
+	submit := func(ctx context.Context, sc *Schedule) (string, error) {
+		if sc.Job.FanOut == nil {
+			return "", errors.New("unexpected submit")
+		}
+		kinds = append(kinds, sc.Job.FanOut.Kind)
+		return "job-1", nil
+	}
+	s.Tick(context.Background(), created.Add(3*time.Hour+30*time.Minute), submit, nil)
+
+	gotSkip, _ := s.Get(skip.ID)
+	if len(gotSkip.Runs) != 1 || gotSkip.Runs[0].Status != RunMissed || gotSkip.Runs[0].Detail != "3 run(s) missed while the scheduler was down" {
+		t.Fatalf("skip policy runs = %+v", gotSkip.Runs)
+	}
+	gotOnce, _ := s.Get(once.ID)
+	if len(kinds) != 1 || kinds[0] != "SYSINFO" || gotOnce.Runs[0].Status != RunStarted {
+		t.Fatalf("run_once policy: submitted %v, runs %+v", kinds, gotOnce.Runs)
+	}
+	if want := time.Date(2026, 10, 14, 14, 0, 0, 0, time.UTC); !gotOnce.NextRunAt.Equal(want) {
+		t.Fatalf("next run %v, want %v", gotOnce.NextRunAt, want)
+	}
+
+	if err := s.Delete(skip.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete(skip.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("second delete: %v", err)
+	}
+	s, _ = Open(path)
+	if list := s.List(); len(list) != 1 || list[0].ID != once.ID {
+		t.Fatalf("after delete: %+v", list)
+	}
+}
@@ -0,0 +1,48 @@
+//go:build darwin
+
+package sysinfo
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// readPlatformPower fills status's power state from pmset. macOS does not expose
+// temperatures without elevated privileges, so the thermal state is left
+// unavailable.
+func readPlatformPower(status *HostStatus) {
+	noPower(status)
+	output, err := exec.Command("pmset", "-g", "batt").Output()
+	if err != nil {
+		return
+	}
+	parsePmsetBatt(string(output), status)
+}
+
+// parsePmsetBatt parses "pmset -g batt", e.g.
+//
+//	Now drawing from 'Battery Power'
+//	 -InternalBattery-0 (id=4653155)	83%; discharging; 4:12 remaining present: true
+func parsePmsetBatt(output string, status *HostStatus) {
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "Now drawing from") {
+			status.PluggedIn = !strings.Contains(line, "'Battery Power'")
+			continue
+		}
+		if !strings.Contains(line, "InternalBattery") {
+			continue
+		}
+		fields := strings.Split(line, ";")
+		tab := strings.LastIndex(fields[0], "\t")
+		if tab < 0 || len(fields) < 2 {
+			continue
+		}
+		percent, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(fields[0][tab+1:]), "%"), 64)
+		if err != nil {
+			continue
+		}
+		status.BatteryPercent = percent
+		status.Charging = strings.TrimSpace(fields[1]) == "charging"
+	}
+}
@@ -0,0 +1,124 @@
+//go:build linux
+
+package sysinfo
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// sysfsRoot is where the kernel exposes power supplies and thermal zones
+const sysfsRoot = "/sys"
+
+// readPlatformPower fills status's power and thermal state from sysfs
+func readPlatformPower(status *HostStatus) {
+	readPower(sysfsRoot, status)
+}
+
+// readPower fills status's power and thermal state from the sysfs tree at root
+func readPower(root string, status *HostStatus) {
+	noPower(status)
+	readPowerSupplies(root, status)
+	readThermalZones(root, status)
+}
+
+// readPowerSupplies reads class/power_supply. Batteries of peripherals
+// (scope "Device", e.g. a wireless mouse) are ignored; several system
+// batteries are averaged.
+func readPowerSupplies(root string, status *HostStatus) {
+	supplies, _ := filepath.Glob(filepath.Join(root, "class", "power_supply", "*"))
+
+	var batteries int
+	var percentSum float64
+	var sawSupply, supplyOnline, discharging bool
+	for _, dir := range supplies {
+		switch readSysfs(dir, "type") {
+		case "Battery":
+			if readSysfs(dir, "scope") == "Device" {
+				continue
+			}
+			capacity, err := strconv.ParseFloat(readSysfs(dir, "capacity"), 64)
+			if err != nil {
+				continue
+			}
+			batteries++
+			percentSum += capacity
+			switch readSysfs(dir, "status") {
+			case "Charging":
+				status.Charging = true
+			case "Discharging":
+				discharging = true
+			}
+		case "Mains", "USB", "USB_C", "USB_PD", "Wireless":
+			sawSupply = true
+			if readSysfs(dir, "online") == "1" {
+				supplyOnline = true
+			}
+		}
+	}
+
+	if batteries == 0 {
+		return
+	}
+	status.BatteryPercent = min(max(percentSum/float64(batteries), 0), 100)
+	if sawSupply {
+		status.PluggedIn = supplyOnline
+	} else {
+		// No charger is exposed; trust the battery's own status
+		status.PluggedIn = !discharging
+	}
+}
+
+// readThermalZones reads class/thermal. The state is the worst of any zone,
+// judged against that zone's trip points; the temperature is the hottest.
+func readThermalZones(root string, status *HostStatus) {
+	zones, _ := filepath.Glob(filepath.Join(root, "class", "thermal", "thermal_zone*"))
+
+	worst := -1
+	for _, dir := range zones {
+		milli, err := strconv.ParseFloat(readSysfs(dir, "temp"), 64)
+		if err != nil || milli <= 0 {
+			continue // missing or a sensor that is switched off
+		}
+		tempC := milli / 1000
+		status.TemperatureC = max(status.TemperatureC, tempC)
+
+		level := 0
+		trips, _ := filepath.Glob(filepath.Join(dir, "trip_point_*_type"))
+		for _, trip := range trips {
+			tripMilli, err := strconv.ParseFloat(readSysfs(strings.TrimSuffix(trip, "_type")+"_temp", ""), 64)
+			if err != nil || tripMilli <= 0 || milli < tripMilli {
+				continue
+			}
+			switch readSysfs(trip, "") {
+			case "passive":
+				level = max(level, 1)
+			case "hot":
+				level = max(level, 2)
+			case "critical":
+				level = max(level, 3)
+			}
+		}
+		worst = max(worst, level)
+	}
+
+	if worst >= 0 {
+		status.ThermalState = []string{ThermalNominal, ThermalFair, ThermalSerious, ThermalCritical}[worst]
+	}
+}
+
+// readSysfs returns the trimmed contents of dir/name, or of dir itself if
+// name is empty; "" if it cannot be read
+func readSysfs(dir, name string) string {
+	path := dir
+	if name != "" {
+		path = filepath.Join(dir, name)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,89 @@
+//go:build linux
+
+package sysinfo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeSysfs creates files under root from a map of relative path to contents
+func writeSysfs(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, contents := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReadPowerPhoneOnBattery(t *testing.T) {
+	root := t.TempDir()
+	writeSysfs(t, root, map[string]string{
+		"class/power_supply/battery/type":     "Battery",
+		"class/power_supply/battery/capacity": "10",
+		"class/power_supply/battery/status":   "Discharging",
+		"class/power_supply/usb/type":         "USB",
+		"class/power_supply/usb/online":       "0",
+		// A wireless mouse's battery is not the device's
+		"class/power_supply/hidpp_battery_0/type":       "Battery",
+		"class/power_supply/hidpp_battery_0/scope":      "Device",
+		"class/power_supply/hidpp_battery_0/capacity":   "100",
+		"class/thermal/thermal_zone0/temp":              "52000",
+		"class/thermal/thermal_zone0/trip_point_0_type": "passive",
+		"class/thermal/thermal_zone0/trip_point_0_temp": "50000",
+		"class/thermal/thermal_zone0/trip_point_1_type": "critical",
+		"class/thermal/thermal_zone0/trip_point_1_temp": "95000",
+		"class/thermal/thermal_zone1/temp":              "38500",
+	})
+
+	var status HostStatus
+	readPower(root, &status)
+
+	if status.BatteryPercent != 10 || status.Charging || status.PluggedIn {
+		t.Errorf("battery = %.0f%% charging=%v plugged_in=%v, want 10%% on battery",
+			status.BatteryPercent, status.Charging, status.PluggedIn)
+	}
+	if status.ThermalState != ThermalFair || status.TemperatureC != 52 {
+		t.Errorf("thermal = %s %.1fC, want fair 52C", status.ThermalState, status.TemperatureC)
+	}
+}
+
+func TestReadPowerLaptopCharging(t *testing.T) {
+	root := t.TempDir()
+	writeSysfs(t, root, map[string]string{
+		"class/power_supply/BAT0/type":     "Battery",
+		"class/power_supply/BAT0/capacity": "60",
+		"class/power_supply/BAT0/status":   "Charging",
+		"class/power_supply/BAT1/type":     "Battery",
+		"class/power_supply/BAT1/capacity": "80",
+		"class/power_supply/BAT1/status":   "Full",
+		"class/power_supply/AC/type":       "Mains",
+		"class/power_supply/AC/online":     "1",
+	})
+
+	var status HostStatus
+	readPower(root, &status)
+
+	if status.BatteryPercent != 70 || !status.Charging || !status.PluggedIn {
+		t.Errorf("battery = %.0f%% charging=%v plugged_in=%v, want 70%% charging on mains",
+			status.BatteryPercent, status.Charging, status.PluggedIn)
+	}
+	if status.ThermalState != "" || status.TemperatureC != -1 {
+		t.Errorf("thermal = %q %.1fC, want unavailable", status.ThermalState, status.TemperatureC)
+	}
+}
+
+func TestReadPowerNoBattery(t *testing.T) {
+	var status HostStatus
+	readPower(t.TempDir(), &status)
+
+	if status.BatteryPercent != -1 || !status.PluggedIn {
+		t.Errorf("battery = %.0f%% plugged_in=%v, want no battery on mains", status.BatteryPercent, status.PluggedIn)
+	}
+}
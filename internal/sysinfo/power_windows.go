@@ -0,0 +1,42 @@
+//go:build windows
+
+package sysinfo
+
+import "unsafe"
+
+var procGetSystemPowerStatus = kernel32.NewProc("GetSystemPowerStatus")
+
+// systemPowerStatus is SYSTEM_POWER_STATUS
+type systemPowerStatus struct {
+	ACLineStatus        byte
+	BatteryFlag         byte
+	BatteryLifePercent  byte
+	SystemStatusFlag    byte
+	BatteryLifeTime     uint32
+	BatteryFullLifeTime uint32
+}
+
+// SYSTEM_POWER_STATUS flags
+const (
+	batteryFlagCharging   = 8
+	batteryFlagNoBattery  = 128
+	batteryFlagUnknown    = 255
+	batteryPercentUnknown = 255
+)
+
+// readPlatformPower fills status's power state from GetSystemPowerStatus. Windows
+// has no documented thermal API for user processes, so the thermal state is
+// left unavailable.
+func readPlatformPower(status *HostStatus) {
+	noPower(status)
+
+	var ps systemPowerStatus
+	ret, _, _ := procGetSystemPowerStatus.Call(uintptr(unsafe.Pointer(&ps)))
+	if ret == 0 || ps.BatteryFlag == batteryFlagUnknown || ps.BatteryFlag&batteryFlagNoBattery != 0 ||
+		ps.BatteryLifePercent == batteryPercentUnknown {
+		return
+	}
+	status.BatteryPercent = float64(ps.BatteryLifePercent)
+	status.Charging = ps.BatteryFlag&batteryFlagCharging != 0
+	status.PluggedIn = ps.ACLineStatus == 1
+}
@@ -13,6 +13,41 @@ type HostStatus struct {
 	GPUMemTotalMB uint64  // GPU memory total (0 if unavailable)
 	NPULoad       float64 // 0.0-1.0, or -1 if unavailable
 	Timestamp     int64   // Unix milliseconds
+
+	// Power and thermal state
+	BatteryPercent float64 // 0-100, or -1 if there is no battery
+	Charging       bool    // battery is charging
+	PluggedIn      bool    // on external power; true without a battery
+	ThermalState   string  // ThermalNominal..ThermalCritical, or "" if unavailable
+	TemperatureC   float64 // hottest sensor, or -1 if unavailable
+}
+
+// GetPowerStatus samples only the power and thermal state, which is much
+// cheaper than GetHostStatus; the other metrics are left unavailable
+func GetPowerStatus() *HostStatus {
+	status := &HostStatus{CPULoad: -1, GPULoad: -1, NPULoad: -1}
+	readPlatformPower(status)
+	status.Timestamp = time.Now().UnixMilli()
+	return status
+}
+
+// Thermal states, from coolest to hottest
+const (
+	ThermalNominal  = "nominal"  // below every trip point
+	ThermalFair     = "fair"     // passive cooling: the CPU may be throttled
+	ThermalSerious  = "serious"  // hot: heavy throttling
+	ThermalCritical = "critical" // about to shut down
+)
+
+// LowBatteryPercent is the charge below which a device on battery should
+// not be given heavy work
+const LowBatteryPercent = 20.0
+
+// noPower is the power state of a host that reports none
+func noPower(status *HostStatus) {
+	status.BatteryPercent = -1
+	status.PluggedIn = true
+	status.TemperatureC = -1
 }
 
 // GetHostStatus samples current host status using platform-specific code
@@ -42,6 +42,9 @@ func getPlatformHostStatus() *HostStatus {
 	status.GPUMemUsedMB = gpuMemUsed
 	status.GPUMemTotalMB = gpuMemTotal
 
+	// Battery and charger from pmset
+	readPlatformPower(status)
+
 	return status
 }
 
@@ -53,6 +53,9 @@ func getPlatformHostStatus() *HostStatus {
 	status.GPUMemUsedMB = gpuMemUsed
 	status.GPUMemTotalMB = gpuMemTotal
 
+	// Battery, charger and thermal zones from sysfs
+	readPlatformPower(status)
+
 	return status
 }
 
@@ -14,7 +14,7 @@ func getPlatformHostStatus() *HostStatus {
 	memUsedMB := memStats.Alloc / (1024 * 1024)
 	memTotalMB := memStats.Sys / (1024 * 1024)
 
-	return &HostStatus{
+	status := &HostStatus{
 		CPULoad:       -1, // Not available
 		MemUsedMB:     memUsedMB,
 		MemTotalMB:    memTotalMB,
@@ -23,4 +23,11 @@ func getPlatformHostStatus() *HostStatus {
 		GPUMemTotalMB: 0,
 		NPULoad:       -1,
 	}
+	readPlatformPower(status)
+	return status
+}
+
+// readPlatformPower reports no battery or thermal state
+func readPlatformPower(status *HostStatus) {
+	noPower(status)
 }
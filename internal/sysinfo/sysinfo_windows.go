@@ -62,6 +62,9 @@ func getPlatformHostStatus() *HostStatus {
 	// NPU metrics for Qualcomm (placeholder - requires SDK)
 	status.NPULoad = getNPULoad()
 
+	// Battery and charger
+	readPlatformPower(status)
+
 	return status
 }
 
@@ -0,0 +1,63 @@
+package tasks
+
+import (
+	"context"
+
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+// LocalOpMS is the predicted latency of fast local kinds like SYSINFO
+const LocalOpMS = 10.0
+
+// Image generation comes before LLM generation so the planner reads
+// "draw a picture" as an image request
+func init() {
+	Register(sysInfo{})
+	Register(echo{})
+	Register(imageGenerate{})
+	Register(llmGenerate{})
+}
+
+// sysInfo reports the worker's system status
+type sysInfo struct{}
+
+func (sysInfo) Schema() Schema {
+	return Schema{
+		Kind:        "SYSINFO",
+		Description: "Collect the device's system status",
+		Input:       "ignored",
+		Example:     "collect_status",
+	}
+}
+
+func (sysInfo) Requirements() Requirements { return Requirements{} }
+
+func (sysInfo) Estimate(task *pb.TaskSpec, device *pb.DeviceInfo) *pb.StepCostEstimate {
+	return &pb.StepCostEstimate{PredictedMs: LocalOpMS}
+}
+
+func (sysInfo) Run(ctx context.Context, env Env, req *pb.TaskRequest) (*pb.TaskResult, error) {
+	return Output(env.SysInfo()), nil
+}
+
+// echo returns its input, for testing the task path
+type echo struct{}
+
+func (echo) Schema() Schema {
+	return Schema{
+		Kind:        "ECHO",
+		Description: "Return the input unchanged",
+		Input:       "any text",
+		Example:     "hello",
+	}
+}
+
+func (echo) Requirements() Requirements { return Requirements{} }
+
+func (echo) Estimate(task *pb.TaskSpec, device *pb.DeviceInfo) *pb.StepCostEstimate {
+	return &pb.StepCostEstimate{PredictedMs: LocalOpMS}
+}
+
+func (echo) Run(ctx context.Context, env Env, req *pb.TaskRequest) (*pb.TaskResult, error) {
+	return Output("echo: " + req.Input), nil
+}
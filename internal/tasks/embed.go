@@ -0,0 +1,59 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+// EmbedMemoryMB is the predicted memory of an embedding model
+const EmbedMemoryMB = 512
+
+func init() {
+	Register(embedGenerate{})
+}
+
+// embedGenerate embeds text with the worker's embed provider
+type embedGenerate struct{}
+
+func (embedGenerate) Schema() Schema {
+	return Schema{
+		Kind:        "EMBED",
+		Description: "Embed text with the device's embed provider; the output is the vector as a JSON array",
+		Input:       "text",
+		Example:     "EdgeMesh runs jobs across the devices on your network",
+	}
+}
+
+// Like LLM_GENERATE, the embed provider may be remote
+func (embedGenerate) Requirements() Requirements { return Requirements{} }
+
+// Estimate: embedding only prefills, predicted_ms = prompt_tokens / prefill_tps * 1000
+func (embedGenerate) Estimate(task *pb.TaskSpec, device *pb.DeviceInfo) *pb.StepCostEstimate {
+	prefillTPS, _ := DeviceThroughput(device)
+	tokens := task.PromptTokens
+	if tokens == 0 {
+		tokens = promptTokens(task.Input)
+	}
+	return &pb.StepCostEstimate{
+		PredictedMs:       float64(tokens) / prefillTPS * 1000,
+		PredictedMemoryMb: EmbedMemoryMB,
+	}
+}
+
+func (embedGenerate) Run(ctx context.Context, env Env, req *pb.TaskRequest) (*pb.TaskResult, error) {
+	vectors, err := env.Embed(ctx, []string{req.Input})
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("embed provider returned %d vectors for 1 input", len(vectors))
+	}
+	out, err := json.Marshal(vectors[0])
+	if err != nil {
+		return nil, err
+	}
+	return Output(string(out)), nil
+}
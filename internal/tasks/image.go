@@ -0,0 +1,116 @@
+package tasks
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+// imageKeywords mark a request as image generation
+var imageKeywords = []string{"image", "picture", "photo", "draw", "painting", "artwork", "visualize", "render"}
+
+// imageGenerate renders an image from a text prompt
+type imageGenerate struct{}
+
+func (imageGenerate) Schema() Schema {
+	return Schema{
+		Kind:        "IMAGE_GENERATE",
+		Description: "Generate an image from a text prompt; the output is the saved image's path",
+		Input:       "image prompt",
+		Example:     "a lighthouse at dusk, watercolor",
+	}
+}
+
+// Image generation works on a CPU, only slower, so nothing is required
+func (imageGenerate) Requirements() Requirements { return Requirements{} }
+
+// Estimate: image generation is typically 10-30 seconds on GPU, 30-60s on CPU
+func (imageGenerate) Estimate(task *pb.TaskSpec, device *pb.DeviceInfo) *pb.StepCostEstimate {
+	var latencyMS float64
+	var memoryMB float64
+	var notes string
+
+	// Estimate based on device capabilities
+	if device.HasGpu || device.HasNpu {
+		// GPU/NPU accelerated: ~15 seconds for 512x512, 20 steps
+		latencyMS = 15000
+		memoryMB = 4096 // 4GB for Stable Diffusion
+		notes = "GPU/NPU accelerated image generation"
+	} else {
+		// CPU only: much slower
+		latencyMS = 45000 // 45 seconds
+		memoryMB = 4096
+		notes = "CPU-only image generation (slower)"
+	}
+
+	// Adjust for TinyML on Arduino (if we detect it)
+	if device.Platform == "arduino" || strings.Contains(strings.ToLower(device.DeviceName), "arduino") {
+		latencyMS = 5000 // TinyML is faster but lower quality
+		memoryMB = 256   // Much smaller models
+		notes = "TinyML image generation (lower quality)"
+	}
+
+	return &pb.StepCostEstimate{
+		PredictedMs:       latencyMS,
+		PredictedMemoryMb: memoryMB,
+		Notes:             notes,
+	}
+}
+
+func (imageGenerate) Run(ctx context.Context, env Env, req *pb.TaskRequest) (*pb.TaskResult, error) {
+	imagePath, err := env.GenerateImage(ctx, req.Input)
+	if err != nil {
+		return nil, err
+	}
+	return Output(imagePath), nil
+}
+
+// Plan routes image requests to the first GPU/NPU device
+func (imageGenerate) Plan(text string, devices []*pb.DeviceInfo) *pb.TaskSpec {
+	if !containsAny(strings.ToLower(text), imageKeywords) {
+		return nil
+	}
+	var bestDevice *pb.DeviceInfo
+	for _, d := range devices {
+		if d.HasGpu || d.HasNpu {
+			bestDevice = d
+			break
+		}
+	}
+	if bestDevice == nil && len(devices) > 0 {
+		bestDevice = devices[0]
+	}
+
+	task := &pb.TaskSpec{
+		TaskId:          uuid.New().String(),
+		Kind:            "IMAGE_GENERATE",
+		Input:           text,
+		PromptTokens:    promptTokens(text), // image gen is heavier than text
+		MaxOutputTokens: 100,                // output is just the image path
+	}
+	if bestDevice != nil {
+		task.TargetDeviceId = bestDevice.DeviceId
+	}
+	return task
+}
+
+func containsAny(s string, words []string) bool {
+	for _, w := range words {
+		if strings.Contains(s, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// promptTokens estimates text's tokens at 4 characters each, at least 10
+func promptTokens(text string) int32 {
+	n := int32(len(text) / 4)
+	if n < 10 {
+		n = 10
+	}
+	return n
+}
@@ -0,0 +1,226 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+// Default throughput values (tokens/sec) for different platforms
+const (
+	DefaultLaptopPrefillTPS = 300.0 // macos, windows, linux
+	DefaultLaptopDecodeTPS  = 30.0
+	DefaultPhonePrefillTPS  = 120.0 // android, ios
+	DefaultPhoneDecodeTPS   = 12.0
+	DefaultMemoryMB         = 2048 // conservative LLM memory estimate
+)
+
+// llmKeywords mark a request as text generation
+var llmKeywords = []string{"summarize", "write", "code", "explain", "chat", "answer", "translate"}
+
+// llmGenerate runs a prompt through the worker's chat provider
+type llmGenerate struct{}
+
+func (llmGenerate) Schema() Schema {
+	return Schema{
+		Kind:        "LLM_GENERATE",
+		Description: "Run a prompt through the device's chat provider",
+		Input:       "prompt",
+		Example:     "Explain EdgeMesh in two sentences",
+	}
+}
+
+// The chat provider may be remote, so a local model is preferred by the
+// planner but not required
+func (llmGenerate) Requirements() Requirements { return Requirements{} }
+
+// Estimate: predicted_ms = (prompt_tokens / prefill_tps + max_output_tokens / decode_tps) * 1000
+func (llmGenerate) Estimate(task *pb.TaskSpec, device *pb.DeviceInfo) *pb.StepCostEstimate {
+	prefillTPS, decodeTPS := DeviceThroughput(device)
+
+	var latencyMS float64
+	var notes string
+
+	// Calculate latency based on tokens
+	if task.PromptTokens > 0 || task.MaxOutputTokens > 0 {
+		prefillTime := float64(task.PromptTokens) / prefillTPS
+		decodeTime := float64(task.MaxOutputTokens) / decodeTPS
+		latencyMS = (prefillTime + decodeTime) * 1000
+	} else {
+		// No token info provided, use a default estimate
+		latencyMS = 1000 // 1 second default
+		notes = "no token counts provided, using default estimate"
+	}
+
+	// Check if we used defaults
+	if device.LlmPrefillToksPerS == 0 || device.LlmDecodeToksPerS == 0 {
+		if notes != "" {
+			notes += "; "
+		}
+		notes += "using default throughput for platform"
+	}
+
+	return &pb.StepCostEstimate{
+		PredictedMs:       latencyMS,
+		PredictedMemoryMb: DefaultMemoryMB,
+		Notes:             notes,
+	}
+}
+
+func (llmGenerate) Run(ctx context.Context, env Env, req *pb.TaskRequest) (*pb.TaskResult, error) {
+	output, err := env.Chat(ctx, req.Input)
+	if err != nil {
+		return nil, err
+	}
+	if output == "" {
+		return nil, fmt.Errorf("chat provider returned empty response")
+	}
+	return Output(output), nil
+}
+
+// Plan routes text requests to the best LLM device (NPU > GPU > CPU)
+func (llmGenerate) Plan(text string, devices []*pb.DeviceInfo) *pb.TaskSpec {
+	textLower := strings.ToLower(text)
+	if !containsAny(textLower, llmKeywords) {
+		return nil
+	}
+
+	// First, filter for devices that actually have LLM capability
+	var bestDevice *pb.DeviceInfo
+	if llmDevices := FilterLLMDevices(devices); len(llmDevices) > 0 {
+		// Use smart LLM device selection (NPU > GPU > CPU, fastest prefill)
+		bestDevice = SelectBestLLMDevice(llmDevices)
+		log.Printf("[INFO] GenerateSmartPlan: LLM task routed to %s (has_npu=%v, has_gpu=%v, prefill_tps=%.1f)",
+			bestDevice.DeviceName, bestDevice.HasNpu, bestDevice.HasGpu, bestDevice.LlmPrefillToksPerS)
+	} else {
+		// Fallback: no LLM devices, use NPU/GPU/CPU priority
+		log.Printf("[WARN] GenerateSmartPlan: No LLM-capable devices found, falling back to hardware priority")
+		for _, d := range devices {
+			if d.HasNpu {
+				bestDevice = d
+				break
+			}
+		}
+		if bestDevice == nil {
+			for _, d := range devices {
+				if d.HasGpu {
+					bestDevice = d
+					break
+				}
+			}
+		}
+		if bestDevice == nil && len(devices) > 0 {
+			bestDevice = devices[0]
+		}
+	}
+
+	// Output tokens based on task type
+	maxOutput := int32(300) // default
+	if strings.Contains(textLower, "summarize") {
+		maxOutput = 200
+	} else if strings.Contains(textLower, "code") || strings.Contains(textLower, "write") {
+		maxOutput = 500
+	}
+
+	task := &pb.TaskSpec{
+		TaskId:          uuid.New().String(),
+		Kind:            "LLM_GENERATE",
+		Input:           text,
+		PromptTokens:    promptTokens(text),
+		MaxOutputTokens: maxOutput,
+	}
+	if bestDevice != nil {
+		task.TargetDeviceId = bestDevice.DeviceId
+	}
+	return task
+}
+
+// DeviceThroughput returns a device's LLM throughput, using platform
+// defaults if it did not report any
+func DeviceThroughput(device *pb.DeviceInfo) (prefill, decode float64) {
+	// Use device-reported values if available
+	if device.LlmPrefillToksPerS > 0 && device.LlmDecodeToksPerS > 0 {
+		return device.LlmPrefillToksPerS, device.LlmDecodeToksPerS
+	}
+
+	// Fallback to defaults based on platform
+	switch device.Platform {
+	case "android", "ios":
+		return DefaultPhonePrefillTPS, DefaultPhoneDecodeTPS
+	default:
+		// macos, windows, linux, or unknown
+		return DefaultLaptopPrefillTPS, DefaultLaptopDecodeTPS
+	}
+}
+
+// HasLLMCapability checks if a device has LLM inference capability
+// A device is LLM-capable if it has a local model endpoint (Ollama/LM Studio)
+func HasLLMCapability(device *pb.DeviceInfo) bool {
+	return device.HasLocalModel
+}
+
+// FilterLLMDevices returns only devices that have LLM capability
+func FilterLLMDevices(devices []*pb.DeviceInfo) []*pb.DeviceInfo {
+	llmDevices := make([]*pb.DeviceInfo, 0)
+	for _, d := range devices {
+		if HasLLMCapability(d) {
+			llmDevices = append(llmDevices, d)
+		}
+	}
+	return llmDevices
+}
+
+// SelectBestLLMDevice selects the best device for LLM tasks
+// Priority: NPU > GPU > CPU, then prefer devices with advertised TPS
+func SelectBestLLMDevice(devices []*pb.DeviceInfo) *pb.DeviceInfo {
+	if len(devices) == 0 {
+		return nil
+	}
+
+	var best *pb.DeviceInfo
+
+	// Phase 1: Prefer NPU devices
+	for _, d := range devices {
+		if d.HasNpu {
+			if best == nil {
+				best = d
+			} else if d.LlmPrefillToksPerS > best.LlmPrefillToksPerS {
+				// If multiple NPU devices, pick faster one
+				best = d
+			}
+		}
+	}
+	if best != nil {
+		return best
+	}
+
+	// Phase 2: Prefer GPU devices
+	for _, d := range devices {
+		if d.HasGpu {
+			if best == nil {
+				best = d
+			} else if d.LlmPrefillToksPerS > best.LlmPrefillToksPerS {
+				best = d
+			}
+		}
+	}
+	if best != nil {
+		return best
+	}
+
+	// Phase 3: Fall back to CPU, prefer devices with TPS info, else first available
+	for _, d := range devices {
+		if best == nil {
+			best = d
+		} else if d.LlmPrefillToksPerS > 0 && d.LlmPrefillToksPerS > best.LlmPrefillToksPerS {
+			best = d
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,167 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+const (
+	// MapItemPlaceholder in a MAP prompt is replaced by each item
+	MapItemPlaceholder = "{{item}}"
+	// MapOutputTokens is the assumed output length of one LLM_GENERATE item
+	MapOutputTokens = 300
+	// MapEmbedBatch is how many items a MAP task embeds per provider call
+	MapEmbedBatch = 32
+)
+
+// MapKinds are the kinds a MAP task can run per item
+var MapKinds = []string{"LLM_GENERATE", "EMBED"}
+
+func init() {
+	Register(mapShard{})
+}
+
+// MapShard is the input of a MAP task: a contiguous slice of a job's items
+type MapShard struct {
+	Kind   string   `json:"kind"`             // one of MapKinds
+	Prompt string   `json:"prompt,omitempty"` // LLM_GENERATE only, see RenderMapPrompt
+	Offset int      `json:"offset"`           // index of Items[0] in the job's item list
+	Items  []string `json:"items"`
+	Names  []string `json:"names,omitempty"` // e.g. the file each item was read from
+}
+
+// MapShardResult is the output of a MAP task. Outputs, Embeddings and
+// Errors are indexed like the shard's items.
+type MapShardResult struct {
+	Offset     int         `json:"offset"`
+	Outputs    []string    `json:"outputs,omitempty"`
+	Embeddings [][]float32 `json:"embeddings,omitempty"`
+	Errors     []string    `json:"errors,omitempty"` // empty string = item succeeded
+}
+
+// RenderMapPrompt builds an item's LLM_GENERATE prompt: the placeholder in
+// prompt is replaced by item, or item is appended if there is none
+func RenderMapPrompt(prompt, item string) string {
+	switch {
+	case prompt == "":
+		return item
+	case strings.Contains(prompt, MapItemPlaceholder):
+		return strings.ReplaceAll(prompt, MapItemPlaceholder, item)
+	default:
+		return prompt + "\n\n" + item
+	}
+}
+
+// ValidMapKind reports whether kind can run per item in a MAP task
+func ValidMapKind(kind string) bool {
+	for _, k := range MapKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// mapShard runs LLM_GENERATE or EMBED over every item of a shard
+type mapShard struct{}
+
+func (mapShard) Schema() Schema {
+	return Schema{
+		Kind:        "MAP",
+		Description: "Run LLM_GENERATE or EMBED over a shard of a data-parallel job's items; jobs create these tasks from a map request",
+		Input:       "JSON MapShard: kind, prompt, offset, items",
+		Example:     `{"kind":"LLM_GENERATE","prompt":"Summarize: {{item}}","offset":0,"items":["first document","second document"]}`,
+	}
+}
+
+func (mapShard) Requirements() Requirements { return Requirements{} }
+
+// Estimate sums the per-item cost of the shard's kind
+func (mapShard) Estimate(task *pb.TaskSpec, device *pb.DeviceInfo) *pb.StepCostEstimate {
+	var shard MapShard
+	if err := json.Unmarshal([]byte(task.Input), &shard); err != nil {
+		return &pb.StepCostEstimate{PredictedMs: 1000, Notes: "unreadable MAP shard, using default estimate"}
+	}
+
+	prefillTPS, decodeTPS := DeviceThroughput(device)
+	var latencyMS float64
+	memoryMB := float64(DefaultMemoryMB)
+	for _, item := range shard.Items {
+		if shard.Kind == "EMBED" {
+			latencyMS += float64(promptTokens(item)) / prefillTPS * 1000
+			continue
+		}
+		prompt := RenderMapPrompt(shard.Prompt, item)
+		latencyMS += (float64(promptTokens(prompt))/prefillTPS + MapOutputTokens/decodeTPS) * 1000
+	}
+	if shard.Kind == "EMBED" {
+		memoryMB = EmbedMemoryMB
+	}
+	return &pb.StepCostEstimate{
+		PredictedMs:       latencyMS,
+		PredictedMemoryMb: memoryMB,
+		Notes:             fmt.Sprintf("%d %s item(s)", len(shard.Items), shard.Kind),
+	}
+}
+
+// Run processes every item. A failed item is recorded in Errors and the
+// shard goes on; the task fails only if no item succeeded.
+func (mapShard) Run(ctx context.Context, env Env, req *pb.TaskRequest) (*pb.TaskResult, error) {
+	var shard MapShard
+	if err := json.Unmarshal([]byte(req.Input), &shard); err != nil {
+		return nil, fmt.Errorf("parse MAP shard: %w", err)
+	}
+	if !ValidMapKind(shard.Kind) {
+		return nil, fmt.Errorf("MAP cannot run %q (want %s)", shard.Kind, strings.Join(MapKinds, " or "))
+	}
+
+	n := len(shard.Items)
+	result := MapShardResult{Offset: shard.Offset, Errors: make([]string, n)}
+	failed := 0
+
+	if shard.Kind == "EMBED" {
+		result.Embeddings = make([][]float32, n)
+		for start := 0; start < n; start += MapEmbedBatch {
+			end := min(start+MapEmbedBatch, n)
+			vectors, err := env.Embed(ctx, shard.Items[start:end])
+			if err == nil && len(vectors) != end-start {
+				err = fmt.Errorf("embed provider returned %d vectors for %d inputs", len(vectors), end-start)
+			}
+			for i := start; i < end; i++ {
+				if err != nil {
+					result.Errors[i] = err.Error()
+					failed++
+					continue
+				}
+				result.Embeddings[i] = vectors[i-start]
+			}
+		}
+	} else {
+		result.Outputs = make([]string, n)
+		for i, item := range shard.Items {
+			output, err := env.Chat(ctx, RenderMapPrompt(shard.Prompt, item))
+			if err != nil {
+				result.Errors[i] = err.Error()
+				failed++
+				continue
+			}
+			result.Outputs[i] = output
+		}
+	}
+
+	if n > 0 && failed == n {
+		return nil, fmt.Errorf("all %d item(s) failed, first error: %s", n, result.Errors[0])
+	}
+	if failed == 0 {
+		result.Errors = nil
+	}
+	out, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	return Output(string(out)), nil
+}
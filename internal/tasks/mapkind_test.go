@@ -0,0 +1,81 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+func runMap(t *testing.T, env Env, shard MapShard) (MapShardResult, error) {
+	t.Helper()
+	input, _ := json.Marshal(shard)
+	res, err := mapShard{}.Run(context.Background(), env, &pb.TaskRequest{Input: string(input)})
+	if err != nil {
+		return MapShardResult{}, err
+	}
+	var out MapShardResult
+	if err := json.Unmarshal([]byte(res.Output), &out); err != nil {
+		t.Fatalf("output %q: %v", res.Output, err)
+	}
+	return out, nil
+}
+
+func TestMapGenerate(t *testing.T) {
+	env := &fakeEnv{chat: func(prompt string) (string, error) {
+		if prompt == "Summarize: bad" {
+			return "", errors.New("model crashed")
+		}
+		return "ok " + prompt, nil
+	}}
+
+	out, err := runMap(t, env, MapShard{Kind: "LLM_GENERATE", Prompt: "Summarize: {{item}}", Offset: 4, Items: []string{"a", "bad", "c"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Offset != 4 || !reflect.DeepEqual(out.Outputs, []string{"ok Summarize: a", "", "ok Summarize: c"}) {
+		t.Fatalf("result %+v", out)
+	}
+	if !reflect.DeepEqual(out.Errors, []string{"", "model crashed", ""}) {
+		t.Fatalf("errors %q", out.Errors)
+	}
+
+	if _, err := runMap(t, env, MapShard{Kind: "LLM_GENERATE", Prompt: "Summarize:", Items: []string{"bad"}}); err != nil {
+		t.Fatalf("prompt without placeholder should append the item: %v", err)
+	}
+	env.chat = func(string) (string, error) { return "", errors.New("down") }
+	if _, err := runMap(t, env, MapShard{Kind: "LLM_GENERATE", Items: []string{"a", "b"}}); err == nil {
+		t.Fatal("expected the shard to fail when every item fails")
+	}
+}
+
+func TestMapEmbedBatches(t *testing.T) {
+	var calls int
+	env := &fakeEnv{embed: func(texts []string) ([][]float32, error) {
+		calls++
+		vectors := make([][]float32, len(texts))
+		for i := range texts {
+			vectors[i] = []float32{float32(len(texts[i]))}
+		}
+		return vectors, nil
+	}}
+
+	items := make([]string, MapEmbedBatch+1)
+	for i := range items {
+		items[i] = string(make([]byte, i))
+	}
+	out, err := runMap(t, env, MapShard{Kind: "EMBED", Items: items})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 || len(out.Embeddings) != len(items) || out.Embeddings[MapEmbedBatch][0] != MapEmbedBatch || out.Errors != nil {
+		t.Fatalf("calls=%d result %+v", calls, out)
+	}
+
+	if _, err := runMap(t, env, MapShard{Kind: "SHELL", Items: items}); err == nil {
+		t.Fatal("expected MAP to reject SHELL items")
+	}
+}
@@ -0,0 +1,137 @@
+package tasks
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+// ErrDuplicateKind is returned when a kind is registered twice
+var ErrDuplicateKind = errors.New("task kind already registered")
+
+// LegacyKinds are the kinds workers ran before they advertised
+// DeviceInfo.task_kinds; a device that advertises none is assumed to run
+// these
+var LegacyKinds = []string{"SYSINFO", "ECHO", "LLM_GENERATE", "IMAGE_GENERATE"}
+
+// Registry maps task kinds to their handlers
+type Registry struct {
+	mu       sync.RWMutex
+	handlers map[string]Handler
+	order    []string // registration order; the planner tries kinds in it
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{handlers: make(map[string]Handler)}
+}
+
+// Register adds h under its schema's kind
+func (r *Registry) Register(h Handler) error {
+	kind := h.Schema().Kind
+	if kind == "" {
+		return errors.New("task kind has no name")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.handlers[kind]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateKind, kind)
+	}
+	r.handlers[kind] = h
+	r.order = append(r.order, kind)
+	return nil
+}
+
+// Get returns the handler for kind
+func (r *Registry) Get(kind string) (Handler, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	h, ok := r.handlers[kind]
+	return h, ok
+}
+
+// Kinds returns the registered kinds in registration order
+func (r *Registry) Kinds() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]string(nil), r.order...)
+}
+
+// Handlers returns the registered handlers in registration order
+func (r *Registry) Handlers() []Handler {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	hs := make([]Handler, len(r.order))
+	for i, kind := range r.order {
+		hs[i] = r.handlers[kind]
+	}
+	return hs
+}
+
+// Advertise returns the kinds device can run with this registry's
+// handlers, for DeviceInfo.task_kinds
+func (r *Registry) Advertise(device *pb.DeviceInfo) []string {
+	var kinds []string
+	for _, h := range r.Handlers() {
+		if h.Requirements().Met(device) {
+			kinds = append(kinds, h.Schema().Kind)
+		}
+	}
+	return kinds
+}
+
+// Supports reports whether device can be given a kind task: it advertises
+// the kind and, when the kind is registered here, meets its requirements
+func (r *Registry) Supports(device *pb.DeviceInfo, kind string) bool {
+	advertised := device.TaskKinds
+	if len(advertised) == 0 {
+		advertised = LegacyKinds
+	}
+	found := false
+	for _, k := range advertised {
+		if k == kind {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return false
+	}
+	if h, ok := r.Get(kind); ok {
+		return h.Requirements().Met(device)
+	}
+	return true
+}
+
+// Filter returns the devices that support kind, keeping their order
+func (r *Registry) Filter(devices []*pb.DeviceInfo, kind string) []*pb.DeviceInfo {
+	var out []*pb.DeviceInfo
+	for _, d := range devices {
+		if r.Supports(d, kind) {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// Default holds the built-in kinds and any registered with Register
+var Default = NewRegistry()
+
+// Register adds h to Default. It panics if the kind is taken, like
+// registering a database driver twice.
+func Register(h Handler) {
+	if err := Default.Register(h); err != nil {
+		panic(err)
+	}
+}
+
+// Get returns the handler for kind from Default
+func Get(kind string) (Handler, bool) { return Default.Get(kind) }
+
+// Kinds returns the kinds registered in Default
+func Kinds() []string { return Default.Kinds() }
+
+// Supports reports whether device can be given a kind task, using Default
+func Supports(device *pb.DeviceInfo, kind string) bool { return Default.Supports(device, kind) }
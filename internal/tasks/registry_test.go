@@ -0,0 +1,90 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+// gpuKind is a kind that needs an accelerator
+type gpuKind struct{}
+
+func (gpuKind) Schema() Schema { return Schema{Kind: "GPU_ONLY"} }
+func (gpuKind) Requirements() Requirements {
+	return Requirements{Accelerator: true, Platforms: []string{"linux"}}
+}
+func (gpuKind) Estimate(*pb.TaskSpec, *pb.DeviceInfo) *pb.StepCostEstimate {
+	return &pb.StepCostEstimate{PredictedMs: 1}
+}
+func (gpuKind) Run(context.Context, Env, *pb.TaskRequest) (*pb.TaskResult, error) {
+	return Output(""), nil
+}
+
+func TestRegisterRejectsDuplicates(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register(gpuKind{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Register(gpuKind{}); !errors.Is(err, ErrDuplicateKind) {
+		t.Fatalf("err = %v, want ErrDuplicateKind", err)
+	}
+	if got := r.Kinds(); !reflect.DeepEqual(got, []string{"GPU_ONLY"}) {
+		t.Fatalf("kinds %v", got)
+	}
+}
+
+func TestBuiltinKinds(t *testing.T) {
+	for _, kind := range LegacyKinds {
+		if _, ok := Get(kind); !ok {
+			t.Errorf("built-in kind %s is not registered", kind)
+		}
+	}
+}
+
+func TestSupports(t *testing.T) {
+	r := NewRegistry()
+	r.Register(gpuKind{})
+
+	gpu := &pb.DeviceInfo{Platform: "linux", HasGpu: true, TaskKinds: []string{"ECHO", "GPU_ONLY"}}
+	cpu := &pb.DeviceInfo{Platform: "linux", TaskKinds: []string{"ECHO", "GPU_ONLY"}}
+	legacy := &pb.DeviceInfo{Platform: "linux", HasGpu: true}
+
+	cases := []struct {
+		name   string
+		device *pb.DeviceInfo
+		kind   string
+		want   bool
+	}{
+		{"advertised and capable", gpu, "GPU_ONLY", true},
+		{"advertised, unregistered here", gpu, "ECHO", true},
+		{"not advertised", gpu, "SYSINFO", false},
+		{"requirements not met", cpu, "GPU_ONLY", false},
+		{"older worker runs built-ins", legacy, "LLM_GENERATE", true},
+		{"older worker runs nothing else", legacy, "GPU_ONLY", false},
+	}
+	for _, c := range cases {
+		if got := r.Supports(c.device, c.kind); got != c.want {
+			t.Errorf("%s: Supports(%s) = %v, want %v", c.name, c.kind, got, c.want)
+		}
+	}
+
+	if got := r.Filter([]*pb.DeviceInfo{cpu, gpu}, "GPU_ONLY"); len(got) != 1 || got[0] != gpu {
+		t.Fatalf("Filter returned %v", got)
+	}
+}
+
+func TestAdvertise(t *testing.T) {
+	r := NewRegistry()
+	r.Register(echo{})
+	r.Register(gpuKind{})
+
+	if got := r.Advertise(&pb.DeviceInfo{Platform: "linux", HasNpu: true}); !reflect.DeepEqual(got, []string{"ECHO", "GPU_ONLY"}) {
+		t.Fatalf("NPU device advertises %v", got)
+	}
+	if got := r.Advertise(&pb.DeviceInfo{Platform: "windows", HasGpu: true}); !reflect.DeepEqual(got, []string{"ECHO"}) {
+		t.Fatalf("windows device advertises %v", got)
+	}
+}
@@ -0,0 +1,80 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+const (
+	// ShellTailLines is how many lines of stdout and stderr a SHELL result keeps
+	ShellTailLines = 20
+	// ShellEstimateMS is the predicted latency of a SHELL task; the real
+	// cost depends entirely on the command
+	ShellEstimateMS = 1000.0
+)
+
+func init() {
+	Register(shell{})
+}
+
+// shell runs one command through the worker's command policy
+type shell struct{}
+
+func (shell) Schema() Schema {
+	return Schema{
+		Kind:        "SHELL",
+		Description: "Run a command allowed by the device's command policy; the result carries its exit code, output tails and duration",
+		Input:       "command line, split on whitespace and run without a shell",
+		Example:     "df -h",
+	}
+}
+
+func (shell) Requirements() Requirements { return Requirements{} }
+
+func (shell) Estimate(task *pb.TaskSpec, device *pb.DeviceInfo) *pb.StepCostEstimate {
+	return &pb.StepCostEstimate{
+		PredictedMs: ShellEstimateMS,
+		Notes:       "shell command, cost depends on the command",
+	}
+}
+
+func (shell) Run(ctx context.Context, env Env, req *pb.TaskRequest) (*pb.TaskResult, error) {
+	fields := strings.Fields(req.Input)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+	res, err := env.RunCommand(ctx, fields[0], fields[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	sr := &pb.ShellResult{
+		Command:    strings.Join(fields, " "),
+		ExitCode:   int32(res.ExitCode),
+		StdoutTail: res.StdoutTail(ShellTailLines),
+		StderrTail: res.StderrTail(ShellTailLines),
+		DurationMs: float64(res.Duration.Milliseconds()),
+		TimedOut:   res.TimedOut,
+	}
+	result := &pb.TaskResult{Ok: res.OK(), Output: sr.StdoutTail, Shell: sr}
+	switch {
+	case res.OK():
+	case res.ExitCode < 0 && res.Error != nil:
+		result.Error = res.Error.Error()
+	default:
+		result.Error = fmt.Sprintf("exit code %d", res.ExitCode)
+		if last := lastLine(sr.StderrTail); last != "" {
+			result.Error += ": " + last
+		}
+	}
+	return result, nil
+}
+
+// lastLine returns the last non-empty line of s
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,69 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edgecli/edgecli/internal/exec"
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+// fakeEnv returns canned results
+type fakeEnv struct {
+	res  *exec.Result
+	err  error
+	name string
+	args []string
+
+	chat  func(prompt string) (string, error)
+	embed func(texts []string) ([][]float32, error)
+}
+
+func (e *fakeEnv) SysInfo() string { return "" }
+func (e *fakeEnv) Chat(_ context.Context, prompt string) (string, error) {
+	return e.chat(prompt)
+}
+func (e *fakeEnv) GenerateImage(context.Context, string) (string, error) { return "", nil }
+func (e *fakeEnv) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	return e.embed(texts)
+}
+func (e *fakeEnv) RunCommand(ctx context.Context, name string, args []string) (*exec.Result, error) {
+	e.name, e.args = name, args
+	return e.res, e.err
+}
+
+func TestShellRun(t *testing.T) {
+	env := &fakeEnv{res: &exec.Result{Stdout: "Filesystem  Size\n/dev/sda1   100G\n", Duration: 42 * time.Millisecond}}
+	res, err := shell{}.Run(context.Background(), env, &pb.TaskRequest{Input: "df  -h"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env.name != "df" || len(env.args) != 1 || env.args[0] != "-h" {
+		t.Fatalf("ran %s %v", env.name, env.args)
+	}
+	if !res.Ok || res.Shell.ExitCode != 0 || res.Shell.DurationMs != 42 || !strings.Contains(res.Output, "/dev/sda1") {
+		t.Fatalf("result %+v", res)
+	}
+}
+
+func TestShellRunFailure(t *testing.T) {
+	env := &fakeEnv{res: &exec.Result{ExitCode: 2, Stderr: "warning\nno such file\n"}}
+	res, err := shell{}.Run(context.Background(), env, &pb.TaskRequest{Input: "ls /missing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Ok || res.Shell.ExitCode != 2 || res.Error != "exit code 2: no such file" {
+		t.Fatalf("result ok=%v exit=%d error=%q", res.Ok, res.Shell.ExitCode, res.Error)
+	}
+
+	env = &fakeEnv{err: errors.New("command not allowed")}
+	if _, err := (shell{}).Run(context.Background(), env, &pb.TaskRequest{Input: "rm -rf /"}); err == nil {
+		t.Fatal("expected the policy error")
+	}
+	if _, err := (shell{}).Run(context.Background(), env, &pb.TaskRequest{Input: "  "}); err == nil {
+		t.Fatal("expected an error for an empty command")
+	}
+}
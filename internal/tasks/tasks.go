@@ -0,0 +1,94 @@
+// Package tasks defines the task kinds workers run. Each kind is a Handler
+// that brings its own execution, input schema, capability requirements and
+// cost model; RunTask, the cost estimator and the job planner look kinds up
+// in a Registry instead of listing them.
+package tasks
+
+import (
+	"context"
+
+	"github.com/edgecli/edgecli/internal/exec"
+	pb "github.com/edgecli/edgecli/proto"
+)
+
+// Schema describes a task kind and what its input holds
+type Schema struct {
+	Kind        string `json:"kind"`
+	Description string `json:"description"`
+	Input       string `json:"input"`                 // what TaskSpec.input holds
+	Example     string `json:"example,omitempty"`     // a sample input
+	InputPaths  bool   `json:"input_paths,omitempty"` // reads the task's staged input files
+}
+
+// Requirements are the device capabilities a kind cannot run without.
+// Zero values require nothing.
+type Requirements struct {
+	Accelerator   bool     `json:"accelerator,omitempty"`    // a GPU or NPU
+	LocalModel    bool     `json:"local_model,omitempty"`    // a local chat model (Ollama, LM Studio)
+	ScreenCapture bool     `json:"screen_capture,omitempty"` // screen capture works
+	Platforms     []string `json:"platforms,omitempty"`      // GOOS values; any if empty
+}
+
+// Met reports whether device has every required capability
+func (r Requirements) Met(device *pb.DeviceInfo) bool {
+	if r.Accelerator && !device.HasGpu && !device.HasNpu {
+		return false
+	}
+	if r.LocalModel && !device.HasLocalModel {
+		return false
+	}
+	if r.ScreenCapture && !device.CanScreenCapture {
+		return false
+	}
+	if len(r.Platforms) == 0 {
+		return true
+	}
+	for _, p := range r.Platforms {
+		if p == device.Platform {
+			return true
+		}
+	}
+	return false
+}
+
+// Env is what handlers can use of the worker running them
+type Env interface {
+	// SysInfo returns the worker's system status report
+	SysInfo() string
+	// Chat runs prompt through the worker's chat provider
+	Chat(ctx context.Context, prompt string) (string, error)
+	// GenerateImage renders prompt and returns the saved image's path
+	GenerateImage(ctx context.Context, prompt string) (string, error)
+	// RunCommand runs name with args if the worker's command policy
+	// allows it, and returns an error if it does not
+	RunCommand(ctx context.Context, name string, args []string) (*exec.Result, error)
+	// Embed returns one embedding vector per text from the worker's embed
+	// provider
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Handler is one task kind
+type Handler interface {
+	Schema() Schema
+	Requirements() Requirements
+	// Estimate predicts the task's latency and memory on device, leaving
+	// out input staging; the estimator fills in TaskId and Kind
+	Estimate(task *pb.TaskSpec, device *pb.DeviceInfo) *pb.StepCostEstimate
+	// Run executes req on this worker. An error means the task could not
+	// run; a result may still report failure with Ok false, e.g. a command
+	// that exited non-zero. RunTask fills in TaskId and TimeMs.
+	Run(ctx context.Context, env Env, req *pb.TaskRequest) (*pb.TaskResult, error)
+}
+
+// Output is a successful result carrying output
+func Output(output string) *pb.TaskResult {
+	return &pb.TaskResult{Ok: true, Output: output}
+}
+
+// Planner is implemented by kinds the job planner can pick from the
+// wording of a request
+type Planner interface {
+	// Plan returns a task for text, targeted at one of devices, or nil if
+	// text does not ask for this kind. devices all support the kind.
+	Plan(text string, devices []*pb.DeviceInfo) *pb.TaskSpec
+}
@@ -0,0 +1,337 @@
+package trace
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// batchSize is how many spans are exported at once
+	batchSize = 256
+	// flushInterval is the longest an ended span waits for export
+	flushInterval = 2 * time.Second
+	// queueSize bounds the spans waiting for export; more are dropped
+	queueSize = 4096
+	// exportTimeout bounds one export call
+	exportTimeout = 10 * time.Second
+)
+
+// Exporter sends batches of ended spans somewhere
+type Exporter interface {
+	Export(ctx context.Context, spans []SpanData) error
+}
+
+var (
+	active  atomic.Bool
+	queue   chan SpanData
+	dropped atomic.Int64
+	startMu sync.Mutex
+)
+
+// exporting reports whether ended spans are exported at all
+func exporting() bool { return active.Load() }
+
+func enqueue(s SpanData) {
+	select {
+	case queue <- s:
+	default:
+		dropped.Add(1)
+	}
+}
+
+// Configure exports ended spans to exps in batches from a background
+// goroutine. Without exporters spans still carry IDs but are not recorded.
+// The returned shutdown exports whatever is still queued.
+func Configure(exps ...Exporter) (shutdown func(context.Context)) {
+	startMu.Lock()
+	defer startMu.Unlock()
+	if len(exps) == 0 || active.Load() {
+		return func(context.Context) {}
+	}
+
+	queue = make(chan SpanData, queueSize)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	active.Store(true)
+	go run(exps, stop, done)
+
+	var once sync.Once
+	return func(ctx context.Context) {
+		once.Do(func() { close(stop) })
+		select {
+		case <-done:
+		case <-ctx.Done():
+		}
+	}
+}
+
+// run batches queued spans until stop is closed, then exports the rest
+func run(exps []Exporter, stop, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]SpanData, 0, batchSize)
+	flush := func() {
+		if n := dropped.Swap(0); n > 0 {
+			log.Printf("[WARN] Tracing: dropped %d span(s), export is falling behind", n)
+		}
+		if len(batch) == 0 {
+			return
+		}
+		for _, e := range exps {
+			ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+			if err := e.Export(ctx, batch); err != nil {
+				log.Printf("[WARN] Tracing: export of %d span(s) failed: %v", len(batch), err)
+			}
+			cancel()
+		}
+		batch = make([]SpanData, 0, batchSize)
+	}
+
+	for {
+		select {
+		case s := <-queue:
+			batch = append(batch, s)
+			if len(batch) >= batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-stop:
+			active.Store(false)
+			for {
+				select {
+				case s := <-queue:
+					batch = append(batch, s)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// ExportersFromEnv returns the exporters configured by TRACE_FILE (OTLP/JSON
+// lines appended to a file) and OTEL_EXPORTER_OTLP_TRACES_ENDPOINT or
+// OTEL_EXPORTER_OTLP_ENDPOINT (OTLP/HTTP with JSON bodies; headers from
+// OTEL_EXPORTER_OTLP_HEADERS). resource describes this process, e.g.
+// service.name, which OTEL_SERVICE_NAME overrides.
+func ExportersFromEnv(resource ...Attr) ([]Exporter, error) {
+	if name := os.Getenv("OTEL_SERVICE_NAME"); name != "" {
+		resource = append([]Attr{String("service.name", name)}, resource...)
+		for i := 1; i < len(resource); i++ {
+			if resource[i].Key == "service.name" {
+				resource = append(resource[:i], resource[i+1:]...)
+				break
+			}
+		}
+	}
+
+	var exps []Exporter
+	if path := os.Getenv("TRACE_FILE"); path != "" {
+		e, err := NewFileExporter(path, resource...)
+		if err != nil {
+			return nil, err
+		}
+		exps = append(exps, e)
+	}
+
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	if endpoint == "" {
+		if base := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); base != "" {
+			endpoint = strings.TrimRight(base, "/") + "/v1/traces"
+		}
+	}
+	if endpoint != "" {
+		e := NewOTLPExporter(endpoint, resource...)
+		for _, kv := range strings.Split(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"), ",") {
+			if k, v, ok := strings.Cut(kv, "="); ok {
+				e.Headers[strings.TrimSpace(k)] = strings.TrimSpace(v)
+			}
+		}
+		exps = append(exps, e)
+	}
+	return exps, nil
+}
+
+// FileExporter appends each batch to a file as one line of OTLP/JSON, the
+// format of the OpenTelemetry Collector's file exporter
+type FileExporter struct {
+	mu       sync.Mutex
+	f        *os.File
+	resource []Attr
+}
+
+// NewFileExporter opens path for appending
+func NewFileExporter(path string, resource ...Attr) (*FileExporter, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open trace file: %w", err)
+	}
+	return &FileExporter{f: f, resource: resource}, nil
+}
+
+// Export writes spans as one line
+func (e *FileExporter) Export(ctx context.Context, spans []SpanData) error {
+	data, err := EncodeOTLP(spans, e.resource...)
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err = e.f.Write(append(data, '\n'))
+	return err
+}
+
+// OTLPExporter posts batches to an OTLP/HTTP collector as JSON
+type OTLPExporter struct {
+	Endpoint string            // e.g. http://localhost:4318/v1/traces
+	Headers  map[string]string // added to every request
+	resource []Attr
+	client   *http.Client
+}
+
+// NewOTLPExporter returns an exporter posting to endpoint
+func NewOTLPExporter(endpoint string, resource ...Attr) *OTLPExporter {
+	return &OTLPExporter{
+		Endpoint: endpoint,
+		Headers:  make(map[string]string),
+		resource: resource,
+		// Its own transport, so exports are never traced themselves
+		client: &http.Client{Transport: &http.Transport{Proxy: http.ProxyFromEnvironment}},
+	}
+}
+
+// Export posts spans to the collector
+func (e *OTLPExporter) Export(ctx context.Context, spans []SpanData) error {
+	data, err := EncodeOTLP(spans, e.resource...)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.Endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("collector returned %s", resp.Status)
+	}
+	return nil
+}
+
+// OTLP/JSON shapes (opentelemetry-proto ExportTraceServiceRequest)
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpSpan struct {
+		TraceID           string         `json:"traceId"`
+		SpanID            string         `json:"spanId"`
+		ParentSpanID      string         `json:"parentSpanId,omitempty"`
+		Name              string         `json:"name"`
+		Kind              int            `json:"kind"`
+		StartTimeUnixNano string         `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+		Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+		Status            otlpStatus     `json:"status"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code,omitempty"`
+		Message string `json:"message,omitempty"`
+	}
+	otlpKeyValue struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+	otlpValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"` // int64 as a decimal string
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+	}
+)
+
+// EncodeOTLP returns spans as an OTLP/JSON ExportTraceServiceRequest
+func EncodeOTLP(spans []SpanData, resource ...Attr) ([]byte, error) {
+	out := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		sp := otlpSpan{
+			TraceID:           s.TraceID.String(),
+			SpanID:            s.SpanID.String(),
+			Name:              s.Name,
+			Kind:              int(s.Kind),
+			StartTimeUnixNano: strconv.FormatInt(s.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.End.UnixNano(), 10),
+			Attributes:        otlpAttrs(s.Attrs),
+			Status:            otlpStatus{Code: s.StatusCode, Message: s.StatusMessage},
+		}
+		if s.ParentSpanID.IsValid() {
+			sp.ParentSpanID = s.ParentSpanID.String()
+		}
+		out = append(out, sp)
+	}
+	return json.Marshal(otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: otlpAttrs(resource)},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: "edgemesh"}, Spans: out}},
+	}}})
+}
+
+func otlpAttrs(attrs []Attr) []otlpKeyValue {
+	out := make([]otlpKeyValue, 0, len(attrs))
+	for _, a := range attrs {
+		var v otlpValue
+		switch x := a.Value.(type) {
+		case string:
+			v.StringValue = &x
+		case bool:
+			v.BoolValue = &x
+		case int:
+			s := strconv.Itoa(x)
+			v.IntValue = &s
+		case int64:
+			s := strconv.FormatInt(x, 10)
+			v.IntValue = &s
+		case float64:
+			v.DoubleValue = &x
+		default:
+			s := fmt.Sprint(x)
+			v.StringValue = &s
+		}
+		out = append(out, otlpKeyValue{Key: a.Key, Value: v})
+	}
+	return out
+}
@@ -0,0 +1,101 @@
+package trace
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// methodName turns "/pkg.Service/Method" into "pkg.Service/Method"
+func methodName(fullMethod string) string {
+	return strings.TrimPrefix(fullMethod, "/")
+}
+
+// Root decides whether a request without a remote parent, named by its
+// gRPC method or "METHOD /path", starts a new trace. Requests that carry a
+// traceparent are always traced. A nil Root starts a trace for every request.
+type Root func(name string) bool
+
+// serverSpan continues the remote trace in ctx, if any, or starts one if root allows
+func serverSpan(ctx context.Context, name string, root Root, attrs ...Attr) (context.Context, *Span) {
+	if !SpanContextFrom(ctx).IsValid() && root != nil && !root(name) {
+		return ctx, nil
+	}
+	return StartKind(ctx, name, KindServer, attrs...)
+}
+
+// serverContext continues the trace named by the call's traceparent metadata
+func serverContext(ctx context.Context, fullMethod string, root Root) (context.Context, *Span) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(TraceparentHeader); len(v) > 0 {
+			ctx = Extract(ctx, v[0])
+		}
+	}
+	return serverSpan(ctx, methodName(fullMethod), root, String("rpc.system", "grpc"))
+}
+
+func endRPC(span *Span, err error) {
+	if err != nil {
+		span.SetAttributes(String("rpc.grpc.status_code", status.Code(err).String()))
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+// UnaryServerInterceptor starts a server span for unary calls
+func UnaryServerInterceptor(root Root) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, span := serverContext(ctx, info.FullMethod, root)
+		resp, err := handler(ctx, req)
+		endRPC(span, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor starts a server span for streaming calls
+func StreamServerInterceptor(root Root) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, span := serverContext(ss.Context(), info.FullMethod, root)
+		err := handler(srv, &tracedStream{ServerStream: ss, ctx: ctx})
+		endRPC(span, err)
+		return err
+	}
+}
+
+// tracedStream hands the handler a context carrying the server span
+type tracedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *tracedStream) Context() context.Context { return s.ctx }
+
+// clientContext starts a client span and puts its traceparent in the
+// outgoing metadata. Calls made outside any trace are not traced.
+func clientContext(ctx context.Context, fullMethod string) (context.Context, *Span) {
+	if !SpanContextFrom(ctx).IsValid() {
+		return ctx, nil
+	}
+	ctx, span := StartKind(ctx, methodName(fullMethod), KindClient, String("rpc.system", "grpc"))
+	return metadata.AppendToOutgoingContext(ctx, TraceparentHeader, FormatTraceparent(span.SpanContext())), span
+}
+
+// UnaryClientInterceptor propagates the trace to the server of a unary call
+func UnaryClientInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	ctx, span := clientContext(ctx, method)
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	endRPC(span, err)
+	return err
+}
+
+// StreamClientInterceptor propagates the trace to the server of a streaming
+// call. The span covers opening the stream.
+func StreamClientInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	ctx, span := clientContext(ctx, method)
+	cs, err := streamer(ctx, desc, cc, method, opts...)
+	endRPC(span, err)
+	return cs, err
+}
@@ -0,0 +1,83 @@
+package trace
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// Transport propagates the trace of a request's context to the server and
+// records a client span for it. Requests made outside any trace pass through.
+type Transport struct {
+	Base http.RoundTripper // http.DefaultTransport if nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if !SpanContextFrom(req.Context()).IsValid() {
+		return base.RoundTrip(req)
+	}
+
+	ctx, span := StartKind(req.Context(), "HTTP "+req.Method, KindClient,
+		String("http.request.method", req.Method),
+		String("url.full", req.URL.Redacted()),
+	)
+	req = req.Clone(ctx)
+	req.Header.Set(TraceparentHeader, FormatTraceparent(span.SpanContext()))
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+	} else {
+		span.SetAttributes(Int("http.response.status_code", int64(resp.StatusCode)))
+		if resp.StatusCode >= 500 {
+			span.SetError(resp.Status)
+		}
+	}
+	span.End()
+	return resp, err
+}
+
+// Handler starts a server span for requests, continuing the trace of the
+// request's traceparent header
+func Handler(next http.Handler, root Root) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := Extract(r.Context(), r.Header.Get(TraceparentHeader))
+		ctx, span := serverSpan(ctx, r.Method+" "+r.URL.Path, root,
+			String("http.request.method", r.Method),
+			String("url.path", r.URL.Path),
+		)
+		if span == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r.WithContext(ctx))
+		span.SetAttributes(Int("http.response.status_code", int64(sw.status)))
+		if sw.status >= 500 {
+			span.SetError(strconv.Itoa(sw.status) + " " + http.StatusText(sw.status))
+		}
+		span.End()
+	})
+}
+
+// statusWriter remembers the response status
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Flush keeps streamed responses flowing through the wrapper
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,52 @@
+package trace
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+)
+
+// TraceparentHeader carries the span context across processes, in HTTP
+// headers and gRPC metadata (https://www.w3.org/TR/trace-context/)
+const TraceparentHeader = "traceparent"
+
+// FormatTraceparent returns sc as a version 00, sampled traceparent
+func FormatTraceparent(sc SpanContext) string {
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-01"
+}
+
+// ParseTraceparent parses a traceparent value; ok is false if it is malformed
+func ParseTraceparent(v string) (sc SpanContext, ok bool) {
+	parts := strings.Split(strings.TrimSpace(v), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" ||
+		len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return SpanContext{}, false
+	}
+	// Version 00 has exactly four fields; later versions may add more
+	if parts[0] == "00" && len(parts) != 4 {
+		return SpanContext{}, false
+	}
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return SpanContext{}, false
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return SpanContext{}, false
+	}
+	return sc, sc.IsValid()
+}
+
+// Inject sets the traceparent of ctx's span through set, if there is one
+func Inject(ctx context.Context, set func(key, value string)) {
+	if sc := SpanContextFrom(ctx); sc.IsValid() {
+		set(TraceparentHeader, FormatTraceparent(sc))
+	}
+}
+
+// Extract returns ctx with the remote parent named by a traceparent value,
+// or ctx unchanged if the value is missing or malformed
+func Extract(ctx context.Context, traceparent string) context.Context {
+	if sc, ok := ParseTraceparent(traceparent); ok {
+		return WithRemote(ctx, sc)
+	}
+	return ctx
+}
@@ -0,0 +1,237 @@
+// Package trace records spans of work across devices, compatible with
+// OpenTelemetry: W3C traceparent propagation over gRPC metadata and HTTP
+// headers, and export as OTLP/JSON to a file or an OTLP/HTTP collector
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// TraceID identifies a trace, shared by all its spans
+type TraceID [16]byte
+
+// SpanID identifies a span within its trace
+type SpanID [8]byte
+
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+func (s SpanID) String() string  { return hex.EncodeToString(s[:]) }
+
+// IsValid reports whether t is not all zeros
+func (t TraceID) IsValid() bool { return t != TraceID{} }
+
+// IsValid reports whether s is not all zeros
+func (s SpanID) IsValid() bool { return s != SpanID{} }
+
+// SpanContext is what a child span, local or remote, needs of its parent
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+}
+
+// IsValid reports whether both IDs are set
+func (sc SpanContext) IsValid() bool { return sc.TraceID.IsValid() && sc.SpanID.IsValid() }
+
+// Kind is the role of a span in a call, as OTLP numbers them
+type Kind int
+
+const (
+	KindInternal Kind = 1
+	KindServer   Kind = 2
+	KindClient   Kind = 3
+)
+
+// Status codes, as OTLP numbers them
+const (
+	StatusUnset = 0
+	StatusOK    = 1
+	StatusError = 2
+)
+
+// Attr is a span attribute. Values are strings, bools, ints or floats.
+type Attr struct {
+	Key   string
+	Value interface{}
+}
+
+// String returns a string attribute
+func String(key, value string) Attr { return Attr{key, value} }
+
+// Int returns an integer attribute
+func Int(key string, value int64) Attr { return Attr{key, value} }
+
+// Bool returns a boolean attribute
+func Bool(key string, value bool) Attr { return Attr{key, value} }
+
+// Float returns a floating point attribute
+func Float(key string, value float64) Attr { return Attr{key, value} }
+
+// Span is one timed operation. A nil *Span is valid and records nothing.
+type Span struct {
+	mu       sync.Mutex
+	sc       SpanContext
+	parent   SpanID
+	name     string
+	kind     Kind
+	start    time.Time
+	end      time.Time
+	attrs    []Attr
+	status   int
+	message  string
+	ended    bool
+	recorded bool // exported when ended
+}
+
+// SpanData is an ended span as exporters see it
+type SpanData struct {
+	TraceID       TraceID
+	SpanID        SpanID
+	ParentSpanID  SpanID
+	Name          string
+	Kind          Kind
+	Start         time.Time
+	End           time.Time
+	Attrs         []Attr
+	StatusCode    int
+	StatusMessage string
+}
+
+type spanKey struct{}
+type remoteKey struct{}
+
+// Start begins an internal span as a child of the span in ctx, or a new
+// trace if there is none
+func Start(ctx context.Context, name string, attrs ...Attr) (context.Context, *Span) {
+	return StartKind(ctx, name, KindInternal, attrs...)
+}
+
+// StartKind begins a span of the given kind
+func StartKind(ctx context.Context, name string, kind Kind, attrs ...Attr) (context.Context, *Span) {
+	parent := SpanContextFrom(ctx)
+	s := &Span{
+		name:     name,
+		kind:     kind,
+		start:    time.Now(),
+		attrs:    attrs,
+		parent:   parent.SpanID,
+		recorded: exporting(),
+	}
+	if parent.TraceID.IsValid() {
+		s.sc.TraceID = parent.TraceID
+	} else {
+		rand.Read(s.sc.TraceID[:])
+	}
+	rand.Read(s.sc.SpanID[:])
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// FromContext returns the span in ctx, or nil
+func FromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+// SpanContextFrom returns the context of the span in ctx, or of the remote
+// parent taken from a request, or a zero SpanContext
+func SpanContextFrom(ctx context.Context) SpanContext {
+	if s := FromContext(ctx); s != nil {
+		return s.sc
+	}
+	sc, _ := ctx.Value(remoteKey{}).(SpanContext)
+	return sc
+}
+
+// WithRemote returns ctx with sc as the parent of spans started from it
+func WithRemote(ctx context.Context, sc SpanContext) context.Context {
+	if !sc.IsValid() {
+		return ctx
+	}
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+// Detach returns a background context carrying only ctx's span context,
+// for work that outlives the request that started it
+func Detach(ctx context.Context) context.Context {
+	return WithRemote(context.Background(), SpanContextFrom(ctx))
+}
+
+// TraceIDFrom returns the trace ID of ctx's span as hex, or ""
+func TraceIDFrom(ctx context.Context) string {
+	sc := SpanContextFrom(ctx)
+	if !sc.TraceID.IsValid() {
+		return ""
+	}
+	return sc.TraceID.String()
+}
+
+// SpanContext returns the span's IDs
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.sc
+}
+
+// SetAttributes adds attributes to the span
+func (s *Span) SetAttributes(attrs ...Attr) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.attrs = append(s.attrs, attrs...)
+	s.mu.Unlock()
+}
+
+// RecordError marks the span failed with err; a nil err does nothing
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.SetError(err.Error())
+}
+
+// SetError marks the span failed with message
+func (s *Span) SetError(message string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.status = StatusError
+	s.message = message
+	s.mu.Unlock()
+}
+
+// End ends the span and queues it for export. Later calls do nothing.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	data := SpanData{
+		TraceID:       s.sc.TraceID,
+		SpanID:        s.sc.SpanID,
+		ParentSpanID:  s.parent,
+		Name:          s.name,
+		Kind:          s.kind,
+		Start:         s.start,
+		End:           s.end,
+		Attrs:         append([]Attr{}, s.attrs...),
+		StatusCode:    s.status,
+		StatusMessage: s.message,
+	}
+	recorded := s.recorded
+	s.mu.Unlock()
+
+	if recorded {
+		enqueue(data)
+	}
+}
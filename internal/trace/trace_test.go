@@ -0,0 +1,126 @@
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestTraceparentRoundTrip(t *testing.T) {
+	_, span := Start(context.Background(), "op")
+	sc := span.SpanContext()
+	got, ok := ParseTraceparent(FormatTraceparent(sc))
+	if !ok || got != sc {
+		t.Fatalf("round trip = %v, %v; want %v", got, ok, sc)
+	}
+
+	for _, bad := range []string{
+		"",
+		"00-00000000000000000000000000000000-0000000000000001-01", // zero trace ID
+		"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331",    // missing flags
+		"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01-x",
+		"ff-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+		"00-0af7651916cd43dd8448eb211c80319z-b7ad6b7169203331-01",
+	} {
+		if _, ok := ParseTraceparent(bad); ok {
+			t.Errorf("ParseTraceparent(%q) accepted", bad)
+		}
+	}
+}
+
+func TestChildSpansShareTrace(t *testing.T) {
+	ctx, parent := Start(context.Background(), "parent")
+	_, child := Start(ctx, "child")
+	if child.SpanContext().TraceID != parent.SpanContext().TraceID {
+		t.Fatal("child started a new trace")
+	}
+	if child.parent != parent.SpanContext().SpanID {
+		t.Fatal("child's parent is not the parent span")
+	}
+	if TraceIDFrom(ctx) != parent.SpanContext().TraceID.String() {
+		t.Fatal("TraceIDFrom does not match the span")
+	}
+
+	// Across a process boundary the trace continues from the header
+	var header string
+	Inject(ctx, func(k, v string) { header = v })
+	remote := Extract(context.Background(), header)
+	_, server := Start(Detach(remote), "server")
+	if server.SpanContext().TraceID != parent.SpanContext().TraceID || server.parent != parent.SpanContext().SpanID {
+		t.Fatal("remote span is not a child of the injected span")
+	}
+}
+
+type captureExporter struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+func (c *captureExporter) Export(ctx context.Context, spans []SpanData) error {
+	c.mu.Lock()
+	c.spans = append(c.spans, spans...)
+	c.mu.Unlock()
+	return nil
+}
+
+func TestExportThroughHTTP(t *testing.T) {
+	capture := &captureExporter{}
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+	file, err := NewFileExporter(path, String("service.name", "test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	shutdown := Configure(capture, file)
+
+	// Only requests continuing a trace are traced
+	srv := httptest.NewServer(Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}), func(string) bool { return false }))
+	defer srv.Close()
+
+	ctx, root := Start(context.Background(), "root", Int("n", 3))
+	req, _ := http.NewRequestWithContext(ctx, "GET", srv.URL+"/x", nil)
+	resp, err := (&http.Client{Transport: &Transport{}}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	resp, err = http.Get(srv.URL + "/untraced")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	root.RecordError(os.ErrNotExist)
+	root.End()
+	shutdown(context.Background())
+
+	byName := map[string]SpanData{}
+	for _, s := range capture.spans {
+		byName[s.Name] = s
+	}
+	client, server, r := byName["HTTP GET"], byName["GET /x"], byName["root"]
+	if r.StatusCode != StatusError || client.ParentSpanID != r.SpanID || server.ParentSpanID != client.SpanID {
+		t.Fatalf("unexpected spans: %+v", capture.spans)
+	}
+	if server.TraceID != r.TraceID || server.Kind != KindServer {
+		t.Fatalf("server span not in the trace: %+v", server)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var req0 otlpRequest
+	if err := json.Unmarshal(data, &req0); err != nil {
+		t.Fatalf("file is not one OTLP/JSON line: %v\n%s", err, data)
+	}
+	spans := req0.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 3 || *req0.ResourceSpans[0].Resource.Attributes[0].Value.StringValue != "test" {
+		t.Fatalf("unexpected export: %s", data)
+	}
+}
@@ -0,0 +1,87 @@
+package transfer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"time"
+)
+
+// maxChecksumEntries bounds the digest cache; the least recently used
+// digests are dropped first
+const maxChecksumEntries = 4096
+
+// checksumEntry caches a file digest along with the stat fields it was
+// computed from, so a changed file is rehashed.
+type checksumEntry struct {
+	path    string
+	size    int64
+	modTime time.Time
+	sum     string
+}
+
+// FileSHA256 returns the lowercase hex SHA-256 digest of the file at path.
+func FileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Checksum returns the SHA-256 of path, reusing a cached digest while the
+// file's size and modification time are unchanged.
+func (m *Manager) Checksum(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+
+	m.mu.Lock()
+	if el, ok := m.checksums[path]; ok {
+		entry := el.Value.(*checksumEntry)
+		if entry.size == info.Size() && entry.modTime.Equal(info.ModTime()) {
+			m.checksumLRU.MoveToFront(el)
+			m.mu.Unlock()
+			return entry.sum, nil
+		}
+	}
+	m.mu.Unlock()
+
+	// Hash outside the lock; concurrent callers may both hash, which is harmless.
+	sum, err := FileSHA256(path)
+	if err != nil {
+		return "", err
+	}
+
+	// Only cache if the file did not change while it was being read.
+	if after, err := os.Stat(path); err == nil && after.Size() == info.Size() && after.ModTime().Equal(info.ModTime()) {
+		m.mu.Lock()
+		m.cacheChecksumLocked(&checksumEntry{path: path, size: info.Size(), modTime: info.ModTime(), sum: sum})
+		m.mu.Unlock()
+	}
+	return sum, nil
+}
+
+// cacheChecksumLocked stores entry, evicting the least recently used
+// digests beyond maxChecksumEntries. Caller must hold m.mu.
+func (m *Manager) cacheChecksumLocked(entry *checksumEntry) {
+	if el, ok := m.checksums[entry.path]; ok {
+		el.Value = entry
+		m.checksumLRU.MoveToFront(el)
+		return
+	}
+	m.checksums[entry.path] = m.checksumLRU.PushFront(entry)
+	for m.checksumLRU.Len() > maxChecksumEntries {
+		oldest := m.checksumLRU.Back()
+		m.checksumLRU.Remove(oldest)
+		delete(m.checksums, oldest.Value.(*checksumEntry).path)
+	}
+}
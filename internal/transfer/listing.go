@@ -0,0 +1,192 @@
+package transfer
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Listing defaults and limits for ListDir.
+const (
+	DefaultPageSize = 100
+	MaxPageSize     = 1000
+)
+
+// Entry types reported by ListDir and Stat.
+const (
+	EntryFile    = "file"
+	EntryDir     = "dir"
+	EntrySymlink = "symlink"
+	EntryOther   = "other"
+)
+
+// FileEntry describes one file or directory under an exported root.
+type FileEntry struct {
+	Name      string
+	Path      string // slash-separated, relative to the root
+	Type      string // EntryFile, EntryDir, EntrySymlink or EntryOther
+	SizeBytes int64
+	ModTime   time.Time
+	Mode      string // e.g. "-rw-r--r--"
+}
+
+// ListOptions controls filtering and pagination for ListDir.
+type ListOptions struct {
+	Glob          string // path.Match pattern applied to entry names
+	PageSize      int
+	PageToken     string // opaque token from a previous ListPage
+	IncludeHidden bool   // include dot-files (upload .part files are always hidden)
+}
+
+// ListPage is one page of directory entries.
+type ListPage struct {
+	Path          string // listed directory, relative to the root
+	Entries       []FileEntry
+	NextPageToken string // empty on the last page
+	TotalMatches  int
+}
+
+// resolveExported maps rel onto root, allowing the root itself, and checks
+// that symlinks do not lead outside of it.
+func resolveExported(root, rel string) (string, error) {
+	rel = strings.TrimSpace(rel)
+	if rel == "" || rel == "." || rel == "/" {
+		return filepath.Clean(root), nil
+	}
+	return ResolveUnderRoot(root, rel)
+}
+
+// entryFromInfo builds a FileEntry without following symlinks.
+func entryFromInfo(root, full string, info os.FileInfo) FileEntry {
+	rel, err := filepath.Rel(root, full)
+	if err != nil {
+		rel = info.Name()
+	}
+
+	entryType := EntryOther
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		entryType = EntrySymlink
+	case info.IsDir():
+		entryType = EntryDir
+	case info.Mode().IsRegular():
+		entryType = EntryFile
+	}
+
+	var size int64
+	if entryType == EntryFile {
+		size = info.Size()
+	}
+
+	return FileEntry{
+		Name:      info.Name(),
+		Path:      filepath.ToSlash(rel),
+		Type:      entryType,
+		SizeBytes: size,
+		ModTime:   info.ModTime(),
+		Mode:      info.Mode().String(),
+	}
+}
+
+// Stat returns metadata for rel under root. Symlinks are reported as such
+// rather than followed.
+func Stat(root, rel string) (*FileEntry, error) {
+	full, err := resolveExported(root, rel)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Lstat(full)
+	if err != nil {
+		return nil, err
+	}
+	entry := entryFromInfo(root, full, info)
+	if full == filepath.Clean(root) {
+		entry.Path = "."
+	}
+	return &entry, nil
+}
+
+// ListDir lists the directory rel under root, sorted by name. Directories
+// are listed before files so an agent sees the tree structure first.
+func ListDir(root, rel string, opts ListOptions) (*ListPage, error) {
+	if opts.Glob != "" {
+		if _, err := path.Match(opts.Glob, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob %q: %w", opts.Glob, err)
+		}
+	}
+
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	if pageSize > MaxPageSize {
+		pageSize = MaxPageSize
+	}
+
+	start := 0
+	if opts.PageToken != "" {
+		n, err := strconv.Atoi(opts.PageToken)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid page token")
+		}
+		start = n
+	}
+
+	full, err := resolveExported(root, rel)
+	if err != nil {
+		return nil, err
+	}
+	dirEntries, err := os.ReadDir(full)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []FileEntry
+	for _, de := range dirEntries {
+		name := de.Name()
+		if strings.HasPrefix(name, ".") && (!opts.IncludeHidden || strings.HasSuffix(name, ".part")) {
+			continue
+		}
+		if opts.Glob != "" {
+			if ok, _ := path.Match(opts.Glob, name); !ok {
+				continue
+			}
+		}
+		info, err := de.Info()
+		if err != nil {
+			// Removed between ReadDir and Info
+			continue
+		}
+		matches = append(matches, entryFromInfo(root, filepath.Join(full, name), info))
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		di, dj := matches[i].Type == EntryDir, matches[j].Type == EntryDir
+		if di != dj {
+			return di
+		}
+		return matches[i].Name < matches[j].Name
+	})
+
+	page := &ListPage{TotalMatches: len(matches)}
+	if relDir, err := filepath.Rel(root, full); err == nil {
+		page.Path = filepath.ToSlash(relDir)
+	}
+	if start >= len(matches) {
+		return page, nil
+	}
+	end := start + pageSize
+	if end > len(matches) {
+		end = len(matches)
+	}
+	page.Entries = matches[start:end]
+	if end < len(matches) {
+		page.NextPageToken = strconv.Itoa(end)
+	}
+	return page, nil
+}
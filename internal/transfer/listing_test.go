@@ -0,0 +1,134 @@
+package transfer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func makeTree(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, f := range []string{"b.txt", "a.csv", "c.txt", ".hidden", "models/m.gguf"} {
+		p := filepath.Join(root, f)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(f), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, ".x.bin.abcd1234.part"), []byte("p"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestListDirOrderingAndHidden(t *testing.T) {
+	root := makeTree(t)
+
+	page, err := ListDir(root, "", ListOptions{})
+	if err != nil {
+		t.Fatalf("ListDir failed: %v", err)
+	}
+	var names []string
+	for _, e := range page.Entries {
+		names = append(names, e.Name)
+	}
+	want := []string{"models", "a.csv", "b.txt", "c.txt"}
+	if len(names) != len(want) {
+		t.Fatalf("got %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("got %v, want %v", names, want)
+		}
+	}
+	if page.Entries[0].Type != EntryDir || page.Entries[1].Type != EntryFile {
+		t.Fatalf("wrong entry types: %+v", page.Entries[:2])
+	}
+	if page.Entries[1].SizeBytes != int64(len("a.csv")) {
+		t.Fatalf("wrong size: %d", page.Entries[1].SizeBytes)
+	}
+
+	page, err = ListDir(root, ".", ListOptions{IncludeHidden: true})
+	if err != nil {
+		t.Fatalf("ListDir failed: %v", err)
+	}
+	if page.TotalMatches != 5 {
+		t.Fatalf("IncludeHidden should add .hidden but never .part files, got %d entries", page.TotalMatches)
+	}
+}
+
+func TestListDirGlobAndPagination(t *testing.T) {
+	root := makeTree(t)
+
+	page, err := ListDir(root, "", ListOptions{Glob: "*.txt", PageSize: 1})
+	if err != nil {
+		t.Fatalf("ListDir failed: %v", err)
+	}
+	if page.TotalMatches != 2 || len(page.Entries) != 1 || page.Entries[0].Name != "b.txt" {
+		t.Fatalf("unexpected first page: %+v", page)
+	}
+	if page.NextPageToken == "" {
+		t.Fatal("expected a next page token")
+	}
+
+	page, err = ListDir(root, "", ListOptions{Glob: "*.txt", PageSize: 1, PageToken: page.NextPageToken})
+	if err != nil {
+		t.Fatalf("ListDir page 2 failed: %v", err)
+	}
+	if len(page.Entries) != 1 || page.Entries[0].Name != "c.txt" || page.NextPageToken != "" {
+		t.Fatalf("unexpected second page: %+v", page)
+	}
+
+	if _, err := ListDir(root, "", ListOptions{Glob: "[bad"}); err == nil {
+		t.Fatal("invalid glob should fail")
+	}
+}
+
+func TestListDirSubdirAndStat(t *testing.T) {
+	root := makeTree(t)
+
+	page, err := ListDir(root, "models", ListOptions{})
+	if err != nil {
+		t.Fatalf("ListDir failed: %v", err)
+	}
+	if page.Path != "models" || len(page.Entries) != 1 || page.Entries[0].Path != "models/m.gguf" {
+		t.Fatalf("unexpected listing: %+v", page)
+	}
+
+	entry, err := Stat(root, "models/m.gguf")
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if entry.Type != EntryFile || entry.SizeBytes != int64(len("models/m.gguf")) {
+		t.Fatalf("unexpected stat: %+v", entry)
+	}
+
+	if _, err := Stat(root, "missing.txt"); !os.IsNotExist(err) {
+		t.Fatalf("expected not-exist error, got %v", err)
+	}
+}
+
+func TestListDirRejectsEscapes(t *testing.T) {
+	root := makeTree(t)
+	outside := t.TempDir()
+
+	if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+
+	for _, rel := range []string{"../", "/etc", "escape"} {
+		if _, err := ListDir(root, rel, ListOptions{}); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("ListDir(%q) should fail with ErrInvalidPath, got %v", rel, err)
+		}
+	}
+
+	// Stat resolves the link target too, so it is rejected as well.
+	entry, err := Stat(root, "escape")
+	if err == nil {
+		t.Fatalf("Stat through an escaping symlink should fail, got %+v", entry)
+	}
+}
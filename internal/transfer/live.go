@@ -0,0 +1,59 @@
+package transfer
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// FollowPollInterval is how often FollowFile checks a live file for new data
+const FollowPollInterval = 250 * time.Millisecond
+
+// SetLive marks path as still being written (e.g. a stream recording) or,
+// with live false, as finished. Downloads of a live file follow it until
+// it is finished instead of stopping at its current size.
+func (m *Manager) SetLive(path string, live bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if live {
+		m.live[path] = true
+	} else {
+		delete(m.live, path)
+	}
+}
+
+// Live reports whether path is still being written
+func (m *Manager) Live(path string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.live[path]
+}
+
+// FollowFile copies r to w like tail -f: at EOF it waits for more data
+// while live reports the file is still being written, then copies what
+// was appended before it finished. flush, if not nil, runs after each
+// batch so the reader sees data as it arrives.
+func FollowFile(ctx context.Context, w io.Writer, r io.Reader, live func() bool, flush func()) (int64, error) {
+	var total int64
+	for {
+		// Sample live before copying so data written just before the
+		// writer finished is still picked up by this pass
+		stillLive := live()
+		n, err := io.Copy(w, r)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if n > 0 && flush != nil {
+			flush()
+		}
+		if !stillLive {
+			return total, nil
+		}
+		select {
+		case <-ctx.Done():
+			return total, ctx.Err()
+		case <-time.After(FollowPollInterval):
+		}
+	}
+}
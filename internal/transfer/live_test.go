@@ -0,0 +1,71 @@
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFollowLiveFile(t *testing.T) {
+	mgr := NewManager(time.Minute)
+	path := filepath.Join(t.TempDir(), "rec.ivf")
+	w, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	w.WriteString("head")
+	mgr.SetLive(path, true)
+	if !mgr.Live(path) {
+		t.Fatal("file not live after SetLive")
+	}
+
+	r, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	// The writer appends while the download runs, then finishes
+	go func() {
+		for _, chunk := range []string{"-one", "-two", "-tail"} {
+			time.Sleep(2 * FollowPollInterval / 3)
+			w.WriteString(chunk)
+		}
+		mgr.SetLive(path, false)
+	}()
+
+	var out bytes.Buffer
+	var flushes atomic.Int32
+	n, err := FollowFile(context.Background(), &out, r, func() bool { return mgr.Live(path) }, func() { flushes.Add(1) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "head-one-two-tail" || n != int64(out.Len()) {
+		t.Fatalf("followed %q (%d bytes)", out.String(), n)
+	}
+	if flushes.Load() < 2 {
+		t.Fatalf("flushed %d times, want one per batch", flushes.Load())
+	}
+}
+
+func TestFollowFinishedFile(t *testing.T) {
+	var out bytes.Buffer
+	n, err := FollowFile(context.Background(), &out, bytes.NewReader([]byte("done")), func() bool { return false }, nil)
+	if err != nil || n != 4 || out.String() != "done" {
+		t.Fatalf("followed %q, %d, %v", out.String(), n, err)
+	}
+}
+
+func TestFollowCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := FollowFile(ctx, &bytes.Buffer{}, bytes.NewReader(nil), func() bool { return true }, nil)
+	if err != context.Canceled {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+}
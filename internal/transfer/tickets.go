@@ -1,35 +1,53 @@
-// Package transfer manages download ticket lifecycle for the bulk HTTP plane.
+// Package transfer manages download and upload ticket lifecycle for the bulk HTTP plane.
 package transfer
 
 import (
+	"container/list"
 	"crypto/rand"
 	"encoding/base64"
 	"fmt"
+	"os"
 	"sync"
 	"time"
 )
 
-// Ticket represents a one-time-use download authorization.
+// MaxSessionLifetime caps how long a download ticket can be kept alive by
+// repeated Redeem calls.
+const MaxSessionLifetime = 12 * time.Hour
+
+// Ticket represents a download authorization. It is either consumed by a
+// single GET (Consume) or redeemed repeatedly for a transfer session (Redeem).
 type Ticket struct {
 	Token     string
 	FilePath  string // absolute path on disk (already validated)
 	Filename  string // basename for Content-Disposition
 	SizeBytes int64
 	ExpiresAt time.Time
+	CreatedAt time.Time
 }
 
 // Manager is a thread-safe ticket store.
 type Manager struct {
-	mu      sync.Mutex
-	tickets map[string]*Ticket
-	ttl     time.Duration
+	mu          sync.Mutex
+	tickets     map[string]*Ticket
+	uploads     map[string]*UploadTicket
+	checksums   map[string]*list.Element // of *checksumEntry
+	checksumLRU *list.List               // most recently used first
+	live        map[string]bool          // files still being written; see SetLive
+	ttl         time.Duration
+	uploadRoot  string
+	limits      UploadLimits
 }
 
 // NewManager creates a new ticket manager with the given default TTL.
 func NewManager(ttl time.Duration) *Manager {
 	return &Manager{
-		tickets: make(map[string]*Ticket),
-		ttl:     ttl,
+		tickets:     make(map[string]*Ticket),
+		uploads:     make(map[string]*UploadTicket),
+		checksums:   make(map[string]*list.Element),
+		checksumLRU: list.New(),
+		live:        make(map[string]bool),
+		ttl:         ttl,
 	}
 }
 
@@ -37,11 +55,10 @@ func NewManager(ttl time.Duration) *Manager {
 // filePath must be the absolute, validated path on disk.
 // filename is the basename used for Content-Disposition.
 func (m *Manager) Create(filePath, filename string, sizeBytes int64) (*Ticket, error) {
-	b := make([]byte, 32)
-	if _, err := rand.Read(b); err != nil {
-		return nil, fmt.Errorf("failed to generate token: %w", err)
+	token, err := newToken()
+	if err != nil {
+		return nil, err
 	}
-	token := base64.RawURLEncoding.EncodeToString(b)
 
 	ticket := &Ticket{
 		Token:     token,
@@ -49,6 +66,7 @@ func (m *Manager) Create(filePath, filename string, sizeBytes int64) (*Ticket, e
 		Filename:  filename,
 		SizeBytes: sizeBytes,
 		ExpiresAt: time.Now().Add(m.ttl),
+		CreatedAt: time.Now(),
 	}
 
 	m.mu.Lock()
@@ -81,7 +99,41 @@ func (m *Manager) Consume(token string) *Ticket {
 	return ticket
 }
 
-// purgeExpiredLocked removes all expired tickets. Caller must hold m.mu.
+// Redeem returns a copy of a ticket without deleting it, so interrupted or
+// ranged downloads can reuse the same token. Each use slides the expiry
+// forward by the TTL, up to MaxSessionLifetime after creation.
+// Returns nil if the token is invalid or expired.
+func (m *Manager) Redeem(token string) *Ticket {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.purgeExpiredLocked()
+
+	ticket, ok := m.tickets[token]
+	if !ok {
+		return nil
+	}
+
+	now := time.Now()
+	if now.After(ticket.ExpiresAt) {
+		delete(m.tickets, token)
+		return nil
+	}
+
+	next := now.Add(m.ttl)
+	if limit := ticket.CreatedAt.Add(MaxSessionLifetime); next.After(limit) {
+		next = limit
+	}
+	if next.After(ticket.ExpiresAt) {
+		ticket.ExpiresAt = next
+	}
+
+	copied := *ticket
+	return &copied
+}
+
+// purgeExpiredLocked removes all expired tickets and the partial files of
+// abandoned uploads. Caller must hold m.mu.
 func (m *Manager) purgeExpiredLocked() {
 	now := time.Now()
 	for token, ticket := range m.tickets {
@@ -89,4 +141,23 @@ func (m *Manager) purgeExpiredLocked() {
 			delete(m.tickets, token)
 		}
 	}
+	for token, upload := range m.uploads {
+		// Skip uploads with a chunk in flight; WriteChunk refreshes their expiry.
+		if now.After(upload.ExpiresAt) && upload.mu.TryLock() {
+			delete(m.uploads, token)
+			if !upload.done {
+				os.Remove(upload.TempPath)
+			}
+			upload.mu.Unlock()
+		}
+	}
+}
+
+// newToken returns a random 256-bit URL-safe token.
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
 }
@@ -1,6 +1,9 @@
 package transfer
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -62,3 +65,101 @@ func TestInvalidToken(t *testing.T) {
 		t.Fatal("Invalid token should return nil")
 	}
 }
+
+func TestRedeemIsReusable(t *testing.T) {
+	mgr := NewManager(60 * time.Second)
+
+	ticket, err := mgr.Create("/tmp/test.txt", "test.txt", 100)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		redeemed := mgr.Redeem(ticket.Token)
+		if redeemed == nil {
+			t.Fatalf("Redeem %d returned nil", i)
+		}
+		if redeemed.FilePath != "/tmp/test.txt" {
+			t.Fatalf("Wrong file path: %s", redeemed.FilePath)
+		}
+	}
+
+	// Consume still ends the session
+	if mgr.Consume(ticket.Token) == nil {
+		t.Fatal("Consume after Redeem should succeed")
+	}
+	if mgr.Redeem(ticket.Token) != nil {
+		t.Fatal("Redeem after Consume should return nil")
+	}
+}
+
+func TestRedeemSlidesExpiry(t *testing.T) {
+	mgr := NewManager(30 * time.Millisecond)
+
+	ticket, err := mgr.Create("/tmp/test.txt", "test.txt", 100)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	// Keep the ticket alive past its original expiry by using it.
+	for i := 0; i < 4; i++ {
+		time.Sleep(15 * time.Millisecond)
+		if mgr.Redeem(ticket.Token) == nil {
+			t.Fatalf("Redeem %d should keep the session alive", i)
+		}
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if mgr.Redeem(ticket.Token) != nil {
+		t.Fatal("Idle ticket should expire")
+	}
+}
+
+func TestChecksumCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(path, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mgr := NewManager(60 * time.Second)
+	sum, err := mgr.Checksum(path)
+	if err != nil {
+		t.Fatalf("Checksum failed: %v", err)
+	}
+	const helloSHA = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	if sum != helloSHA {
+		t.Fatalf("Wrong checksum: %s", sum)
+	}
+
+	// A changed file must not return the cached digest.
+	if err := os.WriteFile(path, []byte("hello, world"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sum, err = mgr.Checksum(path)
+	if err != nil {
+		t.Fatalf("Checksum failed: %v", err)
+	}
+	if sum == helloSHA {
+		t.Fatal("Checksum returned stale digest after file changed")
+	}
+}
+
+func TestChecksumCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	mgr := NewManager(60 * time.Second)
+	for i := 0; i < maxChecksumEntries; i++ {
+		mgr.cacheChecksumLocked(&checksumEntry{path: fmt.Sprintf("/f%d", i)})
+	}
+	// Touch the oldest so the second oldest is evicted instead
+	mgr.checksumLRU.MoveToFront(mgr.checksums["/f0"])
+	mgr.cacheChecksumLocked(&checksumEntry{path: "/new"})
+
+	if len(mgr.checksums) != maxChecksumEntries || mgr.checksumLRU.Len() != maxChecksumEntries {
+		t.Fatalf("cache holds %d entries, want %d", len(mgr.checksums), maxChecksumEntries)
+	}
+	if _, ok := mgr.checksums["/f1"]; ok {
+		t.Fatal("least recently used digest was kept")
+	}
+	if _, ok := mgr.checksums["/f0"]; !ok {
+		t.Fatal("recently used digest was evicted")
+	}
+}
@@ -0,0 +1,497 @@
+package transfer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultChunkSize is the chunk size advertised to upload clients.
+const DefaultChunkSize = 8 * 1024 * 1024
+
+// Upload errors returned by the Manager. Callers map these to transport codes.
+var (
+	ErrInvalidPath      = errors.New("invalid upload path")
+	ErrFileTooLarge     = errors.New("file exceeds maximum upload size")
+	ErrQuotaExceeded    = errors.New("shared root quota exceeded")
+	ErrFileExists       = errors.New("destination file already exists")
+	ErrOffsetMismatch   = errors.New("chunk offset does not match received bytes")
+	ErrChunkOverflow    = errors.New("chunk extends past declared size")
+	ErrChecksumMismatch = errors.New("sha256 mismatch")
+)
+
+// UploadLimits bounds what upload tickets may write.
+// Zero values mean unlimited.
+type UploadLimits struct {
+	MaxFileBytes int64 // per-file size cap
+	QuotaBytes   int64 // total bytes allowed under the shared root
+}
+
+// UploadTicket authorizes a resumable, chunked upload to a single destination.
+type UploadTicket struct {
+	Token     string
+	DestPath  string // absolute destination path under the shared root
+	TempPath  string // partial file next to DestPath, renamed on completion
+	Filename  string
+	SizeBytes int64  // declared total size
+	SHA256    string // expected lowercase hex digest (empty = not verified)
+	Overwrite bool
+	ExpiresAt time.Time
+
+	mu       sync.Mutex // serializes chunk writes
+	received int64
+	done     bool
+}
+
+// UploadStatus is a snapshot of an upload's progress.
+type UploadStatus struct {
+	Token         string
+	DestPath      string
+	SizeBytes     int64
+	ReceivedBytes int64
+	Complete      bool
+	SHA256        string // set once complete
+}
+
+// ResolveUnderRoot joins a relative path onto root and rejects anything
+// that is absolute or would escape root, including through a symlink.
+func ResolveUnderRoot(root, rel string) (string, error) {
+	if err := CheckRelPath(rel); err != nil {
+		return "", err
+	}
+	full := filepath.Join(root, filepath.Clean(rel))
+	if full == filepath.Clean(root) {
+		return "", fmt.Errorf("%w: path must name a file", ErrInvalidPath)
+	}
+	if err := checkInsideRoot(root, full); err != nil {
+		return "", err
+	}
+	return full, nil
+}
+
+// CheckRelPath rejects a path that is empty, absolute or contains "..".
+// It only looks at the path itself; ResolveUnderRoot also checks symlinks.
+func CheckRelPath(rel string) error {
+	if rel == "" {
+		return fmt.Errorf("%w: path is required", ErrInvalidPath)
+	}
+	if filepath.IsAbs(rel) || strings.HasPrefix(rel, "/") || strings.HasPrefix(rel, `\`) {
+		return fmt.Errorf("%w: path must be relative to the shared root", ErrInvalidPath)
+	}
+	for _, part := range strings.FieldsFunc(rel, func(r rune) bool { return r == '/' || r == '\\' }) {
+		if part == ".." {
+			return fmt.Errorf("%w: path must not contain '..'", ErrInvalidPath)
+		}
+	}
+	return nil
+}
+
+// checkInsideRoot follows symlinks in the deepest existing part of full and
+// rejects it if that lands outside root. Parts that do not exist yet cannot
+// be links. A missing root has nothing to follow.
+func checkInsideRoot(root, full string) error {
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for p := full; ; p = filepath.Dir(p) {
+		real, err := filepath.EvalSymlinks(p)
+		if err == nil {
+			if real != realRoot && !strings.HasPrefix(real, realRoot+string(filepath.Separator)) {
+				return fmt.Errorf("%w: path resolves outside the shared root", ErrInvalidPath)
+			}
+			return nil
+		}
+		if !os.IsNotExist(err) {
+			return err
+		}
+		if _, err := os.Lstat(p); err == nil {
+			// p exists but leads nowhere: a dangling link, which a write
+			// would follow to wherever it points
+			return fmt.Errorf("%w: path is a dangling symlink", ErrInvalidPath)
+		}
+	}
+}
+
+// DirSize returns the total size of regular files under root.
+// A missing root counts as empty.
+func DirSize(root string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			total += info.Size()
+		}
+		return nil
+	})
+	return total, err
+}
+
+// SetUploadLimits configures per-file and shared-root quotas for uploads.
+// root is the directory whose usage counts against QuotaBytes.
+func (m *Manager) SetUploadLimits(root string, limits UploadLimits) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.uploadRoot = root
+	m.limits = limits
+}
+
+// Limits returns the configured upload limits.
+func (m *Manager) Limits() UploadLimits {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.limits
+}
+
+// CheckQuota reports whether a new file of sizeBytes fits within the limits,
+// accounting for bytes reserved by in-flight uploads. replacing is the size of
+// an existing file that the write will overwrite.
+func (m *Manager) CheckQuota(sizeBytes, replacing int64) error {
+	used, err := m.usedBytes()
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.checkQuotaLocked(sizeBytes, replacing, used)
+}
+
+// usedBytes measures the upload root for the quota. It walks the tree, so
+// it runs without m.mu; reservations are added under the lock afterwards.
+func (m *Manager) usedBytes() (int64, error) {
+	m.mu.Lock()
+	root, quota := m.uploadRoot, m.limits.QuotaBytes
+	m.mu.Unlock()
+	if quota <= 0 || root == "" {
+		return 0, nil
+	}
+	used, err := DirSize(root)
+	if err != nil {
+		return 0, fmt.Errorf("measure shared root: %w", err)
+	}
+	return used, nil
+}
+
+// checkQuotaLocked checks sizeBytes against the limits given used, the
+// size of the upload root measured by usedBytes
+func (m *Manager) checkQuotaLocked(sizeBytes, replacing, used int64) error {
+	if sizeBytes < 0 {
+		return fmt.Errorf("%w: negative size", ErrInvalidPath)
+	}
+	if m.limits.MaxFileBytes > 0 && sizeBytes > m.limits.MaxFileBytes {
+		return fmt.Errorf("%w: %d > %d bytes", ErrFileTooLarge, sizeBytes, m.limits.MaxFileBytes)
+	}
+	if m.limits.QuotaBytes <= 0 || m.uploadRoot == "" {
+		return nil
+	}
+	// Partial files already live under the root; reserve only what is still to come.
+	var pending int64
+	for _, u := range m.uploads {
+		if !u.done {
+			pending += u.SizeBytes - u.receivedSnapshot()
+		}
+	}
+	if used+pending+sizeBytes-replacing > m.limits.QuotaBytes {
+		return fmt.Errorf("%w: %d used, %d pending, %d requested, quota %d",
+			ErrQuotaExceeded, used, pending, sizeBytes, m.limits.QuotaBytes)
+	}
+	return nil
+}
+
+// CreateUpload mints an upload ticket for destPath, which must already be
+// resolved under the shared root. sha256Hex may be empty to skip verification.
+func (m *Manager) CreateUpload(destPath string, sizeBytes int64, sha256Hex string, overwrite bool) (*UploadTicket, error) {
+	sha256Hex = strings.ToLower(strings.TrimSpace(sha256Hex))
+	if sha256Hex != "" {
+		if b, err := hex.DecodeString(sha256Hex); err != nil || len(b) != sha256.Size {
+			return nil, fmt.Errorf("sha256 must be %d hex characters", sha256.Size*2)
+		}
+	}
+
+	var replacing int64
+	if info, err := os.Stat(destPath); err == nil {
+		if !overwrite {
+			return nil, fmt.Errorf("%w: %s", ErrFileExists, destPath)
+		}
+		if !info.Mode().IsRegular() {
+			return nil, fmt.Errorf("%w: destination is not a regular file", ErrInvalidPath)
+		}
+		replacing = info.Size()
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	ticket := &UploadTicket{
+		Token:     token,
+		DestPath:  destPath,
+		TempPath:  filepath.Join(filepath.Dir(destPath), fmt.Sprintf(".%s.%s.part", filepath.Base(destPath), token[:8])),
+		Filename:  filepath.Base(destPath),
+		SizeBytes: sizeBytes,
+		SHA256:    sha256Hex,
+		Overwrite: overwrite,
+		ExpiresAt: time.Now().Add(m.ttl),
+	}
+
+	used, err := m.usedBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.purgeExpiredLocked()
+	if err := m.checkQuotaLocked(sizeBytes, replacing, used); err != nil {
+		return nil, err
+	}
+
+	// Only after the quota check, so rejected uploads leave nothing behind
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return nil, fmt.Errorf("create destination dir: %w", err)
+	}
+	f, err := os.OpenFile(ticket.TempPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("create partial file: %w", err)
+	}
+	f.Close()
+
+	m.uploads[token] = ticket
+	return ticket, nil
+}
+
+// UploadStatus returns the progress of an upload, or nil if the token is
+// unknown or expired. Completed uploads remain queryable until expiry so a
+// client that lost the final response can confirm success.
+func (m *Manager) UploadStatus(token string) *UploadStatus {
+	m.mu.Lock()
+	m.purgeExpiredLocked()
+	ticket, ok := m.uploads[token]
+	m.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	ticket.mu.Lock()
+	defer ticket.mu.Unlock()
+	return ticket.statusLocked()
+}
+
+// WriteChunk appends r to the upload at offset. offset must equal the number
+// of bytes already received so that retried chunks are detected. When the
+// declared size is reached the file is verified and renamed into place.
+func (m *Manager) WriteChunk(token string, offset int64, r io.Reader) (*UploadStatus, error) {
+	m.mu.Lock()
+	m.purgeExpiredLocked()
+	ticket, ok := m.uploads[token]
+	if ok {
+		// Upload tickets expire after a period of inactivity, not a fixed
+		// lifetime, so large files can take as long as they need.
+		ticket.ExpiresAt = time.Now().Add(m.ttl)
+	}
+	m.mu.Unlock()
+	if !ok {
+		return nil, nil
+	}
+
+	ticket.mu.Lock()
+	defer func() {
+		ticket.mu.Unlock()
+		m.mu.Lock()
+		ticket.ExpiresAt = time.Now().Add(m.ttl)
+		m.mu.Unlock()
+	}()
+
+	if ticket.done {
+		return ticket.statusLocked(), nil
+	}
+	if offset != ticket.received {
+		return ticket.statusLocked(), fmt.Errorf("%w: got %d, have %d", ErrOffsetMismatch, offset, ticket.received)
+	}
+
+	f, err := os.OpenFile(ticket.TempPath, os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("open partial file: %w", err)
+	}
+
+	// Truncate to the acknowledged length so bytes from an interrupted chunk
+	// never survive into the final file.
+	if err := f.Truncate(ticket.received); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("truncate partial file: %w", err)
+	}
+	if _, err := f.Seek(ticket.received, io.SeekStart); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("seek partial file: %w", err)
+	}
+
+	remaining := ticket.SizeBytes - ticket.received
+	n, copyErr := io.Copy(f, io.LimitReader(r, remaining))
+	if copyErr == nil && n == remaining {
+		// Probe for bytes beyond the declared size.
+		var probe [1]byte
+		if extra, _ := r.Read(probe[:]); extra > 0 {
+			f.Truncate(ticket.received)
+			f.Close()
+			return ticket.statusLocked(), ErrChunkOverflow
+		}
+	}
+	if syncErr := f.Sync(); syncErr != nil && copyErr == nil {
+		copyErr = syncErr
+	}
+	if closeErr := f.Close(); closeErr != nil && copyErr == nil {
+		copyErr = closeErr
+	}
+	if copyErr != nil {
+		// Keep nothing from a failed chunk; the client resumes from received.
+		return ticket.statusLocked(), fmt.Errorf("write chunk: %w", copyErr)
+	}
+
+	ticket.received += n
+	if ticket.received < ticket.SizeBytes {
+		return ticket.statusLocked(), nil
+	}
+
+	if err := m.finalizeLocked(ticket); err != nil {
+		return ticket.statusLocked(), err
+	}
+	return ticket.statusLocked(), nil
+}
+
+// finalizeLocked verifies the partial file and renames it into place.
+// Caller must hold ticket.mu.
+func (m *Manager) finalizeLocked(ticket *UploadTicket) error {
+	sum, err := FileSHA256(ticket.TempPath)
+	if err != nil {
+		return fmt.Errorf("hash partial file: %w", err)
+	}
+	if ticket.SHA256 != "" && sum != ticket.SHA256 {
+		// Corrupt upload: discard and make the client start over.
+		os.Truncate(ticket.TempPath, 0)
+		ticket.received = 0
+		return fmt.Errorf("%w: expected %s, got %s", ErrChecksumMismatch, ticket.SHA256, sum)
+	}
+	if !ticket.Overwrite {
+		if _, err := os.Stat(ticket.DestPath); err == nil {
+			return fmt.Errorf("%w: %s", ErrFileExists, ticket.DestPath)
+		}
+	}
+	if err := os.Rename(ticket.TempPath, ticket.DestPath); err != nil {
+		return fmt.Errorf("rename into place: %w", err)
+	}
+	ticket.SHA256 = sum
+	ticket.done = true
+	return nil
+}
+
+// CancelUpload drops an upload ticket and removes its partial file.
+func (m *Manager) CancelUpload(token string) bool {
+	m.mu.Lock()
+	ticket, ok := m.uploads[token]
+	delete(m.uploads, token)
+	m.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	ticket.mu.Lock()
+	defer ticket.mu.Unlock()
+	if !ticket.done {
+		os.Remove(ticket.TempPath)
+	}
+	return true
+}
+
+// WriteFileAtomic writes data to destPath via a temp file and rename.
+// If sha256Hex is non-empty the data must match it. Returns the hex digest.
+func WriteFileAtomic(destPath string, data []byte, sha256Hex string, overwrite bool) (string, error) {
+	sum := sha256.Sum256(data)
+	digest := hex.EncodeToString(sum[:])
+	if sha256Hex != "" && !strings.EqualFold(strings.TrimSpace(sha256Hex), digest) {
+		return "", fmt.Errorf("%w: expected %s, got %s", ErrChecksumMismatch, sha256Hex, digest)
+	}
+	if !overwrite {
+		if _, err := os.Stat(destPath); err == nil {
+			return "", fmt.Errorf("%w: %s", ErrFileExists, destPath)
+		}
+	}
+
+	dir := filepath.Dir(destPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("create destination dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(destPath)+".*.part")
+	if err != nil {
+		return "", fmt.Errorf("create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("write temp file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("sync temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("close temp file: %w", err)
+	}
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("chmod temp file: %w", err)
+	}
+	if err := os.Rename(tmpPath, destPath); err != nil {
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("rename into place: %w", err)
+	}
+	return digest, nil
+}
+
+func (t *UploadTicket) statusLocked() *UploadStatus {
+	st := &UploadStatus{
+		Token:         t.Token,
+		DestPath:      t.DestPath,
+		SizeBytes:     t.SizeBytes,
+		ReceivedBytes: t.received,
+		Complete:      t.done,
+	}
+	if t.done {
+		st.SHA256 = t.SHA256
+	}
+	return st
+}
+
+// receivedSnapshot reads received without blocking on an in-progress chunk;
+// a slightly stale value only makes the quota estimate more conservative.
+func (t *UploadTicket) receivedSnapshot() int64 {
+	if !t.mu.TryLock() {
+		return 0
+	}
+	defer t.mu.Unlock()
+	return t.received
+}
@@ -0,0 +1,234 @@
+package transfer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestResolveUnderRoot(t *testing.T) {
+	root := t.TempDir()
+
+	good, err := ResolveUnderRoot(root, "models/a.bin")
+	if err != nil {
+		t.Fatalf("ResolveUnderRoot failed: %v", err)
+	}
+	if good != filepath.Join(root, "models", "a.bin") {
+		t.Fatalf("Wrong path: %s", good)
+	}
+
+	for _, bad := range []string{"", "/etc/passwd", "../x", "a/../../x", ".", "a/.."} {
+		if _, err := ResolveUnderRoot(root, bad); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("ResolveUnderRoot(%q) should fail with ErrInvalidPath, got %v", bad, err)
+		}
+	}
+}
+
+func TestResolveUnderRootFollowsSymlinks(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+	os.Symlink(filepath.Join(outside, "gone"), filepath.Join(root, "dangling"))
+	os.Mkdir(filepath.Join(root, "inside"), 0755)
+	os.Symlink(filepath.Join(root, "inside"), filepath.Join(root, "alias"))
+
+	for _, bad := range []string{"escape/x.bin", "escape/new/dir/x.bin", "escape", "dangling"} {
+		if _, err := ResolveUnderRoot(root, bad); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("ResolveUnderRoot(%q) should fail with ErrInvalidPath, got %v", bad, err)
+		}
+	}
+	// Links that stay under the root are fine
+	if _, err := ResolveUnderRoot(root, "alias/x.bin"); err != nil {
+		t.Errorf("link inside the root: %v", err)
+	}
+}
+
+func TestChunkedUploadResume(t *testing.T) {
+	root := t.TempDir()
+	mgr := NewManager(60 * time.Second)
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+	dest := filepath.Join(root, "sub", "data.bin")
+
+	ticket, err := mgr.CreateUpload(dest, int64(len(data)), sha256Hex(data), false)
+	if err != nil {
+		t.Fatalf("CreateUpload failed: %v", err)
+	}
+
+	st, err := mgr.WriteChunk(ticket.Token, 0, bytes.NewReader(data[:4000]))
+	if err != nil {
+		t.Fatalf("first chunk failed: %v", err)
+	}
+	if st.ReceivedBytes != 4000 || st.Complete {
+		t.Fatalf("unexpected status after first chunk: %+v", st)
+	}
+
+	// A retried chunk at a stale offset is rejected and reports the real offset.
+	st, err = mgr.WriteChunk(ticket.Token, 0, bytes.NewReader(data[:4000]))
+	if !errors.Is(err, ErrOffsetMismatch) {
+		t.Fatalf("expected ErrOffsetMismatch, got %v", err)
+	}
+	if st.ReceivedBytes != 4000 {
+		t.Fatalf("status should report 4000 received, got %d", st.ReceivedBytes)
+	}
+
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Fatal("destination must not exist before the upload completes")
+	}
+
+	st, err = mgr.WriteChunk(ticket.Token, 4000, bytes.NewReader(data[4000:]))
+	if err != nil {
+		t.Fatalf("final chunk failed: %v", err)
+	}
+	if !st.Complete || st.SHA256 != sha256Hex(data) {
+		t.Fatalf("upload should be complete with digest, got %+v", st)
+	}
+
+	got, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatalf("read destination: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatal("destination content mismatch")
+	}
+	if _, err := os.Stat(ticket.TempPath); !os.IsNotExist(err) {
+		t.Fatal("partial file should be gone after rename")
+	}
+
+	// Status stays queryable after completion.
+	if st := mgr.UploadStatus(ticket.Token); st == nil || !st.Complete {
+		t.Fatalf("UploadStatus after completion: %+v", st)
+	}
+}
+
+func TestUploadChecksumMismatch(t *testing.T) {
+	root := t.TempDir()
+	mgr := NewManager(60 * time.Second)
+	dest := filepath.Join(root, "bad.bin")
+
+	ticket, err := mgr.CreateUpload(dest, 5, sha256Hex([]byte("hello")), false)
+	if err != nil {
+		t.Fatalf("CreateUpload failed: %v", err)
+	}
+
+	st, err := mgr.WriteChunk(ticket.Token, 0, bytes.NewReader([]byte("jello")))
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("expected ErrChecksumMismatch, got %v", err)
+	}
+	if st.ReceivedBytes != 0 {
+		t.Fatalf("corrupt upload should restart from 0, got %d", st.ReceivedBytes)
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Fatal("corrupt upload must not reach the destination")
+	}
+}
+
+func TestUploadOverflow(t *testing.T) {
+	root := t.TempDir()
+	mgr := NewManager(60 * time.Second)
+
+	ticket, err := mgr.CreateUpload(filepath.Join(root, "x.bin"), 3, "", false)
+	if err != nil {
+		t.Fatalf("CreateUpload failed: %v", err)
+	}
+	if _, err := mgr.WriteChunk(ticket.Token, 0, bytes.NewReader([]byte("abcd"))); !errors.Is(err, ErrChunkOverflow) {
+		t.Fatalf("expected ErrChunkOverflow, got %v", err)
+	}
+}
+
+func TestUploadQuota(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "existing.bin"), make([]byte, 600), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mgr := NewManager(60 * time.Second)
+	mgr.SetUploadLimits(root, UploadLimits{MaxFileBytes: 500, QuotaBytes: 1000})
+
+	if _, err := mgr.CreateUpload(filepath.Join(root, "big.bin"), 501, "", false); !errors.Is(err, ErrFileTooLarge) {
+		t.Fatalf("expected ErrFileTooLarge, got %v", err)
+	}
+
+	if _, err := mgr.CreateUpload(filepath.Join(root, "a.bin"), 300, "", false); err != nil {
+		t.Fatalf("first upload within quota failed: %v", err)
+	}
+	// 600 on disk + 300 reserved + 200 requested > 1000
+	if _, err := mgr.CreateUpload(filepath.Join(root, "new", "b.bin"), 200, "", false); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("expected ErrQuotaExceeded, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "new")); !os.IsNotExist(err) {
+		t.Fatalf("rejected upload left its directory behind: %v", err)
+	}
+	// Overwriting the existing file frees its bytes.
+	if _, err := mgr.CreateUpload(filepath.Join(root, "existing.bin"), 200, "", true); err != nil {
+		t.Fatalf("overwrite within quota failed: %v", err)
+	}
+}
+
+func TestUploadExistingFile(t *testing.T) {
+	root := t.TempDir()
+	dest := filepath.Join(root, "keep.txt")
+	if err := os.WriteFile(dest, []byte("keep"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mgr := NewManager(60 * time.Second)
+	if _, err := mgr.CreateUpload(dest, 4, "", false); !errors.Is(err, ErrFileExists) {
+		t.Fatalf("expected ErrFileExists, got %v", err)
+	}
+}
+
+func TestExpiredUploadRemovesPartial(t *testing.T) {
+	root := t.TempDir()
+	mgr := NewManager(1 * time.Millisecond)
+
+	ticket, err := mgr.CreateUpload(filepath.Join(root, "slow.bin"), 10, "", false)
+	if err != nil {
+		t.Fatalf("CreateUpload failed: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if st := mgr.UploadStatus(ticket.Token); st != nil {
+		t.Fatal("expired upload should not be found")
+	}
+	if _, err := os.Stat(ticket.TempPath); !os.IsNotExist(err) {
+		t.Fatal("partial file of expired upload should be removed")
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	root := t.TempDir()
+	dest := filepath.Join(root, "dir", "small.txt")
+	data := []byte("small file")
+
+	sum, err := WriteFileAtomic(dest, data, sha256Hex(data), false)
+	if err != nil {
+		t.Fatalf("WriteFileAtomic failed: %v", err)
+	}
+	if sum != sha256Hex(data) {
+		t.Fatalf("wrong digest: %s", sum)
+	}
+
+	if _, err := WriteFileAtomic(dest, data, "", false); !errors.Is(err, ErrFileExists) {
+		t.Fatalf("expected ErrFileExists, got %v", err)
+	}
+	if _, err := WriteFileAtomic(dest, []byte("other"), sha256Hex(data), true); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("expected ErrChecksumMismatch, got %v", err)
+	}
+
+	got, _ := os.ReadFile(dest)
+	if !bytes.Equal(got, data) {
+		t.Fatal("failed write must leave the original intact")
+	}
+}
@@ -0,0 +1,120 @@
+package webrtcstream
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pion/rtcp"
+)
+
+// Loss thresholds for bitrate adaptation, as fractions of packets lost
+// between receiver reports. Between the two the bitrate holds.
+const (
+	lossDecreaseThreshold = 0.10
+	lossIncreaseThreshold = 0.02
+	bitrateIncreaseFactor = 1.05
+	// decreaseHoldoff keeps one burst of loss, reported in several
+	// receiver reports, from cutting the bitrate more than once.
+	decreaseHoldoff = time.Second
+)
+
+// BitrateController adapts a stream's target bitrate to RTCP feedback:
+// receiver reports drive a loss-based estimate, REMB messages cap it and
+// PLI/FIR requests ask the encoder for a keyframe.
+type BitrateController struct {
+	mu       sync.Mutex
+	minBps   int
+	maxBps   int
+	bitrate  int
+	remb     int // latest receiver estimate, 0 until one arrives
+	keyframe bool
+	lastCut  time.Time
+	lastLoss float64
+	now      func() time.Time
+}
+
+// NewBitrateController starts at startBps and stays within [minBps, maxBps]
+func NewBitrateController(startBps, minBps, maxBps int) *BitrateController {
+	c := &BitrateController{
+		minBps:  minBps,
+		maxBps:  maxBps,
+		bitrate: startBps,
+		now:     time.Now,
+	}
+	c.clamp()
+	return c
+}
+
+// HandleRTCP updates the estimate from a batch of RTCP packets
+func (c *BitrateController) HandleRTCP(pkts []rtcp.Packet) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, pkt := range pkts {
+		switch p := pkt.(type) {
+		case *rtcp.ReceiverReport:
+			for _, r := range p.Reports {
+				c.onLoss(float64(r.FractionLost) / 256)
+			}
+		case *rtcp.ReceiverEstimatedMaximumBitrate:
+			c.remb = int(p.Bitrate)
+			c.clamp()
+		case *rtcp.PictureLossIndication, *rtcp.FullIntraRequest:
+			c.keyframe = true
+		}
+	}
+}
+
+// onLoss applies one loss report: cut in proportion to heavy loss, probe
+// upwards when the path is clean.
+func (c *BitrateController) onLoss(loss float64) {
+	c.lastLoss = loss
+	switch {
+	case loss > lossDecreaseThreshold:
+		now := c.now()
+		if now.Sub(c.lastCut) < decreaseHoldoff {
+			return
+		}
+		c.lastCut = now
+		c.bitrate = int(float64(c.bitrate) * (1 - 0.5*loss))
+	case loss < lossIncreaseThreshold:
+		c.bitrate = int(float64(c.bitrate) * bitrateIncreaseFactor)
+	}
+	c.clamp()
+}
+
+func (c *BitrateController) clamp() {
+	ceiling := c.maxBps
+	if c.remb > 0 && c.remb < ceiling {
+		ceiling = c.remb
+	}
+	if c.bitrate > ceiling {
+		c.bitrate = ceiling
+	}
+	if c.bitrate < c.minBps {
+		c.bitrate = c.minBps
+	}
+}
+
+// Bitrate returns the current target in bits per second
+func (c *BitrateController) Bitrate() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.bitrate
+}
+
+// LastLoss returns the most recently reported loss fraction
+func (c *BitrateController) LastLoss() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lastLoss
+}
+
+// KeyframeRequested reports whether the receiver asked for a keyframe since
+// the last call, and clears the request.
+func (c *BitrateController) KeyframeRequested() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	k := c.keyframe
+	c.keyframe = false
+	return k
+}
@@ -0,0 +1,89 @@
+package webrtcstream
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/rtcp"
+)
+
+func lossReport(fraction float64) []rtcp.Packet {
+	return []rtcp.Packet{&rtcp.ReceiverReport{
+		Reports: []rtcp.ReceptionReport{{FractionLost: uint8(fraction * 256)}},
+	}}
+}
+
+func TestBitrateControllerLoss(t *testing.T) {
+	now := time.Unix(1000, 0)
+	c := NewBitrateController(1_000_000, 100_000, 2_000_000)
+	c.now = func() time.Time { return now }
+
+	c.HandleRTCP(lossReport(0))
+	if got := c.Bitrate(); got != 1_050_000 {
+		t.Fatalf("after clean report: %d, want 1050000", got)
+	}
+
+	c.HandleRTCP(lossReport(0.05))
+	if got := c.Bitrate(); got != 1_050_000 {
+		t.Fatalf("moderate loss should hold, got %d", got)
+	}
+
+	c.HandleRTCP(lossReport(0.25))
+	cut := c.Bitrate()
+	if cut >= 1_050_000 || cut < 900_000 {
+		t.Fatalf("after 25%% loss: %d, want ~918750", cut)
+	}
+
+	// A second report from the same burst does not cut again
+	c.HandleRTCP(lossReport(0.25))
+	if got := c.Bitrate(); got != cut {
+		t.Fatalf("within holdoff: %d, want %d", got, cut)
+	}
+	now = now.Add(2 * decreaseHoldoff)
+	c.HandleRTCP(lossReport(0.25))
+	if got := c.Bitrate(); got >= cut {
+		t.Fatalf("after holdoff: %d, want below %d", got, cut)
+	}
+}
+
+func TestBitrateControllerBounds(t *testing.T) {
+	c := NewBitrateController(1_900_000, 500_000, 2_000_000)
+	for i := 0; i < 20; i++ {
+		c.HandleRTCP(lossReport(0))
+	}
+	if got := c.Bitrate(); got != 2_000_000 {
+		t.Fatalf("ceiling: %d", got)
+	}
+
+	c.HandleRTCP([]rtcp.Packet{&rtcp.ReceiverEstimatedMaximumBitrate{Bitrate: 800_000}})
+	if got := c.Bitrate(); got != 800_000 {
+		t.Fatalf("REMB cap: %d, want 800000", got)
+	}
+	c.HandleRTCP(lossReport(0))
+	if got := c.Bitrate(); got != 800_000 {
+		t.Fatalf("increase past REMB: %d", got)
+	}
+
+	c.HandleRTCP([]rtcp.Packet{&rtcp.ReceiverEstimatedMaximumBitrate{Bitrate: 1000}})
+	if got := c.Bitrate(); got != 500_000 {
+		t.Fatalf("floor: %d, want 500000", got)
+	}
+}
+
+func TestBitrateControllerKeyframeRequests(t *testing.T) {
+	c := NewBitrateController(1_000_000, 100_000, 2_000_000)
+	if c.KeyframeRequested() {
+		t.Fatal("no request yet")
+	}
+	c.HandleRTCP([]rtcp.Packet{&rtcp.PictureLossIndication{MediaSSRC: 1}})
+	if !c.KeyframeRequested() {
+		t.Fatal("PLI should request a keyframe")
+	}
+	if c.KeyframeRequested() {
+		t.Fatal("request should clear after it is read")
+	}
+	c.HandleRTCP([]rtcp.Packet{&rtcp.FullIntraRequest{MediaSSRC: 1}})
+	if !c.KeyframeRequested() {
+		t.Fatal("FIR should request a keyframe")
+	}
+}
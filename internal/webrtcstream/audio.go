@@ -0,0 +1,409 @@
+package webrtcstream
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/webrtc/v3"
+	"github.com/pion/webrtc/v3/pkg/media"
+	"github.com/pion/webrtc/v3/pkg/media/oggreader"
+)
+
+// Audio source names accepted by Manager.SetAudioSource and
+// Options.AudioSource
+const (
+	AudioSystem = "system" // what the device is playing, through ffmpeg
+	AudioMic    = "mic"    // the default microphone, through ffmpeg
+	AudioTone   = "tone"   // a 440Hz sine generated by ffmpeg
+	AudioFile   = "file"   // an Ogg/Opus file, looped
+)
+
+// Opus runs at 48kHz; the Ogg granule position counts samples at that rate
+const (
+	opusClockRate     = 48000
+	opusChannels      = 2
+	defaultOpusPacket = 20 * time.Millisecond
+)
+
+// AudioPacket is one encoded Opus packet and the audio it covers
+type AudioPacket struct {
+	Data     []byte
+	Duration time.Duration
+}
+
+// AudioSource produces Opus packets. ReadPacket may return faster than
+// real time; the stream paces packets by their durations.
+type AudioSource interface {
+	ReadPacket() (AudioPacket, error)
+	Close() error
+}
+
+// AudioConfig holds the device-side settings audio sources read
+type AudioConfig struct {
+	File   string // Ogg/Opus file for AudioFile
+	Device string // ffmpeg input device, overriding the platform default
+}
+
+// AudioSourceFactory opens an audio source for one stream
+type AudioSourceFactory func(cfg AudioConfig) (AudioSource, error)
+
+var (
+	audioSourcesMu sync.RWMutex
+	audioSources   = map[string]AudioSourceFactory{
+		AudioSystem: func(cfg AudioConfig) (AudioSource, error) { return newFFmpegSource(AudioSystem, cfg) },
+		AudioMic:    func(cfg AudioConfig) (AudioSource, error) { return newFFmpegSource(AudioMic, cfg) },
+		AudioTone:   func(cfg AudioConfig) (AudioSource, error) { return newFFmpegSource(AudioTone, cfg) },
+		AudioFile:   func(cfg AudioConfig) (AudioSource, error) { return NewOggFileSource(cfg.File) },
+	}
+)
+
+// RegisterAudioSource makes an audio source available by name. It replaces
+// any source already registered under that name.
+func RegisterAudioSource(name string, factory AudioSourceFactory) {
+	audioSourcesMu.Lock()
+	defer audioSourcesMu.Unlock()
+	audioSources[name] = factory
+}
+
+// AudioSources lists the registered audio source names
+func AudioSources() []string {
+	audioSourcesMu.RLock()
+	defer audioSourcesMu.RUnlock()
+	names := make([]string, 0, len(audioSources))
+	for name := range audioSources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewAudioSource opens a registered audio source
+func NewAudioSource(name string, cfg AudioConfig) (AudioSource, error) {
+	audioSourcesMu.RLock()
+	factory, ok := audioSources[name]
+	audioSourcesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown audio source %q (have %v)", name, AudioSources())
+	}
+	return factory(cfg)
+}
+
+// oggSource reads Opus packets from an Ogg stream, one packet per page
+// (ffmpeg writes them that way with -page_duration 20000)
+type oggSource struct {
+	rc      io.ReadCloser
+	ogg     *oggreader.OggReader
+	granule uint64
+}
+
+func newOggSource(rc io.ReadCloser) (*oggSource, error) {
+	ogg, _, err := oggreader.NewWith(rc)
+	if err != nil {
+		rc.Close()
+		return nil, fmt.Errorf("not an Ogg/Opus stream: %w", err)
+	}
+	return &oggSource{rc: rc, ogg: ogg}, nil
+}
+
+func (s *oggSource) ReadPacket() (AudioPacket, error) {
+	for {
+		data, header, err := s.ogg.ParseNextPage()
+		if err != nil {
+			return AudioPacket{}, err
+		}
+		if bytes.HasPrefix(data, []byte("OpusTags")) {
+			continue
+		}
+		d := defaultOpusPacket
+		if header.GranulePosition > s.granule && s.granule > 0 {
+			d = time.Duration(header.GranulePosition-s.granule) * time.Second / opusClockRate
+		}
+		s.granule = header.GranulePosition
+		return AudioPacket{Data: data, Duration: d}, nil
+	}
+}
+
+func (s *oggSource) Close() error {
+	return s.rc.Close()
+}
+
+// oggFileSource plays an Ogg/Opus file in a loop
+type oggFileSource struct {
+	path string
+	mu   sync.Mutex // guards the reopen at end of file against Close
+	*oggSource
+}
+
+// NewOggFileSource returns a source that loops the Opus packets of an Ogg
+// file, for headless machines and tests
+func NewOggFileSource(path string) (AudioSource, error) {
+	if path == "" {
+		return nil, fmt.Errorf("audio source %q needs a file (STREAM_AUDIO_FILE)", AudioFile)
+	}
+	s := &oggFileSource{path: path}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *oggFileSource) open() error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+	ogg, err := newOggSource(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", s.path, err)
+	}
+	s.oggSource = ogg
+	return nil
+}
+
+func (s *oggFileSource) ReadPacket() (AudioPacket, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pkt, err := s.oggSource.ReadPacket()
+	if err == io.EOF {
+		s.oggSource.Close()
+		if err := s.open(); err != nil {
+			return AudioPacket{}, err
+		}
+		pkt, err = s.oggSource.ReadPacket()
+	}
+	return pkt, err
+}
+
+func (s *oggFileSource) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.oggSource.Close()
+}
+
+// ffmpegSource captures and encodes audio with an ffmpeg child process
+type ffmpegSource struct {
+	cmd *exec.Cmd
+	*oggSource
+}
+
+func newFFmpegSource(name string, cfg AudioConfig) (AudioSource, error) {
+	input, err := ffmpegInput(name, cfg.Device)
+	if err != nil {
+		return nil, err
+	}
+	args := append([]string{"-hide_banner", "-loglevel", "error"}, input...)
+	args = append(args,
+		"-c:a", "libopus", "-b:a", "64k", "-ac", fmt.Sprint(opusChannels), "-ar", fmt.Sprint(opusClockRate),
+		"-page_duration", "20000", "-f", "ogg", "-")
+	cmd := exec.Command("ffmpeg", args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("audio source %q needs ffmpeg: %w", name, err)
+	}
+	ogg, err := newOggSource(stdout)
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return nil, fmt.Errorf("ffmpeg %s: %w", name, err)
+	}
+	return &ffmpegSource{cmd: cmd, oggSource: ogg}, nil
+}
+
+func (s *ffmpegSource) Close() error {
+	s.cmd.Process.Kill()
+	s.oggSource.Close()
+	s.cmd.Wait()
+	return nil
+}
+
+// ffmpegInput returns the ffmpeg input arguments for a source on this
+// platform. Capturing system audio needs a loopback device on macOS and
+// Windows, so there device must be set.
+func ffmpegInput(name, device string) ([]string, error) {
+	if name == AudioTone {
+		return []string{"-re", "-f", "lavfi", "-i", fmt.Sprintf("sine=frequency=440:sample_rate=%d", opusClockRate)}, nil
+	}
+	switch runtime.GOOS {
+	case "linux":
+		if device == "" {
+			device = "default"
+			if name == AudioSystem {
+				device = "@DEFAULT_MONITOR@"
+			}
+		}
+		return []string{"-f", "pulse", "-i", device}, nil
+	case "darwin":
+		if device == "" && name == AudioMic {
+			device = "0"
+		}
+		if device != "" {
+			return []string{"-f", "avfoundation", "-i", ":" + device}, nil
+		}
+	case "windows":
+		if device != "" {
+			return []string{"-f", "dshow", "-i", "audio=" + device}, nil
+		}
+	default:
+		return nil, fmt.Errorf("audio capture is not supported on %s", runtime.GOOS)
+	}
+	return nil, fmt.Errorf("audio source %q on %s needs an input device (STREAM_AUDIO_DEVICE)", name, runtime.GOOS)
+}
+
+// AudioStats is what the viewer's receiver reports say about the audio
+// track
+type AudioStats struct {
+	PacketsSent  int64
+	PacketsLost  uint32        // cumulative, as reported by the viewer
+	FractionLost float64       // since the previous report
+	Jitter       time.Duration // interarrival jitter
+}
+
+// audioStats collects AudioStats from the audio sender's RTCP
+type audioStats struct {
+	mu sync.Mutex
+	AudioStats
+}
+
+func (a *audioStats) handleRTCP(pkts []rtcp.Packet) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, pkt := range pkts {
+		rr, ok := pkt.(*rtcp.ReceiverReport)
+		if !ok {
+			continue
+		}
+		for _, r := range rr.Reports {
+			a.PacketsLost = r.TotalLost
+			a.FractionLost = float64(r.FractionLost) / 256
+			a.Jitter = time.Duration(r.Jitter) * time.Second / opusClockRate
+		}
+	}
+}
+
+func (a *audioStats) sent() {
+	a.mu.Lock()
+	a.PacketsSent++
+	a.mu.Unlock()
+}
+
+func (a *audioStats) snapshot() AudioStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.AudioStats
+}
+
+// addAudioTrack adds an Opus track to pc and starts reading receiver
+// reports from its sender
+func (s *Stream) addAudioTrack(pc *webrtc.PeerConnection) error {
+	track, err := webrtc.NewTrackLocalStaticSample(
+		webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeOpus, ClockRate: opusClockRate, Channels: opusChannels},
+		"audio", "edgecli-"+s.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create audio track: %w", err)
+	}
+	sender, err := pc.AddTrack(track)
+	if err != nil {
+		return fmt.Errorf("failed to add audio track: %w", err)
+	}
+	s.AudioTrack = track
+	s.audioStats = &audioStats{}
+
+	go func() {
+		for {
+			pkts, _, err := sender.ReadRTCP()
+			if err != nil {
+				return
+			}
+			s.audioStats.handleRTCP(pkts)
+		}
+	}()
+	return nil
+}
+
+// AudioStats returns the audio track's statistics; ok is false for a
+// stream without audio
+func (s *Stream) AudioStats() (stats AudioStats, ok bool) {
+	if s.audioStats == nil {
+		return AudioStats{}, false
+	}
+	return s.audioStats.snapshot(), true
+}
+
+// audioLoop opens the stream's audio source and writes its packets to the
+// audio track in real time until ctx is cancelled. A failing source ends
+// the audio only; the screen keeps streaming.
+func (s *Stream) audioLoop(ctx context.Context) {
+	src, err := NewAudioSource(s.audioSource, s.audioConfig)
+	if err != nil {
+		log.Printf("[WARN] WebRTC stream %s: audio: %v", s.ID, err)
+		return
+	}
+	// Closing the source unblocks a pending read when the stream stops
+	var closeOnce sync.Once
+	closeSource := func() { closeOnce.Do(func() { src.Close() }) }
+	defer closeSource()
+	go func() {
+		<-ctx.Done()
+		closeSource()
+	}()
+
+	log.Printf("[INFO] WebRTC stream %s: audio loop started (source=%s)", s.ID, s.audioSource)
+
+	start := time.Now()
+	var (
+		elapsed time.Duration // audio written so far
+		stats   = start
+	)
+	for {
+		pkt, err := src.ReadPacket()
+		if ctx.Err() != nil {
+			log.Printf("[INFO] WebRTC stream %s: audio loop stopped (context cancelled)", s.ID)
+			return
+		}
+		if err != nil {
+			log.Printf("[WARN] WebRTC stream %s: audio source: %v", s.ID, err)
+			return
+		}
+
+		// Sources that read faster than real time are held back; a live
+		// source that falls behind is not made to catch up
+		if ahead := elapsed - time.Since(start); ahead > 0 {
+			select {
+			case <-ctx.Done():
+				log.Printf("[INFO] WebRTC stream %s: audio loop stopped (context cancelled)", s.ID)
+				return
+			case <-time.After(ahead):
+			}
+		} else if ahead < -time.Second {
+			start, elapsed = time.Now(), 0
+		}
+		elapsed += pkt.Duration
+
+		if err := s.AudioTrack.WriteSample(media.Sample{Data: pkt.Data, Duration: pkt.Duration}); err != nil {
+			log.Printf("[WARN] WebRTC stream %s: write audio sample failed: %v", s.ID, err)
+			return
+		}
+		s.audioStats.sent()
+
+		if now := time.Now(); now.Sub(stats) >= statsInterval {
+			st := s.audioStats.snapshot()
+			log.Printf("[INFO] WebRTC stream %s: audio %d packets sent, %d lost (%.1f%%), jitter %s",
+				s.ID, st.PacketsSent, st.PacketsLost, st.FractionLost*100, st.Jitter)
+			stats = now
+		}
+	}
+}
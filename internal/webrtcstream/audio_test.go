@@ -0,0 +1,133 @@
+package webrtcstream
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3"
+	"github.com/pion/webrtc/v3/pkg/media/oggwriter"
+)
+
+// writeOpusFile writes n fake 20ms Opus packets to an Ogg file. Their
+// payloads are their index, which is all the stream needs: it never
+// decodes the audio.
+func writeOpusFile(t *testing.T, n int) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "audio.ogg")
+	w, err := oggwriter.New(path, opusClockRate, opusChannels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		pkt := &rtp.Packet{
+			Header:  rtp.Header{SequenceNumber: uint16(i), Timestamp: uint32(i * 960)},
+			Payload: []byte{0xfc, byte(i)},
+		}
+		if err := w.WriteRTP(pkt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestOggFileSourceLoops(t *testing.T) {
+	src, err := NewOggFileSource(writeOpusFile(t, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	for i := 0; i < 7; i++ {
+		pkt, err := src.ReadPacket()
+		if err != nil {
+			t.Fatalf("packet %d: %v", i, err)
+		}
+		if want := []byte{0xfc, byte(i % 3)}; !bytes.Equal(pkt.Data, want) {
+			t.Fatalf("packet %d = %x, want %x", i, pkt.Data, want)
+		}
+		if pkt.Duration != 20*time.Millisecond {
+			t.Fatalf("packet %d lasts %s", i, pkt.Duration)
+		}
+	}
+
+	if _, err := NewOggFileSource(""); err == nil {
+		t.Fatal("file source without a path opened")
+	}
+}
+
+func TestAudioStatsFromReceiverReport(t *testing.T) {
+	var a audioStats
+	a.handleRTCP([]rtcp.Packet{&rtcp.ReceiverReport{Reports: []rtcp.ReceptionReport{
+		{FractionLost: 64, TotalLost: 12, Jitter: 480},
+	}}})
+	st := a.snapshot()
+	if st.PacketsLost != 12 || st.FractionLost != 0.25 || st.Jitter != 10*time.Millisecond {
+		t.Fatalf("stats %+v", st)
+	}
+}
+
+func TestStartStreamWithAudio(t *testing.T) {
+	m := NewManager()
+	m.SetFrameSource(SourceSynthetic)
+	if err := m.SetAudioSource(AudioFile, AudioConfig{File: writeOpusFile(t, 50)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Start("test", Options{Mode: ModeTiles, Audio: true, AudioSource: "nope"}); err == nil {
+		t.Fatal("unknown audio source accepted")
+	}
+	offer, err := m.Start("test", Options{Mode: ModeTiles, Audio: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop(offer.StreamID)
+	if !offer.Audio {
+		t.Fatal("offer does not report audio")
+	}
+
+	payloads := make(chan []byte, 100)
+	connect(t, m, offer, func(pc *webrtc.PeerConnection) {
+		pc.OnDataChannel(func(*webrtc.DataChannel) {})
+		pc.OnTrack(func(track *webrtc.TrackRemote, _ *webrtc.RTPReceiver) {
+			if track.Codec().MimeType != webrtc.MimeTypeOpus {
+				t.Errorf("track codec %s", track.Codec().MimeType)
+				return
+			}
+			for {
+				pkt, _, err := track.ReadRTP()
+				if err != nil {
+					return
+				}
+				payloads <- pkt.Payload
+			}
+		})
+	})
+
+	// Packets arrive in file order and paced: 10 packets cover 200ms
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		select {
+		case p := <-payloads:
+			if want := []byte{0xfc, byte(i)}; !bytes.Equal(p, want) {
+				t.Fatalf("audio packet %d = %x, want %x", i, p, want)
+			}
+		case <-time.After(15 * time.Second):
+			t.Skip("no audio received; peers could not connect in this environment")
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Fatalf("10 audio packets arrived in %s; source is not paced", elapsed)
+	}
+
+	m.mu.RLock()
+	stream := m.streams[offer.StreamID]
+	m.mu.RUnlock()
+	if st, ok := stream.AudioStats(); !ok || st.PacketsSent < 10 {
+		t.Fatalf("audio stats %+v, %v", st, ok)
+	}
+}
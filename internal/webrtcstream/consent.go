@@ -0,0 +1,54 @@
+package webrtcstream
+
+import (
+	"fmt"
+	"log"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ConsentIndicator tells the person at the device that someone is
+// controlling it. Show and Hide are called once each per controlled stream.
+type ConsentIndicator interface {
+	Show(streamID, principal string)
+	Hide(streamID string)
+}
+
+// DesktopIndicator raises a desktop notification when control starts and
+// ends, using notify-send, osascript or a PowerShell balloon depending on
+// the platform. Notifications are best effort; the event is always logged.
+type DesktopIndicator struct{}
+
+func (DesktopIndicator) Show(streamID, principal string) {
+	log.Printf("[WARN] REMOTE CONTROL ACTIVE: %q is controlling this device (stream %s)", principal, streamID)
+	notify("Remote control active", fmt.Sprintf("%s is controlling this device. Stop the stream to end control.", principal))
+}
+
+func (DesktopIndicator) Hide(streamID string) {
+	log.Printf("[INFO] Remote control ended (stream %s)", streamID)
+	notify("Remote control ended", "Your device is no longer being controlled.")
+}
+
+// notify shows a desktop notification without waiting for it
+func notify(title, body string) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "linux":
+		cmd = exec.Command("notify-send", "--urgency=critical", title, body)
+	case "darwin":
+		cmd = exec.Command("osascript", "-e", fmt.Sprintf("display notification %q with title %q", body, title))
+	case "windows":
+		script := fmt.Sprintf(`Add-Type -AssemblyName System.Windows.Forms; `+
+			`$n = New-Object System.Windows.Forms.NotifyIcon; $n.Icon = [System.Drawing.SystemIcons]::Warning; `+
+			`$n.Visible = $true; $n.ShowBalloonTip(10000, '%s', '%s', 'Warning'); Start-Sleep -Seconds 10; $n.Dispose()`,
+			strings.ReplaceAll(title, "'", "''"), strings.ReplaceAll(body, "'", "''"))
+		cmd = exec.Command("powershell", "-NoProfile", "-WindowStyle", "Hidden", "-Command", script)
+	default:
+		return
+	}
+	if err := cmd.Start(); err != nil {
+		return
+	}
+	go cmd.Wait()
+}
@@ -0,0 +1,134 @@
+package webrtcstream
+
+import (
+	"fmt"
+	"image"
+	"sort"
+	"sync"
+
+	"github.com/edgecli/edgecli/internal/webrtcstream/vp8enc"
+	"github.com/pion/webrtc/v3"
+)
+
+// DefaultVideoEncoder is the encoder used when none is configured
+const DefaultVideoEncoder = "vp8"
+
+// VideoEncoder compresses frames for a video track
+type VideoEncoder interface {
+	// MimeType is the codec negotiated for the track, e.g. webrtc.MimeTypeVP8
+	MimeType() string
+	// Encode compresses one frame. forceKeyframe asks for a frame that
+	// decodes on its own, after packet loss or a PLI.
+	Encode(img image.Image, forceKeyframe bool) ([]byte, error)
+	// SetBitrate sets the target bitrate in bits per second
+	SetBitrate(bps int)
+	Close() error
+}
+
+// EncoderFactory creates an encoder for frames of a fixed size
+type EncoderFactory func(width, height, fps int) (VideoEncoder, error)
+
+var (
+	encodersMu sync.RWMutex
+	encoders   = map[string]EncoderFactory{
+		DefaultVideoEncoder: newVP8Encoder,
+	}
+)
+
+// RegisterEncoder makes a video encoder available by name, e.g. a hardware
+// or cgo-backed encoder built in with a build tag. It replaces any encoder
+// already registered under that name.
+func RegisterEncoder(name string, factory EncoderFactory) {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	encoders[name] = factory
+}
+
+// Encoders lists the registered encoder names
+func Encoders() []string {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+	names := make([]string, 0, len(encoders))
+	for name := range encoders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewVideoEncoder creates a registered encoder
+func NewVideoEncoder(name string, width, height, fps int) (VideoEncoder, error) {
+	encodersMu.RLock()
+	factory, ok := encoders[name]
+	encodersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown video encoder %q (have %v)", name, Encoders())
+	}
+	return factory(width, height, fps)
+}
+
+// vp8Encoder adapts the pure-Go VP8 encoder. Every VP8 frame it produces is
+// a key frame, so keyframe requests need no extra work. It steers the
+// quantizer so the average frame size tracks the bitrate budget.
+type vp8Encoder struct {
+	enc        *vp8enc.Encoder
+	fps        int
+	frameBytes float64 // budget per frame
+	avgBytes   float64 // moving average of encoded frame sizes
+}
+
+func newVP8Encoder(width, height, fps int) (VideoEncoder, error) {
+	enc, err := vp8enc.NewEncoder(width, height)
+	if err != nil {
+		return nil, err
+	}
+	if fps <= 0 {
+		fps = DefaultVideoFPS
+	}
+	e := &vp8Encoder{enc: enc, fps: fps}
+	e.SetBitrate(DefaultMaxBitrateKbps * 1000)
+	return e, nil
+}
+
+func (e *vp8Encoder) MimeType() string {
+	return webrtc.MimeTypeVP8
+}
+
+func (e *vp8Encoder) SetBitrate(bps int) {
+	e.frameBytes = float64(bps) / 8 / float64(e.fps)
+}
+
+func (e *vp8Encoder) Encode(img image.Image, forceKeyframe bool) ([]byte, error) {
+	frame, err := e.enc.Encode(img)
+	if err != nil {
+		return nil, err
+	}
+	e.adapt(len(frame))
+	return frame, nil
+}
+
+// adapt moves the quantizer one step at a time, more when far off budget,
+// with a dead band so a steady scene settles on one quantizer.
+func (e *vp8Encoder) adapt(size int) {
+	if e.avgBytes == 0 {
+		e.avgBytes = float64(size)
+	} else {
+		e.avgBytes = 0.7*e.avgBytes + 0.3*float64(size)
+	}
+	q := e.enc.Quantizer()
+	switch ratio := e.avgBytes / e.frameBytes; {
+	case ratio > 2:
+		q += 6
+	case ratio > 1.1:
+		q += 2
+	case ratio < 0.5:
+		q -= 3
+	case ratio < 0.85:
+		q--
+	}
+	e.enc.SetQuantizer(q)
+}
+
+func (e *vp8Encoder) Close() error {
+	return nil
+}
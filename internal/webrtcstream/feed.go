@@ -0,0 +1,183 @@
+package webrtcstream
+
+import (
+	"fmt"
+	"image"
+	"sync"
+	"time"
+)
+
+// captureHub shares one capture of each monitor between every stream
+// watching it, so N viewers cost one screenshot per frame instead of N
+type captureHub struct {
+	mu    sync.Mutex
+	feeds map[feedKey]*feed
+}
+
+type feedKey struct {
+	source  string
+	monitor int
+}
+
+func newCaptureHub() *captureHub {
+	return &captureHub{feeds: make(map[feedKey]*feed)}
+}
+
+// feed captures one monitor for its subscribers. A capture is reused while
+// it is younger than half the shortest subscriber frame interval, and its
+// downscaled copies are made once per width.
+type feed struct {
+	key    feedKey
+	source FrameSource
+
+	mu       sync.Mutex // held across a capture, so concurrent callers share it
+	subs     map[*feedSubscriber]struct{}
+	last     image.Image
+	at       time.Time
+	scaled   map[int]*image.RGBA
+	captures uint64
+	served   uint64
+}
+
+// FeedInfo describes a monitor's shared capture
+type FeedInfo struct {
+	Source       string
+	MonitorIndex int
+	Subscribers  int
+	Captures     uint64 // screenshots taken
+	FramesServed uint64 // frames handed to streams; above Captures when shared
+}
+
+// subscribe opens the monitor's feed if no stream watches it yet and adds
+// a subscriber capturing at up to fps frames scaled to maxWidth (0 keeps
+// the capture size)
+func (h *captureHub) subscribe(sourceName string, monitor, fps, maxWidth int) (*feedSubscriber, error) {
+	factory, ok := sourceFactories[sourceName]
+	if !ok {
+		return nil, fmt.Errorf("unknown frame source %q", sourceName)
+	}
+	key := feedKey{sourceName, monitor}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	f, ok := h.feeds[key]
+	if !ok {
+		// Opening the source validates the monitor index
+		source, err := factory(monitor)
+		if err != nil {
+			return nil, err
+		}
+		f = &feed{key: key, source: source, subs: make(map[*feedSubscriber]struct{})}
+		h.feeds[key] = f
+	}
+	sub := &feedSubscriber{hub: h, feed: f, fps: fps, maxWidth: maxWidth}
+	f.mu.Lock()
+	f.subs[sub] = struct{}{}
+	f.mu.Unlock()
+	return sub, nil
+}
+
+// unsubscribe removes sub, closing the feed after its last subscriber
+func (h *captureHub) unsubscribe(sub *feedSubscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	f := sub.feed
+	f.mu.Lock()
+	delete(f.subs, sub)
+	empty := len(f.subs) == 0
+	f.mu.Unlock()
+	if empty && h.feeds[f.key] == f {
+		delete(h.feeds, f.key)
+	}
+}
+
+// info lists the open feeds
+func (h *captureHub) info() []FeedInfo {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := make([]FeedInfo, 0, len(h.feeds))
+	for key, f := range h.feeds {
+		f.mu.Lock()
+		out = append(out, FeedInfo{
+			Source:       key.source,
+			MonitorIndex: key.monitor,
+			Subscribers:  len(f.subs),
+			Captures:     f.captures,
+			FramesServed: f.served,
+		})
+		f.mu.Unlock()
+	}
+	return out
+}
+
+// maxAge is how long a capture may be reused; f.mu must be held
+func (f *feed) maxAge() time.Duration {
+	fps := 1
+	for sub := range f.subs {
+		if sub.fps > fps {
+			fps = sub.fps
+		}
+	}
+	return time.Second / time.Duration(fps) / 2
+}
+
+// frame returns the current frame scaled to width, capturing a new one
+// unless the last is recent enough
+func (f *feed) frame(width int) (image.Image, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if now := time.Now(); f.last == nil || now.Sub(f.at) >= f.maxAge() {
+		img, err := f.source.Capture()
+		if err != nil {
+			return nil, err
+		}
+		f.last, f.at, f.scaled = img, now, nil
+		f.captures++
+	}
+	f.served++
+	if width <= 0 {
+		return f.last, nil
+	}
+	if img, ok := f.scaled[width]; ok {
+		return img, nil
+	}
+	img := fitWidth(f.last, width)
+	if f.scaled == nil {
+		f.scaled = make(map[int]*image.RGBA)
+	}
+	f.scaled[width] = img
+	return img, nil
+}
+
+// feedSubscriber is one stream's view of a feed. It is the stream's
+// FrameSource; frames it returns are shared and must not be modified.
+type feedSubscriber struct {
+	hub      *captureHub
+	feed     *feed
+	fps      int
+	maxWidth int
+	once     sync.Once
+}
+
+func (s *feedSubscriber) Bounds() image.Rectangle {
+	b := s.feed.source.Bounds()
+	if s.maxWidth > 0 && b.Dx() > s.maxWidth {
+		return image.Rect(0, 0, s.maxWidth, b.Dy()*s.maxWidth/b.Dx())
+	}
+	return b
+}
+
+// DesktopBounds is where the monitor sits on the desktop, whatever size
+// this subscriber's frames are
+func (s *feedSubscriber) DesktopBounds() image.Rectangle {
+	return desktopBounds(s.feed.source)
+}
+
+func (s *feedSubscriber) Capture() (image.Image, error) {
+	return s.feed.frame(s.maxWidth)
+}
+
+// close leaves the feed; later calls do nothing
+func (s *feedSubscriber) close() {
+	s.once.Do(func() { s.hub.unsubscribe(s) })
+}
@@ -0,0 +1,170 @@
+package webrtcstream
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Tiles mode sends each frame as one or more DataChannel messages
+// ("chunks"), so a frame of any size survives the per-message limit.
+// Every chunk starts with a fixed header, little-endian:
+//
+//	offset size field
+//	0      1    magic (0xED)
+//	1      1    version (1)
+//	2      1    flags (bit 0: keyframe)
+//	3      1    reserved
+//	4      4    frame ID, increasing per frame
+//	8      2    chunk index, from 0
+//	10     2    chunk count
+//	12     -    payload
+//
+// The payloads of a frame's chunks, concatenated in index order, form the
+// frame payload described in tiles.go.
+const (
+	chunkMagic      = 0xED
+	chunkVersion    = 1
+	chunkHeaderSize = 12
+	// ChunkSize is the largest message SplitFrame produces. 16KB is the
+	// size every browser's SCTP stack accepts without fragmentation issues.
+	ChunkSize = 16 * 1024
+	// FlagKeyframe marks a frame that repaints the whole picture
+	FlagKeyframe = 1 << 0
+	// maxPendingFrames bounds how many incomplete frames a Reassembler holds
+	maxPendingFrames = 4
+)
+
+// ChunkHeader is the decoded header of one chunk
+type ChunkHeader struct {
+	Flags   uint8
+	FrameID uint32
+	Index   uint16
+	Count   uint16
+}
+
+// SplitFrame cuts a frame payload into chunks of at most maxSize bytes,
+// header included
+func SplitFrame(frameID uint32, flags uint8, payload []byte, maxSize int) ([][]byte, error) {
+	per := maxSize - chunkHeaderSize
+	if per <= 0 {
+		return nil, fmt.Errorf("chunk size %d too small", maxSize)
+	}
+	count := (len(payload) + per - 1) / per
+	if count == 0 {
+		count = 1
+	}
+	if count > 0xffff {
+		return nil, fmt.Errorf("frame of %d bytes needs %d chunks", len(payload), count)
+	}
+	chunks := make([][]byte, count)
+	for i := range chunks {
+		start := i * per
+		end := start + per
+		if end > len(payload) {
+			end = len(payload)
+		}
+		c := make([]byte, chunkHeaderSize+end-start)
+		c[0], c[1], c[2] = chunkMagic, chunkVersion, flags
+		binary.LittleEndian.PutUint32(c[4:], frameID)
+		binary.LittleEndian.PutUint16(c[8:], uint16(i))
+		binary.LittleEndian.PutUint16(c[10:], uint16(count))
+		copy(c[chunkHeaderSize:], payload[start:end])
+		chunks[i] = c
+	}
+	return chunks, nil
+}
+
+// ParseChunk splits a message into its header and payload
+func ParseChunk(msg []byte) (ChunkHeader, []byte, error) {
+	if len(msg) < chunkHeaderSize || msg[0] != chunkMagic {
+		return ChunkHeader{}, nil, errors.New("not a frame chunk")
+	}
+	if msg[1] != chunkVersion {
+		return ChunkHeader{}, nil, fmt.Errorf("unsupported chunk version %d", msg[1])
+	}
+	h := ChunkHeader{
+		Flags:   msg[2],
+		FrameID: binary.LittleEndian.Uint32(msg[4:]),
+		Index:   binary.LittleEndian.Uint16(msg[8:]),
+		Count:   binary.LittleEndian.Uint16(msg[10:]),
+	}
+	if h.Count == 0 || h.Index >= h.Count {
+		return ChunkHeader{}, nil, fmt.Errorf("chunk %d of %d out of range", h.Index, h.Count)
+	}
+	return h, msg[chunkHeaderSize:], nil
+}
+
+// partialFrame collects the chunks of one frame
+type partialFrame struct {
+	flags    uint8
+	chunks   [][]byte
+	received int
+}
+
+// Reassembler rebuilds frame payloads from chunks. Chunks may interleave
+// across frames; when a frame completes, older incomplete frames are
+// dropped since they can no longer be shown in order.
+type Reassembler struct {
+	pending map[uint32]*partialFrame
+}
+
+// NewReassembler returns an empty reassembler
+func NewReassembler() *Reassembler {
+	return &Reassembler{pending: make(map[uint32]*partialFrame)}
+}
+
+// Add feeds one message. It returns the frame payload once every chunk of
+// the frame has arrived, and nil before that.
+func (r *Reassembler) Add(msg []byte) (ChunkHeader, []byte, error) {
+	h, data, err := ParseChunk(msg)
+	if err != nil {
+		return h, nil, err
+	}
+	p, ok := r.pending[h.FrameID]
+	if !ok {
+		if len(r.pending) >= maxPendingFrames {
+			r.dropOlderThan(h.FrameID)
+		}
+		p = &partialFrame{flags: h.Flags, chunks: make([][]byte, h.Count)}
+		r.pending[h.FrameID] = p
+	}
+	if int(h.Count) != len(p.chunks) {
+		return h, nil, fmt.Errorf("frame %d: chunk count changed from %d to %d", h.FrameID, len(p.chunks), h.Count)
+	}
+	if p.chunks[h.Index] == nil {
+		p.chunks[h.Index] = append([]byte(nil), data...)
+		p.received++
+	}
+	if p.received < len(p.chunks) {
+		return h, nil, nil
+	}
+
+	delete(r.pending, h.FrameID)
+	r.dropOlderThan(h.FrameID)
+	size := 0
+	for _, c := range p.chunks {
+		size += len(c)
+	}
+	payload := make([]byte, 0, size)
+	for _, c := range p.chunks {
+		payload = append(payload, c...)
+	}
+	h.Flags = p.flags
+	return h, payload, nil
+}
+
+// Pending returns the number of incomplete frames held
+func (r *Reassembler) Pending() int {
+	return len(r.pending)
+}
+
+// dropOlderThan discards incomplete frames before id, allowing for the
+// frame counter wrapping around
+func (r *Reassembler) dropOlderThan(id uint32) {
+	for fid := range r.pending {
+		if int32(id-fid) > 0 {
+			delete(r.pending, fid)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package webrtcstream
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSplitAndReassemble(t *testing.T) {
+	payload := make([]byte, 3*ChunkSize+123)
+	for i := range payload {
+		payload[i] = byte(i * 7)
+	}
+	chunks, err := SplitFrame(42, FlagKeyframe, payload, ChunkSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 4 {
+		t.Fatalf("%d chunks, want 4", len(chunks))
+	}
+	for _, c := range chunks {
+		if len(c) > ChunkSize {
+			t.Fatalf("chunk of %d bytes exceeds %d", len(c), ChunkSize)
+		}
+	}
+
+	// Deliver out of order, with a duplicate and a chunk of the next frame
+	// in between
+	r := NewReassembler()
+	other, _ := SplitFrame(43, 0, make([]byte, 2*ChunkSize), ChunkSize)
+	for _, c := range [][]byte{chunks[2], chunks[0], other[0], chunks[0], chunks[3]} {
+		h, got, err := r.Add(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != nil && h.FrameID == 42 {
+			t.Fatal("frame 42 completed early")
+		}
+	}
+	h, got, err := r.Add(chunks[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.FrameID != 42 || h.Flags&FlagKeyframe == 0 || !bytes.Equal(got, payload) {
+		t.Fatalf("reassembled frame %d flags %d, %d bytes; want frame 42 keyframe, %d bytes", h.FrameID, h.Flags, len(got), len(payload))
+	}
+	if r.Pending() != 1 {
+		t.Fatalf("%d frames pending, want the incomplete frame 43", r.Pending())
+	}
+}
+
+func TestReassemblerDropsStaleFrames(t *testing.T) {
+	r := NewReassembler()
+	old, _ := SplitFrame(1, 0, make([]byte, 2*ChunkSize), ChunkSize)
+	r.Add(old[0])
+	cur, _ := SplitFrame(2, 0, []byte("x"), ChunkSize)
+	if _, got, _ := r.Add(cur[0]); string(got) != "x" {
+		t.Fatalf("frame 2 = %q", got)
+	}
+	if r.Pending() != 0 {
+		t.Fatalf("stale frame kept: %d pending", r.Pending())
+	}
+	if _, _, err := r.Add([]byte("plain jpeg")); err == nil {
+		t.Fatal("expected error for a message without a chunk header")
+	}
+}
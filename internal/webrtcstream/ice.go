@@ -0,0 +1,152 @@
+package webrtcstream
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pion/webrtc/v3"
+)
+
+// ParseICEServers turns a comma-separated list of STUN/TURN URLs into ICE
+// servers. username and credential apply to the turn: and turns: URLs.
+func ParseICEServers(urls, username, credential string) ([]webrtc.ICEServer, error) {
+	var servers []webrtc.ICEServer
+	for _, u := range strings.Split(urls, ",") {
+		u = strings.TrimSpace(u)
+		if u == "" {
+			continue
+		}
+		server := webrtc.ICEServer{URLs: []string{u}}
+		switch {
+		case strings.HasPrefix(u, "stun:"), strings.HasPrefix(u, "stuns:"):
+		case strings.HasPrefix(u, "turn:"), strings.HasPrefix(u, "turns:"):
+			if username == "" || credential == "" {
+				return nil, fmt.Errorf("TURN server %s needs a username and credential", u)
+			}
+			server.Username, server.Credential = username, credential
+		default:
+			return nil, fmt.Errorf("ICE server %q is not a stun: or turn: URL", u)
+		}
+		servers = append(servers, server)
+	}
+	return servers, nil
+}
+
+// trickle holds a trickle-ICE stream's candidates: the device's, gathered
+// after the offer is returned, and the viewer's, which may arrive before
+// the answer and are held until it is set.
+type trickle struct {
+	mu       sync.Mutex
+	local    []webrtc.ICECandidateInit
+	complete bool          // gathering finished; local is final
+	changed  chan struct{} // closed and replaced when local changes
+	remote   []webrtc.ICECandidateInit
+	answered bool
+}
+
+func newTrickle() *trickle {
+	return &trickle{changed: make(chan struct{})}
+}
+
+// gathered records a local candidate; nil marks the end of gathering
+func (t *trickle) gathered(c *webrtc.ICECandidate) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if c == nil {
+		t.complete = true
+	} else {
+		t.local = append(t.local, c.ToJSON())
+	}
+	close(t.changed)
+	t.changed = make(chan struct{})
+}
+
+// candidates returns the local candidates after the first since, waiting
+// until there is one, gathering completes or ctx ends
+func (t *trickle) candidates(ctx context.Context, since int) ([]webrtc.ICECandidateInit, bool) {
+	for {
+		t.mu.Lock()
+		if since < 0 {
+			since = 0
+		}
+		if since < len(t.local) || t.complete || ctx.Err() != nil {
+			var out []webrtc.ICECandidateInit
+			if since < len(t.local) {
+				out = append(out, t.local[since:]...)
+			}
+			complete := t.complete
+			t.mu.Unlock()
+			return out, complete
+		}
+		changed := t.changed
+		t.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+		}
+	}
+}
+
+// AddICECandidate adds a viewer candidate to a trickle-ICE stream.
+// Candidates that arrive before the answer are applied by Complete.
+func (m *Manager) AddICECandidate(streamID string, c webrtc.ICECandidateInit) error {
+	stream, err := m.trickleStream(streamID)
+	if err != nil {
+		return err
+	}
+	if c.Candidate == "" {
+		return nil // end of candidates
+	}
+	t := stream.trickle
+	t.mu.Lock()
+	if !t.answered {
+		t.remote = append(t.remote, c)
+		t.mu.Unlock()
+		return nil
+	}
+	t.mu.Unlock()
+	return stream.PeerConnection.AddICECandidate(c)
+}
+
+// ICECandidates returns the device candidates of a trickle-ICE stream after
+// the first since. It waits until there is a new one, gathering completes
+// (complete is then true) or ctx ends.
+func (m *Manager) ICECandidates(ctx context.Context, streamID string, since int) ([]webrtc.ICECandidateInit, bool, error) {
+	stream, err := m.trickleStream(streamID)
+	if err != nil {
+		return nil, false, err
+	}
+	out, complete := stream.trickle.candidates(ctx, since)
+	return out, complete, nil
+}
+
+func (m *Manager) trickleStream(streamID string) (*Stream, error) {
+	m.mu.RLock()
+	stream, ok := m.streams[streamID]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("stream not found: %s", streamID)
+	}
+	if stream.trickle == nil {
+		return nil, fmt.Errorf("stream %s does not use trickle ICE", streamID)
+	}
+	return stream, nil
+}
+
+// applyRemoteCandidates adds the viewer candidates held until the answer
+func (s *Stream) applyRemoteCandidates() error {
+	t := s.trickle
+	t.mu.Lock()
+	pending := t.remote
+	t.remote, t.answered = nil, true
+	t.mu.Unlock()
+	for _, c := range pending {
+		if err := s.PeerConnection.AddICECandidate(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package webrtcstream
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pion/webrtc/v3"
+)
+
+func TestParseICEServers(t *testing.T) {
+	servers, err := ParseICEServers("stun:stun.example.org:3478, turn:relay.example.org:3478?transport=tcp", "u", "p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(servers) != 2 || servers[0].Username != "" || servers[1].Username != "u" || servers[1].Credential != "p" {
+		t.Fatalf("servers %+v", servers)
+	}
+	if _, err := ParseICEServers("turn:relay.example.org", "", ""); err == nil {
+		t.Fatal("TURN server without credentials accepted")
+	}
+	if _, err := ParseICEServers("http://example.org", "", ""); err == nil {
+		t.Fatal("non-ICE URL accepted")
+	}
+	if servers, err := ParseICEServers("", "", ""); err != nil || len(servers) != 0 {
+		t.Fatalf("empty list: %v, %v", servers, err)
+	}
+}
+
+// connectTrickle answers a trickle offer, exchanging candidates through
+// the manager as a signaling server would, and returns the first frame
+// message received
+func connectTrickle(t *testing.T, m *Manager, offer *Offer, config webrtc.Configuration) chan []byte {
+	t.Helper()
+	if strings.Contains(offer.SDP, "a=candidate") {
+		t.Fatal("trickle offer carries candidates")
+	}
+	pc, err := webrtc.NewPeerConnection(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pc.Close() })
+
+	frames := make(chan []byte, 1)
+	pc.OnDataChannel(func(dc *webrtc.DataChannel) {
+		dc.OnMessage(func(msg webrtc.DataChannelMessage) {
+			select {
+			case frames <- msg.Data:
+			default:
+			}
+		})
+	})
+	// Viewer candidates go to the device as they are found, before the
+	// answer has been delivered
+	pc.OnICECandidate(func(c *webrtc.ICECandidate) {
+		if c == nil {
+			return
+		}
+		// Fails only once the stream has stopped
+		m.AddICECandidate(offer.StreamID, c.ToJSON())
+	})
+
+	if err := pc.SetRemoteDescription(webrtc.SessionDescription{Type: webrtc.SDPTypeOffer, SDP: offer.SDP}); err != nil {
+		t.Fatal(err)
+	}
+	ans, err := pc.CreateAnswer(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pc.SetLocalDescription(ans); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if err := m.Complete(offer.StreamID, pc.LocalDescription().SDP); err != nil {
+		t.Fatal(err)
+	}
+
+	// Device candidates, polled as the web UI does
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() {
+		since := 0
+		for ctx.Err() == nil {
+			pollCtx, pollCancel := context.WithTimeout(ctx, time.Second)
+			cands, complete, err := m.ICECandidates(pollCtx, offer.StreamID, since)
+			pollCancel()
+			if err != nil {
+				return
+			}
+			for _, c := range cands {
+				pc.AddICECandidate(c)
+			}
+			since += len(cands)
+			if complete {
+				return
+			}
+		}
+	}()
+	return frames
+}
+
+func TestTrickleICE(t *testing.T) {
+	m := NewManager()
+	m.SetFrameSource(SourceSynthetic)
+	start := time.Now()
+	offer, err := m.Start("test", Options{Mode: ModeTiles, Trickle: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop(offer.StreamID)
+	if !offer.Trickle {
+		t.Fatal("offer does not report trickle ICE")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("trickle offer took %s", elapsed)
+	}
+
+	frames := connectTrickle(t, m, offer, webrtc.Configuration{})
+	select {
+	case <-frames:
+	case <-time.After(15 * time.Second):
+		t.Skip("no frame received; peers could not connect in this environment")
+	}
+
+	if err := m.AddICECandidate("nope", webrtc.ICECandidateInit{Candidate: "x"}); err == nil {
+		t.Fatal("candidate for unknown stream accepted")
+	}
+}
+
+func TestTURNRelayOnly(t *testing.T) {
+	relay, err := StartTURNRelay(TURNConfig{ListenAddr: "127.0.0.1:0", PublicIP: "127.0.0.1"})
+	if err != nil {
+		t.Skipf("TURN relay: %v", err)
+	}
+	defer relay.Close()
+	server, err := relay.ICEServer(time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Both peers may only use relay candidates, so frames must go through
+	// the relay
+	m := NewManager()
+	m.SetFrameSource(SourceSynthetic)
+	offer, err := m.Start("test", Options{
+		Mode:       ModeTiles,
+		Trickle:    true,
+		ICEServers: []webrtc.ICEServer{server},
+		RelayOnly:  true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop(offer.StreamID)
+	if len(offer.ICEServers) != 1 || offer.ICEServers[0].Username == "" {
+		t.Fatalf("offer ICE servers %+v", offer.ICEServers)
+	}
+
+	frames := connectTrickle(t, m, offer, webrtc.Configuration{
+		ICEServers:         offer.ICEServers,
+		ICETransportPolicy: webrtc.ICETransportPolicyRelay,
+	})
+	select {
+	case <-frames:
+	case <-time.After(15 * time.Second):
+		t.Skip("no frame received through the relay in this environment")
+	}
+
+	// A wrong credential gets no allocation, so no candidates
+	bad := server
+	bad.Credential = "wrong"
+	m2 := NewManager()
+	m2.SetFrameSource(SourceSynthetic)
+	offer2, err := m2.Start("test", Options{Mode: ModeTiles, Trickle: true, ICEServers: []webrtc.ICEServer{bad}, RelayOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m2.Stop(offer2.StreamID)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var got []webrtc.ICECandidateInit
+	for since := 0; ; {
+		cands, complete, err := m2.ICECandidates(ctx, offer2.StreamID, since)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, cands...)
+		since += len(cands)
+		if complete || ctx.Err() != nil {
+			break
+		}
+	}
+	if len(got) != 0 {
+		t.Fatalf("relay granted an allocation with a bad credential: %v", got)
+	}
+}
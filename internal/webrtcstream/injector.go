@@ -0,0 +1,144 @@
+package webrtcstream
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Injector names accepted by Manager.SetInputInjector
+const (
+	InjectorNoop    = "noop"
+	InjectorXdotool = "xdotool"
+)
+
+// MouseButton follows the DOM MouseEvent.button numbering
+type MouseButton int
+
+const (
+	MouseLeft   MouseButton = 0
+	MouseMiddle MouseButton = 1
+	MouseRight  MouseButton = 2
+)
+
+// Injector synthesises input on the local desktop
+type Injector interface {
+	// MouseMove moves the pointer to desktop coordinates
+	MouseMove(x, y int) error
+	MouseButton(button MouseButton, down bool) error
+	// Scroll scrolls by wheel notches; positive dy scrolls down, positive
+	// dx scrolls right
+	Scroll(dx, dy int) error
+	// Key presses or releases a key named as in DOM KeyboardEvent.key,
+	// e.g. "a", "Enter", "ArrowLeft", "Shift"
+	Key(key string, down bool) error
+	Close() error
+}
+
+// InjectorFactory opens an injector for one controlled stream
+type InjectorFactory func() (Injector, error)
+
+var (
+	injectorsMu sync.RWMutex
+	injectors   = map[string]InjectorFactory{
+		InjectorNoop:    func() (Injector, error) { return NoopInjector{}, nil },
+		InjectorXdotool: newXdotoolInjector,
+	}
+)
+
+// RegisterInjector makes an input backend available by name. It replaces
+// any injector already registered under that name.
+func RegisterInjector(name string, factory InjectorFactory) {
+	injectorsMu.Lock()
+	defer injectorsMu.Unlock()
+	injectors[name] = factory
+}
+
+// Injectors lists the registered injector names
+func Injectors() []string {
+	injectorsMu.RLock()
+	defer injectorsMu.RUnlock()
+	names := make([]string, 0, len(injectors))
+	for name := range injectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewInjector opens a registered injector
+func NewInjector(name string) (Injector, error) {
+	injectorsMu.RLock()
+	factory, ok := injectors[name]
+	injectorsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown input injector %q (have %v)", name, Injectors())
+	}
+	return factory()
+}
+
+// NoopInjector discards all input. It is the default, so granting control
+// on a device without a configured backend has no effect.
+type NoopInjector struct{}
+
+func (NoopInjector) MouseMove(x, y int) error                   { return nil }
+func (NoopInjector) MouseButton(b MouseButton, down bool) error { return nil }
+func (NoopInjector) Scroll(dx, dy int) error                    { return nil }
+func (NoopInjector) Key(key string, down bool) error            { return nil }
+func (NoopInjector) Close() error                               { return nil }
+
+// RecordedInput is one call made on a RecordingInjector
+type RecordedInput struct {
+	Op     string // "move", "button", "scroll" or "key"
+	X, Y   int    // move position or scroll amount
+	Button MouseButton
+	Key    string
+	Down   bool
+}
+
+// RecordingInjector keeps every call, for tests and dry runs
+type RecordingInjector struct {
+	mu     sync.Mutex
+	events []RecordedInput
+	closed bool
+}
+
+func (r *RecordingInjector) record(e RecordedInput) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return fmt.Errorf("injector closed")
+	}
+	r.events = append(r.events, e)
+	return nil
+}
+
+func (r *RecordingInjector) MouseMove(x, y int) error {
+	return r.record(RecordedInput{Op: "move", X: x, Y: y})
+}
+
+func (r *RecordingInjector) MouseButton(b MouseButton, down bool) error {
+	return r.record(RecordedInput{Op: "button", Button: b, Down: down})
+}
+
+func (r *RecordingInjector) Scroll(dx, dy int) error {
+	return r.record(RecordedInput{Op: "scroll", X: dx, Y: dy})
+}
+
+func (r *RecordingInjector) Key(key string, down bool) error {
+	return r.record(RecordedInput{Op: "key", Key: key, Down: down})
+}
+
+func (r *RecordingInjector) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	return nil
+}
+
+// Events returns a copy of the calls recorded so far
+func (r *RecordingInjector) Events() []RecordedInput {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]RecordedInput(nil), r.events...)
+}
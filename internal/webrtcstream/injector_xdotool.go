@@ -0,0 +1,125 @@
+package webrtcstream
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+// xdotoolKeys maps the DOM key names a viewer may press to X keysyms. Keys
+// not listed, other than single printable characters, are rejected, so a
+// viewer cannot send arbitrary keysyms.
+var xdotoolKeys = map[string]string{
+	"Enter":      "Return",
+	"Backspace":  "BackSpace",
+	"Escape":     "Escape",
+	"Tab":        "Tab",
+	" ":          "space",
+	"ArrowLeft":  "Left",
+	"ArrowRight": "Right",
+	"ArrowUp":    "Up",
+	"ArrowDown":  "Down",
+	"Delete":     "Delete",
+	"Home":       "Home",
+	"End":        "End",
+	"PageUp":     "Prior",
+	"PageDown":   "Next",
+	"Insert":     "Insert",
+	"Shift":      "shift",
+	"Control":    "ctrl",
+	"Alt":        "alt",
+	"Meta":       "super",
+	"CapsLock":   "Caps_Lock",
+}
+
+func init() {
+	// Function keys share their DOM names
+	for i := 1; i <= 12; i++ {
+		name := fmt.Sprintf("F%d", i)
+		xdotoolKeys[name] = name
+	}
+}
+
+// xdotoolInjector drives an X11 desktop through the xdotool command. Each
+// call runs one process, which is fine at human input rates; pointer moves
+// are coalesced before they get here.
+type xdotoolInjector struct {
+	path string
+}
+
+func newXdotoolInjector() (Injector, error) {
+	path, err := exec.LookPath("xdotool")
+	if err != nil {
+		return nil, fmt.Errorf("xdotool not found: %w", err)
+	}
+	return &xdotoolInjector{path: path}, nil
+}
+
+func (x *xdotoolInjector) run(args ...string) error {
+	if out, err := exec.Command(x.path, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("xdotool %v: %w: %s", args, err, out)
+	}
+	return nil
+}
+
+func (x *xdotoolInjector) MouseMove(px, py int) error {
+	return x.run("mousemove", strconv.Itoa(px), strconv.Itoa(py))
+}
+
+func (x *xdotoolInjector) MouseButton(b MouseButton, down bool) error {
+	// X numbers buttons 1 left, 2 middle, 3 right; the wheel and extra
+	// buttons above that are not for viewers to press
+	if b < MouseLeft || b > MouseRight {
+		return fmt.Errorf("unsupported mouse button %d", b)
+	}
+	op := "mouseup"
+	if down {
+		op = "mousedown"
+	}
+	return x.run(op, strconv.Itoa(int(b)+1))
+}
+
+func (x *xdotoolInjector) Scroll(dx, dy int) error {
+	// Wheel notches are clicks on buttons 4 (up), 5 (down), 6 (left), 7 (right)
+	for _, s := range []struct{ n, neg, pos int }{{dy, 4, 5}, {dx, 6, 7}} {
+		if s.n == 0 {
+			continue
+		}
+		button, n := s.pos, s.n
+		if n < 0 {
+			button, n = s.neg, -n
+		}
+		n = min(n, maxScrollNotches)
+		if err := x.run("click", "--repeat", strconv.Itoa(n), strconv.Itoa(button)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (x *xdotoolInjector) Key(key string, down bool) error {
+	sym, named := xdotoolKeys[key]
+	if !named {
+		r, size := utf8.DecodeRuneInString(key)
+		if r == utf8.RuneError || size != len(key) || !unicode.IsPrint(r) {
+			return fmt.Errorf("unsupported key %q", key)
+		}
+		// Printable characters are typed on key down, which also covers
+		// punctuation that has no simple keysym name
+		if !down {
+			return nil
+		}
+		return x.run("type", "--delay", "0", "--", key)
+	}
+	op := "keyup"
+	if down {
+		op = "keydown"
+	}
+	return x.run(op, sym)
+}
+
+func (x *xdotoolInjector) Close() error {
+	return nil
+}
@@ -0,0 +1,286 @@
+package webrtcstream
+
+import (
+	"context"
+	"encoding/json"
+	"image"
+	"log"
+	"math"
+	"sync"
+
+	"github.com/pion/webrtc/v3"
+)
+
+// Input event types sent by the viewer on the "input" data channel
+const (
+	InputMove    = "move"
+	InputDown    = "down"
+	InputUp      = "up"
+	InputClick   = "click"
+	InputWheel   = "wheel"
+	InputKeyDown = "keydown"
+	InputKeyUp   = "keyup"
+)
+
+// Control states the device reports on the input channel
+const (
+	ControlPending = "pending" // waiting for the local user to approve
+	ControlGranted = "granted"
+	ControlDenied  = "denied"
+	ControlRevoked = "revoked"
+)
+
+// wheelNotch is the DOM wheel delta of one notch in pixel mode
+const wheelNotch = 100
+
+// maxScrollNotches caps the notches one wheel event can scroll, so a
+// crafted delta cannot queue an endless scroll
+const maxScrollNotches = 20
+
+// InputEvent is one JSON message from the viewer. Pointer positions are
+// fractions of the streamed frame (0..1), so the viewer needs neither the
+// monitor's resolution nor its place on the desktop.
+type InputEvent struct {
+	Type   string      `json:"t"`
+	X      float64     `json:"x,omitempty"`
+	Y      float64     `json:"y,omitempty"`
+	Button MouseButton `json:"b,omitempty"`
+	DX     float64     `json:"dx,omitempty"` // wheel deltas in DOM pixels
+	DY     float64     `json:"dy,omitempty"`
+	Key    string      `json:"k,omitempty"` // DOM KeyboardEvent.key
+}
+
+// ControlStatus is sent by the device whenever the control state changes
+type ControlStatus struct {
+	Type  string `json:"t"` // always "control"
+	State string `json:"state"`
+}
+
+// ControlAuthorizer asks the local user whether principal may control the
+// monitor. It blocks until they answer or ctx ends.
+type ControlAuthorizer func(ctx context.Context, principal string, monitorIndex int) bool
+
+// inputControl serves one stream's input channel. Events are dropped until
+// the authorizer approves; after that they are mapped onto the monitor and
+// injected in order.
+type inputControl struct {
+	streamID  string
+	principal string
+	monitor   int
+	bounds    image.Rectangle // the monitor's rectangle on the desktop
+	injector  string
+	authorize ControlAuthorizer
+	indicator ConsentIndicator
+
+	dc     *webrtc.DataChannel
+	events chan InputEvent
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	granted bool
+	inj     Injector
+	state   string // last state sent to the viewer
+}
+
+// desktopBounds returns where a source's frames sit on the desktop; sources
+// that are not a real screen map onto their own frame
+func desktopBounds(src FrameSource) image.Rectangle {
+	if d, ok := src.(interface{ DesktopBounds() image.Rectangle }); ok {
+		return d.DesktopBounds()
+	}
+	return src.Bounds()
+}
+
+// mapPoint converts a frame fraction to a desktop pixel inside bounds
+func mapPoint(fx, fy float64, bounds image.Rectangle) image.Point {
+	clamp := func(f float64, lo, n int) int {
+		if math.IsNaN(f) || f < 0 {
+			f = 0
+		}
+		v := lo + int(f*float64(n))
+		if v > lo+n-1 {
+			v = lo + n - 1
+		}
+		return v
+	}
+	return image.Pt(clamp(fx, bounds.Min.X, bounds.Dx()), clamp(fy, bounds.Min.Y, bounds.Dy()))
+}
+
+// setupInput creates the input data channel for a stream that asked for
+// control
+func (s *Stream) setupInput(pc *webrtc.PeerConnection, opts Options, injector string, indicator ConsentIndicator) error {
+	dc, err := pc.CreateDataChannel("input", nil)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	ic := &inputControl{
+		streamID:  s.ID,
+		principal: opts.Principal,
+		monitor:   opts.MonitorIndex,
+		bounds:    desktopBounds(s.source),
+		injector:  injector,
+		authorize: opts.AuthorizeControl,
+		indicator: indicator,
+		dc:        dc,
+		events:    make(chan InputEvent, 256),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+	s.input = ic
+
+	dc.OnOpen(func() {
+		go ic.run()
+	})
+	dc.OnMessage(func(msg webrtc.DataChannelMessage) {
+		var ev InputEvent
+		if err := json.Unmarshal(msg.Data, &ev); err != nil {
+			return
+		}
+		ic.mu.Lock()
+		granted := ic.granted
+		ic.mu.Unlock()
+		if !granted {
+			return
+		}
+		select {
+		case ic.events <- ev:
+		default:
+			// The injector is behind; dropping beats queueing stale input
+		}
+	})
+	dc.OnClose(ic.stop)
+	return nil
+}
+
+// run asks for approval, then injects events until the stream ends
+func (ic *inputControl) run() {
+	ic.send(ControlPending)
+	if ic.authorize == nil || !ic.authorize(ic.ctx, ic.principal, ic.monitor) {
+		log.Printf("[INFO] WebRTC stream %s: input control for %q denied", ic.streamID, ic.principal)
+		ic.send(ControlDenied)
+		return
+	}
+	inj, err := NewInjector(ic.injector)
+	if err != nil {
+		log.Printf("[ERROR] WebRTC stream %s: input injector: %v", ic.streamID, err)
+		ic.send(ControlDenied)
+		return
+	}
+
+	ic.mu.Lock()
+	if ic.ctx.Err() != nil {
+		ic.mu.Unlock()
+		inj.Close()
+		return
+	}
+	ic.granted, ic.inj = true, inj
+	ic.mu.Unlock()
+	ic.indicator.Show(ic.streamID, ic.principal)
+	log.Printf("[WARN] WebRTC stream %s: input control granted to %q (monitor %d, injector %s)",
+		ic.streamID, ic.principal, ic.monitor, ic.injector)
+	ic.send(ControlGranted)
+
+	for {
+		select {
+		case <-ic.ctx.Done():
+			return
+		case ev := <-ic.events:
+			// Coalesce queued moves; only the latest position matters
+			for ev.Type == InputMove && len(ic.events) > 0 {
+				next := <-ic.events
+				if next.Type != InputMove {
+					ic.inject(inj, ev)
+				}
+				ev = next
+			}
+			ic.inject(inj, ev)
+		}
+	}
+}
+
+// inject applies one event; errors are logged and the session continues
+func (ic *inputControl) inject(inj Injector, ev InputEvent) {
+	var err error
+	switch ev.Type {
+	case InputMove:
+		p := mapPoint(ev.X, ev.Y, ic.bounds)
+		err = inj.MouseMove(p.X, p.Y)
+	case InputDown, InputUp:
+		p := mapPoint(ev.X, ev.Y, ic.bounds)
+		if err = inj.MouseMove(p.X, p.Y); err == nil {
+			err = inj.MouseButton(ev.Button, ev.Type == InputDown)
+		}
+	case InputClick:
+		p := mapPoint(ev.X, ev.Y, ic.bounds)
+		if err = inj.MouseMove(p.X, p.Y); err == nil {
+			if err = inj.MouseButton(ev.Button, true); err == nil {
+				err = inj.MouseButton(ev.Button, false)
+			}
+		}
+	case InputWheel:
+		err = inj.Scroll(notches(ev.DX), notches(ev.DY))
+	case InputKeyDown, InputKeyUp:
+		if ev.Key != "" {
+			err = inj.Key(ev.Key, ev.Type == InputKeyDown)
+		}
+	}
+	if err != nil {
+		log.Printf("[WARN] WebRTC stream %s: inject %s: %v", ic.streamID, ev.Type, err)
+	}
+}
+
+// notches rounds a DOM wheel delta to whole notches, at least one for any
+// movement and at most maxScrollNotches
+func notches(d float64) int {
+	if math.IsNaN(d) {
+		return 0
+	}
+	r := math.Max(-maxScrollNotches, math.Min(maxScrollNotches, math.Round(d/wheelNotch)))
+	n := int(r)
+	if n == 0 && d != 0 {
+		n = int(math.Copysign(1, d))
+	}
+	return n
+}
+
+func (ic *inputControl) send(state string) {
+	ic.mu.Lock()
+	ic.state = state
+	ic.mu.Unlock()
+	data, _ := json.Marshal(ControlStatus{Type: "control", State: state})
+	if err := ic.dc.SendText(string(data)); err != nil {
+		log.Printf("[WARN] WebRTC stream %s: input status: %v", ic.streamID, err)
+	}
+}
+
+// currentState returns the control state last reported to the viewer, or
+// ControlPending before the input channel opens
+func (ic *inputControl) currentState() string {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+	if ic.state == "" {
+		return ControlPending
+	}
+	return ic.state
+}
+
+// stop ends control: pending approval is abandoned, the injector closed and
+// the consent indicator removed
+func (ic *inputControl) stop() {
+	ic.cancel()
+	ic.mu.Lock()
+	granted, inj := ic.granted, ic.inj
+	ic.granted, ic.inj = false, nil
+	ic.mu.Unlock()
+	if !granted {
+		return
+	}
+	inj.Close()
+	ic.indicator.Hide(ic.streamID)
+	if ic.dc.ReadyState() == webrtc.DataChannelStateOpen {
+		ic.send(ControlRevoked)
+	}
+	log.Printf("[INFO] WebRTC stream %s: input control by %q ended", ic.streamID, ic.principal)
+}
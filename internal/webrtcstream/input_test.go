@@ -0,0 +1,233 @@
+package webrtcstream
+
+import (
+	"context"
+	"encoding/json"
+	"image"
+	"math"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pion/webrtc/v3"
+)
+
+func TestMapPoint(t *testing.T) {
+	// A second monitor to the right of a 1920-wide primary
+	bounds := image.Rect(1920, 0, 1920+2560, 1440)
+	for _, tc := range []struct {
+		x, y float64
+		want image.Point
+	}{
+		{0, 0, image.Pt(1920, 0)},
+		{0.5, 0.5, image.Pt(1920+1280, 720)},
+		{1, 1, image.Pt(1920+2559, 1439)},
+		{-0.2, 7, image.Pt(1920, 1439)},
+	} {
+		if got := mapPoint(tc.x, tc.y, bounds); got != tc.want {
+			t.Errorf("mapPoint(%v, %v) = %v, want %v", tc.x, tc.y, got, tc.want)
+		}
+	}
+	if notches(-3) != -1 || notches(240) != 2 || notches(0) != 0 {
+		t.Errorf("notches: %d %d %d", notches(-3), notches(240), notches(0))
+	}
+}
+
+type recordingIndicator struct {
+	mu    sync.Mutex
+	shown []string
+	hides int
+}
+
+func (r *recordingIndicator) Show(streamID, principal string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.shown = append(r.shown, principal)
+}
+
+func (r *recordingIndicator) Hide(streamID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hides++
+}
+
+// startControlled starts a synthetic stream with input control and
+// returns the viewer's input channel and the control states it receives
+func startControlled(t *testing.T, approve bool) (*Manager, *Offer, *RecordingInjector, *recordingIndicator, chan *webrtc.DataChannel, chan string) {
+	t.Helper()
+	rec := &RecordingInjector{}
+	RegisterInjector("recording-test", func() (Injector, error) { return rec, nil })
+	ind := &recordingIndicator{}
+
+	m := NewManager()
+	m.SetFrameSource(SourceSynthetic)
+	if err := m.SetInputInjector("recording-test"); err != nil {
+		t.Fatal(err)
+	}
+	m.SetConsentIndicator(ind)
+	offer, err := m.Start("test", Options{
+		Mode:         ModeTiles,
+		InputControl: true,
+		Principal:    "tester",
+		AuthorizeControl: func(ctx context.Context, principal string, monitor int) bool {
+			return approve && principal == "tester" && monitor == 0
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !offer.InputControl {
+		t.Fatal("offer does not report input control")
+	}
+
+	channels := make(chan *webrtc.DataChannel, 1)
+	states := make(chan string, 8)
+	connect(t, m, offer, func(pc *webrtc.PeerConnection) {
+		pc.OnDataChannel(func(dc *webrtc.DataChannel) {
+			if dc.Label() != "input" {
+				return
+			}
+			dc.OnMessage(func(msg webrtc.DataChannelMessage) {
+				var st ControlStatus
+				if json.Unmarshal(msg.Data, &st) == nil && st.Type == "control" {
+					states <- st.State
+				}
+			})
+			channels <- dc
+		})
+	})
+	return m, offer, rec, ind, channels, states
+}
+
+func waitState(t *testing.T, states chan string, want string) {
+	t.Helper()
+	for {
+		select {
+		case s := <-states:
+			if s == want {
+				return
+			}
+			if s != ControlPending {
+				t.Fatalf("control state %q, want %q", s, want)
+			}
+		case <-time.After(15 * time.Second):
+			t.Skip("no control state received; peers could not connect in this environment")
+		}
+	}
+}
+
+func TestNotchesAreCapped(t *testing.T) {
+	for _, tc := range []struct {
+		delta float64
+		want  int
+	}{
+		{0, 0},
+		{3, 1},
+		{-240, -2},
+		{1e12, maxScrollNotches},
+		{-1e12, -maxScrollNotches},
+		{math.Inf(1), maxScrollNotches},
+		{math.NaN(), 0},
+	} {
+		if got := notches(tc.delta); got != tc.want {
+			t.Errorf("notches(%v) = %d, want %d", tc.delta, got, tc.want)
+		}
+	}
+}
+
+func TestInputControlGranted(t *testing.T) {
+	m, offer, rec, ind, channels, states := startControlled(t, true)
+	waitState(t, states, ControlGranted)
+	dc := <-channels
+
+	for _, ev := range []InputEvent{
+		{Type: InputMove, X: 0.5, Y: 0.5},
+		{Type: InputClick, X: 0.25, Y: 0.5, Button: MouseRight},
+		{Type: InputWheel, DY: 240},
+		{Type: InputKeyDown, Key: "Enter"},
+		{Type: InputKeyUp, Key: "Enter"},
+	} {
+		data, _ := json.Marshal(ev)
+		if err := dc.Send(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []RecordedInput{
+		{Op: "move", X: 640, Y: 360},
+		{Op: "move", X: 320, Y: 360},
+		{Op: "button", Button: MouseRight, Down: true},
+		{Op: "button", Button: MouseRight},
+		{Op: "scroll", Y: 2},
+		{Op: "key", Key: "Enter", Down: true},
+		{Op: "key", Key: "Enter"},
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for len(rec.Events()) < len(want) && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	got := rec.Events()
+	if len(got) != len(want) {
+		t.Fatalf("recorded %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("event %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	m.Stop(offer.StreamID)
+	ind.mu.Lock()
+	defer ind.mu.Unlock()
+	if len(ind.shown) != 1 || ind.shown[0] != "tester" || ind.hides != 1 {
+		t.Fatalf("indicator shown %v, hidden %d times; want shown once and hidden once", ind.shown, ind.hides)
+	}
+}
+
+func TestInputControlDenied(t *testing.T) {
+	m, offer, rec, ind, channels, states := startControlled(t, false)
+	defer m.Stop(offer.StreamID)
+	waitState(t, states, ControlDenied)
+	dc := <-channels
+
+	data, _ := json.Marshal(InputEvent{Type: InputClick, X: 0.5, Y: 0.5})
+	dc.Send(data)
+	time.Sleep(200 * time.Millisecond)
+	if got := rec.Events(); len(got) != 0 {
+		t.Fatalf("denied stream injected %+v", got)
+	}
+	ind.mu.Lock()
+	defer ind.mu.Unlock()
+	if len(ind.shown) != 0 {
+		t.Fatal("indicator shown without approval")
+	}
+}
+
+func TestXdotoolRejectsUnlistedInput(t *testing.T) {
+	// A missing binary makes every accepted call fail at exec, so only the
+	// validation errors mention "unsupported"
+	x := &xdotoolInjector{path: "/nonexistent/xdotool"}
+	for name, err := range map[string]error{
+		"button 3":      x.MouseButton(3, true),
+		"button -1":     x.MouseButton(-1, true),
+		"keysym":        x.Key("XF86PowerOff", true),
+		"control char":  x.Key("\x07", true),
+		"invalid utf-8": x.Key("\xff", true),
+		"empty key":     x.Key("", true),
+	} {
+		if err == nil || !strings.Contains(err.Error(), "unsupported") {
+			t.Errorf("%s: err = %v, want unsupported", name, err)
+		}
+	}
+	for name, err := range map[string]error{
+		"right button": x.MouseButton(MouseRight, true),
+		"enter":        x.Key("Enter", true),
+		"f5":           x.Key("F5", true),
+		"letter":       x.Key("é", true),
+	} {
+		if err == nil || strings.Contains(err.Error(), "unsupported") {
+			t.Errorf("%s: err = %v, want an exec error", name, err)
+		}
+	}
+}
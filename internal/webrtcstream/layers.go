@@ -0,0 +1,40 @@
+package webrtcstream
+
+import "fmt"
+
+// Quality layers a viewer can pick per stream
+const (
+	LayerHigh   = "high"
+	LayerMedium = "medium"
+	LayerLow    = "low"
+)
+
+// QualityLayer sets the frame size and the defaults for the frame rate,
+// bitrate and JPEG quality of a stream. Options set explicitly override
+// the defaults.
+type QualityLayer struct {
+	MaxWidth       int // 0 keeps the capture size (video is still capped at 1280)
+	FPS            int // 0 uses the mode default
+	MaxBitrateKbps int // 0 uses DefaultMaxBitrateKbps
+	JPEGQuality    int // 0 uses 60
+}
+
+// qualityLayers maps layer names to their settings. Viewers of one monitor
+// share its capture whatever their layer; each layer is scaled once per frame.
+var qualityLayers = map[string]QualityLayer{
+	LayerHigh:   {},
+	LayerMedium: {MaxWidth: 960, FPS: 10, MaxBitrateKbps: 1200, JPEGQuality: 50},
+	LayerLow:    {MaxWidth: 640, FPS: 5, MaxBitrateKbps: 500, JPEGQuality: 40},
+}
+
+// Layer returns a quality layer by name; empty is LayerHigh
+func Layer(name string) (QualityLayer, error) {
+	if name == "" {
+		name = LayerHigh
+	}
+	l, ok := qualityLayers[name]
+	if !ok {
+		return QualityLayer{}, fmt.Errorf("unknown quality layer %q (have %s, %s, %s)", name, LayerHigh, LayerMedium, LayerLow)
+	}
+	return l, nil
+}
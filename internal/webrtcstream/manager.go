@@ -12,129 +12,561 @@ import (
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/kbinani/screenshot"
 	"github.com/pion/webrtc/v3"
 	"golang.org/x/image/draw"
 )
 
 const maxMessageSize = 63 * 1024 // 63KB, safely under 64KB SCTP limit
 
+// Stream modes
+const (
+	// ModeVideo sends an encoded video track (VP8 by default)
+	ModeVideo = "video"
+	// ModeTiles sends full-resolution frames over a DataChannel as changed
+	// JPEG regions, chunked (see framing.go), for viewers that cannot decode
+	// the video track
+	ModeTiles = "tiles"
+	// ModeJPEG sends one downscaled JPEG per DataChannel message, for
+	// viewers that predate tiles mode
+	ModeJPEG = "jpeg"
+)
+
+// modePreference orders the modes from best to last resort
+var modePreference = []string{ModeVideo, ModeTiles, ModeJPEG}
+
+// NegotiateMode picks the stream mode. An explicit mode wins; otherwise
+// the first mode in preference order that the viewer accepts. With
+// neither, the stream uses video.
+func NegotiateMode(mode string, accept []string) (string, error) {
+	if mode != "" {
+		for _, m := range modePreference {
+			if m == mode {
+				return mode, nil
+			}
+		}
+		return "", fmt.Errorf("unknown stream mode %q", mode)
+	}
+	if len(accept) == 0 {
+		return ModeVideo, nil
+	}
+	for _, m := range modePreference {
+		for _, a := range accept {
+			if a == m {
+				return m, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no common stream mode: viewer accepts %v, device supports %v", accept, modePreference)
+}
+
+// Options configures a new stream
+type Options struct {
+	TargetFPS      int      // default 15 for video, 8 for tiles and jpeg
+	JPEGQuality    int      // tiles and jpeg modes only, default 60
+	MonitorIndex   int      // default 0
+	Mode           string   // forces a mode; see NegotiateMode
+	AcceptModes    []string // modes the viewer can display, any order
+	MaxBitrateKbps int      // video mode only, default 2500
+
+	// InputControl offers an "input" data channel for mouse and keyboard
+	// events. Nothing is injected until AuthorizeControl approves.
+	InputControl     bool
+	Principal        string // who asks for control, shown to the local user
+	AuthorizeControl ControlAuthorizer
+
+	// Audio adds an Opus track from AudioSource, or from the manager's
+	// default source if empty
+	Audio       bool
+	AudioSource string
+
+	// Trickle returns the offer without waiting for ICE gathering; the
+	// device's candidates are then fetched with ICECandidates and the
+	// viewer's sent with AddICECandidate
+	Trickle bool
+	// ICEServers are used in addition to the manager's, e.g. a relay the
+	// coordinator runs
+	ICEServers []webrtc.ICEServer
+	// RelayOnly restricts the device to TURN relay candidates
+	RelayOnly bool
+
+	// Layer picks a quality preset (LayerHigh if empty); see QualityLayer
+	Layer string
+	// Viewer names who is watching, for Streams
+	Viewer string
+	// Record writes the stream to a file under the manager's recording
+	// directory; see SetRecordingDir
+	Record bool
+}
+
+// Offer is the result of starting a stream
+type Offer struct {
+	StreamID     string
+	SDP          string // includes the ICE candidates unless Trickle
+	Mode         string
+	InputControl bool // an input channel is offered
+	Audio        bool // an audio track is offered
+	Trickle      bool
+	ICEServers   []webrtc.ICEServer // the servers the device uses; the viewer should use them too
+	Layer        string
+	Recording    string // path of the recording; empty unless Record
+}
+
 // Stream represents an active WebRTC screen streaming session
 type Stream struct {
 	ID             string
 	PeerConnection *webrtc.PeerConnection
-	DataChannel    *webrtc.DataChannel
-	cancel         context.CancelFunc
+	DataChannel    *webrtc.DataChannel            // tiles and jpeg modes
+	VideoTrack     *webrtc.TrackLocalStaticSample // video mode
+	AudioTrack     *webrtc.TrackLocalStaticSample // nil unless audio was requested
+	mode           string
+	source         *feedSubscriber // this stream's share of the monitor's capture
+	abr            *BitrateController
+	encoderName    string
+	maxBitrateKbps int
 	targetFPS      int
 	jpegQuality    int
 	monitorIndex   int
+	input          *inputControl // nil unless control was requested
+	audioSource    string
+	audioConfig    AudioConfig
+	audioStats     *audioStats
+	trickle        *trickle // nil unless the stream uses trickle ICE
+	sessionID      string
+	viewer         string
+	layer          string
+	started        time.Time
+	videoCodec     string    // video mode only
+	recorder       *recorder // nil unless the stream is recorded
+
+	mu          sync.Mutex
+	cancel      context.CancelFunc
+	audioCancel context.CancelFunc
+	idleSince   time.Time // zero while the viewer is connected
 }
 
 // Manager manages multiple WebRTC streams
 type Manager struct {
-	streams map[string]*Stream
-	mu      sync.RWMutex
+	streams    map[string]*Stream
+	mu         sync.RWMutex
+	hub        *captureHub
+	maxViewers int
+	starting   int // streams being started, counted against maxViewers
+
+	sourceName   string
+	encoderName  string
+	injectorName string
+	indicator    ConsentIndicator
+	audioSource  string
+	audioConfig  AudioConfig
+	iceServers   []webrtc.ICEServer
+	recordDir    string
+	eventHook    func(StreamEvent)
 }
 
 // NewManager creates a new WebRTC stream manager
 func NewManager() *Manager {
 	return &Manager{
-		streams: make(map[string]*Stream),
+		streams:      make(map[string]*Stream),
+		hub:          newCaptureHub(),
+		sourceName:   SourceScreen,
+		encoderName:  DefaultVideoEncoder,
+		injectorName: InjectorNoop,
+		indicator:    DesktopIndicator{},
+		audioSource:  AudioSystem,
 	}
 }
 
+// SetFrameSource selects where new streams get frames from: SourceScreen,
+// or SourceSynthetic for headless machines and tests
+func (m *Manager) SetFrameSource(name string) error {
+	if _, ok := sourceFactories[name]; !ok {
+		return fmt.Errorf("unknown frame source %q", name)
+	}
+	m.mu.Lock()
+	m.sourceName = name
+	m.mu.Unlock()
+	return nil
+}
+
+// SetVideoEncoder selects the registered encoder new video streams use
+func (m *Manager) SetVideoEncoder(name string) error {
+	encodersMu.RLock()
+	_, ok := encoders[name]
+	encodersMu.RUnlock()
+	if !ok {
+		return fmt.Errorf("unknown video encoder %q (have %v)", name, Encoders())
+	}
+	m.mu.Lock()
+	m.encoderName = name
+	m.mu.Unlock()
+	return nil
+}
+
+// SetInputInjector selects the registered backend that controlled streams
+// inject input with
+func (m *Manager) SetInputInjector(name string) error {
+	injectorsMu.RLock()
+	_, ok := injectors[name]
+	injectorsMu.RUnlock()
+	if !ok {
+		return fmt.Errorf("unknown input injector %q (have %v)", name, Injectors())
+	}
+	m.mu.Lock()
+	m.injectorName = name
+	m.mu.Unlock()
+	return nil
+}
+
+// SetAudioSource selects the registered audio source streams use when the
+// viewer does not name one, and the device settings sources read
+func (m *Manager) SetAudioSource(name string, cfg AudioConfig) error {
+	audioSourcesMu.RLock()
+	_, ok := audioSources[name]
+	audioSourcesMu.RUnlock()
+	if !ok {
+		return fmt.Errorf("unknown audio source %q (have %v)", name, AudioSources())
+	}
+	m.mu.Lock()
+	m.audioSource, m.audioConfig = name, cfg
+	m.mu.Unlock()
+	return nil
+}
+
+// SetICEServers sets the STUN and TURN servers every new stream uses. With
+// none, only host candidates are gathered, which works within one LAN.
+func (m *Manager) SetICEServers(servers []webrtc.ICEServer) {
+	m.mu.Lock()
+	m.iceServers = servers
+	m.mu.Unlock()
+}
+
+// SetConsentIndicator replaces how the local user is told that a stream
+// is controlling the device
+func (m *Manager) SetConsentIndicator(ind ConsentIndicator) {
+	m.mu.Lock()
+	m.indicator = ind
+	m.mu.Unlock()
+}
+
 // Start creates a new WebRTC peer connection and returns an offer SDP
-func (m *Manager) Start(sessionID string, targetFPS, jpegQuality, monitorIndex int) (streamID, offerSDP string, err error) {
-	// Apply defaults
-	if targetFPS <= 0 {
-		targetFPS = 8
+func (m *Manager) Start(sessionID string, opts Options) (*Offer, error) {
+	mode, err := NegotiateMode(opts.Mode, opts.AcceptModes)
+	if err != nil {
+		return nil, err
+	}
+	opts.Mode = mode
+	layer, err := Layer(opts.Layer)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Layer == "" {
+		opts.Layer = LayerHigh
+	}
+
+	// Apply defaults: the layer's, then the mode's
+	if opts.TargetFPS <= 0 {
+		opts.TargetFPS = layer.FPS
+	}
+	if opts.TargetFPS <= 0 {
+		opts.TargetFPS = DefaultVideoFPS
+		if opts.Mode != ModeVideo {
+			opts.TargetFPS = 8
+		}
+	}
+	if opts.JPEGQuality <= 0 {
+		opts.JPEGQuality = layer.JPEGQuality
+	}
+	if opts.JPEGQuality <= 0 {
+		opts.JPEGQuality = 60
 	}
-	if jpegQuality <= 0 {
-		jpegQuality = 60
+	if opts.MaxBitrateKbps <= 0 {
+		opts.MaxBitrateKbps = layer.MaxBitrateKbps
 	}
+	if opts.MaxBitrateKbps <= 0 {
+		opts.MaxBitrateKbps = DefaultMaxBitrateKbps
+	}
+	width := layer.MaxWidth
+	if opts.Mode == ModeVideo && (width <= 0 || width > maxVideoWidth) {
+		width = maxVideoWidth
+	}
+	if opts.MaxBitrateKbps < minBitrateKbps {
+		opts.MaxBitrateKbps = minBitrateKbps
+	}
+
+	m.mu.RLock()
+	sourceName, encoderName := m.sourceName, m.encoderName
+	injectorName, indicator := m.injectorName, m.indicator
+	audioSource, audioConfig := m.audioSource, m.audioConfig
+	iceServers := append(append([]webrtc.ICEServer(nil), m.iceServers...), opts.ICEServers...)
+	recordDir := m.recordDir
+	m.mu.RUnlock()
 
-	// Validate monitor index
-	numDisplays := screenshot.NumActiveDisplays()
-	if monitorIndex < 0 || monitorIndex >= numDisplays {
-		return "", "", fmt.Errorf("invalid monitor_index %d, have %d displays", monitorIndex, numDisplays)
+	if opts.Record && recordDir == "" {
+		return nil, ErrRecordingDisabled
 	}
 
-	// Create peer connection with no ICE servers (LAN only)
-	config := webrtc.Configuration{
-		ICEServers: []webrtc.ICEServer{}, // Empty for LAN-only
+	if opts.Audio {
+		if opts.AudioSource != "" {
+			audioSource = opts.AudioSource
+		}
+		audioSourcesMu.RLock()
+		_, ok := audioSources[audioSource]
+		audioSourcesMu.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("unknown audio source %q (have %v)", audioSource, AudioSources())
+		}
 	}
 
-	pc, err := webrtc.NewPeerConnection(config)
+	release, err := m.reserveViewer()
 	if err != nil {
-		return "", "", fmt.Errorf("failed to create peer connection: %w", err)
+		return nil, err
 	}
+	defer release()
 
-	// Create data channel for frames
-	dc, err := pc.CreateDataChannel("frames", nil)
+	// Viewers of the same monitor share its capture
+	source, err := m.hub.subscribe(sourceName, opts.MonitorIndex, opts.TargetFPS, width)
 	if err != nil {
-		pc.Close()
-		return "", "", fmt.Errorf("failed to create data channel: %w", err)
+		return nil, err
 	}
+	stored := false
+	defer func() {
+		if !stored {
+			source.close()
+		}
+	}()
 
-	streamID = uuid.New().String()
+	config := webrtc.Configuration{ICEServers: iceServers}
+	if opts.RelayOnly {
+		config.ICETransportPolicy = webrtc.ICETransportPolicyRelay
+	}
+
+	pc, err := webrtc.NewPeerConnection(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create peer connection: %w", err)
+	}
 
+	streamID := uuid.New().String()
+
+	maxBps := opts.MaxBitrateKbps * 1000
 	stream := &Stream{
 		ID:             streamID,
 		PeerConnection: pc,
-		DataChannel:    dc,
-		targetFPS:      targetFPS,
-		jpegQuality:    jpegQuality,
-		monitorIndex:   monitorIndex,
+		mode:           opts.Mode,
+		source:         source,
+		abr:            NewBitrateController(maxBps/2, minBitrateKbps*1000, maxBps),
+		encoderName:    encoderName,
+		maxBitrateKbps: opts.MaxBitrateKbps,
+		targetFPS:      opts.TargetFPS,
+		jpegQuality:    opts.JPEGQuality,
+		monitorIndex:   opts.MonitorIndex,
+		audioSource:    audioSource,
+		audioConfig:    audioConfig,
+		sessionID:      sessionID,
+		viewer:         opts.Viewer,
+		layer:          opts.Layer,
+		started:        time.Now(),
+		idleSince:      time.Now(), // until the viewer connects
+	}
+	if opts.Trickle {
+		stream.trickle = newTrickle()
+		pc.OnICECandidate(stream.trickle.gathered)
 	}
 
-	// Set up data channel open handler to start capture
-	dc.OnOpen(func() {
-		log.Printf("[INFO] WebRTC stream %s: data channel opened, starting capture", streamID)
-		ctx, cancel := context.WithCancel(context.Background())
-		stream.cancel = cancel
-		go stream.captureLoop(ctx)
-	})
+	pc.OnConnectionStateChange(stream.peerStateChanged)
 
-	dc.OnClose(func() {
-		log.Printf("[INFO] WebRTC stream %s: data channel closed", streamID)
-		if stream.cancel != nil {
-			stream.cancel()
+	if opts.Mode == ModeVideo {
+		if err := m.setupVideo(stream, pc); err != nil {
+			pc.Close()
+			return nil, err
 		}
-	})
+	} else if err := m.setupDataChannel(stream, pc); err != nil {
+		pc.Close()
+		return nil, err
+	}
+
+	if opts.InputControl {
+		if err := stream.setupInput(pc, opts, injectorName, indicator); err != nil {
+			pc.Close()
+			return nil, fmt.Errorf("failed to create input channel: %w", err)
+		}
+	}
+
+	if opts.Audio {
+		if err := stream.addAudioTrack(pc); err != nil {
+			pc.Close()
+			return nil, err
+		}
+	}
 
 	// Create offer
 	offer, err := pc.CreateOffer(nil)
 	if err != nil {
 		pc.Close()
-		return "", "", fmt.Errorf("failed to create offer: %w", err)
+		return nil, fmt.Errorf("failed to create offer: %w", err)
 	}
 
 	// Set local description
 	if err := pc.SetLocalDescription(offer); err != nil {
 		pc.Close()
-		return "", "", fmt.Errorf("failed to set local description: %w", err)
+		return nil, fmt.Errorf("failed to set local description: %w", err)
 	}
 
-	// Wait for ICE gathering to complete (non-trickle)
-	gatherComplete := webrtc.GatheringCompletePromise(pc)
-	select {
-	case <-gatherComplete:
-		// ICE gathering complete
-		log.Printf("[INFO] WebRTC stream %s: ICE gathering complete", streamID)
-	case <-time.After(5 * time.Second):
-		pc.Close()
-		return "", "", fmt.Errorf("ICE gathering timeout")
+	// Without trickle ICE, the offer has to carry every candidate
+	if !opts.Trickle {
+		gatherComplete := webrtc.GatheringCompletePromise(pc)
+		select {
+		case <-gatherComplete:
+			log.Printf("[INFO] WebRTC stream %s: ICE gathering complete", streamID)
+		case <-time.After(5 * time.Second):
+			pc.Close()
+			return nil, fmt.Errorf("ICE gathering timeout")
+		}
 	}
 
-	// Get the complete SDP with candidates
-	offerSDP = pc.LocalDescription().SDP
+	if opts.Record {
+		rec, err := newRecorder(recordDir, stream)
+		if err != nil {
+			pc.Close()
+			return nil, err
+		}
+		stream.recorder = rec
+		log.Printf("[INFO] WebRTC stream %s: recording to %s", streamID, rec.path)
+	}
 
 	// Store stream
 	m.mu.Lock()
 	m.streams[streamID] = stream
 	m.mu.Unlock()
+	stored = true
+	m.emit(StreamEvent{Type: EventStart, Stream: stream.info()})
+
+	var recording string
+	if stream.recorder != nil {
+		recording = stream.recorder.path
+	}
+	return &Offer{
+		StreamID:     streamID,
+		SDP:          pc.LocalDescription().SDP, // complete SDP with candidates
+		Mode:         opts.Mode,
+		InputControl: opts.InputControl,
+		Audio:        opts.Audio,
+		Trickle:      opts.Trickle,
+		ICEServers:   iceServers,
+		Layer:        opts.Layer,
+		Recording:    recording,
+	}, nil
+}
+
+// setupVideo adds the video track; capture runs while the peer is connected
+// (see peerStateChanged)
+func (m *Manager) setupVideo(stream *Stream, pc *webrtc.PeerConnection) error {
+	// Create a throwaway encoder up front to learn its codec and fail
+	// early on a bad encoder name
+	probe, err := NewVideoEncoder(stream.encoderName, 16, 16, stream.targetFPS)
+	if err != nil {
+		return err
+	}
+	mimeType := probe.MimeType()
+	probe.Close()
+	stream.videoCodec = mimeType
+
+	return stream.addVideoTrack(pc, mimeType)
+}
+
+// peerStateChanged runs the video and audio loops while the peer is
+// connected. Data channel modes start capture when their channel opens.
+func (s *Stream) peerStateChanged(state webrtc.PeerConnectionState) {
+	s.markActive(state == webrtc.PeerConnectionStateConnected)
+	switch state {
+	case webrtc.PeerConnectionStateConnected:
+		if s.mode == ModeVideo {
+			log.Printf("[INFO] WebRTC stream %s: peer connected, starting video", s.ID)
+			s.startCapture(s.videoLoop)
+		}
+		if s.AudioTrack != nil {
+			s.startAudio()
+		}
+	case webrtc.PeerConnectionStateDisconnected, webrtc.PeerConnectionStateFailed, webrtc.PeerConnectionStateClosed:
+		log.Printf("[INFO] WebRTC stream %s: peer %s", s.ID, state)
+		s.stopCapture()
+		s.stopAudio()
+	}
+}
+
+// setupDataChannel creates the frames data channel; capture runs while it
+// is open
+func (m *Manager) setupDataChannel(stream *Stream, pc *webrtc.PeerConnection) error {
+	// Create data channel for frames
+	dc, err := pc.CreateDataChannel("frames", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create data channel: %w", err)
+	}
+	stream.DataChannel = dc
+
+	// Set up data channel open handler to start capture
+	dc.OnOpen(func() {
+		log.Printf("[INFO] WebRTC stream %s: data channel opened, starting %s capture", stream.ID, stream.mode)
+		if stream.mode == ModeTiles {
+			stream.startCapture(stream.tileLoop)
+		} else {
+			stream.startCapture(stream.captureLoop)
+		}
+	})
+
+	dc.OnClose(func() {
+		log.Printf("[INFO] WebRTC stream %s: data channel closed", stream.ID)
+		stream.stopCapture()
+	})
+	return nil
+}
+
+// startCapture runs loop until stopCapture; a running loop is left alone
+func (s *Stream) startCapture(loop func(ctx context.Context)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	go loop(ctx)
+}
+
+// stopCapture stops the capture loop, if running
+func (s *Stream) stopCapture() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+}
+
+// startAudio runs audioLoop until stopAudio; a running loop is left alone
+func (s *Stream) startAudio() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.audioCancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.audioCancel = cancel
+	go s.audioLoop(ctx)
+}
+
+// stopAudio stops the audio loop, if running
+func (s *Stream) stopAudio() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.audioCancel != nil {
+		s.audioCancel()
+		s.audioCancel = nil
+	}
+}
 
-	return streamID, offerSDP, nil
+// Mode returns the mode the stream was started in
+func (s *Stream) Mode() string {
+	return s.mode
 }
 
 // Complete sets the remote description (answer) for a stream
@@ -155,6 +587,11 @@ func (m *Manager) Complete(streamID, answerSDP string) error {
 	if err := stream.PeerConnection.SetRemoteDescription(answer); err != nil {
 		return fmt.Errorf("failed to set remote description: %w", err)
 	}
+	if stream.trickle != nil {
+		if err := stream.applyRemoteCandidates(); err != nil {
+			return fmt.Errorf("failed to add ICE candidate: %w", err)
+		}
+	}
 
 	log.Printf("[INFO] WebRTC stream %s: remote description set, connection established", streamID)
 	return nil
@@ -162,6 +599,12 @@ func (m *Manager) Complete(streamID, answerSDP string) error {
 
 // Stop closes a stream and cleans up resources
 func (m *Manager) Stop(streamID string) error {
+	return m.stop(streamID, StopRequested)
+}
+
+// stop closes a stream, finishing its recording before reporting why it
+// stopped
+func (m *Manager) stop(streamID, reason string) error {
 	m.mu.Lock()
 	stream, ok := m.streams[streamID]
 	if ok {
@@ -173,12 +616,22 @@ func (m *Manager) Stop(streamID string) error {
 		return fmt.Errorf("stream not found: %s", streamID)
 	}
 
-	if stream.cancel != nil {
-		stream.cancel()
+	stream.stopCapture()
+	stream.stopAudio()
+	if stream.input != nil {
+		stream.input.stop()
+	}
+	stream.source.close()
+	if stream.recorder != nil {
+		if err := stream.recorder.close(); err != nil {
+			log.Printf("[WARN] WebRTC stream %s: finishing recording: %v", streamID, err)
+		}
 	}
 
 	log.Printf("[INFO] WebRTC stream %s: stopped", streamID)
-	return stream.PeerConnection.Close()
+	err := stream.PeerConnection.Close()
+	m.emit(StreamEvent{Type: EventStop, Reason: reason, Stream: stream.info()})
+	return err
 }
 
 // captureLoop captures screen frames and sends them over the data channel
@@ -217,10 +670,9 @@ func scaleImage(src image.Image, factor float64) image.Image {
 // captureAndSend captures a single frame and sends it
 func (s *Stream) captureAndSend() error {
 	// Capture screen
-	bounds := screenshot.GetDisplayBounds(s.monitorIndex)
-	img, err := screenshot.CaptureRect(bounds)
+	img, err := s.source.Capture()
 	if err != nil {
-		return fmt.Errorf("capture failed: %w", err)
+		return err
 	}
 
 	// Scale down to fit within SCTP message limit.
@@ -237,7 +689,11 @@ func (s *Stream) captureAndSend() error {
 		}
 
 		if buf.Len() <= maxMessageSize {
-			return s.DataChannel.Send(buf.Bytes())
+			if err := s.DataChannel.Send(buf.Bytes()); err != nil {
+				return err
+			}
+			s.record(func(r *recorder) error { return r.writeJPEG(img, s.jpegQuality, time.Now()) })
+			return nil
 		}
 
 		// Still too large — reduce scale and quality
@@ -0,0 +1,236 @@
+package webrtcstream
+
+import (
+	"bytes"
+	"image"
+	"testing"
+	"time"
+
+	"github.com/pion/rtp/codecs"
+	"github.com/pion/webrtc/v3"
+	"github.com/pion/webrtc/v3/pkg/media/samplebuilder"
+	"golang.org/x/image/vp8"
+)
+
+// connect answers an offer with a receive-only peer. setup installs the
+// peer's handlers before negotiation.
+func connect(t *testing.T, m *Manager, offer *Offer, setup func(pc *webrtc.PeerConnection)) {
+	t.Helper()
+	pc, err := webrtc.NewPeerConnection(webrtc.Configuration{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pc.Close() })
+	setup(pc)
+	if err := pc.SetRemoteDescription(webrtc.SessionDescription{Type: webrtc.SDPTypeOffer, SDP: offer.SDP}); err != nil {
+		t.Fatal(err)
+	}
+	ans, err := pc.CreateAnswer(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gathered := webrtc.GatheringCompletePromise(pc)
+	if err := pc.SetLocalDescription(ans); err != nil {
+		t.Fatal(err)
+	}
+	<-gathered
+	if err := m.Complete(offer.StreamID, pc.LocalDescription().SDP); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStartVideoStreamSynthetic(t *testing.T) {
+	m := NewManager()
+	if err := m.SetFrameSource(SourceSynthetic); err != nil {
+		t.Fatal(err)
+	}
+	offer, err := m.Start("test", Options{TargetFPS: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop(offer.StreamID)
+	if offer.Mode != ModeVideo {
+		t.Fatalf("mode = %q, want %q", offer.Mode, ModeVideo)
+	}
+
+	frames := make(chan []byte, 1)
+	connect(t, m, offer, func(pc *webrtc.PeerConnection) {
+		pc.OnTrack(func(track *webrtc.TrackRemote, _ *webrtc.RTPReceiver) {
+			if track.Codec().MimeType != webrtc.MimeTypeVP8 {
+				t.Errorf("track codec %s", track.Codec().MimeType)
+				return
+			}
+			sb := samplebuilder.New(64, &codecs.VP8Packet{}, track.Codec().ClockRate)
+			for {
+				pkt, _, err := track.ReadRTP()
+				if err != nil {
+					return
+				}
+				sb.Push(pkt)
+				if s := sb.Pop(); s != nil {
+					select {
+					case frames <- s.Data:
+					default:
+					}
+					return
+				}
+			}
+		})
+	})
+
+	select {
+	case data := <-frames:
+		d := vp8.NewDecoder()
+		d.Init(bytes.NewReader(data), len(data))
+		fh, err := d.DecodeFrameHeader()
+		if err != nil {
+			t.Fatalf("received frame header: %v", err)
+		}
+		if !fh.KeyFrame || fh.Width != 1280 || fh.Height != 720 {
+			t.Fatalf("frame header %+v, want 1280x720 key frame", fh)
+		}
+		if _, err := d.DecodeFrame(); err != nil {
+			t.Fatalf("decode received frame: %v", err)
+		}
+	case <-time.After(15 * time.Second):
+		t.Skip("no video frame received; peers could not connect in this environment")
+	}
+}
+
+func TestStartJPEGStreamSynthetic(t *testing.T) {
+	m := NewManager()
+	if err := m.SetFrameSource(SourceSynthetic); err != nil {
+		t.Fatal(err)
+	}
+	offer, err := m.Start("test", Options{Mode: ModeJPEG})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop(offer.StreamID)
+
+	frames := make(chan []byte, 1)
+	connect(t, m, offer, func(pc *webrtc.PeerConnection) {
+		pc.OnDataChannel(func(dc *webrtc.DataChannel) {
+			dc.OnMessage(func(msg webrtc.DataChannelMessage) {
+				select {
+				case frames <- msg.Data:
+				default:
+				}
+			})
+		})
+	})
+
+	select {
+	case data := <-frames:
+		if len(data) < 2 || data[0] != 0xff || data[1] != 0xd8 {
+			t.Fatalf("frame is not a JPEG")
+		}
+	case <-time.After(15 * time.Second):
+		t.Skip("no frame received; peers could not connect in this environment")
+	}
+}
+
+func TestStartTilesStreamSynthetic(t *testing.T) {
+	m := NewManager()
+	if err := m.SetFrameSource(SourceSynthetic); err != nil {
+		t.Fatal(err)
+	}
+	offer, err := m.Start("test", Options{AcceptModes: []string{ModeJPEG, ModeTiles}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop(offer.StreamID)
+	if offer.Mode != ModeTiles {
+		t.Fatalf("mode = %q, want %q", offer.Mode, ModeTiles)
+	}
+
+	frames := make(chan []byte, 1)
+	connect(t, m, offer, func(pc *webrtc.PeerConnection) {
+		pc.OnDataChannel(func(dc *webrtc.DataChannel) {
+			r := NewReassembler()
+			dc.OnMessage(func(msg webrtc.DataChannelMessage) {
+				h, payload, err := r.Add(msg.Data)
+				if err != nil {
+					t.Errorf("chunk: %v", err)
+					return
+				}
+				if payload != nil && h.Flags&FlagKeyframe != 0 {
+					select {
+					case frames <- payload:
+					default:
+					}
+				}
+			})
+		})
+	})
+
+	select {
+	case payload := <-frames:
+		canvas, err := ApplyTileFrame(nil, payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := canvas.Bounds().Size(); got != image.Pt(1280, 720) {
+			t.Fatalf("keyframe size %v, want full resolution 1280x720", got)
+		}
+	case <-time.After(15 * time.Second):
+		t.Skip("no frame received; peers could not connect in this environment")
+	}
+}
+
+func TestNegotiateMode(t *testing.T) {
+	for _, tc := range []struct {
+		mode   string
+		accept []string
+		want   string
+	}{
+		{"", nil, ModeVideo},
+		{"", []string{ModeJPEG, ModeVideo, ModeTiles}, ModeVideo},
+		{"", []string{ModeJPEG, ModeTiles}, ModeTiles},
+		{"", []string{"mjpeg", ModeJPEG}, ModeJPEG},
+		{ModeJPEG, []string{ModeVideo}, ModeJPEG},
+	} {
+		got, err := NegotiateMode(tc.mode, tc.accept)
+		if err != nil || got != tc.want {
+			t.Errorf("NegotiateMode(%q, %v) = %q, %v; want %q", tc.mode, tc.accept, got, err, tc.want)
+		}
+	}
+	if _, err := NegotiateMode("", []string{"mjpeg"}); err == nil {
+		t.Error("expected error when no mode is shared")
+	}
+}
+
+func TestStartRejectsUnknownMode(t *testing.T) {
+	m := NewManager()
+	m.SetFrameSource(SourceSynthetic)
+	if _, err := m.Start("test", Options{Mode: "hologram"}); err == nil {
+		t.Fatal("expected error for unknown mode")
+	}
+	if err := m.SetVideoEncoder("h265-imaginary"); err == nil {
+		t.Fatal("expected error for unknown encoder")
+	}
+}
+
+func TestSyntheticSourceFramesDiffer(t *testing.T) {
+	src := NewSyntheticSource(320, 180)
+	a, _ := src.Capture()
+	b, _ := src.Capture()
+	if a.Bounds() != src.Bounds() {
+		t.Fatalf("bounds %v, want %v", a.Bounds(), src.Bounds())
+	}
+	if bytes.Equal(fitWidth(a, 1280).Pix, fitWidth(b, 1280).Pix) {
+		t.Fatal("consecutive synthetic frames are identical")
+	}
+	if got := fitWidth(capture(t, NewSyntheticSource(1921, 1081)), 1280).Rect.Size(); got.X != 1280 || got.Y != 720 {
+		t.Fatalf("scaled size %v, want 1280x720", got)
+	}
+}
+
+func capture(t *testing.T, s FrameSource) image.Image {
+	t.Helper()
+	img, err := s.Capture()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
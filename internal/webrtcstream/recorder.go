@@ -0,0 +1,313 @@
+package webrtcstream
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pion/webrtc/v3"
+)
+
+// Recording formats
+const (
+	// FormatIVF holds the video track's encoded frames as sent, in an IVF
+	// container with a millisecond timebase
+	FormatIVF = "ivf"
+	// FormatMJPEG holds full-resolution JPEG frames back to back; ffmpeg
+	// and VLC play it as MJPEG, and the manifest gives each frame's offset
+	FormatMJPEG = "mjpeg"
+)
+
+// ivfHeaderSize and ivfFrameHeaderSize are fixed by the IVF format
+const (
+	ivfHeaderSize      = 32
+	ivfFrameHeaderSize = 12
+)
+
+// ivfFourCC maps the video codecs IVF can hold to their FourCC
+var ivfFourCC = map[string]string{
+	webrtc.MimeTypeVP8:  "VP80",
+	webrtc.MimeTypeVP9:  "VP90",
+	webrtc.MimeTypeAV1:  "AV01",
+	webrtc.MimeTypeH264: "H264",
+}
+
+// ManifestEntry is one line of a recording's manifest. The first line has
+// Type "start", each frame adds a "frame" line and a finished recording
+// ends with an "end" line; a manifest without one was cut short. Fields
+// left out are zero.
+type ManifestEntry struct {
+	Type string `json:"type"`
+
+	// start
+	StreamID     string `json:"stream_id,omitempty"`
+	SessionID    string `json:"session_id,omitempty"`
+	Viewer       string `json:"viewer,omitempty"`
+	Mode         string `json:"mode,omitempty"`
+	Format       string `json:"format,omitempty"`
+	Codec        string `json:"codec,omitempty"`
+	MonitorIndex int    `json:"monitor_index,omitempty"`
+	TargetFPS    int    `json:"target_fps,omitempty"`
+	Started      string `json:"started,omitempty"` // RFC 3339
+
+	// frame
+	Frame    int   `json:"n,omitempty"`
+	TimeMs   int64 `json:"t_ms,omitempty"`   // since start
+	Offset   int64 `json:"offset,omitempty"` // in the recording; IVF frames start with their 12-byte header
+	Size     int   `json:"size,omitempty"`
+	Keyframe bool  `json:"key,omitempty"`
+	Width    int   `json:"width,omitempty"`
+	Height   int   `json:"height,omitempty"`
+
+	// end
+	Frames     int    `json:"frames,omitempty"`
+	DurationMs int64  `json:"duration_ms,omitempty"`
+	Bytes      int64  `json:"bytes,omitempty"`
+	Ended      string `json:"ended,omitempty"` // RFC 3339
+}
+
+// ErrRecordingDisabled is returned by Start when a recording is asked for
+// but SetRecordingDir was not called
+var ErrRecordingDisabled = errors.New("recording is not enabled on this device")
+
+// SetRecordingDir sets where recorded streams are written
+func (m *Manager) SetRecordingDir(dir string) {
+	m.mu.Lock()
+	m.recordDir = dir
+	m.mu.Unlock()
+}
+
+// record runs write against the stream's recorder, if any. A failed write
+// is logged and ends the recording; the stream itself carries on.
+func (s *Stream) record(write func(r *recorder) error) {
+	if s.recorder == nil {
+		return
+	}
+	if err := write(s.recorder); err != nil {
+		log.Printf("[WARN] WebRTC stream %s: recording to %s failed, stopping it: %v", s.ID, s.recorder.path, err)
+		s.recorder.close()
+	}
+}
+
+// ManifestPath returns the manifest written next to a recording
+func ManifestPath(recording string) string {
+	return strings.TrimSuffix(recording, filepath.Ext(recording)) + ".manifest.jsonl"
+}
+
+// recorder writes a stream's frames to disk as the capture loops produce
+// them. Writes go straight to the files so a download can follow the
+// recording while it grows.
+type recorder struct {
+	path    string
+	format  string
+	codec   string
+	started time.Time
+
+	mu       sync.Mutex
+	media    *os.File
+	manifest *os.File
+	offset   int64
+	frames   int
+	size     image.Point // IVF header dimensions, set by the first frame
+	closed   bool
+}
+
+// newRecorder creates the recording and manifest for s under dir. Video
+// streams need their track's codec to be set.
+func newRecorder(dir string, s *Stream) (*recorder, error) {
+	format, codec := FormatMJPEG, "image/jpeg"
+	if s.mode == ModeVideo {
+		format, codec = FormatIVF, s.videoCodec
+		if _, ok := ivfFourCC[codec]; !ok {
+			return nil, fmt.Errorf("cannot record %s video", codec)
+		}
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create recording dir: %w", err)
+	}
+	now := time.Now()
+	base := fmt.Sprintf("%s-%s", now.Format("20060102-150405"), s.ID[:8])
+	path := filepath.Join(dir, base+"."+format)
+
+	media, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("create recording: %w", err)
+	}
+	manifest, err := os.OpenFile(ManifestPath(path), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		media.Close()
+		os.Remove(path)
+		return nil, fmt.Errorf("create recording manifest: %w", err)
+	}
+	r := &recorder{
+		path:     path,
+		format:   format,
+		codec:    codec,
+		started:  now,
+		media:    media,
+		manifest: manifest,
+	}
+	if err := r.writeManifest(ManifestEntry{
+		Type:         "start",
+		StreamID:     s.ID,
+		SessionID:    s.sessionID,
+		Viewer:       s.viewer,
+		Mode:         s.mode,
+		Format:       format,
+		Codec:        codec,
+		MonitorIndex: s.monitorIndex,
+		TargetFPS:    s.targetFPS,
+		Started:      now.Format(time.RFC3339Nano),
+	}); err != nil {
+		r.close()
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *recorder) writeManifest(e ManifestEntry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = r.manifest.Write(append(line, '\n'))
+	return err
+}
+
+// writeVideo appends an encoded video frame; key is what the encoder was
+// asked for. The IVF header takes the first frame's size; keyframes carry
+// their own, so a later resize still decodes.
+func (r *recorder) writeVideo(data []byte, key bool, width, height int, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	if r.offset == 0 {
+		r.size = image.Pt(width, height)
+		if err := r.write(ivfHeader(r.codec, width, height, 0)); err != nil {
+			return err
+		}
+	}
+	ts := at.Sub(r.started).Milliseconds()
+	hdr := make([]byte, ivfFrameHeaderSize)
+	binary.LittleEndian.PutUint32(hdr[0:], uint32(len(data)))
+	binary.LittleEndian.PutUint64(hdr[4:], uint64(ts))
+	offset := r.offset
+	if err := r.write(hdr); err != nil {
+		return err
+	}
+	if err := r.write(data); err != nil {
+		return err
+	}
+	if r.codec == webrtc.MimeTypeVP8 {
+		key = vp8Keyframe(data)
+	}
+	return r.frame(ts, offset, len(data), key, width, height)
+}
+
+// writeJPEG appends img as a JPEG frame
+func (r *recorder) writeJPEG(img image.Image, quality int, at time.Time) error {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return fmt.Errorf("jpeg encode failed: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	ts := at.Sub(r.started).Milliseconds()
+	offset := r.offset
+	if err := r.write(buf.Bytes()); err != nil {
+		return err
+	}
+	b := img.Bounds()
+	return r.frame(ts, offset, buf.Len(), true, b.Dx(), b.Dy())
+}
+
+func (r *recorder) write(p []byte) error {
+	n, err := r.media.Write(p)
+	r.offset += int64(n)
+	return err
+}
+
+func (r *recorder) frame(ts, offset int64, size int, key bool, width, height int) error {
+	r.frames++
+	return r.writeManifest(ManifestEntry{
+		Type:     "frame",
+		Frame:    r.frames,
+		TimeMs:   ts,
+		Offset:   offset,
+		Size:     size,
+		Keyframe: key,
+		Width:    width,
+		Height:   height,
+	})
+}
+
+// close finishes the recording: the IVF header gets the frame count and
+// the manifest its end line. Later writes are dropped.
+func (r *recorder) close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+
+	var firstErr error
+	keep := func(err error) {
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if r.format == FormatIVF && r.frames > 0 {
+		_, err := r.media.WriteAt(ivfHeader(r.codec, r.size.X, r.size.Y, r.frames), 0)
+		keep(err)
+	}
+	now := time.Now()
+	keep(r.writeManifest(ManifestEntry{
+		Type:       "end",
+		Frames:     r.frames,
+		DurationMs: now.Sub(r.started).Milliseconds(),
+		Bytes:      r.offset,
+		Ended:      now.Format(time.RFC3339Nano),
+	}))
+	keep(r.media.Close())
+	keep(r.manifest.Close())
+	return firstErr
+}
+
+// ivfHeader is the IVF file header for codec frames with a millisecond
+// timebase
+func ivfHeader(codec string, width, height, frames int) []byte {
+	h := make([]byte, ivfHeaderSize)
+	copy(h[0:], "DKIF")
+	binary.LittleEndian.PutUint16(h[4:], 0) // version
+	binary.LittleEndian.PutUint16(h[6:], ivfHeaderSize)
+	copy(h[8:], ivfFourCC[codec])
+	binary.LittleEndian.PutUint16(h[12:], uint16(width))
+	binary.LittleEndian.PutUint16(h[14:], uint16(height))
+	binary.LittleEndian.PutUint32(h[16:], 1000) // timebase denominator
+	binary.LittleEndian.PutUint32(h[20:], 1)    // timebase numerator
+	binary.LittleEndian.PutUint32(h[24:], uint32(frames))
+	return h
+}
+
+// vp8Keyframe reports whether a VP8 frame is a keyframe: bit 0 of its
+// frame tag is clear
+func vp8Keyframe(frame []byte) bool {
+	return len(frame) > 0 && frame[0]&0x01 == 0
+}
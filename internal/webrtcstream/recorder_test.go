@@ -0,0 +1,200 @@
+package webrtcstream
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pion/webrtc/v3"
+	"github.com/pion/webrtc/v3/pkg/media/ivfreader"
+)
+
+func readManifest(t *testing.T, path string) []ManifestEntry {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var entries []ManifestEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e ManifestEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("manifest line %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// recordStream starts a recorded stream in mode, watches it until the
+// manifest lists frames, stops it and returns the recording path and
+// manifest
+func recordStream(t *testing.T, mode string) (string, []ManifestEntry, []StreamEvent) {
+	t.Helper()
+	m := NewManager()
+	m.SetFrameSource(SourceSynthetic)
+	m.SetRecordingDir(filepath.Join(t.TempDir(), "recordings"))
+	var (
+		mu     sync.Mutex
+		events []StreamEvent
+	)
+	m.SetEventHook(func(ev StreamEvent) {
+		mu.Lock()
+		events = append(events, ev)
+		mu.Unlock()
+	})
+
+	offer, err := m.Start("test", Options{Mode: mode, TargetFPS: 10, Record: true, Viewer: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offer.Recording == "" {
+		m.Stop(offer.StreamID)
+		t.Fatal("recorded stream has no recording path")
+	}
+	connect(t, m, offer, func(pc *webrtc.PeerConnection) {
+		pc.OnTrack(func(track *webrtc.TrackRemote, _ *webrtc.RTPReceiver) {
+			for {
+				if _, _, err := track.ReadRTP(); err != nil {
+					return
+				}
+			}
+		})
+		pc.OnDataChannel(func(dc *webrtc.DataChannel) { dc.OnMessage(func(webrtc.DataChannelMessage) {}) })
+	})
+
+	deadline := time.Now().Add(15 * time.Second)
+	for len(readManifest(t, ManifestPath(offer.Recording))) < 4 {
+		if time.Now().After(deadline) {
+			m.Stop(offer.StreamID)
+			t.Skip("nothing recorded; peers could not connect in this environment")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if err := m.Stop(offer.StreamID); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	return offer.Recording, readManifest(t, ManifestPath(offer.Recording)), events
+}
+
+// checkManifest verifies the start and end lines and returns the frames
+func checkManifest(t *testing.T, entries []ManifestEntry, format string) []ManifestEntry {
+	t.Helper()
+	start, end := entries[0], entries[len(entries)-1]
+	if start.Type != "start" || start.Format != format || start.Viewer != "alice" || start.Started == "" {
+		t.Fatalf("start line %+v", start)
+	}
+	frames := entries[1 : len(entries)-1]
+	if end.Type != "end" || end.Frames != len(frames) || end.Ended == "" {
+		t.Fatalf("end line %+v after %d frames", end, len(frames))
+	}
+	var last int64 = -1
+	for i, f := range frames {
+		if f.Type != "frame" || f.Frame != i+1 || f.TimeMs < last || f.Size == 0 {
+			t.Fatalf("frame line %+v", f)
+		}
+		last = f.TimeMs
+	}
+	return frames
+}
+
+func TestRecordVideoStream(t *testing.T) {
+	path, entries, events := recordStream(t, ModeVideo)
+	if filepath.Ext(path) != ".ivf" {
+		t.Fatalf("video recording %s", path)
+	}
+	frames := checkManifest(t, entries, FormatIVF)
+	if !frames[0].Keyframe || frames[0].Offset != ivfHeaderSize {
+		t.Fatalf("first frame %+v, want a keyframe right after the header", frames[0])
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r, hdr, err := ivfreader.NewWith(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hdr.FourCC != "VP80" || hdr.Width != 1280 || hdr.Height != 720 || int(hdr.NumFrames) != len(frames) {
+		t.Fatalf("IVF header %+v, want %d 1280x720 VP8 frames", hdr, len(frames))
+	}
+	for i, want := range frames {
+		data, fh, err := r.ParseNextFrame()
+		if err != nil {
+			t.Fatalf("frame %d: %v", i+1, err)
+		}
+		if len(data) != want.Size || int64(fh.Timestamp) != want.TimeMs {
+			t.Fatalf("frame %d: %d bytes at %dms, manifest says %+v", i+1, len(data), fh.Timestamp, want)
+		}
+	}
+
+	if len(events) != 2 || events[0].Type != EventStart || events[1].Type != EventStop ||
+		events[1].Reason != StopRequested || events[1].Stream.Recording != path {
+		t.Fatalf("events %+v", events)
+	}
+}
+
+func TestRecordTilesStream(t *testing.T) {
+	path, entries, _ := recordStream(t, ModeTiles)
+	if filepath.Ext(path) != ".mjpeg" {
+		t.Fatalf("tiles recording %s", path)
+	}
+	frames := checkManifest(t, entries, FormatMJPEG)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if end := entries[len(entries)-1]; end.Bytes != int64(len(data)) {
+		t.Fatalf("manifest says %d bytes, file has %d", end.Bytes, len(data))
+	}
+	for _, f := range frames {
+		img, err := jpeg.Decode(bytes.NewReader(data[f.Offset : f.Offset+int64(f.Size)]))
+		if err != nil {
+			t.Fatalf("frame %d: %v", f.Frame, err)
+		}
+		if img.Bounds().Size() != image.Pt(1280, 720) || f.Width != 1280 {
+			t.Fatalf("frame %d is %v, want full resolution", f.Frame, img.Bounds())
+		}
+	}
+}
+
+func TestRecordingNeedsDir(t *testing.T) {
+	m := NewManager()
+	m.SetFrameSource(SourceSynthetic)
+	if _, err := m.Start("test", Options{Mode: ModeTiles, Record: true}); !errors.Is(err, ErrRecordingDisabled) {
+		t.Fatalf("err = %v, want ErrRecordingDisabled", err)
+	}
+	if feeds := m.Feeds(); len(feeds) != 0 {
+		t.Fatalf("feeds %+v left open by a refused stream", feeds)
+	}
+}
+
+func TestReapedStreamReportsIdle(t *testing.T) {
+	m := NewManager()
+	m.SetFrameSource(SourceSynthetic)
+	var events []StreamEvent
+	m.SetEventHook(func(ev StreamEvent) { events = append(events, ev) })
+	offer, err := m.Start("test", Options{Mode: ModeTiles})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.reapIdle(time.Now().Add(time.Minute), time.Minute)
+	if len(events) != 2 || events[1].Type != EventStop || events[1].Reason != StopIdle || events[1].Stream.ID != offer.StreamID {
+		t.Fatalf("events %+v", events)
+	}
+}
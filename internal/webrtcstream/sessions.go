@@ -0,0 +1,214 @@
+package webrtcstream
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"time"
+)
+
+// DefaultIdleTimeout is how long a stream may go without a connected viewer
+// before ReapIdle stops it
+const DefaultIdleTimeout = 60 * time.Second
+
+// ErrTooManyViewers is returned by Start when the device already serves
+// its maximum number of streams
+var ErrTooManyViewers = errors.New("too many viewers")
+
+// Stream event types and stop reasons
+const (
+	EventStart = "start"
+	EventStop  = "stop"
+
+	StopRequested = "stopped" // Stop was called
+	StopIdle      = "idle"    // ReapIdle found no viewer
+)
+
+// StreamEvent reports a stream starting or stopping, e.g. for an audit
+// trail. Stop events come after the stream's recording is finished.
+type StreamEvent struct {
+	Type   string // EventStart or EventStop
+	Reason string // stop events only
+	Stream StreamInfo
+}
+
+// StreamInfo describes an active stream for ListStreams
+type StreamInfo struct {
+	ID           string
+	SessionID    string
+	Viewer       string // who asked for the stream, as the caller named them
+	Mode         string
+	Layer        string
+	MonitorIndex int
+	TargetFPS    int
+	BitrateKbps  int    // current video target; 0 in data channel modes
+	State        string // peer connection state
+	Started      time.Time
+	IdleSince    time.Time // zero while a viewer is connected
+	Audio        bool
+	Control      string // control state; empty unless control was requested
+	Recording    string // path of the recording; empty unless recorded
+	Principal    string // who asked for control; empty unless control was requested
+}
+
+// SetMaxViewers limits how many streams the device serves at once; 0 or
+// less means no limit
+func (m *Manager) SetMaxViewers(n int) {
+	m.mu.Lock()
+	m.maxViewers = n
+	m.mu.Unlock()
+}
+
+// SetEventHook has fn called as streams start and stop. It runs on the
+// goroutine starting or stopping the stream and must not block.
+func (m *Manager) SetEventHook(fn func(StreamEvent)) {
+	m.mu.Lock()
+	m.eventHook = fn
+	m.mu.Unlock()
+}
+
+func (m *Manager) emit(ev StreamEvent) {
+	m.mu.RLock()
+	fn := m.eventHook
+	m.mu.RUnlock()
+	if fn != nil {
+		fn(ev)
+	}
+}
+
+// MaxViewers returns the limit set with SetMaxViewers
+func (m *Manager) MaxViewers() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.maxViewers
+}
+
+// reserveViewer takes a viewer slot for a stream being started; release
+// gives it back once the stream is stored or has failed
+func (m *Manager) reserveViewer() (release func(), err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.maxViewers > 0 && len(m.streams)+m.starting >= m.maxViewers {
+		return nil, ErrTooManyViewers
+	}
+	m.starting++
+	return func() {
+		m.mu.Lock()
+		m.starting--
+		m.mu.Unlock()
+	}, nil
+}
+
+// Streams lists the active streams, oldest first
+func (m *Manager) Streams() []StreamInfo {
+	m.mu.RLock()
+	streams := make([]*Stream, 0, len(m.streams))
+	for _, s := range m.streams {
+		streams = append(streams, s)
+	}
+	m.mu.RUnlock()
+
+	out := make([]StreamInfo, 0, len(streams))
+	for _, s := range streams {
+		out = append(out, s.info())
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Started.Before(out[j].Started) })
+	return out
+}
+
+// Feeds lists the shared monitor captures and how many streams use each
+func (m *Manager) Feeds() []FeedInfo {
+	feeds := m.hub.info()
+	sort.Slice(feeds, func(i, j int) bool { return feeds[i].MonitorIndex < feeds[j].MonitorIndex })
+	return feeds
+}
+
+func (s *Stream) info() StreamInfo {
+	s.mu.Lock()
+	idleSince := s.idleSince
+	s.mu.Unlock()
+	info := StreamInfo{
+		ID:           s.ID,
+		SessionID:    s.sessionID,
+		Viewer:       s.viewer,
+		Mode:         s.mode,
+		Layer:        s.layer,
+		MonitorIndex: s.monitorIndex,
+		TargetFPS:    s.targetFPS,
+		State:        s.PeerConnection.ConnectionState().String(),
+		Started:      s.started,
+		IdleSince:    idleSince,
+		Audio:        s.AudioTrack != nil,
+	}
+	if s.mode == ModeVideo {
+		info.BitrateKbps = s.abr.Bitrate() / 1000
+	}
+	if s.input != nil {
+		info.Control = s.input.currentState()
+		info.Principal = s.input.principal
+	}
+	if s.recorder != nil {
+		info.Recording = s.recorder.path
+	}
+	return info
+}
+
+// markActive records whether a viewer is connected; idleSince starts when
+// the last one leaves
+func (s *Stream) markActive(connected bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if connected {
+		s.idleSince = time.Time{}
+	} else if s.idleSince.IsZero() {
+		s.idleSince = time.Now()
+	}
+}
+
+// ReapIdle stops streams that have had no connected viewer for timeout:
+// offers never answered, and viewers that went away without stopping their
+// stream. It runs until ctx is cancelled.
+func (m *Manager) ReapIdle(ctx context.Context, timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultIdleTimeout
+	}
+	interval := timeout / 4
+	if interval < time.Second {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.reapIdle(now, timeout)
+		}
+	}
+}
+
+// reapIdle stops the streams idle for at least timeout at now and returns
+// their IDs
+func (m *Manager) reapIdle(now time.Time, timeout time.Duration) []string {
+	var idle []string
+	m.mu.RLock()
+	for id, s := range m.streams {
+		s.mu.Lock()
+		since := s.idleSince
+		s.mu.Unlock()
+		if !since.IsZero() && now.Sub(since) >= timeout {
+			idle = append(idle, id)
+		}
+	}
+	m.mu.RUnlock()
+
+	for _, id := range idle {
+		log.Printf("[INFO] WebRTC stream %s: no viewer for %s, stopping", id, timeout)
+		if err := m.stop(id, StopIdle); err != nil {
+			log.Printf("[WARN] WebRTC stream %s: %v", id, err)
+		}
+	}
+	return idle
+}
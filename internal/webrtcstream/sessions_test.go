@@ -0,0 +1,180 @@
+package webrtcstream
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pion/webrtc/v3"
+)
+
+var lowWidth = qualityLayers[LayerLow].MaxWidth
+
+func TestViewersShareMonitorCapture(t *testing.T) {
+	hub := newCaptureHub()
+	a, err := hub.subscribe(SourceSynthetic, 0, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := hub.subscribe(SourceSynthetic, 0, 10, lowWidth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.feed != b.feed {
+		t.Fatal("viewers of one monitor got separate feeds")
+	}
+
+	full := capture(t, a)
+	low := capture(t, b)
+	if full.Bounds().Dx() != 1280 || low.Bounds().Dx() != lowWidth || b.Bounds() != low.Bounds() {
+		t.Fatalf("frame sizes %v and %v, subscriber bounds %v", full.Bounds(), low.Bounds(), b.Bounds())
+	}
+	if st := hub.info(); len(st) != 1 || st[0].Subscribers != 2 || st[0].Captures != 1 || st[0].FramesServed != 2 {
+		t.Fatalf("feed %+v, want one capture serving two frames", st)
+	}
+
+	// A capture older than half a frame interval is replaced
+	time.Sleep(60 * time.Millisecond)
+	capture(t, a)
+	if st := hub.info(); st[0].Captures != 2 {
+		t.Fatalf("captures = %d after a frame interval, want 2", st[0].Captures)
+	}
+
+	a.close()
+	a.close()
+	if st := hub.info(); len(st) != 1 || st[0].Subscribers != 1 {
+		t.Fatalf("feed %+v after one viewer left", st)
+	}
+	b.close()
+	if st := hub.info(); len(st) != 0 {
+		t.Fatalf("feed %+v still open after the last viewer left", st)
+	}
+}
+
+func TestQualityLayerDefaults(t *testing.T) {
+	m := NewManager()
+	m.SetFrameSource(SourceSynthetic)
+	if _, err := m.Start("test", Options{Mode: ModeTiles, Layer: "ultra"}); err == nil {
+		t.Fatal("unknown layer accepted")
+	}
+	offer, err := m.Start("test", Options{Mode: ModeTiles, Layer: LayerLow, JPEGQuality: 70, Viewer: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop(offer.StreamID)
+	if offer.Layer != LayerLow {
+		t.Fatalf("offer layer %q", offer.Layer)
+	}
+	m.mu.RLock()
+	stream := m.streams[offer.StreamID]
+	m.mu.RUnlock()
+	if stream.targetFPS != 5 || stream.jpegQuality != 70 || stream.source.Bounds().Dx() != lowWidth {
+		t.Fatalf("low layer stream: fps %d, quality %d, bounds %v", stream.targetFPS, stream.jpegQuality, stream.source.Bounds())
+	}
+
+	streams := m.Streams()
+	if len(streams) != 1 || streams[0].Viewer != "alice" || streams[0].Layer != LayerLow || streams[0].IdleSince.IsZero() {
+		t.Fatalf("streams %+v", streams)
+	}
+}
+
+func TestMaxViewers(t *testing.T) {
+	m := NewManager()
+	m.SetFrameSource(SourceSynthetic)
+	m.SetMaxViewers(2)
+	var ids []string
+	for i := 0; i < 2; i++ {
+		offer, err := m.Start("test", Options{Mode: ModeTiles})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, offer.StreamID)
+	}
+	if _, err := m.Start("test", Options{Mode: ModeTiles}); !errors.Is(err, ErrTooManyViewers) {
+		t.Fatalf("third viewer: %v, want ErrTooManyViewers", err)
+	}
+	if feeds := m.Feeds(); len(feeds) != 1 || feeds[0].Subscribers != 2 {
+		t.Fatalf("feeds %+v after a refused viewer", feeds)
+	}
+	m.Stop(ids[0])
+	offer, err := m.Start("test", Options{Mode: ModeTiles})
+	if err != nil {
+		t.Fatalf("viewer after one left: %v", err)
+	}
+	m.Stop(offer.StreamID)
+	m.Stop(ids[1])
+	if feeds := m.Feeds(); len(feeds) != 0 {
+		t.Fatalf("feeds %+v after every stream stopped", feeds)
+	}
+}
+
+func TestReapIdleStreams(t *testing.T) {
+	m := NewManager()
+	m.SetFrameSource(SourceSynthetic)
+	unanswered, err := m.Start("test", Options{Mode: ModeTiles})
+	if err != nil {
+		t.Fatal(err)
+	}
+	watched, err := m.Start("test", Options{Mode: ModeTiles})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop(watched.StreamID)
+	m.mu.RLock()
+	m.streams[watched.StreamID].markActive(true)
+	m.mu.RUnlock()
+
+	if idle := m.reapIdle(time.Now(), time.Minute); len(idle) != 0 {
+		t.Fatalf("reaped %v before the timeout", idle)
+	}
+	idle := m.reapIdle(time.Now().Add(time.Minute), time.Minute)
+	if len(idle) != 1 || idle[0] != unanswered.StreamID {
+		t.Fatalf("reaped %v, want only the unanswered stream", idle)
+	}
+	if streams := m.Streams(); len(streams) != 1 || streams[0].ID != watched.StreamID {
+		t.Fatalf("streams %+v after reaping", streams)
+	}
+}
+
+func TestStreamIdleAfterViewerLeaves(t *testing.T) {
+	m := NewManager()
+	m.SetFrameSource(SourceSynthetic)
+	offer, err := m.Start("test", Options{Mode: ModeTiles})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop(offer.StreamID)
+
+	opened := make(chan struct{}, 1)
+	var viewer *webrtc.PeerConnection
+	connect(t, m, offer, func(pc *webrtc.PeerConnection) {
+		viewer = pc
+		pc.OnDataChannel(func(*webrtc.DataChannel) {
+			select {
+			case opened <- struct{}{}:
+			default:
+			}
+		})
+	})
+	select {
+	case <-opened:
+	case <-time.After(15 * time.Second):
+		t.Skip("peers could not connect in this environment")
+	}
+	waitIdle := func(want bool) {
+		t.Helper()
+		deadline := time.Now().Add(10 * time.Second)
+		for time.Now().Before(deadline) {
+			if st := m.Streams(); len(st) == 1 && st[0].IdleSince.IsZero() != want {
+				return
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		t.Fatalf("stream idle = %v, want %v", !want, want)
+	}
+	waitIdle(false)
+
+	// The viewer goes away without stopping the stream
+	viewer.Close()
+	waitIdle(true)
+}
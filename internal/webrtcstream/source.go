@@ -0,0 +1,129 @@
+package webrtcstream
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"sync"
+
+	"github.com/kbinani/screenshot"
+)
+
+// Frame source names accepted by Manager.SetFrameSource
+const (
+	SourceScreen    = "screen"
+	SourceSynthetic = "synthetic"
+)
+
+// FrameSource produces the frames a stream encodes
+type FrameSource interface {
+	// Bounds returns the size of the frames Capture produces
+	Bounds() image.Rectangle
+	// Capture returns the current frame. The image must not be reused by
+	// later captures.
+	Capture() (image.Image, error)
+}
+
+// SourceFactory opens a frame source for a monitor
+type SourceFactory func(monitorIndex int) (FrameSource, error)
+
+// sourceFactories maps source names to their factories
+var sourceFactories = map[string]SourceFactory{
+	SourceScreen:    NewScreenSource,
+	SourceSynthetic: func(int) (FrameSource, error) { return NewSyntheticSource(1280, 720), nil },
+}
+
+// screenSource captures one monitor
+type screenSource struct {
+	monitorIndex int
+	bounds       image.Rectangle
+}
+
+// NewScreenSource returns a source that captures the given monitor
+func NewScreenSource(monitorIndex int) (FrameSource, error) {
+	numDisplays := screenshot.NumActiveDisplays()
+	if monitorIndex < 0 || monitorIndex >= numDisplays {
+		return nil, fmt.Errorf("invalid monitor_index %d, have %d displays", monitorIndex, numDisplays)
+	}
+	return &screenSource{
+		monitorIndex: monitorIndex,
+		bounds:       screenshot.GetDisplayBounds(monitorIndex),
+	}, nil
+}
+
+func (s *screenSource) Bounds() image.Rectangle {
+	return image.Rect(0, 0, s.bounds.Dx(), s.bounds.Dy())
+}
+
+// DesktopBounds is the monitor's rectangle in desktop coordinates, where
+// input is injected
+func (s *screenSource) DesktopBounds() image.Rectangle {
+	return s.bounds
+}
+
+func (s *screenSource) Capture() (image.Image, error) {
+	img, err := screenshot.CaptureRect(s.bounds)
+	if err != nil {
+		return nil, fmt.Errorf("capture failed: %w", err)
+	}
+	return img, nil
+}
+
+// SyntheticSource draws a moving test pattern, for headless machines and tests.
+// Each capture advances the pattern by one frame.
+type SyntheticSource struct {
+	mu    sync.Mutex
+	size  image.Rectangle
+	frame int
+}
+
+// NewSyntheticSource returns a synthetic source of the given size
+func NewSyntheticSource(width, height int) *SyntheticSource {
+	return &SyntheticSource{size: image.Rect(0, 0, width, height)}
+}
+
+func (s *SyntheticSource) Bounds() image.Rectangle {
+	return s.size
+}
+
+// Capture draws colour bars, a bar sweeping across them and a block per
+// frame-counter bit, so consecutive frames always differ.
+func (s *SyntheticSource) Capture() (image.Image, error) {
+	s.mu.Lock()
+	n := s.frame
+	s.frame++
+	s.mu.Unlock()
+
+	w, h := s.size.Dx(), s.size.Dy()
+	img := image.NewRGBA(s.size)
+	bars := []color.RGBA{
+		{192, 192, 192, 255}, {192, 192, 0, 255}, {0, 192, 192, 255}, {0, 192, 0, 255},
+		{192, 0, 192, 255}, {192, 0, 0, 255}, {0, 0, 192, 255}, {16, 16, 16, 255},
+	}
+	for i, c := range bars {
+		r := image.Rect(i*w/len(bars), 0, (i+1)*w/len(bars), h)
+		draw.Draw(img, r, &image.Uniform{c}, image.Point{}, draw.Src)
+	}
+
+	sweep := w / 40
+	if sweep < 2 {
+		sweep = 2
+	}
+	x := (n * sweep / 2) % w
+	draw.Draw(img, image.Rect(x, 0, x+sweep, h), &image.Uniform{color.White}, image.Point{}, draw.Src)
+
+	cell := h / 16
+	if cell < 2 {
+		cell = 2
+	}
+	for bit := 0; bit < 16; bit++ {
+		c := color.RGBA{32, 32, 32, 255}
+		if n&(1<<uint(bit)) != 0 {
+			c = color.RGBA{255, 255, 255, 255}
+		}
+		r := image.Rect(bit*cell, h-cell, (bit+1)*cell, h)
+		draw.Draw(img, r, &image.Uniform{c}, image.Point{}, draw.Src)
+	}
+	return img, nil
+}
@@ -0,0 +1,299 @@
+package webrtcstream
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"log"
+	"time"
+)
+
+// A tiles-mode frame payload lists the rectangles that changed since the
+// previous frame, each as its own JPEG, little-endian:
+//
+//	offset size field
+//	0      2    frame width
+//	2      2    frame height
+//	4      2    rectangle count
+//	6      -    rectangles, each:
+//	            x, y, width, height  uint16 each
+//	            length               uint32
+//	            JPEG data            length bytes
+//
+// A keyframe carries a single rectangle covering the whole frame. A frame
+// whose size differs from the viewer's canvas is always a keyframe.
+const (
+	// TileSize is the edge of the grid squares compared between frames
+	TileSize = 64
+	// DefaultKeyframeInterval is how often a full frame is sent even when
+	// little changed, so a viewer recovers from any missed state
+	DefaultKeyframeInterval = 5 * time.Second
+	// keyframeDirtyRatio sends a keyframe instead when more than this share
+	// of the frame changed; one large JPEG beats many small ones
+	keyframeDirtyRatio = 0.6
+	// maxBufferedAmount skips capture while the DataChannel is this far
+	// behind, so a slow viewer gets fewer frames instead of growing latency
+	maxBufferedAmount = 4 << 20
+	maxTileDimension  = 0xffff
+)
+
+// DirtyRect is one changed region of a frame and its JPEG encoding
+type DirtyRect struct {
+	Rect image.Rectangle
+	JPEG []byte
+}
+
+// TileEncoder turns a sequence of frames into tiles-mode payloads, sending
+// only the regions that changed since the previous frame
+type TileEncoder struct {
+	quality     int
+	keyInterval time.Duration
+	prev        *image.RGBA
+	lastKey     time.Time
+	forceKey    bool
+}
+
+// NewTileEncoder encodes rectangles at the given JPEG quality and sends a
+// keyframe at least every keyInterval
+func NewTileEncoder(quality int, keyInterval time.Duration) *TileEncoder {
+	if keyInterval <= 0 {
+		keyInterval = DefaultKeyframeInterval
+	}
+	return &TileEncoder{quality: quality, keyInterval: keyInterval}
+}
+
+// RequestKeyframe makes the next Encode send a full frame
+func (e *TileEncoder) RequestKeyframe() {
+	e.forceKey = true
+}
+
+// Encode returns the payload for img and whether it is a keyframe. The
+// payload is nil when nothing changed. img becomes the reference for the
+// next call, so every returned payload must reach the viewer.
+func (e *TileEncoder) Encode(img *image.RGBA, now time.Time) ([]byte, bool, error) {
+	b := img.Bounds()
+	if b.Dx() > maxTileDimension || b.Dy() > maxTileDimension {
+		return nil, false, fmt.Errorf("frame %dx%d too large", b.Dx(), b.Dy())
+	}
+
+	key := e.forceKey || e.prev == nil || e.prev.Bounds() != b || now.Sub(e.lastKey) >= e.keyInterval
+	var rects []image.Rectangle
+	if !key {
+		rects = dirtyRects(e.prev, img, TileSize)
+		if len(rects) == 0 {
+			return nil, false, nil
+		}
+		area := 0
+		for _, r := range rects {
+			area += r.Dx() * r.Dy()
+		}
+		key = float64(area) > keyframeDirtyRatio*float64(b.Dx()*b.Dy())
+	}
+	if key {
+		rects = []image.Rectangle{b}
+	}
+
+	var buf bytes.Buffer
+	var hdr [12]byte
+	binary.LittleEndian.PutUint16(hdr[0:], uint16(b.Dx()))
+	binary.LittleEndian.PutUint16(hdr[2:], uint16(b.Dy()))
+	binary.LittleEndian.PutUint16(hdr[4:], uint16(len(rects)))
+	buf.Write(hdr[:6])
+	var enc bytes.Buffer
+	for _, r := range rects {
+		enc.Reset()
+		if err := jpeg.Encode(&enc, img.SubImage(r), &jpeg.Options{Quality: e.quality}); err != nil {
+			return nil, false, fmt.Errorf("jpeg encode failed: %w", err)
+		}
+		binary.LittleEndian.PutUint16(hdr[0:], uint16(r.Min.X-b.Min.X))
+		binary.LittleEndian.PutUint16(hdr[2:], uint16(r.Min.Y-b.Min.Y))
+		binary.LittleEndian.PutUint16(hdr[4:], uint16(r.Dx()))
+		binary.LittleEndian.PutUint16(hdr[6:], uint16(r.Dy()))
+		binary.LittleEndian.PutUint32(hdr[8:], uint32(enc.Len()))
+		buf.Write(hdr[:])
+		buf.Write(enc.Bytes())
+	}
+
+	e.prev = img
+	if key {
+		e.lastKey = now
+		e.forceKey = false
+	}
+	return buf.Bytes(), key, nil
+}
+
+// dirtyRects compares two same-sized frames tile by tile and merges the
+// changed tiles of each tile row into horizontal runs
+func dirtyRects(prev, cur *image.RGBA, tile int) []image.Rectangle {
+	b := cur.Bounds()
+	var rects []image.Rectangle
+	for ty := b.Min.Y; ty < b.Max.Y; ty += tile {
+		y1 := min(ty+tile, b.Max.Y)
+		run := -1
+		for tx := b.Min.X; tx < b.Max.X; tx += tile {
+			x1 := min(tx+tile, b.Max.X)
+			if tileChanged(prev, cur, image.Rect(tx, ty, x1, y1)) {
+				if run < 0 {
+					run = tx
+				}
+				continue
+			}
+			if run >= 0 {
+				rects = append(rects, image.Rect(run, ty, tx, y1))
+				run = -1
+			}
+		}
+		if run >= 0 {
+			rects = append(rects, image.Rect(run, ty, b.Max.X, y1))
+		}
+	}
+	return rects
+}
+
+func tileChanged(prev, cur *image.RGBA, r image.Rectangle) bool {
+	n := r.Dx() * 4
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		po, co := prev.PixOffset(r.Min.X, y), cur.PixOffset(r.Min.X, y)
+		if !bytes.Equal(prev.Pix[po:po+n], cur.Pix[co:co+n]) {
+			return true
+		}
+	}
+	return false
+}
+
+// DecodeTileFrame parses a frame payload into its size and rectangles
+func DecodeTileFrame(payload []byte) (image.Point, []DirtyRect, error) {
+	if len(payload) < 6 {
+		return image.Point{}, nil, errors.New("tile frame too short")
+	}
+	size := image.Pt(int(binary.LittleEndian.Uint16(payload[0:])), int(binary.LittleEndian.Uint16(payload[2:])))
+	n := int(binary.LittleEndian.Uint16(payload[4:]))
+	p := payload[6:]
+	rects := make([]DirtyRect, 0, n)
+	for i := 0; i < n; i++ {
+		if len(p) < 12 {
+			return size, nil, fmt.Errorf("rectangle %d: truncated header", i)
+		}
+		x, y := int(binary.LittleEndian.Uint16(p[0:])), int(binary.LittleEndian.Uint16(p[2:]))
+		w, h := int(binary.LittleEndian.Uint16(p[4:])), int(binary.LittleEndian.Uint16(p[6:]))
+		l := int(binary.LittleEndian.Uint32(p[8:]))
+		if len(p)-12 < l {
+			return size, nil, fmt.Errorf("rectangle %d: truncated data", i)
+		}
+		rects = append(rects, DirtyRect{Rect: image.Rect(x, y, x+w, y+h), JPEG: p[12 : 12+l]})
+		p = p[12+l:]
+	}
+	return size, rects, nil
+}
+
+// ApplyTileFrame paints a frame payload onto canvas, replacing it with a
+// new one when the frame size changed. It is the reference viewer for the
+// protocol.
+func ApplyTileFrame(canvas *image.RGBA, payload []byte) (*image.RGBA, error) {
+	size, rects, err := DecodeTileFrame(payload)
+	if err != nil {
+		return canvas, err
+	}
+	if canvas == nil || canvas.Bounds().Size() != size {
+		canvas = image.NewRGBA(image.Rectangle{Max: size})
+	}
+	for _, r := range rects {
+		img, err := jpeg.Decode(bytes.NewReader(r.JPEG))
+		if err != nil {
+			return canvas, fmt.Errorf("rectangle at %v: %w", r.Rect.Min, err)
+		}
+		draw.Draw(canvas, r.Rect, img, img.Bounds().Min, draw.Src)
+	}
+	return canvas, nil
+}
+
+// toRGBA returns img as an RGBA image at full resolution, origin at 0,0
+func toRGBA(img image.Image) *image.RGBA {
+	if m, ok := img.(*image.RGBA); ok && m.Rect.Min == (image.Point{}) {
+		return m
+	}
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Src)
+	return dst
+}
+
+// tileLoop captures full-resolution frames and sends the changed regions
+// over the data channel, chunked, until ctx is cancelled or a send fails
+func (s *Stream) tileLoop(ctx context.Context) {
+	ticker := time.NewTicker(time.Second / time.Duration(s.targetFPS))
+	defer ticker.Stop()
+
+	log.Printf("[INFO] WebRTC stream %s: tile loop started (fps=%d, quality=%d, monitor=%d)",
+		s.ID, s.targetFPS, s.jpegQuality, s.monitorIndex)
+
+	var (
+		enc     = NewTileEncoder(s.jpegQuality, DefaultKeyframeInterval)
+		frameID uint32
+		frames  int
+		keys    int
+		sent    int64
+		stats   = time.Now()
+	)
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("[INFO] WebRTC stream %s: tile loop stopped (context cancelled)", s.ID)
+			return
+		case <-ticker.C:
+		}
+
+		// Skip the tick rather than queue behind a slow viewer; the
+		// encoder's reference only advances when a frame is sent
+		if s.DataChannel.BufferedAmount() > maxBufferedAmount {
+			continue
+		}
+
+		img, err := s.source.Capture()
+		if err != nil {
+			log.Printf("[WARN] WebRTC stream %s: capture error: %v", s.ID, err)
+			continue
+		}
+		now := time.Now()
+		payload, key, err := enc.Encode(toRGBA(img), now)
+		if err != nil {
+			log.Printf("[WARN] WebRTC stream %s: encode error: %v", s.ID, err)
+			continue
+		}
+		if payload != nil {
+			var flags uint8
+			if key {
+				flags |= FlagKeyframe
+				keys++
+			}
+			chunks, err := SplitFrame(frameID, flags, payload, ChunkSize)
+			if err != nil {
+				log.Printf("[WARN] WebRTC stream %s: %v", s.ID, err)
+				enc.RequestKeyframe()
+				continue
+			}
+			frameID++
+			for _, c := range chunks {
+				if err := s.DataChannel.Send(c); err != nil {
+					log.Printf("[WARN] WebRTC stream %s: send error: %v", s.ID, err)
+					return
+				}
+			}
+			s.record(func(r *recorder) error { return r.writeJPEG(img, s.jpegQuality, now) })
+			frames++
+			sent += int64(len(payload))
+		}
+
+		if now.Sub(stats) >= statsInterval {
+			secs := now.Sub(stats).Seconds()
+			log.Printf("[INFO] WebRTC stream %s: %.1f fps (%d keyframes), %.0f kbps",
+				s.ID, float64(frames)/secs, keys, float64(sent)*8/secs/1000)
+			frames, keys, sent, stats = 0, 0, 0, now
+		}
+	}
+}
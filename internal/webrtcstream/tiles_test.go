@@ -0,0 +1,74 @@
+package webrtcstream
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+	"time"
+)
+
+func TestTileEncoderSendsChangedRegions(t *testing.T) {
+	src := NewSyntheticSource(640, 360)
+	enc := NewTileEncoder(90, time.Hour)
+	now := time.Now()
+
+	first := toRGBA(capture(t, src))
+	payload, key, err := enc.Encode(first, now)
+	if err != nil || !key {
+		t.Fatalf("first frame key=%v err=%v, want keyframe", key, err)
+	}
+	canvas, err := ApplyTileFrame(nil, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if canvas.Bounds() != first.Bounds() {
+		t.Fatalf("canvas %v, want %v", canvas.Bounds(), first.Bounds())
+	}
+
+	// An identical frame sends nothing
+	same := image.NewRGBA(first.Rect)
+	copy(same.Pix, first.Pix)
+	if payload, _, _ := enc.Encode(same, now); payload != nil {
+		t.Fatalf("unchanged frame produced %d bytes", len(payload))
+	}
+
+	// A small change sends only the tiles it touches
+	changed := image.NewRGBA(first.Rect)
+	copy(changed.Pix, first.Pix)
+	box := image.Rect(100, 70, 140, 90)
+	draw.Draw(changed, box, &image.Uniform{color.RGBA{255, 0, 128, 255}}, image.Point{}, draw.Src)
+	payload, key, err = enc.Encode(changed, now)
+	if err != nil || key {
+		t.Fatalf("delta key=%v err=%v", key, err)
+	}
+	_, rects, err := DecodeTileFrame(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rects) != 1 || rects[0].Rect != image.Rect(64, 64, 192, 128) {
+		t.Fatalf("got %d rects, want the two tiles under the change merged into (64,64)-(192,128)", len(rects))
+	}
+	if canvas, err = ApplyTileFrame(canvas, payload); err != nil {
+		t.Fatal(err)
+	}
+	if c := canvas.RGBAAt(120, 80); absDiff(c.R, 255) > 8 || absDiff(c.B, 128) > 8 {
+		t.Fatalf("canvas pixel %v, want close to the drawn colour", c)
+	}
+
+	// A full-screen change falls back to a keyframe, as does the interval
+	if _, key, _ := enc.Encode(image.NewRGBA(first.Rect), now); !key {
+		t.Fatal("full-frame change not sent as keyframe")
+	}
+	enc.keyInterval = time.Second
+	if _, key, _ := enc.Encode(changed, now.Add(2*time.Second)); !key {
+		t.Fatal("no keyframe after the interval")
+	}
+}
+
+func absDiff(a, b uint8) int {
+	if a > b {
+		return int(a - b)
+	}
+	return int(b - a)
+}
@@ -0,0 +1,122 @@
+package webrtcstream
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net"
+	"time"
+
+	"github.com/pion/turn/v2"
+	"github.com/pion/webrtc/v3"
+)
+
+// DefaultTURNRealm is the realm of the built-in relay
+const DefaultTURNRealm = "edgemesh"
+
+// TURNConfig configures the built-in TURN relay
+type TURNConfig struct {
+	ListenAddr string // UDP and TCP, e.g. ":3478"
+	PublicIP   string // address peers reach relays on; detected if empty
+	Realm      string // default DefaultTURNRealm
+	// Secret signs time-limited credentials (the TURN REST scheme), so
+	// nothing else has to be shared with peers. Random if empty.
+	Secret string
+}
+
+// TURNRelay is a TURN server for peers that cannot reach each other
+// directly, e.g. a viewer and a device on different subnets
+type TURNRelay struct {
+	server   *turn.Server
+	secret   string
+	publicIP string
+	port     int
+}
+
+// StartTURNRelay listens for TURN clients on cfg.ListenAddr
+func StartTURNRelay(cfg TURNConfig) (*TURNRelay, error) {
+	if cfg.Realm == "" {
+		cfg.Realm = DefaultTURNRealm
+	}
+	if cfg.Secret == "" {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		cfg.Secret = hex.EncodeToString(b)
+	}
+	if cfg.PublicIP == "" {
+		cfg.PublicIP = outboundIP()
+	}
+	relayIP := net.ParseIP(cfg.PublicIP)
+	if relayIP == nil {
+		return nil, fmt.Errorf("invalid TURN public IP %q", cfg.PublicIP)
+	}
+
+	udp, err := net.ListenPacket("udp4", cfg.ListenAddr)
+	if err != nil {
+		return nil, fmt.Errorf("TURN listen: %w", err)
+	}
+	port := udp.LocalAddr().(*net.UDPAddr).Port
+	tcp, err := net.Listen("tcp4", fmt.Sprintf("%s:%d", listenHost(cfg.ListenAddr), port))
+	if err != nil {
+		udp.Close()
+		return nil, fmt.Errorf("TURN listen: %w", err)
+	}
+	gen := func() turn.RelayAddressGenerator {
+		return &turn.RelayAddressGeneratorStatic{RelayAddress: relayIP, Address: "0.0.0.0"}
+	}
+	server, err := turn.NewServer(turn.ServerConfig{
+		Realm:             cfg.Realm,
+		AuthHandler:       turn.NewLongTermAuthHandler(cfg.Secret, nil),
+		PacketConnConfigs: []turn.PacketConnConfig{{PacketConn: udp, RelayAddressGenerator: gen()}},
+		ListenerConfigs:   []turn.ListenerConfig{{Listener: tcp, RelayAddressGenerator: gen()}},
+	})
+	if err != nil {
+		udp.Close()
+		tcp.Close()
+		return nil, fmt.Errorf("TURN server: %w", err)
+	}
+	log.Printf("[INFO] TURN relay listening on %s (udp+tcp port %d, relay address %s)", cfg.ListenAddr, port, relayIP)
+	return &TURNRelay{server: server, secret: cfg.Secret, publicIP: relayIP.String(), port: port}, nil
+}
+
+// ICEServer returns the relay as an ICE server with credentials valid for
+// ttl
+func (r *TURNRelay) ICEServer(ttl time.Duration) (webrtc.ICEServer, error) {
+	username, password, err := turn.GenerateLongTermCredentials(r.secret, ttl)
+	if err != nil {
+		return webrtc.ICEServer{}, err
+	}
+	addr := net.JoinHostPort(r.publicIP, fmt.Sprint(r.port))
+	return webrtc.ICEServer{
+		URLs:       []string{"turn:" + addr + "?transport=udp", "turn:" + addr + "?transport=tcp"},
+		Username:   username,
+		Credential: password,
+	}, nil
+}
+
+// Close stops the relay and its allocations
+func (r *TURNRelay) Close() error {
+	return r.server.Close()
+}
+
+func listenHost(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return ""
+	}
+	return host
+}
+
+// outboundIP returns the address this host uses to reach other networks,
+// or loopback when it has none. No packets are sent.
+func outboundIP() string {
+	conn, err := net.Dial("udp4", "192.0.2.1:9")
+	if err != nil {
+		return "127.0.0.1"
+	}
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr).IP.String()
+}
@@ -0,0 +1,167 @@
+package webrtcstream
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"log"
+	"time"
+
+	"github.com/pion/webrtc/v3"
+	"github.com/pion/webrtc/v3/pkg/media"
+	"golang.org/x/image/draw"
+)
+
+// Video stream defaults
+const (
+	DefaultVideoFPS       = 15
+	DefaultMaxBitrateKbps = 2500
+	minBitrateKbps        = 150
+	maxVideoWidth         = 1280
+	// refreshInterval re-sends an unchanged screen so a viewer that joined
+	// late or lost packets still converges on the current picture.
+	refreshInterval = 2 * time.Second
+	statsInterval   = 30 * time.Second
+)
+
+// addVideoTrack adds a sample track for the stream's encoder to pc and
+// starts reading RTCP feedback from its sender.
+func (s *Stream) addVideoTrack(pc *webrtc.PeerConnection, mimeType string) error {
+	track, err := webrtc.NewTrackLocalStaticSample(
+		webrtc.RTPCodecCapability{MimeType: mimeType},
+		"screen", "edgecli-"+s.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create video track: %w", err)
+	}
+	sender, err := pc.AddTrack(track)
+	if err != nil {
+		return fmt.Errorf("failed to add video track: %w", err)
+	}
+	s.VideoTrack = track
+
+	go func() {
+		for {
+			pkts, _, err := sender.ReadRTCP()
+			if err != nil {
+				return
+			}
+			s.abr.HandleRTCP(pkts)
+		}
+	}()
+	return nil
+}
+
+// videoLoop captures, encodes and writes frames to the video track until
+// ctx is cancelled or the track fails.
+func (s *Stream) videoLoop(ctx context.Context) {
+	ticker := time.NewTicker(time.Second / time.Duration(s.targetFPS))
+	defer ticker.Stop()
+
+	log.Printf("[INFO] WebRTC stream %s: video loop started (fps=%d, encoder=%s, max=%dkbps, monitor=%d)",
+		s.ID, s.targetFPS, s.encoderName, s.maxBitrateKbps, s.monitorIndex)
+
+	var (
+		enc      VideoEncoder
+		size     image.Point
+		last     []byte
+		lastSent time.Time
+		frames   int
+		sent     int64
+		stats    = time.Now()
+	)
+	defer func() {
+		if enc != nil {
+			enc.Close()
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("[INFO] WebRTC stream %s: video loop stopped (context cancelled)", s.ID)
+			return
+		case now := <-ticker.C:
+			img, err := s.source.Capture()
+			if err != nil {
+				log.Printf("[WARN] WebRTC stream %s: %v", s.ID, err)
+				return
+			}
+			frame := fitWidth(img, maxVideoWidth)
+
+			keyframe := s.abr.KeyframeRequested()
+			if !keyframe && last != nil && now.Sub(lastSent) < refreshInterval && bytes.Equal(frame.Pix, last) {
+				continue
+			}
+
+			w, h := frame.Rect.Dx(), frame.Rect.Dy()
+			if enc == nil || size != frame.Rect.Size() {
+				if enc != nil {
+					enc.Close()
+				}
+				if enc, err = NewVideoEncoder(s.encoderName, w, h, s.targetFPS); err != nil {
+					log.Printf("[WARN] WebRTC stream %s: %v", s.ID, err)
+					return
+				}
+				size = frame.Rect.Size()
+				keyframe = true
+			}
+			enc.SetBitrate(s.abr.Bitrate())
+			data, err := enc.Encode(frame, keyframe)
+			if err != nil {
+				log.Printf("[WARN] WebRTC stream %s: encode failed: %v", s.ID, err)
+				return
+			}
+
+			duration := time.Second / time.Duration(s.targetFPS)
+			if !lastSent.IsZero() {
+				duration = now.Sub(lastSent)
+			}
+			if err := s.VideoTrack.WriteSample(media.Sample{Data: data, Duration: duration}); err != nil {
+				log.Printf("[WARN] WebRTC stream %s: write sample failed: %v", s.ID, err)
+				return
+			}
+			s.record(func(r *recorder) error { return r.writeVideo(data, keyframe, w, h, now) })
+			last, lastSent = frame.Pix, now
+			frames++
+			sent += int64(len(data))
+
+			if now.Sub(stats) >= statsInterval {
+				secs := now.Sub(stats).Seconds()
+				log.Printf("[INFO] WebRTC stream %s: %dx%d, %.1f fps, %.0f kbps (target %d kbps, loss %.1f%%)",
+					s.ID, w, h, float64(frames)/secs, float64(sent)*8/secs/1000,
+					s.abr.Bitrate()/1000, s.abr.LastLoss()*100)
+				frames, sent, stats = 0, 0, now
+			}
+		}
+	}
+}
+
+// fitWidth returns img as RGBA, scaled down to at most maxWidth pixels wide.
+// Both dimensions are kept even, which hardware decoders prefer for 4:2:0.
+func fitWidth(img image.Image, maxWidth int) *image.RGBA {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > maxWidth {
+		h = h * maxWidth / w
+		w = maxWidth
+	}
+	w, h = w&^1, h&^1
+	if w < 2 {
+		w = 2
+	}
+	if h < 2 {
+		h = 2
+	}
+	if rgba, ok := img.(*image.RGBA); ok && b.Dx() == w && b.Dy() == h && b.Min == (image.Point{}) {
+		return rgba
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	if b.Dx() == w && b.Dy() == h {
+		draw.Copy(dst, image.Point{}, img, b, draw.Src, nil)
+	} else {
+		draw.ApproxBiLinear.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	}
+	return dst
+}
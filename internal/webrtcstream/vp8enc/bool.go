@@ -0,0 +1,81 @@
+package vp8enc
+
+// boolEncoder is the boolean entropy encoder of RFC 6386 section 7.3.
+// Each partition of a frame is coded with its own encoder.
+type boolEncoder struct {
+	out      []byte
+	rng      uint32
+	bottom   uint32
+	bitCount int
+}
+
+func newBoolEncoder(capacity int) *boolEncoder {
+	return &boolEncoder{
+		out:      make([]byte, 0, capacity),
+		rng:      255,
+		bitCount: 24,
+	}
+}
+
+// putBit codes one bit whose probability of being false is prob/256.
+func (e *boolEncoder) putBit(prob uint8, bit bool) {
+	split := 1 + (((e.rng - 1) * uint32(prob)) >> 8)
+	if bit {
+		e.bottom += split
+		e.rng -= split
+	} else {
+		e.rng = split
+	}
+	for e.rng < 128 {
+		e.rng <<= 1
+		if e.bottom&(1<<31) != 0 {
+			e.carry()
+		}
+		e.bottom <<= 1
+		e.bitCount--
+		if e.bitCount == 0 {
+			e.out = append(e.out, byte(e.bottom>>24))
+			e.bottom &= (1 << 24) - 1
+			e.bitCount = 8
+		}
+	}
+}
+
+// putLiteral codes an n-bit unsigned value, most significant bit first,
+// with even probabilities.
+func (e *boolEncoder) putLiteral(v uint32, n int) {
+	for n > 0 {
+		n--
+		e.putBit(128, v&(1<<uint(n)) != 0)
+	}
+}
+
+// carry propagates a carry into the bytes already written.
+func (e *boolEncoder) carry() {
+	i := len(e.out) - 1
+	for i >= 0 && e.out[i] == 255 {
+		e.out[i] = 0
+		i--
+	}
+	if i >= 0 {
+		e.out[i]++
+	}
+}
+
+// finish flushes the remaining state and returns the coded bytes.
+func (e *boolEncoder) finish() []byte {
+	c := e.bitCount
+	v := e.bottom
+	if v&(1<<uint(32-c)) != 0 {
+		e.carry()
+	}
+	v <<= uint(c & 7)
+	for c >>= 3; c > 0; c-- {
+		v <<= 8
+	}
+	for i := 0; i < 4; i++ {
+		e.out = append(e.out, byte(v>>24))
+		v <<= 8
+	}
+	return e.out
+}
@@ -0,0 +1,368 @@
+// Package vp8enc is a small, dependency-free VP8 encoder (RFC 6386) for
+// screen streaming.
+//
+// Every frame is a key frame. Macroblocks use whole-block DC, vertical or
+// horizontal prediction, a single quantizer and one token partition. That
+// keeps the encoder simple and every frame independently decodable, at the
+// cost of a higher bitrate than an encoder with inter prediction.
+package vp8enc
+
+import (
+	"errors"
+	"fmt"
+	"image"
+)
+
+// MaxDimension is the largest width or height a VP8 frame header can carry.
+const MaxDimension = 1<<14 - 1
+
+// Default settings for a new encoder.
+const (
+	DefaultQuantizer = 40 // index into the dequantization tables, 0 (best) to 127
+	MinQuantizer     = 4
+	MaxQuantizer     = 127
+)
+
+// ErrSizeMismatch is returned when a frame does not match the encoder size.
+var ErrSizeMismatch = errors.New("vp8enc: frame size does not match encoder")
+
+// Intra prediction modes, in the decoder's numbering.
+const (
+	predDC = iota
+	predTM
+	predVE
+	predHE
+)
+
+// nzContext holds the "has nonzero coefficients" flags along one edge of a
+// macroblock, which select token probabilities for the neighbouring blocks.
+type nzContext struct {
+	y  [4]uint8
+	u  [2]uint8
+	v  [2]uint8
+	y2 uint8
+}
+
+// mbInfo is what the first partition records for each macroblock.
+type mbInfo struct {
+	yMode  uint8
+	uvMode uint8
+	skip   bool
+}
+
+// Encoder encodes frames of a fixed size. It is not safe for concurrent use.
+type Encoder struct {
+	width, height int
+	mbw, mbh      int
+	quantizer     int
+
+	yStride, cStride      int
+	srcY, srcU, srcV      []uint8 // padded source planes
+	recY, recU, recV      []uint8 // reconstruction, as the decoder will see it
+	above                 []nzContext
+	left                  nzContext
+	y1Quant, y2Quant, uvQ quant
+	modes                 []mbInfo
+}
+
+// NewEncoder returns an encoder for width x height frames.
+func NewEncoder(width, height int) (*Encoder, error) {
+	if width <= 0 || height <= 0 || width > MaxDimension || height > MaxDimension {
+		return nil, fmt.Errorf("vp8enc: invalid frame size %dx%d", width, height)
+	}
+	e := &Encoder{
+		width:     width,
+		height:    height,
+		mbw:       (width + 15) / 16,
+		mbh:       (height + 15) / 16,
+		quantizer: DefaultQuantizer,
+	}
+	e.yStride = e.mbw * 16
+	e.cStride = e.mbw * 8
+	ySize, cSize := e.yStride*e.mbh*16, e.cStride*e.mbh*8
+	e.srcY, e.recY = make([]uint8, ySize), make([]uint8, ySize)
+	e.srcU, e.recU = make([]uint8, cSize), make([]uint8, cSize)
+	e.srcV, e.recV = make([]uint8, cSize), make([]uint8, cSize)
+	e.above = make([]nzContext, e.mbw)
+	e.modes = make([]mbInfo, e.mbw*e.mbh)
+	return e, nil
+}
+
+// Size returns the frame size the encoder was created for.
+func (e *Encoder) Size() (width, height int) {
+	return e.width, e.height
+}
+
+// SetQuantizer sets the quantizer index used for following frames. Lower
+// values give better quality and larger frames.
+func (e *Encoder) SetQuantizer(q int) {
+	if q < MinQuantizer {
+		q = MinQuantizer
+	}
+	if q > MaxQuantizer {
+		q = MaxQuantizer
+	}
+	e.quantizer = q
+}
+
+// Quantizer returns the current quantizer index.
+func (e *Encoder) Quantizer() int {
+	return e.quantizer
+}
+
+// Encode encodes img as one key frame and returns the compressed frame.
+func (e *Encoder) Encode(img image.Image) ([]byte, error) {
+	b := img.Bounds()
+	if b.Dx() != e.width || b.Dy() != e.height {
+		return nil, fmt.Errorf("%w: got %dx%d, want %dx%d", ErrSizeMismatch, b.Dx(), b.Dy(), e.width, e.height)
+	}
+	e.loadImage(img)
+	e.y1Quant, e.y2Quant, e.uvQ = quantizers(e.quantizer)
+
+	tokens := newBoolEncoder(e.width * e.height / 4)
+	for i := range e.above {
+		e.above[i] = nzContext{}
+	}
+	skipped := 0
+	for mby := 0; mby < e.mbh; mby++ {
+		e.left = nzContext{}
+		for mbx := 0; mbx < e.mbw; mbx++ {
+			info := e.encodeMacroblock(tokens, mbx, mby)
+			if info.skip {
+				skipped++
+			}
+			e.modes[mby*e.mbw+mbx] = info
+		}
+	}
+	first := e.writeHeader(skipped)
+	second := tokens.finish()
+
+	out := make([]byte, 0, 10+len(first)+len(second))
+	tag := uint32(len(first))<<5 | 1<<4 // key frame, version 0, shown
+	out = append(out, byte(tag), byte(tag>>8), byte(tag>>16))
+	out = append(out, 0x9d, 0x01, 0x2a)
+	out = append(out, byte(e.width), byte(e.width>>8), byte(e.height), byte(e.height>>8))
+	out = append(out, first...)
+	out = append(out, second...)
+	return out, nil
+}
+
+// writeHeader codes the first partition: frame header fields followed by
+// the per-macroblock skip flags and prediction modes.
+func (e *Encoder) writeHeader(skipped int) []byte {
+	w := newBoolEncoder(64 + len(e.modes))
+	w.putLiteral(0, 1)   // color space
+	w.putLiteral(0, 1)   // clamping required
+	w.putBit(128, false) // no segmentation
+
+	w.putBit(128, false) // normal loop filter
+	w.putLiteral(uint32(filterLevel(e.quantizer)), 6)
+	w.putLiteral(0, 3)   // sharpness
+	w.putBit(128, false) // no mode/ref filter deltas
+
+	w.putLiteral(0, 2) // one token partition
+
+	w.putLiteral(uint32(e.quantizer), 7)
+	for i := 0; i < 5; i++ {
+		w.putBit(128, false) // no quantizer deltas
+	}
+	w.putBit(128, true) // refresh entropy probabilities
+
+	for i := range tokenProbUpdateProb {
+		for j := range tokenProbUpdateProb[i] {
+			for k := range tokenProbUpdateProb[i][j] {
+				for _, p := range tokenProbUpdateProb[i][j][k] {
+					w.putBit(p, false)
+				}
+			}
+		}
+	}
+
+	w.putBit(128, true) // per-macroblock skip flags
+	skipProb := skipProbability(skipped, len(e.modes))
+	w.putLiteral(uint32(skipProb), 8)
+
+	for _, m := range e.modes {
+		w.putBit(skipProb, m.skip)
+		w.putBit(145, true) // 16x16 luma prediction
+		switch m.yMode {
+		case predDC:
+			w.putBit(156, false)
+			w.putBit(163, false)
+		case predVE:
+			w.putBit(156, false)
+			w.putBit(163, true)
+		case predHE:
+			w.putBit(156, true)
+			w.putBit(128, false)
+		}
+		switch m.uvMode {
+		case predDC:
+			w.putBit(142, false)
+		case predVE:
+			w.putBit(142, true)
+			w.putBit(114, false)
+		case predHE:
+			w.putBit(142, true)
+			w.putBit(114, true)
+			w.putBit(183, false)
+		}
+	}
+	return w.finish()
+}
+
+// skipProbability is the probability, out of 256, that a macroblock is not
+// skipped.
+func skipProbability(skipped, total int) uint8 {
+	p := (total - skipped) * 256 / total
+	if p < 1 {
+		p = 1
+	}
+	if p > 255 {
+		p = 255
+	}
+	return uint8(p)
+}
+
+// filterLevel picks a loop filter strength that grows with the quantizer,
+// smoothing block edges at low bitrates and leaving sharp text alone at
+// high ones.
+func filterLevel(q int) int {
+	l := q * 2 / 5
+	if l > 63 {
+		l = 63
+	}
+	return l
+}
+
+// encodeMacroblock predicts, transforms and quantizes one macroblock,
+// writes its tokens and updates the reconstruction.
+func (e *Encoder) encodeMacroblock(w *boolEncoder, mbx, mby int) mbInfo {
+	var info mbInfo
+
+	// Luma: choose the 16x16 mode with the smallest prediction error.
+	var aboveY, leftY [16]uint8
+	edges(e.recY, e.yStride, mbx, mby, 16, &aboveY, &leftY)
+	srcY := e.srcY[mby*16*e.yStride+mbx*16:]
+	var predY [16 * 16]uint8
+	info.yMode = bestMode(srcY, e.yStride, 16, aboveY[:], leftY[:], mbx > 0, mby > 0, predY[:])
+
+	var coeffs [16][16]int32
+	var dcs, y2 [16]int32
+	for b := 0; b < 16; b++ {
+		bx, by := (b%4)*4, (b/4)*4
+		var res [16]int32
+		for j := 0; j < 4; j++ {
+			for i := 0; i < 4; i++ {
+				res[j*4+i] = int32(srcY[(by+j)*e.yStride+bx+i]) - int32(predY[(by+j)*16+bx+i])
+			}
+		}
+		fdct4(&res, &coeffs[b])
+		dcs[b] = coeffs[b][0]
+	}
+	fwht4(&dcs, &y2)
+	nz := quantize(&y2, e.y2Quant, 0)
+	for b := range coeffs {
+		coeffs[b][0] = 0
+		if quantize(&coeffs[b], e.y1Quant, 1) {
+			nz = true
+		}
+	}
+
+	// Chroma: one mode for both planes.
+	var aboveU, leftU, aboveV, leftV [16]uint8
+	edges(e.recU, e.cStride, mbx, mby, 8, &aboveU, &leftU)
+	edges(e.recV, e.cStride, mbx, mby, 8, &aboveV, &leftV)
+	cOff := mby*8*e.cStride + mbx*8
+	var predU, predV [8 * 8]uint8
+	info.uvMode = bestChromaMode(e.srcU[cOff:], e.srcV[cOff:], e.cStride, aboveU[:8], leftU[:8], aboveV[:8], leftV[:8], mbx > 0, mby > 0, predU[:], predV[:])
+	var uCoeffs, vCoeffs [4][16]int32
+	for _, p := range []struct {
+		src   []uint8
+		pred  []uint8
+		coeff *[4][16]int32
+	}{{e.srcU[cOff:], predU[:], &uCoeffs}, {e.srcV[cOff:], predV[:], &vCoeffs}} {
+		for b := 0; b < 4; b++ {
+			bx, by := (b%2)*4, (b/2)*4
+			var res [16]int32
+			for j := 0; j < 4; j++ {
+				for i := 0; i < 4; i++ {
+					res[j*4+i] = int32(p.src[(by+j)*e.cStride+bx+i]) - int32(p.pred[(by+j)*8+bx+i])
+				}
+			}
+			fdct4(&res, &p.coeff[b])
+			if quantize(&p.coeff[b], e.uvQ, 0) {
+				nz = true
+			}
+		}
+	}
+	info.skip = !nz
+
+	e.reconstruct(mbx, mby, predY[:], predU[:], predV[:], &y2, &coeffs, &uCoeffs, &vCoeffs)
+
+	above := &e.above[mbx]
+	if info.skip {
+		*above = nzContext{}
+		e.left = nzContext{}
+		return info
+	}
+	ctx := putCoeffs(w, &defaultTokenProb[planeY2], e.left.y2+above.y2, &y2, 0)
+	e.left.y2, above.y2 = ctx, ctx
+	for y := 0; y < 4; y++ {
+		ctx := e.left.y[y]
+		for x := 0; x < 4; x++ {
+			ctx = putCoeffs(w, &defaultTokenProb[planeY1WithY2], ctx+above.y[x], &coeffs[y*4+x], 1)
+			above.y[x] = ctx
+		}
+		e.left.y[y] = ctx
+	}
+	for _, p := range []struct {
+		coeff       *[4][16]int32
+		left, above *[2]uint8
+	}{{&uCoeffs, &e.left.u, &above.u}, {&vCoeffs, &e.left.v, &above.v}} {
+		for y := 0; y < 2; y++ {
+			ctx := p.left[y]
+			for x := 0; x < 2; x++ {
+				ctx = putCoeffs(w, &defaultTokenProb[planeUV], ctx+p.above[x], &p.coeff[y*2+x], 0)
+				p.above[x] = ctx
+			}
+			p.left[y] = ctx
+		}
+	}
+	return info
+}
+
+// reconstruct writes the decoded macroblock into the reconstruction planes,
+// using the decoder's inverse transforms so later predictions match.
+func (e *Encoder) reconstruct(mbx, mby int, predY, predU, predV []uint8, y2 *[16]int32, coeffs *[16][16]int32, uCoeffs, vCoeffs *[4][16]int32) {
+	var y2deq, dcs [16]int32
+	dequantize(y2, e.y2Quant, &y2deq)
+	iwht4(&y2deq, &dcs)
+
+	dst := e.recY[mby*16*e.yStride+mbx*16:]
+	for j := 0; j < 16; j++ {
+		copy(dst[j*e.yStride:j*e.yStride+16], predY[j*16:j*16+16])
+	}
+	for b := 0; b < 16; b++ {
+		var deq [16]int32
+		dequantize(&coeffs[b], e.y1Quant, &deq)
+		deq[0] = dcs[b]
+		idct4Add(&deq, dst[(b/4)*4*e.yStride+(b%4)*4:], e.yStride)
+	}
+
+	cOff := mby*8*e.cStride + mbx*8
+	for _, p := range []struct {
+		dst   []uint8
+		pred  []uint8
+		coeff *[4][16]int32
+	}{{e.recU[cOff:], predU, uCoeffs}, {e.recV[cOff:], predV, vCoeffs}} {
+		for j := 0; j < 8; j++ {
+			copy(p.dst[j*e.cStride:j*e.cStride+8], p.pred[j*8:j*8+8])
+		}
+		for b := 0; b < 4; b++ {
+			var deq [16]int32
+			dequantize(&p.coeff[b], e.uvQ, &deq)
+			idct4Add(&deq, p.dst[(b/2)*4*e.cStride+(b%2)*4:], e.cStride)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package vp8enc
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"golang.org/x/image/vp8"
+)
+
+// testImage draws gradients, hard edges and text-like stripes, which
+// exercise all three prediction modes.
+func testImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.RGBA{uint8(x * 255 / w), uint8(y * 255 / h), 128, 255}
+			if x > w/2 && (y/3)%2 == 0 {
+				c = color.RGBA{20, 20, 20, 255}
+			}
+			if y > h/2 && x < w/3 {
+				c = color.RGBA{240, 200, 10, 255}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func decode(t *testing.T, frame []byte) *image.YCbCr {
+	t.Helper()
+	d := vp8.NewDecoder()
+	d.Init(bytes.NewReader(frame), len(frame))
+	if _, err := d.DecodeFrameHeader(); err != nil {
+		t.Fatalf("DecodeFrameHeader: %v", err)
+	}
+	img, err := d.DecodeFrame()
+	if err != nil {
+		t.Fatalf("DecodeFrame: %v", err)
+	}
+	return img
+}
+
+// lumaPSNR compares the decoded luma plane with the encoder's source plane.
+func lumaPSNR(e *Encoder, img *image.YCbCr) float64 {
+	var sse float64
+	for y := 0; y < e.height; y++ {
+		for x := 0; x < e.width; x++ {
+			d := float64(e.srcY[y*e.yStride+x]) - float64(img.Y[img.YOffset(x, y)])
+			sse += d * d
+		}
+	}
+	mse := sse / float64(e.width*e.height)
+	if mse == 0 {
+		return math.Inf(1)
+	}
+	return 10 * math.Log10(255*255/mse)
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	// Odd sizes cover partial edge macroblocks.
+	for _, size := range [][2]int{{64, 48}, {101, 37}} {
+		e, err := NewEncoder(size[0], size[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		frame, err := e.Encode(testImage(size[0], size[1]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		img := decode(t, frame)
+		if b := img.Bounds(); b.Dx() != size[0] || b.Dy() != size[1] {
+			t.Fatalf("decoded size %v, want %v", b.Size(), size)
+		}
+		if psnr := lumaPSNR(e, img); psnr < 30 {
+			t.Errorf("%dx%d: luma PSNR %.1f dB, want >= 30", size[0], size[1], psnr)
+		}
+	}
+}
+
+func TestQuantizerTradesSizeForQuality(t *testing.T) {
+	src := testImage(96, 64)
+	var sizes []int
+	var psnrs []float64
+	for _, q := range []int{10, 60, 120} {
+		e, _ := NewEncoder(96, 64)
+		e.SetQuantizer(q)
+		frame, err := e.Encode(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sizes = append(sizes, len(frame))
+		psnrs = append(psnrs, lumaPSNR(e, decode(t, frame)))
+	}
+	for i := 1; i < len(sizes); i++ {
+		if sizes[i] >= sizes[i-1] || psnrs[i] >= psnrs[i-1] {
+			t.Errorf("quantizer step %d: size %d -> %d, PSNR %.1f -> %.1f; want both to drop",
+				i, sizes[i-1], sizes[i], psnrs[i-1], psnrs[i])
+		}
+	}
+}
+
+func TestEncodeFlatFrameSkipsMacroblocks(t *testing.T) {
+	img := image.NewYCbCr(image.Rect(0, 0, 320, 240), image.YCbCrSubsampleRatio420)
+	for i := range img.Y {
+		img.Y[i] = 128
+	}
+	for i := range img.Cb {
+		img.Cb[i], img.Cr[i] = 128, 128
+	}
+	e, _ := NewEncoder(320, 240)
+	frame, err := e.Encode(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frame) > 1200 {
+		t.Errorf("flat 320x240 frame is %d bytes, want a few hundred", len(frame))
+	}
+	if psnr := lumaPSNR(e, decode(t, frame)); psnr < 45 {
+		t.Errorf("flat frame PSNR %.1f dB", psnr)
+	}
+}
+
+func TestEncodeRejectsWrongSize(t *testing.T) {
+	e, _ := NewEncoder(32, 32)
+	if _, err := e.Encode(image.NewRGBA(image.Rect(0, 0, 16, 16))); err == nil {
+		t.Fatal("expected size mismatch error")
+	}
+}
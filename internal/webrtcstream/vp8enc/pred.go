@@ -0,0 +1,111 @@
+package vp8enc
+
+// Whole-block intra prediction, as specified in section 12.2.
+
+// edges loads the reconstructed row above and column left of a size x size
+// block. Outside the frame the decoder assumes 127 above and 129 left.
+func edges(plane []uint8, stride, mbx, mby, size int, above, left *[16]uint8) {
+	x0, y0 := mbx*size, mby*size
+	for i := 0; i < size; i++ {
+		if mby > 0 {
+			above[i] = plane[(y0-1)*stride+x0+i]
+		} else {
+			above[i] = 0x7f
+		}
+		if mbx > 0 {
+			left[i] = plane[(y0+i)*stride+x0-1]
+		} else {
+			left[i] = 0x81
+		}
+	}
+}
+
+// predict fills out with the size x size prediction for mode. DC prediction
+// only averages the edges that lie inside the frame.
+func predict(mode uint8, size int, above, left []uint8, haveLeft, haveAbove bool, out []uint8) {
+	switch mode {
+	case predVE:
+		for j := 0; j < size; j++ {
+			copy(out[j*size:(j+1)*size], above)
+		}
+	case predHE:
+		for j := 0; j < size; j++ {
+			for i := 0; i < size; i++ {
+				out[j*size+i] = left[j]
+			}
+		}
+	default:
+		sum, n := 0, 0
+		if haveAbove {
+			for _, v := range above {
+				sum += int(v)
+			}
+			n += size
+		}
+		if haveLeft {
+			for _, v := range left {
+				sum += int(v)
+			}
+			n += size
+		}
+		dc := uint8(0x80)
+		if n > 0 {
+			dc = uint8((sum + n/2) / n)
+		}
+		for i := range out[:size*size] {
+			out[i] = dc
+		}
+	}
+}
+
+// sad sums the absolute differences between a source block and a prediction.
+func sad(src []uint8, stride int, pred []uint8, size int) int {
+	total := 0
+	for j := 0; j < size; j++ {
+		for i := 0; i < size; i++ {
+			d := int(src[j*stride+i]) - int(pred[j*size+i])
+			if d < 0 {
+				d = -d
+			}
+			total += d
+		}
+	}
+	return total
+}
+
+// predModes are the modes the encoder tries, cheapest to signal first so
+// ties keep it. TrueMotion is left out: it is rarely worth it on screen
+// content.
+var predModes = [...]uint8{predDC, predVE, predHE}
+
+// bestMode picks the luma prediction with the least error and leaves it in
+// pred.
+func bestMode(src []uint8, stride, size int, above, left []uint8, haveLeft, haveAbove bool, pred []uint8) uint8 {
+	best, bestCost := uint8(predDC), -1
+	var buf [16 * 16]uint8
+	tmp := buf[:size*size]
+	for _, m := range predModes {
+		predict(m, size, above, left, haveLeft, haveAbove, tmp)
+		if c := sad(src, stride, tmp, size); bestCost < 0 || c < bestCost {
+			best, bestCost = m, c
+			copy(pred, tmp)
+		}
+	}
+	return best
+}
+
+// bestChromaMode picks one prediction mode for both chroma planes.
+func bestChromaMode(srcU, srcV []uint8, stride int, aboveU, leftU, aboveV, leftV []uint8, haveLeft, haveAbove bool, predU, predV []uint8) uint8 {
+	best, bestCost := uint8(predDC), -1
+	var tu, tv [8 * 8]uint8
+	for _, m := range predModes {
+		predict(m, 8, aboveU, leftU, haveLeft, haveAbove, tu[:])
+		predict(m, 8, aboveV, leftV, haveLeft, haveAbove, tv[:])
+		if c := sad(srcU, stride, tu[:], 8) + sad(srcV, stride, tv[:], 8); bestCost < 0 || c < bestCost {
+			best, bestCost = m, c
+			copy(predU, tu[:])
+			copy(predV, tv[:])
+		}
+	}
+	return best
+}
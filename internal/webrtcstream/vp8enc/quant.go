@@ -0,0 +1,104 @@
+package vp8enc
+
+// quant are the DC and AC quantizer steps of one coefficient type.
+type quant [2]int32
+
+// quantizers derives the Y1, Y2 and UV steps for a frame quantizer index,
+// as the decoder does in section 9.6 with all deltas zero.
+func quantizers(q int) (y1, y2, uv quant) {
+	if q < 0 {
+		q = 0
+	}
+	if q > 127 {
+		q = 127
+	}
+	y1 = quant{int32(dequantTableDC[q]), int32(dequantTableAC[q])}
+	y2 = quant{int32(dequantTableDC[q]) * 2, int32(dequantTableAC[q]) * 155 / 100}
+	if y2[1] < 8 {
+		y2[1] = 8
+	}
+	uvq := q
+	if uvq > 117 {
+		uvq = 117
+	}
+	uv = quant{int32(dequantTableDC[uvq]), int32(dequantTableAC[q])}
+	return y1, y2, uv
+}
+
+// maxLevel is the largest magnitude a DCT_CAT6 token can carry.
+const maxLevel = 2048
+
+// quantize replaces each coefficient of a block with its quantized level,
+// starting at first, and returns whether any level is nonzero.
+func quantize(c *[16]int32, q quant, first int) bool {
+	nz := false
+	for i := first; i < 16; i++ {
+		step := q[1]
+		if i == 0 {
+			step = q[0]
+		}
+		v := c[i]
+		neg := v < 0
+		if neg {
+			v = -v
+		}
+		v = (v + step/2) / step
+		if v > maxLevel {
+			v = maxLevel
+		}
+		if neg {
+			v = -v
+		}
+		c[i] = v
+		nz = nz || v != 0
+	}
+	return nz
+}
+
+// dequantize turns levels back into the coefficients the decoder will see.
+func dequantize(levels *[16]int32, q quant, out *[16]int32) {
+	out[0] = levels[0] * q[0]
+	for i := 1; i < 16; i++ {
+		out[i] = levels[i] * q[1]
+	}
+}
+
+// The dequantization tables are specified in section 14.1.
+var (
+	dequantTableDC = [128]uint16{
+		4, 5, 6, 7, 8, 9, 10, 10,
+		11, 12, 13, 14, 15, 16, 17, 17,
+		18, 19, 20, 20, 21, 21, 22, 22,
+		23, 23, 24, 25, 25, 26, 27, 28,
+		29, 30, 31, 32, 33, 34, 35, 36,
+		37, 37, 38, 39, 40, 41, 42, 43,
+		44, 45, 46, 46, 47, 48, 49, 50,
+		51, 52, 53, 54, 55, 56, 57, 58,
+		59, 60, 61, 62, 63, 64, 65, 66,
+		67, 68, 69, 70, 71, 72, 73, 74,
+		75, 76, 76, 77, 78, 79, 80, 81,
+		82, 83, 84, 85, 86, 87, 88, 89,
+		91, 93, 95, 96, 98, 100, 101, 102,
+		104, 106, 108, 110, 112, 114, 116, 118,
+		122, 124, 126, 128, 130, 132, 134, 136,
+		138, 140, 143, 145, 148, 151, 154, 157,
+	}
+	dequantTableAC = [128]uint16{
+		4, 5, 6, 7, 8, 9, 10, 11,
+		12, 13, 14, 15, 16, 17, 18, 19,
+		20, 21, 22, 23, 24, 25, 26, 27,
+		28, 29, 30, 31, 32, 33, 34, 35,
+		36, 37, 38, 39, 40, 41, 42, 43,
+		44, 45, 46, 47, 48, 49, 50, 51,
+		52, 53, 54, 55, 56, 57, 58, 60,
+		62, 64, 66, 68, 70, 72, 74, 76,
+		78, 80, 82, 84, 86, 88, 90, 92,
+		94, 96, 98, 100, 102, 104, 106, 108,
+		110, 112, 114, 116, 119, 122, 125, 128,
+		131, 134, 137, 140, 143, 146, 149, 152,
+		155, 158, 161, 164, 167, 170, 173, 177,
+		181, 185, 189, 193, 197, 201, 205, 209,
+		213, 217, 221, 225, 229, 234, 239, 245,
+		249, 254, 259, 264, 269, 274, 279, 284,
+	}
+)
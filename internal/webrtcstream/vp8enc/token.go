@@ -0,0 +1,89 @@
+package vp8enc
+
+// putCoeffs codes the quantized levels of one 4x4 block (raster order),
+// starting at position first, as the token tree of section 13.2. It
+// returns whether any token other than the leading end-of-block was coded,
+// which is the nonzero context the neighbouring blocks see.
+func putCoeffs(w *boolEncoder, probs *[nBand][nContext][nProb]uint8, ctx uint8, levels *[16]int32, first int) uint8 {
+	last := -1
+	for n := first; n < 16; n++ {
+		if levels[zigzag[n]] != 0 {
+			last = n
+		}
+	}
+	p := &probs[bands[first]][ctx]
+	if last < 0 {
+		w.putBit(p[0], false)
+		return 0
+	}
+	w.putBit(p[0], true)
+	for n := first; n <= last; n++ {
+		v := levels[zigzag[n]]
+		neg := v < 0
+		if neg {
+			v = -v
+		}
+		if v == 0 {
+			w.putBit(p[1], false)
+			p = &probs[bands[n+1]][0]
+			continue
+		}
+		w.putBit(p[1], true)
+		if v == 1 {
+			w.putBit(p[2], false)
+			p = &probs[bands[n+1]][1]
+		} else {
+			w.putBit(p[2], true)
+			putLevel(w, p, v)
+			p = &probs[bands[n+1]][2]
+		}
+		w.putBit(128, neg)
+		if n == 15 {
+			break
+		}
+		w.putBit(p[0], n < last)
+	}
+	return 1
+}
+
+// putLevel codes a magnitude of at least 2.
+func putLevel(w *boolEncoder, p *[nProb]uint8, v int32) {
+	switch {
+	case v == 2:
+		w.putBit(p[3], false)
+		w.putBit(p[4], false)
+	case v <= 4:
+		w.putBit(p[3], false)
+		w.putBit(p[4], true)
+		w.putBit(p[5], v == 4)
+	case v <= 6:
+		w.putBit(p[3], true)
+		w.putBit(p[6], false)
+		w.putBit(p[7], false)
+		w.putBit(159, v == 6)
+	case v <= 10:
+		w.putBit(p[3], true)
+		w.putBit(p[6], false)
+		w.putBit(p[7], true)
+		w.putBit(165, (v-7)&2 != 0)
+		w.putBit(145, (v-7)&1 != 0)
+	default:
+		w.putBit(p[3], true)
+		w.putBit(p[6], true)
+		cat := 0
+		for cat < 3 && v >= 3+(16<<uint(cat)) {
+			cat++
+		}
+		w.putBit(p[8], cat >= 2)
+		w.putBit(p[9+cat>>1], cat&1 != 0)
+		extra := v - 3 - (8 << uint(cat))
+		tab := &cat3456[cat]
+		nbits := 0
+		for tab[nbits] != 0 {
+			nbits++
+		}
+		for i := 0; i < nbits; i++ {
+			w.putBit(tab[i], extra&(1<<uint(nbits-1-i)) != 0)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package vp8enc
+
+// Forward transforms follow libvpx; inverse transforms follow RFC 6386
+// sections 14.3 and 14.4 exactly, because the encoder must reconstruct the
+// same pixels the decoder will predict from.
+//
+// Coefficient blocks are 16 values in raster order (row*4 + col).
+
+// fdct4 computes the forward DCT of a 4x4 residual block.
+func fdct4(in *[16]int32, out *[16]int32) {
+	var tmp [16]int32
+	for i := 0; i < 4; i++ {
+		r := in[i*4 : i*4+4]
+		a1 := (r[0] + r[3]) * 8
+		b1 := (r[1] + r[2]) * 8
+		c1 := (r[1] - r[2]) * 8
+		d1 := (r[0] - r[3]) * 8
+		tmp[i*4+0] = a1 + b1
+		tmp[i*4+2] = a1 - b1
+		tmp[i*4+1] = (c1*2217 + d1*5352 + 14500) >> 12
+		tmp[i*4+3] = (d1*2217 - c1*5352 + 7500) >> 12
+	}
+	for i := 0; i < 4; i++ {
+		a1 := tmp[i] + tmp[12+i]
+		b1 := tmp[4+i] + tmp[8+i]
+		c1 := tmp[4+i] - tmp[8+i]
+		d1 := tmp[i] - tmp[12+i]
+		out[i] = (a1 + b1 + 7) >> 4
+		out[8+i] = (a1 - b1 + 7) >> 4
+		out[4+i] = (c1*2217+d1*5352+12000)>>16 + btoi(d1 != 0)
+		out[12+i] = (d1*2217 - c1*5352 + 51000) >> 16
+	}
+}
+
+// fwht4 computes the forward Walsh-Hadamard transform of the 16 luma DC
+// coefficients of a macroblock.
+func fwht4(in *[16]int32, out *[16]int32) {
+	var tmp [16]int32
+	for i := 0; i < 4; i++ {
+		r := in[i*4 : i*4+4]
+		a1 := (r[0] + r[2]) * 4
+		d1 := (r[1] + r[3]) * 4
+		c1 := (r[1] - r[3]) * 4
+		b1 := (r[0] - r[2]) * 4
+		tmp[i*4+0] = a1 + d1 + btoi(a1 != 0)
+		tmp[i*4+1] = b1 + c1
+		tmp[i*4+2] = b1 - c1
+		tmp[i*4+3] = a1 - d1
+	}
+	for i := 0; i < 4; i++ {
+		a1 := tmp[i] + tmp[8+i]
+		d1 := tmp[4+i] + tmp[12+i]
+		c1 := tmp[4+i] - tmp[12+i]
+		b1 := tmp[i] - tmp[8+i]
+		a2, b2, c2, d2 := a1+d1, b1+c1, b1-c1, a1-d1
+		a2 += btoi(a2 < 0)
+		b2 += btoi(b2 < 0)
+		c2 += btoi(c2 < 0)
+		d2 += btoi(d2 < 0)
+		out[i] = (a2 + 3) >> 3
+		out[4+i] = (b2 + 3) >> 3
+		out[8+i] = (c2 + 3) >> 3
+		out[12+i] = (d2 + 3) >> 3
+	}
+}
+
+// idct4Add adds the inverse DCT of coeff to the 4x4 block at dst.
+func idct4Add(coeff *[16]int32, dst []uint8, stride int) {
+	const (
+		c1 = 85627 // 65536 * cos(pi/8) * sqrt(2)
+		c2 = 35468 // 65536 * sin(pi/8) * sqrt(2)
+	)
+	var m [4][4]int32
+	for i := 0; i < 4; i++ {
+		a := coeff[i] + coeff[8+i]
+		b := coeff[i] - coeff[8+i]
+		c := (coeff[4+i]*c2)>>16 - (coeff[12+i]*c1)>>16
+		d := (coeff[4+i]*c1)>>16 + (coeff[12+i]*c2)>>16
+		m[i][0] = a + d
+		m[i][1] = b + c
+		m[i][2] = b - c
+		m[i][3] = a - d
+	}
+	for j := 0; j < 4; j++ {
+		dc := m[0][j] + 4
+		a := dc + m[2][j]
+		b := dc - m[2][j]
+		c := (m[1][j]*c2)>>16 - (m[3][j]*c1)>>16
+		d := (m[1][j]*c1)>>16 + (m[3][j]*c2)>>16
+		row := dst[j*stride:]
+		row[0] = clip8(int32(row[0]) + (a+d)>>3)
+		row[1] = clip8(int32(row[1]) + (b+c)>>3)
+		row[2] = clip8(int32(row[2]) + (b-c)>>3)
+		row[3] = clip8(int32(row[3]) + (a-d)>>3)
+	}
+}
+
+// iwht4 inverts the Walsh-Hadamard transform, returning the DC coefficient
+// of each of the 16 luma blocks in raster order.
+func iwht4(in *[16]int32, out *[16]int32) {
+	var m [16]int32
+	for i := 0; i < 4; i++ {
+		a0 := in[i] + in[12+i]
+		a1 := in[4+i] + in[8+i]
+		a2 := in[4+i] - in[8+i]
+		a3 := in[i] - in[12+i]
+		m[i] = a0 + a1
+		m[8+i] = a0 - a1
+		m[4+i] = a3 + a2
+		m[12+i] = a3 - a2
+	}
+	for i := 0; i < 4; i++ {
+		dc := m[i*4] + 3
+		a0 := dc + m[i*4+3]
+		a1 := m[i*4+1] + m[i*4+2]
+		a2 := m[i*4+1] - m[i*4+2]
+		a3 := dc - m[i*4+3]
+		out[i*4+0] = (a0 + a1) >> 3
+		out[i*4+1] = (a3 + a2) >> 3
+		out[i*4+2] = (a0 - a1) >> 3
+		out[i*4+3] = (a3 - a2) >> 3
+	}
+}
+
+func clip8(v int32) uint8 {
+	if v < 0 {
+		return 0
+	}
+	if v > 255 {
+		return 255
+	}
+	return uint8(v)
+}
+
+func btoi(b bool) int32 {
+	if b {
+		return 1
+	}
+	return 0
+}
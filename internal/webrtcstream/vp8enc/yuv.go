@@ -0,0 +1,130 @@
+package vp8enc
+
+import (
+	"image"
+	"image/color"
+)
+
+// loadImage converts img into the encoder's padded 4:2:0 source planes.
+// RGB input is converted with BT.601 limited-range coefficients, which is
+// what browsers assume for VP8. Pixels past the image edge repeat the last
+// row and column so edge macroblocks stay cheap to code.
+func (e *Encoder) loadImage(img image.Image) {
+	switch m := img.(type) {
+	case *image.YCbCr:
+		if m.SubsampleRatio == image.YCbCrSubsampleRatio420 {
+			e.loadYCbCr420(m)
+			break
+		}
+		e.loadRGB(img)
+	case *image.RGBA:
+		e.loadRGBA(m)
+	default:
+		e.loadRGB(img)
+	}
+	padPlane(e.srcY, e.yStride, e.width, e.height, e.mbh*16)
+	cw, ch := (e.width+1)/2, (e.height+1)/2
+	padPlane(e.srcU, e.cStride, cw, ch, e.mbh*8)
+	padPlane(e.srcV, e.cStride, cw, ch, e.mbh*8)
+}
+
+func (e *Encoder) loadYCbCr420(m *image.YCbCr) {
+	b := m.Rect
+	for y := 0; y < e.height; y++ {
+		off := m.YOffset(b.Min.X, b.Min.Y+y)
+		copy(e.srcY[y*e.yStride:y*e.yStride+e.width], m.Y[off:off+e.width])
+	}
+	cw, ch := (e.width+1)/2, (e.height+1)/2
+	for y := 0; y < ch; y++ {
+		off := m.COffset(b.Min.X, b.Min.Y+2*y)
+		copy(e.srcU[y*e.cStride:y*e.cStride+cw], m.Cb[off:off+cw])
+		copy(e.srcV[y*e.cStride:y*e.cStride+cw], m.Cr[off:off+cw])
+	}
+}
+
+// loadRGBA is the fast path for screen captures.
+func (e *Encoder) loadRGBA(m *image.RGBA) {
+	b := m.Rect
+	for y := 0; y < e.height; y++ {
+		pix := m.Pix[m.PixOffset(b.Min.X, b.Min.Y+y):]
+		row := e.srcY[y*e.yStride:]
+		for x := 0; x < e.width; x++ {
+			r, g, bl := int32(pix[4*x]), int32(pix[4*x+1]), int32(pix[4*x+2])
+			row[x] = rgbToY(r, g, bl)
+		}
+	}
+	cw, ch := (e.width+1)/2, (e.height+1)/2
+	for y := 0; y < ch; y++ {
+		y0 := m.Pix[m.PixOffset(b.Min.X, b.Min.Y+2*y):]
+		y1 := y0
+		if 2*y+1 < e.height {
+			y1 = m.Pix[m.PixOffset(b.Min.X, b.Min.Y+2*y+1):]
+		}
+		for x := 0; x < cw; x++ {
+			i0, i1 := 8*x, 8*x+4
+			if 2*x+1 >= e.width {
+				i1 = i0
+			}
+			r := int32(y0[i0]) + int32(y0[i1]) + int32(y1[i0]) + int32(y1[i1])
+			g := int32(y0[i0+1]) + int32(y0[i1+1]) + int32(y1[i0+1]) + int32(y1[i1+1])
+			bl := int32(y0[i0+2]) + int32(y0[i1+2]) + int32(y1[i0+2]) + int32(y1[i1+2])
+			e.srcU[y*e.cStride+x], e.srcV[y*e.cStride+x] = rgbToUV((r+2)>>2, (g+2)>>2, (bl+2)>>2)
+		}
+	}
+}
+
+// loadRGB converts any other image type, one pixel at a time.
+func (e *Encoder) loadRGB(img image.Image) {
+	b := img.Bounds()
+	at := func(x, y int) (r, g, bl int32) {
+		if x >= e.width {
+			x = e.width - 1
+		}
+		if y >= e.height {
+			y = e.height - 1
+		}
+		c := color.RGBAModel.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.RGBA)
+		return int32(c.R), int32(c.G), int32(c.B)
+	}
+	for y := 0; y < e.height; y++ {
+		row := e.srcY[y*e.yStride:]
+		for x := 0; x < e.width; x++ {
+			r, g, bl := at(x, y)
+			row[x] = rgbToY(r, g, bl)
+		}
+	}
+	cw, ch := (e.width+1)/2, (e.height+1)/2
+	for y := 0; y < ch; y++ {
+		for x := 0; x < cw; x++ {
+			var r, g, bl int32
+			for _, d := range [4][2]int{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+				pr, pg, pb := at(2*x+d[0], 2*y+d[1])
+				r, g, bl = r+pr, g+pg, bl+pb
+			}
+			e.srcU[y*e.cStride+x], e.srcV[y*e.cStride+x] = rgbToUV((r+2)>>2, (g+2)>>2, (bl+2)>>2)
+		}
+	}
+}
+
+// padPlane replicates the last column and row of a w x h region out to the
+// plane's stride and rows.
+func padPlane(p []uint8, stride, w, h, rows int) {
+	for y := 0; y < h; y++ {
+		row := p[y*stride : (y+1)*stride]
+		for x := w; x < stride; x++ {
+			row[x] = row[w-1]
+		}
+	}
+	last := p[(h-1)*stride : h*stride]
+	for y := h; y < rows; y++ {
+		copy(p[y*stride:(y+1)*stride], last)
+	}
+}
+
+func rgbToY(r, g, b int32) uint8 {
+	return uint8((66*r+129*g+25*b+128)>>8 + 16)
+}
+
+func rgbToUV(r, g, b int32) (u, v uint8) {
+	return uint8((-38*r-74*g+112*b+128)>>8 + 128), uint8((112*r-94*g-18*b+128)>>8 + 128)
+}
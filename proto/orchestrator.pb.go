@@ -82,6 +82,8 @@ const (
 	RoutingPolicy_FORCE_DEVICE_ID     RoutingPolicy_Mode = 3 // require specific device_id
 	RoutingPolicy_PREFER_LOCAL_MODEL  RoutingPolicy_Mode = 4 // prefer device with local LLM model
 	RoutingPolicy_REQUIRE_LOCAL_MODEL RoutingPolicy_Mode = 5 // fail if no local LLM model available
+	RoutingPolicy_PREFER_PLUGGED_IN   RoutingPolicy_Mode = 6 // prefer devices on external power, then best available
+	RoutingPolicy_AVOID_LOW_BATTERY   RoutingPolicy_Mode = 7 // never devices on battery below min_battery_percent, or critically hot
 )
 
 // Enum value maps for RoutingPolicy_Mode.
@@ -93,6 +95,8 @@ var (
 		3: "FORCE_DEVICE_ID",
 		4: "PREFER_LOCAL_MODEL",
 		5: "REQUIRE_LOCAL_MODEL",
+		6: "PREFER_PLUGGED_IN",
+		7: "AVOID_LOW_BATTERY",
 	}
 	RoutingPolicy_Mode_value = map[string]int32{
 		"BEST_AVAILABLE":      0,
@@ -101,6 +105,8 @@ var (
 		"FORCE_DEVICE_ID":     3,
 		"PREFER_LOCAL_MODEL":  4,
 		"REQUIRE_LOCAL_MODEL": 5,
+		"PREFER_PLUGGED_IN":   6,
+		"AVOID_LOW_BATTERY":   7,
 	}
 )
 
@@ -128,7 +134,7 @@ func (x RoutingPolicy_Mode) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use RoutingPolicy_Mode.Descriptor instead.
 func (RoutingPolicy_Mode) EnumDescriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{14, 0}
+	return file_orchestrator_proto_rawDescGZIP(), []int{18, 0}
 }
 
 type Empty struct {
@@ -463,8 +469,11 @@ type DeviceInfo struct {
 	HasLocalModel     bool   `protobuf:"varint,14,opt,name=has_local_model,json=hasLocalModel,proto3" json:"has_local_model,omitempty"`            // device has Ollama/chat running
 	LocalModelName    string `protobuf:"bytes,15,opt,name=local_model_name,json=localModelName,proto3" json:"local_model_name,omitempty"`          // loaded model (e.g., "llama3.2:3b")
 	LocalChatEndpoint string `protobuf:"bytes,16,opt,name=local_chat_endpoint,json=localChatEndpoint,proto3" json:"local_chat_endpoint,omitempty"` // URL to chat service (e.g., "http://192.168.1.38:11434")
-	unknownFields     protoimpl.UnknownFields
-	sizeCache         protoimpl.SizeCache
+	// Task kinds the worker runs; empty = the four built-in kinds of older workers
+	TaskKinds     []string    `protobuf:"bytes,17,rep,name=task_kinds,json=taskKinds,proto3" json:"task_kinds,omitempty"`
+	Power         *PowerState `protobuf:"bytes,18,opt,name=power,proto3" json:"power,omitempty"` // battery and thermal state (unset = unknown)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *DeviceInfo) Reset() {
@@ -609,6 +618,105 @@ func (x *DeviceInfo) GetLocalChatEndpoint() string {
 	return ""
 }
 
+func (x *DeviceInfo) GetTaskKinds() []string {
+	if x != nil {
+		return x.TaskKinds
+	}
+	return nil
+}
+
+func (x *DeviceInfo) GetPower() *PowerState {
+	if x != nil {
+		return x.Power
+	}
+	return nil
+}
+
+// PowerState is a device's battery, charger and thermal condition
+type PowerState struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	HasBattery     bool                   `protobuf:"varint,1,opt,name=has_battery,json=hasBattery,proto3" json:"has_battery,omitempty"`
+	BatteryPercent float64                `protobuf:"fixed64,2,opt,name=battery_percent,json=batteryPercent,proto3" json:"battery_percent,omitempty"` // 0..100, if has_battery
+	Charging       bool                   `protobuf:"varint,3,opt,name=charging,proto3" json:"charging,omitempty"`
+	PluggedIn      bool                   `protobuf:"varint,4,opt,name=plugged_in,json=pluggedIn,proto3" json:"plugged_in,omitempty"`           // on external power; true without a battery
+	ThermalState   string                 `protobuf:"bytes,5,opt,name=thermal_state,json=thermalState,proto3" json:"thermal_state,omitempty"`   // "nominal", "fair", "serious", "critical"; "" = unknown
+	TemperatureC   float64                `protobuf:"fixed64,6,opt,name=temperature_c,json=temperatureC,proto3" json:"temperature_c,omitempty"` // hottest sensor; 0 = unknown
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *PowerState) Reset() {
+	*x = PowerState{}
+	mi := &file_orchestrator_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PowerState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PowerState) ProtoMessage() {}
+
+func (x *PowerState) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PowerState.ProtoReflect.Descriptor instead.
+func (*PowerState) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *PowerState) GetHasBattery() bool {
+	if x != nil {
+		return x.HasBattery
+	}
+	return false
+}
+
+func (x *PowerState) GetBatteryPercent() float64 {
+	if x != nil {
+		return x.BatteryPercent
+	}
+	return 0
+}
+
+func (x *PowerState) GetCharging() bool {
+	if x != nil {
+		return x.Charging
+	}
+	return false
+}
+
+func (x *PowerState) GetPluggedIn() bool {
+	if x != nil {
+		return x.PluggedIn
+	}
+	return false
+}
+
+func (x *PowerState) GetThermalState() string {
+	if x != nil {
+		return x.ThermalState
+	}
+	return ""
+}
+
+func (x *PowerState) GetTemperatureC() float64 {
+	if x != nil {
+		return x.TemperatureC
+	}
+	return 0
+}
+
 type DeviceAck struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Ok            bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
@@ -619,7 +727,7 @@ type DeviceAck struct {
 
 func (x *DeviceAck) Reset() {
 	*x = DeviceAck{}
-	mi := &file_orchestrator_proto_msgTypes[7]
+	mi := &file_orchestrator_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -631,7 +739,7 @@ func (x *DeviceAck) String() string {
 func (*DeviceAck) ProtoMessage() {}
 
 func (x *DeviceAck) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[7]
+	mi := &file_orchestrator_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -644,7 +752,7 @@ func (x *DeviceAck) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeviceAck.ProtoReflect.Descriptor instead.
 func (*DeviceAck) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{7}
+	return file_orchestrator_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *DeviceAck) GetOk() bool {
@@ -669,18 +777,20 @@ type DeviceStatus struct {
 	MemUsedMb  uint64                 `protobuf:"varint,4,opt,name=mem_used_mb,json=memUsedMb,proto3" json:"mem_used_mb,omitempty"`
 	MemTotalMb uint64                 `protobuf:"varint,5,opt,name=mem_total_mb,json=memTotalMb,proto3" json:"mem_total_mb,omitempty"`
 	// GPU/NPU metrics for activity tracking
-	GpuLoad       float64 `protobuf:"fixed64,6,opt,name=gpu_load,json=gpuLoad,proto3" json:"gpu_load,omitempty"` // 0..1, or -1 if unavailable
-	GpuMemUsedMb  uint64  `protobuf:"varint,7,opt,name=gpu_mem_used_mb,json=gpuMemUsedMb,proto3" json:"gpu_mem_used_mb,omitempty"`
-	GpuMemTotalMb uint64  `protobuf:"varint,8,opt,name=gpu_mem_total_mb,json=gpuMemTotalMb,proto3" json:"gpu_mem_total_mb,omitempty"`
-	NpuLoad       float64 `protobuf:"fixed64,9,opt,name=npu_load,json=npuLoad,proto3" json:"npu_load,omitempty"`             // 0..1, or -1 if unavailable
-	TimestampMs   int64   `protobuf:"varint,10,opt,name=timestamp_ms,json=timestampMs,proto3" json:"timestamp_ms,omitempty"` // sample timestamp (unix milliseconds)
+	GpuLoad       float64      `protobuf:"fixed64,6,opt,name=gpu_load,json=gpuLoad,proto3" json:"gpu_load,omitempty"` // 0..1, or -1 if unavailable
+	GpuMemUsedMb  uint64       `protobuf:"varint,7,opt,name=gpu_mem_used_mb,json=gpuMemUsedMb,proto3" json:"gpu_mem_used_mb,omitempty"`
+	GpuMemTotalMb uint64       `protobuf:"varint,8,opt,name=gpu_mem_total_mb,json=gpuMemTotalMb,proto3" json:"gpu_mem_total_mb,omitempty"`
+	NpuLoad       float64      `protobuf:"fixed64,9,opt,name=npu_load,json=npuLoad,proto3" json:"npu_load,omitempty"`             // 0..1, or -1 if unavailable
+	TimestampMs   int64        `protobuf:"varint,10,opt,name=timestamp_ms,json=timestampMs,proto3" json:"timestamp_ms,omitempty"` // sample timestamp (unix milliseconds)
+	Power         *PowerState  `protobuf:"bytes,11,opt,name=power,proto3" json:"power,omitempty"`                                 // battery and thermal state (unset = unknown)
+	Links         []*LinkStats `protobuf:"bytes,12,rep,name=links,proto3" json:"links,omitempty"`                                 // links measured from this device to its peers
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *DeviceStatus) Reset() {
 	*x = DeviceStatus{}
-	mi := &file_orchestrator_proto_msgTypes[8]
+	mi := &file_orchestrator_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -692,7 +802,7 @@ func (x *DeviceStatus) String() string {
 func (*DeviceStatus) ProtoMessage() {}
 
 func (x *DeviceStatus) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[8]
+	mi := &file_orchestrator_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -705,7 +815,7 @@ func (x *DeviceStatus) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeviceStatus.ProtoReflect.Descriptor instead.
 func (*DeviceStatus) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{8}
+	return file_orchestrator_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *DeviceStatus) GetDeviceId() string {
@@ -778,6 +888,20 @@ func (x *DeviceStatus) GetTimestampMs() int64 {
 	return 0
 }
 
+func (x *DeviceStatus) GetPower() *PowerState {
+	if x != nil {
+		return x.Power
+	}
+	return nil
+}
+
+func (x *DeviceStatus) GetLinks() []*LinkStats {
+	if x != nil {
+		return x.Links
+	}
+	return nil
+}
+
 type ListDevicesRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -786,7 +910,7 @@ type ListDevicesRequest struct {
 
 func (x *ListDevicesRequest) Reset() {
 	*x = ListDevicesRequest{}
-	mi := &file_orchestrator_proto_msgTypes[9]
+	mi := &file_orchestrator_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -798,7 +922,7 @@ func (x *ListDevicesRequest) String() string {
 func (*ListDevicesRequest) ProtoMessage() {}
 
 func (x *ListDevicesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[9]
+	mi := &file_orchestrator_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -811,7 +935,7 @@ func (x *ListDevicesRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListDevicesRequest.ProtoReflect.Descriptor instead.
 func (*ListDevicesRequest) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{9}
+	return file_orchestrator_proto_rawDescGZIP(), []int{10}
 }
 
 type ListDevicesResponse struct {
@@ -823,7 +947,7 @@ type ListDevicesResponse struct {
 
 func (x *ListDevicesResponse) Reset() {
 	*x = ListDevicesResponse{}
-	mi := &file_orchestrator_proto_msgTypes[10]
+	mi := &file_orchestrator_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -835,7 +959,7 @@ func (x *ListDevicesResponse) String() string {
 func (*ListDevicesResponse) ProtoMessage() {}
 
 func (x *ListDevicesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[10]
+	mi := &file_orchestrator_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -848,7 +972,7 @@ func (x *ListDevicesResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ListDevicesResponse.ProtoReflect.Descriptor instead.
 func (*ListDevicesResponse) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{10}
+	return file_orchestrator_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *ListDevicesResponse) GetDevices() []*DeviceInfo {
@@ -869,7 +993,7 @@ type AITaskRequest struct {
 
 func (x *AITaskRequest) Reset() {
 	*x = AITaskRequest{}
-	mi := &file_orchestrator_proto_msgTypes[11]
+	mi := &file_orchestrator_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -881,7 +1005,7 @@ func (x *AITaskRequest) String() string {
 func (*AITaskRequest) ProtoMessage() {}
 
 func (x *AITaskRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[11]
+	mi := &file_orchestrator_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -894,7 +1018,7 @@ func (x *AITaskRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AITaskRequest.ProtoReflect.Descriptor instead.
 func (*AITaskRequest) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{11}
+	return file_orchestrator_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *AITaskRequest) GetSessionId() string {
@@ -930,7 +1054,7 @@ type AITaskResponse struct {
 
 func (x *AITaskResponse) Reset() {
 	*x = AITaskResponse{}
-	mi := &file_orchestrator_proto_msgTypes[12]
+	mi := &file_orchestrator_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -942,7 +1066,7 @@ func (x *AITaskResponse) String() string {
 func (*AITaskResponse) ProtoMessage() {}
 
 func (x *AITaskResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[12]
+	mi := &file_orchestrator_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -955,7 +1079,7 @@ func (x *AITaskResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AITaskResponse.ProtoReflect.Descriptor instead.
 func (*AITaskResponse) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{12}
+	return file_orchestrator_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *AITaskResponse) GetSelectedDeviceId() string {
@@ -997,7 +1121,7 @@ type HealthStatus struct {
 
 func (x *HealthStatus) Reset() {
 	*x = HealthStatus{}
-	mi := &file_orchestrator_proto_msgTypes[13]
+	mi := &file_orchestrator_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1009,7 +1133,7 @@ func (x *HealthStatus) String() string {
 func (*HealthStatus) ProtoMessage() {}
 
 func (x *HealthStatus) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[13]
+	mi := &file_orchestrator_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1022,7 +1146,7 @@ func (x *HealthStatus) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthStatus.ProtoReflect.Descriptor instead.
 func (*HealthStatus) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{13}
+	return file_orchestrator_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *HealthStatus) GetDeviceId() string {
@@ -1046,29 +1170,28 @@ func (x *HealthStatus) GetMessage() string {
 	return ""
 }
 
-type RoutingPolicy struct {
+type PingRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Mode          RoutingPolicy_Mode     `protobuf:"varint,1,opt,name=mode,proto3,enum=edgemesh.RoutingPolicy_Mode" json:"mode,omitempty"`
-	DeviceId      string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"` // used if FORCE_DEVICE_ID
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"` // sender's device ID
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RoutingPolicy) Reset() {
-	*x = RoutingPolicy{}
-	mi := &file_orchestrator_proto_msgTypes[14]
+func (x *PingRequest) Reset() {
+	*x = PingRequest{}
+	mi := &file_orchestrator_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RoutingPolicy) String() string {
+func (x *PingRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RoutingPolicy) ProtoMessage() {}
+func (*PingRequest) ProtoMessage() {}
 
-func (x *RoutingPolicy) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[14]
+func (x *PingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1079,50 +1202,41 @@ func (x *RoutingPolicy) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RoutingPolicy.ProtoReflect.Descriptor instead.
-func (*RoutingPolicy) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{14}
-}
-
-func (x *RoutingPolicy) GetMode() RoutingPolicy_Mode {
-	if x != nil {
-		return x.Mode
-	}
-	return RoutingPolicy_BEST_AVAILABLE
+// Deprecated: Use PingRequest.ProtoReflect.Descriptor instead.
+func (*PingRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{15}
 }
 
-func (x *RoutingPolicy) GetDeviceId() string {
+func (x *PingRequest) GetDeviceId() string {
 	if x != nil {
 		return x.DeviceId
 	}
 	return ""
 }
 
-type RoutedCommandRequest struct {
+type PingResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
-	Policy        *RoutingPolicy         `protobuf:"bytes,2,opt,name=policy,proto3" json:"policy,omitempty"`
-	Command       string                 `protobuf:"bytes,3,opt,name=command,proto3" json:"command,omitempty"`
-	Args          []string               `protobuf:"bytes,4,rep,name=args,proto3" json:"args,omitempty"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`                // responder's device ID
+	ServerTimeMs  int64                  `protobuf:"varint,2,opt,name=server_time_ms,json=serverTimeMs,proto3" json:"server_time_ms,omitempty"` // unix milliseconds
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RoutedCommandRequest) Reset() {
-	*x = RoutedCommandRequest{}
-	mi := &file_orchestrator_proto_msgTypes[15]
+func (x *PingResponse) Reset() {
+	*x = PingResponse{}
+	mi := &file_orchestrator_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RoutedCommandRequest) String() string {
+func (x *PingResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RoutedCommandRequest) ProtoMessage() {}
+func (*PingResponse) ProtoMessage() {}
 
-func (x *RoutedCommandRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[15]
+func (x *PingResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1133,66 +1247,54 @@ func (x *RoutedCommandRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RoutedCommandRequest.ProtoReflect.Descriptor instead.
-func (*RoutedCommandRequest) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{15}
-}
-
-func (x *RoutedCommandRequest) GetSessionId() string {
-	if x != nil {
-		return x.SessionId
-	}
-	return ""
-}
-
-func (x *RoutedCommandRequest) GetPolicy() *RoutingPolicy {
-	if x != nil {
-		return x.Policy
-	}
-	return nil
+// Deprecated: Use PingResponse.ProtoReflect.Descriptor instead.
+func (*PingResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{16}
 }
 
-func (x *RoutedCommandRequest) GetCommand() string {
+func (x *PingResponse) GetDeviceId() string {
 	if x != nil {
-		return x.Command
+		return x.DeviceId
 	}
 	return ""
 }
 
-func (x *RoutedCommandRequest) GetArgs() []string {
+func (x *PingResponse) GetServerTimeMs() int64 {
 	if x != nil {
-		return x.Args
+		return x.ServerTimeMs
 	}
-	return nil
+	return 0
 }
 
-type RoutedCommandResponse struct {
-	state              protoimpl.MessageState `protogen:"open.v1"`
-	Output             *CommandResponse       `protobuf:"bytes,1,opt,name=output,proto3" json:"output,omitempty"`
-	SelectedDeviceId   string                 `protobuf:"bytes,2,opt,name=selected_device_id,json=selectedDeviceId,proto3" json:"selected_device_id,omitempty"`
-	SelectedDeviceName string                 `protobuf:"bytes,3,opt,name=selected_device_name,json=selectedDeviceName,proto3" json:"selected_device_name,omitempty"`
-	SelectedDeviceAddr string                 `protobuf:"bytes,4,opt,name=selected_device_addr,json=selectedDeviceAddr,proto3" json:"selected_device_addr,omitempty"`
-	TotalTimeMs        float64                `protobuf:"fixed64,5,opt,name=total_time_ms,json=totalTimeMs,proto3" json:"total_time_ms,omitempty"`          // includes forwarding overhead
-	ExecutedLocally    bool                   `protobuf:"varint,6,opt,name=executed_locally,json=executedLocally,proto3" json:"executed_locally,omitempty"` // true if ran on the coordinator itself
-	unknownFields      protoimpl.UnknownFields
-	sizeCache          protoimpl.SizeCache
+// LinkStats is a measured network link from one device to another
+type LinkStats struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	FromDeviceId     string                 `protobuf:"bytes,1,opt,name=from_device_id,json=fromDeviceId,proto3" json:"from_device_id,omitempty"`
+	ToDeviceId       string                 `protobuf:"bytes,2,opt,name=to_device_id,json=toDeviceId,proto3" json:"to_device_id,omitempty"`
+	RttMs            float64                `protobuf:"fixed64,3,opt,name=rtt_ms,json=rttMs,proto3" json:"rtt_ms,omitempty"`                                     // round-trip time, moving average
+	BytesPerSec      float64                `protobuf:"fixed64,4,opt,name=bytes_per_sec,json=bytesPerSec,proto3" json:"bytes_per_sec,omitempty"`                 // bulk HTTP throughput, moving average; 0 = unmeasured
+	MeasuredAtUnixMs int64                  `protobuf:"varint,5,opt,name=measured_at_unix_ms,json=measuredAtUnixMs,proto3" json:"measured_at_unix_ms,omitempty"` // last successful probe
+	Samples          int32                  `protobuf:"varint,6,opt,name=samples,proto3" json:"samples,omitempty"`                                               // successful probes
+	Error            string                 `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`                                                    // last probe's error, if it failed
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
-func (x *RoutedCommandResponse) Reset() {
-	*x = RoutedCommandResponse{}
-	mi := &file_orchestrator_proto_msgTypes[16]
+func (x *LinkStats) Reset() {
+	*x = LinkStats{}
+	mi := &file_orchestrator_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RoutedCommandResponse) String() string {
+func (x *LinkStats) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RoutedCommandResponse) ProtoMessage() {}
+func (*LinkStats) ProtoMessage() {}
 
-func (x *RoutedCommandResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[16]
+func (x *LinkStats) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1203,75 +1305,84 @@ func (x *RoutedCommandResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RoutedCommandResponse.ProtoReflect.Descriptor instead.
-func (*RoutedCommandResponse) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{16}
+// Deprecated: Use LinkStats.ProtoReflect.Descriptor instead.
+func (*LinkStats) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{17}
 }
 
-func (x *RoutedCommandResponse) GetOutput() *CommandResponse {
+func (x *LinkStats) GetFromDeviceId() string {
 	if x != nil {
-		return x.Output
+		return x.FromDeviceId
 	}
-	return nil
+	return ""
 }
 
-func (x *RoutedCommandResponse) GetSelectedDeviceId() string {
+func (x *LinkStats) GetToDeviceId() string {
 	if x != nil {
-		return x.SelectedDeviceId
+		return x.ToDeviceId
 	}
 	return ""
 }
 
-func (x *RoutedCommandResponse) GetSelectedDeviceName() string {
+func (x *LinkStats) GetRttMs() float64 {
 	if x != nil {
-		return x.SelectedDeviceName
+		return x.RttMs
 	}
-	return ""
+	return 0
 }
 
-func (x *RoutedCommandResponse) GetSelectedDeviceAddr() string {
+func (x *LinkStats) GetBytesPerSec() float64 {
 	if x != nil {
-		return x.SelectedDeviceAddr
+		return x.BytesPerSec
 	}
-	return ""
+	return 0
 }
 
-func (x *RoutedCommandResponse) GetTotalTimeMs() float64 {
+func (x *LinkStats) GetMeasuredAtUnixMs() int64 {
 	if x != nil {
-		return x.TotalTimeMs
+		return x.MeasuredAtUnixMs
 	}
 	return 0
 }
 
-func (x *RoutedCommandResponse) GetExecutedLocally() bool {
+func (x *LinkStats) GetSamples() int32 {
 	if x != nil {
-		return x.ExecutedLocally
+		return x.Samples
 	}
-	return false
+	return 0
 }
 
-type JobId struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *LinkStats) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
 }
 
-func (x *JobId) Reset() {
-	*x = JobId{}
-	mi := &file_orchestrator_proto_msgTypes[17]
+type RoutingPolicy struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Mode              RoutingPolicy_Mode     `protobuf:"varint,1,opt,name=mode,proto3,enum=edgemesh.RoutingPolicy_Mode" json:"mode,omitempty"`
+	DeviceId          string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`                                // used if FORCE_DEVICE_ID
+	MinBatteryPercent float64                `protobuf:"fixed64,3,opt,name=min_battery_percent,json=minBatteryPercent,proto3" json:"min_battery_percent,omitempty"` // used if AVOID_LOW_BATTERY (0 = 20)
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *RoutingPolicy) Reset() {
+	*x = RoutingPolicy{}
+	mi := &file_orchestrator_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *JobId) String() string {
+func (x *RoutingPolicy) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*JobId) ProtoMessage() {}
+func (*RoutingPolicy) ProtoMessage() {}
 
-func (x *JobId) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[17]
+func (x *RoutingPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1282,44 +1393,57 @@ func (x *JobId) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use JobId.ProtoReflect.Descriptor instead.
-func (*JobId) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{17}
+// Deprecated: Use RoutingPolicy.ProtoReflect.Descriptor instead.
+func (*RoutingPolicy) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{18}
 }
 
-func (x *JobId) GetJobId() string {
+func (x *RoutingPolicy) GetMode() RoutingPolicy_Mode {
 	if x != nil {
-		return x.JobId
+		return x.Mode
+	}
+	return RoutingPolicy_BEST_AVAILABLE
+}
+
+func (x *RoutingPolicy) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
 	}
 	return ""
 }
 
-type JobRequest struct {
+func (x *RoutingPolicy) GetMinBatteryPercent() float64 {
+	if x != nil {
+		return x.MinBatteryPercent
+	}
+	return 0
+}
+
+type RoutedCommandRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
-	Text          string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`                                // user request description
-	MaxWorkers    int32                  `protobuf:"varint,3,opt,name=max_workers,json=maxWorkers,proto3" json:"max_workers,omitempty"` // 0 = use all available devices
-	Plan          *Plan                  `protobuf:"bytes,4,opt,name=plan,proto3" json:"plan,omitempty"`                                // optional: explicit execution plan
-	Reduce        *ReduceSpec            `protobuf:"bytes,5,opt,name=reduce,proto3" json:"reduce,omitempty"`                            // optional: how to combine results
+	Policy        *RoutingPolicy         `protobuf:"bytes,2,opt,name=policy,proto3" json:"policy,omitempty"`
+	Command       string                 `protobuf:"bytes,3,opt,name=command,proto3" json:"command,omitempty"`
+	Args          []string               `protobuf:"bytes,4,rep,name=args,proto3" json:"args,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *JobRequest) Reset() {
-	*x = JobRequest{}
-	mi := &file_orchestrator_proto_msgTypes[18]
+func (x *RoutedCommandRequest) Reset() {
+	*x = RoutedCommandRequest{}
+	mi := &file_orchestrator_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *JobRequest) String() string {
+func (x *RoutedCommandRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*JobRequest) ProtoMessage() {}
+func (*RoutedCommandRequest) ProtoMessage() {}
 
-func (x *JobRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[18]
+func (x *RoutedCommandRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1330,69 +1454,66 @@ func (x *JobRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use JobRequest.ProtoReflect.Descriptor instead.
-func (*JobRequest) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{18}
+// Deprecated: Use RoutedCommandRequest.ProtoReflect.Descriptor instead.
+func (*RoutedCommandRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{19}
 }
 
-func (x *JobRequest) GetSessionId() string {
+func (x *RoutedCommandRequest) GetSessionId() string {
 	if x != nil {
 		return x.SessionId
 	}
 	return ""
 }
 
-func (x *JobRequest) GetText() string {
-	if x != nil {
-		return x.Text
-	}
-	return ""
-}
-
-func (x *JobRequest) GetMaxWorkers() int32 {
+func (x *RoutedCommandRequest) GetPolicy() *RoutingPolicy {
 	if x != nil {
-		return x.MaxWorkers
+		return x.Policy
 	}
-	return 0
+	return nil
 }
 
-func (x *JobRequest) GetPlan() *Plan {
+func (x *RoutedCommandRequest) GetCommand() string {
 	if x != nil {
-		return x.Plan
+		return x.Command
 	}
-	return nil
+	return ""
 }
 
-func (x *JobRequest) GetReduce() *ReduceSpec {
+func (x *RoutedCommandRequest) GetArgs() []string {
 	if x != nil {
-		return x.Reduce
+		return x.Args
 	}
 	return nil
 }
 
-// Planning structures
-type Plan struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Groups        []*TaskGroup           `protobuf:"bytes,1,rep,name=groups,proto3" json:"groups,omitempty"` // groups execute sequentially
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+type RoutedCommandResponse struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Output             *CommandResponse       `protobuf:"bytes,1,opt,name=output,proto3" json:"output,omitempty"`
+	SelectedDeviceId   string                 `protobuf:"bytes,2,opt,name=selected_device_id,json=selectedDeviceId,proto3" json:"selected_device_id,omitempty"`
+	SelectedDeviceName string                 `protobuf:"bytes,3,opt,name=selected_device_name,json=selectedDeviceName,proto3" json:"selected_device_name,omitempty"`
+	SelectedDeviceAddr string                 `protobuf:"bytes,4,opt,name=selected_device_addr,json=selectedDeviceAddr,proto3" json:"selected_device_addr,omitempty"`
+	TotalTimeMs        float64                `protobuf:"fixed64,5,opt,name=total_time_ms,json=totalTimeMs,proto3" json:"total_time_ms,omitempty"`          // includes forwarding overhead
+	ExecutedLocally    bool                   `protobuf:"varint,6,opt,name=executed_locally,json=executedLocally,proto3" json:"executed_locally,omitempty"` // true if ran on the coordinator itself
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
 }
 
-func (x *Plan) Reset() {
-	*x = Plan{}
-	mi := &file_orchestrator_proto_msgTypes[19]
+func (x *RoutedCommandResponse) Reset() {
+	*x = RoutedCommandResponse{}
+	mi := &file_orchestrator_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Plan) String() string {
+func (x *RoutedCommandResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Plan) ProtoMessage() {}
+func (*RoutedCommandResponse) ProtoMessage() {}
 
-func (x *Plan) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[19]
+func (x *RoutedCommandResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1403,41 +1524,75 @@ func (x *Plan) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Plan.ProtoReflect.Descriptor instead.
-func (*Plan) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{19}
+// Deprecated: Use RoutedCommandResponse.ProtoReflect.Descriptor instead.
+func (*RoutedCommandResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{20}
 }
 
-func (x *Plan) GetGroups() []*TaskGroup {
+func (x *RoutedCommandResponse) GetOutput() *CommandResponse {
 	if x != nil {
-		return x.Groups
+		return x.Output
 	}
 	return nil
 }
 
-type TaskGroup struct {
+func (x *RoutedCommandResponse) GetSelectedDeviceId() string {
+	if x != nil {
+		return x.SelectedDeviceId
+	}
+	return ""
+}
+
+func (x *RoutedCommandResponse) GetSelectedDeviceName() string {
+	if x != nil {
+		return x.SelectedDeviceName
+	}
+	return ""
+}
+
+func (x *RoutedCommandResponse) GetSelectedDeviceAddr() string {
+	if x != nil {
+		return x.SelectedDeviceAddr
+	}
+	return ""
+}
+
+func (x *RoutedCommandResponse) GetTotalTimeMs() float64 {
+	if x != nil {
+		return x.TotalTimeMs
+	}
+	return 0
+}
+
+func (x *RoutedCommandResponse) GetExecutedLocally() bool {
+	if x != nil {
+		return x.ExecutedLocally
+	}
+	return false
+}
+
+type JobId struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Index         int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"` // execution order (0 = first)
-	Tasks         []*TaskSpec            `protobuf:"bytes,2,rep,name=tasks,proto3" json:"tasks,omitempty"`  // tasks in group run in parallel
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *TaskGroup) Reset() {
-	*x = TaskGroup{}
-	mi := &file_orchestrator_proto_msgTypes[20]
+func (x *JobId) Reset() {
+	*x = JobId{}
+	mi := &file_orchestrator_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *TaskGroup) String() string {
+func (x *JobId) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TaskGroup) ProtoMessage() {}
+func (*JobId) ProtoMessage() {}
 
-func (x *TaskGroup) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[20]
+func (x *JobId) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1448,53 +1603,47 @@ func (x *TaskGroup) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TaskGroup.ProtoReflect.Descriptor instead.
-func (*TaskGroup) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{20}
-}
-
-func (x *TaskGroup) GetIndex() int32 {
-	if x != nil {
-		return x.Index
-	}
-	return 0
+// Deprecated: Use JobId.ProtoReflect.Descriptor instead.
+func (*JobId) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{21}
 }
 
-func (x *TaskGroup) GetTasks() []*TaskSpec {
+func (x *JobId) GetJobId() string {
 	if x != nil {
-		return x.Tasks
+		return x.JobId
 	}
-	return nil
+	return ""
 }
 
-type TaskSpec struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	TaskId         string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
-	Kind           string                 `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"` // "SYSINFO", "ECHO", "LLM_GENERATE", etc.
-	Input          string                 `protobuf:"bytes,3,opt,name=input,proto3" json:"input,omitempty"`
-	TargetDeviceId string                 `protobuf:"bytes,4,opt,name=target_device_id,json=targetDeviceId,proto3" json:"target_device_id,omitempty"` // empty = let orchestrator assign
-	// LLM_GENERATE parameters (for cost estimation)
-	PromptTokens    int32 `protobuf:"varint,5,opt,name=prompt_tokens,json=promptTokens,proto3" json:"prompt_tokens,omitempty"`            // estimated prompt tokens
-	MaxOutputTokens int32 `protobuf:"varint,6,opt,name=max_output_tokens,json=maxOutputTokens,proto3" json:"max_output_tokens,omitempty"` // max output tokens to generate
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+type JobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Text          string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`                                // user request description
+	MaxWorkers    int32                  `protobuf:"varint,3,opt,name=max_workers,json=maxWorkers,proto3" json:"max_workers,omitempty"` // 0 = use all available devices
+	Plan          *Plan                  `protobuf:"bytes,4,opt,name=plan,proto3" json:"plan,omitempty"`                                // optional: explicit execution plan
+	Reduce        *ReduceSpec            `protobuf:"bytes,5,opt,name=reduce,proto3" json:"reduce,omitempty"`                            // optional: how to combine results
+	FanOut        *FanOut                `protobuf:"bytes,6,opt,name=fan_out,json=fanOut,proto3" json:"fan_out,omitempty"`              // optional: one task per matching device instead of a plan
+	Map           *MapSpec               `protobuf:"bytes,7,opt,name=map,proto3" json:"map,omitempty"`                                  // optional: shard items across LLM-capable devices instead of a plan
+	Priority      int32                  `protobuf:"varint,8,opt,name=priority,proto3" json:"priority,omitempty"`                       // higher runs first: -10 low, 0 normal, 10 high, 20 interactive
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *TaskSpec) Reset() {
-	*x = TaskSpec{}
-	mi := &file_orchestrator_proto_msgTypes[21]
+func (x *JobRequest) Reset() {
+	*x = JobRequest{}
+	mi := &file_orchestrator_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *TaskSpec) String() string {
+func (x *JobRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TaskSpec) ProtoMessage() {}
+func (*JobRequest) ProtoMessage() {}
 
-func (x *TaskSpec) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[21]
+func (x *JobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1505,120 +1654,92 @@ func (x *TaskSpec) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TaskSpec.ProtoReflect.Descriptor instead.
-func (*TaskSpec) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{21}
+// Deprecated: Use JobRequest.ProtoReflect.Descriptor instead.
+func (*JobRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{22}
 }
 
-func (x *TaskSpec) GetTaskId() string {
+func (x *JobRequest) GetSessionId() string {
 	if x != nil {
-		return x.TaskId
+		return x.SessionId
 	}
 	return ""
 }
 
-func (x *TaskSpec) GetKind() string {
+func (x *JobRequest) GetText() string {
 	if x != nil {
-		return x.Kind
+		return x.Text
 	}
 	return ""
 }
 
-func (x *TaskSpec) GetInput() string {
+func (x *JobRequest) GetMaxWorkers() int32 {
 	if x != nil {
-		return x.Input
+		return x.MaxWorkers
 	}
-	return ""
+	return 0
 }
 
-func (x *TaskSpec) GetTargetDeviceId() string {
+func (x *JobRequest) GetPlan() *Plan {
 	if x != nil {
-		return x.TargetDeviceId
+		return x.Plan
 	}
-	return ""
+	return nil
 }
 
-func (x *TaskSpec) GetPromptTokens() int32 {
+func (x *JobRequest) GetReduce() *ReduceSpec {
 	if x != nil {
-		return x.PromptTokens
+		return x.Reduce
 	}
-	return 0
+	return nil
 }
 
-func (x *TaskSpec) GetMaxOutputTokens() int32 {
+func (x *JobRequest) GetFanOut() *FanOut {
 	if x != nil {
-		return x.MaxOutputTokens
+		return x.FanOut
 	}
-	return 0
-}
-
-type ReduceSpec struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Kind          string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"` // "CONCAT" for now
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
-}
-
-func (x *ReduceSpec) Reset() {
-	*x = ReduceSpec{}
-	mi := &file_orchestrator_proto_msgTypes[22]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
-}
-
-func (x *ReduceSpec) String() string {
-	return protoimpl.X.MessageStringOf(x)
+	return nil
 }
 
-func (*ReduceSpec) ProtoMessage() {}
-
-func (x *ReduceSpec) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[22]
+func (x *JobRequest) GetMap() *MapSpec {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.Map
 	}
-	return mi.MessageOf(x)
-}
-
-// Deprecated: Use ReduceSpec.ProtoReflect.Descriptor instead.
-func (*ReduceSpec) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{22}
+	return nil
 }
 
-func (x *ReduceSpec) GetKind() string {
+func (x *JobRequest) GetPriority() int32 {
 	if x != nil {
-		return x.Kind
+		return x.Priority
 	}
-	return ""
+	return 0
 }
 
-type JobInfo struct {
+// FanOut runs the same task on every device that matches
+type FanOut struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
-	CreatedAt     int64                  `protobuf:"varint,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	Summary       string                 `protobuf:"bytes,3,opt,name=summary,proto3" json:"summary,omitempty"` // e.g. "distributed to N devices"
+	Kind          string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"` // task kind, e.g. "SHELL"
+	Input         string                 `protobuf:"bytes,2,opt,name=input,proto3" json:"input,omitempty"`
+	Platforms     []string               `protobuf:"bytes,3,rep,name=platforms,proto3" json:"platforms,omitempty"`       // GOOS values; any if empty
+	Capabilities  []string               `protobuf:"bytes,4,rep,name=capabilities,proto3" json:"capabilities,omitempty"` // "gpu", "npu", "local_model", "screen_capture"; all required
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *JobInfo) Reset() {
-	*x = JobInfo{}
+func (x *FanOut) Reset() {
+	*x = FanOut{}
 	mi := &file_orchestrator_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *JobInfo) String() string {
+func (x *FanOut) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*JobInfo) ProtoMessage() {}
+func (*FanOut) ProtoMessage() {}
 
-func (x *JobInfo) ProtoReflect() protoreflect.Message {
+func (x *FanOut) ProtoReflect() protoreflect.Message {
 	mi := &file_orchestrator_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1630,58 +1751,69 @@ func (x *JobInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use JobInfo.ProtoReflect.Descriptor instead.
-func (*JobInfo) Descriptor() ([]byte, []int) {
+// Deprecated: Use FanOut.ProtoReflect.Descriptor instead.
+func (*FanOut) Descriptor() ([]byte, []int) {
 	return file_orchestrator_proto_rawDescGZIP(), []int{23}
 }
 
-func (x *JobInfo) GetJobId() string {
+func (x *FanOut) GetKind() string {
 	if x != nil {
-		return x.JobId
+		return x.Kind
 	}
 	return ""
 }
 
-func (x *JobInfo) GetCreatedAt() int64 {
+func (x *FanOut) GetInput() string {
 	if x != nil {
-		return x.CreatedAt
+		return x.Input
 	}
-	return 0
+	return ""
 }
 
-func (x *JobInfo) GetSummary() string {
+func (x *FanOut) GetPlatforms() []string {
 	if x != nil {
-		return x.Summary
+		return x.Platforms
 	}
-	return ""
+	return nil
 }
 
-type JobStatus struct {
+func (x *FanOut) GetCapabilities() []string {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+// MapSpec runs LLM_GENERATE or EMBED once per item, sharded across devices
+// in proportion to their throughput; items come inline or from a file or
+// directory in a device's shared folder
+type MapSpec struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
-	State         string                 `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"` // QUEUED, RUNNING, DONE, FAILED
-	Tasks         []*TaskStatus          `protobuf:"bytes,3,rep,name=tasks,proto3" json:"tasks,omitempty"`
-	FinalResult   string                 `protobuf:"bytes,4,opt,name=final_result,json=finalResult,proto3" json:"final_result,omitempty"`     // concatenated results when DONE
-	CurrentGroup  int32                  `protobuf:"varint,5,opt,name=current_group,json=currentGroup,proto3" json:"current_group,omitempty"` // which group is currently executing
-	TotalGroups   int32                  `protobuf:"varint,6,opt,name=total_groups,json=totalGroups,proto3" json:"total_groups,omitempty"`    // total number of groups in plan
+	Kind          string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`                                // "LLM_GENERATE" (default) or "EMBED"
+	Prompt        string                 `protobuf:"bytes,2,opt,name=prompt,proto3" json:"prompt,omitempty"`                            // LLM_GENERATE: "{{item}}" is replaced by each item, else the item is appended
+	Items         []string               `protobuf:"bytes,3,rep,name=items,proto3" json:"items,omitempty"`                              // inline items
+	DeviceId      string                 `protobuf:"bytes,4,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`        // device holding path (empty = coordinator)
+	Path          string                 `protobuf:"bytes,5,opt,name=path,proto3" json:"path,omitempty"`                                // file: one item per non-empty line; directory: one item per file
+	Glob          string                 `protobuf:"bytes,6,opt,name=glob,proto3" json:"glob,omitempty"`                                // directory only: file name filter, e.g. "*.md"
+	ChunkItems    int32                  `protobuf:"varint,7,opt,name=chunk_items,json=chunkItems,proto3" json:"chunk_items,omitempty"` // max items per task (0 = 8 for LLM_GENERATE, 256 for EMBED)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *JobStatus) Reset() {
-	*x = JobStatus{}
+func (x *MapSpec) Reset() {
+	*x = MapSpec{}
 	mi := &file_orchestrator_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *JobStatus) String() string {
+func (x *MapSpec) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*JobStatus) ProtoMessage() {}
+func (*MapSpec) ProtoMessage() {}
 
-func (x *JobStatus) ProtoReflect() protoreflect.Message {
+func (x *MapSpec) ProtoReflect() protoreflect.Message {
 	mi := &file_orchestrator_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1693,79 +1825,82 @@ func (x *JobStatus) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use JobStatus.ProtoReflect.Descriptor instead.
-func (*JobStatus) Descriptor() ([]byte, []int) {
+// Deprecated: Use MapSpec.ProtoReflect.Descriptor instead.
+func (*MapSpec) Descriptor() ([]byte, []int) {
 	return file_orchestrator_proto_rawDescGZIP(), []int{24}
 }
 
-func (x *JobStatus) GetJobId() string {
+func (x *MapSpec) GetKind() string {
 	if x != nil {
-		return x.JobId
+		return x.Kind
 	}
 	return ""
 }
 
-func (x *JobStatus) GetState() string {
+func (x *MapSpec) GetPrompt() string {
 	if x != nil {
-		return x.State
+		return x.Prompt
 	}
 	return ""
 }
 
-func (x *JobStatus) GetTasks() []*TaskStatus {
+func (x *MapSpec) GetItems() []string {
 	if x != nil {
-		return x.Tasks
+		return x.Items
 	}
 	return nil
 }
 
-func (x *JobStatus) GetFinalResult() string {
+func (x *MapSpec) GetDeviceId() string {
 	if x != nil {
-		return x.FinalResult
+		return x.DeviceId
 	}
 	return ""
 }
 
-func (x *JobStatus) GetCurrentGroup() int32 {
+func (x *MapSpec) GetPath() string {
 	if x != nil {
-		return x.CurrentGroup
+		return x.Path
 	}
-	return 0
+	return ""
 }
 
-func (x *JobStatus) GetTotalGroups() int32 {
+func (x *MapSpec) GetGlob() string {
 	if x != nil {
-		return x.TotalGroups
+		return x.Glob
+	}
+	return ""
+}
+
+func (x *MapSpec) GetChunkItems() int32 {
+	if x != nil {
+		return x.ChunkItems
 	}
 	return 0
 }
 
-type TaskStatus struct {
-	state              protoimpl.MessageState `protogen:"open.v1"`
-	TaskId             string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
-	AssignedDeviceId   string                 `protobuf:"bytes,2,opt,name=assigned_device_id,json=assignedDeviceId,proto3" json:"assigned_device_id,omitempty"`
-	AssignedDeviceName string                 `protobuf:"bytes,3,opt,name=assigned_device_name,json=assignedDeviceName,proto3" json:"assigned_device_name,omitempty"`
-	State              string                 `protobuf:"bytes,4,opt,name=state,proto3" json:"state,omitempty"` // QUEUED, RUNNING, DONE, FAILED
-	Result             string                 `protobuf:"bytes,5,opt,name=result,proto3" json:"result,omitempty"`
-	Error              string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
-	unknownFields      protoimpl.UnknownFields
-	sizeCache          protoimpl.SizeCache
+// Planning structures
+type Plan struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Groups        []*TaskGroup           `protobuf:"bytes,1,rep,name=groups,proto3" json:"groups,omitempty"` // groups execute sequentially
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *TaskStatus) Reset() {
-	*x = TaskStatus{}
+func (x *Plan) Reset() {
+	*x = Plan{}
 	mi := &file_orchestrator_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *TaskStatus) String() string {
+func (x *Plan) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TaskStatus) ProtoMessage() {}
+func (*Plan) ProtoMessage() {}
 
-func (x *TaskStatus) ProtoReflect() protoreflect.Message {
+func (x *Plan) ProtoReflect() protoreflect.Message {
 	mi := &file_orchestrator_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1777,78 +1912,102 @@ func (x *TaskStatus) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TaskStatus.ProtoReflect.Descriptor instead.
-func (*TaskStatus) Descriptor() ([]byte, []int) {
+// Deprecated: Use Plan.ProtoReflect.Descriptor instead.
+func (*Plan) Descriptor() ([]byte, []int) {
 	return file_orchestrator_proto_rawDescGZIP(), []int{25}
 }
 
-func (x *TaskStatus) GetTaskId() string {
+func (x *Plan) GetGroups() []*TaskGroup {
 	if x != nil {
-		return x.TaskId
+		return x.Groups
 	}
-	return ""
+	return nil
 }
 
-func (x *TaskStatus) GetAssignedDeviceId() string {
-	if x != nil {
-		return x.AssignedDeviceId
-	}
-	return ""
+type TaskGroup struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"` // execution order (0 = first)
+	Tasks         []*TaskSpec            `protobuf:"bytes,2,rep,name=tasks,proto3" json:"tasks,omitempty"`  // tasks in group run in parallel
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *TaskStatus) GetAssignedDeviceName() string {
-	if x != nil {
-		return x.AssignedDeviceName
-	}
-	return ""
+func (x *TaskGroup) Reset() {
+	*x = TaskGroup{}
+	mi := &file_orchestrator_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *TaskStatus) GetState() string {
+func (x *TaskGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskGroup) ProtoMessage() {}
+
+func (x *TaskGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[26]
 	if x != nil {
-		return x.State
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *TaskStatus) GetResult() string {
+// Deprecated: Use TaskGroup.ProtoReflect.Descriptor instead.
+func (*TaskGroup) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *TaskGroup) GetIndex() int32 {
 	if x != nil {
-		return x.Result
+		return x.Index
 	}
-	return ""
+	return 0
 }
 
-func (x *TaskStatus) GetError() string {
+func (x *TaskGroup) GetTasks() []*TaskSpec {
 	if x != nil {
-		return x.Error
+		return x.Tasks
 	}
-	return ""
+	return nil
 }
 
-type TaskRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
-	JobId         string                 `protobuf:"bytes,2,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
-	Kind          string                 `protobuf:"bytes,3,opt,name=kind,proto3" json:"kind,omitempty"` // "ECHO" or "SYSINFO"
-	Input         string                 `protobuf:"bytes,4,opt,name=input,proto3" json:"input,omitempty"`
+type TaskSpec struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	TaskId         string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	Kind           string                 `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"` // "SYSINFO", "ECHO", "LLM_GENERATE", etc.
+	Input          string                 `protobuf:"bytes,3,opt,name=input,proto3" json:"input,omitempty"`
+	TargetDeviceId string                 `protobuf:"bytes,4,opt,name=target_device_id,json=targetDeviceId,proto3" json:"target_device_id,omitempty"` // empty = let orchestrator assign
+	// LLM_GENERATE parameters (for cost estimation)
+	PromptTokens    int32 `protobuf:"varint,5,opt,name=prompt_tokens,json=promptTokens,proto3" json:"prompt_tokens,omitempty"`            // estimated prompt tokens
+	MaxOutputTokens int32 `protobuf:"varint,6,opt,name=max_output_tokens,json=maxOutputTokens,proto3" json:"max_output_tokens,omitempty"` // max output tokens to generate
+	// Input files; tasks without a target run where their inputs already are
+	// or have them staged onto the chosen device first
+	Inputs        []*InputArtifact `protobuf:"bytes,7,rep,name=inputs,proto3" json:"inputs,omitempty"`
+	Priority      int32            `protobuf:"varint,8,opt,name=priority,proto3" json:"priority,omitempty"` // 0 = the job's priority
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *TaskRequest) Reset() {
-	*x = TaskRequest{}
-	mi := &file_orchestrator_proto_msgTypes[26]
+func (x *TaskSpec) Reset() {
+	*x = TaskSpec{}
+	mi := &file_orchestrator_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *TaskRequest) String() string {
+func (x *TaskSpec) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TaskRequest) ProtoMessage() {}
+func (*TaskSpec) ProtoMessage() {}
 
-func (x *TaskRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[26]
+func (x *TaskSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1859,139 +2018,92 @@ func (x *TaskRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TaskRequest.ProtoReflect.Descriptor instead.
-func (*TaskRequest) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{26}
+// Deprecated: Use TaskSpec.ProtoReflect.Descriptor instead.
+func (*TaskSpec) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{27}
 }
 
-func (x *TaskRequest) GetTaskId() string {
+func (x *TaskSpec) GetTaskId() string {
 	if x != nil {
 		return x.TaskId
 	}
 	return ""
 }
 
-func (x *TaskRequest) GetJobId() string {
+func (x *TaskSpec) GetKind() string {
 	if x != nil {
-		return x.JobId
+		return x.Kind
 	}
 	return ""
 }
 
-func (x *TaskRequest) GetKind() string {
+func (x *TaskSpec) GetInput() string {
 	if x != nil {
-		return x.Kind
+		return x.Input
 	}
 	return ""
 }
 
-func (x *TaskRequest) GetInput() string {
+func (x *TaskSpec) GetTargetDeviceId() string {
 	if x != nil {
-		return x.Input
+		return x.TargetDeviceId
 	}
 	return ""
 }
 
-type TaskResult struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
-	Ok            bool                   `protobuf:"varint,2,opt,name=ok,proto3" json:"ok,omitempty"`
-	Output        string                 `protobuf:"bytes,3,opt,name=output,proto3" json:"output,omitempty"`
-	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
-	TimeMs        float64                `protobuf:"fixed64,5,opt,name=time_ms,json=timeMs,proto3" json:"time_ms,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
-}
-
-func (x *TaskResult) Reset() {
-	*x = TaskResult{}
-	mi := &file_orchestrator_proto_msgTypes[27]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
-}
-
-func (x *TaskResult) String() string {
-	return protoimpl.X.MessageStringOf(x)
-}
-
-func (*TaskResult) ProtoMessage() {}
-
-func (x *TaskResult) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[27]
-	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
-	}
-	return mi.MessageOf(x)
-}
-
-// Deprecated: Use TaskResult.ProtoReflect.Descriptor instead.
-func (*TaskResult) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{27}
-}
-
-func (x *TaskResult) GetTaskId() string {
-	if x != nil {
-		return x.TaskId
-	}
-	return ""
-}
-
-func (x *TaskResult) GetOk() bool {
+func (x *TaskSpec) GetPromptTokens() int32 {
 	if x != nil {
-		return x.Ok
+		return x.PromptTokens
 	}
-	return false
+	return 0
 }
 
-func (x *TaskResult) GetOutput() string {
+func (x *TaskSpec) GetMaxOutputTokens() int32 {
 	if x != nil {
-		return x.Output
+		return x.MaxOutputTokens
 	}
-	return ""
+	return 0
 }
 
-func (x *TaskResult) GetError() string {
+func (x *TaskSpec) GetInputs() []*InputArtifact {
 	if x != nil {
-		return x.Error
+		return x.Inputs
 	}
-	return ""
+	return nil
 }
 
-func (x *TaskResult) GetTimeMs() float64 {
+func (x *TaskSpec) GetPriority() int32 {
 	if x != nil {
-		return x.TimeMs
+		return x.Priority
 	}
 	return 0
 }
 
-type WebRTCConfig struct {
+// InputArtifact names a task input by device and path, by content hash, or both.
+type InputArtifact struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
-	TargetFps     int32                  `protobuf:"varint,2,opt,name=target_fps,json=targetFps,proto3" json:"target_fps,omitempty"`          // default 8 if 0
-	JpegQuality   int32                  `protobuf:"varint,3,opt,name=jpeg_quality,json=jpegQuality,proto3" json:"jpeg_quality,omitempty"`    // default 60 if 0
-	MonitorIndex  int32                  `protobuf:"varint,4,opt,name=monitor_index,json=monitorIndex,proto3" json:"monitor_index,omitempty"` // default 0
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`     // device holding the file (with path)
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`                             // relative to that device's shared root
+	Sha256        string                 `protobuf:"bytes,3,opt,name=sha256,proto3" json:"sha256,omitempty"`                         // content hash; any device with a copy qualifies
+	SizeBytes     int64                  `protobuf:"varint,4,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"` // for transfer cost (0 = look it up)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *WebRTCConfig) Reset() {
-	*x = WebRTCConfig{}
+func (x *InputArtifact) Reset() {
+	*x = InputArtifact{}
 	mi := &file_orchestrator_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *WebRTCConfig) String() string {
+func (x *InputArtifact) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*WebRTCConfig) ProtoMessage() {}
+func (*InputArtifact) ProtoMessage() {}
 
-func (x *WebRTCConfig) ProtoReflect() protoreflect.Message {
+func (x *InputArtifact) ProtoReflect() protoreflect.Message {
 	mi := &file_orchestrator_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -2003,61 +2115,60 @@ func (x *WebRTCConfig) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use WebRTCConfig.ProtoReflect.Descriptor instead.
-func (*WebRTCConfig) Descriptor() ([]byte, []int) {
+// Deprecated: Use InputArtifact.ProtoReflect.Descriptor instead.
+func (*InputArtifact) Descriptor() ([]byte, []int) {
 	return file_orchestrator_proto_rawDescGZIP(), []int{28}
 }
 
-func (x *WebRTCConfig) GetSessionId() string {
+func (x *InputArtifact) GetDeviceId() string {
 	if x != nil {
-		return x.SessionId
+		return x.DeviceId
 	}
 	return ""
 }
 
-func (x *WebRTCConfig) GetTargetFps() int32 {
+func (x *InputArtifact) GetPath() string {
 	if x != nil {
-		return x.TargetFps
+		return x.Path
 	}
-	return 0
+	return ""
 }
 
-func (x *WebRTCConfig) GetJpegQuality() int32 {
+func (x *InputArtifact) GetSha256() string {
 	if x != nil {
-		return x.JpegQuality
+		return x.Sha256
 	}
-	return 0
+	return ""
 }
 
-func (x *WebRTCConfig) GetMonitorIndex() int32 {
+func (x *InputArtifact) GetSizeBytes() int64 {
 	if x != nil {
-		return x.MonitorIndex
+		return x.SizeBytes
 	}
 	return 0
 }
 
-type WebRTCOffer struct {
+type ReduceSpec struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	StreamId      string                 `protobuf:"bytes,1,opt,name=stream_id,json=streamId,proto3" json:"stream_id,omitempty"`
-	Sdp           string                 `protobuf:"bytes,2,opt,name=sdp,proto3" json:"sdp,omitempty"` // offer SDP including ICE candidates (non-trickle)
+	Kind          string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"` // "CONCAT" for now
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *WebRTCOffer) Reset() {
-	*x = WebRTCOffer{}
+func (x *ReduceSpec) Reset() {
+	*x = ReduceSpec{}
 	mi := &file_orchestrator_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *WebRTCOffer) String() string {
+func (x *ReduceSpec) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*WebRTCOffer) ProtoMessage() {}
+func (*ReduceSpec) ProtoMessage() {}
 
-func (x *WebRTCOffer) ProtoReflect() protoreflect.Message {
+func (x *ReduceSpec) ProtoReflect() protoreflect.Message {
 	mi := &file_orchestrator_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -2069,47 +2180,42 @@ func (x *WebRTCOffer) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use WebRTCOffer.ProtoReflect.Descriptor instead.
-func (*WebRTCOffer) Descriptor() ([]byte, []int) {
+// Deprecated: Use ReduceSpec.ProtoReflect.Descriptor instead.
+func (*ReduceSpec) Descriptor() ([]byte, []int) {
 	return file_orchestrator_proto_rawDescGZIP(), []int{29}
 }
 
-func (x *WebRTCOffer) GetStreamId() string {
-	if x != nil {
-		return x.StreamId
-	}
-	return ""
-}
-
-func (x *WebRTCOffer) GetSdp() string {
+func (x *ReduceSpec) GetKind() string {
 	if x != nil {
-		return x.Sdp
+		return x.Kind
 	}
 	return ""
 }
 
-type WebRTCAnswer struct {
+type JobInfo struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	StreamId      string                 `protobuf:"bytes,1,opt,name=stream_id,json=streamId,proto3" json:"stream_id,omitempty"`
-	Sdp           string                 `protobuf:"bytes,2,opt,name=sdp,proto3" json:"sdp,omitempty"` // answer SDP including ICE candidates (non-trickle)
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Summary       string                 `protobuf:"bytes,3,opt,name=summary,proto3" json:"summary,omitempty"`                // e.g. "distributed to N devices"
+	TraceId       string                 `protobuf:"bytes,4,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"` // hex trace ID of the job's spans, for finding it in a tracing backend
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *WebRTCAnswer) Reset() {
-	*x = WebRTCAnswer{}
+func (x *JobInfo) Reset() {
+	*x = JobInfo{}
 	mi := &file_orchestrator_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *WebRTCAnswer) String() string {
+func (x *JobInfo) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*WebRTCAnswer) ProtoMessage() {}
+func (*JobInfo) ProtoMessage() {}
 
-func (x *WebRTCAnswer) ProtoReflect() protoreflect.Message {
+func (x *JobInfo) ProtoReflect() protoreflect.Message {
 	mi := &file_orchestrator_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -2121,46 +2227,67 @@ func (x *WebRTCAnswer) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use WebRTCAnswer.ProtoReflect.Descriptor instead.
-func (*WebRTCAnswer) Descriptor() ([]byte, []int) {
+// Deprecated: Use JobInfo.ProtoReflect.Descriptor instead.
+func (*JobInfo) Descriptor() ([]byte, []int) {
 	return file_orchestrator_proto_rawDescGZIP(), []int{30}
 }
 
-func (x *WebRTCAnswer) GetStreamId() string {
+func (x *JobInfo) GetJobId() string {
 	if x != nil {
-		return x.StreamId
+		return x.JobId
 	}
 	return ""
 }
 
-func (x *WebRTCAnswer) GetSdp() string {
+func (x *JobInfo) GetCreatedAt() int64 {
 	if x != nil {
-		return x.Sdp
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *JobInfo) GetSummary() string {
+	if x != nil {
+		return x.Summary
 	}
 	return ""
 }
 
-type WebRTCStop struct {
+func (x *JobInfo) GetTraceId() string {
+	if x != nil {
+		return x.TraceId
+	}
+	return ""
+}
+
+type JobStatus struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	StreamId      string                 `protobuf:"bytes,1,opt,name=stream_id,json=streamId,proto3" json:"stream_id,omitempty"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	State         string                 `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"` // QUEUED, RUNNING, DONE, FAILED
+	Tasks         []*TaskStatus          `protobuf:"bytes,3,rep,name=tasks,proto3" json:"tasks,omitempty"`
+	FinalResult   string                 `protobuf:"bytes,4,opt,name=final_result,json=finalResult,proto3" json:"final_result,omitempty"`     // concatenated results when DONE
+	CurrentGroup  int32                  `protobuf:"varint,5,opt,name=current_group,json=currentGroup,proto3" json:"current_group,omitempty"` // which group is currently executing
+	TotalGroups   int32                  `protobuf:"varint,6,opt,name=total_groups,json=totalGroups,proto3" json:"total_groups,omitempty"`    // total number of groups in plan
+	TraceId       string                 `protobuf:"bytes,7,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"`                 // same as JobInfo.trace_id
+	ScheduleId    string                 `protobuf:"bytes,8,opt,name=schedule_id,json=scheduleId,proto3" json:"schedule_id,omitempty"`        // schedule that started the job, if any
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *WebRTCStop) Reset() {
-	*x = WebRTCStop{}
+func (x *JobStatus) Reset() {
+	*x = JobStatus{}
 	mi := &file_orchestrator_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *WebRTCStop) String() string {
+func (x *JobStatus) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*WebRTCStop) ProtoMessage() {}
+func (*JobStatus) ProtoMessage() {}
 
-func (x *WebRTCStop) ProtoReflect() protoreflect.Message {
+func (x *JobStatus) ProtoReflect() protoreflect.Message {
 	mi := &file_orchestrator_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -2172,41 +2299,94 @@ func (x *WebRTCStop) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use WebRTCStop.ProtoReflect.Descriptor instead.
-func (*WebRTCStop) Descriptor() ([]byte, []int) {
+// Deprecated: Use JobStatus.ProtoReflect.Descriptor instead.
+func (*JobStatus) Descriptor() ([]byte, []int) {
 	return file_orchestrator_proto_rawDescGZIP(), []int{31}
 }
 
-func (x *WebRTCStop) GetStreamId() string {
+func (x *JobStatus) GetJobId() string {
 	if x != nil {
-		return x.StreamId
+		return x.JobId
 	}
 	return ""
 }
 
-type PlanPreviewRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
-	Text          string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
-	MaxWorkers    int32                  `protobuf:"varint,3,opt,name=max_workers,json=maxWorkers,proto3" json:"max_workers,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *JobStatus) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
 }
 
-func (x *PlanPreviewRequest) Reset() {
-	*x = PlanPreviewRequest{}
+func (x *JobStatus) GetTasks() []*TaskStatus {
+	if x != nil {
+		return x.Tasks
+	}
+	return nil
+}
+
+func (x *JobStatus) GetFinalResult() string {
+	if x != nil {
+		return x.FinalResult
+	}
+	return ""
+}
+
+func (x *JobStatus) GetCurrentGroup() int32 {
+	if x != nil {
+		return x.CurrentGroup
+	}
+	return 0
+}
+
+func (x *JobStatus) GetTotalGroups() int32 {
+	if x != nil {
+		return x.TotalGroups
+	}
+	return 0
+}
+
+func (x *JobStatus) GetTraceId() string {
+	if x != nil {
+		return x.TraceId
+	}
+	return ""
+}
+
+func (x *JobStatus) GetScheduleId() string {
+	if x != nil {
+		return x.ScheduleId
+	}
+	return ""
+}
+
+type TaskStatus struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	TaskId             string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	AssignedDeviceId   string                 `protobuf:"bytes,2,opt,name=assigned_device_id,json=assignedDeviceId,proto3" json:"assigned_device_id,omitempty"`
+	AssignedDeviceName string                 `protobuf:"bytes,3,opt,name=assigned_device_name,json=assignedDeviceName,proto3" json:"assigned_device_name,omitempty"`
+	State              string                 `protobuf:"bytes,4,opt,name=state,proto3" json:"state,omitempty"` // QUEUED, RUNNING, DONE, FAILED
+	Result             string                 `protobuf:"bytes,5,opt,name=result,proto3" json:"result,omitempty"`
+	Error              string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	Shell              *ShellResult           `protobuf:"bytes,7,opt,name=shell,proto3" json:"shell,omitempty"` // SHELL tasks only
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *TaskStatus) Reset() {
+	*x = TaskStatus{}
 	mi := &file_orchestrator_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PlanPreviewRequest) String() string {
+func (x *TaskStatus) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PlanPreviewRequest) ProtoMessage() {}
+func (*TaskStatus) ProtoMessage() {}
 
-func (x *PlanPreviewRequest) ProtoReflect() protoreflect.Message {
+func (x *TaskStatus) ProtoReflect() protoreflect.Message {
 	mi := &file_orchestrator_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -2218,57 +2398,86 @@ func (x *PlanPreviewRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PlanPreviewRequest.ProtoReflect.Descriptor instead.
-func (*PlanPreviewRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use TaskStatus.ProtoReflect.Descriptor instead.
+func (*TaskStatus) Descriptor() ([]byte, []int) {
 	return file_orchestrator_proto_rawDescGZIP(), []int{32}
 }
 
-func (x *PlanPreviewRequest) GetSessionId() string {
+func (x *TaskStatus) GetTaskId() string {
 	if x != nil {
-		return x.SessionId
+		return x.TaskId
 	}
 	return ""
 }
 
-func (x *PlanPreviewRequest) GetText() string {
+func (x *TaskStatus) GetAssignedDeviceId() string {
 	if x != nil {
-		return x.Text
+		return x.AssignedDeviceId
 	}
 	return ""
 }
 
-func (x *PlanPreviewRequest) GetMaxWorkers() int32 {
+func (x *TaskStatus) GetAssignedDeviceName() string {
 	if x != nil {
-		return x.MaxWorkers
+		return x.AssignedDeviceName
 	}
-	return 0
+	return ""
 }
 
-type PlanPreviewResponse struct {
+func (x *TaskStatus) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *TaskStatus) GetResult() string {
+	if x != nil {
+		return x.Result
+	}
+	return ""
+}
+
+func (x *TaskStatus) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *TaskStatus) GetShell() *ShellResult {
+	if x != nil {
+		return x.Shell
+	}
+	return nil
+}
+
+type TaskRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	UsedAi        bool                   `protobuf:"varint,1,opt,name=used_ai,json=usedAi,proto3" json:"used_ai,omitempty"`
-	Notes         string                 `protobuf:"bytes,2,opt,name=notes,proto3" json:"notes,omitempty"`
-	Rationale     string                 `protobuf:"bytes,3,opt,name=rationale,proto3" json:"rationale,omitempty"`
-	Plan          *Plan                  `protobuf:"bytes,4,opt,name=plan,proto3" json:"plan,omitempty"`
-	Reduce        *ReduceSpec            `protobuf:"bytes,5,opt,name=reduce,proto3" json:"reduce,omitempty"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	JobId         string                 `protobuf:"bytes,2,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Kind          string                 `protobuf:"bytes,3,opt,name=kind,proto3" json:"kind,omitempty"` // a kind the worker advertises in task_kinds
+	Input         string                 `protobuf:"bytes,4,opt,name=input,proto3" json:"input,omitempty"`
+	InputPaths    []string               `protobuf:"bytes,5,rep,name=input_paths,json=inputPaths,proto3" json:"input_paths,omitempty"` // staged inputs, relative to the worker's shared root
+	SessionId     string                 `protobuf:"bytes,6,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`    // session on the worker
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PlanPreviewResponse) Reset() {
-	*x = PlanPreviewResponse{}
+func (x *TaskRequest) Reset() {
+	*x = TaskRequest{}
 	mi := &file_orchestrator_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PlanPreviewResponse) String() string {
+func (x *TaskRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PlanPreviewResponse) ProtoMessage() {}
+func (*TaskRequest) ProtoMessage() {}
 
-func (x *PlanPreviewResponse) ProtoReflect() protoreflect.Message {
+func (x *TaskRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_orchestrator_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -2280,69 +2489,79 @@ func (x *PlanPreviewResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PlanPreviewResponse.ProtoReflect.Descriptor instead.
-func (*PlanPreviewResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use TaskRequest.ProtoReflect.Descriptor instead.
+func (*TaskRequest) Descriptor() ([]byte, []int) {
 	return file_orchestrator_proto_rawDescGZIP(), []int{33}
 }
 
-func (x *PlanPreviewResponse) GetUsedAi() bool {
+func (x *TaskRequest) GetTaskId() string {
 	if x != nil {
-		return x.UsedAi
+		return x.TaskId
 	}
-	return false
+	return ""
 }
 
-func (x *PlanPreviewResponse) GetNotes() string {
+func (x *TaskRequest) GetJobId() string {
 	if x != nil {
-		return x.Notes
+		return x.JobId
 	}
 	return ""
 }
 
-func (x *PlanPreviewResponse) GetRationale() string {
+func (x *TaskRequest) GetKind() string {
 	if x != nil {
-		return x.Rationale
+		return x.Kind
 	}
 	return ""
 }
 
-func (x *PlanPreviewResponse) GetPlan() *Plan {
+func (x *TaskRequest) GetInput() string {
 	if x != nil {
-		return x.Plan
+		return x.Input
 	}
-	return nil
+	return ""
 }
 
-func (x *PlanPreviewResponse) GetReduce() *ReduceSpec {
+func (x *TaskRequest) GetInputPaths() []string {
 	if x != nil {
-		return x.Reduce
+		return x.InputPaths
 	}
 	return nil
 }
 
-type PlanCostRequest struct {
+func (x *TaskRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type TaskResult struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
-	Plan          *Plan                  `protobuf:"bytes,2,opt,name=plan,proto3" json:"plan,omitempty"`                            // plan to estimate
-	DeviceIds     []string               `protobuf:"bytes,3,rep,name=device_ids,json=deviceIds,proto3" json:"device_ids,omitempty"` // optional: limit to these devices (empty = all)
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	Ok            bool                   `protobuf:"varint,2,opt,name=ok,proto3" json:"ok,omitempty"`
+	Output        string                 `protobuf:"bytes,3,opt,name=output,proto3" json:"output,omitempty"`
+	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	TimeMs        float64                `protobuf:"fixed64,5,opt,name=time_ms,json=timeMs,proto3" json:"time_ms,omitempty"`
+	Shell         *ShellResult           `protobuf:"bytes,6,opt,name=shell,proto3" json:"shell,omitempty"` // SHELL tasks only
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PlanCostRequest) Reset() {
-	*x = PlanCostRequest{}
+func (x *TaskResult) Reset() {
+	*x = TaskResult{}
 	mi := &file_orchestrator_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PlanCostRequest) String() string {
+func (x *TaskResult) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PlanCostRequest) ProtoMessage() {}
+func (*TaskResult) ProtoMessage() {}
 
-func (x *PlanCostRequest) ProtoReflect() protoreflect.Message {
+func (x *TaskResult) ProtoReflect() protoreflect.Message {
 	mi := &file_orchestrator_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -2354,58 +2573,80 @@ func (x *PlanCostRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PlanCostRequest.ProtoReflect.Descriptor instead.
-func (*PlanCostRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use TaskResult.ProtoReflect.Descriptor instead.
+func (*TaskResult) Descriptor() ([]byte, []int) {
 	return file_orchestrator_proto_rawDescGZIP(), []int{34}
 }
 
-func (x *PlanCostRequest) GetSessionId() string {
+func (x *TaskResult) GetTaskId() string {
 	if x != nil {
-		return x.SessionId
+		return x.TaskId
 	}
 	return ""
 }
 
-func (x *PlanCostRequest) GetPlan() *Plan {
+func (x *TaskResult) GetOk() bool {
 	if x != nil {
-		return x.Plan
+		return x.Ok
 	}
-	return nil
+	return false
 }
 
-func (x *PlanCostRequest) GetDeviceIds() []string {
+func (x *TaskResult) GetOutput() string {
 	if x != nil {
-		return x.DeviceIds
+		return x.Output
+	}
+	return ""
+}
+
+func (x *TaskResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *TaskResult) GetTimeMs() float64 {
+	if x != nil {
+		return x.TimeMs
+	}
+	return 0
+}
+
+func (x *TaskResult) GetShell() *ShellResult {
+	if x != nil {
+		return x.Shell
 	}
 	return nil
 }
 
-type PlanCostResponse struct {
-	state                 protoimpl.MessageState `protogen:"open.v1"`
-	TotalPredictedMs      float64                `protobuf:"fixed64,1,opt,name=total_predicted_ms,json=totalPredictedMs,proto3" json:"total_predicted_ms,omitempty"` // best device's total latency
-	DeviceCosts           []*DeviceCostEstimate  `protobuf:"bytes,2,rep,name=device_costs,json=deviceCosts,proto3" json:"device_costs,omitempty"`
-	RecommendedDeviceId   string                 `protobuf:"bytes,3,opt,name=recommended_device_id,json=recommendedDeviceId,proto3" json:"recommended_device_id,omitempty"`
-	RecommendedDeviceName string                 `protobuf:"bytes,4,opt,name=recommended_device_name,json=recommendedDeviceName,proto3" json:"recommended_device_name,omitempty"`
-	HasUnknownCosts       bool                   `protobuf:"varint,5,opt,name=has_unknown_costs,json=hasUnknownCosts,proto3" json:"has_unknown_costs,omitempty"` // true if any step had unknown cost
-	Warning               string                 `protobuf:"bytes,6,opt,name=warning,proto3" json:"warning,omitempty"`                                           // warning message if applicable
-	unknownFields         protoimpl.UnknownFields
-	sizeCache             protoimpl.SizeCache
+// ShellResult is a SHELL task's command outcome
+type ShellResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Command       string                 `protobuf:"bytes,1,opt,name=command,proto3" json:"command,omitempty"`
+	ExitCode      int32                  `protobuf:"varint,2,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`      // -1 if the command did not start or timed out
+	StdoutTail    string                 `protobuf:"bytes,3,opt,name=stdout_tail,json=stdoutTail,proto3" json:"stdout_tail,omitempty"` // last lines of stdout
+	StderrTail    string                 `protobuf:"bytes,4,opt,name=stderr_tail,json=stderrTail,proto3" json:"stderr_tail,omitempty"` // last lines of stderr
+	DurationMs    float64                `protobuf:"fixed64,5,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	TimedOut      bool                   `protobuf:"varint,6,opt,name=timed_out,json=timedOut,proto3" json:"timed_out,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PlanCostResponse) Reset() {
-	*x = PlanCostResponse{}
+func (x *ShellResult) Reset() {
+	*x = ShellResult{}
 	mi := &file_orchestrator_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PlanCostResponse) String() string {
+func (x *ShellResult) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PlanCostResponse) ProtoMessage() {}
+func (*ShellResult) ProtoMessage() {}
 
-func (x *PlanCostResponse) ProtoReflect() protoreflect.Message {
+func (x *ShellResult) ProtoReflect() protoreflect.Message {
 	mi := &file_orchestrator_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -2417,79 +2658,88 @@ func (x *PlanCostResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PlanCostResponse.ProtoReflect.Descriptor instead.
-func (*PlanCostResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use ShellResult.ProtoReflect.Descriptor instead.
+func (*ShellResult) Descriptor() ([]byte, []int) {
 	return file_orchestrator_proto_rawDescGZIP(), []int{35}
 }
 
-func (x *PlanCostResponse) GetTotalPredictedMs() float64 {
+func (x *ShellResult) GetCommand() string {
 	if x != nil {
-		return x.TotalPredictedMs
+		return x.Command
 	}
-	return 0
+	return ""
 }
 
-func (x *PlanCostResponse) GetDeviceCosts() []*DeviceCostEstimate {
+func (x *ShellResult) GetExitCode() int32 {
 	if x != nil {
-		return x.DeviceCosts
+		return x.ExitCode
 	}
-	return nil
+	return 0
 }
 
-func (x *PlanCostResponse) GetRecommendedDeviceId() string {
+func (x *ShellResult) GetStdoutTail() string {
 	if x != nil {
-		return x.RecommendedDeviceId
+		return x.StdoutTail
 	}
 	return ""
 }
 
-func (x *PlanCostResponse) GetRecommendedDeviceName() string {
+func (x *ShellResult) GetStderrTail() string {
 	if x != nil {
-		return x.RecommendedDeviceName
+		return x.StderrTail
 	}
 	return ""
 }
 
-func (x *PlanCostResponse) GetHasUnknownCosts() bool {
+func (x *ShellResult) GetDurationMs() float64 {
 	if x != nil {
-		return x.HasUnknownCosts
+		return x.DurationMs
 	}
-	return false
+	return 0
 }
 
-func (x *PlanCostResponse) GetWarning() string {
+func (x *ShellResult) GetTimedOut() bool {
 	if x != nil {
-		return x.Warning
+		return x.TimedOut
 	}
-	return ""
+	return false
 }
 
-type DeviceCostEstimate struct {
-	state              protoimpl.MessageState `protogen:"open.v1"`
-	DeviceId           string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
-	DeviceName         string                 `protobuf:"bytes,2,opt,name=device_name,json=deviceName,proto3" json:"device_name,omitempty"`
-	TotalMs            float64                `protobuf:"fixed64,3,opt,name=total_ms,json=totalMs,proto3" json:"total_ms,omitempty"`
-	StepCosts          []*StepCostEstimate    `protobuf:"bytes,4,rep,name=step_costs,json=stepCosts,proto3" json:"step_costs,omitempty"`
-	EstimatedPeakRamMb uint64                 `protobuf:"varint,5,opt,name=estimated_peak_ram_mb,json=estimatedPeakRamMb,proto3" json:"estimated_peak_ram_mb,omitempty"`
-	RamSufficient      bool                   `protobuf:"varint,6,opt,name=ram_sufficient,json=ramSufficient,proto3" json:"ram_sufficient,omitempty"` // false if estimated RAM > device free RAM
-	unknownFields      protoimpl.UnknownFields
-	sizeCache          protoimpl.SizeCache
+type WebRTCConfig struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	SessionId      string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	TargetFps      int32                  `protobuf:"varint,2,opt,name=target_fps,json=targetFps,proto3" json:"target_fps,omitempty"`                  // default 8 if 0
+	JpegQuality    int32                  `protobuf:"varint,3,opt,name=jpeg_quality,json=jpegQuality,proto3" json:"jpeg_quality,omitempty"`            // default 60 if 0
+	MonitorIndex   int32                  `protobuf:"varint,4,opt,name=monitor_index,json=monitorIndex,proto3" json:"monitor_index,omitempty"`         // default 0
+	Mode           string                 `protobuf:"bytes,5,opt,name=mode,proto3" json:"mode,omitempty"`                                              // force "video" (VP8 track), "tiles" (chunked changed regions over a DataChannel) or "jpeg" (legacy single-message JPEG frames)
+	MaxBitrateKbps int32                  `protobuf:"varint,6,opt,name=max_bitrate_kbps,json=maxBitrateKbps,proto3" json:"max_bitrate_kbps,omitempty"` // video bitrate ceiling, default 2500 if 0
+	AcceptModes    []string               `protobuf:"bytes,7,rep,name=accept_modes,json=acceptModes,proto3" json:"accept_modes,omitempty"`             // when mode is empty: modes the viewer can display; the device picks its preferred one (video if empty)
+	InputControl   bool                   `protobuf:"varint,8,opt,name=input_control,json=inputControl,proto3" json:"input_control,omitempty"`         // offer an "input" DataChannel; needs the stream.control permission and local approval
+	Audio          bool                   `protobuf:"varint,9,opt,name=audio,proto3" json:"audio,omitempty"`                                           // add an Opus audio track
+	AudioSource    string                 `protobuf:"bytes,10,opt,name=audio_source,json=audioSource,proto3" json:"audio_source,omitempty"`            // "system", "mic", "tone" or "file"; the device's STREAM_AUDIO if empty
+	Trickle        bool                   `protobuf:"varint,11,opt,name=trickle,proto3" json:"trickle,omitempty"`                                      // return the offer at once; candidates follow via GetIceCandidates
+	IceServers     []*IceServer           `protobuf:"bytes,12,rep,name=ice_servers,json=iceServers,proto3" json:"ice_servers,omitempty"`               // used in addition to the device's own, e.g. the coordinator's TURN relay
+	Layer          string                 `protobuf:"bytes,13,opt,name=layer,proto3" json:"layer,omitempty"`                                           // quality preset "high", "medium" or "low"; high if empty
+	Viewer         string                 `protobuf:"bytes,14,opt,name=viewer,proto3" json:"viewer,omitempty"`                                         // who is watching, shown by ListStreams
+	Record         bool                   `protobuf:"varint,15,opt,name=record,proto3" json:"record,omitempty"`                                        // record the stream under the device's shared recordings/ folder
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
-func (x *DeviceCostEstimate) Reset() {
-	*x = DeviceCostEstimate{}
+func (x *WebRTCConfig) Reset() {
+	*x = WebRTCConfig{}
 	mi := &file_orchestrator_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeviceCostEstimate) String() string {
+func (x *WebRTCConfig) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeviceCostEstimate) ProtoMessage() {}
+func (*WebRTCConfig) ProtoMessage() {}
 
-func (x *DeviceCostEstimate) ProtoReflect() protoreflect.Message {
+func (x *WebRTCConfig) ProtoReflect() protoreflect.Message {
 	mi := &file_orchestrator_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -2501,79 +2751,145 @@ func (x *DeviceCostEstimate) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeviceCostEstimate.ProtoReflect.Descriptor instead.
-func (*DeviceCostEstimate) Descriptor() ([]byte, []int) {
+// Deprecated: Use WebRTCConfig.ProtoReflect.Descriptor instead.
+func (*WebRTCConfig) Descriptor() ([]byte, []int) {
 	return file_orchestrator_proto_rawDescGZIP(), []int{36}
 }
 
-func (x *DeviceCostEstimate) GetDeviceId() string {
+func (x *WebRTCConfig) GetSessionId() string {
 	if x != nil {
-		return x.DeviceId
+		return x.SessionId
 	}
 	return ""
 }
 
-func (x *DeviceCostEstimate) GetDeviceName() string {
+func (x *WebRTCConfig) GetTargetFps() int32 {
 	if x != nil {
-		return x.DeviceName
+		return x.TargetFps
 	}
-	return ""
+	return 0
 }
 
-func (x *DeviceCostEstimate) GetTotalMs() float64 {
+func (x *WebRTCConfig) GetJpegQuality() int32 {
 	if x != nil {
-		return x.TotalMs
+		return x.JpegQuality
 	}
 	return 0
 }
 
-func (x *DeviceCostEstimate) GetStepCosts() []*StepCostEstimate {
+func (x *WebRTCConfig) GetMonitorIndex() int32 {
 	if x != nil {
-		return x.StepCosts
+		return x.MonitorIndex
 	}
-	return nil
+	return 0
 }
 
-func (x *DeviceCostEstimate) GetEstimatedPeakRamMb() uint64 {
+func (x *WebRTCConfig) GetMode() string {
 	if x != nil {
-		return x.EstimatedPeakRamMb
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *WebRTCConfig) GetMaxBitrateKbps() int32 {
+	if x != nil {
+		return x.MaxBitrateKbps
 	}
 	return 0
 }
 
-func (x *DeviceCostEstimate) GetRamSufficient() bool {
+func (x *WebRTCConfig) GetAcceptModes() []string {
 	if x != nil {
-		return x.RamSufficient
+		return x.AcceptModes
 	}
-	return false
+	return nil
 }
 
-type StepCostEstimate struct {
-	state             protoimpl.MessageState `protogen:"open.v1"`
-	TaskId            string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
-	Kind              string                 `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
-	PredictedMs       float64                `protobuf:"fixed64,3,opt,name=predicted_ms,json=predictedMs,proto3" json:"predicted_ms,omitempty"`
-	PredictedMemoryMb float64                `protobuf:"fixed64,4,opt,name=predicted_memory_mb,json=predictedMemoryMb,proto3" json:"predicted_memory_mb,omitempty"`
-	UnknownCost       bool                   `protobuf:"varint,5,opt,name=unknown_cost,json=unknownCost,proto3" json:"unknown_cost,omitempty"` // true if step type not recognized
-	Notes             string                 `protobuf:"bytes,6,opt,name=notes,proto3" json:"notes,omitempty"`                                 // e.g., "using default prefill TPS"
-	unknownFields     protoimpl.UnknownFields
-	sizeCache         protoimpl.SizeCache
+func (x *WebRTCConfig) GetInputControl() bool {
+	if x != nil {
+		return x.InputControl
+	}
+	return false
 }
 
-func (x *StepCostEstimate) Reset() {
-	*x = StepCostEstimate{}
+func (x *WebRTCConfig) GetAudio() bool {
+	if x != nil {
+		return x.Audio
+	}
+	return false
+}
+
+func (x *WebRTCConfig) GetAudioSource() string {
+	if x != nil {
+		return x.AudioSource
+	}
+	return ""
+}
+
+func (x *WebRTCConfig) GetTrickle() bool {
+	if x != nil {
+		return x.Trickle
+	}
+	return false
+}
+
+func (x *WebRTCConfig) GetIceServers() []*IceServer {
+	if x != nil {
+		return x.IceServers
+	}
+	return nil
+}
+
+func (x *WebRTCConfig) GetLayer() string {
+	if x != nil {
+		return x.Layer
+	}
+	return ""
+}
+
+func (x *WebRTCConfig) GetViewer() string {
+	if x != nil {
+		return x.Viewer
+	}
+	return ""
+}
+
+func (x *WebRTCConfig) GetRecord() bool {
+	if x != nil {
+		return x.Record
+	}
+	return false
+}
+
+type WebRTCOffer struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StreamId      string                 `protobuf:"bytes,1,opt,name=stream_id,json=streamId,proto3" json:"stream_id,omitempty"`
+	Sdp           string                 `protobuf:"bytes,2,opt,name=sdp,proto3" json:"sdp,omitempty"`                                        // offer SDP including ICE candidates (non-trickle)
+	Mode          string                 `protobuf:"bytes,3,opt,name=mode,proto3" json:"mode,omitempty"`                                      // mode the stream was started in
+	InputControl  bool                   `protobuf:"varint,4,opt,name=input_control,json=inputControl,proto3" json:"input_control,omitempty"` // an "input" DataChannel is offered; control starts once the local user approves
+	Audio         bool                   `protobuf:"varint,5,opt,name=audio,proto3" json:"audio,omitempty"`                                   // an Opus audio track is offered
+	Trickle       bool                   `protobuf:"varint,6,opt,name=trickle,proto3" json:"trickle,omitempty"`                               // sdp has no candidates yet
+	IceServers    []*IceServer           `protobuf:"bytes,7,rep,name=ice_servers,json=iceServers,proto3" json:"ice_servers,omitempty"`        // servers the device uses; the viewer should use them too
+	Layer         string                 `protobuf:"bytes,8,opt,name=layer,proto3" json:"layer,omitempty"`                                    // quality layer the stream uses
+	Recording     string                 `protobuf:"bytes,9,opt,name=recording,proto3" json:"recording,omitempty"`                            // recording path relative to the shared root; empty unless recorded
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WebRTCOffer) Reset() {
+	*x = WebRTCOffer{}
 	mi := &file_orchestrator_proto_msgTypes[37]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StepCostEstimate) String() string {
+func (x *WebRTCOffer) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StepCostEstimate) ProtoMessage() {}
+func (*WebRTCOffer) ProtoMessage() {}
 
-func (x *StepCostEstimate) ProtoReflect() protoreflect.Message {
+func (x *WebRTCOffer) ProtoReflect() protoreflect.Message {
 	mi := &file_orchestrator_proto_msgTypes[37]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -2585,74 +2901,96 @@ func (x *StepCostEstimate) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StepCostEstimate.ProtoReflect.Descriptor instead.
-func (*StepCostEstimate) Descriptor() ([]byte, []int) {
+// Deprecated: Use WebRTCOffer.ProtoReflect.Descriptor instead.
+func (*WebRTCOffer) Descriptor() ([]byte, []int) {
 	return file_orchestrator_proto_rawDescGZIP(), []int{37}
 }
 
-func (x *StepCostEstimate) GetTaskId() string {
+func (x *WebRTCOffer) GetStreamId() string {
 	if x != nil {
-		return x.TaskId
+		return x.StreamId
 	}
 	return ""
 }
 
-func (x *StepCostEstimate) GetKind() string {
+func (x *WebRTCOffer) GetSdp() string {
 	if x != nil {
-		return x.Kind
+		return x.Sdp
 	}
 	return ""
 }
 
-func (x *StepCostEstimate) GetPredictedMs() float64 {
+func (x *WebRTCOffer) GetMode() string {
 	if x != nil {
-		return x.PredictedMs
+		return x.Mode
 	}
-	return 0
+	return ""
 }
 
-func (x *StepCostEstimate) GetPredictedMemoryMb() float64 {
+func (x *WebRTCOffer) GetInputControl() bool {
 	if x != nil {
-		return x.PredictedMemoryMb
+		return x.InputControl
 	}
-	return 0
+	return false
 }
 
-func (x *StepCostEstimate) GetUnknownCost() bool {
+func (x *WebRTCOffer) GetAudio() bool {
 	if x != nil {
-		return x.UnknownCost
+		return x.Audio
 	}
 	return false
 }
 
-func (x *StepCostEstimate) GetNotes() string {
+func (x *WebRTCOffer) GetTrickle() bool {
 	if x != nil {
-		return x.Notes
+		return x.Trickle
+	}
+	return false
+}
+
+func (x *WebRTCOffer) GetIceServers() []*IceServer {
+	if x != nil {
+		return x.IceServers
+	}
+	return nil
+}
+
+func (x *WebRTCOffer) GetLayer() string {
+	if x != nil {
+		return x.Layer
 	}
 	return ""
 }
 
-type DownloadTicketRequest struct {
+func (x *WebRTCOffer) GetRecording() string {
+	if x != nil {
+		return x.Recording
+	}
+	return ""
+}
+
+type WebRTCAnswer struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"` // relative path under shared root, e.g. "test.txt"
+	StreamId      string                 `protobuf:"bytes,1,opt,name=stream_id,json=streamId,proto3" json:"stream_id,omitempty"`
+	Sdp           string                 `protobuf:"bytes,2,opt,name=sdp,proto3" json:"sdp,omitempty"` // answer SDP; includes ICE candidates unless the stream uses trickle ICE
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DownloadTicketRequest) Reset() {
-	*x = DownloadTicketRequest{}
+func (x *WebRTCAnswer) Reset() {
+	*x = WebRTCAnswer{}
 	mi := &file_orchestrator_proto_msgTypes[38]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DownloadTicketRequest) String() string {
+func (x *WebRTCAnswer) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DownloadTicketRequest) ProtoMessage() {}
+func (*WebRTCAnswer) ProtoMessage() {}
 
-func (x *DownloadTicketRequest) ProtoReflect() protoreflect.Message {
+func (x *WebRTCAnswer) ProtoReflect() protoreflect.Message {
 	mi := &file_orchestrator_proto_msgTypes[38]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -2664,42 +3002,46 @@ func (x *DownloadTicketRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DownloadTicketRequest.ProtoReflect.Descriptor instead.
-func (*DownloadTicketRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use WebRTCAnswer.ProtoReflect.Descriptor instead.
+func (*WebRTCAnswer) Descriptor() ([]byte, []int) {
 	return file_orchestrator_proto_rawDescGZIP(), []int{38}
 }
 
-func (x *DownloadTicketRequest) GetPath() string {
+func (x *WebRTCAnswer) GetStreamId() string {
 	if x != nil {
-		return x.Path
+		return x.StreamId
 	}
 	return ""
 }
 
-type DownloadTicketResponse struct {
+func (x *WebRTCAnswer) GetSdp() string {
+	if x != nil {
+		return x.Sdp
+	}
+	return ""
+}
+
+type WebRTCStop struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`                                         // one-time-use download token
-	Filename      string                 `protobuf:"bytes,2,opt,name=filename,proto3" json:"filename,omitempty"`                                   // basename of the file
-	SizeBytes     int64                  `protobuf:"varint,3,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`               // file size
-	ExpiresUnixMs int64                  `protobuf:"varint,4,opt,name=expires_unix_ms,json=expiresUnixMs,proto3" json:"expires_unix_ms,omitempty"` // absolute expiry (ms since epoch)
+	StreamId      string                 `protobuf:"bytes,1,opt,name=stream_id,json=streamId,proto3" json:"stream_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DownloadTicketResponse) Reset() {
-	*x = DownloadTicketResponse{}
+func (x *WebRTCStop) Reset() {
+	*x = WebRTCStop{}
 	mi := &file_orchestrator_proto_msgTypes[39]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DownloadTicketResponse) String() string {
+func (x *WebRTCStop) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DownloadTicketResponse) ProtoMessage() {}
+func (*WebRTCStop) ProtoMessage() {}
 
-func (x *DownloadTicketResponse) ProtoReflect() protoreflect.Message {
+func (x *WebRTCStop) ProtoReflect() protoreflect.Message {
 	mi := &file_orchestrator_proto_msgTypes[39]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -2711,66 +3053,41 @@ func (x *DownloadTicketResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DownloadTicketResponse.ProtoReflect.Descriptor instead.
-func (*DownloadTicketResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use WebRTCStop.ProtoReflect.Descriptor instead.
+func (*WebRTCStop) Descriptor() ([]byte, []int) {
 	return file_orchestrator_proto_rawDescGZIP(), []int{39}
 }
 
-func (x *DownloadTicketResponse) GetToken() string {
-	if x != nil {
-		return x.Token
-	}
-	return ""
-}
-
-func (x *DownloadTicketResponse) GetFilename() string {
+func (x *WebRTCStop) GetStreamId() string {
 	if x != nil {
-		return x.Filename
+		return x.StreamId
 	}
 	return ""
 }
 
-func (x *DownloadTicketResponse) GetSizeBytes() int64 {
-	if x != nil {
-		return x.SizeBytes
-	}
-	return 0
-}
-
-func (x *DownloadTicketResponse) GetExpiresUnixMs() int64 {
-	if x != nil {
-		return x.ExpiresUnixMs
-	}
-	return 0
-}
-
-type ReadFileRequest struct {
+type IceServer struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
-	DeviceId      string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"` // target device (empty = local)
-	Path          string                 `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
-	Mode          ReadMode               `protobuf:"varint,4,opt,name=mode,proto3,enum=edgemesh.ReadMode" json:"mode,omitempty"`
-	MaxBytes      int32                  `protobuf:"varint,5,opt,name=max_bytes,json=maxBytes,proto3" json:"max_bytes,omitempty"` // default 65536, max 10MB
-	Offset        int64                  `protobuf:"varint,6,opt,name=offset,proto3" json:"offset,omitempty"`                     // for RANGE mode
-	Length        int64                  `protobuf:"varint,7,opt,name=length,proto3" json:"length,omitempty"`                     // for RANGE mode
+	Urls          []string               `protobuf:"bytes,1,rep,name=urls,proto3" json:"urls,omitempty"` // stun:, turn: or turns: URLs
+	Username      string                 `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	Credential    string                 `protobuf:"bytes,3,opt,name=credential,proto3" json:"credential,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ReadFileRequest) Reset() {
-	*x = ReadFileRequest{}
+func (x *IceServer) Reset() {
+	*x = IceServer{}
 	mi := &file_orchestrator_proto_msgTypes[40]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ReadFileRequest) String() string {
+func (x *IceServer) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ReadFileRequest) ProtoMessage() {}
+func (*IceServer) ProtoMessage() {}
 
-func (x *ReadFileRequest) ProtoReflect() protoreflect.Message {
+func (x *IceServer) ProtoReflect() protoreflect.Message {
 	mi := &file_orchestrator_proto_msgTypes[40]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -2782,87 +3099,116 @@ func (x *ReadFileRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ReadFileRequest.ProtoReflect.Descriptor instead.
-func (*ReadFileRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use IceServer.ProtoReflect.Descriptor instead.
+func (*IceServer) Descriptor() ([]byte, []int) {
 	return file_orchestrator_proto_rawDescGZIP(), []int{40}
 }
 
-func (x *ReadFileRequest) GetSessionId() string {
+func (x *IceServer) GetUrls() []string {
 	if x != nil {
-		return x.SessionId
+		return x.Urls
 	}
-	return ""
+	return nil
 }
 
-func (x *ReadFileRequest) GetDeviceId() string {
+func (x *IceServer) GetUsername() string {
 	if x != nil {
-		return x.DeviceId
+		return x.Username
 	}
 	return ""
 }
 
-func (x *ReadFileRequest) GetPath() string {
+func (x *IceServer) GetCredential() string {
 	if x != nil {
-		return x.Path
+		return x.Credential
 	}
 	return ""
 }
 
-func (x *ReadFileRequest) GetMode() ReadMode {
+// IceCandidate follows the browser's RTCIceCandidateInit
+type IceCandidate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Candidate     string                 `protobuf:"bytes,1,opt,name=candidate,proto3" json:"candidate,omitempty"` // "candidate:..." line; empty marks the end of candidates
+	SdpMid        string                 `protobuf:"bytes,2,opt,name=sdp_mid,json=sdpMid,proto3" json:"sdp_mid,omitempty"`
+	SdpMlineIndex int32                  `protobuf:"varint,3,opt,name=sdp_mline_index,json=sdpMlineIndex,proto3" json:"sdp_mline_index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IceCandidate) Reset() {
+	*x = IceCandidate{}
+	mi := &file_orchestrator_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IceCandidate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IceCandidate) ProtoMessage() {}
+
+func (x *IceCandidate) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[41]
 	if x != nil {
-		return x.Mode
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ReadMode_READ_MODE_FULL
+	return mi.MessageOf(x)
 }
 
-func (x *ReadFileRequest) GetMaxBytes() int32 {
+// Deprecated: Use IceCandidate.ProtoReflect.Descriptor instead.
+func (*IceCandidate) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *IceCandidate) GetCandidate() string {
 	if x != nil {
-		return x.MaxBytes
+		return x.Candidate
 	}
-	return 0
+	return ""
 }
 
-func (x *ReadFileRequest) GetOffset() int64 {
+func (x *IceCandidate) GetSdpMid() string {
 	if x != nil {
-		return x.Offset
+		return x.SdpMid
 	}
-	return 0
+	return ""
 }
 
-func (x *ReadFileRequest) GetLength() int64 {
+func (x *IceCandidate) GetSdpMlineIndex() int32 {
 	if x != nil {
-		return x.Length
+		return x.SdpMlineIndex
 	}
 	return 0
 }
 
-type ReadFileResponse struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	Content        []byte                 `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
-	SizeBytes      int64                  `protobuf:"varint,2,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`             // actual file size
-	BytesReturned  int64                  `protobuf:"varint,3,opt,name=bytes_returned,json=bytesReturned,proto3" json:"bytes_returned,omitempty"` // bytes returned in content
-	Truncated      bool                   `protobuf:"varint,4,opt,name=truncated,proto3" json:"truncated,omitempty"`
-	Error          string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
-	ContentPreview string                 `protobuf:"bytes,6,opt,name=content_preview,json=contentPreview,proto3" json:"content_preview,omitempty"` // first ~2KB decoded if text
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+type IceCandidateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StreamId      string                 `protobuf:"bytes,1,opt,name=stream_id,json=streamId,proto3" json:"stream_id,omitempty"`
+	Candidate     *IceCandidate          `protobuf:"bytes,2,opt,name=candidate,proto3" json:"candidate,omitempty"` // a viewer candidate
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ReadFileResponse) Reset() {
-	*x = ReadFileResponse{}
-	mi := &file_orchestrator_proto_msgTypes[41]
+func (x *IceCandidateRequest) Reset() {
+	*x = IceCandidateRequest{}
+	mi := &file_orchestrator_proto_msgTypes[42]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ReadFileResponse) String() string {
+func (x *IceCandidateRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ReadFileResponse) ProtoMessage() {}
+func (*IceCandidateRequest) ProtoMessage() {}
 
-func (x *ReadFileResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[41]
+func (x *IceCandidateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[42]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2873,77 +3219,49 @@ func (x *ReadFileResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ReadFileResponse.ProtoReflect.Descriptor instead.
-func (*ReadFileResponse) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{41}
+// Deprecated: Use IceCandidateRequest.ProtoReflect.Descriptor instead.
+func (*IceCandidateRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{42}
 }
 
-func (x *ReadFileResponse) GetContent() []byte {
+func (x *IceCandidateRequest) GetStreamId() string {
 	if x != nil {
-		return x.Content
-	}
-	return nil
-}
-
-func (x *ReadFileResponse) GetSizeBytes() int64 {
-	if x != nil {
-		return x.SizeBytes
-	}
-	return 0
-}
-
-func (x *ReadFileResponse) GetBytesReturned() int64 {
-	if x != nil {
-		return x.BytesReturned
-	}
-	return 0
-}
-
-func (x *ReadFileResponse) GetTruncated() bool {
-	if x != nil {
-		return x.Truncated
-	}
-	return false
-}
-
-func (x *ReadFileResponse) GetError() string {
-	if x != nil {
-		return x.Error
+		return x.StreamId
 	}
 	return ""
 }
 
-func (x *ReadFileResponse) GetContentPreview() string {
+func (x *IceCandidateRequest) GetCandidate() *IceCandidate {
 	if x != nil {
-		return x.ContentPreview
+		return x.Candidate
 	}
-	return ""
+	return nil
 }
 
-type ChatMemorySync struct {
+type IceCandidatesRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`                   // which device this memory belongs to
-	LastUpdatedMs int64                  `protobuf:"varint,2,opt,name=last_updated_ms,json=lastUpdatedMs,proto3" json:"last_updated_ms,omitempty"` // timestamp of sender's memory
-	MemoryJson    string                 `protobuf:"bytes,3,opt,name=memory_json,json=memoryJson,proto3" json:"memory_json,omitempty"`             // full JSON if pushing, empty if pulling
+	StreamId      string                 `protobuf:"bytes,1,opt,name=stream_id,json=streamId,proto3" json:"stream_id,omitempty"`
+	Since         int32                  `protobuf:"varint,2,opt,name=since,proto3" json:"since,omitempty"`                 // number of device candidates already received
+	WaitMs        int32                  `protobuf:"varint,3,opt,name=wait_ms,json=waitMs,proto3" json:"wait_ms,omitempty"` // wait up to this long for a new one, capped at 30000
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ChatMemorySync) Reset() {
-	*x = ChatMemorySync{}
-	mi := &file_orchestrator_proto_msgTypes[42]
+func (x *IceCandidatesRequest) Reset() {
+	*x = IceCandidatesRequest{}
+	mi := &file_orchestrator_proto_msgTypes[43]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ChatMemorySync) String() string {
+func (x *IceCandidatesRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ChatMemorySync) ProtoMessage() {}
+func (*IceCandidatesRequest) ProtoMessage() {}
 
-func (x *ChatMemorySync) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[42]
+func (x *IceCandidatesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[43]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2954,55 +3272,55 @@ func (x *ChatMemorySync) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ChatMemorySync.ProtoReflect.Descriptor instead.
-func (*ChatMemorySync) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{42}
+// Deprecated: Use IceCandidatesRequest.ProtoReflect.Descriptor instead.
+func (*IceCandidatesRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{43}
 }
 
-func (x *ChatMemorySync) GetDeviceId() string {
+func (x *IceCandidatesRequest) GetStreamId() string {
 	if x != nil {
-		return x.DeviceId
+		return x.StreamId
 	}
 	return ""
 }
 
-func (x *ChatMemorySync) GetLastUpdatedMs() int64 {
+func (x *IceCandidatesRequest) GetSince() int32 {
 	if x != nil {
-		return x.LastUpdatedMs
+		return x.Since
 	}
 	return 0
 }
 
-func (x *ChatMemorySync) GetMemoryJson() string {
+func (x *IceCandidatesRequest) GetWaitMs() int32 {
 	if x != nil {
-		return x.MemoryJson
+		return x.WaitMs
 	}
-	return ""
+	return 0
 }
 
-type ChatMemorySyncResponse struct {
+type IceCandidatesResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Updated       bool                   `protobuf:"varint,1,opt,name=updated,proto3" json:"updated,omitempty"`                        // true if receiver's memory was updated
-	MemoryJson    string                 `protobuf:"bytes,2,opt,name=memory_json,json=memoryJson,proto3" json:"memory_json,omitempty"` // new JSON if updated, empty otherwise
+	Candidates    []*IceCandidate        `protobuf:"bytes,1,rep,name=candidates,proto3" json:"candidates,omitempty"` // device candidates after the first since
+	Complete      bool                   `protobuf:"varint,2,opt,name=complete,proto3" json:"complete,omitempty"`    // gathering is done; no more will follow
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ChatMemorySyncResponse) Reset() {
-	*x = ChatMemorySyncResponse{}
-	mi := &file_orchestrator_proto_msgTypes[43]
+func (x *IceCandidatesResponse) Reset() {
+	*x = IceCandidatesResponse{}
+	mi := &file_orchestrator_proto_msgTypes[44]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ChatMemorySyncResponse) String() string {
+func (x *IceCandidatesResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ChatMemorySyncResponse) ProtoMessage() {}
+func (*IceCandidatesResponse) ProtoMessage() {}
 
-func (x *ChatMemorySyncResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[43]
+func (x *IceCandidatesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[44]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3013,47 +3331,47 @@ func (x *ChatMemorySyncResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ChatMemorySyncResponse.ProtoReflect.Descriptor instead.
-func (*ChatMemorySyncResponse) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{43}
+// Deprecated: Use IceCandidatesResponse.ProtoReflect.Descriptor instead.
+func (*IceCandidatesResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{44}
 }
 
-func (x *ChatMemorySyncResponse) GetUpdated() bool {
+func (x *IceCandidatesResponse) GetCandidates() []*IceCandidate {
 	if x != nil {
-		return x.Updated
+		return x.Candidates
 	}
-	return false
+	return nil
 }
 
-func (x *ChatMemorySyncResponse) GetMemoryJson() string {
+func (x *IceCandidatesResponse) GetComplete() bool {
 	if x != nil {
-		return x.MemoryJson
+		return x.Complete
 	}
-	return ""
+	return false
 }
 
-type ChatMemoryData struct {
+type ListStreamsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	MemoryJson    string                 `protobuf:"bytes,1,opt,name=memory_json,json=memoryJson,proto3" json:"memory_json,omitempty"` // full chat memory JSON
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ChatMemoryData) Reset() {
-	*x = ChatMemoryData{}
-	mi := &file_orchestrator_proto_msgTypes[44]
+func (x *ListStreamsRequest) Reset() {
+	*x = ListStreamsRequest{}
+	mi := &file_orchestrator_proto_msgTypes[45]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ChatMemoryData) String() string {
+func (x *ListStreamsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ChatMemoryData) ProtoMessage() {}
+func (*ListStreamsRequest) ProtoMessage() {}
 
-func (x *ChatMemoryData) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[44]
+func (x *ListStreamsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[45]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3064,42 +3382,53 @@ func (x *ChatMemoryData) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ChatMemoryData.ProtoReflect.Descriptor instead.
-func (*ChatMemoryData) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{44}
+// Deprecated: Use ListStreamsRequest.ProtoReflect.Descriptor instead.
+func (*ListStreamsRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{45}
 }
 
-func (x *ChatMemoryData) GetMemoryJson() string {
+func (x *ListStreamsRequest) GetSessionId() string {
 	if x != nil {
-		return x.MemoryJson
+		return x.SessionId
 	}
 	return ""
 }
 
-type LLMTaskRequest struct {
+type StreamSession struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Prompt        string                 `protobuf:"bytes,1,opt,name=prompt,proto3" json:"prompt,omitempty"`
-	Model         string                 `protobuf:"bytes,2,opt,name=model,proto3" json:"model,omitempty"`                           // optional: specific model name
-	MaxTokens     int32                  `protobuf:"varint,3,opt,name=max_tokens,json=maxTokens,proto3" json:"max_tokens,omitempty"` // optional: max tokens to generate
+	StreamId      string                 `protobuf:"bytes,1,opt,name=stream_id,json=streamId,proto3" json:"stream_id,omitempty"`
+	Viewer        string                 `protobuf:"bytes,3,opt,name=viewer,proto3" json:"viewer,omitempty"`
+	Mode          string                 `protobuf:"bytes,4,opt,name=mode,proto3" json:"mode,omitempty"`
+	Layer         string                 `protobuf:"bytes,5,opt,name=layer,proto3" json:"layer,omitempty"`
+	MonitorIndex  int32                  `protobuf:"varint,6,opt,name=monitor_index,json=monitorIndex,proto3" json:"monitor_index,omitempty"`
+	TargetFps     int32                  `protobuf:"varint,7,opt,name=target_fps,json=targetFps,proto3" json:"target_fps,omitempty"`
+	BitrateKbps   int32                  `protobuf:"varint,8,opt,name=bitrate_kbps,json=bitrateKbps,proto3" json:"bitrate_kbps,omitempty"` // current video target; 0 in DataChannel modes
+	State         string                 `protobuf:"bytes,9,opt,name=state,proto3" json:"state,omitempty"`                                 // peer connection state
+	StartedAtMs   int64                  `protobuf:"varint,10,opt,name=started_at_ms,json=startedAtMs,proto3" json:"started_at_ms,omitempty"`
+	IdleMs        int64                  `protobuf:"varint,11,opt,name=idle_ms,json=idleMs,proto3" json:"idle_ms,omitempty"` // time without a connected viewer; 0 while connected
+	Audio         bool                   `protobuf:"varint,12,opt,name=audio,proto3" json:"audio,omitempty"`
+	Control       string                 `protobuf:"bytes,13,opt,name=control,proto3" json:"control,omitempty"`     // control state; empty unless control was requested
+	Recording     string                 `protobuf:"bytes,14,opt,name=recording,proto3" json:"recording,omitempty"` // recording path relative to the shared root; empty unless recorded
+	Principal     string                 `protobuf:"bytes,15,opt,name=principal,proto3" json:"principal,omitempty"` // who holds control; empty unless control was requested
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *LLMTaskRequest) Reset() {
-	*x = LLMTaskRequest{}
-	mi := &file_orchestrator_proto_msgTypes[45]
+func (x *StreamSession) Reset() {
+	*x = StreamSession{}
+	mi := &file_orchestrator_proto_msgTypes[46]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *LLMTaskRequest) String() string {
+func (x *StreamSession) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LLMTaskRequest) ProtoMessage() {}
+func (*StreamSession) ProtoMessage() {}
 
-func (x *LLMTaskRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[45]
+func (x *StreamSession) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[46]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3110,128 +3439,133 @@ func (x *LLMTaskRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LLMTaskRequest.ProtoReflect.Descriptor instead.
-func (*LLMTaskRequest) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{45}
+// Deprecated: Use StreamSession.ProtoReflect.Descriptor instead.
+func (*StreamSession) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{46}
 }
 
-func (x *LLMTaskRequest) GetPrompt() string {
+func (x *StreamSession) GetStreamId() string {
 	if x != nil {
-		return x.Prompt
+		return x.StreamId
 	}
 	return ""
 }
 
-func (x *LLMTaskRequest) GetModel() string {
+func (x *StreamSession) GetViewer() string {
 	if x != nil {
-		return x.Model
+		return x.Viewer
 	}
 	return ""
 }
 
-func (x *LLMTaskRequest) GetMaxTokens() int32 {
+func (x *StreamSession) GetMode() string {
 	if x != nil {
-		return x.MaxTokens
+		return x.Mode
 	}
-	return 0
+	return ""
 }
 
-type LLMTaskResponse struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	Output          string                 `protobuf:"bytes,1,opt,name=output,proto3" json:"output,omitempty"`
-	ModelUsed       string                 `protobuf:"bytes,2,opt,name=model_used,json=modelUsed,proto3" json:"model_used,omitempty"`
-	TokensGenerated int64                  `protobuf:"varint,3,opt,name=tokens_generated,json=tokensGenerated,proto3" json:"tokens_generated,omitempty"`
-	Error           string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+func (x *StreamSession) GetLayer() string {
+	if x != nil {
+		return x.Layer
+	}
+	return ""
 }
 
-func (x *LLMTaskResponse) Reset() {
-	*x = LLMTaskResponse{}
-	mi := &file_orchestrator_proto_msgTypes[46]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *StreamSession) GetMonitorIndex() int32 {
+	if x != nil {
+		return x.MonitorIndex
+	}
+	return 0
 }
 
-func (x *LLMTaskResponse) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *StreamSession) GetTargetFps() int32 {
+	if x != nil {
+		return x.TargetFps
+	}
+	return 0
 }
 
-func (*LLMTaskResponse) ProtoMessage() {}
+func (x *StreamSession) GetBitrateKbps() int32 {
+	if x != nil {
+		return x.BitrateKbps
+	}
+	return 0
+}
 
-func (x *LLMTaskResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[46]
+func (x *StreamSession) GetState() string {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.State
 	}
-	return mi.MessageOf(x)
+	return ""
 }
 
-// Deprecated: Use LLMTaskResponse.ProtoReflect.Descriptor instead.
-func (*LLMTaskResponse) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{46}
+func (x *StreamSession) GetStartedAtMs() int64 {
+	if x != nil {
+		return x.StartedAtMs
+	}
+	return 0
 }
 
-func (x *LLMTaskResponse) GetOutput() string {
+func (x *StreamSession) GetIdleMs() int64 {
 	if x != nil {
-		return x.Output
+		return x.IdleMs
 	}
-	return ""
+	return 0
 }
 
-func (x *LLMTaskResponse) GetModelUsed() string {
+func (x *StreamSession) GetAudio() bool {
 	if x != nil {
-		return x.ModelUsed
+		return x.Audio
+	}
+	return false
+}
+
+func (x *StreamSession) GetControl() string {
+	if x != nil {
+		return x.Control
 	}
 	return ""
 }
 
-func (x *LLMTaskResponse) GetTokensGenerated() int64 {
+func (x *StreamSession) GetRecording() string {
 	if x != nil {
-		return x.TokensGenerated
+		return x.Recording
 	}
-	return 0
+	return ""
 }
 
-func (x *LLMTaskResponse) GetError() string {
+func (x *StreamSession) GetPrincipal() string {
 	if x != nil {
-		return x.Error
+		return x.Principal
 	}
 	return ""
 }
 
-type MetricsSample struct {
+type CaptureFeed struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	TimestampMs   int64                  `protobuf:"varint,1,opt,name=timestamp_ms,json=timestampMs,proto3" json:"timestamp_ms,omitempty"`
-	CpuLoad       float64                `protobuf:"fixed64,2,opt,name=cpu_load,json=cpuLoad,proto3" json:"cpu_load,omitempty"`
-	MemUsedMb     uint64                 `protobuf:"varint,3,opt,name=mem_used_mb,json=memUsedMb,proto3" json:"mem_used_mb,omitempty"`
-	MemTotalMb    uint64                 `protobuf:"varint,4,opt,name=mem_total_mb,json=memTotalMb,proto3" json:"mem_total_mb,omitempty"`
-	GpuLoad       float64                `protobuf:"fixed64,5,opt,name=gpu_load,json=gpuLoad,proto3" json:"gpu_load,omitempty"`
-	GpuMemUsedMb  uint64                 `protobuf:"varint,6,opt,name=gpu_mem_used_mb,json=gpuMemUsedMb,proto3" json:"gpu_mem_used_mb,omitempty"`
-	GpuMemTotalMb uint64                 `protobuf:"varint,7,opt,name=gpu_mem_total_mb,json=gpuMemTotalMb,proto3" json:"gpu_mem_total_mb,omitempty"`
-	NpuLoad       float64                `protobuf:"fixed64,8,opt,name=npu_load,json=npuLoad,proto3" json:"npu_load,omitempty"`
+	MonitorIndex  int32                  `protobuf:"varint,1,opt,name=monitor_index,json=monitorIndex,proto3" json:"monitor_index,omitempty"`
+	Subscribers   int32                  `protobuf:"varint,2,opt,name=subscribers,proto3" json:"subscribers,omitempty"`                       // streams sharing this monitor's capture
+	Captures      uint64                 `protobuf:"varint,3,opt,name=captures,proto3" json:"captures,omitempty"`                             // screenshots taken
+	FramesServed  uint64                 `protobuf:"varint,4,opt,name=frames_served,json=framesServed,proto3" json:"frames_served,omitempty"` // frames handed to streams
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *MetricsSample) Reset() {
-	*x = MetricsSample{}
+func (x *CaptureFeed) Reset() {
+	*x = CaptureFeed{}
 	mi := &file_orchestrator_proto_msgTypes[47]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *MetricsSample) String() string {
+func (x *CaptureFeed) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MetricsSample) ProtoMessage() {}
+func (*CaptureFeed) ProtoMessage() {}
 
-func (x *MetricsSample) ProtoReflect() protoreflect.Message {
+func (x *CaptureFeed) ProtoReflect() protoreflect.Message {
 	mi := &file_orchestrator_proto_msgTypes[47]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -3243,96 +3577,3894 @@ func (x *MetricsSample) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MetricsSample.ProtoReflect.Descriptor instead.
-func (*MetricsSample) Descriptor() ([]byte, []int) {
+// Deprecated: Use CaptureFeed.ProtoReflect.Descriptor instead.
+func (*CaptureFeed) Descriptor() ([]byte, []int) {
 	return file_orchestrator_proto_rawDescGZIP(), []int{47}
 }
 
-func (x *MetricsSample) GetTimestampMs() int64 {
+func (x *CaptureFeed) GetMonitorIndex() int32 {
 	if x != nil {
-		return x.TimestampMs
+		return x.MonitorIndex
 	}
 	return 0
 }
 
-func (x *MetricsSample) GetCpuLoad() float64 {
+func (x *CaptureFeed) GetSubscribers() int32 {
 	if x != nil {
-		return x.CpuLoad
+		return x.Subscribers
 	}
 	return 0
 }
 
-func (x *MetricsSample) GetMemUsedMb() uint64 {
+func (x *CaptureFeed) GetCaptures() uint64 {
 	if x != nil {
-		return x.MemUsedMb
+		return x.Captures
 	}
 	return 0
 }
 
-func (x *MetricsSample) GetMemTotalMb() uint64 {
+func (x *CaptureFeed) GetFramesServed() uint64 {
 	if x != nil {
-		return x.MemTotalMb
+		return x.FramesServed
 	}
 	return 0
 }
 
-func (x *MetricsSample) GetGpuLoad() float64 {
+type ListStreamsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Streams       []*StreamSession       `protobuf:"bytes,1,rep,name=streams,proto3" json:"streams,omitempty"`
+	Feeds         []*CaptureFeed         `protobuf:"bytes,2,rep,name=feeds,proto3" json:"feeds,omitempty"`
+	MaxViewers    int32                  `protobuf:"varint,3,opt,name=max_viewers,json=maxViewers,proto3" json:"max_viewers,omitempty"` // 0 means no limit
+	IdleTimeoutMs int64                  `protobuf:"varint,4,opt,name=idle_timeout_ms,json=idleTimeoutMs,proto3" json:"idle_timeout_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListStreamsResponse) Reset() {
+	*x = ListStreamsResponse{}
+	mi := &file_orchestrator_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListStreamsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListStreamsResponse) ProtoMessage() {}
+
+func (x *ListStreamsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[48]
 	if x != nil {
-		return x.GpuLoad
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-func (x *MetricsSample) GetGpuMemUsedMb() uint64 {
+// Deprecated: Use ListStreamsResponse.ProtoReflect.Descriptor instead.
+func (*ListStreamsResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *ListStreamsResponse) GetStreams() []*StreamSession {
 	if x != nil {
-		return x.GpuMemUsedMb
+		return x.Streams
 	}
-	return 0
+	return nil
 }
 
-func (x *MetricsSample) GetGpuMemTotalMb() uint64 {
+func (x *ListStreamsResponse) GetFeeds() []*CaptureFeed {
+	if x != nil {
+		return x.Feeds
+	}
+	return nil
+}
+
+func (x *ListStreamsResponse) GetMaxViewers() int32 {
+	if x != nil {
+		return x.MaxViewers
+	}
+	return 0
+}
+
+func (x *ListStreamsResponse) GetIdleTimeoutMs() int64 {
+	if x != nil {
+		return x.IdleTimeoutMs
+	}
+	return 0
+}
+
+type PlanPreviewRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Text          string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	MaxWorkers    int32                  `protobuf:"varint,3,opt,name=max_workers,json=maxWorkers,proto3" json:"max_workers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlanPreviewRequest) Reset() {
+	*x = PlanPreviewRequest{}
+	mi := &file_orchestrator_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlanPreviewRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlanPreviewRequest) ProtoMessage() {}
+
+func (x *PlanPreviewRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlanPreviewRequest.ProtoReflect.Descriptor instead.
+func (*PlanPreviewRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *PlanPreviewRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *PlanPreviewRequest) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *PlanPreviewRequest) GetMaxWorkers() int32 {
+	if x != nil {
+		return x.MaxWorkers
+	}
+	return 0
+}
+
+type PlanPreviewResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UsedAi        bool                   `protobuf:"varint,1,opt,name=used_ai,json=usedAi,proto3" json:"used_ai,omitempty"`
+	Notes         string                 `protobuf:"bytes,2,opt,name=notes,proto3" json:"notes,omitempty"`
+	Rationale     string                 `protobuf:"bytes,3,opt,name=rationale,proto3" json:"rationale,omitempty"`
+	Plan          *Plan                  `protobuf:"bytes,4,opt,name=plan,proto3" json:"plan,omitempty"`
+	Reduce        *ReduceSpec            `protobuf:"bytes,5,opt,name=reduce,proto3" json:"reduce,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlanPreviewResponse) Reset() {
+	*x = PlanPreviewResponse{}
+	mi := &file_orchestrator_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlanPreviewResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlanPreviewResponse) ProtoMessage() {}
+
+func (x *PlanPreviewResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlanPreviewResponse.ProtoReflect.Descriptor instead.
+func (*PlanPreviewResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *PlanPreviewResponse) GetUsedAi() bool {
+	if x != nil {
+		return x.UsedAi
+	}
+	return false
+}
+
+func (x *PlanPreviewResponse) GetNotes() string {
+	if x != nil {
+		return x.Notes
+	}
+	return ""
+}
+
+func (x *PlanPreviewResponse) GetRationale() string {
+	if x != nil {
+		return x.Rationale
+	}
+	return ""
+}
+
+func (x *PlanPreviewResponse) GetPlan() *Plan {
+	if x != nil {
+		return x.Plan
+	}
+	return nil
+}
+
+func (x *PlanPreviewResponse) GetReduce() *ReduceSpec {
+	if x != nil {
+		return x.Reduce
+	}
+	return nil
+}
+
+type PlanCostRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Plan          *Plan                  `protobuf:"bytes,2,opt,name=plan,proto3" json:"plan,omitempty"`                            // plan to estimate
+	DeviceIds     []string               `protobuf:"bytes,3,rep,name=device_ids,json=deviceIds,proto3" json:"device_ids,omitempty"` // optional: limit to these devices (empty = all)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlanCostRequest) Reset() {
+	*x = PlanCostRequest{}
+	mi := &file_orchestrator_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlanCostRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlanCostRequest) ProtoMessage() {}
+
+func (x *PlanCostRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlanCostRequest.ProtoReflect.Descriptor instead.
+func (*PlanCostRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *PlanCostRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *PlanCostRequest) GetPlan() *Plan {
+	if x != nil {
+		return x.Plan
+	}
+	return nil
+}
+
+func (x *PlanCostRequest) GetDeviceIds() []string {
+	if x != nil {
+		return x.DeviceIds
+	}
+	return nil
+}
+
+type PlanCostResponse struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	TotalPredictedMs      float64                `protobuf:"fixed64,1,opt,name=total_predicted_ms,json=totalPredictedMs,proto3" json:"total_predicted_ms,omitempty"` // best device's total latency
+	DeviceCosts           []*DeviceCostEstimate  `protobuf:"bytes,2,rep,name=device_costs,json=deviceCosts,proto3" json:"device_costs,omitempty"`
+	RecommendedDeviceId   string                 `protobuf:"bytes,3,opt,name=recommended_device_id,json=recommendedDeviceId,proto3" json:"recommended_device_id,omitempty"`
+	RecommendedDeviceName string                 `protobuf:"bytes,4,opt,name=recommended_device_name,json=recommendedDeviceName,proto3" json:"recommended_device_name,omitempty"`
+	HasUnknownCosts       bool                   `protobuf:"varint,5,opt,name=has_unknown_costs,json=hasUnknownCosts,proto3" json:"has_unknown_costs,omitempty"` // true if any step had unknown cost
+	Warning               string                 `protobuf:"bytes,6,opt,name=warning,proto3" json:"warning,omitempty"`                                           // warning message if applicable
+	Confidence            string                 `protobuf:"bytes,7,opt,name=confidence,proto3" json:"confidence,omitempty"`                                     // recommended device's confidence
+	Samples               int32                  `protobuf:"varint,8,opt,name=samples,proto3" json:"samples,omitempty"`                                          // recommended device's fewest step samples
+	TotalEnergyJ          float64                `protobuf:"fixed64,9,opt,name=total_energy_j,json=totalEnergyJ,proto3" json:"total_energy_j,omitempty"`         // recommended device's energy estimate
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *PlanCostResponse) Reset() {
+	*x = PlanCostResponse{}
+	mi := &file_orchestrator_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlanCostResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlanCostResponse) ProtoMessage() {}
+
+func (x *PlanCostResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlanCostResponse.ProtoReflect.Descriptor instead.
+func (*PlanCostResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *PlanCostResponse) GetTotalPredictedMs() float64 {
+	if x != nil {
+		return x.TotalPredictedMs
+	}
+	return 0
+}
+
+func (x *PlanCostResponse) GetDeviceCosts() []*DeviceCostEstimate {
+	if x != nil {
+		return x.DeviceCosts
+	}
+	return nil
+}
+
+func (x *PlanCostResponse) GetRecommendedDeviceId() string {
+	if x != nil {
+		return x.RecommendedDeviceId
+	}
+	return ""
+}
+
+func (x *PlanCostResponse) GetRecommendedDeviceName() string {
+	if x != nil {
+		return x.RecommendedDeviceName
+	}
+	return ""
+}
+
+func (x *PlanCostResponse) GetHasUnknownCosts() bool {
+	if x != nil {
+		return x.HasUnknownCosts
+	}
+	return false
+}
+
+func (x *PlanCostResponse) GetWarning() string {
+	if x != nil {
+		return x.Warning
+	}
+	return ""
+}
+
+func (x *PlanCostResponse) GetConfidence() string {
+	if x != nil {
+		return x.Confidence
+	}
+	return ""
+}
+
+func (x *PlanCostResponse) GetSamples() int32 {
+	if x != nil {
+		return x.Samples
+	}
+	return 0
+}
+
+func (x *PlanCostResponse) GetTotalEnergyJ() float64 {
+	if x != nil {
+		return x.TotalEnergyJ
+	}
+	return 0
+}
+
+type DeviceCostEstimate struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId            string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	DeviceName          string                 `protobuf:"bytes,2,opt,name=device_name,json=deviceName,proto3" json:"device_name,omitempty"`
+	TotalMs             float64                `protobuf:"fixed64,3,opt,name=total_ms,json=totalMs,proto3" json:"total_ms,omitempty"`
+	StepCosts           []*StepCostEstimate    `protobuf:"bytes,4,rep,name=step_costs,json=stepCosts,proto3" json:"step_costs,omitempty"`
+	EstimatedPeakRamMb  uint64                 `protobuf:"varint,5,opt,name=estimated_peak_ram_mb,json=estimatedPeakRamMb,proto3" json:"estimated_peak_ram_mb,omitempty"`
+	RamSufficient       bool                   `protobuf:"varint,6,opt,name=ram_sufficient,json=ramSufficient,proto3" json:"ram_sufficient,omitempty"`                       // false if estimated RAM > device free RAM
+	Confidence          string                 `protobuf:"bytes,7,opt,name=confidence,proto3" json:"confidence,omitempty"`                                                   // lowest confidence of its steps
+	Samples             int32                  `protobuf:"varint,8,opt,name=samples,proto3" json:"samples,omitempty"`                                                        // fewest samples behind any step
+	EnergyJ             float64                `protobuf:"fixed64,9,opt,name=energy_j,json=energyJ,proto3" json:"energy_j,omitempty"`                                        // sum of its steps' energy
+	BatteryDrainPercent float64                `protobuf:"fixed64,10,opt,name=battery_drain_percent,json=batteryDrainPercent,proto3" json:"battery_drain_percent,omitempty"` // of a full battery, if on battery power
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *DeviceCostEstimate) Reset() {
+	*x = DeviceCostEstimate{}
+	mi := &file_orchestrator_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeviceCostEstimate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceCostEstimate) ProtoMessage() {}
+
+func (x *DeviceCostEstimate) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceCostEstimate.ProtoReflect.Descriptor instead.
+func (*DeviceCostEstimate) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *DeviceCostEstimate) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *DeviceCostEstimate) GetDeviceName() string {
+	if x != nil {
+		return x.DeviceName
+	}
+	return ""
+}
+
+func (x *DeviceCostEstimate) GetTotalMs() float64 {
+	if x != nil {
+		return x.TotalMs
+	}
+	return 0
+}
+
+func (x *DeviceCostEstimate) GetStepCosts() []*StepCostEstimate {
+	if x != nil {
+		return x.StepCosts
+	}
+	return nil
+}
+
+func (x *DeviceCostEstimate) GetEstimatedPeakRamMb() uint64 {
+	if x != nil {
+		return x.EstimatedPeakRamMb
+	}
+	return 0
+}
+
+func (x *DeviceCostEstimate) GetRamSufficient() bool {
+	if x != nil {
+		return x.RamSufficient
+	}
+	return false
+}
+
+func (x *DeviceCostEstimate) GetConfidence() string {
+	if x != nil {
+		return x.Confidence
+	}
+	return ""
+}
+
+func (x *DeviceCostEstimate) GetSamples() int32 {
+	if x != nil {
+		return x.Samples
+	}
+	return 0
+}
+
+func (x *DeviceCostEstimate) GetEnergyJ() float64 {
+	if x != nil {
+		return x.EnergyJ
+	}
+	return 0
+}
+
+func (x *DeviceCostEstimate) GetBatteryDrainPercent() float64 {
+	if x != nil {
+		return x.BatteryDrainPercent
+	}
+	return 0
+}
+
+type StepCostEstimate struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	TaskId            string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	Kind              string                 `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	PredictedMs       float64                `protobuf:"fixed64,3,opt,name=predicted_ms,json=predictedMs,proto3" json:"predicted_ms,omitempty"`
+	PredictedMemoryMb float64                `protobuf:"fixed64,4,opt,name=predicted_memory_mb,json=predictedMemoryMb,proto3" json:"predicted_memory_mb,omitempty"`
+	UnknownCost       bool                   `protobuf:"varint,5,opt,name=unknown_cost,json=unknownCost,proto3" json:"unknown_cost,omitempty"`       // true if step type not recognized
+	Notes             string                 `protobuf:"bytes,6,opt,name=notes,proto3" json:"notes,omitempty"`                                       // e.g., "using default prefill TPS"
+	TransferMs        float64                `protobuf:"fixed64,7,opt,name=transfer_ms,json=transferMs,proto3" json:"transfer_ms,omitempty"`         // time to stage inputs onto the device
+	TransferBytes     int64                  `protobuf:"varint,8,opt,name=transfer_bytes,json=transferBytes,proto3" json:"transfer_bytes,omitempty"` // input bytes not already on the device
+	Confidence        string                 `protobuf:"bytes,9,opt,name=confidence,proto3" json:"confidence,omitempty"`                             // "default", "low", "medium" or "high"
+	Samples           int32                  `protobuf:"varint,10,opt,name=samples,proto3" json:"samples,omitempty"`                                 // completed tasks the estimate was learned from
+	EnergyJ           float64                `protobuf:"fixed64,11,opt,name=energy_j,json=energyJ,proto3" json:"energy_j,omitempty"`                 // predicted energy use in joules
+	NetworkMs         float64                `protobuf:"fixed64,12,opt,name=network_ms,json=networkMs,proto3" json:"network_ms,omitempty"`           // sending the task and its result over the network
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *StepCostEstimate) Reset() {
+	*x = StepCostEstimate{}
+	mi := &file_orchestrator_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StepCostEstimate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StepCostEstimate) ProtoMessage() {}
+
+func (x *StepCostEstimate) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StepCostEstimate.ProtoReflect.Descriptor instead.
+func (*StepCostEstimate) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *StepCostEstimate) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *StepCostEstimate) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *StepCostEstimate) GetPredictedMs() float64 {
+	if x != nil {
+		return x.PredictedMs
+	}
+	return 0
+}
+
+func (x *StepCostEstimate) GetPredictedMemoryMb() float64 {
+	if x != nil {
+		return x.PredictedMemoryMb
+	}
+	return 0
+}
+
+func (x *StepCostEstimate) GetUnknownCost() bool {
+	if x != nil {
+		return x.UnknownCost
+	}
+	return false
+}
+
+func (x *StepCostEstimate) GetNotes() string {
+	if x != nil {
+		return x.Notes
+	}
+	return ""
+}
+
+func (x *StepCostEstimate) GetTransferMs() float64 {
+	if x != nil {
+		return x.TransferMs
+	}
+	return 0
+}
+
+func (x *StepCostEstimate) GetTransferBytes() int64 {
+	if x != nil {
+		return x.TransferBytes
+	}
+	return 0
+}
+
+func (x *StepCostEstimate) GetConfidence() string {
+	if x != nil {
+		return x.Confidence
+	}
+	return ""
+}
+
+func (x *StepCostEstimate) GetSamples() int32 {
+	if x != nil {
+		return x.Samples
+	}
+	return 0
+}
+
+func (x *StepCostEstimate) GetEnergyJ() float64 {
+	if x != nil {
+		return x.EnergyJ
+	}
+	return 0
+}
+
+func (x *StepCostEstimate) GetNetworkMs() float64 {
+	if x != nil {
+		return x.NetworkMs
+	}
+	return 0
+}
+
+type DownloadTicketRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"` // relative path under shared root, e.g. "test.txt"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadTicketRequest) Reset() {
+	*x = DownloadTicketRequest{}
+	mi := &file_orchestrator_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadTicketRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadTicketRequest) ProtoMessage() {}
+
+func (x *DownloadTicketRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadTicketRequest.ProtoReflect.Descriptor instead.
+func (*DownloadTicketRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *DownloadTicketRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type DownloadTicketResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`                                         // one-time-use download token
+	Filename      string                 `protobuf:"bytes,2,opt,name=filename,proto3" json:"filename,omitempty"`                                   // basename of the file
+	SizeBytes     int64                  `protobuf:"varint,3,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`               // file size
+	ExpiresUnixMs int64                  `protobuf:"varint,4,opt,name=expires_unix_ms,json=expiresUnixMs,proto3" json:"expires_unix_ms,omitempty"` // absolute expiry (ms since epoch)
+	Live          bool                   `protobuf:"varint,5,opt,name=live,proto3" json:"live,omitempty"`                                          // still being written; a GET without Range follows it until it is finished
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadTicketResponse) Reset() {
+	*x = DownloadTicketResponse{}
+	mi := &file_orchestrator_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadTicketResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadTicketResponse) ProtoMessage() {}
+
+func (x *DownloadTicketResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadTicketResponse.ProtoReflect.Descriptor instead.
+func (*DownloadTicketResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{56}
+}
+
+func (x *DownloadTicketResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *DownloadTicketResponse) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *DownloadTicketResponse) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *DownloadTicketResponse) GetExpiresUnixMs() int64 {
+	if x != nil {
+		return x.ExpiresUnixMs
+	}
+	return 0
+}
+
+func (x *DownloadTicketResponse) GetLive() bool {
+	if x != nil {
+		return x.Live
+	}
+	return false
+}
+
+type UploadTicketRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`                             // relative path under shared root, e.g. "models/a.gguf"
+	SizeBytes     int64                  `protobuf:"varint,2,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"` // total size of the file to upload
+	Sha256        string                 `protobuf:"bytes,3,opt,name=sha256,proto3" json:"sha256,omitempty"`                         // expected hex digest (optional, verified on completion)
+	Overwrite     bool                   `protobuf:"varint,4,opt,name=overwrite,proto3" json:"overwrite,omitempty"`                  // replace an existing file
+	SessionId     string                 `protobuf:"bytes,5,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`  // session on the device issuing the ticket
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadTicketRequest) Reset() {
+	*x = UploadTicketRequest{}
+	mi := &file_orchestrator_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadTicketRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadTicketRequest) ProtoMessage() {}
+
+func (x *UploadTicketRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadTicketRequest.ProtoReflect.Descriptor instead.
+func (*UploadTicketRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *UploadTicketRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *UploadTicketRequest) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *UploadTicketRequest) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *UploadTicketRequest) GetOverwrite() bool {
+	if x != nil {
+		return x.Overwrite
+	}
+	return false
+}
+
+func (x *UploadTicketRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type UploadTicketResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Token          string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`                                            // upload token for /bulk/upload/<token>
+	Path           string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`                                              // resolved relative path under shared root
+	ChunkSizeBytes int64                  `protobuf:"varint,3,opt,name=chunk_size_bytes,json=chunkSizeBytes,proto3" json:"chunk_size_bytes,omitempty"` // suggested PUT chunk size
+	ExpiresUnixMs  int64                  `protobuf:"varint,4,opt,name=expires_unix_ms,json=expiresUnixMs,proto3" json:"expires_unix_ms,omitempty"`    // expiry; extended on every chunk
+	MaxUploadBytes int64                  `protobuf:"varint,5,opt,name=max_upload_bytes,json=maxUploadBytes,proto3" json:"max_upload_bytes,omitempty"` // per-file limit (0 = unlimited)
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *UploadTicketResponse) Reset() {
+	*x = UploadTicketResponse{}
+	mi := &file_orchestrator_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadTicketResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadTicketResponse) ProtoMessage() {}
+
+func (x *UploadTicketResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadTicketResponse.ProtoReflect.Descriptor instead.
+func (*UploadTicketResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *UploadTicketResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *UploadTicketResponse) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *UploadTicketResponse) GetChunkSizeBytes() int64 {
+	if x != nil {
+		return x.ChunkSizeBytes
+	}
+	return 0
+}
+
+func (x *UploadTicketResponse) GetExpiresUnixMs() int64 {
+	if x != nil {
+		return x.ExpiresUnixMs
+	}
+	return 0
+}
+
+func (x *UploadTicketResponse) GetMaxUploadBytes() int64 {
+	if x != nil {
+		return x.MaxUploadBytes
+	}
+	return 0
+}
+
+type PutFileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	DeviceId      string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"` // target device (empty = local)
+	Path          string                 `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`                         // relative path under shared root
+	Content       []byte                 `protobuf:"bytes,4,opt,name=content,proto3" json:"content,omitempty"`                   // max 10MB; use upload tickets for larger files
+	Sha256        string                 `protobuf:"bytes,5,opt,name=sha256,proto3" json:"sha256,omitempty"`                     // expected hex digest (optional)
+	Overwrite     bool                   `protobuf:"varint,6,opt,name=overwrite,proto3" json:"overwrite,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutFileRequest) Reset() {
+	*x = PutFileRequest{}
+	mi := &file_orchestrator_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutFileRequest) ProtoMessage() {}
+
+func (x *PutFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutFileRequest.ProtoReflect.Descriptor instead.
+func (*PutFileRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{59}
+}
+
+func (x *PutFileRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *PutFileRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *PutFileRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *PutFileRequest) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+func (x *PutFileRequest) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *PutFileRequest) GetOverwrite() bool {
+	if x != nil {
+		return x.Overwrite
+	}
+	return false
+}
+
+type PutFileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"` // resolved relative path under shared root
+	SizeBytes     int64                  `protobuf:"varint,2,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	Sha256        string                 `protobuf:"bytes,3,opt,name=sha256,proto3" json:"sha256,omitempty"` // digest of the written file
+	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutFileResponse) Reset() {
+	*x = PutFileResponse{}
+	mi := &file_orchestrator_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutFileResponse) ProtoMessage() {}
+
+func (x *PutFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutFileResponse.ProtoReflect.Descriptor instead.
+func (*PutFileResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{60}
+}
+
+func (x *PutFileResponse) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *PutFileResponse) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *PutFileResponse) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *PutFileResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ReadFileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	DeviceId      string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"` // target device (empty = local)
+	Path          string                 `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
+	Mode          ReadMode               `protobuf:"varint,4,opt,name=mode,proto3,enum=edgemesh.ReadMode" json:"mode,omitempty"`
+	MaxBytes      int32                  `protobuf:"varint,5,opt,name=max_bytes,json=maxBytes,proto3" json:"max_bytes,omitempty"` // default 65536, max 10MB
+	Offset        int64                  `protobuf:"varint,6,opt,name=offset,proto3" json:"offset,omitempty"`                     // for RANGE mode
+	Length        int64                  `protobuf:"varint,7,opt,name=length,proto3" json:"length,omitempty"`                     // for RANGE mode
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadFileRequest) Reset() {
+	*x = ReadFileRequest{}
+	mi := &file_orchestrator_proto_msgTypes[61]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadFileRequest) ProtoMessage() {}
+
+func (x *ReadFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[61]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadFileRequest.ProtoReflect.Descriptor instead.
+func (*ReadFileRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{61}
+}
+
+func (x *ReadFileRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *ReadFileRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *ReadFileRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ReadFileRequest) GetMode() ReadMode {
+	if x != nil {
+		return x.Mode
+	}
+	return ReadMode_READ_MODE_FULL
+}
+
+func (x *ReadFileRequest) GetMaxBytes() int32 {
+	if x != nil {
+		return x.MaxBytes
+	}
+	return 0
+}
+
+func (x *ReadFileRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ReadFileRequest) GetLength() int64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+type ReadFileResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Content        []byte                 `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
+	SizeBytes      int64                  `protobuf:"varint,2,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`             // actual file size
+	BytesReturned  int64                  `protobuf:"varint,3,opt,name=bytes_returned,json=bytesReturned,proto3" json:"bytes_returned,omitempty"` // bytes returned in content
+	Truncated      bool                   `protobuf:"varint,4,opt,name=truncated,proto3" json:"truncated,omitempty"`
+	Error          string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	ContentPreview string                 `protobuf:"bytes,6,opt,name=content_preview,json=contentPreview,proto3" json:"content_preview,omitempty"` // first ~2KB decoded if text
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ReadFileResponse) Reset() {
+	*x = ReadFileResponse{}
+	mi := &file_orchestrator_proto_msgTypes[62]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadFileResponse) ProtoMessage() {}
+
+func (x *ReadFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[62]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadFileResponse.ProtoReflect.Descriptor instead.
+func (*ReadFileResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{62}
+}
+
+func (x *ReadFileResponse) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+func (x *ReadFileResponse) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *ReadFileResponse) GetBytesReturned() int64 {
+	if x != nil {
+		return x.BytesReturned
+	}
+	return 0
+}
+
+func (x *ReadFileResponse) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+func (x *ReadFileResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ReadFileResponse) GetContentPreview() string {
+	if x != nil {
+		return x.ContentPreview
+	}
+	return ""
+}
+
+type FileEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`                             // slash-separated, relative to shared root
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`                             // "file", "dir", "symlink", "other"
+	SizeBytes     int64                  `protobuf:"varint,4,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"` // 0 for non-files
+	ModTimeUnixMs int64                  `protobuf:"varint,5,opt,name=mod_time_unix_ms,json=modTimeUnixMs,proto3" json:"mod_time_unix_ms,omitempty"`
+	Mode          string                 `protobuf:"bytes,6,opt,name=mode,proto3" json:"mode,omitempty"` // e.g. "-rw-r--r--"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileEntry) Reset() {
+	*x = FileEntry{}
+	mi := &file_orchestrator_proto_msgTypes[63]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileEntry) ProtoMessage() {}
+
+func (x *FileEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[63]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileEntry.ProtoReflect.Descriptor instead.
+func (*FileEntry) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{63}
+}
+
+func (x *FileEntry) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *FileEntry) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *FileEntry) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *FileEntry) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *FileEntry) GetModTimeUnixMs() int64 {
+	if x != nil {
+		return x.ModTimeUnixMs
+	}
+	return 0
+}
+
+func (x *FileEntry) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+type ListDirRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	DeviceId      string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`                 // target device (empty = local)
+	Path          string                 `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`                                         // directory relative to shared root (empty = root)
+	Glob          string                 `protobuf:"bytes,4,opt,name=glob,proto3" json:"glob,omitempty"`                                         // filter on entry names, e.g. "*.gguf"
+	PageSize      int32                  `protobuf:"varint,5,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`                // default 100, max 1000
+	PageToken     string                 `protobuf:"bytes,6,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`              // from a previous response
+	IncludeHidden bool                   `protobuf:"varint,7,opt,name=include_hidden,json=includeHidden,proto3" json:"include_hidden,omitempty"` // include dot-files
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDirRequest) Reset() {
+	*x = ListDirRequest{}
+	mi := &file_orchestrator_proto_msgTypes[64]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDirRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDirRequest) ProtoMessage() {}
+
+func (x *ListDirRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[64]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDirRequest.ProtoReflect.Descriptor instead.
+func (*ListDirRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{64}
+}
+
+func (x *ListDirRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *ListDirRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *ListDirRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ListDirRequest) GetGlob() string {
+	if x != nil {
+		return x.Glob
+	}
+	return ""
+}
+
+func (x *ListDirRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListDirRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListDirRequest) GetIncludeHidden() bool {
+	if x != nil {
+		return x.IncludeHidden
+	}
+	return false
+}
+
+type ListDirResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"` // listed directory, relative to shared root
+	Entries       []*FileEntry           `protobuf:"bytes,2,rep,name=entries,proto3" json:"entries,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,3,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"` // empty on the last page
+	TotalMatches  int32                  `protobuf:"varint,4,opt,name=total_matches,json=totalMatches,proto3" json:"total_matches,omitempty"`     // entries matching the filter across all pages
+	Error         string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDirResponse) Reset() {
+	*x = ListDirResponse{}
+	mi := &file_orchestrator_proto_msgTypes[65]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDirResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDirResponse) ProtoMessage() {}
+
+func (x *ListDirResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[65]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDirResponse.ProtoReflect.Descriptor instead.
+func (*ListDirResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{65}
+}
+
+func (x *ListDirResponse) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ListDirResponse) GetEntries() []*FileEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *ListDirResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+func (x *ListDirResponse) GetTotalMatches() int32 {
+	if x != nil {
+		return x.TotalMatches
+	}
+	return 0
+}
+
+func (x *ListDirResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type StatFileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	DeviceId      string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"` // target device (empty = local)
+	Path          string                 `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`                         // relative to shared root
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatFileRequest) Reset() {
+	*x = StatFileRequest{}
+	mi := &file_orchestrator_proto_msgTypes[66]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatFileRequest) ProtoMessage() {}
+
+func (x *StatFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[66]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatFileRequest.ProtoReflect.Descriptor instead.
+func (*StatFileRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{66}
+}
+
+func (x *StatFileRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *StatFileRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *StatFileRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type StatFileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Exists        bool                   `protobuf:"varint,1,opt,name=exists,proto3" json:"exists,omitempty"`
+	Entry         *FileEntry             `protobuf:"bytes,2,opt,name=entry,proto3" json:"entry,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatFileResponse) Reset() {
+	*x = StatFileResponse{}
+	mi := &file_orchestrator_proto_msgTypes[67]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatFileResponse) ProtoMessage() {}
+
+func (x *StatFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[67]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatFileResponse.ProtoReflect.Descriptor instead.
+func (*StatFileResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{67}
+}
+
+func (x *StatFileResponse) GetExists() bool {
+	if x != nil {
+		return x.Exists
+	}
+	return false
+}
+
+func (x *StatFileResponse) GetEntry() *FileEntry {
+	if x != nil {
+		return x.Entry
+	}
+	return nil
+}
+
+func (x *StatFileResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type SyncFile struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"` // slash-separated, relative to shared root
+	SizeBytes     int64                  `protobuf:"varint,2,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	ModTimeMs     int64                  `protobuf:"varint,3,opt,name=mod_time_ms,json=modTimeMs,proto3" json:"mod_time_ms,omitempty"`
+	Sha256        string                 `protobuf:"bytes,4,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncFile) Reset() {
+	*x = SyncFile{}
+	mi := &file_orchestrator_proto_msgTypes[68]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncFile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncFile) ProtoMessage() {}
+
+func (x *SyncFile) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[68]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncFile.ProtoReflect.Descriptor instead.
+func (*SyncFile) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{68}
+}
+
+func (x *SyncFile) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *SyncFile) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *SyncFile) GetModTimeMs() int64 {
+	if x != nil {
+		return x.ModTimeMs
+	}
+	return 0
+}
+
+func (x *SyncFile) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+type SyncManifestRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncManifestRequest) Reset() {
+	*x = SyncManifestRequest{}
+	mi := &file_orchestrator_proto_msgTypes[69]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncManifestRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncManifestRequest) ProtoMessage() {}
+
+func (x *SyncManifestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[69]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncManifestRequest.ProtoReflect.Descriptor instead.
+func (*SyncManifestRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{69}
+}
+
+func (x *SyncManifestRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type SyncManifestResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	DeviceName    string                 `protobuf:"bytes,2,opt,name=device_name,json=deviceName,proto3" json:"device_name,omitempty"`
+	Files         []*SyncFile            `protobuf:"bytes,3,rep,name=files,proto3" json:"files,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncManifestResponse) Reset() {
+	*x = SyncManifestResponse{}
+	mi := &file_orchestrator_proto_msgTypes[70]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncManifestResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncManifestResponse) ProtoMessage() {}
+
+func (x *SyncManifestResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[70]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncManifestResponse.ProtoReflect.Descriptor instead.
+func (*SyncManifestResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{70}
+}
+
+func (x *SyncManifestResponse) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *SyncManifestResponse) GetDeviceName() string {
+	if x != nil {
+		return x.DeviceName
+	}
+	return ""
+}
+
+func (x *SyncManifestResponse) GetFiles() []*SyncFile {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+type SyncStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	DeviceId      string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"` // target device (empty = local)
+	SyncNow       bool                   `protobuf:"varint,3,opt,name=sync_now,json=syncNow,proto3" json:"sync_now,omitempty"`   // start a round with every peer immediately
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncStatusRequest) Reset() {
+	*x = SyncStatusRequest{}
+	mi := &file_orchestrator_proto_msgTypes[71]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncStatusRequest) ProtoMessage() {}
+
+func (x *SyncStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[71]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncStatusRequest.ProtoReflect.Descriptor instead.
+func (*SyncStatusRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{71}
+}
+
+func (x *SyncStatusRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *SyncStatusRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *SyncStatusRequest) GetSyncNow() bool {
+	if x != nil {
+		return x.SyncNow
+	}
+	return false
+}
+
+type SyncPeerStatus struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	PeerId         string                 `protobuf:"bytes,1,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`
+	PeerName       string                 `protobuf:"bytes,2,opt,name=peer_name,json=peerName,proto3" json:"peer_name,omitempty"`
+	LastSyncUnixMs int64                  `protobuf:"varint,3,opt,name=last_sync_unix_ms,json=lastSyncUnixMs,proto3" json:"last_sync_unix_ms,omitempty"`
+	LastError      string                 `protobuf:"bytes,4,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	Rounds         int32                  `protobuf:"varint,5,opt,name=rounds,proto3" json:"rounds,omitempty"`
+	FilesPulled    int32                  `protobuf:"varint,6,opt,name=files_pulled,json=filesPulled,proto3" json:"files_pulled,omitempty"`    // last round
+	FilesDeleted   int32                  `protobuf:"varint,7,opt,name=files_deleted,json=filesDeleted,proto3" json:"files_deleted,omitempty"` // last round
+	Conflicts      int32                  `protobuf:"varint,8,opt,name=conflicts,proto3" json:"conflicts,omitempty"`                           // last round
+	Pending        int32                  `protobuf:"varint,9,opt,name=pending,proto3" json:"pending,omitempty"`                               // failed actions in the last round
+	BytesPulled    int64                  `protobuf:"varint,10,opt,name=bytes_pulled,json=bytesPulled,proto3" json:"bytes_pulled,omitempty"`   // total since start
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SyncPeerStatus) Reset() {
+	*x = SyncPeerStatus{}
+	mi := &file_orchestrator_proto_msgTypes[72]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncPeerStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncPeerStatus) ProtoMessage() {}
+
+func (x *SyncPeerStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[72]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncPeerStatus.ProtoReflect.Descriptor instead.
+func (*SyncPeerStatus) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{72}
+}
+
+func (x *SyncPeerStatus) GetPeerId() string {
+	if x != nil {
+		return x.PeerId
+	}
+	return ""
+}
+
+func (x *SyncPeerStatus) GetPeerName() string {
+	if x != nil {
+		return x.PeerName
+	}
+	return ""
+}
+
+func (x *SyncPeerStatus) GetLastSyncUnixMs() int64 {
+	if x != nil {
+		return x.LastSyncUnixMs
+	}
+	return 0
+}
+
+func (x *SyncPeerStatus) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *SyncPeerStatus) GetRounds() int32 {
+	if x != nil {
+		return x.Rounds
+	}
+	return 0
+}
+
+func (x *SyncPeerStatus) GetFilesPulled() int32 {
+	if x != nil {
+		return x.FilesPulled
+	}
+	return 0
+}
+
+func (x *SyncPeerStatus) GetFilesDeleted() int32 {
+	if x != nil {
+		return x.FilesDeleted
+	}
+	return 0
+}
+
+func (x *SyncPeerStatus) GetConflicts() int32 {
+	if x != nil {
+		return x.Conflicts
+	}
+	return 0
+}
+
+func (x *SyncPeerStatus) GetPending() int32 {
+	if x != nil {
+		return x.Pending
+	}
+	return 0
+}
+
+func (x *SyncPeerStatus) GetBytesPulled() int64 {
+	if x != nil {
+		return x.BytesPulled
+	}
+	return 0
+}
+
+type SyncStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Enabled       bool                   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	DeviceId      string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	LocalFiles    int32                  `protobuf:"varint,3,opt,name=local_files,json=localFiles,proto3" json:"local_files,omitempty"`
+	LocalBytes    int64                  `protobuf:"varint,4,opt,name=local_bytes,json=localBytes,proto3" json:"local_bytes,omitempty"`
+	IntervalMs    int64                  `protobuf:"varint,5,opt,name=interval_ms,json=intervalMs,proto3" json:"interval_ms,omitempty"`
+	Paired        []string               `protobuf:"bytes,6,rep,name=paired,proto3" json:"paired,omitempty"` // SYNC_PEERS entries ("*" = all devices)
+	Peers         []*SyncPeerStatus      `protobuf:"bytes,7,rep,name=peers,proto3" json:"peers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncStatusResponse) Reset() {
+	*x = SyncStatusResponse{}
+	mi := &file_orchestrator_proto_msgTypes[73]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncStatusResponse) ProtoMessage() {}
+
+func (x *SyncStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[73]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncStatusResponse.ProtoReflect.Descriptor instead.
+func (*SyncStatusResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{73}
+}
+
+func (x *SyncStatusResponse) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *SyncStatusResponse) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *SyncStatusResponse) GetLocalFiles() int32 {
+	if x != nil {
+		return x.LocalFiles
+	}
+	return 0
+}
+
+func (x *SyncStatusResponse) GetLocalBytes() int64 {
+	if x != nil {
+		return x.LocalBytes
+	}
+	return 0
+}
+
+func (x *SyncStatusResponse) GetIntervalMs() int64 {
+	if x != nil {
+		return x.IntervalMs
+	}
+	return 0
+}
+
+func (x *SyncStatusResponse) GetPaired() []string {
+	if x != nil {
+		return x.Paired
+	}
+	return nil
+}
+
+func (x *SyncStatusResponse) GetPeers() []*SyncPeerStatus {
+	if x != nil {
+		return x.Peers
+	}
+	return nil
+}
+
+type LocateArtifactsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Sha256        []string               `protobuf:"bytes,2,rep,name=sha256,proto3" json:"sha256,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LocateArtifactsRequest) Reset() {
+	*x = LocateArtifactsRequest{}
+	mi := &file_orchestrator_proto_msgTypes[74]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LocateArtifactsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LocateArtifactsRequest) ProtoMessage() {}
+
+func (x *LocateArtifactsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[74]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LocateArtifactsRequest.ProtoReflect.Descriptor instead.
+func (*LocateArtifactsRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{74}
+}
+
+func (x *LocateArtifactsRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *LocateArtifactsRequest) GetSha256() []string {
+	if x != nil {
+		return x.Sha256
+	}
+	return nil
+}
+
+type ArtifactLocation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sha256        string                 `protobuf:"bytes,1,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"` // relative to the shared root
+	SizeBytes     int64                  `protobuf:"varint,3,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ArtifactLocation) Reset() {
+	*x = ArtifactLocation{}
+	mi := &file_orchestrator_proto_msgTypes[75]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ArtifactLocation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArtifactLocation) ProtoMessage() {}
+
+func (x *ArtifactLocation) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[75]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArtifactLocation.ProtoReflect.Descriptor instead.
+func (*ArtifactLocation) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{75}
+}
+
+func (x *ArtifactLocation) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *ArtifactLocation) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ArtifactLocation) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+type LocateArtifactsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	Found         []*ArtifactLocation    `protobuf:"bytes,2,rep,name=found,proto3" json:"found,omitempty"` // one per hash held by the device
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LocateArtifactsResponse) Reset() {
+	*x = LocateArtifactsResponse{}
+	mi := &file_orchestrator_proto_msgTypes[76]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LocateArtifactsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LocateArtifactsResponse) ProtoMessage() {}
+
+func (x *LocateArtifactsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[76]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LocateArtifactsResponse.ProtoReflect.Descriptor instead.
+func (*LocateArtifactsResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{76}
+}
+
+func (x *LocateArtifactsResponse) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *LocateArtifactsResponse) GetFound() []*ArtifactLocation {
+	if x != nil {
+		return x.Found
+	}
+	return nil
+}
+
+type StageFileRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	SessionId      string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Path           string                 `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`     // destination, relative to the shared root
+	Sha256         string                 `protobuf:"bytes,4,opt,name=sha256,proto3" json:"sha256,omitempty"` // expected hash (empty = trust X-Content-SHA256)
+	SizeBytes      int64                  `protobuf:"varint,5,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	SourceDeviceId string                 `protobuf:"bytes,6,opt,name=source_device_id,json=sourceDeviceId,proto3" json:"source_device_id,omitempty"` // registered device holding the file
+	Token          string                 `protobuf:"bytes,7,opt,name=token,proto3" json:"token,omitempty"`                                           // download ticket issued by the source device
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *StageFileRequest) Reset() {
+	*x = StageFileRequest{}
+	mi := &file_orchestrator_proto_msgTypes[77]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StageFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StageFileRequest) ProtoMessage() {}
+
+func (x *StageFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[77]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StageFileRequest.ProtoReflect.Descriptor instead.
+func (*StageFileRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{77}
+}
+
+func (x *StageFileRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *StageFileRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *StageFileRequest) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *StageFileRequest) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *StageFileRequest) GetSourceDeviceId() string {
+	if x != nil {
+		return x.SourceDeviceId
+	}
+	return ""
+}
+
+func (x *StageFileRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+type StageFileResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Path           string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	SizeBytes      int64                  `protobuf:"varint,2,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	Sha256         string                 `protobuf:"bytes,3,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	AlreadyPresent bool                   `protobuf:"varint,4,opt,name=already_present,json=alreadyPresent,proto3" json:"already_present,omitempty"` // destination already held the content
+	Error          string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *StageFileResponse) Reset() {
+	*x = StageFileResponse{}
+	mi := &file_orchestrator_proto_msgTypes[78]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StageFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StageFileResponse) ProtoMessage() {}
+
+func (x *StageFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[78]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StageFileResponse.ProtoReflect.Descriptor instead.
+func (*StageFileResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{78}
+}
+
+func (x *StageFileResponse) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *StageFileResponse) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *StageFileResponse) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *StageFileResponse) GetAlreadyPresent() bool {
+	if x != nil {
+		return x.AlreadyPresent
+	}
+	return false
+}
+
+func (x *StageFileResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ChatMemorySync struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`                   // which device this memory belongs to
+	LastUpdatedMs int64                  `protobuf:"varint,2,opt,name=last_updated_ms,json=lastUpdatedMs,proto3" json:"last_updated_ms,omitempty"` // timestamp of sender's memory
+	MemoryJson    string                 `protobuf:"bytes,3,opt,name=memory_json,json=memoryJson,proto3" json:"memory_json,omitempty"`             // full JSON if pushing, empty if pulling
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChatMemorySync) Reset() {
+	*x = ChatMemorySync{}
+	mi := &file_orchestrator_proto_msgTypes[79]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChatMemorySync) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChatMemorySync) ProtoMessage() {}
+
+func (x *ChatMemorySync) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[79]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChatMemorySync.ProtoReflect.Descriptor instead.
+func (*ChatMemorySync) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{79}
+}
+
+func (x *ChatMemorySync) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *ChatMemorySync) GetLastUpdatedMs() int64 {
+	if x != nil {
+		return x.LastUpdatedMs
+	}
+	return 0
+}
+
+func (x *ChatMemorySync) GetMemoryJson() string {
+	if x != nil {
+		return x.MemoryJson
+	}
+	return ""
+}
+
+type ChatMemorySyncResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Updated       bool                   `protobuf:"varint,1,opt,name=updated,proto3" json:"updated,omitempty"`                        // true if receiver's memory was updated
+	MemoryJson    string                 `protobuf:"bytes,2,opt,name=memory_json,json=memoryJson,proto3" json:"memory_json,omitempty"` // new JSON if updated, empty otherwise
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChatMemorySyncResponse) Reset() {
+	*x = ChatMemorySyncResponse{}
+	mi := &file_orchestrator_proto_msgTypes[80]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChatMemorySyncResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChatMemorySyncResponse) ProtoMessage() {}
+
+func (x *ChatMemorySyncResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[80]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChatMemorySyncResponse.ProtoReflect.Descriptor instead.
+func (*ChatMemorySyncResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{80}
+}
+
+func (x *ChatMemorySyncResponse) GetUpdated() bool {
+	if x != nil {
+		return x.Updated
+	}
+	return false
+}
+
+func (x *ChatMemorySyncResponse) GetMemoryJson() string {
+	if x != nil {
+		return x.MemoryJson
+	}
+	return ""
+}
+
+type ChatMemoryData struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MemoryJson    string                 `protobuf:"bytes,1,opt,name=memory_json,json=memoryJson,proto3" json:"memory_json,omitempty"` // full chat memory JSON
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChatMemoryData) Reset() {
+	*x = ChatMemoryData{}
+	mi := &file_orchestrator_proto_msgTypes[81]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChatMemoryData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChatMemoryData) ProtoMessage() {}
+
+func (x *ChatMemoryData) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[81]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChatMemoryData.ProtoReflect.Descriptor instead.
+func (*ChatMemoryData) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{81}
+}
+
+func (x *ChatMemoryData) GetMemoryJson() string {
+	if x != nil {
+		return x.MemoryJson
+	}
+	return ""
+}
+
+type LLMTaskRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Prompt        string                 `protobuf:"bytes,1,opt,name=prompt,proto3" json:"prompt,omitempty"`
+	Model         string                 `protobuf:"bytes,2,opt,name=model,proto3" json:"model,omitempty"`                           // optional: specific model name
+	MaxTokens     int32                  `protobuf:"varint,3,opt,name=max_tokens,json=maxTokens,proto3" json:"max_tokens,omitempty"` // optional: max tokens to generate
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LLMTaskRequest) Reset() {
+	*x = LLMTaskRequest{}
+	mi := &file_orchestrator_proto_msgTypes[82]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LLMTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LLMTaskRequest) ProtoMessage() {}
+
+func (x *LLMTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[82]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LLMTaskRequest.ProtoReflect.Descriptor instead.
+func (*LLMTaskRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{82}
+}
+
+func (x *LLMTaskRequest) GetPrompt() string {
+	if x != nil {
+		return x.Prompt
+	}
+	return ""
+}
+
+func (x *LLMTaskRequest) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *LLMTaskRequest) GetMaxTokens() int32 {
+	if x != nil {
+		return x.MaxTokens
+	}
+	return 0
+}
+
+type LLMTaskResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Output          string                 `protobuf:"bytes,1,opt,name=output,proto3" json:"output,omitempty"`
+	ModelUsed       string                 `protobuf:"bytes,2,opt,name=model_used,json=modelUsed,proto3" json:"model_used,omitempty"`
+	TokensGenerated int64                  `protobuf:"varint,3,opt,name=tokens_generated,json=tokensGenerated,proto3" json:"tokens_generated,omitempty"`
+	Error           string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *LLMTaskResponse) Reset() {
+	*x = LLMTaskResponse{}
+	mi := &file_orchestrator_proto_msgTypes[83]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LLMTaskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LLMTaskResponse) ProtoMessage() {}
+
+func (x *LLMTaskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[83]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LLMTaskResponse.ProtoReflect.Descriptor instead.
+func (*LLMTaskResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{83}
+}
+
+func (x *LLMTaskResponse) GetOutput() string {
+	if x != nil {
+		return x.Output
+	}
+	return ""
+}
+
+func (x *LLMTaskResponse) GetModelUsed() string {
+	if x != nil {
+		return x.ModelUsed
+	}
+	return ""
+}
+
+func (x *LLMTaskResponse) GetTokensGenerated() int64 {
+	if x != nil {
+		return x.TokensGenerated
+	}
+	return 0
+}
+
+func (x *LLMTaskResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type BenchmarkRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	DeviceId      string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"` // device to benchmark; empty = this device
+	Models        []string               `protobuf:"bytes,3,rep,name=models,proto3" json:"models,omitempty"`                     // empty = the configured chat model
+	Runs          int32                  `protobuf:"varint,4,opt,name=runs,proto3" json:"runs,omitempty"`                        // passes over the prompt set; 0 = 1
+	Cached        bool                   `protobuf:"varint,5,opt,name=cached,proto3" json:"cached,omitempty"`                    // return stored results without running
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BenchmarkRequest) Reset() {
+	*x = BenchmarkRequest{}
+	mi := &file_orchestrator_proto_msgTypes[84]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BenchmarkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BenchmarkRequest) ProtoMessage() {}
+
+func (x *BenchmarkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[84]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BenchmarkRequest.ProtoReflect.Descriptor instead.
+func (*BenchmarkRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{84}
+}
+
+func (x *BenchmarkRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *BenchmarkRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *BenchmarkRequest) GetModels() []string {
+	if x != nil {
+		return x.Models
+	}
+	return nil
+}
+
+func (x *BenchmarkRequest) GetRuns() int32 {
+	if x != nil {
+		return x.Runs
+	}
+	return 0
+}
+
+func (x *BenchmarkRequest) GetCached() bool {
+	if x != nil {
+		return x.Cached
+	}
+	return false
+}
+
+type LLMBenchmark struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId         string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	Provider         string                 `protobuf:"bytes,2,opt,name=provider,proto3" json:"provider,omitempty"`
+	Model            string                 `protobuf:"bytes,3,opt,name=model,proto3" json:"model,omitempty"`
+	PrefillToksPerS  float64                `protobuf:"fixed64,4,opt,name=prefill_toks_per_s,json=prefillToksPerS,proto3" json:"prefill_toks_per_s,omitempty"`
+	DecodeToksPerS   float64                `protobuf:"fixed64,5,opt,name=decode_toks_per_s,json=decodeToksPerS,proto3" json:"decode_toks_per_s,omitempty"`
+	PromptTokens     int64                  `protobuf:"varint,6,opt,name=prompt_tokens,json=promptTokens,proto3" json:"prompt_tokens,omitempty"`
+	OutputTokens     int64                  `protobuf:"varint,7,opt,name=output_tokens,json=outputTokens,proto3" json:"output_tokens,omitempty"`
+	Method           string                 `protobuf:"bytes,8,opt,name=method,proto3" json:"method,omitempty"` // "provider" timings or "wall-clock" fit
+	MeasuredAtUnixMs int64                  `protobuf:"varint,9,opt,name=measured_at_unix_ms,json=measuredAtUnixMs,proto3" json:"measured_at_unix_ms,omitempty"`
+	DurationMs       int64                  `protobuf:"varint,10,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	Error            string                 `protobuf:"bytes,11,opt,name=error,proto3" json:"error,omitempty"` // set if this model could not be benchmarked
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *LLMBenchmark) Reset() {
+	*x = LLMBenchmark{}
+	mi := &file_orchestrator_proto_msgTypes[85]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LLMBenchmark) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LLMBenchmark) ProtoMessage() {}
+
+func (x *LLMBenchmark) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[85]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LLMBenchmark.ProtoReflect.Descriptor instead.
+func (*LLMBenchmark) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{85}
+}
+
+func (x *LLMBenchmark) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *LLMBenchmark) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *LLMBenchmark) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *LLMBenchmark) GetPrefillToksPerS() float64 {
+	if x != nil {
+		return x.PrefillToksPerS
+	}
+	return 0
+}
+
+func (x *LLMBenchmark) GetDecodeToksPerS() float64 {
+	if x != nil {
+		return x.DecodeToksPerS
+	}
+	return 0
+}
+
+func (x *LLMBenchmark) GetPromptTokens() int64 {
+	if x != nil {
+		return x.PromptTokens
+	}
+	return 0
+}
+
+func (x *LLMBenchmark) GetOutputTokens() int64 {
+	if x != nil {
+		return x.OutputTokens
+	}
+	return 0
+}
+
+func (x *LLMBenchmark) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *LLMBenchmark) GetMeasuredAtUnixMs() int64 {
+	if x != nil {
+		return x.MeasuredAtUnixMs
+	}
+	return 0
+}
+
+func (x *LLMBenchmark) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *LLMBenchmark) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type BenchmarkResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*LLMBenchmark        `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BenchmarkResponse) Reset() {
+	*x = BenchmarkResponse{}
+	mi := &file_orchestrator_proto_msgTypes[86]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BenchmarkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BenchmarkResponse) ProtoMessage() {}
+
+func (x *BenchmarkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[86]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BenchmarkResponse.ProtoReflect.Descriptor instead.
+func (*BenchmarkResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{86}
+}
+
+func (x *BenchmarkResponse) GetResults() []*LLMBenchmark {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type MetricsSample struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TimestampMs   int64                  `protobuf:"varint,1,opt,name=timestamp_ms,json=timestampMs,proto3" json:"timestamp_ms,omitempty"`
+	CpuLoad       float64                `protobuf:"fixed64,2,opt,name=cpu_load,json=cpuLoad,proto3" json:"cpu_load,omitempty"`
+	MemUsedMb     uint64                 `protobuf:"varint,3,opt,name=mem_used_mb,json=memUsedMb,proto3" json:"mem_used_mb,omitempty"`
+	MemTotalMb    uint64                 `protobuf:"varint,4,opt,name=mem_total_mb,json=memTotalMb,proto3" json:"mem_total_mb,omitempty"`
+	GpuLoad       float64                `protobuf:"fixed64,5,opt,name=gpu_load,json=gpuLoad,proto3" json:"gpu_load,omitempty"`
+	GpuMemUsedMb  uint64                 `protobuf:"varint,6,opt,name=gpu_mem_used_mb,json=gpuMemUsedMb,proto3" json:"gpu_mem_used_mb,omitempty"`
+	GpuMemTotalMb uint64                 `protobuf:"varint,7,opt,name=gpu_mem_total_mb,json=gpuMemTotalMb,proto3" json:"gpu_mem_total_mb,omitempty"`
+	NpuLoad       float64                `protobuf:"fixed64,8,opt,name=npu_load,json=npuLoad,proto3" json:"npu_load,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MetricsSample) Reset() {
+	*x = MetricsSample{}
+	mi := &file_orchestrator_proto_msgTypes[87]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetricsSample) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetricsSample) ProtoMessage() {}
+
+func (x *MetricsSample) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[87]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetricsSample.ProtoReflect.Descriptor instead.
+func (*MetricsSample) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{87}
+}
+
+func (x *MetricsSample) GetTimestampMs() int64 {
+	if x != nil {
+		return x.TimestampMs
+	}
+	return 0
+}
+
+func (x *MetricsSample) GetCpuLoad() float64 {
+	if x != nil {
+		return x.CpuLoad
+	}
+	return 0
+}
+
+func (x *MetricsSample) GetMemUsedMb() uint64 {
+	if x != nil {
+		return x.MemUsedMb
+	}
+	return 0
+}
+
+func (x *MetricsSample) GetMemTotalMb() uint64 {
+	if x != nil {
+		return x.MemTotalMb
+	}
+	return 0
+}
+
+func (x *MetricsSample) GetGpuLoad() float64 {
+	if x != nil {
+		return x.GpuLoad
+	}
+	return 0
+}
+
+func (x *MetricsSample) GetGpuMemUsedMb() uint64 {
+	if x != nil {
+		return x.GpuMemUsedMb
+	}
+	return 0
+}
+
+func (x *MetricsSample) GetGpuMemTotalMb() uint64 {
+	if x != nil {
+		return x.GpuMemTotalMb
+	}
+	return 0
+}
+
+func (x *MetricsSample) GetNpuLoad() float64 {
+	if x != nil {
+		return x.NpuLoad
+	}
+	return 0
+}
+
+type RunningTask struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	JobId         string                 `protobuf:"bytes,2,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Kind          string                 `protobuf:"bytes,3,opt,name=kind,proto3" json:"kind,omitempty"`
+	Input         string                 `protobuf:"bytes,4,opt,name=input,proto3" json:"input,omitempty"`
+	DeviceId      string                 `protobuf:"bytes,5,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	DeviceName    string                 `protobuf:"bytes,6,opt,name=device_name,json=deviceName,proto3" json:"device_name,omitempty"`
+	StartedAtMs   int64                  `protobuf:"varint,7,opt,name=started_at_ms,json=startedAtMs,proto3" json:"started_at_ms,omitempty"`
+	ElapsedMs     int64                  `protobuf:"varint,8,opt,name=elapsed_ms,json=elapsedMs,proto3" json:"elapsed_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunningTask) Reset() {
+	*x = RunningTask{}
+	mi := &file_orchestrator_proto_msgTypes[88]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunningTask) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunningTask) ProtoMessage() {}
+
+func (x *RunningTask) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[88]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunningTask.ProtoReflect.Descriptor instead.
+func (*RunningTask) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{88}
+}
+
+func (x *RunningTask) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *RunningTask) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *RunningTask) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *RunningTask) GetInput() string {
+	if x != nil {
+		return x.Input
+	}
+	return ""
+}
+
+func (x *RunningTask) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *RunningTask) GetDeviceName() string {
+	if x != nil {
+		return x.DeviceName
+	}
+	return ""
+}
+
+func (x *RunningTask) GetStartedAtMs() int64 {
+	if x != nil {
+		return x.StartedAtMs
+	}
+	return 0
+}
+
+func (x *RunningTask) GetElapsedMs() int64 {
+	if x != nil {
+		return x.ElapsedMs
+	}
+	return 0
+}
+
+type DeviceActivity struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId         string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	DeviceName       string                 `protobuf:"bytes,2,opt,name=device_name,json=deviceName,proto3" json:"device_name,omitempty"`
+	RunningTaskCount int32                  `protobuf:"varint,3,opt,name=running_task_count,json=runningTaskCount,proto3" json:"running_task_count,omitempty"`
+	CurrentStatus    *DeviceStatus          `protobuf:"bytes,4,opt,name=current_status,json=currentStatus,proto3" json:"current_status,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *DeviceActivity) Reset() {
+	*x = DeviceActivity{}
+	mi := &file_orchestrator_proto_msgTypes[89]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeviceActivity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceActivity) ProtoMessage() {}
+
+func (x *DeviceActivity) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[89]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceActivity.ProtoReflect.Descriptor instead.
+func (*DeviceActivity) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{89}
+}
+
+func (x *DeviceActivity) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *DeviceActivity) GetDeviceName() string {
+	if x != nil {
+		return x.DeviceName
+	}
+	return ""
+}
+
+func (x *DeviceActivity) GetRunningTaskCount() int32 {
+	if x != nil {
+		return x.RunningTaskCount
+	}
+	return 0
+}
+
+func (x *DeviceActivity) GetCurrentStatus() *DeviceStatus {
+	if x != nil {
+		return x.CurrentStatus
+	}
+	return nil
+}
+
+type ActivityData struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	RunningTasks     []*RunningTask         `protobuf:"bytes,1,rep,name=running_tasks,json=runningTasks,proto3" json:"running_tasks,omitempty"`
+	DeviceActivities []*DeviceActivity      `protobuf:"bytes,2,rep,name=device_activities,json=deviceActivities,proto3" json:"device_activities,omitempty"`
+	Links            []*LinkStats           `protobuf:"bytes,3,rep,name=links,proto3" json:"links,omitempty"` // measured link matrix
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ActivityData) Reset() {
+	*x = ActivityData{}
+	mi := &file_orchestrator_proto_msgTypes[90]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ActivityData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ActivityData) ProtoMessage() {}
+
+func (x *ActivityData) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[90]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ActivityData.ProtoReflect.Descriptor instead.
+func (*ActivityData) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{90}
+}
+
+func (x *ActivityData) GetRunningTasks() []*RunningTask {
+	if x != nil {
+		return x.RunningTasks
+	}
+	return nil
+}
+
+func (x *ActivityData) GetDeviceActivities() []*DeviceActivity {
+	if x != nil {
+		return x.DeviceActivities
+	}
+	return nil
+}
+
+func (x *ActivityData) GetLinks() []*LinkStats {
+	if x != nil {
+		return x.Links
+	}
+	return nil
+}
+
+type GetActivityRequest struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	IncludeMetricsHistory bool                   `protobuf:"varint,1,opt,name=include_metrics_history,json=includeMetricsHistory,proto3" json:"include_metrics_history,omitempty"`
+	MetricsSinceMs        int64                  `protobuf:"varint,2,opt,name=metrics_since_ms,json=metricsSinceMs,proto3" json:"metrics_since_ms,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *GetActivityRequest) Reset() {
+	*x = GetActivityRequest{}
+	mi := &file_orchestrator_proto_msgTypes[91]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetActivityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetActivityRequest) ProtoMessage() {}
+
+func (x *GetActivityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[91]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetActivityRequest.ProtoReflect.Descriptor instead.
+func (*GetActivityRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{91}
+}
+
+func (x *GetActivityRequest) GetIncludeMetricsHistory() bool {
+	if x != nil {
+		return x.IncludeMetricsHistory
+	}
+	return false
+}
+
+func (x *GetActivityRequest) GetMetricsSinceMs() int64 {
+	if x != nil {
+		return x.MetricsSinceMs
+	}
+	return 0
+}
+
+// MetricsQuery selects a device's metrics. Without bounds or resolution
+// it returns the live samples of the last few minutes; otherwise aggregated
+// points from the long-term history.
+type MetricsQuery struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`              // same field number as DeviceId
+	FromMs        int64                  `protobuf:"varint,2,opt,name=from_ms,json=fromMs,proto3" json:"from_ms,omitempty"`                   // 0 = 24 hours before to_ms
+	ToMs          int64                  `protobuf:"varint,3,opt,name=to_ms,json=toMs,proto3" json:"to_ms,omitempty"`                         // 0 = now
+	ResolutionMs  int64                  `protobuf:"varint,4,opt,name=resolution_ms,json=resolutionMs,proto3" json:"resolution_ms,omitempty"` // bucket size; 0 = automatic
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MetricsQuery) Reset() {
+	*x = MetricsQuery{}
+	mi := &file_orchestrator_proto_msgTypes[92]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetricsQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetricsQuery) ProtoMessage() {}
+
+func (x *MetricsQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[92]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetricsQuery.ProtoReflect.Descriptor instead.
+func (*MetricsQuery) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{92}
+}
+
+func (x *MetricsQuery) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *MetricsQuery) GetFromMs() int64 {
+	if x != nil {
+		return x.FromMs
+	}
+	return 0
+}
+
+func (x *MetricsQuery) GetToMs() int64 {
+	if x != nil {
+		return x.ToMs
+	}
+	return 0
+}
+
+func (x *MetricsQuery) GetResolutionMs() int64 {
+	if x != nil {
+		return x.ResolutionMs
+	}
+	return 0
+}
+
+// MetricStats aggregates one metric over a bucket
+type MetricStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Min           float64                `protobuf:"fixed64,1,opt,name=min,proto3" json:"min,omitempty"`
+	Max           float64                `protobuf:"fixed64,2,opt,name=max,proto3" json:"max,omitempty"`
+	Avg           float64                `protobuf:"fixed64,3,opt,name=avg,proto3" json:"avg,omitempty"`
+	Count         int64                  `protobuf:"varint,4,opt,name=count,proto3" json:"count,omitempty"` // samples that reported the metric
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MetricStats) Reset() {
+	*x = MetricStats{}
+	mi := &file_orchestrator_proto_msgTypes[93]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetricStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetricStats) ProtoMessage() {}
+
+func (x *MetricStats) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[93]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetricStats.ProtoReflect.Descriptor instead.
+func (*MetricStats) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{93}
+}
+
+func (x *MetricStats) GetMin() float64 {
+	if x != nil {
+		return x.Min
+	}
+	return 0
+}
+
+func (x *MetricStats) GetMax() float64 {
+	if x != nil {
+		return x.Max
+	}
+	return 0
+}
+
+func (x *MetricStats) GetAvg() float64 {
+	if x != nil {
+		return x.Avg
+	}
+	return 0
+}
+
+func (x *MetricStats) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+// MetricsPoint aggregates a device's samples from start_ms over one
+// resolution step. Metrics the device did not report are unset.
+type MetricsPoint struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StartMs       int64                  `protobuf:"varint,1,opt,name=start_ms,json=startMs,proto3" json:"start_ms,omitempty"`
+	CpuLoad       *MetricStats           `protobuf:"bytes,2,opt,name=cpu_load,json=cpuLoad,proto3" json:"cpu_load,omitempty"`
+	MemUsedMb     *MetricStats           `protobuf:"bytes,3,opt,name=mem_used_mb,json=memUsedMb,proto3" json:"mem_used_mb,omitempty"`
+	MemTotalMb    *MetricStats           `protobuf:"bytes,4,opt,name=mem_total_mb,json=memTotalMb,proto3" json:"mem_total_mb,omitempty"`
+	GpuLoad       *MetricStats           `protobuf:"bytes,5,opt,name=gpu_load,json=gpuLoad,proto3" json:"gpu_load,omitempty"`
+	GpuMemUsedMb  *MetricStats           `protobuf:"bytes,6,opt,name=gpu_mem_used_mb,json=gpuMemUsedMb,proto3" json:"gpu_mem_used_mb,omitempty"`
+	GpuMemTotalMb *MetricStats           `protobuf:"bytes,7,opt,name=gpu_mem_total_mb,json=gpuMemTotalMb,proto3" json:"gpu_mem_total_mb,omitempty"`
+	NpuLoad       *MetricStats           `protobuf:"bytes,8,opt,name=npu_load,json=npuLoad,proto3" json:"npu_load,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MetricsPoint) Reset() {
+	*x = MetricsPoint{}
+	mi := &file_orchestrator_proto_msgTypes[94]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetricsPoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetricsPoint) ProtoMessage() {}
+
+func (x *MetricsPoint) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[94]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetricsPoint.ProtoReflect.Descriptor instead.
+func (*MetricsPoint) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{94}
+}
+
+func (x *MetricsPoint) GetStartMs() int64 {
+	if x != nil {
+		return x.StartMs
+	}
+	return 0
+}
+
+func (x *MetricsPoint) GetCpuLoad() *MetricStats {
+	if x != nil {
+		return x.CpuLoad
+	}
+	return nil
+}
+
+func (x *MetricsPoint) GetMemUsedMb() *MetricStats {
+	if x != nil {
+		return x.MemUsedMb
+	}
+	return nil
+}
+
+func (x *MetricsPoint) GetMemTotalMb() *MetricStats {
+	if x != nil {
+		return x.MemTotalMb
+	}
+	return nil
+}
+
+func (x *MetricsPoint) GetGpuLoad() *MetricStats {
+	if x != nil {
+		return x.GpuLoad
+	}
+	return nil
+}
+
+func (x *MetricsPoint) GetGpuMemUsedMb() *MetricStats {
+	if x != nil {
+		return x.GpuMemUsedMb
+	}
+	return nil
+}
+
+func (x *MetricsPoint) GetGpuMemTotalMb() *MetricStats {
+	if x != nil {
+		return x.GpuMemTotalMb
+	}
+	return nil
+}
+
+func (x *MetricsPoint) GetNpuLoad() *MetricStats {
+	if x != nil {
+		return x.NpuLoad
+	}
+	return nil
+}
+
+type MetricsHistoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	DeviceName    string                 `protobuf:"bytes,2,opt,name=device_name,json=deviceName,proto3" json:"device_name,omitempty"`
+	Samples       []*MetricsSample       `protobuf:"bytes,3,rep,name=samples,proto3" json:"samples,omitempty"`                                // live samples (query without bounds)
+	Points        []*MetricsPoint        `protobuf:"bytes,4,rep,name=points,proto3" json:"points,omitempty"`                                  // long-term history (query with bounds)
+	ResolutionMs  int64                  `protobuf:"varint,5,opt,name=resolution_ms,json=resolutionMs,proto3" json:"resolution_ms,omitempty"` // of points; 0 = raw samples
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MetricsHistoryResponse) Reset() {
+	*x = MetricsHistoryResponse{}
+	mi := &file_orchestrator_proto_msgTypes[95]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetricsHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetricsHistoryResponse) ProtoMessage() {}
+
+func (x *MetricsHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[95]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetricsHistoryResponse.ProtoReflect.Descriptor instead.
+func (*MetricsHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{95}
+}
+
+func (x *MetricsHistoryResponse) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *MetricsHistoryResponse) GetDeviceName() string {
+	if x != nil {
+		return x.DeviceName
+	}
+	return ""
+}
+
+func (x *MetricsHistoryResponse) GetSamples() []*MetricsSample {
+	if x != nil {
+		return x.Samples
+	}
+	return nil
+}
+
+func (x *MetricsHistoryResponse) GetPoints() []*MetricsPoint {
+	if x != nil {
+		return x.Points
+	}
+	return nil
+}
+
+func (x *MetricsHistoryResponse) GetResolutionMs() int64 {
+	if x != nil {
+		return x.ResolutionMs
+	}
+	return 0
+}
+
+type GetActivityResponse struct {
+	state         protoimpl.MessageState             `protogen:"open.v1"`
+	Activity      *ActivityData                      `protobuf:"bytes,1,opt,name=activity,proto3" json:"activity,omitempty"`
+	DeviceMetrics map[string]*MetricsHistoryResponse `protobuf:"bytes,2,rep,name=device_metrics,json=deviceMetrics,proto3" json:"device_metrics,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetActivityResponse) Reset() {
+	*x = GetActivityResponse{}
+	mi := &file_orchestrator_proto_msgTypes[96]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetActivityResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetActivityResponse) ProtoMessage() {}
+
+func (x *GetActivityResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[96]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetActivityResponse.ProtoReflect.Descriptor instead.
+func (*GetActivityResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{96}
+}
+
+func (x *GetActivityResponse) GetActivity() *ActivityData {
+	if x != nil {
+		return x.Activity
+	}
+	return nil
+}
+
+func (x *GetActivityResponse) GetDeviceMetrics() map[string]*MetricsHistoryResponse {
+	if x != nil {
+		return x.DeviceMetrics
+	}
+	return nil
+}
+
+// Enhanced task status for visualization
+type TaskStatusEnhanced struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	TaskId             string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	JobId              string                 `protobuf:"bytes,2,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	AssignedDeviceId   string                 `protobuf:"bytes,3,opt,name=assigned_device_id,json=assignedDeviceId,proto3" json:"assigned_device_id,omitempty"`
+	AssignedDeviceName string                 `protobuf:"bytes,4,opt,name=assigned_device_name,json=assignedDeviceName,proto3" json:"assigned_device_name,omitempty"`
+	Kind               string                 `protobuf:"bytes,5,opt,name=kind,proto3" json:"kind,omitempty"`
+	Input              string                 `protobuf:"bytes,6,opt,name=input,proto3" json:"input,omitempty"`
+	State              string                 `protobuf:"bytes,7,opt,name=state,proto3" json:"state,omitempty"`
+	Result             string                 `protobuf:"bytes,8,opt,name=result,proto3" json:"result,omitempty"`
+	Error              string                 `protobuf:"bytes,9,opt,name=error,proto3" json:"error,omitempty"`
+	GroupIndex         int32                  `protobuf:"varint,10,opt,name=group_index,json=groupIndex,proto3" json:"group_index,omitempty"`
+	StartedAtMs        int64                  `protobuf:"varint,11,opt,name=started_at_ms,json=startedAtMs,proto3" json:"started_at_ms,omitempty"`
+	EndedAtMs          int64                  `protobuf:"varint,12,opt,name=ended_at_ms,json=endedAtMs,proto3" json:"ended_at_ms,omitempty"`
+	InputPaths         []string               `protobuf:"bytes,13,rep,name=input_paths,json=inputPaths,proto3" json:"input_paths,omitempty"`     // inputs on the assigned device
+	StagedBytes        int64                  `protobuf:"varint,14,opt,name=staged_bytes,json=stagedBytes,proto3" json:"staged_bytes,omitempty"` // input bytes copied onto the device
+	Placement          string                 `protobuf:"bytes,15,opt,name=placement,proto3" json:"placement,omitempty"`                         // why the device was chosen
+	Shell              *ShellResult           `protobuf:"bytes,16,opt,name=shell,proto3" json:"shell,omitempty"`                                 // SHELL tasks only
+	Priority           int32                  `protobuf:"varint,17,opt,name=priority,proto3" json:"priority,omitempty"`
+	QueuePosition      int32                  `protobuf:"varint,18,opt,name=queue_position,json=queuePosition,proto3" json:"queue_position,omitempty"` // place in its device's dispatch queue, 1 = next; 0 = not waiting
+	Preemptions        int32                  `protobuf:"varint,19,opt,name=preemptions,proto3" json:"preemptions,omitempty"`                          // times the task was stopped and requeued for a more urgent one
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *TaskStatusEnhanced) Reset() {
+	*x = TaskStatusEnhanced{}
+	mi := &file_orchestrator_proto_msgTypes[97]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskStatusEnhanced) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskStatusEnhanced) ProtoMessage() {}
+
+func (x *TaskStatusEnhanced) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[97]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskStatusEnhanced.ProtoReflect.Descriptor instead.
+func (*TaskStatusEnhanced) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{97}
+}
+
+func (x *TaskStatusEnhanced) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *TaskStatusEnhanced) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *TaskStatusEnhanced) GetAssignedDeviceId() string {
+	if x != nil {
+		return x.AssignedDeviceId
+	}
+	return ""
+}
+
+func (x *TaskStatusEnhanced) GetAssignedDeviceName() string {
+	if x != nil {
+		return x.AssignedDeviceName
+	}
+	return ""
+}
+
+func (x *TaskStatusEnhanced) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *TaskStatusEnhanced) GetInput() string {
+	if x != nil {
+		return x.Input
+	}
+	return ""
+}
+
+func (x *TaskStatusEnhanced) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *TaskStatusEnhanced) GetResult() string {
+	if x != nil {
+		return x.Result
+	}
+	return ""
+}
+
+func (x *TaskStatusEnhanced) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *TaskStatusEnhanced) GetGroupIndex() int32 {
+	if x != nil {
+		return x.GroupIndex
+	}
+	return 0
+}
+
+func (x *TaskStatusEnhanced) GetStartedAtMs() int64 {
+	if x != nil {
+		return x.StartedAtMs
+	}
+	return 0
+}
+
+func (x *TaskStatusEnhanced) GetEndedAtMs() int64 {
+	if x != nil {
+		return x.EndedAtMs
+	}
+	return 0
+}
+
+func (x *TaskStatusEnhanced) GetInputPaths() []string {
+	if x != nil {
+		return x.InputPaths
+	}
+	return nil
+}
+
+func (x *TaskStatusEnhanced) GetStagedBytes() int64 {
+	if x != nil {
+		return x.StagedBytes
+	}
+	return 0
+}
+
+func (x *TaskStatusEnhanced) GetPlacement() string {
+	if x != nil {
+		return x.Placement
+	}
+	return ""
+}
+
+func (x *TaskStatusEnhanced) GetShell() *ShellResult {
+	if x != nil {
+		return x.Shell
+	}
+	return nil
+}
+
+func (x *TaskStatusEnhanced) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *TaskStatusEnhanced) GetQueuePosition() int32 {
 	if x != nil {
-		return x.GpuMemTotalMb
+		return x.QueuePosition
 	}
 	return 0
 }
 
-func (x *MetricsSample) GetNpuLoad() float64 {
+func (x *TaskStatusEnhanced) GetPreemptions() int32 {
 	if x != nil {
-		return x.NpuLoad
+		return x.Preemptions
 	}
 	return 0
 }
 
-type RunningTask struct {
+type JobDetailResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
-	JobId         string                 `protobuf:"bytes,2,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
-	Kind          string                 `protobuf:"bytes,3,opt,name=kind,proto3" json:"kind,omitempty"`
-	Input         string                 `protobuf:"bytes,4,opt,name=input,proto3" json:"input,omitempty"`
-	DeviceId      string                 `protobuf:"bytes,5,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
-	DeviceName    string                 `protobuf:"bytes,6,opt,name=device_name,json=deviceName,proto3" json:"device_name,omitempty"`
-	StartedAtMs   int64                  `protobuf:"varint,7,opt,name=started_at_ms,json=startedAtMs,proto3" json:"started_at_ms,omitempty"`
-	ElapsedMs     int64                  `protobuf:"varint,8,opt,name=elapsed_ms,json=elapsedMs,proto3" json:"elapsed_ms,omitempty"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	State         string                 `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
+	Tasks         []*TaskStatusEnhanced  `protobuf:"bytes,3,rep,name=tasks,proto3" json:"tasks,omitempty"`
+	FinalResult   string                 `protobuf:"bytes,4,opt,name=final_result,json=finalResult,proto3" json:"final_result,omitempty"`
+	CurrentGroup  int32                  `protobuf:"varint,5,opt,name=current_group,json=currentGroup,proto3" json:"current_group,omitempty"`
+	TotalGroups   int32                  `protobuf:"varint,6,opt,name=total_groups,json=totalGroups,proto3" json:"total_groups,omitempty"`
+	CreatedAtMs   int64                  `protobuf:"varint,7,opt,name=created_at_ms,json=createdAtMs,proto3" json:"created_at_ms,omitempty"`
+	StartedAtMs   int64                  `protobuf:"varint,8,opt,name=started_at_ms,json=startedAtMs,proto3" json:"started_at_ms,omitempty"`
+	EndedAtMs     int64                  `protobuf:"varint,9,opt,name=ended_at_ms,json=endedAtMs,proto3" json:"ended_at_ms,omitempty"`
+	ScheduleId    string                 `protobuf:"bytes,10,opt,name=schedule_id,json=scheduleId,proto3" json:"schedule_id,omitempty"` // schedule that started the job, if any
+	Priority      int32                  `protobuf:"varint,11,opt,name=priority,proto3" json:"priority,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RunningTask) Reset() {
-	*x = RunningTask{}
-	mi := &file_orchestrator_proto_msgTypes[48]
+func (x *JobDetailResponse) Reset() {
+	*x = JobDetailResponse{}
+	mi := &file_orchestrator_proto_msgTypes[98]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RunningTask) String() string {
+func (x *JobDetailResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RunningTask) ProtoMessage() {}
+func (*JobDetailResponse) ProtoMessage() {}
 
-func (x *RunningTask) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[48]
+func (x *JobDetailResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[98]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3343,92 +7475,111 @@ func (x *RunningTask) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RunningTask.ProtoReflect.Descriptor instead.
-func (*RunningTask) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{48}
+// Deprecated: Use JobDetailResponse.ProtoReflect.Descriptor instead.
+func (*JobDetailResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{98}
 }
 
-func (x *RunningTask) GetTaskId() string {
+func (x *JobDetailResponse) GetJobId() string {
 	if x != nil {
-		return x.TaskId
+		return x.JobId
 	}
 	return ""
 }
 
-func (x *RunningTask) GetJobId() string {
+func (x *JobDetailResponse) GetState() string {
 	if x != nil {
-		return x.JobId
+		return x.State
 	}
 	return ""
 }
 
-func (x *RunningTask) GetKind() string {
+func (x *JobDetailResponse) GetTasks() []*TaskStatusEnhanced {
 	if x != nil {
-		return x.Kind
+		return x.Tasks
 	}
-	return ""
+	return nil
 }
 
-func (x *RunningTask) GetInput() string {
+func (x *JobDetailResponse) GetFinalResult() string {
 	if x != nil {
-		return x.Input
+		return x.FinalResult
 	}
 	return ""
 }
 
-func (x *RunningTask) GetDeviceId() string {
+func (x *JobDetailResponse) GetCurrentGroup() int32 {
 	if x != nil {
-		return x.DeviceId
+		return x.CurrentGroup
 	}
-	return ""
+	return 0
 }
 
-func (x *RunningTask) GetDeviceName() string {
+func (x *JobDetailResponse) GetTotalGroups() int32 {
 	if x != nil {
-		return x.DeviceName
+		return x.TotalGroups
 	}
-	return ""
+	return 0
 }
 
-func (x *RunningTask) GetStartedAtMs() int64 {
+func (x *JobDetailResponse) GetCreatedAtMs() int64 {
+	if x != nil {
+		return x.CreatedAtMs
+	}
+	return 0
+}
+
+func (x *JobDetailResponse) GetStartedAtMs() int64 {
 	if x != nil {
 		return x.StartedAtMs
 	}
 	return 0
 }
 
-func (x *RunningTask) GetElapsedMs() int64 {
+func (x *JobDetailResponse) GetEndedAtMs() int64 {
 	if x != nil {
-		return x.ElapsedMs
+		return x.EndedAtMs
 	}
 	return 0
 }
 
-type DeviceActivity struct {
-	state            protoimpl.MessageState `protogen:"open.v1"`
-	DeviceId         string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
-	DeviceName       string                 `protobuf:"bytes,2,opt,name=device_name,json=deviceName,proto3" json:"device_name,omitempty"`
-	RunningTaskCount int32                  `protobuf:"varint,3,opt,name=running_task_count,json=runningTaskCount,proto3" json:"running_task_count,omitempty"`
-	CurrentStatus    *DeviceStatus          `protobuf:"bytes,4,opt,name=current_status,json=currentStatus,proto3" json:"current_status,omitempty"`
-	unknownFields    protoimpl.UnknownFields
-	sizeCache        protoimpl.SizeCache
+func (x *JobDetailResponse) GetScheduleId() string {
+	if x != nil {
+		return x.ScheduleId
+	}
+	return ""
 }
 
-func (x *DeviceActivity) Reset() {
-	*x = DeviceActivity{}
-	mi := &file_orchestrator_proto_msgTypes[49]
+func (x *JobDetailResponse) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+type WatchAlertsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	IncludeFiring bool                   `protobuf:"varint,2,opt,name=include_firing,json=includeFiring,proto3" json:"include_firing,omitempty"` // first send the alerts firing now
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchAlertsRequest) Reset() {
+	*x = WatchAlertsRequest{}
+	mi := &file_orchestrator_proto_msgTypes[99]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeviceActivity) String() string {
+func (x *WatchAlertsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeviceActivity) ProtoMessage() {}
+func (*WatchAlertsRequest) ProtoMessage() {}
 
-func (x *DeviceActivity) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[49]
+func (x *WatchAlertsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[99]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3439,62 +7590,58 @@ func (x *DeviceActivity) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeviceActivity.ProtoReflect.Descriptor instead.
-func (*DeviceActivity) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{49}
-}
-
-func (x *DeviceActivity) GetDeviceId() string {
-	if x != nil {
-		return x.DeviceId
-	}
-	return ""
+// Deprecated: Use WatchAlertsRequest.ProtoReflect.Descriptor instead.
+func (*WatchAlertsRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{99}
 }
 
-func (x *DeviceActivity) GetDeviceName() string {
+func (x *WatchAlertsRequest) GetSessionId() string {
 	if x != nil {
-		return x.DeviceName
+		return x.SessionId
 	}
 	return ""
 }
 
-func (x *DeviceActivity) GetRunningTaskCount() int32 {
-	if x != nil {
-		return x.RunningTaskCount
-	}
-	return 0
-}
-
-func (x *DeviceActivity) GetCurrentStatus() *DeviceStatus {
+func (x *WatchAlertsRequest) GetIncludeFiring() bool {
 	if x != nil {
-		return x.CurrentStatus
+		return x.IncludeFiring
 	}
-	return nil
+	return false
 }
 
-type ActivityData struct {
-	state            protoimpl.MessageState `protogen:"open.v1"`
-	RunningTasks     []*RunningTask         `protobuf:"bytes,1,rep,name=running_tasks,json=runningTasks,proto3" json:"running_tasks,omitempty"`
-	DeviceActivities []*DeviceActivity      `protobuf:"bytes,2,rep,name=device_activities,json=deviceActivities,proto3" json:"device_activities,omitempty"`
-	unknownFields    protoimpl.UnknownFields
-	sizeCache        protoimpl.SizeCache
+type Alert struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"` // rule and subject; the same when the alert resolves
+	Rule          string                 `protobuf:"bytes,2,opt,name=rule,proto3" json:"rule,omitempty"`
+	Kind          string                 `protobuf:"bytes,3,opt,name=kind,proto3" json:"kind,omitempty"`         // "metric", "device_offline" or "job_failed"
+	Severity      string                 `protobuf:"bytes,4,opt,name=severity,proto3" json:"severity,omitempty"` // "info", "warning" or "critical"
+	State         string                 `protobuf:"bytes,5,opt,name=state,proto3" json:"state,omitempty"`       // "firing" or "resolved"
+	DeviceId      string                 `protobuf:"bytes,6,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	DeviceName    string                 `protobuf:"bytes,7,opt,name=device_name,json=deviceName,proto3" json:"device_name,omitempty"`
+	JobId         string                 `protobuf:"bytes,8,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Value         float64                `protobuf:"fixed64,9,opt,name=value,proto3" json:"value,omitempty"` // the metric's value, seconds unseen, or failed tasks
+	Message       string                 `protobuf:"bytes,10,opt,name=message,proto3" json:"message,omitempty"`
+	SinceMs       int64                  `protobuf:"varint,11,opt,name=since_ms,json=sinceMs,proto3" json:"since_ms,omitempty"` // when the condition began to hold
+	TimeMs        int64                  `protobuf:"varint,12,opt,name=time_ms,json=timeMs,proto3" json:"time_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ActivityData) Reset() {
-	*x = ActivityData{}
-	mi := &file_orchestrator_proto_msgTypes[50]
+func (x *Alert) Reset() {
+	*x = Alert{}
+	mi := &file_orchestrator_proto_msgTypes[100]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ActivityData) String() string {
+func (x *Alert) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ActivityData) ProtoMessage() {}
+func (*Alert) ProtoMessage() {}
 
-func (x *ActivityData) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[50]
+func (x *Alert) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[100]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3505,101 +7652,123 @@ func (x *ActivityData) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ActivityData.ProtoReflect.Descriptor instead.
-func (*ActivityData) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{50}
+// Deprecated: Use Alert.ProtoReflect.Descriptor instead.
+func (*Alert) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{100}
 }
 
-func (x *ActivityData) GetRunningTasks() []*RunningTask {
+func (x *Alert) GetId() string {
 	if x != nil {
-		return x.RunningTasks
+		return x.Id
 	}
-	return nil
+	return ""
 }
 
-func (x *ActivityData) GetDeviceActivities() []*DeviceActivity {
+func (x *Alert) GetRule() string {
 	if x != nil {
-		return x.DeviceActivities
+		return x.Rule
 	}
-	return nil
+	return ""
 }
 
-type GetActivityRequest struct {
-	state                 protoimpl.MessageState `protogen:"open.v1"`
-	IncludeMetricsHistory bool                   `protobuf:"varint,1,opt,name=include_metrics_history,json=includeMetricsHistory,proto3" json:"include_metrics_history,omitempty"`
-	MetricsSinceMs        int64                  `protobuf:"varint,2,opt,name=metrics_since_ms,json=metricsSinceMs,proto3" json:"metrics_since_ms,omitempty"`
-	unknownFields         protoimpl.UnknownFields
-	sizeCache             protoimpl.SizeCache
+func (x *Alert) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
 }
 
-func (x *GetActivityRequest) Reset() {
-	*x = GetActivityRequest{}
-	mi := &file_orchestrator_proto_msgTypes[51]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *Alert) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
 }
 
-func (x *GetActivityRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *Alert) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
 }
 
-func (*GetActivityRequest) ProtoMessage() {}
+func (x *Alert) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
 
-func (x *GetActivityRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[51]
+func (x *Alert) GetDeviceName() string {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.DeviceName
 	}
-	return mi.MessageOf(x)
+	return ""
 }
 
-// Deprecated: Use GetActivityRequest.ProtoReflect.Descriptor instead.
-func (*GetActivityRequest) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{51}
+func (x *Alert) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
 }
 
-func (x *GetActivityRequest) GetIncludeMetricsHistory() bool {
+func (x *Alert) GetValue() float64 {
 	if x != nil {
-		return x.IncludeMetricsHistory
+		return x.Value
 	}
-	return false
+	return 0
 }
 
-func (x *GetActivityRequest) GetMetricsSinceMs() int64 {
+func (x *Alert) GetMessage() string {
 	if x != nil {
-		return x.MetricsSinceMs
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Alert) GetSinceMs() int64 {
+	if x != nil {
+		return x.SinceMs
 	}
 	return 0
 }
 
-type MetricsHistoryResponse struct {
+func (x *Alert) GetTimeMs() int64 {
+	if x != nil {
+		return x.TimeMs
+	}
+	return 0
+}
+
+type CreateScheduleRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
-	DeviceName    string                 `protobuf:"bytes,2,opt,name=device_name,json=deviceName,proto3" json:"device_name,omitempty"`
-	Samples       []*MetricsSample       `protobuf:"bytes,3,rep,name=samples,proto3" json:"samples,omitempty"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Cron          string                 `protobuf:"bytes,3,opt,name=cron,proto3" json:"cron,omitempty"`                                      // "min hour day month weekday", or @hourly, @daily, @weekly, @monthly, @yearly
+	Timezone      string                 `protobuf:"bytes,4,opt,name=timezone,proto3" json:"timezone,omitempty"`                              // IANA name, e.g. "Europe/Berlin"; empty = the server's local time
+	Job           *JobRequest            `protobuf:"bytes,5,opt,name=job,proto3" json:"job,omitempty"`                                        // submitted on every run; its session_id is ignored
+	MissedPolicy  string                 `protobuf:"bytes,6,opt,name=missed_policy,json=missedPolicy,proto3" json:"missed_policy,omitempty"`  // runs missed while the server was down: "run_once" (default) or "skip"
+	AllowOverlap  bool                   `protobuf:"varint,7,opt,name=allow_overlap,json=allowOverlap,proto3" json:"allow_overlap,omitempty"` // run even if the previous run's job is still going
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *MetricsHistoryResponse) Reset() {
-	*x = MetricsHistoryResponse{}
-	mi := &file_orchestrator_proto_msgTypes[52]
+func (x *CreateScheduleRequest) Reset() {
+	*x = CreateScheduleRequest{}
+	mi := &file_orchestrator_proto_msgTypes[101]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *MetricsHistoryResponse) String() string {
+func (x *CreateScheduleRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MetricsHistoryResponse) ProtoMessage() {}
+func (*CreateScheduleRequest) ProtoMessage() {}
 
-func (x *MetricsHistoryResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[52]
+func (x *CreateScheduleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[101]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3610,55 +7779,85 @@ func (x *MetricsHistoryResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MetricsHistoryResponse.ProtoReflect.Descriptor instead.
-func (*MetricsHistoryResponse) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{52}
+// Deprecated: Use CreateScheduleRequest.ProtoReflect.Descriptor instead.
+func (*CreateScheduleRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{101}
+}
+
+func (x *CreateScheduleRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *CreateScheduleRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateScheduleRequest) GetCron() string {
+	if x != nil {
+		return x.Cron
+	}
+	return ""
 }
 
-func (x *MetricsHistoryResponse) GetDeviceId() string {
+func (x *CreateScheduleRequest) GetTimezone() string {
 	if x != nil {
-		return x.DeviceId
+		return x.Timezone
 	}
 	return ""
 }
 
-func (x *MetricsHistoryResponse) GetDeviceName() string {
+func (x *CreateScheduleRequest) GetJob() *JobRequest {
 	if x != nil {
-		return x.DeviceName
+		return x.Job
+	}
+	return nil
+}
+
+func (x *CreateScheduleRequest) GetMissedPolicy() string {
+	if x != nil {
+		return x.MissedPolicy
 	}
 	return ""
 }
 
-func (x *MetricsHistoryResponse) GetSamples() []*MetricsSample {
+func (x *CreateScheduleRequest) GetAllowOverlap() bool {
 	if x != nil {
-		return x.Samples
+		return x.AllowOverlap
 	}
-	return nil
+	return false
 }
 
-type GetActivityResponse struct {
-	state         protoimpl.MessageState             `protogen:"open.v1"`
-	Activity      *ActivityData                      `protobuf:"bytes,1,opt,name=activity,proto3" json:"activity,omitempty"`
-	DeviceMetrics map[string]*MetricsHistoryResponse `protobuf:"bytes,2,rep,name=device_metrics,json=deviceMetrics,proto3" json:"device_metrics,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+type ScheduleRun struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DueMs         int64                  `protobuf:"varint,1,opt,name=due_ms,json=dueMs,proto3" json:"due_ms,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"` // "started", "skipped", "missed" or "failed"
+	JobId         string                 `protobuf:"bytes,3,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Detail        string                 `protobuf:"bytes,4,opt,name=detail,proto3" json:"detail,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetActivityResponse) Reset() {
-	*x = GetActivityResponse{}
-	mi := &file_orchestrator_proto_msgTypes[53]
+func (x *ScheduleRun) Reset() {
+	*x = ScheduleRun{}
+	mi := &file_orchestrator_proto_msgTypes[102]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetActivityResponse) String() string {
+func (x *ScheduleRun) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetActivityResponse) ProtoMessage() {}
+func (*ScheduleRun) ProtoMessage() {}
 
-func (x *GetActivityResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[53]
+func (x *ScheduleRun) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[102]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3669,59 +7868,72 @@ func (x *GetActivityResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetActivityResponse.ProtoReflect.Descriptor instead.
-func (*GetActivityResponse) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{53}
+// Deprecated: Use ScheduleRun.ProtoReflect.Descriptor instead.
+func (*ScheduleRun) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{102}
 }
 
-func (x *GetActivityResponse) GetActivity() *ActivityData {
+func (x *ScheduleRun) GetDueMs() int64 {
 	if x != nil {
-		return x.Activity
+		return x.DueMs
 	}
-	return nil
+	return 0
 }
 
-func (x *GetActivityResponse) GetDeviceMetrics() map[string]*MetricsHistoryResponse {
+func (x *ScheduleRun) GetStatus() string {
 	if x != nil {
-		return x.DeviceMetrics
+		return x.Status
 	}
-	return nil
+	return ""
 }
 
-// Enhanced task status for visualization
-type TaskStatusEnhanced struct {
-	state              protoimpl.MessageState `protogen:"open.v1"`
-	TaskId             string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
-	JobId              string                 `protobuf:"bytes,2,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
-	AssignedDeviceId   string                 `protobuf:"bytes,3,opt,name=assigned_device_id,json=assignedDeviceId,proto3" json:"assigned_device_id,omitempty"`
-	AssignedDeviceName string                 `protobuf:"bytes,4,opt,name=assigned_device_name,json=assignedDeviceName,proto3" json:"assigned_device_name,omitempty"`
-	Kind               string                 `protobuf:"bytes,5,opt,name=kind,proto3" json:"kind,omitempty"`
-	Input              string                 `protobuf:"bytes,6,opt,name=input,proto3" json:"input,omitempty"`
-	State              string                 `protobuf:"bytes,7,opt,name=state,proto3" json:"state,omitempty"`
-	Result             string                 `protobuf:"bytes,8,opt,name=result,proto3" json:"result,omitempty"`
-	Error              string                 `protobuf:"bytes,9,opt,name=error,proto3" json:"error,omitempty"`
-	GroupIndex         int32                  `protobuf:"varint,10,opt,name=group_index,json=groupIndex,proto3" json:"group_index,omitempty"`
-	StartedAtMs        int64                  `protobuf:"varint,11,opt,name=started_at_ms,json=startedAtMs,proto3" json:"started_at_ms,omitempty"`
-	EndedAtMs          int64                  `protobuf:"varint,12,opt,name=ended_at_ms,json=endedAtMs,proto3" json:"ended_at_ms,omitempty"`
-	unknownFields      protoimpl.UnknownFields
-	sizeCache          protoimpl.SizeCache
+func (x *ScheduleRun) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
 }
 
-func (x *TaskStatusEnhanced) Reset() {
-	*x = TaskStatusEnhanced{}
-	mi := &file_orchestrator_proto_msgTypes[54]
+func (x *ScheduleRun) GetDetail() string {
+	if x != nil {
+		return x.Detail
+	}
+	return ""
+}
+
+type ScheduleInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ScheduleId    string                 `protobuf:"bytes,1,opt,name=schedule_id,json=scheduleId,proto3" json:"schedule_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Cron          string                 `protobuf:"bytes,3,opt,name=cron,proto3" json:"cron,omitempty"`
+	Timezone      string                 `protobuf:"bytes,4,opt,name=timezone,proto3" json:"timezone,omitempty"`
+	Job           *JobRequest            `protobuf:"bytes,5,opt,name=job,proto3" json:"job,omitempty"`
+	MissedPolicy  string                 `protobuf:"bytes,6,opt,name=missed_policy,json=missedPolicy,proto3" json:"missed_policy,omitempty"`
+	AllowOverlap  bool                   `protobuf:"varint,7,opt,name=allow_overlap,json=allowOverlap,proto3" json:"allow_overlap,omitempty"`
+	CreatedAtMs   int64                  `protobuf:"varint,8,opt,name=created_at_ms,json=createdAtMs,proto3" json:"created_at_ms,omitempty"`
+	NextRunMs     int64                  `protobuf:"varint,9,opt,name=next_run_ms,json=nextRunMs,proto3" json:"next_run_ms,omitempty"`
+	LastRunMs     int64                  `protobuf:"varint,10,opt,name=last_run_ms,json=lastRunMs,proto3" json:"last_run_ms,omitempty"` // 0 = never
+	LastJobId     string                 `protobuf:"bytes,11,opt,name=last_job_id,json=lastJobId,proto3" json:"last_job_id,omitempty"`
+	Runs          []*ScheduleRun         `protobuf:"bytes,12,rep,name=runs,proto3" json:"runs,omitempty"` // newest last
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScheduleInfo) Reset() {
+	*x = ScheduleInfo{}
+	mi := &file_orchestrator_proto_msgTypes[103]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *TaskStatusEnhanced) String() string {
+func (x *ScheduleInfo) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TaskStatusEnhanced) ProtoMessage() {}
+func (*ScheduleInfo) ProtoMessage() {}
 
-func (x *TaskStatusEnhanced) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[54]
+func (x *ScheduleInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[103]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3732,125 +7944,117 @@ func (x *TaskStatusEnhanced) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TaskStatusEnhanced.ProtoReflect.Descriptor instead.
-func (*TaskStatusEnhanced) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{54}
+// Deprecated: Use ScheduleInfo.ProtoReflect.Descriptor instead.
+func (*ScheduleInfo) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{103}
 }
 
-func (x *TaskStatusEnhanced) GetTaskId() string {
+func (x *ScheduleInfo) GetScheduleId() string {
 	if x != nil {
-		return x.TaskId
+		return x.ScheduleId
 	}
 	return ""
 }
 
-func (x *TaskStatusEnhanced) GetJobId() string {
+func (x *ScheduleInfo) GetName() string {
 	if x != nil {
-		return x.JobId
+		return x.Name
 	}
 	return ""
 }
 
-func (x *TaskStatusEnhanced) GetAssignedDeviceId() string {
+func (x *ScheduleInfo) GetCron() string {
 	if x != nil {
-		return x.AssignedDeviceId
+		return x.Cron
 	}
 	return ""
 }
 
-func (x *TaskStatusEnhanced) GetAssignedDeviceName() string {
+func (x *ScheduleInfo) GetTimezone() string {
 	if x != nil {
-		return x.AssignedDeviceName
+		return x.Timezone
 	}
 	return ""
 }
 
-func (x *TaskStatusEnhanced) GetKind() string {
+func (x *ScheduleInfo) GetJob() *JobRequest {
 	if x != nil {
-		return x.Kind
+		return x.Job
 	}
-	return ""
+	return nil
 }
 
-func (x *TaskStatusEnhanced) GetInput() string {
+func (x *ScheduleInfo) GetMissedPolicy() string {
 	if x != nil {
-		return x.Input
+		return x.MissedPolicy
 	}
 	return ""
 }
 
-func (x *TaskStatusEnhanced) GetState() string {
+func (x *ScheduleInfo) GetAllowOverlap() bool {
 	if x != nil {
-		return x.State
+		return x.AllowOverlap
 	}
-	return ""
+	return false
 }
 
-func (x *TaskStatusEnhanced) GetResult() string {
+func (x *ScheduleInfo) GetCreatedAtMs() int64 {
 	if x != nil {
-		return x.Result
+		return x.CreatedAtMs
 	}
-	return ""
+	return 0
 }
 
-func (x *TaskStatusEnhanced) GetError() string {
+func (x *ScheduleInfo) GetNextRunMs() int64 {
 	if x != nil {
-		return x.Error
+		return x.NextRunMs
 	}
-	return ""
+	return 0
 }
 
-func (x *TaskStatusEnhanced) GetGroupIndex() int32 {
+func (x *ScheduleInfo) GetLastRunMs() int64 {
 	if x != nil {
-		return x.GroupIndex
+		return x.LastRunMs
 	}
 	return 0
 }
 
-func (x *TaskStatusEnhanced) GetStartedAtMs() int64 {
+func (x *ScheduleInfo) GetLastJobId() string {
 	if x != nil {
-		return x.StartedAtMs
+		return x.LastJobId
 	}
-	return 0
+	return ""
 }
 
-func (x *TaskStatusEnhanced) GetEndedAtMs() int64 {
+func (x *ScheduleInfo) GetRuns() []*ScheduleRun {
 	if x != nil {
-		return x.EndedAtMs
+		return x.Runs
 	}
-	return 0
+	return nil
 }
 
-type JobDetailResponse struct {
+type ListSchedulesRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
-	State         string                 `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
-	Tasks         []*TaskStatusEnhanced  `protobuf:"bytes,3,rep,name=tasks,proto3" json:"tasks,omitempty"`
-	FinalResult   string                 `protobuf:"bytes,4,opt,name=final_result,json=finalResult,proto3" json:"final_result,omitempty"`
-	CurrentGroup  int32                  `protobuf:"varint,5,opt,name=current_group,json=currentGroup,proto3" json:"current_group,omitempty"`
-	TotalGroups   int32                  `protobuf:"varint,6,opt,name=total_groups,json=totalGroups,proto3" json:"total_groups,omitempty"`
-	CreatedAtMs   int64                  `protobuf:"varint,7,opt,name=created_at_ms,json=createdAtMs,proto3" json:"created_at_ms,omitempty"`
-	StartedAtMs   int64                  `protobuf:"varint,8,opt,name=started_at_ms,json=startedAtMs,proto3" json:"started_at_ms,omitempty"`
-	EndedAtMs     int64                  `protobuf:"varint,9,opt,name=ended_at_ms,json=endedAtMs,proto3" json:"ended_at_ms,omitempty"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *JobDetailResponse) Reset() {
-	*x = JobDetailResponse{}
-	mi := &file_orchestrator_proto_msgTypes[55]
+func (x *ListSchedulesRequest) Reset() {
+	*x = ListSchedulesRequest{}
+	mi := &file_orchestrator_proto_msgTypes[104]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *JobDetailResponse) String() string {
+func (x *ListSchedulesRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*JobDetailResponse) ProtoMessage() {}
+func (*ListSchedulesRequest) ProtoMessage() {}
 
-func (x *JobDetailResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[55]
+func (x *ListSchedulesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[104]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -3861,72 +8065,112 @@ func (x *JobDetailResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use JobDetailResponse.ProtoReflect.Descriptor instead.
-func (*JobDetailResponse) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{55}
+// Deprecated: Use ListSchedulesRequest.ProtoReflect.Descriptor instead.
+func (*ListSchedulesRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{104}
 }
 
-func (x *JobDetailResponse) GetJobId() string {
+func (x *ListSchedulesRequest) GetSessionId() string {
 	if x != nil {
-		return x.JobId
+		return x.SessionId
 	}
 	return ""
 }
 
-func (x *JobDetailResponse) GetState() string {
+type ListSchedulesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Schedules     []*ScheduleInfo        `protobuf:"bytes,1,rep,name=schedules,proto3" json:"schedules,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSchedulesResponse) Reset() {
+	*x = ListSchedulesResponse{}
+	mi := &file_orchestrator_proto_msgTypes[105]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSchedulesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSchedulesResponse) ProtoMessage() {}
+
+func (x *ListSchedulesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[105]
 	if x != nil {
-		return x.State
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *JobDetailResponse) GetTasks() []*TaskStatusEnhanced {
+// Deprecated: Use ListSchedulesResponse.ProtoReflect.Descriptor instead.
+func (*ListSchedulesResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{105}
+}
+
+func (x *ListSchedulesResponse) GetSchedules() []*ScheduleInfo {
 	if x != nil {
-		return x.Tasks
+		return x.Schedules
 	}
 	return nil
 }
 
-func (x *JobDetailResponse) GetFinalResult() string {
-	if x != nil {
-		return x.FinalResult
-	}
-	return ""
+type DeleteScheduleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	ScheduleId    string                 `protobuf:"bytes,2,opt,name=schedule_id,json=scheduleId,proto3" json:"schedule_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *JobDetailResponse) GetCurrentGroup() int32 {
-	if x != nil {
-		return x.CurrentGroup
-	}
-	return 0
+func (x *DeleteScheduleRequest) Reset() {
+	*x = DeleteScheduleRequest{}
+	mi := &file_orchestrator_proto_msgTypes[106]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *JobDetailResponse) GetTotalGroups() int32 {
-	if x != nil {
-		return x.TotalGroups
-	}
-	return 0
+func (x *DeleteScheduleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *JobDetailResponse) GetCreatedAtMs() int64 {
+func (*DeleteScheduleRequest) ProtoMessage() {}
+
+func (x *DeleteScheduleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[106]
 	if x != nil {
-		return x.CreatedAtMs
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-func (x *JobDetailResponse) GetStartedAtMs() int64 {
+// Deprecated: Use DeleteScheduleRequest.ProtoReflect.Descriptor instead.
+func (*DeleteScheduleRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{106}
+}
+
+func (x *DeleteScheduleRequest) GetSessionId() string {
 	if x != nil {
-		return x.StartedAtMs
+		return x.SessionId
 	}
-	return 0
+	return ""
 }
 
-func (x *JobDetailResponse) GetEndedAtMs() int64 {
+func (x *DeleteScheduleRequest) GetScheduleId() string {
 	if x != nil {
-		return x.EndedAtMs
+		return x.ScheduleId
 	}
-	return 0
+	return ""
 }
 
 var File_orchestrator_proto protoreflect.FileDescriptor
@@ -3954,7 +8198,7 @@ const file_orchestrator_proto_rawDesc = "" +
 	"\x06stdout\x18\x02 \x01(\tR\x06stdout\x12\x16\n" +
 	"\x06stderr\x18\x03 \x01(\tR\x06stderr\"'\n" +
 	"\bDeviceId\x12\x1b\n" +
-	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\"\xb5\x04\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\"\x80\x05\n" +
 	"\n" +
 	"DeviceInfo\x12\x1b\n" +
 	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12\x1f\n" +
@@ -3974,10 +8218,23 @@ const file_orchestrator_proto_rawDesc = "" +
 	"\vram_free_mb\x18\r \x01(\x04R\tramFreeMb\x12&\n" +
 	"\x0fhas_local_model\x18\x0e \x01(\bR\rhasLocalModel\x12(\n" +
 	"\x10local_model_name\x18\x0f \x01(\tR\x0elocalModelName\x12.\n" +
-	"\x13local_chat_endpoint\x18\x10 \x01(\tR\x11localChatEndpoint\"@\n" +
+	"\x13local_chat_endpoint\x18\x10 \x01(\tR\x11localChatEndpoint\x12\x1d\n" +
+	"\n" +
+	"task_kinds\x18\x11 \x03(\tR\ttaskKinds\x12*\n" +
+	"\x05power\x18\x12 \x01(\v2\x14.edgemesh.PowerStateR\x05power\"\xdb\x01\n" +
+	"\n" +
+	"PowerState\x12\x1f\n" +
+	"\vhas_battery\x18\x01 \x01(\bR\n" +
+	"hasBattery\x12'\n" +
+	"\x0fbattery_percent\x18\x02 \x01(\x01R\x0ebatteryPercent\x12\x1a\n" +
+	"\bcharging\x18\x03 \x01(\bR\bcharging\x12\x1d\n" +
+	"\n" +
+	"plugged_in\x18\x04 \x01(\bR\tpluggedIn\x12#\n" +
+	"\rthermal_state\x18\x05 \x01(\tR\fthermalState\x12#\n" +
+	"\rtemperature_c\x18\x06 \x01(\x01R\ftemperatureC\"@\n" +
 	"\tDeviceAck\x12\x0e\n" +
 	"\x02ok\x18\x01 \x01(\bR\x02ok\x12#\n" +
-	"\rregistered_at\x18\x02 \x01(\x03R\fregisteredAt\"\xce\x02\n" +
+	"\rregistered_at\x18\x02 \x01(\x03R\fregisteredAt\"\xa5\x03\n" +
 	"\fDeviceStatus\x12\x1b\n" +
 	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12\x1b\n" +
 	"\tlast_seen\x18\x02 \x01(\x03R\blastSeen\x12\x19\n" +
@@ -3990,7 +8247,9 @@ const file_orchestrator_proto_rawDesc = "" +
 	"\x10gpu_mem_total_mb\x18\b \x01(\x04R\rgpuMemTotalMb\x12\x19\n" +
 	"\bnpu_load\x18\t \x01(\x01R\anpuLoad\x12!\n" +
 	"\ftimestamp_ms\x18\n" +
-	" \x01(\x03R\vtimestampMs\"\x14\n" +
+	" \x01(\x03R\vtimestampMs\x12*\n" +
+	"\x05power\x18\v \x01(\v2\x14.edgemesh.PowerStateR\x05power\x12)\n" +
+	"\x05links\x18\f \x03(\v2\x13.edgemesh.LinkStatsR\x05links\"\x14\n" +
 	"\x12ListDevicesRequest\"E\n" +
 	"\x13ListDevicesResponse\x12.\n" +
 	"\adevices\x18\x01 \x03(\v2\x14.edgemesh.DeviceInfoR\adevices\"X\n" +
@@ -4008,17 +8267,34 @@ const file_orchestrator_proto_rawDesc = "" +
 	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12\x1f\n" +
 	"\vserver_time\x18\x02 \x01(\x03R\n" +
 	"serverTime\x12\x18\n" +
-	"\amessage\x18\x03 \x01(\tR\amessage\"\xe5\x01\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\"*\n" +
+	"\vPingRequest\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\"Q\n" +
+	"\fPingResponse\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12$\n" +
+	"\x0eserver_time_ms\x18\x02 \x01(\x03R\fserverTimeMs\"\xed\x01\n" +
+	"\tLinkStats\x12$\n" +
+	"\x0efrom_device_id\x18\x01 \x01(\tR\ffromDeviceId\x12 \n" +
+	"\fto_device_id\x18\x02 \x01(\tR\n" +
+	"toDeviceId\x12\x15\n" +
+	"\x06rtt_ms\x18\x03 \x01(\x01R\x05rttMs\x12\"\n" +
+	"\rbytes_per_sec\x18\x04 \x01(\x01R\vbytesPerSec\x12-\n" +
+	"\x13measured_at_unix_ms\x18\x05 \x01(\x03R\x10measuredAtUnixMs\x12\x18\n" +
+	"\asamples\x18\x06 \x01(\x05R\asamples\x12\x14\n" +
+	"\x05error\x18\a \x01(\tR\x05error\"\xc3\x02\n" +
 	"\rRoutingPolicy\x120\n" +
 	"\x04mode\x18\x01 \x01(\x0e2\x1c.edgemesh.RoutingPolicy.ModeR\x04mode\x12\x1b\n" +
-	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\"\x84\x01\n" +
+	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\x12.\n" +
+	"\x13min_battery_percent\x18\x03 \x01(\x01R\x11minBatteryPercent\"\xb2\x01\n" +
 	"\x04Mode\x12\x12\n" +
 	"\x0eBEST_AVAILABLE\x10\x00\x12\x0f\n" +
 	"\vREQUIRE_NPU\x10\x01\x12\x11\n" +
 	"\rPREFER_REMOTE\x10\x02\x12\x13\n" +
 	"\x0fFORCE_DEVICE_ID\x10\x03\x12\x16\n" +
 	"\x12PREFER_LOCAL_MODEL\x10\x04\x12\x17\n" +
-	"\x13REQUIRE_LOCAL_MODEL\x10\x05\"\x94\x01\n" +
+	"\x13REQUIRE_LOCAL_MODEL\x10\x05\x12\x15\n" +
+	"\x11PREFER_PLUGGED_IN\x10\x06\x12\x15\n" +
+	"\x11AVOID_LOW_BATTERY\x10\a\"\x94\x01\n" +
 	"\x14RoutedCommandRequest\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x12/\n" +
@@ -4033,7 +8309,7 @@ const file_orchestrator_proto_rawDesc = "" +
 	"\rtotal_time_ms\x18\x05 \x01(\x01R\vtotalTimeMs\x12)\n" +
 	"\x10executed_locally\x18\x06 \x01(\bR\x0fexecutedLocally\"\x1e\n" +
 	"\x05JobId\x12\x15\n" +
-	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"\xb2\x01\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"\x9e\x02\n" +
 	"\n" +
 	"JobRequest\x12\x1d\n" +
 	"\n" +
@@ -4042,34 +8318,63 @@ const file_orchestrator_proto_rawDesc = "" +
 	"\vmax_workers\x18\x03 \x01(\x05R\n" +
 	"maxWorkers\x12\"\n" +
 	"\x04plan\x18\x04 \x01(\v2\x0e.edgemesh.PlanR\x04plan\x12,\n" +
-	"\x06reduce\x18\x05 \x01(\v2\x14.edgemesh.ReduceSpecR\x06reduce\"3\n" +
+	"\x06reduce\x18\x05 \x01(\v2\x14.edgemesh.ReduceSpecR\x06reduce\x12)\n" +
+	"\afan_out\x18\x06 \x01(\v2\x10.edgemesh.FanOutR\x06fanOut\x12#\n" +
+	"\x03map\x18\a \x01(\v2\x11.edgemesh.MapSpecR\x03map\x12\x1a\n" +
+	"\bpriority\x18\b \x01(\x05R\bpriority\"t\n" +
+	"\x06FanOut\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x14\n" +
+	"\x05input\x18\x02 \x01(\tR\x05input\x12\x1c\n" +
+	"\tplatforms\x18\x03 \x03(\tR\tplatforms\x12\"\n" +
+	"\fcapabilities\x18\x04 \x03(\tR\fcapabilities\"\xb1\x01\n" +
+	"\aMapSpec\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x16\n" +
+	"\x06prompt\x18\x02 \x01(\tR\x06prompt\x12\x14\n" +
+	"\x05items\x18\x03 \x03(\tR\x05items\x12\x1b\n" +
+	"\tdevice_id\x18\x04 \x01(\tR\bdeviceId\x12\x12\n" +
+	"\x04path\x18\x05 \x01(\tR\x04path\x12\x12\n" +
+	"\x04glob\x18\x06 \x01(\tR\x04glob\x12\x1f\n" +
+	"\vchunk_items\x18\a \x01(\x05R\n" +
+	"chunkItems\"3\n" +
 	"\x04Plan\x12+\n" +
 	"\x06groups\x18\x01 \x03(\v2\x13.edgemesh.TaskGroupR\x06groups\"K\n" +
 	"\tTaskGroup\x12\x14\n" +
 	"\x05index\x18\x01 \x01(\x05R\x05index\x12(\n" +
-	"\x05tasks\x18\x02 \x03(\v2\x12.edgemesh.TaskSpecR\x05tasks\"\xc8\x01\n" +
+	"\x05tasks\x18\x02 \x03(\v2\x12.edgemesh.TaskSpecR\x05tasks\"\x95\x02\n" +
 	"\bTaskSpec\x12\x17\n" +
 	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x12\n" +
 	"\x04kind\x18\x02 \x01(\tR\x04kind\x12\x14\n" +
 	"\x05input\x18\x03 \x01(\tR\x05input\x12(\n" +
 	"\x10target_device_id\x18\x04 \x01(\tR\x0etargetDeviceId\x12#\n" +
 	"\rprompt_tokens\x18\x05 \x01(\x05R\fpromptTokens\x12*\n" +
-	"\x11max_output_tokens\x18\x06 \x01(\x05R\x0fmaxOutputTokens\" \n" +
+	"\x11max_output_tokens\x18\x06 \x01(\x05R\x0fmaxOutputTokens\x12/\n" +
+	"\x06inputs\x18\a \x03(\v2\x17.edgemesh.InputArtifactR\x06inputs\x12\x1a\n" +
+	"\bpriority\x18\b \x01(\x05R\bpriority\"w\n" +
+	"\rInputArtifact\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x16\n" +
+	"\x06sha256\x18\x03 \x01(\tR\x06sha256\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x04 \x01(\x03R\tsizeBytes\" \n" +
 	"\n" +
 	"ReduceSpec\x12\x12\n" +
-	"\x04kind\x18\x01 \x01(\tR\x04kind\"Y\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\"t\n" +
 	"\aJobInfo\x12\x15\n" +
 	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x1d\n" +
 	"\n" +
 	"created_at\x18\x02 \x01(\x03R\tcreatedAt\x12\x18\n" +
-	"\asummary\x18\x03 \x01(\tR\asummary\"\xcf\x01\n" +
+	"\asummary\x18\x03 \x01(\tR\asummary\x12\x19\n" +
+	"\btrace_id\x18\x04 \x01(\tR\atraceId\"\x8b\x02\n" +
 	"\tJobStatus\x12\x15\n" +
 	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x14\n" +
 	"\x05state\x18\x02 \x01(\tR\x05state\x12*\n" +
 	"\x05tasks\x18\x03 \x03(\v2\x14.edgemesh.TaskStatusR\x05tasks\x12!\n" +
 	"\ffinal_result\x18\x04 \x01(\tR\vfinalResult\x12#\n" +
 	"\rcurrent_group\x18\x05 \x01(\x05R\fcurrentGroup\x12!\n" +
-	"\ftotal_groups\x18\x06 \x01(\x05R\vtotalGroups\"\xc9\x01\n" +
+	"\ftotal_groups\x18\x06 \x01(\x05R\vtotalGroups\x12\x19\n" +
+	"\btrace_id\x18\a \x01(\tR\atraceId\x12\x1f\n" +
+	"\vschedule_id\x18\b \x01(\tR\n" +
+	"scheduleId\"\xf6\x01\n" +
 	"\n" +
 	"TaskStatus\x12\x17\n" +
 	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12,\n" +
@@ -4077,35 +8382,125 @@ const file_orchestrator_proto_rawDesc = "" +
 	"\x14assigned_device_name\x18\x03 \x01(\tR\x12assignedDeviceName\x12\x14\n" +
 	"\x05state\x18\x04 \x01(\tR\x05state\x12\x16\n" +
 	"\x06result\x18\x05 \x01(\tR\x06result\x12\x14\n" +
-	"\x05error\x18\x06 \x01(\tR\x05error\"g\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\x12+\n" +
+	"\x05shell\x18\a \x01(\v2\x15.edgemesh.ShellResultR\x05shell\"\xa7\x01\n" +
 	"\vTaskRequest\x12\x17\n" +
 	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x15\n" +
 	"\x06job_id\x18\x02 \x01(\tR\x05jobId\x12\x12\n" +
 	"\x04kind\x18\x03 \x01(\tR\x04kind\x12\x14\n" +
-	"\x05input\x18\x04 \x01(\tR\x05input\"|\n" +
+	"\x05input\x18\x04 \x01(\tR\x05input\x12\x1f\n" +
+	"\vinput_paths\x18\x05 \x03(\tR\n" +
+	"inputPaths\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x06 \x01(\tR\tsessionId\"\xa9\x01\n" +
 	"\n" +
 	"TaskResult\x12\x17\n" +
 	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x0e\n" +
 	"\x02ok\x18\x02 \x01(\bR\x02ok\x12\x16\n" +
 	"\x06output\x18\x03 \x01(\tR\x06output\x12\x14\n" +
 	"\x05error\x18\x04 \x01(\tR\x05error\x12\x17\n" +
-	"\atime_ms\x18\x05 \x01(\x01R\x06timeMs\"\x94\x01\n" +
+	"\atime_ms\x18\x05 \x01(\x01R\x06timeMs\x12+\n" +
+	"\x05shell\x18\x06 \x01(\v2\x15.edgemesh.ShellResultR\x05shell\"\xc4\x01\n" +
+	"\vShellResult\x12\x18\n" +
+	"\acommand\x18\x01 \x01(\tR\acommand\x12\x1b\n" +
+	"\texit_code\x18\x02 \x01(\x05R\bexitCode\x12\x1f\n" +
+	"\vstdout_tail\x18\x03 \x01(\tR\n" +
+	"stdoutTail\x12\x1f\n" +
+	"\vstderr_tail\x18\x04 \x01(\tR\n" +
+	"stderrTail\x12\x1f\n" +
+	"\vduration_ms\x18\x05 \x01(\x01R\n" +
+	"durationMs\x12\x1b\n" +
+	"\ttimed_out\x18\x06 \x01(\bR\btimedOut\"\xe9\x03\n" +
 	"\fWebRTCConfig\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1d\n" +
 	"\n" +
 	"target_fps\x18\x02 \x01(\x05R\ttargetFps\x12!\n" +
 	"\fjpeg_quality\x18\x03 \x01(\x05R\vjpegQuality\x12#\n" +
-	"\rmonitor_index\x18\x04 \x01(\x05R\fmonitorIndex\"<\n" +
+	"\rmonitor_index\x18\x04 \x01(\x05R\fmonitorIndex\x12\x12\n" +
+	"\x04mode\x18\x05 \x01(\tR\x04mode\x12(\n" +
+	"\x10max_bitrate_kbps\x18\x06 \x01(\x05R\x0emaxBitrateKbps\x12!\n" +
+	"\faccept_modes\x18\a \x03(\tR\vacceptModes\x12#\n" +
+	"\rinput_control\x18\b \x01(\bR\finputControl\x12\x14\n" +
+	"\x05audio\x18\t \x01(\bR\x05audio\x12!\n" +
+	"\faudio_source\x18\n" +
+	" \x01(\tR\vaudioSource\x12\x18\n" +
+	"\atrickle\x18\v \x01(\bR\atrickle\x124\n" +
+	"\vice_servers\x18\f \x03(\v2\x13.edgemesh.IceServerR\n" +
+	"iceServers\x12\x14\n" +
+	"\x05layer\x18\r \x01(\tR\x05layer\x12\x16\n" +
+	"\x06viewer\x18\x0e \x01(\tR\x06viewer\x12\x16\n" +
+	"\x06record\x18\x0f \x01(\bR\x06record\"\x8f\x02\n" +
 	"\vWebRTCOffer\x12\x1b\n" +
 	"\tstream_id\x18\x01 \x01(\tR\bstreamId\x12\x10\n" +
-	"\x03sdp\x18\x02 \x01(\tR\x03sdp\"=\n" +
+	"\x03sdp\x18\x02 \x01(\tR\x03sdp\x12\x12\n" +
+	"\x04mode\x18\x03 \x01(\tR\x04mode\x12#\n" +
+	"\rinput_control\x18\x04 \x01(\bR\finputControl\x12\x14\n" +
+	"\x05audio\x18\x05 \x01(\bR\x05audio\x12\x18\n" +
+	"\atrickle\x18\x06 \x01(\bR\atrickle\x124\n" +
+	"\vice_servers\x18\a \x03(\v2\x13.edgemesh.IceServerR\n" +
+	"iceServers\x12\x14\n" +
+	"\x05layer\x18\b \x01(\tR\x05layer\x12\x1c\n" +
+	"\trecording\x18\t \x01(\tR\trecording\"=\n" +
 	"\fWebRTCAnswer\x12\x1b\n" +
 	"\tstream_id\x18\x01 \x01(\tR\bstreamId\x12\x10\n" +
 	"\x03sdp\x18\x02 \x01(\tR\x03sdp\")\n" +
 	"\n" +
 	"WebRTCStop\x12\x1b\n" +
-	"\tstream_id\x18\x01 \x01(\tR\bstreamId\"h\n" +
+	"\tstream_id\x18\x01 \x01(\tR\bstreamId\"[\n" +
+	"\tIceServer\x12\x12\n" +
+	"\x04urls\x18\x01 \x03(\tR\x04urls\x12\x1a\n" +
+	"\busername\x18\x02 \x01(\tR\busername\x12\x1e\n" +
+	"\n" +
+	"credential\x18\x03 \x01(\tR\n" +
+	"credential\"m\n" +
+	"\fIceCandidate\x12\x1c\n" +
+	"\tcandidate\x18\x01 \x01(\tR\tcandidate\x12\x17\n" +
+	"\asdp_mid\x18\x02 \x01(\tR\x06sdpMid\x12&\n" +
+	"\x0fsdp_mline_index\x18\x03 \x01(\x05R\rsdpMlineIndex\"h\n" +
+	"\x13IceCandidateRequest\x12\x1b\n" +
+	"\tstream_id\x18\x01 \x01(\tR\bstreamId\x124\n" +
+	"\tcandidate\x18\x02 \x01(\v2\x16.edgemesh.IceCandidateR\tcandidate\"b\n" +
+	"\x14IceCandidatesRequest\x12\x1b\n" +
+	"\tstream_id\x18\x01 \x01(\tR\bstreamId\x12\x14\n" +
+	"\x05since\x18\x02 \x01(\x05R\x05since\x12\x17\n" +
+	"\await_ms\x18\x03 \x01(\x05R\x06waitMs\"k\n" +
+	"\x15IceCandidatesResponse\x126\n" +
+	"\n" +
+	"candidates\x18\x01 \x03(\v2\x16.edgemesh.IceCandidateR\n" +
+	"candidates\x12\x1a\n" +
+	"\bcomplete\x18\x02 \x01(\bR\bcomplete\"3\n" +
+	"\x12ListStreamsRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\"\x9a\x03\n" +
+	"\rStreamSession\x12\x1b\n" +
+	"\tstream_id\x18\x01 \x01(\tR\bstreamId\x12\x16\n" +
+	"\x06viewer\x18\x03 \x01(\tR\x06viewer\x12\x12\n" +
+	"\x04mode\x18\x04 \x01(\tR\x04mode\x12\x14\n" +
+	"\x05layer\x18\x05 \x01(\tR\x05layer\x12#\n" +
+	"\rmonitor_index\x18\x06 \x01(\x05R\fmonitorIndex\x12\x1d\n" +
+	"\n" +
+	"target_fps\x18\a \x01(\x05R\ttargetFps\x12!\n" +
+	"\fbitrate_kbps\x18\b \x01(\x05R\vbitrateKbps\x12\x14\n" +
+	"\x05state\x18\t \x01(\tR\x05state\x12\"\n" +
+	"\rstarted_at_ms\x18\n" +
+	" \x01(\x03R\vstartedAtMs\x12\x17\n" +
+	"\aidle_ms\x18\v \x01(\x03R\x06idleMs\x12\x14\n" +
+	"\x05audio\x18\f \x01(\bR\x05audio\x12\x18\n" +
+	"\acontrol\x18\r \x01(\tR\acontrol\x12\x1c\n" +
+	"\trecording\x18\x0e \x01(\tR\trecording\x12\x1c\n" +
+	"\tprincipal\x18\x0f \x01(\tR\tprincipalJ\x04\b\x02\x10\x03\"\x95\x01\n" +
+	"\vCaptureFeed\x12#\n" +
+	"\rmonitor_index\x18\x01 \x01(\x05R\fmonitorIndex\x12 \n" +
+	"\vsubscribers\x18\x02 \x01(\x05R\vsubscribers\x12\x1a\n" +
+	"\bcaptures\x18\x03 \x01(\x04R\bcaptures\x12#\n" +
+	"\rframes_served\x18\x04 \x01(\x04R\fframesServed\"\xbe\x01\n" +
+	"\x13ListStreamsResponse\x121\n" +
+	"\astreams\x18\x01 \x03(\v2\x17.edgemesh.StreamSessionR\astreams\x12+\n" +
+	"\x05feeds\x18\x02 \x03(\v2\x15.edgemesh.CaptureFeedR\x05feeds\x12\x1f\n" +
+	"\vmax_viewers\x18\x03 \x01(\x05R\n" +
+	"maxViewers\x12&\n" +
+	"\x0fidle_timeout_ms\x18\x04 \x01(\x03R\ridleTimeoutMs\"h\n" +
 	"\x12PlanPreviewRequest\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x12\n" +
@@ -4123,14 +8518,19 @@ const file_orchestrator_proto_rawDesc = "" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x12\"\n" +
 	"\x04plan\x18\x02 \x01(\v2\x0e.edgemesh.PlanR\x04plan\x12\x1d\n" +
 	"\n" +
-	"device_ids\x18\x03 \x03(\tR\tdeviceIds\"\xb3\x02\n" +
+	"device_ids\x18\x03 \x03(\tR\tdeviceIds\"\x93\x03\n" +
 	"\x10PlanCostResponse\x12,\n" +
 	"\x12total_predicted_ms\x18\x01 \x01(\x01R\x10totalPredictedMs\x12?\n" +
 	"\fdevice_costs\x18\x02 \x03(\v2\x1c.edgemesh.DeviceCostEstimateR\vdeviceCosts\x122\n" +
 	"\x15recommended_device_id\x18\x03 \x01(\tR\x13recommendedDeviceId\x126\n" +
 	"\x17recommended_device_name\x18\x04 \x01(\tR\x15recommendedDeviceName\x12*\n" +
 	"\x11has_unknown_costs\x18\x05 \x01(\bR\x0fhasUnknownCosts\x12\x18\n" +
-	"\awarning\x18\x06 \x01(\tR\awarning\"\x82\x02\n" +
+	"\awarning\x18\x06 \x01(\tR\awarning\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\a \x01(\tR\n" +
+	"confidence\x12\x18\n" +
+	"\asamples\x18\b \x01(\x05R\asamples\x12$\n" +
+	"\x0etotal_energy_j\x18\t \x01(\x01R\ftotalEnergyJ\"\x8b\x03\n" +
 	"\x12DeviceCostEstimate\x12\x1b\n" +
 	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12\x1f\n" +
 	"\vdevice_name\x18\x02 \x01(\tR\n" +
@@ -4139,22 +8539,69 @@ const file_orchestrator_proto_rawDesc = "" +
 	"\n" +
 	"step_costs\x18\x04 \x03(\v2\x1a.edgemesh.StepCostEstimateR\tstepCosts\x121\n" +
 	"\x15estimated_peak_ram_mb\x18\x05 \x01(\x04R\x12estimatedPeakRamMb\x12%\n" +
-	"\x0eram_sufficient\x18\x06 \x01(\bR\rramSufficient\"\xcb\x01\n" +
+	"\x0eram_sufficient\x18\x06 \x01(\bR\rramSufficient\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\a \x01(\tR\n" +
+	"confidence\x12\x18\n" +
+	"\asamples\x18\b \x01(\x05R\asamples\x12\x19\n" +
+	"\benergy_j\x18\t \x01(\x01R\aenergyJ\x122\n" +
+	"\x15battery_drain_percent\x18\n" +
+	" \x01(\x01R\x13batteryDrainPercent\"\x87\x03\n" +
 	"\x10StepCostEstimate\x12\x17\n" +
 	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x12\n" +
 	"\x04kind\x18\x02 \x01(\tR\x04kind\x12!\n" +
 	"\fpredicted_ms\x18\x03 \x01(\x01R\vpredictedMs\x12.\n" +
 	"\x13predicted_memory_mb\x18\x04 \x01(\x01R\x11predictedMemoryMb\x12!\n" +
 	"\funknown_cost\x18\x05 \x01(\bR\vunknownCost\x12\x14\n" +
-	"\x05notes\x18\x06 \x01(\tR\x05notes\"+\n" +
+	"\x05notes\x18\x06 \x01(\tR\x05notes\x12\x1f\n" +
+	"\vtransfer_ms\x18\a \x01(\x01R\n" +
+	"transferMs\x12%\n" +
+	"\x0etransfer_bytes\x18\b \x01(\x03R\rtransferBytes\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\t \x01(\tR\n" +
+	"confidence\x12\x18\n" +
+	"\asamples\x18\n" +
+	" \x01(\x05R\asamples\x12\x19\n" +
+	"\benergy_j\x18\v \x01(\x01R\aenergyJ\x12\x1d\n" +
+	"\n" +
+	"network_ms\x18\f \x01(\x01R\tnetworkMs\"+\n" +
 	"\x15DownloadTicketRequest\x12\x12\n" +
-	"\x04path\x18\x01 \x01(\tR\x04path\"\x91\x01\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\"\xa5\x01\n" +
 	"\x16DownloadTicketResponse\x12\x14\n" +
 	"\x05token\x18\x01 \x01(\tR\x05token\x12\x1a\n" +
 	"\bfilename\x18\x02 \x01(\tR\bfilename\x12\x1d\n" +
 	"\n" +
 	"size_bytes\x18\x03 \x01(\x03R\tsizeBytes\x12&\n" +
-	"\x0fexpires_unix_ms\x18\x04 \x01(\x03R\rexpiresUnixMs\"\xd6\x01\n" +
+	"\x0fexpires_unix_ms\x18\x04 \x01(\x03R\rexpiresUnixMs\x12\x12\n" +
+	"\x04live\x18\x05 \x01(\bR\x04live\"\x9d\x01\n" +
+	"\x13UploadTicketRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x02 \x01(\x03R\tsizeBytes\x12\x16\n" +
+	"\x06sha256\x18\x03 \x01(\tR\x06sha256\x12\x1c\n" +
+	"\toverwrite\x18\x04 \x01(\bR\toverwrite\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x05 \x01(\tR\tsessionId\"\xbc\x01\n" +
+	"\x14UploadTicketResponse\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12(\n" +
+	"\x10chunk_size_bytes\x18\x03 \x01(\x03R\x0echunkSizeBytes\x12&\n" +
+	"\x0fexpires_unix_ms\x18\x04 \x01(\x03R\rexpiresUnixMs\x12(\n" +
+	"\x10max_upload_bytes\x18\x05 \x01(\x03R\x0emaxUploadBytes\"\xb0\x01\n" +
+	"\x0ePutFileRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1b\n" +
+	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\x12\x12\n" +
+	"\x04path\x18\x03 \x01(\tR\x04path\x12\x18\n" +
+	"\acontent\x18\x04 \x01(\fR\acontent\x12\x16\n" +
+	"\x06sha256\x18\x05 \x01(\tR\x06sha256\x12\x1c\n" +
+	"\toverwrite\x18\x06 \x01(\bR\toverwrite\"r\n" +
+	"\x0fPutFileResponse\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x02 \x01(\x03R\tsizeBytes\x12\x16\n" +
+	"\x06sha256\x18\x03 \x01(\tR\x06sha256\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"\xd6\x01\n" +
 	"\x0fReadFileRequest\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1b\n" +
@@ -4171,7 +8618,111 @@ const file_orchestrator_proto_rawDesc = "" +
 	"\x0ebytes_returned\x18\x03 \x01(\x03R\rbytesReturned\x12\x1c\n" +
 	"\ttruncated\x18\x04 \x01(\bR\ttruncated\x12\x14\n" +
 	"\x05error\x18\x05 \x01(\tR\x05error\x12'\n" +
-	"\x0fcontent_preview\x18\x06 \x01(\tR\x0econtentPreview\"v\n" +
+	"\x0fcontent_preview\x18\x06 \x01(\tR\x0econtentPreview\"\xa3\x01\n" +
+	"\tFileEntry\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x04 \x01(\x03R\tsizeBytes\x12'\n" +
+	"\x10mod_time_unix_ms\x18\x05 \x01(\x03R\rmodTimeUnixMs\x12\x12\n" +
+	"\x04mode\x18\x06 \x01(\tR\x04mode\"\xd7\x01\n" +
+	"\x0eListDirRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1b\n" +
+	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\x12\x12\n" +
+	"\x04path\x18\x03 \x01(\tR\x04path\x12\x12\n" +
+	"\x04glob\x18\x04 \x01(\tR\x04glob\x12\x1b\n" +
+	"\tpage_size\x18\x05 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x06 \x01(\tR\tpageToken\x12%\n" +
+	"\x0einclude_hidden\x18\a \x01(\bR\rincludeHidden\"\xb7\x01\n" +
+	"\x0fListDirResponse\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12-\n" +
+	"\aentries\x18\x02 \x03(\v2\x13.edgemesh.FileEntryR\aentries\x12&\n" +
+	"\x0fnext_page_token\x18\x03 \x01(\tR\rnextPageToken\x12#\n" +
+	"\rtotal_matches\x18\x04 \x01(\x05R\ftotalMatches\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\"a\n" +
+	"\x0fStatFileRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1b\n" +
+	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\x12\x12\n" +
+	"\x04path\x18\x03 \x01(\tR\x04path\"k\n" +
+	"\x10StatFileResponse\x12\x16\n" +
+	"\x06exists\x18\x01 \x01(\bR\x06exists\x12)\n" +
+	"\x05entry\x18\x02 \x01(\v2\x13.edgemesh.FileEntryR\x05entry\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"u\n" +
+	"\bSyncFile\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x02 \x01(\x03R\tsizeBytes\x12\x1e\n" +
+	"\vmod_time_ms\x18\x03 \x01(\x03R\tmodTimeMs\x12\x16\n" +
+	"\x06sha256\x18\x04 \x01(\tR\x06sha256\"4\n" +
+	"\x13SyncManifestRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\"~\n" +
+	"\x14SyncManifestResponse\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12\x1f\n" +
+	"\vdevice_name\x18\x02 \x01(\tR\n" +
+	"deviceName\x12(\n" +
+	"\x05files\x18\x03 \x03(\v2\x12.edgemesh.SyncFileR\x05files\"j\n" +
+	"\x11SyncStatusRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1b\n" +
+	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\x12\x19\n" +
+	"\bsync_now\x18\x03 \x01(\bR\asyncNow\"\xcb\x02\n" +
+	"\x0eSyncPeerStatus\x12\x17\n" +
+	"\apeer_id\x18\x01 \x01(\tR\x06peerId\x12\x1b\n" +
+	"\tpeer_name\x18\x02 \x01(\tR\bpeerName\x12)\n" +
+	"\x11last_sync_unix_ms\x18\x03 \x01(\x03R\x0elastSyncUnixMs\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\x04 \x01(\tR\tlastError\x12\x16\n" +
+	"\x06rounds\x18\x05 \x01(\x05R\x06rounds\x12!\n" +
+	"\ffiles_pulled\x18\x06 \x01(\x05R\vfilesPulled\x12#\n" +
+	"\rfiles_deleted\x18\a \x01(\x05R\ffilesDeleted\x12\x1c\n" +
+	"\tconflicts\x18\b \x01(\x05R\tconflicts\x12\x18\n" +
+	"\apending\x18\t \x01(\x05R\apending\x12!\n" +
+	"\fbytes_pulled\x18\n" +
+	" \x01(\x03R\vbytesPulled\"\xf6\x01\n" +
+	"\x12SyncStatusResponse\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\x12\x1b\n" +
+	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\x12\x1f\n" +
+	"\vlocal_files\x18\x03 \x01(\x05R\n" +
+	"localFiles\x12\x1f\n" +
+	"\vlocal_bytes\x18\x04 \x01(\x03R\n" +
+	"localBytes\x12\x1f\n" +
+	"\vinterval_ms\x18\x05 \x01(\x03R\n" +
+	"intervalMs\x12\x16\n" +
+	"\x06paired\x18\x06 \x03(\tR\x06paired\x12.\n" +
+	"\x05peers\x18\a \x03(\v2\x18.edgemesh.SyncPeerStatusR\x05peers\"O\n" +
+	"\x16LocateArtifactsRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x16\n" +
+	"\x06sha256\x18\x02 \x03(\tR\x06sha256\"]\n" +
+	"\x10ArtifactLocation\x12\x16\n" +
+	"\x06sha256\x18\x01 \x01(\tR\x06sha256\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x03 \x01(\x03R\tsizeBytes\"h\n" +
+	"\x17LocateArtifactsResponse\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x120\n" +
+	"\x05found\x18\x02 \x03(\v2\x1a.edgemesh.ArtifactLocationR\x05found\"\xc2\x01\n" +
+	"\x10StageFileRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x12\n" +
+	"\x04path\x18\x03 \x01(\tR\x04path\x12\x16\n" +
+	"\x06sha256\x18\x04 \x01(\tR\x06sha256\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x05 \x01(\x03R\tsizeBytes\x12(\n" +
+	"\x10source_device_id\x18\x06 \x01(\tR\x0esourceDeviceId\x12\x14\n" +
+	"\x05token\x18\a \x01(\tR\x05tokenJ\x04\b\x02\x10\x03\"\x9d\x01\n" +
+	"\x11StageFileResponse\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x02 \x01(\x03R\tsizeBytes\x12\x16\n" +
+	"\x06sha256\x18\x03 \x01(\tR\x06sha256\x12'\n" +
+	"\x0falready_present\x18\x04 \x01(\bR\x0ealreadyPresent\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\"v\n" +
 	"\x0eChatMemorySync\x12\x1b\n" +
 	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12&\n" +
 	"\x0flast_updated_ms\x18\x02 \x01(\x03R\rlastUpdatedMs\x12\x1f\n" +
@@ -4194,7 +8745,30 @@ const file_orchestrator_proto_rawDesc = "" +
 	"\n" +
 	"model_used\x18\x02 \x01(\tR\tmodelUsed\x12)\n" +
 	"\x10tokens_generated\x18\x03 \x01(\x03R\x0ftokensGenerated\x12\x14\n" +
-	"\x05error\x18\x04 \x01(\tR\x05error\"\x95\x02\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"\x92\x01\n" +
+	"\x10BenchmarkRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1b\n" +
+	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\x12\x16\n" +
+	"\x06models\x18\x03 \x03(\tR\x06models\x12\x12\n" +
+	"\x04runs\x18\x04 \x01(\x05R\x04runs\x12\x16\n" +
+	"\x06cached\x18\x05 \x01(\bR\x06cached\"\xfd\x02\n" +
+	"\fLLMBenchmark\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12\x1a\n" +
+	"\bprovider\x18\x02 \x01(\tR\bprovider\x12\x14\n" +
+	"\x05model\x18\x03 \x01(\tR\x05model\x12+\n" +
+	"\x12prefill_toks_per_s\x18\x04 \x01(\x01R\x0fprefillToksPerS\x12)\n" +
+	"\x11decode_toks_per_s\x18\x05 \x01(\x01R\x0edecodeToksPerS\x12#\n" +
+	"\rprompt_tokens\x18\x06 \x01(\x03R\fpromptTokens\x12#\n" +
+	"\routput_tokens\x18\a \x01(\x03R\foutputTokens\x12\x16\n" +
+	"\x06method\x18\b \x01(\tR\x06method\x12-\n" +
+	"\x13measured_at_unix_ms\x18\t \x01(\x03R\x10measuredAtUnixMs\x12\x1f\n" +
+	"\vduration_ms\x18\n" +
+	" \x01(\x03R\n" +
+	"durationMs\x12\x14\n" +
+	"\x05error\x18\v \x01(\tR\x05error\"E\n" +
+	"\x11BenchmarkResponse\x120\n" +
+	"\aresults\x18\x01 \x03(\v2\x16.edgemesh.LLMBenchmarkR\aresults\"\x95\x02\n" +
 	"\rMetricsSample\x12!\n" +
 	"\ftimestamp_ms\x18\x01 \x01(\x03R\vtimestampMs\x12\x19\n" +
 	"\bcpu_load\x18\x02 \x01(\x01R\acpuLoad\x12\x1e\n" +
@@ -4221,24 +8795,47 @@ const file_orchestrator_proto_rawDesc = "" +
 	"\vdevice_name\x18\x02 \x01(\tR\n" +
 	"deviceName\x12,\n" +
 	"\x12running_task_count\x18\x03 \x01(\x05R\x10runningTaskCount\x12=\n" +
-	"\x0ecurrent_status\x18\x04 \x01(\v2\x16.edgemesh.DeviceStatusR\rcurrentStatus\"\x91\x01\n" +
+	"\x0ecurrent_status\x18\x04 \x01(\v2\x16.edgemesh.DeviceStatusR\rcurrentStatus\"\xbc\x01\n" +
 	"\fActivityData\x12:\n" +
 	"\rrunning_tasks\x18\x01 \x03(\v2\x15.edgemesh.RunningTaskR\frunningTasks\x12E\n" +
-	"\x11device_activities\x18\x02 \x03(\v2\x18.edgemesh.DeviceActivityR\x10deviceActivities\"v\n" +
+	"\x11device_activities\x18\x02 \x03(\v2\x18.edgemesh.DeviceActivityR\x10deviceActivities\x12)\n" +
+	"\x05links\x18\x03 \x03(\v2\x13.edgemesh.LinkStatsR\x05links\"v\n" +
 	"\x12GetActivityRequest\x126\n" +
 	"\x17include_metrics_history\x18\x01 \x01(\bR\x15includeMetricsHistory\x12(\n" +
-	"\x10metrics_since_ms\x18\x02 \x01(\x03R\x0emetricsSinceMs\"\x89\x01\n" +
+	"\x10metrics_since_ms\x18\x02 \x01(\x03R\x0emetricsSinceMs\"~\n" +
+	"\fMetricsQuery\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12\x17\n" +
+	"\afrom_ms\x18\x02 \x01(\x03R\x06fromMs\x12\x13\n" +
+	"\x05to_ms\x18\x03 \x01(\x03R\x04toMs\x12#\n" +
+	"\rresolution_ms\x18\x04 \x01(\x03R\fresolutionMs\"Y\n" +
+	"\vMetricStats\x12\x10\n" +
+	"\x03min\x18\x01 \x01(\x01R\x03min\x12\x10\n" +
+	"\x03max\x18\x02 \x01(\x01R\x03max\x12\x10\n" +
+	"\x03avg\x18\x03 \x01(\x01R\x03avg\x12\x14\n" +
+	"\x05count\x18\x04 \x01(\x03R\x05count\"\xad\x03\n" +
+	"\fMetricsPoint\x12\x19\n" +
+	"\bstart_ms\x18\x01 \x01(\x03R\astartMs\x120\n" +
+	"\bcpu_load\x18\x02 \x01(\v2\x15.edgemesh.MetricStatsR\acpuLoad\x125\n" +
+	"\vmem_used_mb\x18\x03 \x01(\v2\x15.edgemesh.MetricStatsR\tmemUsedMb\x127\n" +
+	"\fmem_total_mb\x18\x04 \x01(\v2\x15.edgemesh.MetricStatsR\n" +
+	"memTotalMb\x120\n" +
+	"\bgpu_load\x18\x05 \x01(\v2\x15.edgemesh.MetricStatsR\agpuLoad\x12<\n" +
+	"\x0fgpu_mem_used_mb\x18\x06 \x01(\v2\x15.edgemesh.MetricStatsR\fgpuMemUsedMb\x12>\n" +
+	"\x10gpu_mem_total_mb\x18\a \x01(\v2\x15.edgemesh.MetricStatsR\rgpuMemTotalMb\x120\n" +
+	"\bnpu_load\x18\b \x01(\v2\x15.edgemesh.MetricStatsR\anpuLoad\"\xde\x01\n" +
 	"\x16MetricsHistoryResponse\x12\x1b\n" +
 	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12\x1f\n" +
 	"\vdevice_name\x18\x02 \x01(\tR\n" +
 	"deviceName\x121\n" +
-	"\asamples\x18\x03 \x03(\v2\x17.edgemesh.MetricsSampleR\asamples\"\x86\x02\n" +
+	"\asamples\x18\x03 \x03(\v2\x17.edgemesh.MetricsSampleR\asamples\x12.\n" +
+	"\x06points\x18\x04 \x03(\v2\x16.edgemesh.MetricsPointR\x06points\x12#\n" +
+	"\rresolution_ms\x18\x05 \x01(\x03R\fresolutionMs\"\x86\x02\n" +
 	"\x13GetActivityResponse\x122\n" +
 	"\bactivity\x18\x01 \x01(\v2\x16.edgemesh.ActivityDataR\bactivity\x12W\n" +
 	"\x0edevice_metrics\x18\x02 \x03(\v20.edgemesh.GetActivityResponse.DeviceMetricsEntryR\rdeviceMetrics\x1ab\n" +
 	"\x12DeviceMetricsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x126\n" +
-	"\x05value\x18\x02 \x01(\v2 .edgemesh.MetricsHistoryResponseR\x05value:\x028\x01\"\xf7\x02\n" +
+	"\x05value\x18\x02 \x01(\v2 .edgemesh.MetricsHistoryResponseR\x05value:\x028\x01\"\xeb\x04\n" +
 	"\x12TaskStatusEnhanced\x12\x17\n" +
 	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x15\n" +
 	"\x06job_id\x18\x02 \x01(\tR\x05jobId\x12,\n" +
@@ -4253,7 +8850,15 @@ const file_orchestrator_proto_rawDesc = "" +
 	" \x01(\x05R\n" +
 	"groupIndex\x12\"\n" +
 	"\rstarted_at_ms\x18\v \x01(\x03R\vstartedAtMs\x12\x1e\n" +
-	"\vended_at_ms\x18\f \x01(\x03R\tendedAtMs\"\xc7\x02\n" +
+	"\vended_at_ms\x18\f \x01(\x03R\tendedAtMs\x12\x1f\n" +
+	"\vinput_paths\x18\r \x03(\tR\n" +
+	"inputPaths\x12!\n" +
+	"\fstaged_bytes\x18\x0e \x01(\x03R\vstagedBytes\x12\x1c\n" +
+	"\tplacement\x18\x0f \x01(\tR\tplacement\x12+\n" +
+	"\x05shell\x18\x10 \x01(\v2\x15.edgemesh.ShellResultR\x05shell\x12\x1a\n" +
+	"\bpriority\x18\x11 \x01(\x05R\bpriority\x12%\n" +
+	"\x0equeue_position\x18\x12 \x01(\x05R\rqueuePosition\x12 \n" +
+	"\vpreemptions\x18\x13 \x01(\x05R\vpreemptions\"\x84\x03\n" +
 	"\x11JobDetailResponse\x12\x15\n" +
 	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x14\n" +
 	"\x05state\x18\x02 \x01(\tR\x05state\x122\n" +
@@ -4263,12 +8868,74 @@ const file_orchestrator_proto_rawDesc = "" +
 	"\ftotal_groups\x18\x06 \x01(\x05R\vtotalGroups\x12\"\n" +
 	"\rcreated_at_ms\x18\a \x01(\x03R\vcreatedAtMs\x12\"\n" +
 	"\rstarted_at_ms\x18\b \x01(\x03R\vstartedAtMs\x12\x1e\n" +
-	"\vended_at_ms\x18\t \x01(\x03R\tendedAtMs*[\n" +
+	"\vended_at_ms\x18\t \x01(\x03R\tendedAtMs\x12\x1f\n" +
+	"\vschedule_id\x18\n" +
+	" \x01(\tR\n" +
+	"scheduleId\x12\x1a\n" +
+	"\bpriority\x18\v \x01(\x05R\bpriority\"Z\n" +
+	"\x12WatchAlertsRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12%\n" +
+	"\x0einclude_firing\x18\x02 \x01(\bR\rincludeFiring\"\xaa\x02\n" +
+	"\x05Alert\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04rule\x18\x02 \x01(\tR\x04rule\x12\x12\n" +
+	"\x04kind\x18\x03 \x01(\tR\x04kind\x12\x1a\n" +
+	"\bseverity\x18\x04 \x01(\tR\bseverity\x12\x14\n" +
+	"\x05state\x18\x05 \x01(\tR\x05state\x12\x1b\n" +
+	"\tdevice_id\x18\x06 \x01(\tR\bdeviceId\x12\x1f\n" +
+	"\vdevice_name\x18\a \x01(\tR\n" +
+	"deviceName\x12\x15\n" +
+	"\x06job_id\x18\b \x01(\tR\x05jobId\x12\x14\n" +
+	"\x05value\x18\t \x01(\x01R\x05value\x12\x18\n" +
+	"\amessage\x18\n" +
+	" \x01(\tR\amessage\x12\x19\n" +
+	"\bsince_ms\x18\v \x01(\x03R\asinceMs\x12\x17\n" +
+	"\atime_ms\x18\f \x01(\x03R\x06timeMs\"\xec\x01\n" +
+	"\x15CreateScheduleRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04cron\x18\x03 \x01(\tR\x04cron\x12\x1a\n" +
+	"\btimezone\x18\x04 \x01(\tR\btimezone\x12&\n" +
+	"\x03job\x18\x05 \x01(\v2\x14.edgemesh.JobRequestR\x03job\x12#\n" +
+	"\rmissed_policy\x18\x06 \x01(\tR\fmissedPolicy\x12#\n" +
+	"\rallow_overlap\x18\a \x01(\bR\fallowOverlap\"k\n" +
+	"\vScheduleRun\x12\x15\n" +
+	"\x06due_ms\x18\x01 \x01(\x03R\x05dueMs\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x15\n" +
+	"\x06job_id\x18\x03 \x01(\tR\x05jobId\x12\x16\n" +
+	"\x06detail\x18\x04 \x01(\tR\x06detail\"\x94\x03\n" +
+	"\fScheduleInfo\x12\x1f\n" +
+	"\vschedule_id\x18\x01 \x01(\tR\n" +
+	"scheduleId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04cron\x18\x03 \x01(\tR\x04cron\x12\x1a\n" +
+	"\btimezone\x18\x04 \x01(\tR\btimezone\x12&\n" +
+	"\x03job\x18\x05 \x01(\v2\x14.edgemesh.JobRequestR\x03job\x12#\n" +
+	"\rmissed_policy\x18\x06 \x01(\tR\fmissedPolicy\x12#\n" +
+	"\rallow_overlap\x18\a \x01(\bR\fallowOverlap\x12\"\n" +
+	"\rcreated_at_ms\x18\b \x01(\x03R\vcreatedAtMs\x12\x1e\n" +
+	"\vnext_run_ms\x18\t \x01(\x03R\tnextRunMs\x12\x1e\n" +
+	"\vlast_run_ms\x18\n" +
+	" \x01(\x03R\tlastRunMs\x12\x1e\n" +
+	"\vlast_job_id\x18\v \x01(\tR\tlastJobId\x12)\n" +
+	"\x04runs\x18\f \x03(\v2\x15.edgemesh.ScheduleRunR\x04runs\"5\n" +
+	"\x14ListSchedulesRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\"M\n" +
+	"\x15ListSchedulesResponse\x124\n" +
+	"\tschedules\x18\x01 \x03(\v2\x16.edgemesh.ScheduleInfoR\tschedules\"W\n" +
+	"\x15DeleteScheduleRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1f\n" +
+	"\vschedule_id\x18\x02 \x01(\tR\n" +
+	"scheduleId*[\n" +
 	"\bReadMode\x12\x12\n" +
 	"\x0eREAD_MODE_FULL\x10\x00\x12\x12\n" +
 	"\x0eREAD_MODE_HEAD\x10\x01\x12\x12\n" +
 	"\x0eREAD_MODE_TAIL\x10\x02\x12\x13\n" +
-	"\x0fREAD_MODE_RANGE\x10\x032\x8a\r\n" +
+	"\x0fREAD_MODE_RANGE\x10\x032\xe1\x16\n" +
 	"\x13OrchestratorService\x12=\n" +
 	"\rCreateSession\x12\x15.edgemesh.AuthRequest\x1a\x15.edgemesh.SessionInfo\x123\n" +
 	"\tHeartbeat\x12\x15.edgemesh.SessionInfo\x1a\x0f.edgemesh.Empty\x12E\n" +
@@ -4277,7 +8944,8 @@ const file_orchestrator_proto_rawDesc = "" +
 	"\vListDevices\x12\x1c.edgemesh.ListDevicesRequest\x1a\x1d.edgemesh.ListDevicesResponse\x12=\n" +
 	"\x0fGetDeviceStatus\x12\x12.edgemesh.DeviceId\x1a\x16.edgemesh.DeviceStatus\x12>\n" +
 	"\tRunAITask\x12\x17.edgemesh.AITaskRequest\x1a\x18.edgemesh.AITaskResponse\x126\n" +
-	"\vHealthCheck\x12\x0f.edgemesh.Empty\x1a\x16.edgemesh.HealthStatus\x12W\n" +
+	"\vHealthCheck\x12\x0f.edgemesh.Empty\x1a\x16.edgemesh.HealthStatus\x125\n" +
+	"\x04Ping\x12\x15.edgemesh.PingRequest\x1a\x16.edgemesh.PingResponse\x12W\n" +
 	"\x14ExecuteRoutedCommand\x12\x1e.edgemesh.RoutedCommandRequest\x1a\x1f.edgemesh.RoutedCommandResponse\x124\n" +
 	"\tSubmitJob\x12\x14.edgemesh.JobRequest\x1a\x11.edgemesh.JobInfo\x12.\n" +
 	"\x06GetJob\x12\x0f.edgemesh.JobId\x1a\x13.edgemesh.JobStatus\x126\n" +
@@ -4287,16 +8955,33 @@ const file_orchestrator_proto_rawDesc = "" +
 	"\vStartWebRTC\x12\x16.edgemesh.WebRTCConfig\x1a\x15.edgemesh.WebRTCOffer\x129\n" +
 	"\x0eCompleteWebRTC\x12\x16.edgemesh.WebRTCAnswer\x1a\x0f.edgemesh.Empty\x123\n" +
 	"\n" +
-	"StopWebRTC\x12\x14.edgemesh.WebRTCStop\x1a\x0f.edgemesh.Empty\x12Y\n" +
-	"\x14CreateDownloadTicket\x12\x1f.edgemesh.DownloadTicketRequest\x1a .edgemesh.DownloadTicketResponse\x12A\n" +
-	"\bReadFile\x12\x19.edgemesh.ReadFileRequest\x1a\x1a.edgemesh.ReadFileResponse\x12L\n" +
+	"StopWebRTC\x12\x14.edgemesh.WebRTCStop\x1a\x0f.edgemesh.Empty\x12A\n" +
+	"\x0fAddIceCandidate\x12\x1d.edgemesh.IceCandidateRequest\x1a\x0f.edgemesh.Empty\x12S\n" +
+	"\x10GetIceCandidates\x12\x1e.edgemesh.IceCandidatesRequest\x1a\x1f.edgemesh.IceCandidatesResponse\x12J\n" +
+	"\vListStreams\x12\x1c.edgemesh.ListStreamsRequest\x1a\x1d.edgemesh.ListStreamsResponse\x12Y\n" +
+	"\x14CreateDownloadTicket\x12\x1f.edgemesh.DownloadTicketRequest\x1a .edgemesh.DownloadTicketResponse\x12S\n" +
+	"\x12CreateUploadTicket\x12\x1d.edgemesh.UploadTicketRequest\x1a\x1e.edgemesh.UploadTicketResponse\x12>\n" +
+	"\aPutFile\x12\x18.edgemesh.PutFileRequest\x1a\x19.edgemesh.PutFileResponse\x12A\n" +
+	"\bReadFile\x12\x19.edgemesh.ReadFileRequest\x1a\x1a.edgemesh.ReadFileResponse\x12>\n" +
+	"\aListDir\x12\x18.edgemesh.ListDirRequest\x1a\x19.edgemesh.ListDirResponse\x12A\n" +
+	"\bStatFile\x12\x19.edgemesh.StatFileRequest\x1a\x1a.edgemesh.StatFileResponse\x12P\n" +
+	"\x0fGetSyncManifest\x12\x1d.edgemesh.SyncManifestRequest\x1a\x1e.edgemesh.SyncManifestResponse\x12G\n" +
+	"\n" +
+	"SyncStatus\x12\x1b.edgemesh.SyncStatusRequest\x1a\x1c.edgemesh.SyncStatusResponse\x12V\n" +
+	"\x0fLocateArtifacts\x12 .edgemesh.LocateArtifactsRequest\x1a!.edgemesh.LocateArtifactsResponse\x12D\n" +
+	"\tStageFile\x12\x1a.edgemesh.StageFileRequest\x1a\x1b.edgemesh.StageFileResponse\x12L\n" +
 	"\x0eSyncChatMemory\x12\x18.edgemesh.ChatMemorySync\x1a .edgemesh.ChatMemorySyncResponse\x12:\n" +
 	"\rGetChatMemory\x12\x0f.edgemesh.Empty\x1a\x18.edgemesh.ChatMemoryData\x12A\n" +
 	"\n" +
-	"RunLLMTask\x12\x18.edgemesh.LLMTaskRequest\x1a\x19.edgemesh.LLMTaskResponse\x12J\n" +
-	"\vGetActivity\x12\x1c.edgemesh.GetActivityRequest\x1a\x1d.edgemesh.GetActivityResponse\x12H\n" +
-	"\x10GetDeviceMetrics\x12\x12.edgemesh.DeviceId\x1a .edgemesh.MetricsHistoryResponse\x12<\n" +
-	"\fGetJobDetail\x12\x0f.edgemesh.JobId\x1a\x1b.edgemesh.JobDetailResponseB\"Z github.com/edgecli/edgecli/protob\x06proto3"
+	"RunLLMTask\x12\x18.edgemesh.LLMTaskRequest\x1a\x19.edgemesh.LLMTaskResponse\x12D\n" +
+	"\tBenchmark\x12\x1a.edgemesh.BenchmarkRequest\x1a\x1b.edgemesh.BenchmarkResponse\x12J\n" +
+	"\vGetActivity\x12\x1c.edgemesh.GetActivityRequest\x1a\x1d.edgemesh.GetActivityResponse\x12L\n" +
+	"\x10GetDeviceMetrics\x12\x16.edgemesh.MetricsQuery\x1a .edgemesh.MetricsHistoryResponse\x12<\n" +
+	"\fGetJobDetail\x12\x0f.edgemesh.JobId\x1a\x1b.edgemesh.JobDetailResponse\x12>\n" +
+	"\vWatchAlerts\x12\x1c.edgemesh.WatchAlertsRequest\x1a\x0f.edgemesh.Alert0\x01\x12I\n" +
+	"\x0eCreateSchedule\x12\x1f.edgemesh.CreateScheduleRequest\x1a\x16.edgemesh.ScheduleInfo\x12P\n" +
+	"\rListSchedules\x12\x1e.edgemesh.ListSchedulesRequest\x1a\x1f.edgemesh.ListSchedulesResponse\x12B\n" +
+	"\x0eDeleteSchedule\x12\x1f.edgemesh.DeleteScheduleRequest\x1a\x0f.edgemesh.EmptyB\"Z github.com/edgecli/edgecli/protob\x06proto3"
 
 var (
 	file_orchestrator_proto_rawDescOnce sync.Once
@@ -4311,147 +8996,266 @@ func file_orchestrator_proto_rawDescGZIP() []byte {
 }
 
 var file_orchestrator_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_orchestrator_proto_msgTypes = make([]protoimpl.MessageInfo, 57)
+var file_orchestrator_proto_msgTypes = make([]protoimpl.MessageInfo, 108)
 var file_orchestrator_proto_goTypes = []any{
-	(ReadMode)(0),                  // 0: edgemesh.ReadMode
-	(RoutingPolicy_Mode)(0),        // 1: edgemesh.RoutingPolicy.Mode
-	(*Empty)(nil),                  // 2: edgemesh.Empty
-	(*AuthRequest)(nil),            // 3: edgemesh.AuthRequest
-	(*SessionInfo)(nil),            // 4: edgemesh.SessionInfo
-	(*CommandRequest)(nil),         // 5: edgemesh.CommandRequest
-	(*CommandResponse)(nil),        // 6: edgemesh.CommandResponse
-	(*DeviceId)(nil),               // 7: edgemesh.DeviceId
-	(*DeviceInfo)(nil),             // 8: edgemesh.DeviceInfo
-	(*DeviceAck)(nil),              // 9: edgemesh.DeviceAck
-	(*DeviceStatus)(nil),           // 10: edgemesh.DeviceStatus
-	(*ListDevicesRequest)(nil),     // 11: edgemesh.ListDevicesRequest
-	(*ListDevicesResponse)(nil),    // 12: edgemesh.ListDevicesResponse
-	(*AITaskRequest)(nil),          // 13: edgemesh.AITaskRequest
-	(*AITaskResponse)(nil),         // 14: edgemesh.AITaskResponse
-	(*HealthStatus)(nil),           // 15: edgemesh.HealthStatus
-	(*RoutingPolicy)(nil),          // 16: edgemesh.RoutingPolicy
-	(*RoutedCommandRequest)(nil),   // 17: edgemesh.RoutedCommandRequest
-	(*RoutedCommandResponse)(nil),  // 18: edgemesh.RoutedCommandResponse
-	(*JobId)(nil),                  // 19: edgemesh.JobId
-	(*JobRequest)(nil),             // 20: edgemesh.JobRequest
-	(*Plan)(nil),                   // 21: edgemesh.Plan
-	(*TaskGroup)(nil),              // 22: edgemesh.TaskGroup
-	(*TaskSpec)(nil),               // 23: edgemesh.TaskSpec
-	(*ReduceSpec)(nil),             // 24: edgemesh.ReduceSpec
-	(*JobInfo)(nil),                // 25: edgemesh.JobInfo
-	(*JobStatus)(nil),              // 26: edgemesh.JobStatus
-	(*TaskStatus)(nil),             // 27: edgemesh.TaskStatus
-	(*TaskRequest)(nil),            // 28: edgemesh.TaskRequest
-	(*TaskResult)(nil),             // 29: edgemesh.TaskResult
-	(*WebRTCConfig)(nil),           // 30: edgemesh.WebRTCConfig
-	(*WebRTCOffer)(nil),            // 31: edgemesh.WebRTCOffer
-	(*WebRTCAnswer)(nil),           // 32: edgemesh.WebRTCAnswer
-	(*WebRTCStop)(nil),             // 33: edgemesh.WebRTCStop
-	(*PlanPreviewRequest)(nil),     // 34: edgemesh.PlanPreviewRequest
-	(*PlanPreviewResponse)(nil),    // 35: edgemesh.PlanPreviewResponse
-	(*PlanCostRequest)(nil),        // 36: edgemesh.PlanCostRequest
-	(*PlanCostResponse)(nil),       // 37: edgemesh.PlanCostResponse
-	(*DeviceCostEstimate)(nil),     // 38: edgemesh.DeviceCostEstimate
-	(*StepCostEstimate)(nil),       // 39: edgemesh.StepCostEstimate
-	(*DownloadTicketRequest)(nil),  // 40: edgemesh.DownloadTicketRequest
-	(*DownloadTicketResponse)(nil), // 41: edgemesh.DownloadTicketResponse
-	(*ReadFileRequest)(nil),        // 42: edgemesh.ReadFileRequest
-	(*ReadFileResponse)(nil),       // 43: edgemesh.ReadFileResponse
-	(*ChatMemorySync)(nil),         // 44: edgemesh.ChatMemorySync
-	(*ChatMemorySyncResponse)(nil), // 45: edgemesh.ChatMemorySyncResponse
-	(*ChatMemoryData)(nil),         // 46: edgemesh.ChatMemoryData
-	(*LLMTaskRequest)(nil),         // 47: edgemesh.LLMTaskRequest
-	(*LLMTaskResponse)(nil),        // 48: edgemesh.LLMTaskResponse
-	(*MetricsSample)(nil),          // 49: edgemesh.MetricsSample
-	(*RunningTask)(nil),            // 50: edgemesh.RunningTask
-	(*DeviceActivity)(nil),         // 51: edgemesh.DeviceActivity
-	(*ActivityData)(nil),           // 52: edgemesh.ActivityData
-	(*GetActivityRequest)(nil),     // 53: edgemesh.GetActivityRequest
-	(*MetricsHistoryResponse)(nil), // 54: edgemesh.MetricsHistoryResponse
-	(*GetActivityResponse)(nil),    // 55: edgemesh.GetActivityResponse
-	(*TaskStatusEnhanced)(nil),     // 56: edgemesh.TaskStatusEnhanced
-	(*JobDetailResponse)(nil),      // 57: edgemesh.JobDetailResponse
-	nil,                            // 58: edgemesh.GetActivityResponse.DeviceMetricsEntry
+	(ReadMode)(0),                   // 0: edgemesh.ReadMode
+	(RoutingPolicy_Mode)(0),         // 1: edgemesh.RoutingPolicy.Mode
+	(*Empty)(nil),                   // 2: edgemesh.Empty
+	(*AuthRequest)(nil),             // 3: edgemesh.AuthRequest
+	(*SessionInfo)(nil),             // 4: edgemesh.SessionInfo
+	(*CommandRequest)(nil),          // 5: edgemesh.CommandRequest
+	(*CommandResponse)(nil),         // 6: edgemesh.CommandResponse
+	(*DeviceId)(nil),                // 7: edgemesh.DeviceId
+	(*DeviceInfo)(nil),              // 8: edgemesh.DeviceInfo
+	(*PowerState)(nil),              // 9: edgemesh.PowerState
+	(*DeviceAck)(nil),               // 10: edgemesh.DeviceAck
+	(*DeviceStatus)(nil),            // 11: edgemesh.DeviceStatus
+	(*ListDevicesRequest)(nil),      // 12: edgemesh.ListDevicesRequest
+	(*ListDevicesResponse)(nil),     // 13: edgemesh.ListDevicesResponse
+	(*AITaskRequest)(nil),           // 14: edgemesh.AITaskRequest
+	(*AITaskResponse)(nil),          // 15: edgemesh.AITaskResponse
+	(*HealthStatus)(nil),            // 16: edgemesh.HealthStatus
+	(*PingRequest)(nil),             // 17: edgemesh.PingRequest
+	(*PingResponse)(nil),            // 18: edgemesh.PingResponse
+	(*LinkStats)(nil),               // 19: edgemesh.LinkStats
+	(*RoutingPolicy)(nil),           // 20: edgemesh.RoutingPolicy
+	(*RoutedCommandRequest)(nil),    // 21: edgemesh.RoutedCommandRequest
+	(*RoutedCommandResponse)(nil),   // 22: edgemesh.RoutedCommandResponse
+	(*JobId)(nil),                   // 23: edgemesh.JobId
+	(*JobRequest)(nil),              // 24: edgemesh.JobRequest
+	(*FanOut)(nil),                  // 25: edgemesh.FanOut
+	(*MapSpec)(nil),                 // 26: edgemesh.MapSpec
+	(*Plan)(nil),                    // 27: edgemesh.Plan
+	(*TaskGroup)(nil),               // 28: edgemesh.TaskGroup
+	(*TaskSpec)(nil),                // 29: edgemesh.TaskSpec
+	(*InputArtifact)(nil),           // 30: edgemesh.InputArtifact
+	(*ReduceSpec)(nil),              // 31: edgemesh.ReduceSpec
+	(*JobInfo)(nil),                 // 32: edgemesh.JobInfo
+	(*JobStatus)(nil),               // 33: edgemesh.JobStatus
+	(*TaskStatus)(nil),              // 34: edgemesh.TaskStatus
+	(*TaskRequest)(nil),             // 35: edgemesh.TaskRequest
+	(*TaskResult)(nil),              // 36: edgemesh.TaskResult
+	(*ShellResult)(nil),             // 37: edgemesh.ShellResult
+	(*WebRTCConfig)(nil),            // 38: edgemesh.WebRTCConfig
+	(*WebRTCOffer)(nil),             // 39: edgemesh.WebRTCOffer
+	(*WebRTCAnswer)(nil),            // 40: edgemesh.WebRTCAnswer
+	(*WebRTCStop)(nil),              // 41: edgemesh.WebRTCStop
+	(*IceServer)(nil),               // 42: edgemesh.IceServer
+	(*IceCandidate)(nil),            // 43: edgemesh.IceCandidate
+	(*IceCandidateRequest)(nil),     // 44: edgemesh.IceCandidateRequest
+	(*IceCandidatesRequest)(nil),    // 45: edgemesh.IceCandidatesRequest
+	(*IceCandidatesResponse)(nil),   // 46: edgemesh.IceCandidatesResponse
+	(*ListStreamsRequest)(nil),      // 47: edgemesh.ListStreamsRequest
+	(*StreamSession)(nil),           // 48: edgemesh.StreamSession
+	(*CaptureFeed)(nil),             // 49: edgemesh.CaptureFeed
+	(*ListStreamsResponse)(nil),     // 50: edgemesh.ListStreamsResponse
+	(*PlanPreviewRequest)(nil),      // 51: edgemesh.PlanPreviewRequest
+	(*PlanPreviewResponse)(nil),     // 52: edgemesh.PlanPreviewResponse
+	(*PlanCostRequest)(nil),         // 53: edgemesh.PlanCostRequest
+	(*PlanCostResponse)(nil),        // 54: edgemesh.PlanCostResponse
+	(*DeviceCostEstimate)(nil),      // 55: edgemesh.DeviceCostEstimate
+	(*StepCostEstimate)(nil),        // 56: edgemesh.StepCostEstimate
+	(*DownloadTicketRequest)(nil),   // 57: edgemesh.DownloadTicketRequest
+	(*DownloadTicketResponse)(nil),  // 58: edgemesh.DownloadTicketResponse
+	(*UploadTicketRequest)(nil),     // 59: edgemesh.UploadTicketRequest
+	(*UploadTicketResponse)(nil),    // 60: edgemesh.UploadTicketResponse
+	(*PutFileRequest)(nil),          // 61: edgemesh.PutFileRequest
+	(*PutFileResponse)(nil),         // 62: edgemesh.PutFileResponse
+	(*ReadFileRequest)(nil),         // 63: edgemesh.ReadFileRequest
+	(*ReadFileResponse)(nil),        // 64: edgemesh.ReadFileResponse
+	(*FileEntry)(nil),               // 65: edgemesh.FileEntry
+	(*ListDirRequest)(nil),          // 66: edgemesh.ListDirRequest
+	(*ListDirResponse)(nil),         // 67: edgemesh.ListDirResponse
+	(*StatFileRequest)(nil),         // 68: edgemesh.StatFileRequest
+	(*StatFileResponse)(nil),        // 69: edgemesh.StatFileResponse
+	(*SyncFile)(nil),                // 70: edgemesh.SyncFile
+	(*SyncManifestRequest)(nil),     // 71: edgemesh.SyncManifestRequest
+	(*SyncManifestResponse)(nil),    // 72: edgemesh.SyncManifestResponse
+	(*SyncStatusRequest)(nil),       // 73: edgemesh.SyncStatusRequest
+	(*SyncPeerStatus)(nil),          // 74: edgemesh.SyncPeerStatus
+	(*SyncStatusResponse)(nil),      // 75: edgemesh.SyncStatusResponse
+	(*LocateArtifactsRequest)(nil),  // 76: edgemesh.LocateArtifactsRequest
+	(*ArtifactLocation)(nil),        // 77: edgemesh.ArtifactLocation
+	(*LocateArtifactsResponse)(nil), // 78: edgemesh.LocateArtifactsResponse
+	(*StageFileRequest)(nil),        // 79: edgemesh.StageFileRequest
+	(*StageFileResponse)(nil),       // 80: edgemesh.StageFileResponse
+	(*ChatMemorySync)(nil),          // 81: edgemesh.ChatMemorySync
+	(*ChatMemorySyncResponse)(nil),  // 82: edgemesh.ChatMemorySyncResponse
+	(*ChatMemoryData)(nil),          // 83: edgemesh.ChatMemoryData
+	(*LLMTaskRequest)(nil),          // 84: edgemesh.LLMTaskRequest
+	(*LLMTaskResponse)(nil),         // 85: edgemesh.LLMTaskResponse
+	(*BenchmarkRequest)(nil),        // 86: edgemesh.BenchmarkRequest
+	(*LLMBenchmark)(nil),            // 87: edgemesh.LLMBenchmark
+	(*BenchmarkResponse)(nil),       // 88: edgemesh.BenchmarkResponse
+	(*MetricsSample)(nil),           // 89: edgemesh.MetricsSample
+	(*RunningTask)(nil),             // 90: edgemesh.RunningTask
+	(*DeviceActivity)(nil),          // 91: edgemesh.DeviceActivity
+	(*ActivityData)(nil),            // 92: edgemesh.ActivityData
+	(*GetActivityRequest)(nil),      // 93: edgemesh.GetActivityRequest
+	(*MetricsQuery)(nil),            // 94: edgemesh.MetricsQuery
+	(*MetricStats)(nil),             // 95: edgemesh.MetricStats
+	(*MetricsPoint)(nil),            // 96: edgemesh.MetricsPoint
+	(*MetricsHistoryResponse)(nil),  // 97: edgemesh.MetricsHistoryResponse
+	(*GetActivityResponse)(nil),     // 98: edgemesh.GetActivityResponse
+	(*TaskStatusEnhanced)(nil),      // 99: edgemesh.TaskStatusEnhanced
+	(*JobDetailResponse)(nil),       // 100: edgemesh.JobDetailResponse
+	(*WatchAlertsRequest)(nil),      // 101: edgemesh.WatchAlertsRequest
+	(*Alert)(nil),                   // 102: edgemesh.Alert
+	(*CreateScheduleRequest)(nil),   // 103: edgemesh.CreateScheduleRequest
+	(*ScheduleRun)(nil),             // 104: edgemesh.ScheduleRun
+	(*ScheduleInfo)(nil),            // 105: edgemesh.ScheduleInfo
+	(*ListSchedulesRequest)(nil),    // 106: edgemesh.ListSchedulesRequest
+	(*ListSchedulesResponse)(nil),   // 107: edgemesh.ListSchedulesResponse
+	(*DeleteScheduleRequest)(nil),   // 108: edgemesh.DeleteScheduleRequest
+	nil,                             // 109: edgemesh.GetActivityResponse.DeviceMetricsEntry
 }
 var file_orchestrator_proto_depIdxs = []int32{
-	8,  // 0: edgemesh.ListDevicesResponse.devices:type_name -> edgemesh.DeviceInfo
-	1,  // 1: edgemesh.RoutingPolicy.mode:type_name -> edgemesh.RoutingPolicy.Mode
-	16, // 2: edgemesh.RoutedCommandRequest.policy:type_name -> edgemesh.RoutingPolicy
-	6,  // 3: edgemesh.RoutedCommandResponse.output:type_name -> edgemesh.CommandResponse
-	21, // 4: edgemesh.JobRequest.plan:type_name -> edgemesh.Plan
-	24, // 5: edgemesh.JobRequest.reduce:type_name -> edgemesh.ReduceSpec
-	22, // 6: edgemesh.Plan.groups:type_name -> edgemesh.TaskGroup
-	23, // 7: edgemesh.TaskGroup.tasks:type_name -> edgemesh.TaskSpec
-	27, // 8: edgemesh.JobStatus.tasks:type_name -> edgemesh.TaskStatus
-	21, // 9: edgemesh.PlanPreviewResponse.plan:type_name -> edgemesh.Plan
-	24, // 10: edgemesh.PlanPreviewResponse.reduce:type_name -> edgemesh.ReduceSpec
-	21, // 11: edgemesh.PlanCostRequest.plan:type_name -> edgemesh.Plan
-	38, // 12: edgemesh.PlanCostResponse.device_costs:type_name -> edgemesh.DeviceCostEstimate
-	39, // 13: edgemesh.DeviceCostEstimate.step_costs:type_name -> edgemesh.StepCostEstimate
-	0,  // 14: edgemesh.ReadFileRequest.mode:type_name -> edgemesh.ReadMode
-	10, // 15: edgemesh.DeviceActivity.current_status:type_name -> edgemesh.DeviceStatus
-	50, // 16: edgemesh.ActivityData.running_tasks:type_name -> edgemesh.RunningTask
-	51, // 17: edgemesh.ActivityData.device_activities:type_name -> edgemesh.DeviceActivity
-	49, // 18: edgemesh.MetricsHistoryResponse.samples:type_name -> edgemesh.MetricsSample
-	52, // 19: edgemesh.GetActivityResponse.activity:type_name -> edgemesh.ActivityData
-	58, // 20: edgemesh.GetActivityResponse.device_metrics:type_name -> edgemesh.GetActivityResponse.DeviceMetricsEntry
-	56, // 21: edgemesh.JobDetailResponse.tasks:type_name -> edgemesh.TaskStatusEnhanced
-	54, // 22: edgemesh.GetActivityResponse.DeviceMetricsEntry.value:type_name -> edgemesh.MetricsHistoryResponse
-	3,  // 23: edgemesh.OrchestratorService.CreateSession:input_type -> edgemesh.AuthRequest
-	4,  // 24: edgemesh.OrchestratorService.Heartbeat:input_type -> edgemesh.SessionInfo
-	5,  // 25: edgemesh.OrchestratorService.ExecuteCommand:input_type -> edgemesh.CommandRequest
-	8,  // 26: edgemesh.OrchestratorService.RegisterDevice:input_type -> edgemesh.DeviceInfo
-	11, // 27: edgemesh.OrchestratorService.ListDevices:input_type -> edgemesh.ListDevicesRequest
-	7,  // 28: edgemesh.OrchestratorService.GetDeviceStatus:input_type -> edgemesh.DeviceId
-	13, // 29: edgemesh.OrchestratorService.RunAITask:input_type -> edgemesh.AITaskRequest
-	2,  // 30: edgemesh.OrchestratorService.HealthCheck:input_type -> edgemesh.Empty
-	17, // 31: edgemesh.OrchestratorService.ExecuteRoutedCommand:input_type -> edgemesh.RoutedCommandRequest
-	20, // 32: edgemesh.OrchestratorService.SubmitJob:input_type -> edgemesh.JobRequest
-	19, // 33: edgemesh.OrchestratorService.GetJob:input_type -> edgemesh.JobId
-	28, // 34: edgemesh.OrchestratorService.RunTask:input_type -> edgemesh.TaskRequest
-	34, // 35: edgemesh.OrchestratorService.PreviewPlan:input_type -> edgemesh.PlanPreviewRequest
-	36, // 36: edgemesh.OrchestratorService.PreviewPlanCost:input_type -> edgemesh.PlanCostRequest
-	30, // 37: edgemesh.OrchestratorService.StartWebRTC:input_type -> edgemesh.WebRTCConfig
-	32, // 38: edgemesh.OrchestratorService.CompleteWebRTC:input_type -> edgemesh.WebRTCAnswer
-	33, // 39: edgemesh.OrchestratorService.StopWebRTC:input_type -> edgemesh.WebRTCStop
-	40, // 40: edgemesh.OrchestratorService.CreateDownloadTicket:input_type -> edgemesh.DownloadTicketRequest
-	42, // 41: edgemesh.OrchestratorService.ReadFile:input_type -> edgemesh.ReadFileRequest
-	44, // 42: edgemesh.OrchestratorService.SyncChatMemory:input_type -> edgemesh.ChatMemorySync
-	2,  // 43: edgemesh.OrchestratorService.GetChatMemory:input_type -> edgemesh.Empty
-	47, // 44: edgemesh.OrchestratorService.RunLLMTask:input_type -> edgemesh.LLMTaskRequest
-	53, // 45: edgemesh.OrchestratorService.GetActivity:input_type -> edgemesh.GetActivityRequest
-	7,  // 46: edgemesh.OrchestratorService.GetDeviceMetrics:input_type -> edgemesh.DeviceId
-	19, // 47: edgemesh.OrchestratorService.GetJobDetail:input_type -> edgemesh.JobId
-	4,  // 48: edgemesh.OrchestratorService.CreateSession:output_type -> edgemesh.SessionInfo
-	2,  // 49: edgemesh.OrchestratorService.Heartbeat:output_type -> edgemesh.Empty
-	6,  // 50: edgemesh.OrchestratorService.ExecuteCommand:output_type -> edgemesh.CommandResponse
-	9,  // 51: edgemesh.OrchestratorService.RegisterDevice:output_type -> edgemesh.DeviceAck
-	12, // 52: edgemesh.OrchestratorService.ListDevices:output_type -> edgemesh.ListDevicesResponse
-	10, // 53: edgemesh.OrchestratorService.GetDeviceStatus:output_type -> edgemesh.DeviceStatus
-	14, // 54: edgemesh.OrchestratorService.RunAITask:output_type -> edgemesh.AITaskResponse
-	15, // 55: edgemesh.OrchestratorService.HealthCheck:output_type -> edgemesh.HealthStatus
-	18, // 56: edgemesh.OrchestratorService.ExecuteRoutedCommand:output_type -> edgemesh.RoutedCommandResponse
-	25, // 57: edgemesh.OrchestratorService.SubmitJob:output_type -> edgemesh.JobInfo
-	26, // 58: edgemesh.OrchestratorService.GetJob:output_type -> edgemesh.JobStatus
-	29, // 59: edgemesh.OrchestratorService.RunTask:output_type -> edgemesh.TaskResult
-	35, // 60: edgemesh.OrchestratorService.PreviewPlan:output_type -> edgemesh.PlanPreviewResponse
-	37, // 61: edgemesh.OrchestratorService.PreviewPlanCost:output_type -> edgemesh.PlanCostResponse
-	31, // 62: edgemesh.OrchestratorService.StartWebRTC:output_type -> edgemesh.WebRTCOffer
-	2,  // 63: edgemesh.OrchestratorService.CompleteWebRTC:output_type -> edgemesh.Empty
-	2,  // 64: edgemesh.OrchestratorService.StopWebRTC:output_type -> edgemesh.Empty
-	41, // 65: edgemesh.OrchestratorService.CreateDownloadTicket:output_type -> edgemesh.DownloadTicketResponse
-	43, // 66: edgemesh.OrchestratorService.ReadFile:output_type -> edgemesh.ReadFileResponse
-	45, // 67: edgemesh.OrchestratorService.SyncChatMemory:output_type -> edgemesh.ChatMemorySyncResponse
-	46, // 68: edgemesh.OrchestratorService.GetChatMemory:output_type -> edgemesh.ChatMemoryData
-	48, // 69: edgemesh.OrchestratorService.RunLLMTask:output_type -> edgemesh.LLMTaskResponse
-	55, // 70: edgemesh.OrchestratorService.GetActivity:output_type -> edgemesh.GetActivityResponse
-	54, // 71: edgemesh.OrchestratorService.GetDeviceMetrics:output_type -> edgemesh.MetricsHistoryResponse
-	57, // 72: edgemesh.OrchestratorService.GetJobDetail:output_type -> edgemesh.JobDetailResponse
-	48, // [48:73] is the sub-list for method output_type
-	23, // [23:48] is the sub-list for method input_type
-	23, // [23:23] is the sub-list for extension type_name
-	23, // [23:23] is the sub-list for extension extendee
-	0,  // [0:23] is the sub-list for field type_name
+	9,   // 0: edgemesh.DeviceInfo.power:type_name -> edgemesh.PowerState
+	9,   // 1: edgemesh.DeviceStatus.power:type_name -> edgemesh.PowerState
+	19,  // 2: edgemesh.DeviceStatus.links:type_name -> edgemesh.LinkStats
+	8,   // 3: edgemesh.ListDevicesResponse.devices:type_name -> edgemesh.DeviceInfo
+	1,   // 4: edgemesh.RoutingPolicy.mode:type_name -> edgemesh.RoutingPolicy.Mode
+	20,  // 5: edgemesh.RoutedCommandRequest.policy:type_name -> edgemesh.RoutingPolicy
+	6,   // 6: edgemesh.RoutedCommandResponse.output:type_name -> edgemesh.CommandResponse
+	27,  // 7: edgemesh.JobRequest.plan:type_name -> edgemesh.Plan
+	31,  // 8: edgemesh.JobRequest.reduce:type_name -> edgemesh.ReduceSpec
+	25,  // 9: edgemesh.JobRequest.fan_out:type_name -> edgemesh.FanOut
+	26,  // 10: edgemesh.JobRequest.map:type_name -> edgemesh.MapSpec
+	28,  // 11: edgemesh.Plan.groups:type_name -> edgemesh.TaskGroup
+	29,  // 12: edgemesh.TaskGroup.tasks:type_name -> edgemesh.TaskSpec
+	30,  // 13: edgemesh.TaskSpec.inputs:type_name -> edgemesh.InputArtifact
+	34,  // 14: edgemesh.JobStatus.tasks:type_name -> edgemesh.TaskStatus
+	37,  // 15: edgemesh.TaskStatus.shell:type_name -> edgemesh.ShellResult
+	37,  // 16: edgemesh.TaskResult.shell:type_name -> edgemesh.ShellResult
+	42,  // 17: edgemesh.WebRTCConfig.ice_servers:type_name -> edgemesh.IceServer
+	42,  // 18: edgemesh.WebRTCOffer.ice_servers:type_name -> edgemesh.IceServer
+	43,  // 19: edgemesh.IceCandidateRequest.candidate:type_name -> edgemesh.IceCandidate
+	43,  // 20: edgemesh.IceCandidatesResponse.candidates:type_name -> edgemesh.IceCandidate
+	48,  // 21: edgemesh.ListStreamsResponse.streams:type_name -> edgemesh.StreamSession
+	49,  // 22: edgemesh.ListStreamsResponse.feeds:type_name -> edgemesh.CaptureFeed
+	27,  // 23: edgemesh.PlanPreviewResponse.plan:type_name -> edgemesh.Plan
+	31,  // 24: edgemesh.PlanPreviewResponse.reduce:type_name -> edgemesh.ReduceSpec
+	27,  // 25: edgemesh.PlanCostRequest.plan:type_name -> edgemesh.Plan
+	55,  // 26: edgemesh.PlanCostResponse.device_costs:type_name -> edgemesh.DeviceCostEstimate
+	56,  // 27: edgemesh.DeviceCostEstimate.step_costs:type_name -> edgemesh.StepCostEstimate
+	0,   // 28: edgemesh.ReadFileRequest.mode:type_name -> edgemesh.ReadMode
+	65,  // 29: edgemesh.ListDirResponse.entries:type_name -> edgemesh.FileEntry
+	65,  // 30: edgemesh.StatFileResponse.entry:type_name -> edgemesh.FileEntry
+	70,  // 31: edgemesh.SyncManifestResponse.files:type_name -> edgemesh.SyncFile
+	74,  // 32: edgemesh.SyncStatusResponse.peers:type_name -> edgemesh.SyncPeerStatus
+	77,  // 33: edgemesh.LocateArtifactsResponse.found:type_name -> edgemesh.ArtifactLocation
+	87,  // 34: edgemesh.BenchmarkResponse.results:type_name -> edgemesh.LLMBenchmark
+	11,  // 35: edgemesh.DeviceActivity.current_status:type_name -> edgemesh.DeviceStatus
+	90,  // 36: edgemesh.ActivityData.running_tasks:type_name -> edgemesh.RunningTask
+	91,  // 37: edgemesh.ActivityData.device_activities:type_name -> edgemesh.DeviceActivity
+	19,  // 38: edgemesh.ActivityData.links:type_name -> edgemesh.LinkStats
+	95,  // 39: edgemesh.MetricsPoint.cpu_load:type_name -> edgemesh.MetricStats
+	95,  // 40: edgemesh.MetricsPoint.mem_used_mb:type_name -> edgemesh.MetricStats
+	95,  // 41: edgemesh.MetricsPoint.mem_total_mb:type_name -> edgemesh.MetricStats
+	95,  // 42: edgemesh.MetricsPoint.gpu_load:type_name -> edgemesh.MetricStats
+	95,  // 43: edgemesh.MetricsPoint.gpu_mem_used_mb:type_name -> edgemesh.MetricStats
+	95,  // 44: edgemesh.MetricsPoint.gpu_mem_total_mb:type_name -> edgemesh.MetricStats
+	95,  // 45: edgemesh.MetricsPoint.npu_load:type_name -> edgemesh.MetricStats
+	89,  // 46: edgemesh.MetricsHistoryResponse.samples:type_name -> edgemesh.MetricsSample
+	96,  // 47: edgemesh.MetricsHistoryResponse.points:type_name -> edgemesh.MetricsPoint
+	92,  // 48: edgemesh.GetActivityResponse.activity:type_name -> edgemesh.ActivityData
+	109, // 49: edgemesh.GetActivityResponse.device_metrics:type_name -> edgemesh.GetActivityResponse.DeviceMetricsEntry
+	37,  // 50: edgemesh.TaskStatusEnhanced.shell:type_name -> edgemesh.ShellResult
+	99,  // 51: edgemesh.JobDetailResponse.tasks:type_name -> edgemesh.TaskStatusEnhanced
+	24,  // 52: edgemesh.CreateScheduleRequest.job:type_name -> edgemesh.JobRequest
+	24,  // 53: edgemesh.ScheduleInfo.job:type_name -> edgemesh.JobRequest
+	104, // 54: edgemesh.ScheduleInfo.runs:type_name -> edgemesh.ScheduleRun
+	105, // 55: edgemesh.ListSchedulesResponse.schedules:type_name -> edgemesh.ScheduleInfo
+	97,  // 56: edgemesh.GetActivityResponse.DeviceMetricsEntry.value:type_name -> edgemesh.MetricsHistoryResponse
+	3,   // 57: edgemesh.OrchestratorService.CreateSession:input_type -> edgemesh.AuthRequest
+	4,   // 58: edgemesh.OrchestratorService.Heartbeat:input_type -> edgemesh.SessionInfo
+	5,   // 59: edgemesh.OrchestratorService.ExecuteCommand:input_type -> edgemesh.CommandRequest
+	8,   // 60: edgemesh.OrchestratorService.RegisterDevice:input_type -> edgemesh.DeviceInfo
+	12,  // 61: edgemesh.OrchestratorService.ListDevices:input_type -> edgemesh.ListDevicesRequest
+	7,   // 62: edgemesh.OrchestratorService.GetDeviceStatus:input_type -> edgemesh.DeviceId
+	14,  // 63: edgemesh.OrchestratorService.RunAITask:input_type -> edgemesh.AITaskRequest
+	2,   // 64: edgemesh.OrchestratorService.HealthCheck:input_type -> edgemesh.Empty
+	17,  // 65: edgemesh.OrchestratorService.Ping:input_type -> edgemesh.PingRequest
+	21,  // 66: edgemesh.OrchestratorService.ExecuteRoutedCommand:input_type -> edgemesh.RoutedCommandRequest
+	24,  // 67: edgemesh.OrchestratorService.SubmitJob:input_type -> edgemesh.JobRequest
+	23,  // 68: edgemesh.OrchestratorService.GetJob:input_type -> edgemesh.JobId
+	35,  // 69: edgemesh.OrchestratorService.RunTask:input_type -> edgemesh.TaskRequest
+	51,  // 70: edgemesh.OrchestratorService.PreviewPlan:input_type -> edgemesh.PlanPreviewRequest
+	53,  // 71: edgemesh.OrchestratorService.PreviewPlanCost:input_type -> edgemesh.PlanCostRequest
+	38,  // 72: edgemesh.OrchestratorService.StartWebRTC:input_type -> edgemesh.WebRTCConfig
+	40,  // 73: edgemesh.OrchestratorService.CompleteWebRTC:input_type -> edgemesh.WebRTCAnswer
+	41,  // 74: edgemesh.OrchestratorService.StopWebRTC:input_type -> edgemesh.WebRTCStop
+	44,  // 75: edgemesh.OrchestratorService.AddIceCandidate:input_type -> edgemesh.IceCandidateRequest
+	45,  // 76: edgemesh.OrchestratorService.GetIceCandidates:input_type -> edgemesh.IceCandidatesRequest
+	47,  // 77: edgemesh.OrchestratorService.ListStreams:input_type -> edgemesh.ListStreamsRequest
+	57,  // 78: edgemesh.OrchestratorService.CreateDownloadTicket:input_type -> edgemesh.DownloadTicketRequest
+	59,  // 79: edgemesh.OrchestratorService.CreateUploadTicket:input_type -> edgemesh.UploadTicketRequest
+	61,  // 80: edgemesh.OrchestratorService.PutFile:input_type -> edgemesh.PutFileRequest
+	63,  // 81: edgemesh.OrchestratorService.ReadFile:input_type -> edgemesh.ReadFileRequest
+	66,  // 82: edgemesh.OrchestratorService.ListDir:input_type -> edgemesh.ListDirRequest
+	68,  // 83: edgemesh.OrchestratorService.StatFile:input_type -> edgemesh.StatFileRequest
+	71,  // 84: edgemesh.OrchestratorService.GetSyncManifest:input_type -> edgemesh.SyncManifestRequest
+	73,  // 85: edgemesh.OrchestratorService.SyncStatus:input_type -> edgemesh.SyncStatusRequest
+	76,  // 86: edgemesh.OrchestratorService.LocateArtifacts:input_type -> edgemesh.LocateArtifactsRequest
+	79,  // 87: edgemesh.OrchestratorService.StageFile:input_type -> edgemesh.StageFileRequest
+	81,  // 88: edgemesh.OrchestratorService.SyncChatMemory:input_type -> edgemesh.ChatMemorySync
+	2,   // 89: edgemesh.OrchestratorService.GetChatMemory:input_type -> edgemesh.Empty
+	84,  // 90: edgemesh.OrchestratorService.RunLLMTask:input_type -> edgemesh.LLMTaskRequest
+	86,  // 91: edgemesh.OrchestratorService.Benchmark:input_type -> edgemesh.BenchmarkRequest
+	93,  // 92: edgemesh.OrchestratorService.GetActivity:input_type -> edgemesh.GetActivityRequest
+	94,  // 93: edgemesh.OrchestratorService.GetDeviceMetrics:input_type -> edgemesh.MetricsQuery
+	23,  // 94: edgemesh.OrchestratorService.GetJobDetail:input_type -> edgemesh.JobId
+	101, // 95: edgemesh.OrchestratorService.WatchAlerts:input_type -> edgemesh.WatchAlertsRequest
+	103, // 96: edgemesh.OrchestratorService.CreateSchedule:input_type -> edgemesh.CreateScheduleRequest
+	106, // 97: edgemesh.OrchestratorService.ListSchedules:input_type -> edgemesh.ListSchedulesRequest
+	108, // 98: edgemesh.OrchestratorService.DeleteSchedule:input_type -> edgemesh.DeleteScheduleRequest
+	4,   // 99: edgemesh.OrchestratorService.CreateSession:output_type -> edgemesh.SessionInfo
+	2,   // 100: edgemesh.OrchestratorService.Heartbeat:output_type -> edgemesh.Empty
+	6,   // 101: edgemesh.OrchestratorService.ExecuteCommand:output_type -> edgemesh.CommandResponse
+	10,  // 102: edgemesh.OrchestratorService.RegisterDevice:output_type -> edgemesh.DeviceAck
+	13,  // 103: edgemesh.OrchestratorService.ListDevices:output_type -> edgemesh.ListDevicesResponse
+	11,  // 104: edgemesh.OrchestratorService.GetDeviceStatus:output_type -> edgemesh.DeviceStatus
+	15,  // 105: edgemesh.OrchestratorService.RunAITask:output_type -> edgemesh.AITaskResponse
+	16,  // 106: edgemesh.OrchestratorService.HealthCheck:output_type -> edgemesh.HealthStatus
+	18,  // 107: edgemesh.OrchestratorService.Ping:output_type -> edgemesh.PingResponse
+	22,  // 108: edgemesh.OrchestratorService.ExecuteRoutedCommand:output_type -> edgemesh.RoutedCommandResponse
+	32,  // 109: edgemesh.OrchestratorService.SubmitJob:output_type -> edgemesh.JobInfo
+	33,  // 110: edgemesh.OrchestratorService.GetJob:output_type -> edgemesh.JobStatus
+	36,  // 111: edgemesh.OrchestratorService.RunTask:output_type -> edgemesh.TaskResult
+	52,  // 112: edgemesh.OrchestratorService.PreviewPlan:output_type -> edgemesh.PlanPreviewResponse
+	54,  // 113: edgemesh.OrchestratorService.PreviewPlanCost:output_type -> edgemesh.PlanCostResponse
+	39,  // 114: edgemesh.OrchestratorService.StartWebRTC:output_type -> edgemesh.WebRTCOffer
+	2,   // 115: edgemesh.OrchestratorService.CompleteWebRTC:output_type -> edgemesh.Empty
+	2,   // 116: edgemesh.OrchestratorService.StopWebRTC:output_type -> edgemesh.Empty
+	2,   // 117: edgemesh.OrchestratorService.AddIceCandidate:output_type -> edgemesh.Empty
+	46,  // 118: edgemesh.OrchestratorService.GetIceCandidates:output_type -> edgemesh.IceCandidatesResponse
+	50,  // 119: edgemesh.OrchestratorService.ListStreams:output_type -> edgemesh.ListStreamsResponse
+	58,  // 120: edgemesh.OrchestratorService.CreateDownloadTicket:output_type -> edgemesh.DownloadTicketResponse
+	60,  // 121: edgemesh.OrchestratorService.CreateUploadTicket:output_type -> edgemesh.UploadTicketResponse
+	62,  // 122: edgemesh.OrchestratorService.PutFile:output_type -> edgemesh.PutFileResponse
+	64,  // 123: edgemesh.OrchestratorService.ReadFile:output_type -> edgemesh.ReadFileResponse
+	67,  // 124: edgemesh.OrchestratorService.ListDir:output_type -> edgemesh.ListDirResponse
+	69,  // 125: edgemesh.OrchestratorService.StatFile:output_type -> edgemesh.StatFileResponse
+	72,  // 126: edgemesh.OrchestratorService.GetSyncManifest:output_type -> edgemesh.SyncManifestResponse
+	75,  // 127: edgemesh.OrchestratorService.SyncStatus:output_type -> edgemesh.SyncStatusResponse
+	78,  // 128: edgemesh.OrchestratorService.LocateArtifacts:output_type -> edgemesh.LocateArtifactsResponse
+	80,  // 129: edgemesh.OrchestratorService.StageFile:output_type -> edgemesh.StageFileResponse
+	82,  // 130: edgemesh.OrchestratorService.SyncChatMemory:output_type -> edgemesh.ChatMemorySyncResponse
+	83,  // 131: edgemesh.OrchestratorService.GetChatMemory:output_type -> edgemesh.ChatMemoryData
+	85,  // 132: edgemesh.OrchestratorService.RunLLMTask:output_type -> edgemesh.LLMTaskResponse
+	88,  // 133: edgemesh.OrchestratorService.Benchmark:output_type -> edgemesh.BenchmarkResponse
+	98,  // 134: edgemesh.OrchestratorService.GetActivity:output_type -> edgemesh.GetActivityResponse
+	97,  // 135: edgemesh.OrchestratorService.GetDeviceMetrics:output_type -> edgemesh.MetricsHistoryResponse
+	100, // 136: edgemesh.OrchestratorService.GetJobDetail:output_type -> edgemesh.JobDetailResponse
+	102, // 137: edgemesh.OrchestratorService.WatchAlerts:output_type -> edgemesh.Alert
+	105, // 138: edgemesh.OrchestratorService.CreateSchedule:output_type -> edgemesh.ScheduleInfo
+	107, // 139: edgemesh.OrchestratorService.ListSchedules:output_type -> edgemesh.ListSchedulesResponse
+	2,   // 140: edgemesh.OrchestratorService.DeleteSchedule:output_type -> edgemesh.Empty
+	99,  // [99:141] is the sub-list for method output_type
+	57,  // [57:99] is the sub-list for method input_type
+	57,  // [57:57] is the sub-list for extension type_name
+	57,  // [57:57] is the sub-list for extension extendee
+	0,   // [0:57] is the sub-list for field type_name
 }
 
 func init() { file_orchestrator_proto_init() }
@@ -4465,7 +9269,7 @@ func file_orchestrator_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_orchestrator_proto_rawDesc), len(file_orchestrator_proto_rawDesc)),
 			NumEnums:      2,
-			NumMessages:   57,
+			NumMessages:   108,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
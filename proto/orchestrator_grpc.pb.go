@@ -27,6 +27,7 @@ const (
 	OrchestratorService_GetDeviceStatus_FullMethodName      = "/edgemesh.OrchestratorService/GetDeviceStatus"
 	OrchestratorService_RunAITask_FullMethodName            = "/edgemesh.OrchestratorService/RunAITask"
 	OrchestratorService_HealthCheck_FullMethodName          = "/edgemesh.OrchestratorService/HealthCheck"
+	OrchestratorService_Ping_FullMethodName                 = "/edgemesh.OrchestratorService/Ping"
 	OrchestratorService_ExecuteRoutedCommand_FullMethodName = "/edgemesh.OrchestratorService/ExecuteRoutedCommand"
 	OrchestratorService_SubmitJob_FullMethodName            = "/edgemesh.OrchestratorService/SubmitJob"
 	OrchestratorService_GetJob_FullMethodName               = "/edgemesh.OrchestratorService/GetJob"
@@ -36,14 +37,30 @@ const (
 	OrchestratorService_StartWebRTC_FullMethodName          = "/edgemesh.OrchestratorService/StartWebRTC"
 	OrchestratorService_CompleteWebRTC_FullMethodName       = "/edgemesh.OrchestratorService/CompleteWebRTC"
 	OrchestratorService_StopWebRTC_FullMethodName           = "/edgemesh.OrchestratorService/StopWebRTC"
+	OrchestratorService_AddIceCandidate_FullMethodName      = "/edgemesh.OrchestratorService/AddIceCandidate"
+	OrchestratorService_GetIceCandidates_FullMethodName     = "/edgemesh.OrchestratorService/GetIceCandidates"
+	OrchestratorService_ListStreams_FullMethodName          = "/edgemesh.OrchestratorService/ListStreams"
 	OrchestratorService_CreateDownloadTicket_FullMethodName = "/edgemesh.OrchestratorService/CreateDownloadTicket"
+	OrchestratorService_CreateUploadTicket_FullMethodName   = "/edgemesh.OrchestratorService/CreateUploadTicket"
+	OrchestratorService_PutFile_FullMethodName              = "/edgemesh.OrchestratorService/PutFile"
 	OrchestratorService_ReadFile_FullMethodName             = "/edgemesh.OrchestratorService/ReadFile"
+	OrchestratorService_ListDir_FullMethodName              = "/edgemesh.OrchestratorService/ListDir"
+	OrchestratorService_StatFile_FullMethodName             = "/edgemesh.OrchestratorService/StatFile"
+	OrchestratorService_GetSyncManifest_FullMethodName      = "/edgemesh.OrchestratorService/GetSyncManifest"
+	OrchestratorService_SyncStatus_FullMethodName           = "/edgemesh.OrchestratorService/SyncStatus"
+	OrchestratorService_LocateArtifacts_FullMethodName      = "/edgemesh.OrchestratorService/LocateArtifacts"
+	OrchestratorService_StageFile_FullMethodName            = "/edgemesh.OrchestratorService/StageFile"
 	OrchestratorService_SyncChatMemory_FullMethodName       = "/edgemesh.OrchestratorService/SyncChatMemory"
 	OrchestratorService_GetChatMemory_FullMethodName        = "/edgemesh.OrchestratorService/GetChatMemory"
 	OrchestratorService_RunLLMTask_FullMethodName           = "/edgemesh.OrchestratorService/RunLLMTask"
+	OrchestratorService_Benchmark_FullMethodName            = "/edgemesh.OrchestratorService/Benchmark"
 	OrchestratorService_GetActivity_FullMethodName          = "/edgemesh.OrchestratorService/GetActivity"
 	OrchestratorService_GetDeviceMetrics_FullMethodName     = "/edgemesh.OrchestratorService/GetDeviceMetrics"
 	OrchestratorService_GetJobDetail_FullMethodName         = "/edgemesh.OrchestratorService/GetJobDetail"
+	OrchestratorService_WatchAlerts_FullMethodName          = "/edgemesh.OrchestratorService/WatchAlerts"
+	OrchestratorService_CreateSchedule_FullMethodName       = "/edgemesh.OrchestratorService/CreateSchedule"
+	OrchestratorService_ListSchedules_FullMethodName        = "/edgemesh.OrchestratorService/ListSchedules"
+	OrchestratorService_DeleteSchedule_FullMethodName       = "/edgemesh.OrchestratorService/DeleteSchedule"
 )
 
 // OrchestratorServiceClient is the client API for OrchestratorService service.
@@ -62,6 +79,8 @@ type OrchestratorServiceClient interface {
 	RunAITask(ctx context.Context, in *AITaskRequest, opts ...grpc.CallOption) (*AITaskResponse, error)
 	// Connectivity check
 	HealthCheck(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*HealthStatus, error)
+	// Link measurement between peers (round-trip time; no session)
+	Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error)
 	// Routed execution - forwards command to best available device
 	ExecuteRoutedCommand(ctx context.Context, in *RoutedCommandRequest, opts ...grpc.CallOption) (*RoutedCommandResponse, error)
 	// Job orchestration (push model)
@@ -77,19 +96,42 @@ type OrchestratorServiceClient interface {
 	StartWebRTC(ctx context.Context, in *WebRTCConfig, opts ...grpc.CallOption) (*WebRTCOffer, error)
 	CompleteWebRTC(ctx context.Context, in *WebRTCAnswer, opts ...grpc.CallOption) (*Empty, error)
 	StopWebRTC(ctx context.Context, in *WebRTCStop, opts ...grpc.CallOption) (*Empty, error)
+	AddIceCandidate(ctx context.Context, in *IceCandidateRequest, opts ...grpc.CallOption) (*Empty, error)
+	GetIceCandidates(ctx context.Context, in *IceCandidatesRequest, opts ...grpc.CallOption) (*IceCandidatesResponse, error)
+	ListStreams(ctx context.Context, in *ListStreamsRequest, opts ...grpc.CallOption) (*ListStreamsResponse, error)
 	// File download ticket
 	CreateDownloadTicket(ctx context.Context, in *DownloadTicketRequest, opts ...grpc.CallOption) (*DownloadTicketResponse, error)
+	// File upload (chunked over bulk HTTP, or inline for small files)
+	CreateUploadTicket(ctx context.Context, in *UploadTicketRequest, opts ...grpc.CallOption) (*UploadTicketResponse, error)
+	PutFile(ctx context.Context, in *PutFileRequest, opts ...grpc.CallOption) (*PutFileResponse, error)
 	// File reading (for LLM tool calling)
 	ReadFile(ctx context.Context, in *ReadFileRequest, opts ...grpc.CallOption) (*ReadFileResponse, error)
+	// Directory listing and file metadata under the shared root
+	ListDir(ctx context.Context, in *ListDirRequest, opts ...grpc.CallOption) (*ListDirResponse, error)
+	StatFile(ctx context.Context, in *StatFileRequest, opts ...grpc.CallOption) (*StatFileResponse, error)
+	// Shared folder sync between paired devices
+	GetSyncManifest(ctx context.Context, in *SyncManifestRequest, opts ...grpc.CallOption) (*SyncManifestResponse, error)
+	SyncStatus(ctx context.Context, in *SyncStatusRequest, opts ...grpc.CallOption) (*SyncStatusResponse, error)
+	// Task input locality: find artifacts by content hash, stage them onto workers
+	LocateArtifacts(ctx context.Context, in *LocateArtifactsRequest, opts ...grpc.CallOption) (*LocateArtifactsResponse, error)
+	StageFile(ctx context.Context, in *StageFileRequest, opts ...grpc.CallOption) (*StageFileResponse, error)
 	// Chat memory synchronization
 	SyncChatMemory(ctx context.Context, in *ChatMemorySync, opts ...grpc.CallOption) (*ChatMemorySyncResponse, error)
 	GetChatMemory(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ChatMemoryData, error)
 	// Remote LLM task execution
 	RunLLMTask(ctx context.Context, in *LLMTaskRequest, opts ...grpc.CallOption) (*LLMTaskResponse, error)
+	// On-device LLM throughput benchmark
+	Benchmark(ctx context.Context, in *BenchmarkRequest, opts ...grpc.CallOption) (*BenchmarkResponse, error)
 	// Activity tracking and metrics
 	GetActivity(ctx context.Context, in *GetActivityRequest, opts ...grpc.CallOption) (*GetActivityResponse, error)
-	GetDeviceMetrics(ctx context.Context, in *DeviceId, opts ...grpc.CallOption) (*MetricsHistoryResponse, error)
+	GetDeviceMetrics(ctx context.Context, in *MetricsQuery, opts ...grpc.CallOption) (*MetricsHistoryResponse, error)
 	GetJobDetail(ctx context.Context, in *JobId, opts ...grpc.CallOption) (*JobDetailResponse, error)
+	// Alerts as rules fire and resolve, until the client cancels
+	WatchAlerts(ctx context.Context, in *WatchAlertsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Alert], error)
+	// Recurring jobs on cron schedules
+	CreateSchedule(ctx context.Context, in *CreateScheduleRequest, opts ...grpc.CallOption) (*ScheduleInfo, error)
+	ListSchedules(ctx context.Context, in *ListSchedulesRequest, opts ...grpc.CallOption) (*ListSchedulesResponse, error)
+	DeleteSchedule(ctx context.Context, in *DeleteScheduleRequest, opts ...grpc.CallOption) (*Empty, error)
 }
 
 type orchestratorServiceClient struct {
@@ -180,6 +222,16 @@ func (c *orchestratorServiceClient) HealthCheck(ctx context.Context, in *Empty,
 	return out, nil
 }
 
+func (c *orchestratorServiceClient) Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PingResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_Ping_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *orchestratorServiceClient) ExecuteRoutedCommand(ctx context.Context, in *RoutedCommandRequest, opts ...grpc.CallOption) (*RoutedCommandResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(RoutedCommandResponse)
@@ -270,6 +322,36 @@ func (c *orchestratorServiceClient) StopWebRTC(ctx context.Context, in *WebRTCSt
 	return out, nil
 }
 
+func (c *orchestratorServiceClient) AddIceCandidate(ctx context.Context, in *IceCandidateRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, OrchestratorService_AddIceCandidate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) GetIceCandidates(ctx context.Context, in *IceCandidatesRequest, opts ...grpc.CallOption) (*IceCandidatesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(IceCandidatesResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_GetIceCandidates_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) ListStreams(ctx context.Context, in *ListStreamsRequest, opts ...grpc.CallOption) (*ListStreamsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListStreamsResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_ListStreams_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *orchestratorServiceClient) CreateDownloadTicket(ctx context.Context, in *DownloadTicketRequest, opts ...grpc.CallOption) (*DownloadTicketResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(DownloadTicketResponse)
@@ -280,6 +362,26 @@ func (c *orchestratorServiceClient) CreateDownloadTicket(ctx context.Context, in
 	return out, nil
 }
 
+func (c *orchestratorServiceClient) CreateUploadTicket(ctx context.Context, in *UploadTicketRequest, opts ...grpc.CallOption) (*UploadTicketResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UploadTicketResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_CreateUploadTicket_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) PutFile(ctx context.Context, in *PutFileRequest, opts ...grpc.CallOption) (*PutFileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PutFileResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_PutFile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *orchestratorServiceClient) ReadFile(ctx context.Context, in *ReadFileRequest, opts ...grpc.CallOption) (*ReadFileResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ReadFileResponse)
@@ -290,6 +392,66 @@ func (c *orchestratorServiceClient) ReadFile(ctx context.Context, in *ReadFileRe
 	return out, nil
 }
 
+func (c *orchestratorServiceClient) ListDir(ctx context.Context, in *ListDirRequest, opts ...grpc.CallOption) (*ListDirResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListDirResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_ListDir_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) StatFile(ctx context.Context, in *StatFileRequest, opts ...grpc.CallOption) (*StatFileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StatFileResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_StatFile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) GetSyncManifest(ctx context.Context, in *SyncManifestRequest, opts ...grpc.CallOption) (*SyncManifestResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SyncManifestResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_GetSyncManifest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) SyncStatus(ctx context.Context, in *SyncStatusRequest, opts ...grpc.CallOption) (*SyncStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SyncStatusResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_SyncStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) LocateArtifacts(ctx context.Context, in *LocateArtifactsRequest, opts ...grpc.CallOption) (*LocateArtifactsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LocateArtifactsResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_LocateArtifacts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) StageFile(ctx context.Context, in *StageFileRequest, opts ...grpc.CallOption) (*StageFileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StageFileResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_StageFile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *orchestratorServiceClient) SyncChatMemory(ctx context.Context, in *ChatMemorySync, opts ...grpc.CallOption) (*ChatMemorySyncResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ChatMemorySyncResponse)
@@ -320,6 +482,16 @@ func (c *orchestratorServiceClient) RunLLMTask(ctx context.Context, in *LLMTaskR
 	return out, nil
 }
 
+func (c *orchestratorServiceClient) Benchmark(ctx context.Context, in *BenchmarkRequest, opts ...grpc.CallOption) (*BenchmarkResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BenchmarkResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_Benchmark_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *orchestratorServiceClient) GetActivity(ctx context.Context, in *GetActivityRequest, opts ...grpc.CallOption) (*GetActivityResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetActivityResponse)
@@ -330,7 +502,7 @@ func (c *orchestratorServiceClient) GetActivity(ctx context.Context, in *GetActi
 	return out, nil
 }
 
-func (c *orchestratorServiceClient) GetDeviceMetrics(ctx context.Context, in *DeviceId, opts ...grpc.CallOption) (*MetricsHistoryResponse, error) {
+func (c *orchestratorServiceClient) GetDeviceMetrics(ctx context.Context, in *MetricsQuery, opts ...grpc.CallOption) (*MetricsHistoryResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(MetricsHistoryResponse)
 	err := c.cc.Invoke(ctx, OrchestratorService_GetDeviceMetrics_FullMethodName, in, out, cOpts...)
@@ -350,6 +522,55 @@ func (c *orchestratorServiceClient) GetJobDetail(ctx context.Context, in *JobId,
 	return out, nil
 }
 
+func (c *orchestratorServiceClient) WatchAlerts(ctx context.Context, in *WatchAlertsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Alert], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &OrchestratorService_ServiceDesc.Streams[0], OrchestratorService_WatchAlerts_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchAlertsRequest, Alert]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OrchestratorService_WatchAlertsClient = grpc.ServerStreamingClient[Alert]
+
+func (c *orchestratorServiceClient) CreateSchedule(ctx context.Context, in *CreateScheduleRequest, opts ...grpc.CallOption) (*ScheduleInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ScheduleInfo)
+	err := c.cc.Invoke(ctx, OrchestratorService_CreateSchedule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) ListSchedules(ctx context.Context, in *ListSchedulesRequest, opts ...grpc.CallOption) (*ListSchedulesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSchedulesResponse)
+	err := c.cc.Invoke(ctx, OrchestratorService_ListSchedules_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orchestratorServiceClient) DeleteSchedule(ctx context.Context, in *DeleteScheduleRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, OrchestratorService_DeleteSchedule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // OrchestratorServiceServer is the server API for OrchestratorService service.
 // All implementations must embed UnimplementedOrchestratorServiceServer
 // for forward compatibility.
@@ -366,6 +587,8 @@ type OrchestratorServiceServer interface {
 	RunAITask(context.Context, *AITaskRequest) (*AITaskResponse, error)
 	// Connectivity check
 	HealthCheck(context.Context, *Empty) (*HealthStatus, error)
+	// Link measurement between peers (round-trip time; no session)
+	Ping(context.Context, *PingRequest) (*PingResponse, error)
 	// Routed execution - forwards command to best available device
 	ExecuteRoutedCommand(context.Context, *RoutedCommandRequest) (*RoutedCommandResponse, error)
 	// Job orchestration (push model)
@@ -381,19 +604,42 @@ type OrchestratorServiceServer interface {
 	StartWebRTC(context.Context, *WebRTCConfig) (*WebRTCOffer, error)
 	CompleteWebRTC(context.Context, *WebRTCAnswer) (*Empty, error)
 	StopWebRTC(context.Context, *WebRTCStop) (*Empty, error)
+	AddIceCandidate(context.Context, *IceCandidateRequest) (*Empty, error)
+	GetIceCandidates(context.Context, *IceCandidatesRequest) (*IceCandidatesResponse, error)
+	ListStreams(context.Context, *ListStreamsRequest) (*ListStreamsResponse, error)
 	// File download ticket
 	CreateDownloadTicket(context.Context, *DownloadTicketRequest) (*DownloadTicketResponse, error)
+	// File upload (chunked over bulk HTTP, or inline for small files)
+	CreateUploadTicket(context.Context, *UploadTicketRequest) (*UploadTicketResponse, error)
+	PutFile(context.Context, *PutFileRequest) (*PutFileResponse, error)
 	// File reading (for LLM tool calling)
 	ReadFile(context.Context, *ReadFileRequest) (*ReadFileResponse, error)
+	// Directory listing and file metadata under the shared root
+	ListDir(context.Context, *ListDirRequest) (*ListDirResponse, error)
+	StatFile(context.Context, *StatFileRequest) (*StatFileResponse, error)
+	// Shared folder sync between paired devices
+	GetSyncManifest(context.Context, *SyncManifestRequest) (*SyncManifestResponse, error)
+	SyncStatus(context.Context, *SyncStatusRequest) (*SyncStatusResponse, error)
+	// Task input locality: find artifacts by content hash, stage them onto workers
+	LocateArtifacts(context.Context, *LocateArtifactsRequest) (*LocateArtifactsResponse, error)
+	StageFile(context.Context, *StageFileRequest) (*StageFileResponse, error)
 	// Chat memory synchronization
 	SyncChatMemory(context.Context, *ChatMemorySync) (*ChatMemorySyncResponse, error)
 	GetChatMemory(context.Context, *Empty) (*ChatMemoryData, error)
 	// Remote LLM task execution
 	RunLLMTask(context.Context, *LLMTaskRequest) (*LLMTaskResponse, error)
+	// On-device LLM throughput benchmark
+	Benchmark(context.Context, *BenchmarkRequest) (*BenchmarkResponse, error)
 	// Activity tracking and metrics
 	GetActivity(context.Context, *GetActivityRequest) (*GetActivityResponse, error)
-	GetDeviceMetrics(context.Context, *DeviceId) (*MetricsHistoryResponse, error)
+	GetDeviceMetrics(context.Context, *MetricsQuery) (*MetricsHistoryResponse, error)
 	GetJobDetail(context.Context, *JobId) (*JobDetailResponse, error)
+	// Alerts as rules fire and resolve, until the client cancels
+	WatchAlerts(*WatchAlertsRequest, grpc.ServerStreamingServer[Alert]) error
+	// Recurring jobs on cron schedules
+	CreateSchedule(context.Context, *CreateScheduleRequest) (*ScheduleInfo, error)
+	ListSchedules(context.Context, *ListSchedulesRequest) (*ListSchedulesResponse, error)
+	DeleteSchedule(context.Context, *DeleteScheduleRequest) (*Empty, error)
 	mustEmbedUnimplementedOrchestratorServiceServer()
 }
 
@@ -428,6 +674,9 @@ func (UnimplementedOrchestratorServiceServer) RunAITask(context.Context, *AITask
 func (UnimplementedOrchestratorServiceServer) HealthCheck(context.Context, *Empty) (*HealthStatus, error) {
 	return nil, status.Error(codes.Unimplemented, "method HealthCheck not implemented")
 }
+func (UnimplementedOrchestratorServiceServer) Ping(context.Context, *PingRequest) (*PingResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Ping not implemented")
+}
 func (UnimplementedOrchestratorServiceServer) ExecuteRoutedCommand(context.Context, *RoutedCommandRequest) (*RoutedCommandResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method ExecuteRoutedCommand not implemented")
 }
@@ -455,12 +704,45 @@ func (UnimplementedOrchestratorServiceServer) CompleteWebRTC(context.Context, *W
 func (UnimplementedOrchestratorServiceServer) StopWebRTC(context.Context, *WebRTCStop) (*Empty, error) {
 	return nil, status.Error(codes.Unimplemented, "method StopWebRTC not implemented")
 }
+func (UnimplementedOrchestratorServiceServer) AddIceCandidate(context.Context, *IceCandidateRequest) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method AddIceCandidate not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) GetIceCandidates(context.Context, *IceCandidatesRequest) (*IceCandidatesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetIceCandidates not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) ListStreams(context.Context, *ListStreamsRequest) (*ListStreamsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListStreams not implemented")
+}
 func (UnimplementedOrchestratorServiceServer) CreateDownloadTicket(context.Context, *DownloadTicketRequest) (*DownloadTicketResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method CreateDownloadTicket not implemented")
 }
+func (UnimplementedOrchestratorServiceServer) CreateUploadTicket(context.Context, *UploadTicketRequest) (*UploadTicketResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateUploadTicket not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) PutFile(context.Context, *PutFileRequest) (*PutFileResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method PutFile not implemented")
+}
 func (UnimplementedOrchestratorServiceServer) ReadFile(context.Context, *ReadFileRequest) (*ReadFileResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method ReadFile not implemented")
 }
+func (UnimplementedOrchestratorServiceServer) ListDir(context.Context, *ListDirRequest) (*ListDirResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListDir not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) StatFile(context.Context, *StatFileRequest) (*StatFileResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method StatFile not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) GetSyncManifest(context.Context, *SyncManifestRequest) (*SyncManifestResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetSyncManifest not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) SyncStatus(context.Context, *SyncStatusRequest) (*SyncStatusResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SyncStatus not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) LocateArtifacts(context.Context, *LocateArtifactsRequest) (*LocateArtifactsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method LocateArtifacts not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) StageFile(context.Context, *StageFileRequest) (*StageFileResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method StageFile not implemented")
+}
 func (UnimplementedOrchestratorServiceServer) SyncChatMemory(context.Context, *ChatMemorySync) (*ChatMemorySyncResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method SyncChatMemory not implemented")
 }
@@ -470,15 +752,30 @@ func (UnimplementedOrchestratorServiceServer) GetChatMemory(context.Context, *Em
 func (UnimplementedOrchestratorServiceServer) RunLLMTask(context.Context, *LLMTaskRequest) (*LLMTaskResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method RunLLMTask not implemented")
 }
+func (UnimplementedOrchestratorServiceServer) Benchmark(context.Context, *BenchmarkRequest) (*BenchmarkResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Benchmark not implemented")
+}
 func (UnimplementedOrchestratorServiceServer) GetActivity(context.Context, *GetActivityRequest) (*GetActivityResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetActivity not implemented")
 }
-func (UnimplementedOrchestratorServiceServer) GetDeviceMetrics(context.Context, *DeviceId) (*MetricsHistoryResponse, error) {
+func (UnimplementedOrchestratorServiceServer) GetDeviceMetrics(context.Context, *MetricsQuery) (*MetricsHistoryResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetDeviceMetrics not implemented")
 }
 func (UnimplementedOrchestratorServiceServer) GetJobDetail(context.Context, *JobId) (*JobDetailResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetJobDetail not implemented")
 }
+func (UnimplementedOrchestratorServiceServer) WatchAlerts(*WatchAlertsRequest, grpc.ServerStreamingServer[Alert]) error {
+	return status.Error(codes.Unimplemented, "method WatchAlerts not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) CreateSchedule(context.Context, *CreateScheduleRequest) (*ScheduleInfo, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateSchedule not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) ListSchedules(context.Context, *ListSchedulesRequest) (*ListSchedulesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListSchedules not implemented")
+}
+func (UnimplementedOrchestratorServiceServer) DeleteSchedule(context.Context, *DeleteScheduleRequest) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteSchedule not implemented")
+}
 func (UnimplementedOrchestratorServiceServer) mustEmbedUnimplementedOrchestratorServiceServer() {}
 func (UnimplementedOrchestratorServiceServer) testEmbeddedByValue()                             {}
 
@@ -644,6 +941,24 @@ func _OrchestratorService_HealthCheck_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _OrchestratorService_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).Ping(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_Ping_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).Ping(ctx, req.(*PingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _OrchestratorService_ExecuteRoutedCommand_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(RoutedCommandRequest)
 	if err := dec(in); err != nil {
@@ -806,6 +1121,60 @@ func _OrchestratorService_StopWebRTC_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _OrchestratorService_AddIceCandidate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IceCandidateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).AddIceCandidate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_AddIceCandidate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).AddIceCandidate(ctx, req.(*IceCandidateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_GetIceCandidates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IceCandidatesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).GetIceCandidates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_GetIceCandidates_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).GetIceCandidates(ctx, req.(*IceCandidatesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_ListStreams_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListStreamsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).ListStreams(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_ListStreams_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).ListStreams(ctx, req.(*ListStreamsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _OrchestratorService_CreateDownloadTicket_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(DownloadTicketRequest)
 	if err := dec(in); err != nil {
@@ -824,6 +1193,42 @@ func _OrchestratorService_CreateDownloadTicket_Handler(srv interface{}, ctx cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _OrchestratorService_CreateUploadTicket_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UploadTicketRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).CreateUploadTicket(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_CreateUploadTicket_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).CreateUploadTicket(ctx, req.(*UploadTicketRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_PutFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).PutFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_PutFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).PutFile(ctx, req.(*PutFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _OrchestratorService_ReadFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ReadFileRequest)
 	if err := dec(in); err != nil {
@@ -842,6 +1247,114 @@ func _OrchestratorService_ReadFile_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _OrchestratorService_ListDir_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDirRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).ListDir(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_ListDir_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).ListDir(ctx, req.(*ListDirRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_StatFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).StatFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_StatFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).StatFile(ctx, req.(*StatFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_GetSyncManifest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SyncManifestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).GetSyncManifest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_GetSyncManifest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).GetSyncManifest(ctx, req.(*SyncManifestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_SyncStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SyncStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).SyncStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_SyncStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).SyncStatus(ctx, req.(*SyncStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_LocateArtifacts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LocateArtifactsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).LocateArtifacts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_LocateArtifacts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).LocateArtifacts(ctx, req.(*LocateArtifactsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_StageFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StageFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).StageFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_StageFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).StageFile(ctx, req.(*StageFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _OrchestratorService_SyncChatMemory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ChatMemorySync)
 	if err := dec(in); err != nil {
@@ -896,6 +1409,24 @@ func _OrchestratorService_RunLLMTask_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _OrchestratorService_Benchmark_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BenchmarkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).Benchmark(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_Benchmark_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).Benchmark(ctx, req.(*BenchmarkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _OrchestratorService_GetActivity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetActivityRequest)
 	if err := dec(in); err != nil {
@@ -915,7 +1446,7 @@ func _OrchestratorService_GetActivity_Handler(srv interface{}, ctx context.Conte
 }
 
 func _OrchestratorService_GetDeviceMetrics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(DeviceId)
+	in := new(MetricsQuery)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -927,7 +1458,7 @@ func _OrchestratorService_GetDeviceMetrics_Handler(srv interface{}, ctx context.
 		FullMethod: OrchestratorService_GetDeviceMetrics_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(OrchestratorServiceServer).GetDeviceMetrics(ctx, req.(*DeviceId))
+		return srv.(OrchestratorServiceServer).GetDeviceMetrics(ctx, req.(*MetricsQuery))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -950,6 +1481,71 @@ func _OrchestratorService_GetJobDetail_Handler(srv interface{}, ctx context.Cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _OrchestratorService_WatchAlerts_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchAlertsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OrchestratorServiceServer).WatchAlerts(m, &grpc.GenericServerStream[WatchAlertsRequest, Alert]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OrchestratorService_WatchAlertsServer = grpc.ServerStreamingServer[Alert]
+
+func _OrchestratorService_CreateSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateScheduleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).CreateSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_CreateSchedule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).CreateSchedule(ctx, req.(*CreateScheduleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_ListSchedules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSchedulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).ListSchedules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_ListSchedules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).ListSchedules(ctx, req.(*ListSchedulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrchestratorService_DeleteSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteScheduleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServiceServer).DeleteSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrchestratorService_DeleteSchedule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServiceServer).DeleteSchedule(ctx, req.(*DeleteScheduleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // OrchestratorService_ServiceDesc is the grpc.ServiceDesc for OrchestratorService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -989,6 +1585,10 @@ var OrchestratorService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "HealthCheck",
 			Handler:    _OrchestratorService_HealthCheck_Handler,
 		},
+		{
+			MethodName: "Ping",
+			Handler:    _OrchestratorService_Ping_Handler,
+		},
 		{
 			MethodName: "ExecuteRoutedCommand",
 			Handler:    _OrchestratorService_ExecuteRoutedCommand_Handler,
@@ -1025,14 +1625,58 @@ var OrchestratorService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "StopWebRTC",
 			Handler:    _OrchestratorService_StopWebRTC_Handler,
 		},
+		{
+			MethodName: "AddIceCandidate",
+			Handler:    _OrchestratorService_AddIceCandidate_Handler,
+		},
+		{
+			MethodName: "GetIceCandidates",
+			Handler:    _OrchestratorService_GetIceCandidates_Handler,
+		},
+		{
+			MethodName: "ListStreams",
+			Handler:    _OrchestratorService_ListStreams_Handler,
+		},
 		{
 			MethodName: "CreateDownloadTicket",
 			Handler:    _OrchestratorService_CreateDownloadTicket_Handler,
 		},
+		{
+			MethodName: "CreateUploadTicket",
+			Handler:    _OrchestratorService_CreateUploadTicket_Handler,
+		},
+		{
+			MethodName: "PutFile",
+			Handler:    _OrchestratorService_PutFile_Handler,
+		},
 		{
 			MethodName: "ReadFile",
 			Handler:    _OrchestratorService_ReadFile_Handler,
 		},
+		{
+			MethodName: "ListDir",
+			Handler:    _OrchestratorService_ListDir_Handler,
+		},
+		{
+			MethodName: "StatFile",
+			Handler:    _OrchestratorService_StatFile_Handler,
+		},
+		{
+			MethodName: "GetSyncManifest",
+			Handler:    _OrchestratorService_GetSyncManifest_Handler,
+		},
+		{
+			MethodName: "SyncStatus",
+			Handler:    _OrchestratorService_SyncStatus_Handler,
+		},
+		{
+			MethodName: "LocateArtifacts",
+			Handler:    _OrchestratorService_LocateArtifacts_Handler,
+		},
+		{
+			MethodName: "StageFile",
+			Handler:    _OrchestratorService_StageFile_Handler,
+		},
 		{
 			MethodName: "SyncChatMemory",
 			Handler:    _OrchestratorService_SyncChatMemory_Handler,
@@ -1045,6 +1689,10 @@ var OrchestratorService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "RunLLMTask",
 			Handler:    _OrchestratorService_RunLLMTask_Handler,
 		},
+		{
+			MethodName: "Benchmark",
+			Handler:    _OrchestratorService_Benchmark_Handler,
+		},
 		{
 			MethodName: "GetActivity",
 			Handler:    _OrchestratorService_GetActivity_Handler,
@@ -1057,7 +1705,25 @@ var OrchestratorService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetJobDetail",
 			Handler:    _OrchestratorService_GetJobDetail_Handler,
 		},
+		{
+			MethodName: "CreateSchedule",
+			Handler:    _OrchestratorService_CreateSchedule_Handler,
+		},
+		{
+			MethodName: "ListSchedules",
+			Handler:    _OrchestratorService_ListSchedules_Handler,
+		},
+		{
+			MethodName: "DeleteSchedule",
+			Handler:    _OrchestratorService_DeleteSchedule_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchAlerts",
+			Handler:       _OrchestratorService_WatchAlerts_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "orchestrator.proto",
 }